@@ -0,0 +1,185 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/va6996/travelingman/log"
+	"github.com/va6996/travelingman/orm"
+	"github.com/va6996/travelingman/pb"
+	"github.com/va6996/travelingman/plugins/amadeus"
+)
+
+// FlightBooking is a placed flight order with what each traveler owes of it
+type FlightBooking struct {
+	Order *amadeus.FlightOrderResponse
+	// Shares are the recorded split, empty if the cost was not split
+	Shares []*pb.Payment
+	// Summary is the confirmation as emailed to the travelers
+	Summary string
+}
+
+// BookFlight places an order for offer with the provider and, if split is set, records
+// who owes what of its total. The split is checked before the order is placed, so an
+// invalid one books nothing; BY_TRAVELER splits charge each user the fares of the
+// travelers they were booked as, an infant's fare going to its adult. The total split
+// and recorded is the price of the provider's order, not the offer's, which may be
+// stale. The travelers are emailed a summary with their shares when the tracker has a
+// Mailer.
+//
+// The order is stored as a booking if the provider did not store it, and an unsplit
+// total is recorded as one pending payment by the first user, so every booking has
+// its payment rows.
+func (bt *BookingTracker) BookFlight(ctx context.Context, offer amadeus.FlightOffer, users []*pb.User, split *pb.PaymentSplit) (*FlightBooking, error) {
+	if bt.Booker == nil {
+		return nil, errors.New("no flight booking provider configured")
+	}
+	if split != nil {
+		if err := checkSplit(ctx, split, offer, users); err != nil {
+			return nil, err
+		}
+	}
+
+	order, err := bt.Booker.BookFlight(ctx, offer, users)
+	if err != nil {
+		return nil, err
+	}
+	booking := &FlightBooking{Order: order}
+	total, currencyCode := orderPrice(order, offer)
+	if order.BookingID == 0 && bt.DB != nil {
+		flight := order.BookedFlight()
+		if flight == nil {
+			flight = offer.ToTransport(nil).GetFlight()
+		}
+		stored, err := orm.CreateBooking(bt.DB, order.Data.ID, flight, travelerIDs(users))
+		if err != nil {
+			return booking, fmt.Errorf("order %s was placed but not stored: %w", order.Data.ID, err)
+		}
+		order.BookingID = stored.ID
+	}
+	if split != nil {
+		if order.BookingID == 0 {
+			return booking, fmt.Errorf("order %s was placed but not stored, so its split was not recorded", order.Data.ID)
+		}
+		if booking.Shares, err = bt.RecordSplit(ctx, order.BookingID, split, total, currencyCode, orderFares(order, offer)); err != nil {
+			return booking, fmt.Errorf("order %s was placed but its split was not recorded: %w", order.Data.ID, err)
+		}
+	} else if order.BookingID != 0 && bt.DB != nil {
+		payment := &pb.Payment{Amount: total, Currency: currencyCode, Status: orm.PaymentStatusPending}
+		if ids := travelerIDs(users); len(ids) > 0 {
+			payment.UserId = ids[0]
+		}
+		if err := orm.RecordPaymentSplit(bt.DB, order.BookingID, []*pb.Payment{payment}); err != nil {
+			return booking, fmt.Errorf("order %s was placed but its payment was not recorded: %w", order.Data.ID, err)
+		}
+	}
+	booking.Summary = bookingSummary(order.Data.ID, offer, total, currencyCode, users, booking.Shares)
+
+	if bt.Mailer != nil {
+		to := bt.Mailer.travelerEmails(ctx, travelerIDs(users))
+		if len(to) > 0 {
+			if err := bt.Mailer.Send(to, fmt.Sprintf("Booking %s confirmed", order.Data.ID), booking.Summary); err != nil {
+				log.Errorf(ctx, "BookingTracker: failed to email confirmation of order %s: %v", order.Data.ID, err)
+			}
+		}
+	}
+	return booking, nil
+}
+
+// checkSplit rejects a split that could not be recorded once offer is booked for
+// users. A BY_TRAVELER split is worked out from the fares the travelers will be booked
+// with, so every fare must fall to a user who can pay it.
+func checkSplit(ctx context.Context, split *pb.PaymentSplit, offer amadeus.FlightOffer, users []*pb.User) error {
+	var fares []*pb.Payment
+	if split.GetMode() == pb.SplitMode_SPLIT_MODE_BY_TRAVELER {
+		if len(offer.TravelerPricings) == 0 {
+			return fmt.Errorf("%w: offer has no per-traveler fares", ErrInvalidSplit)
+		}
+		userIDs, pricings, err := amadeus.AssignTravelers(ctx, offer, users)
+		if err != nil {
+			return err
+		}
+		fares = travelerFares(pricings, userIDs)
+	}
+	_, err := SplitPayment(split, offer.Price.Total, offer.Price.Currency, fares)
+	return err
+}
+
+// orderPrice is the total the provider charged for order, or the offer's if the order
+// does not say
+func orderPrice(order *amadeus.FlightOrderResponse, offer amadeus.FlightOffer) (string, string) {
+	if len(order.Data.FlightOffers) > 0 {
+		if price := order.Data.FlightOffers[0].Price; price.Total != "" && price.Currency != "" {
+			return price.Total, price.Currency
+		}
+	}
+	return offer.Price.Total, offer.Price.Currency
+}
+
+// orderFares returns each booked traveler's fare, charged to the user they were booked
+// as. The order's own pricings are preferred to the offer's as they name each infant's
+// adult.
+func orderFares(order *amadeus.FlightOrderResponse, offer amadeus.FlightOffer) []*pb.Payment {
+	pricings := offer.TravelerPricings
+	if len(order.Data.FlightOffers) > 0 && len(order.Data.FlightOffers[0].TravelerPricings) > 0 {
+		pricings = order.Data.FlightOffers[0].TravelerPricings
+	}
+	return travelerFares(pricings, order.TravelerUserIDs)
+}
+
+// travelerFares returns the fare of each of pricings, charged to the user in userIDs
+// its traveler is booked as, or to its adult's user for an infant
+func travelerFares(pricings []amadeus.TravelerPricing, userIDs map[string]int64) []*pb.Payment {
+	fares := make([]*pb.Payment, len(pricings))
+	for i, tp := range pricings {
+		user := userIDs[tp.TravelerID]
+		if user == 0 && tp.AssociatedAdultID != "" {
+			user = userIDs[tp.AssociatedAdultID]
+		}
+		fares[i] = &pb.Payment{UserId: user, Amount: tp.Price.Total, Currency: tp.Price.Currency}
+	}
+	return fares
+}
+
+// bookingSummary describes a placed order: its flights, total and each user's share
+func bookingSummary(orderID string, offer amadeus.FlightOffer, total, currencyCode string, users []*pb.User, shares []*pb.Payment) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Your booking %s is confirmed.\n", orderID)
+	n := 0
+	for _, itin := range offer.Itineraries {
+		for _, seg := range itin.Segments {
+			n++
+			fmt.Fprintf(&b, "- Flight %d: %s%s %s %s -> %s %s\n", n, seg.CarrierCode, seg.Number,
+				seg.Departure.IataCode, seg.Departure.At, seg.Arrival.IataCode, seg.Arrival.At)
+		}
+	}
+	fmt.Fprintf(&b, "Total: %s %s\n", total, currencyCode)
+	if len(shares) > 0 {
+		names := make(map[int64]string, len(users))
+		for _, u := range users {
+			names[u.GetId()] = u.GetFullName()
+		}
+		b.WriteString("Shares:\n")
+		for _, s := range shares {
+			name := names[s.UserId]
+			if name == "" {
+				name = fmt.Sprintf("User %d", s.UserId)
+			}
+			fmt.Fprintf(&b, "- %s: %s %s\n", name, s.Amount, s.Currency)
+		}
+	}
+	return b.String()
+}
+
+// travelerIDs returns the IDs of the users that have one
+func travelerIDs(users []*pb.User) []int64 {
+	var ids []int64
+	for _, u := range users {
+		if u.GetId() != 0 {
+			ids = append(ids, u.GetId())
+		}
+	}
+	return ids
+}
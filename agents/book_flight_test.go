@@ -0,0 +1,186 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/orm"
+	"github.com/va6996/travelingman/pb"
+	"github.com/va6996/travelingman/plugins/amadeus"
+	"gorm.io/gorm"
+)
+
+// fakeBooker stores every order it places, numbering travelers "1".."n" as Amadeus does.
+// Orders are priced at price if it is set, as if the fare changed since the search.
+type fakeBooker struct {
+	db     *gorm.DB
+	orders int
+	price  amadeus.Price
+}
+
+func (f *fakeBooker) BookFlight(ctx context.Context, offer amadeus.FlightOffer, users []*pb.User) (*amadeus.FlightOrderResponse, error) {
+	f.orders++
+	order := &amadeus.FlightOrderResponse{TravelerUserIDs: map[string]int64{}}
+	order.Data.ID = fmt.Sprintf("ORDER%d", f.orders)
+	if f.price.Total != "" {
+		offer.Price = f.price
+	}
+	order.Data.FlightOffers = []amadeus.FlightOffer{offer}
+	var travelers []int64
+	for i, u := range users {
+		order.TravelerUserIDs[fmt.Sprint(i+1)] = u.Id
+		travelers = append(travelers, u.Id)
+	}
+	booking, err := orm.CreateBooking(f.db, order.Data.ID, &pb.Flight{CarrierCode: "BA", FlightNumber: "178"}, travelers)
+	if err != nil {
+		return nil, err
+	}
+	order.BookingID = booking.ID
+	return order, nil
+}
+
+func TestBookingTracker_BookFlight(t *testing.T) {
+	bt, _ := setupBookingTracker(t, nil)
+	assert.NoError(t, bt.DB.AutoMigrate(&orm.User{}))
+	booker := &fakeBooker{db: bt.DB}
+	mail := &sentMail{}
+	bt.Booker = booker
+	bt.Mailer = &EmailNotifier{DB: bt.DB, Addr: "smtp.example.com:25", From: "trips@example.com", send: mail.send}
+
+	ada := &pb.User{FullName: "Ada Lovelace", Email: "ada@example.com"}
+	alan := &pb.User{FullName: "Alan Turing", Email: "alan@example.com"}
+	assert.NoError(t, orm.CreateUser(bt.DB, ada))
+	assert.NoError(t, orm.CreateUser(bt.DB, alan))
+	users := []*pb.User{ada, alan}
+
+	offer := bookedOffer("2030-06-01T09:30:00", "2030-06-01T21:30:00")
+	offer.Price = amadeus.Price{Currency: "EUR", Total: "700.00"}
+	offer.TravelerPricings = []amadeus.TravelerPricing{
+		{TravelerID: "1", Price: amadeus.Price{Currency: "EUR", Total: "450.00"}},
+		{TravelerID: "2", Price: amadeus.Price{Currency: "EUR", Total: "250.00"}},
+	}
+
+	// Each traveler owes their own fare, and the split is stored against the booking
+	split := &pb.PaymentSplit{Mode: pb.SplitMode_SPLIT_MODE_BY_TRAVELER}
+	booking, err := bt.BookFlight(context.Background(), offer, users, split)
+	if !assert.NoError(t, err) {
+		return
+	}
+	want := []string{fmt.Sprintf("%d:450.00", ada.Id), fmt.Sprintf("%d:250.00", alan.Id)}
+	assert.Equal(t, want, shareAmounts(booking.Shares))
+	stored, err := orm.BookingPayments(bt.DB, booking.Order.BookingID)
+	assert.NoError(t, err)
+	assert.Equal(t, want, shareAmounts(stored))
+
+	// The emailed summary lists each traveler's share
+	assert.Contains(t, booking.Summary, "- Ada Lovelace: 450.00 EUR\n")
+	assert.Contains(t, booking.Summary, "- Alan Turing: 250.00 EUR\n")
+	if assert.Equal(t, 1, mail.count()) {
+		assert.Equal(t, []string{"ada@example.com", "alan@example.com"}, mail.to[0])
+		assert.Contains(t, mail.msgs[0], "Subject: Booking ORDER1 confirmed")
+		assert.Contains(t, mail.msgs[0], "- Alan Turing: 250.00 EUR\r\n")
+	}
+
+	// An invalid split is rejected before anything is booked
+	_, err = bt.BookFlight(context.Background(), offer, users, &pb.PaymentSplit{Mode: pb.SplitMode_SPLIT_MODE_EQUAL})
+	assert.ErrorIs(t, err, ErrInvalidSplit)
+	assert.Equal(t, 1, booker.orders)
+
+	// So is a per-traveler split with a traveler no user can be charged for
+	guest := &pb.User{FullName: "Grace Hopper"}
+	_, err = bt.BookFlight(context.Background(), offer, []*pb.User{ada, guest}, split)
+	assert.ErrorIs(t, err, ErrInvalidSplit)
+	assert.Equal(t, 1, booker.orders)
+
+	// Without a split the order is booked as is
+	booking, err = bt.BookFlight(context.Background(), offer, users, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, booking.Shares)
+	assert.NotContains(t, booking.Summary, "Shares:")
+}
+
+// forgetfulBooker places orders without storing them, like a provider client with no DB
+type forgetfulBooker struct{}
+
+func (forgetfulBooker) BookFlight(ctx context.Context, offer amadeus.FlightOffer, users []*pb.User) (*amadeus.FlightOrderResponse, error) {
+	order := &amadeus.FlightOrderResponse{TravelerUserIDs: map[string]int64{"1": users[0].Id}}
+	order.Data.ID = "ORDER42"
+	return order, nil
+}
+
+func TestBookingTracker_BookFlight_RecordsBookingAndPayment(t *testing.T) {
+	bt, _ := setupBookingTracker(t, nil)
+	assert.NoError(t, bt.DB.AutoMigrate(&orm.User{}))
+	ada := &pb.User{FullName: "Ada Lovelace", Email: "ada@example.com"}
+	assert.NoError(t, orm.CreateUser(bt.DB, ada))
+
+	offer := bookedOffer("2030-06-01T09:30:00", "2030-06-01T21:30:00")
+	offer.Price = amadeus.Price{Currency: "EUR", Total: "450.00"}
+
+	for _, booker := range []FlightBooker{&fakeBooker{db: bt.DB}, forgetfulBooker{}} {
+		bt.Booker = booker
+		booking, err := bt.BookFlight(context.Background(), offer, []*pb.User{ada}, nil)
+		if !assert.NoError(t, err) || !assert.NotZero(t, booking.Order.BookingID, "the order is stored") {
+			continue
+		}
+
+		stored, err := orm.GetBooking(bt.DB, booking.Order.BookingID)
+		if assert.NoError(t, err) {
+			assert.Equal(t, booking.Order.Data.ID, stored.Reference)
+			assert.Equal(t, pb.BookingStatus_BOOKING_STATUS_PENDING, stored.Status)
+			assert.Equal(t, []int64{ada.Id}, stored.Travelers)
+		}
+
+		// The whole total is one pending payment by the booker
+		payments, err := orm.BookingPayments(bt.DB, booking.Order.BookingID)
+		if assert.NoError(t, err) && assert.Len(t, payments, 1) {
+			assert.Equal(t, ada.Id, payments[0].UserId)
+			assert.Equal(t, "450.00", payments[0].Amount)
+			assert.Equal(t, "EUR", payments[0].Currency)
+			assert.Equal(t, orm.PaymentStatusPending, payments[0].Status)
+		}
+	}
+}
+
+func TestBookingTracker_BookFlight_ChargesOrderPrice(t *testing.T) {
+	bt, _ := setupBookingTracker(t, nil)
+	assert.NoError(t, bt.DB.AutoMigrate(&orm.User{}))
+	ada := &pb.User{FullName: "Ada Lovelace", Email: "ada@example.com"}
+	alan := &pb.User{FullName: "Alan Turing", Email: "alan@example.com"}
+	assert.NoError(t, orm.CreateUser(bt.DB, ada))
+	assert.NoError(t, orm.CreateUser(bt.DB, alan))
+
+	// The fare went up between the search and the order
+	bt.Booker = &fakeBooker{db: bt.DB, price: amadeus.Price{Currency: "EUR", Total: "760.00"}}
+	offer := bookedOffer("2030-06-01T09:30:00", "2030-06-01T21:30:00")
+	offer.Price = amadeus.Price{Currency: "EUR", Total: "700.00"}
+
+	split := &pb.PaymentSplit{Mode: pb.SplitMode_SPLIT_MODE_EQUAL, UserIds: []int64{ada.Id, alan.Id}}
+	booking, err := bt.BookFlight(context.Background(), offer, []*pb.User{ada, alan}, split)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{fmt.Sprintf("%d:380.00", ada.Id), fmt.Sprintf("%d:380.00", alan.Id)}, shareAmounts(booking.Shares))
+		assert.Contains(t, booking.Summary, "Total: 760.00 EUR\n")
+	}
+
+	booking, err = bt.BookFlight(context.Background(), offer, []*pb.User{ada}, nil)
+	if assert.NoError(t, err) {
+		payments, err := orm.BookingPayments(bt.DB, booking.Order.BookingID)
+		if assert.NoError(t, err) && assert.Len(t, payments, 1) {
+			assert.Equal(t, "760.00", payments[0].Amount)
+		}
+	}
+}
+
+func TestOrderFares_InfantChargedToAdult(t *testing.T) {
+	order := &amadeus.FlightOrderResponse{TravelerUserIDs: map[string]int64{"1": 7, "2": 0}}
+	offer := amadeus.FlightOffer{TravelerPricings: []amadeus.TravelerPricing{
+		{TravelerID: "1", Price: amadeus.Price{Currency: "EUR", Total: "400.00"}},
+		{TravelerID: "2", AssociatedAdultID: "1", Price: amadeus.Price{Currency: "EUR", Total: "40.00"}},
+	}}
+
+	shares, err := SplitPayment(&pb.PaymentSplit{Mode: pb.SplitMode_SPLIT_MODE_BY_TRAVELER}, "440.00", "EUR", orderFares(order, offer))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"7:440.00"}, shareAmounts(shares))
+}
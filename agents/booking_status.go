@@ -0,0 +1,306 @@
+package agents
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tmcontext "github.com/va6996/travelingman/context"
+	"github.com/va6996/travelingman/log"
+	"github.com/va6996/travelingman/orm"
+	"github.com/va6996/travelingman/pb"
+	"github.com/va6996/travelingman/plugins/amadeus"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+// BookingTracker keeps stored bookings in sync with the provider's orders
+type BookingTracker struct {
+	Orders FlightOrderSource
+	DB     *gorm.DB
+
+	// Notifier, if set, is told about schedule changes and cancellations
+	Notifier BookingNotifier
+	// Booker places the orders booked with BookFlight
+	Booker FlightBooker
+	// Mailer, if set, emails BookFlight's confirmation to the travelers
+	Mailer *EmailNotifier
+	// Hotels books the stays of itineraries booked with ResumeBooking
+	Hotels HotelBooker
+
+	// plans holds a *sync.Mutex per saved plan; see LockPlan
+	plans sync.Map
+}
+
+// NewBookingTracker creates a tracker for the bookings in db
+func NewBookingTracker(orders FlightOrderSource, db *gorm.DB) *BookingTracker {
+	return &BookingTracker{Orders: orders, DB: db}
+}
+
+// Refresh fetches a booking's order from the provider and records the result: a
+// missing order is CANCELLED, a flight that differs from the stored one is CHANGED,
+// and otherwise the order is TICKETED or CONFIRMED. CHANGED is kept until the booking
+// is cancelled so that a change is not lost to the next refresh. The returned change
+// has From equal to To when nothing moved; such refreshes are not recorded.
+func (bt *BookingTracker) Refresh(ctx context.Context, id uint) (*pb.BookingStatusChange, error) {
+	b, err := orm.GetBooking(bt.DB, id)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := b.BookedFlight()
+	if err != nil {
+		return nil, fmt.Errorf("booking %d: %w", id, err)
+	}
+
+	change := &pb.BookingStatusChange{BookingId: int64(b.ID), From: b.Status, ChangedAt: timestamppb.New(tmcontext.Now(ctx))}
+	current := stored
+	order, err := bt.Orders.GetFlightOrder(ctx, b.Reference)
+	switch {
+	case errors.Is(err, amadeus.ErrOrderNotFound):
+		change.To = pb.BookingStatus_BOOKING_STATUS_CANCELLED
+	case err != nil:
+		return nil, err
+	default:
+		if flight := order.BookedFlight(); flight != nil {
+			current = flight
+			change.Changes = diffFlight(stored, flight)
+		}
+		switch {
+		case len(change.Changes) > 0 || b.Status == pb.BookingStatus_BOOKING_STATUS_CHANGED:
+			change.To = pb.BookingStatus_BOOKING_STATUS_CHANGED
+		case order.Ticketed():
+			change.To = pb.BookingStatus_BOOKING_STATUS_TICKETED
+		default:
+			change.To = pb.BookingStatus_BOOKING_STATUS_CONFIRMED
+		}
+	}
+
+	if change.From == change.To && len(change.Changes) == 0 {
+		return change, nil
+	}
+	if err := orm.RecordBookingStatus(bt.DB, b, change, current); err != nil {
+		return nil, err
+	}
+	log.Infof(ctx, "BookingTracker: booking %d moved from %s to %s with %d changes", b.ID, change.From, change.To, len(change.Changes))
+
+	if bt.Notifier != nil && (len(change.Changes) > 0 || change.To == pb.BookingStatus_BOOKING_STATUS_CANCELLED) {
+		if err := bt.Notifier.NotifyBookingChange(ctx, change); err != nil {
+			log.Errorf(ctx, "BookingTracker: failed to notify about booking %d: %v", b.ID, err)
+		}
+	}
+	return change, nil
+}
+
+// Poll refreshes all upcoming bookings every interval until ctx is done
+func (bt *BookingTracker) Poll(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		bt.refreshUpcoming(ctx)
+	}
+}
+
+// refreshUpcoming refreshes every booking that has not departed yet
+func (bt *BookingTracker) refreshUpcoming(ctx context.Context) {
+	bookings, err := orm.UpcomingBookings(bt.DB, tmcontext.Now(ctx))
+	if err != nil {
+		log.Errorf(ctx, "BookingTracker: failed to list upcoming bookings: %v", err)
+		return
+	}
+	for _, b := range bookings {
+		if _, err := bt.Refresh(ctx, b.ID); err != nil {
+			log.Errorf(ctx, "BookingTracker: failed to refresh booking %d: %v", b.ID, err)
+		}
+	}
+}
+
+// diffFlight lists the schedule differences between a stored flight and the provider's
+// current one, segment by segment. A flight without segments is compared as a whole.
+func diffFlight(old, cur *pb.Flight) []*pb.FlightChange {
+	oldSegs, curSegs := flightSegments(old), flightSegments(cur)
+
+	var changes []*pb.FlightChange
+	if len(oldSegs) != len(curSegs) {
+		changes = append(changes, &pb.FlightChange{
+			Segment:  -1,
+			Field:    "segments",
+			OldValue: strconv.Itoa(len(oldSegs)),
+			NewValue: strconv.Itoa(len(curSegs)),
+		})
+	}
+	for i := 0; i < len(oldSegs) && i < len(curSegs); i++ {
+		o, c := oldSegs[i], curSegs[i]
+		for _, f := range []struct {
+			field    string
+			old, cur string
+		}{
+			{"flight_number", o.CarrierCode + o.FlightNumber, c.CarrierCode + c.FlightNumber},
+			{"departure_airport", o.DepartureAirportCode, c.DepartureAirportCode},
+			{"arrival_airport", o.ArrivalAirportCode, c.ArrivalAirportCode},
+			{"departure_time", formatChangeTime(o.DepartureTime), formatChangeTime(c.DepartureTime)},
+			{"arrival_time", formatChangeTime(o.ArrivalTime), formatChangeTime(c.ArrivalTime)},
+			{"departure_terminal", o.DepartureTerminal, c.DepartureTerminal},
+			{"arrival_terminal", o.ArrivalTerminal, c.ArrivalTerminal},
+		} {
+			if f.old != f.cur {
+				changes = append(changes, &pb.FlightChange{Segment: int32(i), Field: f.field, OldValue: f.old, NewValue: f.cur})
+			}
+		}
+	}
+	return changes
+}
+
+// flightSegments returns a flight's segments, or the flight itself as one segment
+func flightSegments(f *pb.Flight) []*pb.FlightSegment {
+	if len(f.GetSegments()) > 0 {
+		return f.Segments
+	}
+	if f == nil {
+		return nil
+	}
+	return []*pb.FlightSegment{{
+		CarrierCode:   f.CarrierCode,
+		FlightNumber:  f.FlightNumber,
+		DepartureTime: f.DepartureTime,
+		ArrivalTime:   f.ArrivalTime,
+	}}
+}
+
+func formatChangeTime(ts *timestamppb.Timestamp) string {
+	if ts == nil {
+		return ""
+	}
+	return ts.AsTime().Format(time.RFC3339)
+}
+
+// WebhookNotifier posts booking changes as JSON to a URL
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+// NotifyBookingChange posts the change and fails on a non-2xx response
+func (n *WebhookNotifier) NotifyBookingChange(ctx context.Context, change *pb.BookingStatusChange) error {
+	body, err := protojson.Marshal(change)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := n.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// BookingNotifiers tells each notifier in turn; one failing does not stop the others
+type BookingNotifiers []BookingNotifier
+
+// NotifyBookingChange notifies every notifier and returns their errors joined
+func (ns BookingNotifiers) NotifyBookingChange(ctx context.Context, change *pb.BookingStatusChange) error {
+	var errs []error
+	for _, n := range ns {
+		errs = append(errs, n.NotifyBookingChange(ctx, change))
+	}
+	return errors.Join(errs...)
+}
+
+// EmailNotifier emails booking changes to the travelers on the booking
+type EmailNotifier struct {
+	DB   *gorm.DB
+	Addr string // SMTP server as host:port
+	From string
+	Auth smtp.Auth // Nil sends without authenticating
+
+	// send delivers a message; nil uses smtp.SendMail
+	send func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NotifyBookingChange emails the change to every traveler with an email address. A
+// booking without any is skipped.
+func (n *EmailNotifier) NotifyBookingChange(ctx context.Context, change *pb.BookingStatusChange) error {
+	b, err := orm.GetBooking(n.DB, uint(change.BookingId))
+	if err != nil {
+		return err
+	}
+	to := n.travelerEmails(ctx, b.Travelers)
+	if len(to) == 0 {
+		log.Debugf(ctx, "EmailNotifier: booking %d has no traveler emails, skipping", b.ID)
+		return nil
+	}
+
+	var subject string
+	var body strings.Builder
+	if change.To == pb.BookingStatus_BOOKING_STATUS_CANCELLED {
+		subject = fmt.Sprintf("Booking %s was cancelled", b.Reference)
+		fmt.Fprintf(&body, "Your booking %s was cancelled by the airline.\n", b.Reference)
+	} else {
+		subject = fmt.Sprintf("Schedule change for booking %s", b.Reference)
+		fmt.Fprintf(&body, "The airline changed your booking %s:\n", b.Reference)
+	}
+	for _, c := range change.Changes {
+		field := strings.ReplaceAll(c.Field, "_", " ")
+		if c.Segment >= 0 {
+			fmt.Fprintf(&body, "- Flight %d %s: %s -> %s\n", c.Segment+1, field, c.OldValue, c.NewValue)
+		} else {
+			fmt.Fprintf(&body, "- %s: %s -> %s\n", field, c.OldValue, c.NewValue)
+		}
+	}
+	return n.Send(to, subject, body.String())
+}
+
+// Send emails a plain-text message
+func (n *EmailNotifier) Send(to []string, subject, body string) error {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\nTo: %s\r\nSubject: %s\r\n", n.From, strings.Join(to, ", "), subject)
+	msg.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	send := n.send
+	if send == nil {
+		send = smtp.SendMail
+	}
+	return send(n.Addr, n.Auth, n.From, to, msg.Bytes())
+}
+
+// travelerEmails returns the email addresses of the users, skipping unknown users
+func (n *EmailNotifier) travelerEmails(ctx context.Context, users []int64) []string {
+	var emails []string
+	for _, id := range users {
+		user, err := orm.GetUser(n.DB, uint(id))
+		if err != nil {
+			log.Warnf(ctx, "EmailNotifier: traveler %d not found: %v", id, err)
+			continue
+		}
+		if user.Email != "" {
+			emails = append(emails, user.Email)
+		}
+	}
+	return emails
+}
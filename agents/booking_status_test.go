@@ -0,0 +1,325 @@
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	tmcontext "github.com/va6996/travelingman/context"
+	"github.com/va6996/travelingman/orm"
+	"github.com/va6996/travelingman/pb"
+	"github.com/va6996/travelingman/plugins/amadeus"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// fakeNotifier records the changes it is told about
+type fakeNotifier struct {
+	changes []*pb.BookingStatusChange
+}
+
+func (f *fakeNotifier) NotifyBookingChange(ctx context.Context, change *pb.BookingStatusChange) error {
+	f.changes = append(f.changes, change)
+	return nil
+}
+
+// bookedOffer is a one-segment JFK-LHR offer as the order endpoint returns it
+func bookedOffer(departure, arrival string) amadeus.FlightOffer {
+	seg := amadeus.Segment{CarrierCode: "BA", Number: "178"}
+	seg.Departure = amadeus.FlightEndPoint{IataCode: "JFK", At: departure}
+	seg.Arrival = amadeus.FlightEndPoint{IataCode: "LHR", At: arrival, Terminal: "5"}
+	return amadeus.FlightOffer{ID: "1", Itineraries: []amadeus.Itinerary{{Segments: []amadeus.Segment{seg}}}}
+}
+
+// flightChanges formats changes as "segment field: old -> new"
+func flightChanges(changes []*pb.FlightChange) []string {
+	var out []string
+	for _, c := range changes {
+		out = append(out, fmt.Sprintf("%d %s: %s -> %s", c.Segment, c.Field, c.OldValue, c.NewValue))
+	}
+	return out
+}
+
+func setupBookingTracker(t *testing.T, orders map[string]*amadeus.FlightOrderResponse) (*BookingTracker, *fakeNotifier) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/security/oauth2/token" {
+			json.NewEncoder(w).Encode(amadeus.AuthToken{AccessToken: "test_token", ExpiresIn: 1800})
+			return
+		}
+		order, ok := orders[r.URL.Path[len("/v1/booking/flight-orders/"):]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(order)
+	}))
+	t.Cleanup(ts.Close)
+
+	client, err := amadeus.NewClient(amadeus.Config{
+		ClientID: "id", ClientSecret: "secret",
+		FlightLimit: 10, HotelLimit: 10, Timeout: 10,
+		CacheTTL: amadeus.CacheTTLConfig{Location: 24, Flight: 24, Hotel: 24},
+	}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	client.BaseURL = ts.URL
+
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	assert.NoError(t, db.AutoMigrate(&orm.Booking{}, &orm.BookingStatusChange{}, &orm.Payment{}))
+
+	notifier := &fakeNotifier{}
+	bt := NewBookingTracker(client, db)
+	bt.Notifier = notifier
+	return bt, notifier
+}
+
+func TestBookingTracker_Refresh(t *testing.T) {
+	stored := bookedOffer("2026-06-01T09:30:00", "2026-06-01T21:30:00").ToTransport(nil).GetFlight()
+
+	// The airline moved the flight by an hour and a half
+	moved := &amadeus.FlightOrderResponse{}
+	moved.Data.FlightOffers = []amadeus.FlightOffer{bookedOffer("2026-06-01T11:00:00", "2026-06-01T23:00:00")}
+	ticketed := &amadeus.FlightOrderResponse{}
+	ticketed.Data.FlightOffers = []amadeus.FlightOffer{bookedOffer("2026-06-01T09:30:00", "2026-06-01T21:30:00")}
+	ticketed.Data.Tickets = []amadeus.Ticket{{DocumentType: "ETICKET", DocumentStatus: "ISSUED", TravelerId: "1"}}
+	orders := map[string]*amadeus.FlightOrderResponse{"ORDER1": moved, "ORDER2": ticketed}
+
+	bt, notifier := setupBookingTracker(t, orders)
+	now := time.Date(2026, 5, 20, 8, 0, 0, 0, time.UTC)
+	ctx := tmcontext.WithClock(context.Background(), tmcontext.FixedClock(now))
+	changed, err := orm.CreateBooking(bt.DB, "ORDER1", stored, nil)
+	assert.NoError(t, err)
+	unchanged, err := orm.CreateBooking(bt.DB, "ORDER2", stored, nil)
+	assert.NoError(t, err)
+
+	// The time change is reported field by field
+	change, err := bt.Refresh(ctx, changed.ID)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, pb.BookingStatus_BOOKING_STATUS_PENDING, change.From)
+	assert.Equal(t, pb.BookingStatus_BOOKING_STATUS_CHANGED, change.To)
+	assert.Equal(t, now, change.ChangedAt.AsTime(), "stamped by the request's clock")
+	assert.Equal(t, []string{
+		"0 departure_time: 2026-06-01T09:30:00Z -> 2026-06-01T11:00:00Z",
+		"0 arrival_time: 2026-06-01T21:30:00Z -> 2026-06-01T23:00:00Z",
+	}, flightChanges(change.Changes))
+	assert.Len(t, notifier.changes, 1)
+
+	// The new schedule is stored, so refreshing again finds nothing new
+	again, err := bt.Refresh(ctx, changed.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, pb.BookingStatus_BOOKING_STATUS_CHANGED, again.To)
+	assert.Empty(t, again.Changes)
+	assert.Len(t, notifier.changes, 1)
+
+	// The airline cancels
+	delete(orders, "ORDER1")
+	cancelled, err := bt.Refresh(ctx, changed.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, pb.BookingStatus_BOOKING_STATUS_CANCELLED, cancelled.To)
+	if assert.Len(t, notifier.changes, 2) {
+		assert.Equal(t, pb.BookingStatus_BOOKING_STATUS_CANCELLED, notifier.changes[1].To)
+	}
+
+	history, err := orm.BookingHistory(bt.DB, changed.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, history, 2) {
+		assert.Equal(t, pb.BookingStatus_BOOKING_STATUS_PENDING, history[0].From)
+		assert.Equal(t, pb.BookingStatus_BOOKING_STATUS_CHANGED, history[0].To)
+		assert.Len(t, history[0].Changes, 2)
+		assert.Equal(t, pb.BookingStatus_BOOKING_STATUS_CHANGED, history[1].From)
+		assert.Equal(t, pb.BookingStatus_BOOKING_STATUS_CANCELLED, history[1].To)
+	}
+
+	// An unchanged, ticketed order only moves the status and is not notified
+	change, err = bt.Refresh(ctx, unchanged.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, pb.BookingStatus_BOOKING_STATUS_TICKETED, change.To)
+	assert.Empty(t, change.Changes)
+	assert.Len(t, notifier.changes, 2)
+
+	_, err = bt.Refresh(ctx, 999)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestDiffFlight(t *testing.T) {
+	direct := bookedOffer("2026-06-01T09:30:00", "2026-06-01T21:30:00").ToTransport(nil).GetFlight()
+
+	// A rerouting through Dublin changes the segment count and the first segment's arrival
+	rerouted := proto.Clone(direct).(*pb.Flight)
+	rerouted.Segments[0].ArrivalAirportCode = "DUB"
+	rerouted.Segments[0].ArrivalTerminal = ""
+	rerouted.Segments = append(rerouted.Segments, &pb.FlightSegment{CarrierCode: "EI", FlightNumber: "152", DepartureAirportCode: "DUB", ArrivalAirportCode: "LHR"})
+
+	assert.Equal(t, []string{
+		"-1 segments: 1 -> 2",
+		"0 arrival_airport: LHR -> DUB",
+		"0 arrival_terminal: 5 -> ",
+	}, flightChanges(diffFlight(direct, rerouted)))
+
+	assert.Empty(t, diffFlight(direct, direct))
+
+	// Flights without segments are compared as a whole
+	assert.Equal(t, []string{"0 flight_number: BA178 -> BA176"},
+		flightChanges(diffFlight(&pb.Flight{CarrierCode: "BA", FlightNumber: "178"}, &pb.Flight{CarrierCode: "BA", FlightNumber: "176"})))
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var calls atomic.Int32
+	var got pb.BookingStatusChange
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		assert.NoError(t, protojson.Unmarshal(body, &got))
+		if got.BookingId == 2 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer ts.Close()
+
+	n := &WebhookNotifier{URL: ts.URL}
+	change := &pb.BookingStatusChange{BookingId: 1, To: pb.BookingStatus_BOOKING_STATUS_CANCELLED}
+	assert.NoError(t, n.NotifyBookingChange(context.Background(), change))
+	assert.Equal(t, int64(1), got.BookingId)
+	assert.Equal(t, pb.BookingStatus_BOOKING_STATUS_CANCELLED, got.To)
+
+	assert.Error(t, n.NotifyBookingChange(context.Background(), &pb.BookingStatusChange{BookingId: 2}))
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+// sentMail records the messages an EmailNotifier sends
+type sentMail struct {
+	mu   sync.Mutex
+	to   [][]string
+	msgs []string
+}
+
+func (m *sentMail) send(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.to = append(m.to, to)
+	m.msgs = append(m.msgs, string(msg))
+	return nil
+}
+
+func (m *sentMail) count() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.msgs)
+}
+
+func TestBookingTracker_BookThenPoll(t *testing.T) {
+	departure := time.Now().AddDate(0, 1, 0).Truncate(24 * time.Hour).Add(9*time.Hour + 30*time.Minute)
+	at := func(d time.Time) string { return d.Format("2006-01-02T15:04:05") }
+	booked := bookedOffer(at(departure), at(departure.Add(12*time.Hour)))
+
+	// The order is placed as offered, then the airline moves it by an hour and a half
+	var moved atomic.Bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		order := &amadeus.FlightOrderResponse{}
+		order.Data.ID = "ORDER9"
+		order.Data.FlightOffers = []amadeus.FlightOffer{booked}
+		switch {
+		case r.URL.Path == "/v1/security/oauth2/token":
+			json.NewEncoder(w).Encode(amadeus.AuthToken{AccessToken: "test_token", ExpiresIn: 1800})
+			return
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/booking/flight-orders":
+			w.WriteHeader(http.StatusCreated)
+		case r.URL.Path == "/v1/booking/flight-orders/ORDER9" && moved.Load():
+			later := departure.Add(90 * time.Minute)
+			order.Data.FlightOffers = []amadeus.FlightOffer{bookedOffer(at(later), at(later.Add(12*time.Hour)))}
+		}
+		json.NewEncoder(w).Encode(order)
+	}))
+	defer ts.Close()
+
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	assert.NoError(t, db.AutoMigrate(&orm.User{}, &orm.Booking{}, &orm.BookingStatusChange{}))
+
+	client, err := amadeus.NewClient(amadeus.Config{
+		ClientID: "id", ClientSecret: "secret",
+		FlightLimit: 10, HotelLimit: 10, Timeout: 10,
+		CacheTTL: amadeus.CacheTTLConfig{Location: 24, Flight: 24, Hotel: 24},
+	}, nil, nil, db)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	client.BaseURL = ts.URL
+
+	traveler := &pb.User{
+		FullName:    "Ada Lovelace",
+		Email:       "ada@example.com",
+		Gender:      "FEMALE",
+		Phone:       "1234567890",
+		DateOfBirth: timestamppb.New(time.Date(1990, 12, 10, 0, 0, 0, 0, time.UTC)),
+	}
+	assert.NoError(t, orm.CreateUser(db, traveler))
+
+	// Booking stores the order with its travelers and segments
+	order, err := client.BookFlight(context.Background(), booked, []*pb.User{traveler})
+	if !assert.NoError(t, err) || !assert.NotZero(t, order.BookingID) {
+		return
+	}
+	stored, err := orm.GetBooking(db, order.BookingID)
+	assert.NoError(t, err)
+	assert.Equal(t, "ORDER9", stored.Reference)
+	assert.Equal(t, []int64{traveler.Id}, stored.Travelers)
+	flight, err := stored.BookedFlight()
+	assert.NoError(t, err)
+	assert.Len(t, flight.GetSegments(), 1)
+
+	mail := &sentMail{}
+	bt := NewBookingTracker(client, db)
+	bt.Notifier = &EmailNotifier{DB: db, Addr: "smtp.example.com:25", From: "trips@example.com", send: mail.send}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	moved.Store(true)
+	go bt.Poll(ctx, 10*time.Millisecond)
+
+	// The poller finds the change, records the transition and emails the traveler
+	assert.Eventually(t, func() bool { return mail.count() > 0 }, 5*time.Second, 10*time.Millisecond)
+	cancel()
+
+	history, err := orm.BookingHistory(db, order.BookingID)
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, history) {
+		assert.Equal(t, pb.BookingStatus_BOOKING_STATUS_PENDING, history[0].From)
+		assert.Equal(t, pb.BookingStatus_BOOKING_STATUS_CHANGED, history[0].To)
+		assert.Len(t, history[0].Changes, 2)
+	}
+	mail.mu.Lock()
+	defer mail.mu.Unlock()
+	assert.Equal(t, []string{"ada@example.com"}, mail.to[0])
+	assert.Contains(t, mail.msgs[0], "Subject: Schedule change for booking ORDER9")
+	assert.True(t, strings.Contains(mail.msgs[0], "- Flight 1 departure time: "), mail.msgs[0])
+}
+
+func TestBookingNotifiers(t *testing.T) {
+	first, second := &fakeNotifier{}, &fakeNotifier{}
+	change := &pb.BookingStatusChange{BookingId: 1, To: pb.BookingStatus_BOOKING_STATUS_CANCELLED}
+	assert.NoError(t, BookingNotifiers{first, second}.NotifyBookingChange(context.Background(), change))
+	assert.Len(t, first.changes, 1)
+	assert.Len(t, second.changes, 1)
+}
@@ -0,0 +1,26 @@
+package agents
+
+import (
+	"github.com/va6996/travelingman/agents/options"
+	"github.com/va6996/travelingman/pb"
+)
+
+// TagBreakfastIncluded marks stays whose rate includes breakfast
+const TagBreakfastIncluded = "Breakfast Included"
+
+// DefaultBreakfastValue is the estimated cost of breakfast per traveller per night
+const DefaultBreakfastValue = 15.0
+
+// breakfastCost is what a traveller who wants breakfast would pay for it on top of a
+// stay that does not include it, for all travellers and nights
+func (ta *TravelAgent) breakfastCost(wanted bool, s *pb.Accommodation) float64 {
+	if !wanted || s.BreakfastIncluded || ta.BreakfastValue <= 0 || s.CheckIn == nil || s.CheckOut == nil {
+		return 0
+	}
+	travelers := s.TravelerCount
+	if travelers <= 0 {
+		travelers = 1
+	}
+	nights := options.Nights(s.CheckIn.AsTime(), s.CheckOut.AsTime())
+	return ta.BreakfastValue * float64(nights) * float64(travelers)
+}
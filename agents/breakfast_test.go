@@ -0,0 +1,72 @@
+package agents
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// breakfastItinerary is a three-night stay for two with a room-only and a slightly
+// pricier breakfast-included offer
+func breakfastItinerary(wantsBreakfast bool) *pb.Itinerary {
+	checkIn := time.Date(2026, 6, 1, 15, 0, 0, 0, time.UTC)
+	offer := func(name string, price float64, breakfast bool) *pb.Accommodation {
+		return &pb.Accommodation{
+			Name:              name,
+			HotelId:           name,
+			TravelerCount:     2,
+			CheckIn:           timestamppb.New(checkIn),
+			CheckOut:          timestamppb.New(checkIn.AddDate(0, 0, 3)),
+			Cost:              &pb.Cost{Value: price, Currency: "EUR"},
+			BreakfastIncluded: breakfast,
+		}
+	}
+	return &pb.Itinerary{Graph: &pb.Graph{Nodes: []*pb.Node{{
+		Id:   "lisbon",
+		Stay: &pb.Accommodation{Preferences: &pb.AccommodationPreferences{Breakfast: wantsBreakfast}},
+		StayOptions: []*pb.Accommodation{
+			offer("Room Only", 400, false),
+			offer("With Breakfast", 450, true),
+		},
+	}}}}
+}
+
+func TestTravelAgent_ScoreAndTag_Breakfast(t *testing.T) {
+	t.Run("Wanted", func(t *testing.T) {
+		it := breakfastItinerary(true)
+		NewTravelAgent(nil, nil).scoreAndTag([]*pb.Itinerary{it})
+
+		// 400 plus 2 travellers x 3 nights x 15 for breakfast is more than 450
+		node := it.Graph.Nodes[0]
+		assert.Equal(t, "With Breakfast", node.Stay.Name)
+		assert.ElementsMatch(t, []string{TagBreakfastIncluded, "Best Value"}, node.Stay.Tags)
+		assert.Equal(t, []string{"Cheapest"}, node.StayOptions[1].Tags, "the cheapest is still the room-only rate")
+		assert.Equal(t, 450.0, calculateItineraryScore(it), "totals are actual prices")
+	})
+
+	t.Run("NotWanted", func(t *testing.T) {
+		it := breakfastItinerary(false)
+		NewTravelAgent(nil, nil).scoreAndTag([]*pb.Itinerary{it})
+		assert.Equal(t, "Room Only", it.Graph.Nodes[0].Stay.Name)
+		assert.Contains(t, it.Graph.Nodes[0].StayOptions[1].Tags, TagBreakfastIncluded)
+	})
+
+	t.Run("NoEstimate", func(t *testing.T) {
+		it := breakfastItinerary(true)
+		agent := NewTravelAgent(nil, nil)
+		agent.BreakfastValue = 0
+		agent.scoreAndTag([]*pb.Itinerary{it})
+		assert.Equal(t, "Room Only", it.Graph.Nodes[0].Stay.Name)
+	})
+
+	t.Run("SmallerEstimate", func(t *testing.T) {
+		it := breakfastItinerary(true)
+		agent := NewTravelAgent(nil, nil)
+		agent.BreakfastValue = 5
+		agent.scoreAndTag([]*pb.Itinerary{it})
+		assert.Equal(t, "Room Only", it.Graph.Nodes[0].Stay.Name, "30 for breakfast does not make up a 50 difference")
+	})
+}
@@ -0,0 +1,332 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/va6996/travelingman/agents/options"
+	"github.com/va6996/travelingman/log"
+	"github.com/va6996/travelingman/pb"
+)
+
+// Budget split heuristics. The buffer share is held back for meals, transfers and
+// everything else; the rest is split in proportion to the typical cost of a flight leg
+// and of a hotel night at an average destination. Only the ratio of the weights matters.
+const (
+	budgetBufferShare = 0.10
+	flightLegWeight   = 250.0
+	hotelNightWeight  = 120.0
+)
+
+// destinationPriceIndex is the relative price level of hotels by IATA city code, 1
+// being average. Destinations not listed count as average.
+var destinationPriceIndex = map[string]float64{
+	"ZRH": 1.8, "GVA": 1.7, "NYC": 1.7, "SFO": 1.6, "REK": 1.6, "LON": 1.5,
+	"OSL": 1.5, "CPH": 1.4, "PAR": 1.4, "SIN": 1.4, "HKG": 1.4, "DXB": 1.3,
+	"AMS": 1.3, "TYO": 1.3, "SYD": 1.3, "ROM": 1.1, "BCN": 1.1, "MIL": 1.1,
+	"BER": 1.0, "MAD": 1.0, "VIE": 1.0, "LIS": 0.9, "PRG": 0.8, "ATH": 0.8,
+	"KRK": 0.7, "BUD": 0.7, "IST": 0.7, "MEX": 0.7, "LIM": 0.6, "BKK": 0.6,
+	"DEL": 0.5, "HAN": 0.5, "CAI": 0.5,
+}
+
+// BudgetAllocation is a suggested split of a total budget
+type BudgetAllocation struct {
+	Flights float64 // All flight legs, for all travellers
+	Hotels  float64 // All nights
+	Buffer  float64 // Held back for everything else
+}
+
+// AllocateBudget splits total across flights and hotels for a trip with the given
+// number of flight legs and hotel nights. priceIndex scales the hotel share for
+// expensive (> 1) or cheap (< 1) destinations; zero counts as average. Amounts are
+// whole units of the budget's currency and always add up to total.
+func AllocateBudget(total float64, legs, nights int, priceIndex float64) BudgetAllocation {
+	if total <= 0 {
+		return BudgetAllocation{}
+	}
+	if priceIndex <= 0 {
+		priceIndex = 1
+	}
+	total = math.Floor(total)
+
+	flightWeight := float64(legs) * flightLegWeight
+	hotelWeight := float64(nights) * hotelNightWeight * priceIndex
+	if flightWeight+hotelWeight == 0 {
+		return BudgetAllocation{Buffer: total}
+	}
+
+	buffer := math.Round(total * budgetBufferShare)
+	flights := math.Round((total - buffer) * flightWeight / (flightWeight + hotelWeight))
+	return BudgetAllocation{
+		Flights: flights,
+		Hotels:  total - buffer - flights,
+		Buffer:  buffer,
+	}
+}
+
+// tripShape counts the flight legs and hotel nights in a graph and its sub-graphs, and
+// returns the price index of the stays weighted by their nights
+func tripShape(g *pb.Graph) (legs, nights int, priceIndex float64) {
+	var weighted float64
+	var walk func(g *pb.Graph)
+	walk = func(g *pb.Graph) {
+		if g == nil {
+			return
+		}
+		for _, e := range g.Edges {
+			if e.GetTransport().GetType() == pb.TransportType_TRANSPORT_TYPE_FLIGHT {
+				legs++
+			}
+		}
+		for _, n := range g.Nodes {
+			if n.Stay != nil {
+				stayNights := options.Nights(n.Stay.CheckIn.AsTime(), n.Stay.CheckOut.AsTime())
+				nights += stayNights
+				weighted += float64(stayNights) * cityPriceIndex(n.Stay.GetLocation(), n.GetLocation())
+			}
+			walk(n.SubGraph)
+		}
+		walk(g.SubGraph)
+	}
+	walk(g)
+
+	if nights == 0 {
+		return legs, 0, 1
+	}
+	return legs, nights, weighted / float64(nights)
+}
+
+// cityPriceIndex looks up the first of the locations with a known city code
+func cityPriceIndex(locs ...*pb.Location) float64 {
+	for _, loc := range locs {
+		if index, ok := destinationPriceIndex[loc.GetCityCode()]; ok {
+			return index
+		}
+	}
+	return 1
+}
+
+// planBudget splits the itinerary's budget across its flights and hotels, records the
+// split and sets the per-traveller fare and per-night caps the searches use. Caps are
+// only set on costs in the budget's currency. Itineraries without a budget are left alone.
+func planBudget(ctx context.Context, it *pb.Itinerary) {
+	total, currency := it.GetBudget().GetValue(), it.GetBudget().GetCurrency()
+	if total <= 0 || it.Graph == nil {
+		return
+	}
+	if currency == "" {
+		currency = "USD"
+	}
+
+	legs, nights, priceIndex := tripShape(it.Graph)
+	a := AllocateBudget(total, legs, nights, priceIndex)
+	it.BudgetSplit = &pb.BudgetSplit{
+		Flights:    &pb.Cost{Value: a.Flights, Currency: currency},
+		Hotels:     &pb.Cost{Value: a.Hotels, Currency: currency},
+		Buffer:     &pb.Cost{Value: a.Buffer, Currency: currency},
+		FlightLegs: int32(legs),
+		Nights:     int32(nights),
+		PriceIndex: priceIndex,
+	}
+	log.Infof(ctx, "Budget: %s", formatBudgetSplit(it.Budget, it.BudgetSplit))
+
+	var perLeg, perNight float64
+	if legs > 0 {
+		perLeg = math.Floor(a.Flights / float64(legs))
+	}
+	if nights > 0 {
+		perNight = math.Floor(a.Hotels / float64(nights))
+	}
+
+	var walk func(g *pb.Graph)
+	walk = func(g *pb.Graph) {
+		if g == nil {
+			return
+		}
+		for _, e := range g.Edges {
+			t := e.Transport
+			if t.GetType() != pb.TransportType_TRANSPORT_TYPE_FLIGHT || perLeg == 0 || t.GetCost().GetCurrency() != currency {
+				continue
+			}
+			travelers := t.TravelerCount
+			if travelers <= 0 {
+				travelers = 1
+			}
+			if t.FlightPreferences == nil {
+				t.FlightPreferences = &pb.FlightPreferences{}
+			}
+			t.FlightPreferences.MaxPrice = math.Floor(perLeg / float64(travelers))
+		}
+		for _, n := range g.Nodes {
+			if s := n.Stay; s != nil && perNight > 0 && s.GetCost().GetCurrency() == currency {
+				if s.Preferences == nil {
+					s.Preferences = &pb.AccommodationPreferences{}
+				}
+				s.Preferences.MaxNightlyPrice = perNight
+			}
+			walk(n.SubGraph)
+		}
+		walk(g.SubGraph)
+	}
+	walk(it.Graph)
+}
+
+// rebalanceBudget compares the cheapest verified flights and hotels with the budget
+// split. When one goes over its share but cheaper options on the other side keep the
+// two within their combined share, the difference is moved across and a note explaining
+// it is recorded on the split and returned. It returns "" when nothing was moved.
+func rebalanceBudget(it *pb.Itinerary) string {
+	split := it.GetBudgetSplit()
+	if split == nil {
+		return ""
+	}
+	currency := split.GetFlights().GetCurrency()
+	flights, hotels, ok := cheapestCosts(it.Graph, currency)
+	if !ok {
+		return ""
+	}
+
+	flightShare, hotelShare := split.GetFlights().GetValue(), split.GetHotels().GetValue()
+	available := flightShare + hotelShare
+	if flights+hotels > available {
+		return ""
+	}
+
+	switch {
+	case flights > flightShare:
+		moved := math.Min(math.Ceil(flights), available-hotels)
+		split.Note = fmt.Sprintf("Flights cost at least %.2f %s, over the %.2f %s suggested; cheaper hotels make up the difference, so the hotel budget is now %.2f %s",
+			flights, currency, flightShare, currency, available-moved, currency)
+		split.Flights.Value, split.Hotels.Value = moved, available-moved
+	case hotels > hotelShare:
+		moved := math.Min(math.Ceil(hotels), available-flights)
+		split.Note = fmt.Sprintf("Hotels cost at least %.2f %s, over the %.2f %s suggested; cheaper flights make up the difference, so the flight budget is now %.2f %s",
+			hotels, currency, hotelShare, currency, available-moved, currency)
+		split.Flights.Value, split.Hotels.Value = available-moved, moved
+	default:
+		return ""
+	}
+	return split.Note
+}
+
+// BudgetConflict is the error for an itinerary whose cheapest verified options cost
+// more than its whole budget. Re-planning the same trip cannot fit the budget, so the
+// user is asked which of their constraints to relax.
+type BudgetConflict struct {
+	Budget   *pb.Cost
+	Cheapest float64  // The cheapest flights and stays together, in the budget's currency
+	Class    pb.Class // The premium cabin asked for on its flights, if any
+}
+
+func (e *BudgetConflict) Error() string {
+	return fmt.Sprintf("the cheapest options cost %.2f %s, over the budget of %.2f %s",
+		e.Cheapest, e.Budget.GetCurrency(), e.Budget.GetValue(), e.Budget.GetCurrency())
+}
+
+// Question asks the user whether to relax the budget or, for premium cabins, the class
+func (e *BudgetConflict) Question() string {
+	options := "Would you like to raise the budget, or change the dates or destination?"
+	what := "the cheapest options"
+	if e.Class > pb.Class_CLASS_ECONOMY {
+		class := strings.ToLower(strings.ReplaceAll(strings.TrimPrefix(e.Class.String(), "CLASS_"), "_", " "))
+		what = fmt.Sprintf("the cheapest %s options", class)
+		options = "Would you like to raise the budget, or fly economy instead?"
+	}
+	return fmt.Sprintf("I couldn't find a trip within your budget: %s come to %.2f %s, over your %.2f %s. %s",
+		what, e.Cheapest, e.Budget.GetCurrency(), e.Budget.GetValue(), e.Budget.GetCurrency(), options)
+}
+
+// budgetConflict returns a BudgetConflict if the cheapest verified flights and stays of
+// a budgeted itinerary add up to more than its budget, and nil otherwise
+func budgetConflict(it *pb.Itinerary) *BudgetConflict {
+	if it.GetBudgetSplit() == nil {
+		return nil
+	}
+	flights, hotels, ok := cheapestCosts(it.Graph, it.BudgetSplit.GetFlights().GetCurrency())
+	if !ok || flights+hotels <= it.GetBudget().GetValue() {
+		return nil
+	}
+	return &BudgetConflict{Budget: it.Budget, Cheapest: flights + hotels, Class: premiumClass(it.Graph)}
+}
+
+// premiumClass returns the highest cabin above economy asked for on any flight, or
+// CLASS_UNSPECIFIED if there is none
+func premiumClass(g *pb.Graph) pb.Class {
+	class := pb.Class_CLASS_UNSPECIFIED
+	var walk func(g *pb.Graph)
+	walk = func(g *pb.Graph) {
+		if g == nil {
+			return
+		}
+		for _, e := range g.Edges {
+			if c := e.GetTransport().GetFlightPreferences().GetTravelClass(); c > pb.Class_CLASS_ECONOMY && c > class {
+				class = c
+			}
+		}
+		for _, n := range g.Nodes {
+			walk(n.SubGraph)
+		}
+		walk(g.SubGraph)
+	}
+	walk(g)
+	return class
+}
+
+// cheapestCosts adds up the cheapest option found for every flight and every stay. It
+// reports false if any of them has no option in the given currency to compare.
+func cheapestCosts(g *pb.Graph, currency string) (flights, hotels float64, ok bool) {
+	ok = true
+	var walk func(g *pb.Graph)
+	walk = func(g *pb.Graph) {
+		if g == nil {
+			return
+		}
+		for _, e := range g.Edges {
+			if e.GetTransport().GetType() != pb.TransportType_TRANSPORT_TYPE_FLIGHT {
+				continue
+			}
+			cheapest := -1.0
+			for _, opt := range e.TransportOptions {
+				if c := opt.GetCost(); c.GetCurrency() == currency && c.GetValue() > 0 && (cheapest < 0 || c.Value < cheapest) {
+					cheapest = c.Value
+				}
+			}
+			if cheapest < 0 {
+				ok = false
+				return
+			}
+			flights += cheapest
+		}
+		for _, n := range g.Nodes {
+			if n.Stay != nil {
+				cheapest := -1.0
+				for _, opt := range n.StayOptions {
+					if c := opt.GetCost(); c.GetCurrency() == currency && c.GetValue() > 0 && (cheapest < 0 || c.Value < cheapest) {
+						cheapest = c.Value
+					}
+				}
+				if cheapest < 0 {
+					ok = false
+					return
+				}
+				hotels += cheapest
+			}
+			walk(n.SubGraph)
+		}
+		walk(g.SubGraph)
+	}
+	walk(g)
+	return flights, hotels, ok
+}
+
+// formatBudgetSplit describes a split, e.g. "1500.00 USD total: aim for at most
+// 614.00 USD on flights and 736.00 USD on hotels, keeping 150.00 USD spare"
+func formatBudgetSplit(total *pb.Cost, s *pb.BudgetSplit) string {
+	return fmt.Sprintf("%.2f %s total: aim for at most %.2f %s on flights and %.2f %s on hotels, keeping %.2f %s spare",
+		total.GetValue(), s.GetBuffer().GetCurrency(),
+		s.GetFlights().GetValue(), s.GetFlights().GetCurrency(),
+		s.GetHotels().GetValue(), s.GetHotels().GetCurrency(),
+		s.GetBuffer().GetValue(), s.GetBuffer().GetCurrency())
+}
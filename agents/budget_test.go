@@ -0,0 +1,235 @@
+package agents
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/agents/options"
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestAllocateBudget(t *testing.T) {
+	tests := []struct {
+		name       string
+		total      float64
+		legs       int
+		nights     int
+		priceIndex float64
+		want       BudgetAllocation
+	}{
+		{"ShortAverage", 1500, 2, 5, 1, BudgetAllocation{Flights: 614, Hotels: 736, Buffer: 150}},
+		{"ShortExpensive", 1500, 2, 2, 1.7, BudgetAllocation{Flights: 743, Hotels: 607, Buffer: 150}},
+		{"ShortCheap", 1500, 2, 2, 0.5, BudgetAllocation{Flights: 1089, Hotels: 261, Buffer: 150}},
+		{"LongExpensive", 4000, 2, 14, 1.7, BudgetAllocation{Flights: 536, Hotels: 3064, Buffer: 400}},
+		{"LongCheap", 4000, 2, 14, 0.5, BudgetAllocation{Flights: 1343, Hotels: 2257, Buffer: 400}},
+		{"MultiCity", 3000, 4, 6, 1, BudgetAllocation{Flights: 1570, Hotels: 1130, Buffer: 300}},
+		{"UnknownIndexIsAverage", 1500, 2, 5, 0, BudgetAllocation{Flights: 614, Hotels: 736, Buffer: 150}},
+		{"NoFlights", 1000, 0, 3, 1, BudgetAllocation{Flights: 0, Hotels: 900, Buffer: 100}},
+		{"NoNights", 1000, 2, 0, 1, BudgetAllocation{Flights: 900, Hotels: 0, Buffer: 100}},
+		{"NothingToBook", 1000, 0, 0, 1, BudgetAllocation{Buffer: 1000}},
+		{"FractionsDropped", 999.99, 2, 5, 1, BudgetAllocation{Flights: 409, Hotels: 490, Buffer: 100}},
+		{"NoBudget", 0, 2, 5, 1, BudgetAllocation{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AllocateBudget(tt.total, tt.legs, tt.nights, tt.priceIndex)
+			assert.Equal(t, tt.want, got)
+			if tt.total > 0 {
+				assert.Equal(t, float64(int(tt.total)), got.Flights+got.Hotels+got.Buffer, "the split adds up to the budget")
+			}
+		})
+	}
+}
+
+// budgetFixture is a return trip to Paris for two with a three-night stay and a 1500 USD budget
+func budgetFixture() *pb.Itinerary {
+	checkIn := time.Date(2026, 6, 1, 15, 0, 0, 0, time.UTC)
+	flight := func(from, to string, dep time.Time) *pb.Edge {
+		return &pb.Edge{FromId: from, ToId: to, Transport: &pb.Transport{
+			Type:          pb.TransportType_TRANSPORT_TYPE_FLIGHT,
+			TravelerCount: 2,
+			Cost:          &pb.Cost{Currency: "USD"},
+			Details:       &pb.Transport_Flight{Flight: &pb.Flight{DepartureTime: timestamppb.New(dep)}},
+		}}
+	}
+	return &pb.Itinerary{
+		Title:  "Paris",
+		Budget: &pb.Cost{Value: 1500, Currency: "USD"},
+		Graph: &pb.Graph{
+			Nodes: []*pb.Node{
+				{Id: "home", Location: &pb.Location{CityCode: "BER"}},
+				{Id: "paris", Location: &pb.Location{CityCode: "PAR"}, Stay: &pb.Accommodation{
+					TravelerCount: 2,
+					Cost:          &pb.Cost{Currency: "USD"},
+					CheckIn:       timestamppb.New(checkIn),
+					CheckOut:      timestamppb.New(checkIn.AddDate(0, 0, 3).Add(-4 * time.Hour)),
+				}},
+			},
+			Edges: []*pb.Edge{
+				flight("home", "paris", checkIn.Add(-5*time.Hour)),
+				flight("paris", "home", checkIn.AddDate(0, 0, 3)),
+			},
+		},
+	}
+}
+
+func TestTripShape(t *testing.T) {
+	legs, nights, priceIndex := tripShape(budgetFixture().Graph)
+	assert.Equal(t, 2, legs)
+	assert.Equal(t, 3, nights, "nights are counted by date")
+	assert.InDelta(t, 1.4, priceIndex, 1e-9)
+
+	// Stays are weighted by their nights; unknown cities count as average
+	g := budgetFixture().Graph
+	g.SubGraph = &pb.Graph{Nodes: []*pb.Node{{Id: "day_trip", Location: &pb.Location{CityCode: "XXX"}, Stay: &pb.Accommodation{
+		CheckIn:  timestamppb.New(time.Date(2026, 6, 4, 12, 0, 0, 0, time.UTC)),
+		CheckOut: timestamppb.New(time.Date(2026, 6, 5, 10, 0, 0, 0, time.UTC)),
+	}}}}
+	legs, nights, priceIndex = tripShape(g)
+	assert.Equal(t, 2, legs)
+	assert.Equal(t, 4, nights)
+	assert.InDelta(t, (3*1.4+1)/4, priceIndex, 1e-9)
+
+	assert.Equal(t, 0, options.Nights(time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC), time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 1, options.Nights(time.Date(2026, 6, 1, 1, 0, 0, 0, time.UTC), time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)), "a day room still counts as a night")
+}
+
+func TestPlanBudget(t *testing.T) {
+	it := budgetFixture()
+	planBudget(context.Background(), it)
+
+	// 2 legs and 3 nights at 1.4x: the 1350 left after the buffer splits 672/678
+	split := it.BudgetSplit
+	if assert.NotNil(t, split) {
+		assert.Equal(t, 672.0, split.Flights.GetValue())
+		assert.Equal(t, 678.0, split.Hotels.GetValue())
+		assert.Equal(t, 150.0, split.Buffer.GetValue())
+		assert.Equal(t, "USD", split.Hotels.GetCurrency())
+		assert.Equal(t, int32(2), split.FlightLegs)
+		assert.Equal(t, int32(3), split.Nights)
+	}
+
+	// Fares are capped per traveller, stays per night
+	for _, e := range it.Graph.Edges {
+		assert.Equal(t, 168.0, e.Transport.FlightPreferences.GetMaxPrice())
+	}
+	assert.Equal(t, 226.0, it.Graph.Nodes[1].Stay.Preferences.GetMaxNightlyPrice())
+
+	t.Run("NoBudget", func(t *testing.T) {
+		it := budgetFixture()
+		it.Budget = nil
+		planBudget(context.Background(), it)
+		assert.Nil(t, it.BudgetSplit)
+		assert.Nil(t, it.Graph.Edges[0].Transport.FlightPreferences)
+		assert.Nil(t, it.Graph.Nodes[1].Stay.Preferences)
+	})
+
+	t.Run("OtherCurrency", func(t *testing.T) {
+		it := budgetFixture()
+		it.Graph.Nodes[1].Stay.Cost.Currency = "EUR"
+		planBudget(context.Background(), it)
+		assert.NotNil(t, it.BudgetSplit)
+		assert.Nil(t, it.Graph.Nodes[1].Stay.Preferences, "caps are only set in the budget's currency")
+	})
+}
+
+func TestRebalanceBudget(t *testing.T) {
+	// verified returns the fixture after planning, with one option per flight and stay
+	verified := func(flight, stay float64) *pb.Itinerary {
+		it := budgetFixture()
+		planBudget(context.Background(), it)
+		for _, e := range it.Graph.Edges {
+			e.TransportOptions = []*pb.Transport{
+				{Cost: &pb.Cost{Value: flight + 50, Currency: "USD"}},
+				{Cost: &pb.Cost{Value: flight, Currency: "USD"}},
+			}
+		}
+		it.Graph.Nodes[1].StayOptions = []*pb.Accommodation{{Cost: &pb.Cost{Value: stay, Currency: "USD"}}}
+		return it
+	}
+
+	t.Run("ExpensiveFlightsCheapHotel", func(t *testing.T) {
+		it := verified(400, 500)
+		note := rebalanceBudget(it)
+		assert.Equal(t, "Flights cost at least 800.00 USD, over the 672.00 USD suggested; cheaper hotels make up the difference, so the hotel budget is now 550.00 USD", note)
+		assert.Equal(t, note, it.BudgetSplit.Note)
+		assert.Equal(t, 800.0, it.BudgetSplit.Flights.Value)
+		assert.Equal(t, 550.0, it.BudgetSplit.Hotels.Value)
+		assert.Equal(t, 150.0, it.BudgetSplit.Buffer.Value, "the buffer is not touched")
+	})
+
+	t.Run("ExpensiveHotelCheapFlights", func(t *testing.T) {
+		it := verified(200, 900)
+		assert.Equal(t, "Hotels cost at least 900.00 USD, over the 678.00 USD suggested; cheaper flights make up the difference, so the flight budget is now 450.00 USD", rebalanceBudget(it))
+		assert.Equal(t, 450.0, it.BudgetSplit.Flights.Value)
+		assert.Equal(t, 900.0, it.BudgetSplit.Hotels.Value)
+	})
+
+	t.Run("WithinSplit", func(t *testing.T) {
+		it := verified(300, 650)
+		assert.Empty(t, rebalanceBudget(it))
+		assert.Equal(t, 672.0, it.BudgetSplit.Flights.Value)
+		assert.Empty(t, it.BudgetSplit.Note)
+	})
+
+	t.Run("OverBudget", func(t *testing.T) {
+		it := verified(400, 600)
+		assert.Empty(t, rebalanceBudget(it), "nothing to move when the total does not fit")
+		assert.Equal(t, 672.0, it.BudgetSplit.Flights.Value)
+	})
+
+	t.Run("MissingOptions", func(t *testing.T) {
+		it := verified(400, 500)
+		it.Graph.Nodes[1].StayOptions = nil
+		assert.Empty(t, rebalanceBudget(it))
+	})
+}
+
+func TestBudgetConflict(t *testing.T) {
+	verified := func(budget, flight, stay float64) *pb.Itinerary {
+		it := budgetFixture()
+		it.Budget.Value = budget
+		planBudget(context.Background(), it)
+		for _, e := range it.Graph.Edges {
+			e.TransportOptions = []*pb.Transport{{Cost: &pb.Cost{Value: flight, Currency: "USD"}}}
+		}
+		it.Graph.Nodes[1].StayOptions = []*pb.Accommodation{{Cost: &pb.Cost{Value: stay, Currency: "USD"}}}
+		return it
+	}
+
+	t.Run("WithinBudget", func(t *testing.T) {
+		assert.Nil(t, budgetConflict(verified(1500, 400, 600)), "the buffer may be spent")
+	})
+
+	t.Run("OverBudget", func(t *testing.T) {
+		c := budgetConflict(verified(1500, 500, 600))
+		if assert.NotNil(t, c) {
+			assert.Equal(t, 1600.0, c.Cheapest)
+			assert.Equal(t, pb.Class_CLASS_UNSPECIFIED, c.Class)
+			assert.Equal(t, "I couldn't find a trip within your budget: the cheapest options come to 1600.00 USD, over your 1500.00 USD. "+
+				"Would you like to raise the budget, or change the dates or destination?", c.Question())
+		}
+	})
+
+	t.Run("PremiumCabin", func(t *testing.T) {
+		it := verified(200, 2400, 600)
+		it.Graph.Edges[0].Transport.FlightPreferences.TravelClass = pb.Class_CLASS_PREMIUM_ECONOMY
+		it.Graph.Edges[1].Transport.FlightPreferences.TravelClass = pb.Class_CLASS_BUSINESS
+		c := budgetConflict(it)
+		if assert.NotNil(t, c) {
+			assert.Equal(t, pb.Class_CLASS_BUSINESS, c.Class)
+			assert.Equal(t, "I couldn't find a trip within your budget: the cheapest business options come to 5400.00 USD, over your 200.00 USD. "+
+				"Would you like to raise the budget, or fly economy instead?", c.Question())
+		}
+	})
+
+	t.Run("NoBudget", func(t *testing.T) {
+		it := verified(1500, 5000, 5000)
+		it.BudgetSplit = nil
+		assert.Nil(t, budgetConflict(it))
+	})
+}
@@ -0,0 +1,107 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	tmcontext "github.com/va6996/travelingman/context"
+	"github.com/va6996/travelingman/log"
+	"github.com/va6996/travelingman/orm"
+	"github.com/va6996/travelingman/pb"
+	"github.com/va6996/travelingman/plugins/amadeus"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// CancelItinerary cancels the provider orders of every booked transport and stay of it,
+// sub-graphs included, and marks them CANCELLED. The stored bookings of cancelled
+// flights move to CANCELLED and their payments are cancelled or marked for refund. An
+// order the provider no longer has counts as cancelled.
+//
+// A segment that cannot be cancelled does not stop the others; it is returned as a
+// failure and keeps its status, so calling CancelItinerary again retries just the
+// segments still booked.
+func (bt *BookingTracker) CancelItinerary(ctx context.Context, it *pb.Itinerary) []*pb.TripConflict {
+	var failures []*pb.TripConflict
+	fail := func(id string, err error) {
+		log.Errorf(ctx, "CancelItinerary: failed to cancel %s: %v", id, err)
+		failures = append(failures, &pb.TripConflict{
+			ComponentId: id,
+			Message:     err.Error(),
+			Suggestion:  "Cancel again, or contact the provider if it keeps failing",
+		})
+	}
+
+	var walk func(g *pb.Graph)
+	walk = func(g *pb.Graph) {
+		for _, e := range g.GetEdges() {
+			t := e.Transport
+			if t.GetReferenceNumber() == "" || t.Status == SegmentCancelled {
+				continue
+			}
+			id := e.FromId + "->" + e.ToId
+			if err := bt.cancelFlight(ctx, t); err != nil {
+				fail(id, err)
+				continue
+			}
+			t.Status = SegmentCancelled
+			log.Infof(ctx, "CancelItinerary: cancelled %s order %s", id, t.ReferenceNumber)
+		}
+		for _, n := range g.GetNodes() {
+			if stay := n.Stay; stay.GetBookingReference() != "" && stay.Status != SegmentCancelled {
+				if err := bt.cancelHotel(ctx, stay.BookingReference); err != nil {
+					fail(n.Id, err)
+				} else {
+					stay.Status = SegmentCancelled
+					log.Infof(ctx, "CancelItinerary: cancelled stay %s order %s", n.Id, stay.BookingReference)
+				}
+			}
+			walk(n.SubGraph)
+		}
+	}
+	walk(it.GetGraph())
+	return failures
+}
+
+// cancelFlight cancels a transport's order and its stored booking
+func (bt *BookingTracker) cancelFlight(ctx context.Context, t *pb.Transport) error {
+	if bt.Orders == nil {
+		return errors.New("no flight order provider configured")
+	}
+	if err := bt.Orders.CancelFlightOrder(ctx, t.ReferenceNumber); err != nil && !errors.Is(err, amadeus.ErrOrderNotFound) {
+		return err
+	}
+	if t.BookingId == 0 || bt.DB == nil {
+		return nil
+	}
+
+	b, err := orm.GetBooking(bt.DB, uint(t.BookingId))
+	if err != nil {
+		return fmt.Errorf("order %s was cancelled but its booking was not updated: %w", t.ReferenceNumber, err)
+	}
+	if b.Status != pb.BookingStatus_BOOKING_STATUS_CANCELLED {
+		flight, err := b.BookedFlight()
+		if err != nil {
+			return fmt.Errorf("booking %d: %w", b.ID, err)
+		}
+		change := &pb.BookingStatusChange{BookingId: int64(b.ID), From: b.Status, To: pb.BookingStatus_BOOKING_STATUS_CANCELLED, ChangedAt: timestamppb.New(tmcontext.Now(ctx))}
+		if err := orm.RecordBookingStatus(bt.DB, b, change, flight); err != nil {
+			return fmt.Errorf("order %s was cancelled but its booking was not updated: %w", t.ReferenceNumber, err)
+		}
+	}
+	if err := orm.CancelBookingPayments(bt.DB, b.ID); err != nil {
+		return fmt.Errorf("order %s was cancelled but its payments were not updated: %w", t.ReferenceNumber, err)
+	}
+	return nil
+}
+
+// cancelHotel cancels a hotel order
+func (bt *BookingTracker) cancelHotel(ctx context.Context, ref string) error {
+	if bt.Hotels == nil {
+		return errors.New("no hotel booking provider configured")
+	}
+	if err := bt.Hotels.CancelHotelOrder(ctx, ref); err != nil && !errors.Is(err, amadeus.ErrOrderNotFound) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package agents
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/orm"
+	"github.com/va6996/travelingman/pb"
+	"github.com/va6996/travelingman/plugins/amadeus"
+)
+
+func TestBookingTracker_CancelItinerary(t *testing.T) {
+	placed := &amadeus.FlightOrderResponse{}
+	placed.Data.ID = "ORDER1"
+	bt, notifier := setupBookingTracker(t, map[string]*amadeus.FlightOrderResponse{"ORDER1": placed})
+	hotels := &fakeHotels{orders: map[string]*amadeus.HotelOrder{}, stuck: map[string]bool{"HOTEL-PAR": true}}
+	bt.Hotels = hotels
+
+	booking, err := orm.CreateBooking(bt.DB, "ORDER1", &pb.Flight{CarrierCode: "BA", FlightNumber: "178"}, []int64{1, 2})
+	assert.NoError(t, err)
+	assert.NoError(t, orm.RecordPaymentSplit(bt.DB, booking.ID, []*pb.Payment{
+		{UserId: 1, Amount: "300.00", Currency: "EUR"},
+		{UserId: 2, Amount: "300.00", Currency: "EUR", Status: "PAID", TransactionId: "tx-2"},
+	}))
+
+	it := &pb.Itinerary{Graph: &pb.Graph{
+		Nodes: []*pb.Node{
+			{Id: "nyc"},
+			{Id: "lon", Stay: &pb.Accommodation{BookingReference: "HOTEL-LON", Status: SegmentBooked}},
+			{Id: "par", Stay: &pb.Accommodation{BookingReference: "HOTEL-PAR", Status: SegmentBooked}},
+		},
+		Edges: []*pb.Edge{
+			{FromId: "nyc", ToId: "lon", Transport: &pb.Transport{ReferenceNumber: "ORDER1", BookingId: int64(booking.ID), Status: SegmentBooked}},
+			{FromId: "lon", ToId: "par", Transport: &pb.Transport{}},
+		},
+	}}
+
+	// The Paris hotel refuses to cancel; everything else is cancelled regardless
+	failures := bt.CancelItinerary(context.Background(), it)
+	if assert.Len(t, failures, 1) {
+		assert.Equal(t, "par", failures[0].ComponentId)
+		assert.Contains(t, failures[0].Message, "500")
+	}
+	assert.Equal(t, SegmentCancelled, it.Graph.Edges[0].Transport.Status)
+	assert.Empty(t, it.Graph.Edges[1].Transport.Status, "unbooked legs are left alone")
+	assert.Equal(t, SegmentCancelled, it.Graph.Nodes[1].Stay.Status)
+	assert.Equal(t, SegmentBooked, it.Graph.Nodes[2].Stay.Status)
+
+	stored, err := orm.GetBooking(bt.DB, booking.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, pb.BookingStatus_BOOKING_STATUS_CANCELLED, stored.Status)
+	history, err := orm.BookingHistory(bt.DB, booking.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, history, 1) {
+		assert.Equal(t, pb.BookingStatus_BOOKING_STATUS_PENDING, history[0].From)
+		assert.Equal(t, pb.BookingStatus_BOOKING_STATUS_CANCELLED, history[0].To)
+	}
+	payments, err := orm.BookingPayments(bt.DB, booking.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, payments, 2) {
+		assert.Equal(t, orm.PaymentStatusCancelled, payments[0].Status)
+		assert.Equal(t, orm.PaymentStatusRefundDue, payments[1].Status)
+	}
+	assert.Empty(t, notifier.changes, "travelers are not notified of their own cancellation")
+
+	// Cancelling again retries only what is still booked
+	hotels.stuck = nil
+	assert.Empty(t, bt.CancelItinerary(context.Background(), it))
+	assert.Equal(t, []string{"HOTEL-LON", "HOTEL-PAR"}, hotels.cancelled)
+	assert.Equal(t, SegmentCancelled, it.Graph.Nodes[2].Stay.Status)
+	history, err = orm.BookingHistory(bt.DB, booking.ID)
+	assert.NoError(t, err)
+	assert.Len(t, history, 1)
+}
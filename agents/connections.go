@@ -0,0 +1,161 @@
+package agents
+
+import (
+	"fmt"
+	"time"
+
+	tmcore "github.com/va6996/travelingman/core"
+	"github.com/va6996/travelingman/pb"
+	"github.com/va6996/travelingman/plugins/core"
+)
+
+// DefaultSelfTransferBuffer is added to the minimum connection time when the two flights
+// of a connection are booked separately, to collect and re-check bags
+const DefaultSelfTransferBuffer = 60 * time.Minute
+
+// SelfTransferWarning is attached to a flight that the traveller self-transfers onto
+const SelfTransferWarning = "self-transfer: bags not checked through"
+
+// TagTightConnection marks flight options with a layover below the airport's minimum connection time
+const TagTightConnection = "Tight connection"
+
+// tightConnectionPenalty is added to an option's score for each layover below the minimum
+const tightConnectionPenalty = 200.0
+
+// connection is a layover between two flights at one airport
+type connection struct {
+	airport string
+	layover time.Duration
+	minimum time.Duration
+}
+
+func (c connection) tight() bool {
+	return c.layover < c.minimum
+}
+
+// connectionTimes returns the configured MCT table, or the built-in one
+func (ta *TravelAgent) connectionTimes() *core.ConnectionTimes {
+	if ta.ConnectionTimes != nil {
+		return ta.ConnectionTimes
+	}
+	return core.DefaultConnectionTimes()
+}
+
+// flightConnections returns the layovers between the segments of one flight offer.
+// Segments of one offer are ticketed together, so bags are checked through.
+func (ta *TravelAgent) flightConnections(f *pb.Flight) []connection {
+	var conns []connection
+	segs := f.GetSegments()
+	for i := 1; i < len(segs); i++ {
+		in, out := segs[i-1], segs[i]
+		if in.ArrivalTime == nil || out.DepartureTime == nil {
+			continue
+		}
+		conns = append(conns, ta.connectionAt(
+			in.DepartureAirportCode, in.ArrivalAirportCode, in.ArrivalTerminal,
+			out.DepartureAirportCode, out.DepartureTerminal, out.ArrivalAirportCode,
+			out.DepartureTime.AsTime().Sub(in.ArrivalTime.AsTime()),
+		))
+	}
+	return conns
+}
+
+// tightConnections counts the layovers of a transport option below the minimum
+func (ta *TravelAgent) tightConnections(t *pb.Transport) int {
+	var n int
+	for _, c := range ta.flightConnections(t.GetFlight()) {
+		if c.tight() {
+			n++
+		}
+	}
+	return n
+}
+
+// connectionAt builds a connection arriving at arrAirport and leaving from depAirport.
+// Changing terminal or airport uses the airport's inter-terminal time.
+func (ta *TravelAgent) connectionAt(from, arrAirport, arrTerminal, depAirport, depTerminal, to string, layover time.Duration) connection {
+	mct := ta.connectionTimes()
+	terminalChange := arrAirport != depAirport ||
+		(arrTerminal != "" && depTerminal != "" && arrTerminal != depTerminal)
+	return connection{
+		airport: arrAirport,
+		layover: layover,
+		minimum: mct.Minimum(arrAirport, mct.IsInternational(from, arrAirport, to), terminalChange),
+	}
+}
+
+// checkSelfTransfers warns about connection nodes where two separately booked flights meet.
+// Each edge is searched and booked on its own, so bags are not checked through and the
+// layover must cover the minimum connection time plus SelfTransferBuffer.
+func (ta *TravelAgent) checkSelfTransfers(g *pb.Graph) {
+	if g == nil {
+		return
+	}
+	for _, n := range g.Nodes {
+		if n.Stay != nil || n.SubGraph != nil {
+			continue
+		}
+		for _, in := range tmcore.GetEdgesToNode(g, n.Id) {
+			for _, out := range tmcore.GetEdgesFromNode(g, n.Id) {
+				c, ok := ta.selfTransfer(in, out)
+				if !ok {
+					continue
+				}
+				msg := SelfTransferWarning
+				if c.tight() {
+					msg = fmt.Sprintf("%s; %d min connection at %s is below the %d min minimum", msg, int(c.layover.Minutes()), c.airport, int(c.minimum.Minutes()))
+					out.Transport.Tags = append(out.Transport.Tags, TagTightConnection)
+				}
+				if out.Transport.Error == nil || out.Transport.Error.Severity != pb.ErrorSeverity_ERROR_SEVERITY_ERROR {
+					out.Transport.Error = &pb.Error{Message: msg, Severity: pb.ErrorSeverity_ERROR_SEVERITY_WARNING}
+				}
+			}
+		}
+	}
+}
+
+// selfTransfer returns the connection from edge in to edge out if both are flights
+// on different bookings that form a connection
+func (ta *TravelAgent) selfTransfer(in, out *pb.Edge) (connection, bool) {
+	inFlight, outFlight := in.GetTransport().GetFlight(), out.GetTransport().GetFlight()
+	if inFlight == nil || outFlight == nil {
+		return connection{}, false
+	}
+	if ref := in.Transport.ReferenceNumber; ref != "" && ref == out.Transport.ReferenceNumber {
+		return connection{}, false
+	}
+
+	_, arr, okIn := legTimes(in)
+	dep, _, okOut := legTimes(out)
+	if !okIn || !okOut {
+		return connection{}, false
+	}
+	layover := dep.Sub(arr)
+	if layover < 0 || layover > transferThreshold {
+		return connection{}, false
+	}
+
+	var from, arrAirport, arrTerminal, depAirport, depTerminal, to string
+	if segs := inFlight.Segments; len(segs) > 0 {
+		last := segs[len(segs)-1]
+		from, arrAirport, arrTerminal = last.DepartureAirportCode, last.ArrivalAirportCode, last.ArrivalTerminal
+	} else {
+		from, arrAirport = firstIata(in.Transport.OriginLocation), firstIata(in.Transport.DestinationLocation)
+	}
+	if segs := outFlight.Segments; len(segs) > 0 {
+		depAirport, depTerminal, to = segs[0].DepartureAirportCode, segs[0].DepartureTerminal, segs[0].ArrivalAirportCode
+	} else {
+		depAirport, to = firstIata(out.Transport.OriginLocation), firstIata(out.Transport.DestinationLocation)
+	}
+
+	c := ta.connectionAt(from, arrAirport, arrTerminal, depAirport, depTerminal, to, layover)
+	c.minimum += ta.SelfTransferBuffer
+	return c, true
+}
+
+func firstIata(l *pb.Location) string {
+	if len(l.GetIataCodes()) == 0 {
+		return ""
+	}
+	return l.GetIataCodes()[0]
+}
@@ -0,0 +1,124 @@
+package agents
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// connectingFlight is a two-segment offer from origin to dest with a layover at via
+func connectingFlight(origin, via, dest string, arrive time.Time, layover time.Duration, price float64) *pb.Transport {
+	depart := arrive.Add(layover)
+	return &pb.Transport{
+		Type:                pb.TransportType_TRANSPORT_TYPE_FLIGHT,
+		OriginLocation:      &pb.Location{IataCodes: []string{origin}},
+		DestinationLocation: &pb.Location{IataCodes: []string{dest}},
+		Cost:                &pb.Cost{Value: price, Currency: "USD"},
+		Details: &pb.Transport_Flight{Flight: &pb.Flight{
+			DepartureTime: timestamppb.New(arrive.Add(-2 * time.Hour)),
+			ArrivalTime:   timestamppb.New(depart.Add(2 * time.Hour)),
+			Segments: []*pb.FlightSegment{
+				{DepartureAirportCode: origin, ArrivalAirportCode: via, DepartureTime: timestamppb.New(arrive.Add(-2 * time.Hour)), ArrivalTime: timestamppb.New(arrive)},
+				{DepartureAirportCode: via, ArrivalAirportCode: dest, DepartureTime: timestamppb.New(depart), ArrivalTime: timestamppb.New(depart.Add(2 * time.Hour))},
+			},
+			LayoverCount: 1,
+		}},
+	}
+}
+
+func TestTravelAgent_ScoreAndTag_TightConnections(t *testing.T) {
+	arrive := time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	// Same 70-minute international layover: too short at Heathrow, fine at London City
+	viaLHR := connectingFlight("CDG", "LHR", "EDI", arrive, 70*time.Minute, 180)
+	viaLCY := connectingFlight("AMS", "LCY", "EDI", arrive, 70*time.Minute, 200)
+
+	it := &pb.Itinerary{Graph: &pb.Graph{
+		Nodes: []*pb.Node{{Id: "paris"}, {Id: "edinburgh"}},
+		Edges: []*pb.Edge{{FromId: "paris", ToId: "edinburgh", TransportOptions: []*pb.Transport{viaLHR, viaLCY}}},
+	}}
+
+	agent := NewTravelAgent(nil, nil)
+	agent.scoreAndTag([]*pb.Itinerary{it})
+
+	assert.Contains(t, viaLHR.Tags, TagTightConnection)
+	assert.Contains(t, viaLHR.Tags, "Cheapest")
+	assert.NotContains(t, viaLCY.Tags, TagTightConnection)
+
+	// The penalty outweighs the cheaper fare
+	assert.Same(t, viaLCY, it.Graph.Edges[0].Transport)
+	assert.Contains(t, viaLCY.Tags, "Best Value")
+}
+
+func TestTravelAgent_ScoreAndTag_SelfTransfer(t *testing.T) {
+	arrive := time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	// Two one-way offers from separate searches, meeting at Heathrow two hours apart
+	newItinerary := func() *pb.Itinerary {
+		in := &pb.Transport{
+			Type:                pb.TransportType_TRANSPORT_TYPE_FLIGHT,
+			ReferenceNumber:     "PNR1",
+			OriginLocation:      &pb.Location{IataCodes: []string{"JFK"}},
+			DestinationLocation: &pb.Location{IataCodes: []string{"LHR"}},
+			Cost:                &pb.Cost{Value: 400, Currency: "USD"},
+			Details: &pb.Transport_Flight{Flight: &pb.Flight{
+				DepartureTime: timestamppb.New(arrive.Add(-7 * time.Hour)),
+				ArrivalTime:   timestamppb.New(arrive),
+			}},
+		}
+		out := &pb.Transport{
+			Type:                pb.TransportType_TRANSPORT_TYPE_FLIGHT,
+			ReferenceNumber:     "PNR2",
+			OriginLocation:      &pb.Location{IataCodes: []string{"LHR"}},
+			DestinationLocation: &pb.Location{IataCodes: []string{"LIS"}},
+			Cost:                &pb.Cost{Value: 90, Currency: "USD"},
+			Details: &pb.Transport_Flight{Flight: &pb.Flight{
+				DepartureTime: timestamppb.New(arrive.Add(2 * time.Hour)),
+				ArrivalTime:   timestamppb.New(arrive.Add(5 * time.Hour)),
+			}},
+		}
+		return &pb.Itinerary{Graph: &pb.Graph{
+			Nodes: []*pb.Node{{Id: "nyc"}, {Id: "london"}, {Id: "lisbon"}},
+			Edges: []*pb.Edge{
+				{FromId: "nyc", ToId: "london", Transport: in},
+				{FromId: "london", ToId: "lisbon", Transport: out},
+			},
+		}}
+	}
+
+	// 120 minutes covers Heathrow's 90-minute MCT but not the extra hour to re-check bags
+	agent := NewTravelAgent(nil, nil)
+	it := newItinerary()
+	agent.scoreAndTag([]*pb.Itinerary{it})
+
+	out := it.Graph.Edges[1].Transport
+	if assert.NotNil(t, out.Error) {
+		assert.Equal(t, pb.ErrorSeverity_ERROR_SEVERITY_WARNING, out.Error.Severity)
+		assert.Contains(t, out.Error.Message, SelfTransferWarning)
+		assert.Contains(t, out.Error.Message, "120 min connection at LHR is below the 150 min minimum")
+	}
+	assert.Contains(t, out.Tags, TagTightConnection)
+	assert.Nil(t, it.Graph.Edges[0].Transport.Error)
+	assert.Contains(t, agent.formatItinerary(it, 0), "Warning: "+SelfTransferWarning)
+	assert.Empty(t, availabilityIssues(it), "a self-transfer is a warning, not a failure")
+
+	// Without the buffer the connection is feasible, but bags still are not checked through
+	agent.SelfTransferBuffer = 0
+	it = newItinerary()
+	agent.scoreAndTag([]*pb.Itinerary{it})
+	out = it.Graph.Edges[1].Transport
+	if assert.NotNil(t, out.Error) {
+		assert.Equal(t, SelfTransferWarning, out.Error.Message)
+	}
+	assert.NotContains(t, out.Tags, TagTightConnection)
+
+	// Legs on one booking are checked through
+	agent.SelfTransferBuffer = DefaultSelfTransferBuffer
+	it = newItinerary()
+	it.Graph.Edges[1].Transport.ReferenceNumber = "PNR1"
+	agent.scoreAndTag([]*pb.Itinerary{it})
+	assert.Nil(t, it.Graph.Edges[1].Transport.Error)
+}
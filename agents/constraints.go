@@ -0,0 +1,209 @@
+package agents
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/va6996/travelingman/log"
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// QueryConstraints are the requirements a trip request states outright: a budget, a
+// cabin, non-stop flights and who is travelling. They are read from the query without
+// the model and written into every plan, so that no revision can drop them. Zero
+// fields constrain nothing.
+type QueryConstraints struct {
+	Budget      *pb.Cost // Total for the whole trip; the currency is empty if the query gave none
+	TravelClass pb.Class
+	NonStop     bool
+	Adults      int32
+	Children    int32
+	Infants     int32
+}
+
+var (
+	// travelerPattern matches a count of travellers, e.g. "2 adults", "three kids" or "a baby",
+	// and the "family of" or "with" before it
+	travelerPattern = regexp.MustCompile(`(?i)(?:\b(family|group|party) of\s+|\b(with)\s+)?\b(\d+|an?|one|two|three|four|five|six|seven|eight|nine|ten)\s+(adults?|children|child|kids?|infants?|bab(?:y|ies)|people|persons|travell?ers|passengers)\b`)
+
+	// budgetPattern matches a spending cap, e.g. "under $2000", "budget of 2,500 EUR" or "max 1.5k"
+	budgetPattern = regexp.MustCompile(`(?i)\b(under|below|less than|no more than|up to|at most|max(?:imum)?|within|budget(?:\s+of|\s+is)?:?)\s*([$€£])?\s*(\d[\d,]*(?:\.\d+)?)(k\b)?\s*(usd|eur|gbp|dollars?|euros?|pounds?)?\b`)
+
+	nonStopPattern = regexp.MustCompile(`(?i)\bnon[- ]?stop\b|\bdirect flights?\b|\bno (?:stops|layovers|connections)\b`)
+
+	premiumEconomyPattern = regexp.MustCompile(`(?i)\bpremium economy\b`)
+	firstClassPattern     = regexp.MustCompile(`(?i)\bfirst[- ]class\b`)
+	economyPattern        = regexp.MustCompile(`(?i)\beconomy\b|\bcoach\b`)
+
+	// businessPattern matches only the cabin, as "business" alone is as often why the
+	// user travels, e.g. "on business next week"
+	businessPattern = regexp.MustCompile(`(?i)\bbusiness[- ](?:class|cabin)\b`)
+)
+
+var numberWords = map[string]int32{
+	"a": 1, "an": 1, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5,
+	"six": 6, "seven": 7, "eight": 8, "nine": 9, "ten": 10,
+}
+
+var currencySymbols = map[string]string{"$": "USD", "€": "EUR", "£": "GBP"}
+
+// ParseQueryConstraints reads the constraints a trip request states, e.g. "2 adults,
+// business class, nonstop, under $2000". Only unambiguous phrasings are recognised; a number
+// is taken as a budget only with a currency or the word budget.
+func ParseQueryConstraints(ctx context.Context, query string) QueryConstraints {
+	var c QueryConstraints
+
+	// A bare head count is split once the children and infants are known. "4 people
+	// with a baby" counts the baby on top, unless the count is the whole "family of 4".
+	var total, extra int32
+	group := false
+	for _, m := range travelerPattern.FindAllStringSubmatch(query, -1) {
+		n, ok := numberWords[strings.ToLower(m[3])]
+		if !ok {
+			parsed, err := strconv.Atoi(m[3])
+			if err != nil || parsed <= 0 {
+				continue
+			}
+			n = int32(parsed)
+		}
+		switch word := strings.ToLower(m[4]); {
+		case strings.HasPrefix(word, "adult"):
+			c.Adults += n
+		case strings.HasPrefix(word, "child"), strings.HasPrefix(word, "kid"):
+			c.Children += n
+		case strings.HasPrefix(word, "infant"), strings.HasPrefix(word, "bab"):
+			c.Infants += n
+		default:
+			total = max(total, n)
+			group = group || m[1] != ""
+			continue
+		}
+		if m[2] != "" {
+			extra += n
+		}
+	}
+	if group {
+		extra = 0
+	}
+	if included := c.Children + c.Infants - extra; c.Adults == 0 && total > included {
+		c.Adults = total - included
+	}
+
+	for _, m := range budgetPattern.FindAllStringSubmatch(query, -1) {
+		currency := currencySymbols[m[2]]
+		if currency == "" {
+			currency = currencyCode(m[5])
+		}
+		if currency == "" && !strings.HasPrefix(strings.ToLower(m[1]), "budget") {
+			continue
+		}
+		value, err := strconv.ParseFloat(strings.ReplaceAll(m[3], ",", ""), 64)
+		if err != nil || value <= 0 {
+			continue
+		}
+		if m[4] != "" {
+			value *= 1000
+		}
+		c.Budget = &pb.Cost{Value: value, Currency: currency}
+		break
+	}
+
+	c.NonStop = nonStopPattern.MatchString(query)
+	c.TravelClass = queryClass(query)
+
+	if c != (QueryConstraints{}) {
+		log.Debugf(ctx, "Query constraints: %d adults, %d children, %d infants, class %s, non-stop %t, budget %s %.0f",
+			c.Adults, c.Children, c.Infants, c.TravelClass, c.NonStop, c.Budget.GetCurrency(), c.Budget.GetValue())
+	}
+	return c
+}
+
+// queryClass returns the cabin a query asks for, or CLASS_UNSPECIFIED
+func queryClass(query string) pb.Class {
+	switch {
+	case premiumEconomyPattern.MatchString(query):
+		return pb.Class_CLASS_PREMIUM_ECONOMY
+	case firstClassPattern.MatchString(query):
+		return pb.Class_CLASS_FIRST
+	case businessPattern.MatchString(query):
+		return pb.Class_CLASS_BUSINESS
+	}
+	if economyPattern.MatchString(query) {
+		return pb.Class_CLASS_ECONOMY
+	}
+	return pb.Class_CLASS_UNSPECIFIED
+}
+
+// currencyCode maps a currency code or name, e.g. "eur" or "dollars", to its ISO code
+func currencyCode(word string) string {
+	switch word = strings.ToLower(word); {
+	case word == "usd", strings.HasPrefix(word, "dollar"):
+		return "USD"
+	case word == "eur", strings.HasPrefix(word, "euro"):
+		return "EUR"
+	case word == "gbp", strings.HasPrefix(word, "pound"):
+		return "GBP"
+	}
+	return ""
+}
+
+// Travelers returns how many people travel, or 0 if the query did not say
+func (c QueryConstraints) Travelers() int32 {
+	return c.Adults + c.Children + c.Infants
+}
+
+// Apply writes the constraints into a planned itinerary: its budget, the traveller
+// count of the trip and of its transports and stays, and the cabin and stop cap of its
+// flights. A budget without a currency keeps the one the plan gave.
+func (c QueryConstraints) Apply(it *pb.Itinerary) {
+	if c.Budget != nil {
+		budget := proto.Clone(c.Budget).(*pb.Cost)
+		if budget.Currency == "" {
+			budget.Currency = it.GetBudget().GetCurrency()
+		}
+		it.Budget = budget
+	}
+	travelers := c.Travelers()
+	if travelers > 0 {
+		it.Travelers = travelers
+	}
+
+	var walk func(g *pb.Graph)
+	walk = func(g *pb.Graph) {
+		if g == nil {
+			return
+		}
+		for _, n := range g.Nodes {
+			if n.Stay != nil && travelers > 0 {
+				n.Stay.TravelerCount = travelers
+			}
+			walk(n.SubGraph)
+		}
+		for _, e := range g.Edges {
+			t := e.Transport
+			if t == nil {
+				continue
+			}
+			if travelers > 0 {
+				t.TravelerCount = travelers
+			}
+			if t.Type != pb.TransportType_TRANSPORT_TYPE_FLIGHT || (c.TravelClass == pb.Class_CLASS_UNSPECIFIED && !c.NonStop) {
+				continue
+			}
+			if t.FlightPreferences == nil {
+				t.FlightPreferences = &pb.FlightPreferences{}
+			}
+			if c.TravelClass != pb.Class_CLASS_UNSPECIFIED {
+				t.FlightPreferences.TravelClass = c.TravelClass
+			}
+			if c.NonStop {
+				t.FlightPreferences.MaxStops = proto.Int32(0)
+			}
+		}
+		walk(g.SubGraph)
+	}
+	walk(it.Graph)
+}
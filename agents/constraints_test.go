@@ -0,0 +1,146 @@
+package agents
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestParseQueryConstraints(t *testing.T) {
+	tests := []struct {
+		query string
+		want  QueryConstraints
+	}{
+		{
+			query: "2 adults, business class, nonstop, under $2000",
+			want: QueryConstraints{
+				Budget:      &pb.Cost{Value: 2000, Currency: "USD"},
+				TravelClass: pb.Class_CLASS_BUSINESS,
+				NonStop:     true,
+				Adults:      2,
+			},
+		},
+		{
+			query: "Lisbon in May for two adults and 1 kid, premium economy, budget of 2,500 EUR",
+			want: QueryConstraints{
+				Budget:      &pb.Cost{Value: 2500, Currency: "EUR"},
+				TravelClass: pb.Class_CLASS_PREMIUM_ECONOMY,
+				Adults:      2,
+				Children:    1,
+			},
+		},
+		{
+			query: "Family of 4 people with a baby, direct flights only, max £1.5k",
+			want: QueryConstraints{
+				Budget:  &pb.Cost{Value: 1500, Currency: "GBP"},
+				NonStop: true,
+				Adults:  3,
+				Infants: 1,
+			},
+		},
+		{
+			// A budget with no currency keeps the plan's
+			query: "First class to Tokyo, my budget is 9000",
+			want: QueryConstraints{
+				Budget:      &pb.Cost{Value: 9000},
+				TravelClass: pb.Class_CLASS_FIRST,
+			},
+		},
+		{
+			// Neither the purpose of the trip nor a bare number constrains anything
+			query: "Business trip to Berlin, under 3 hours from the airport",
+		},
+		{
+			query: "On business next week, flying to Boston",
+		},
+		{
+			query: "Flights for business, 2 adults",
+			want:  QueryConstraints{Adults: 2},
+		},
+		{
+			query: "Business cabin to Singapore for 2 people with 1 child",
+			want: QueryConstraints{
+				TravelClass: pb.Class_CLASS_BUSINESS,
+				Adults:      2,
+				Children:    1,
+			},
+		},
+		{
+			query: "4 travellers including 2 kids",
+			want:  QueryConstraints{Adults: 2, Children: 2},
+		},
+		{
+			query: "Weekend in Rome",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseQueryConstraints(context.Background(), tt.query))
+		})
+	}
+}
+
+func TestQueryConstraints_Apply(t *testing.T) {
+	c := QueryConstraints{
+		Budget:      &pb.Cost{Value: 2000},
+		TravelClass: pb.Class_CLASS_BUSINESS,
+		NonStop:     true,
+		Adults:      2,
+		Children:    1,
+	}
+	it := &pb.Itinerary{
+		Travelers: 1,
+		Budget:    &pb.Cost{Value: 5000, Currency: "EUR"},
+		Graph: &pb.Graph{
+			Nodes: []*pb.Node{{Id: "paris", Stay: &pb.Accommodation{TravelerCount: 1}}},
+			Edges: []*pb.Edge{
+				{Transport: &pb.Transport{Type: pb.TransportType_TRANSPORT_TYPE_FLIGHT, TravelerCount: 1}},
+				{Transport: &pb.Transport{Type: pb.TransportType_TRANSPORT_TYPE_TRAIN, TravelerCount: 1}},
+			},
+		},
+	}
+	c.Apply(it)
+
+	assert.Equal(t, int32(3), it.Travelers)
+	assert.True(t, proto.Equal(&pb.Cost{Value: 2000, Currency: "EUR"}, it.Budget), "the plan's currency is kept")
+	assert.Equal(t, int32(3), it.Graph.Nodes[0].Stay.TravelerCount)
+
+	flight := it.Graph.Edges[0].Transport
+	assert.Equal(t, int32(3), flight.TravelerCount)
+	assert.Equal(t, pb.Class_CLASS_BUSINESS, flight.FlightPreferences.TravelClass)
+	assert.Equal(t, int32(0), flight.FlightPreferences.GetMaxStops())
+	assert.NotNil(t, flight.FlightPreferences.MaxStops)
+
+	train := it.Graph.Edges[1].Transport
+	assert.Equal(t, int32(3), train.TravelerCount)
+	assert.Nil(t, train.FlightPreferences)
+
+	// Nothing stated changes nothing
+	before := proto.Clone(it)
+	QueryConstraints{}.Apply(it)
+	assert.True(t, proto.Equal(before, it))
+}
+
+func TestTravelAgent_OrchestrateRequest_AppliesConstraints(t *testing.T) {
+	// The planner forgets the cabin the user asked for
+	plan := stayPlan("Paris", nil)
+	plan.Graph.Edges = []*pb.Edge{{Transport: &pb.Transport{Type: pb.TransportType_TRANSPORT_TYPE_FLIGHT, TravelerCount: 1}}}
+
+	planner := new(MockPlanner)
+	planner.On("Plan", mock.Anything, mock.Anything).Return(&PlanResult{PossibleItineraries: []*pb.Itinerary{plan}}, nil)
+
+	_, itineraries, err := NewTravelAgent(planner, &checkedDesk{}).OrchestrateRequest(context.Background(), "Paris for 2 adults, business class, nonstop", "")
+	assert.NoError(t, err)
+	if assert.Len(t, itineraries, 1) {
+		flight := itineraries[0].Graph.Edges[0].Transport
+		assert.Equal(t, int32(2), itineraries[0].Travelers)
+		assert.Equal(t, int32(2), flight.TravelerCount)
+		assert.Equal(t, pb.Class_CLASS_BUSINESS, flight.FlightPreferences.GetTravelClass())
+		assert.NotNil(t, flight.FlightPreferences.MaxStops)
+	}
+}
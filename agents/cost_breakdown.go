@@ -0,0 +1,73 @@
+package agents
+
+import (
+	"fmt"
+
+	"github.com/va6996/travelingman/pb"
+)
+
+// computeCostBreakdown adds up the selected transports, stays and flight extras of an
+// itinerary, including its sub-graphs. Amounts are in the currency of the first cost
+// found; costs in any other currency are left out and the breakdown marked partial.
+func computeCostBreakdown(it *pb.Itinerary) *pb.CostBreakdown {
+	var currency string
+	var transport, accommodation, ancillaries float64
+	partial := false
+
+	// add counts c towards sum, taking its currency if it is the first cost seen
+	add := func(sum *float64, c *pb.Cost) {
+		if c.GetValue() == 0 {
+			return
+		}
+		if currency == "" {
+			currency = c.Currency
+		}
+		if c.Currency != currency {
+			partial = true
+			return
+		}
+		*sum += c.Value
+	}
+
+	var walk func(g *pb.Graph)
+	walk = func(g *pb.Graph) {
+		if g == nil {
+			return
+		}
+		for _, e := range g.Edges {
+			t := e.Transport
+			add(&transport, t.GetCost())
+			for _, a := range t.GetFlight().GetAncillaryCosts() {
+				add(&ancillaries, a.GetCost())
+			}
+		}
+		for _, n := range g.Nodes {
+			add(&accommodation, n.GetStay().GetCost())
+			walk(n.SubGraph)
+		}
+		walk(g.SubGraph)
+	}
+	walk(it.GetGraph())
+
+	return &pb.CostBreakdown{
+		Transport:     &pb.Cost{Value: transport, Currency: currency},
+		Accommodation: &pb.Cost{Value: accommodation, Currency: currency},
+		Ancillaries:   &pb.Cost{Value: ancillaries, Currency: currency},
+		Total:         &pb.Cost{Value: transport + accommodation + ancillaries, Currency: currency},
+		Partial:       partial,
+	}
+}
+
+// formatCostBreakdown renders a breakdown, e.g. "Total: 1234.00 USD (transport 800.00,
+// accommodation 400.00, extras 34.00)"
+func formatCostBreakdown(b *pb.CostBreakdown) string {
+	if b.GetTotal().GetValue() == 0 {
+		return ""
+	}
+	s := fmt.Sprintf("Total: %.2f %s (transport %.2f, accommodation %.2f, extras %.2f)",
+		b.Total.Value, b.Total.Currency, b.GetTransport().GetValue(), b.GetAccommodation().GetValue(), b.GetAncillaries().GetValue())
+	if b.Partial {
+		s += "; some costs in other currencies are not included"
+	}
+	return s
+}
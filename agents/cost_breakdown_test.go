@@ -0,0 +1,100 @@
+package agents
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// costFixture is a return flight to Denver with a checked bag, a stay and a train on a
+// day trip
+func costFixture() *pb.Itinerary {
+	usd := func(v float64) *pb.Cost { return &pb.Cost{Value: v, Currency: "USD"} }
+	start := time.Date(2026, 6, 1, 8, 0, 0, 0, time.UTC)
+	it := &pb.Itinerary{
+		Title:     "Denver",
+		StartTime: timestamppb.New(start),
+		Travelers: 2,
+		Graph: &pb.Graph{
+			Nodes: []*pb.Node{
+				{Id: "n1", Location: &pb.Location{City: "Chicago", IataCodes: []string{"ORD"}}},
+				{Id: "n2", Location: &pb.Location{City: "Denver", IataCodes: []string{"DEN"}}, Stay: &pb.Accommodation{TravelerCount: 2}},
+			},
+			Edges: []*pb.Edge{{FromId: "n1", ToId: "n2", Transport: &pb.Transport{
+				Type:    pb.TransportType_TRANSPORT_TYPE_FLIGHT,
+				Details: &pb.Transport_Flight{Flight: &pb.Flight{DepartureTime: timestamppb.New(start)}},
+			}}},
+		},
+	}
+	g := it.Graph
+
+	outbound := g.Edges[0].Transport
+	outbound.Cost = usd(180)
+	outbound.GetFlight().AncillaryCosts = []*pb.AncillaryCost{{Type: "BAGGAGE", Cost: usd(35)}}
+	g.Edges = append(g.Edges, &pb.Edge{FromId: "n2", ToId: "n1", Transport: &pb.Transport{
+		Type: pb.TransportType_TRANSPORT_TYPE_FLIGHT,
+		Cost: usd(160),
+	}})
+
+	denver := g.Nodes[1]
+	denver.Stay.Cost = usd(450)
+	denver.SubGraph = &pb.Graph{
+		Nodes: []*pb.Node{{Id: "boulder"}},
+		Edges: []*pb.Edge{{FromId: "n2", ToId: "boulder", Transport: &pb.Transport{
+			Type:    pb.TransportType_TRANSPORT_TYPE_TRAIN,
+			Cost:    usd(12.5),
+			Details: &pb.Transport_Train{Train: &pb.Train{DepartureTime: timestamppb.New(time.Date(2026, 6, 2, 9, 0, 0, 0, time.UTC))}},
+		}}},
+	}
+	return it
+}
+
+func TestComputeCostBreakdown(t *testing.T) {
+	b := computeCostBreakdown(costFixture())
+
+	assert.Equal(t, 180+160+12.5, b.Transport.Value, "flights and the day trip train")
+	assert.Equal(t, 450.0, b.Accommodation.Value)
+	assert.Equal(t, 35.0, b.Ancillaries.Value)
+	assert.Equal(t, b.Transport.Value+b.Accommodation.Value+b.Ancillaries.Value, b.Total.Value, "the subtotals sum to the total")
+	assert.Equal(t, 837.5, b.Total.Value)
+	assert.Equal(t, "USD", b.Total.Currency)
+	assert.False(t, b.Partial)
+
+	assert.Equal(t, "Total: 837.50 USD (transport 352.50, accommodation 450.00, extras 35.00)", formatCostBreakdown(b))
+}
+
+func TestComputeCostBreakdown_OtherCurrency(t *testing.T) {
+	it := costFixture()
+	it.Graph.Nodes[1].Stay.Cost = &pb.Cost{Value: 400, Currency: "EUR"}
+
+	b := computeCostBreakdown(it)
+	assert.Equal(t, 0.0, b.Accommodation.Value)
+	assert.Equal(t, b.Transport.Value+b.Accommodation.Value+b.Ancillaries.Value, b.Total.Value)
+	assert.True(t, b.Partial)
+	assert.Contains(t, formatCostBreakdown(b), "some costs in other currencies are not included")
+
+	assert.Empty(t, formatCostBreakdown(computeCostBreakdown(&pb.Itinerary{})), "nothing priced, nothing to show")
+}
+
+func TestTravelAgent_ScoreAndTag_CostBreakdown(t *testing.T) {
+	it := costFixture()
+	it.Graph.Nodes[1].StayOptions = []*pb.Accommodation{
+		{Name: "Pricey", Cost: &pb.Cost{Value: 600, Currency: "USD"}},
+		{Name: "Cheap", Cost: &pb.Cost{Value: 300, Currency: "USD"}},
+	}
+
+	agent := NewTravelAgent(nil, nil)
+	agent.scoreAndTag([]*pb.Itinerary{it})
+
+	// The breakdown follows the options selected while ranking
+	b := it.CostBreakdown
+	if assert.NotNil(t, b) {
+		assert.Equal(t, 300.0, b.Accommodation.Value)
+		assert.Equal(t, b.Transport.Value+b.Accommodation.Value+b.Ancillaries.Value, b.Total.Value)
+		assert.Equal(t, calculateItineraryScore(it)+b.Ancillaries.Value+12.5, b.Total.Value,
+			"the top-level total plus extras and the day trip")
+	}
+}
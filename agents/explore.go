@@ -0,0 +1,147 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/va6996/travelingman/log"
+	"github.com/va6996/travelingman/pb"
+)
+
+// TagDestinationIdea marks the itineraries of an exploratory plan: a destination priced
+// by its outbound leg, not a verified trip
+const TagDestinationIdea = "Destination Idea"
+
+// exploreTopDestinations is how many of the cheapest candidate destinations an
+// exploratory plan returns
+const exploreTopDestinations = 3
+
+// ProbeDestination searches the outbound leg of a candidate destination once and
+// returns its cheapest option. Stays and later legs are not checked.
+func (td *TravelDesk) ProbeDestination(ctx context.Context, it *pb.Itinerary) (*pb.Transport, error) {
+	edge := outboundLeg(it.GetGraph())
+	if edge == nil {
+		return nil, errors.New("no outbound leg to price")
+	}
+	td.EnrichGraph(ctx, &pb.Itinerary{Graph: &pb.Graph{Edges: []*pb.Edge{edge}}})
+
+	provider, ok := td.Transports[edge.Transport.Type]
+	if !ok {
+		return nil, fmt.Errorf("no provider for %s", strings.ToLower(transportLabel(edge.Transport.Type)))
+	}
+	options, err := provider.SearchTransport(ctx, edge.Transport)
+	if err != nil {
+		return nil, err
+	}
+	return cheapestOption(options), nil
+}
+
+// outboundLeg is the first edge of a graph with a transport, or nil if there is none
+func outboundLeg(g *pb.Graph) *pb.Edge {
+	for _, e := range g.GetEdges() {
+		if e.GetTransport() != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+// cheapestOption is the lowest priced of options, or nil if none has a price
+func cheapestOption(options []*pb.Transport) *pb.Transport {
+	var cheapest *pb.Transport
+	for _, o := range options {
+		if o.GetCost().GetValue() > 0 && (cheapest == nil || o.Cost.Value < cheapest.Cost.Value) {
+			cheapest = o
+		}
+	}
+	return cheapest
+}
+
+// exploreDestinations answers an exploratory plan: every candidate destination is
+// probed with one search and the cheapest to get to are returned, each trimmed to its
+// outbound leg with the option found selected
+func (ta *TravelAgent) exploreDestinations(ctx context.Context, planRes *PlanResult) (string, []*pb.Itinerary, error) {
+	prober, ok := ta.desk.(DestinationProber)
+	if !ok {
+		return "", nil, errors.New("travel desk cannot price candidate destinations")
+	}
+	candidates := planRes.PossibleItineraries
+	log.Infof(ctx, "STEP 2: Pricing %d candidate destinations...", len(candidates))
+
+	prices := make([]*pb.Transport, len(candidates))
+	var wg sync.WaitGroup
+	for i, it := range candidates {
+		wg.Add(1)
+		go func(i int, it *pb.Itinerary) {
+			defer wg.Done()
+			option, err := prober.ProbeDestination(ctx, it)
+			switch {
+			case err != nil:
+				log.Warnf(ctx, "Could not price destination %q: %v", it.GetTitle(), err)
+			case option == nil:
+				log.Warnf(ctx, "No priced options to destination %q", it.GetTitle())
+			}
+			prices[i] = option
+		}(i, it)
+	}
+	wg.Wait()
+
+	var ideas []*pb.Itinerary
+	for i, it := range candidates {
+		if prices[i] == nil {
+			continue
+		}
+		ideas = append(ideas, destinationIdea(it, prices[i]))
+	}
+	if len(ideas) == 0 {
+		return "I couldn't find flights to any of the destinations I had in mind. Could you tell me more about where you'd like to go?", nil, nil
+	}
+
+	// Cheapest first; equal ones keep the planner's order
+	sort.SliceStable(ideas, func(i, j int) bool {
+		return ideas[i].CostBreakdown.GetTotal().GetValue() < ideas[j].CostBreakdown.GetTotal().GetValue()
+	})
+	if len(ideas) > exploreTopDestinations {
+		ideas = ideas[:exploreTopDestinations]
+	}
+	log.Infof(ctx, "STEP 3: Priced %d of %d destinations, returning the %d cheapest", len(ideas), len(candidates), len(ideas))
+
+	var response strings.Builder
+	fmt.Fprintf(&response, "Here are the destinations that fit your request, cheapest to get to first:\n\n%s\n\n", planRes.Reasoning)
+	for i, idea := range ideas {
+		total := idea.CostBreakdown.Total
+		fmt.Fprintf(&response, "### Option %d: %s\nGetting there from %.2f %s\n", i+1, idea.Title, total.Value, total.Currency)
+	}
+	return response.String(), ideas, nil
+}
+
+// destinationIdea is a candidate destination reduced to its outbound leg, with option
+// selected on it
+func destinationIdea(it *pb.Itinerary, option *pb.Transport) *pb.Itinerary {
+	edge := outboundLeg(it.Graph)
+	idea := &pb.Itinerary{
+		Title:       it.Title,
+		Description: it.Description,
+		StartTime:   it.StartTime,
+		EndTime:     it.EndTime,
+		Travelers:   it.Travelers,
+		Tags:        []string{TagDestinationIdea},
+		Graph: &pb.Graph{Edges: []*pb.Edge{{
+			FromId:           edge.FromId,
+			ToId:             edge.ToId,
+			Transport:        option,
+			TransportOptions: []*pb.Transport{option},
+		}}},
+	}
+	for _, n := range it.Graph.Nodes {
+		if n.Id == edge.FromId || n.Id == edge.ToId {
+			idea.Graph.Nodes = append(idea.Graph.Nodes, &pb.Node{Id: n.Id, Location: n.Location})
+		}
+	}
+	idea.CostBreakdown = computeCostBreakdown(idea)
+	return idea
+}
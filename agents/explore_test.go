@@ -0,0 +1,144 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	tmcontext "github.com/va6996/travelingman/context"
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// probeDesk prices each destination from a fixed fare list and records what it was
+// asked to probe. Full availability checks fail the test.
+type probeDesk struct {
+	t     *testing.T
+	fares map[string]float64
+
+	mu     sync.Mutex
+	probed []string
+}
+
+func (d *probeDesk) CheckAvailability(ctx context.Context, it *pb.Itinerary) (*pb.Itinerary, error) {
+	d.t.Errorf("candidate %q was verified in full", it.Title)
+	return it, nil
+}
+
+func (d *probeDesk) ProbeDestination(ctx context.Context, it *pb.Itinerary) (*pb.Transport, error) {
+	d.mu.Lock()
+	d.probed = append(d.probed, it.Title)
+	d.mu.Unlock()
+
+	fare, ok := d.fares[it.Title]
+	if !ok {
+		return nil, errors.New("no flights")
+	}
+	return &pb.Transport{Type: pb.TransportType_TRANSPORT_TYPE_FLIGHT, Cost: &pb.Cost{Value: fare, Currency: "USD"}}, nil
+}
+
+// fareList offers the same options for every search and counts the searches
+type fareList struct {
+	options []*pb.Transport
+	calls   int
+}
+
+func (f *fareList) SearchTransport(ctx context.Context, t *pb.Transport) ([]*pb.Transport, error) {
+	f.calls++
+	return f.options, nil
+}
+
+// candidate is a destination the planner proposes: a flight out of New York and a stay
+func candidate(city, iata string) *pb.Itinerary {
+	dep := time.Date(2026, 3, 6, 9, 0, 0, 0, time.UTC)
+	return &pb.Itinerary{
+		Title: city,
+		Graph: &pb.Graph{
+			Nodes: []*pb.Node{
+				{Id: "home", Location: &pb.Location{City: "New York", IataCodes: []string{"JFK"}}},
+				{Id: "dest", Location: &pb.Location{City: city, IataCodes: []string{iata}}, Stay: &pb.Accommodation{}},
+			},
+			Edges: []*pb.Edge{{FromId: "home", ToId: "dest", Transport: &pb.Transport{
+				Type:    pb.TransportType_TRANSPORT_TYPE_FLIGHT,
+				Details: &pb.Transport_Flight{Flight: &pb.Flight{DepartureTime: timestamppb.New(dep)}},
+			}}},
+		},
+	}
+}
+
+func TestTravelAgent_OrchestrateRequest_Exploratory(t *testing.T) {
+	planner := new(MockPlanner)
+	planner.On("Plan", mock.Anything, mock.Anything).Return(&PlanResult{
+		Exploratory: true,
+		Reasoning:   "Warm in March, in Europe",
+		PossibleItineraries: []*pb.Itinerary{
+			candidate("Seville", "SVQ"),
+			candidate("Lisbon", "LIS"),
+			candidate("Valletta", "MLA"),
+			candidate("Athens", "ATH"),
+			candidate("Palermo", "PMO"),
+		},
+	}, nil).Once()
+	desk := &probeDesk{t: t, fares: map[string]float64{"Seville": 480, "Lisbon": 390, "Valletta": 455, "Athens": 520}}
+
+	response, itineraries, err := NewTravelAgent(planner, desk).OrchestrateRequest(context.Background(), "Somewhere warm in Europe under $500", "")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"Seville", "Lisbon", "Valletta", "Athens", "Palermo"}, desk.probed)
+
+	// The cheapest three, each reduced to its priced outbound leg
+	var titles []string
+	for _, it := range itineraries {
+		titles = append(titles, it.Title)
+		assert.Equal(t, []string{TagDestinationIdea}, it.Tags)
+		assert.Len(t, it.Graph.Edges, 1)
+		assert.Nil(t, it.Graph.Nodes[1].Stay)
+	}
+	assert.Equal(t, []string{"Lisbon", "Valletta", "Seville"}, titles)
+	assert.Equal(t, 390.0, itineraries[0].CostBreakdown.Total.Value)
+	assert.Contains(t, response, "### Option 1: Lisbon\nGetting there from 390.00 USD")
+	planner.AssertExpectations(t)
+}
+
+func TestTravelAgent_OrchestrateRequest_ExploratoryNothingPriced(t *testing.T) {
+	planner := new(MockPlanner)
+	planner.On("Plan", mock.Anything, mock.Anything).Return(&PlanResult{
+		Exploratory:         true,
+		PossibleItineraries: []*pb.Itinerary{candidate("Seville", "SVQ")},
+	}, nil).Once()
+
+	response, itineraries, err := NewTravelAgent(planner, &probeDesk{t: t}).OrchestrateRequest(context.Background(), "Somewhere warm", "")
+	assert.NoError(t, err)
+	assert.Empty(t, itineraries)
+	assert.Contains(t, response, "Could you tell me more")
+}
+
+func TestTravelDesk_ProbeDestination(t *testing.T) {
+	fares := &fareList{options: []*pb.Transport{
+		{Cost: &pb.Cost{Value: 410, Currency: "USD"}},
+		{Cost: &pb.Cost{Value: 0, Currency: "USD"}},
+		{Cost: &pb.Cost{Value: 395, Currency: "USD"}},
+	}}
+	desk := NewTravelDesk(nil)
+	desk.Transports.Register(pb.TransportType_TRANSPORT_TYPE_FLIGHT, fares)
+
+	// The memo answers the location search, so no provider is needed
+	ctx := tmcontext.WithLocationMemo(context.Background(), tmcontext.NewLocationMemo())
+	tmcontext.LocationMemoFromContext(ctx).Resolve(ctx, "JFK", func(context.Context, string) ([]*pb.Location, error) {
+		return []*pb.Location{{City: "NEW YORK", CityCode: "NYC", IataCodes: []string{"JFK"}}}, nil
+	})
+
+	it := candidate("Lisbon", "LIS")
+	it.Graph.Edges[0].Transport.OriginLocation = &pb.Location{IataCodes: []string{"JFK"}}
+	option, err := desk.ProbeDestination(ctx, it)
+	assert.NoError(t, err)
+	assert.Equal(t, 395.0, option.GetCost().GetValue())
+	assert.Equal(t, 1, fares.calls, "one search per destination")
+	assert.Equal(t, "NYC", it.Graph.Edges[0].Transport.OriginLocation.CityCode)
+
+	_, err = desk.ProbeDestination(ctx, &pb.Itinerary{Graph: &pb.Graph{}})
+	assert.Error(t, err)
+}
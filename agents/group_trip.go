@@ -0,0 +1,133 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/va6996/travelingman/log"
+	"github.com/va6996/travelingman/orm"
+	"github.com/va6996/travelingman/pb"
+	"github.com/va6996/travelingman/plugins/amadeus"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+// ErrNotOrganizer is returned when someone other than a group's organizer plans for it
+var ErrNotOrganizer = errors.New("only the group's organizer can plan its trips")
+
+// GroupService plans and books trips for travel groups. The organizer plans once for
+// everyone: every plan is for as many travellers as the group has members, and what is
+// booked is recorded against the group.
+type GroupService struct {
+	DB       *gorm.DB
+	Agent    *TravelAgent
+	Bookings *BookingTracker
+}
+
+// NewGroupService creates a GroupService
+func NewGroupService(db *gorm.DB, agent *TravelAgent, bookings *BookingTracker) *GroupService {
+	return &GroupService{DB: db, Agent: agent, Bookings: bookings}
+}
+
+// CreateGroup creates a group organized by organizerID, who is its first member
+func (s *GroupService) CreateGroup(ctx context.Context, name string, organizerID uint, destination string, travelDate time.Time) (*pb.TravelGroup, error) {
+	group := &pb.TravelGroup{
+		Name:        name,
+		OrganizerId: int64(organizerID),
+		Destination: destination,
+		TravelDate:  timestamppb.New(travelDate),
+	}
+	if err := orm.CreateTravelGroup(s.DB, group); err != nil {
+		return nil, fmt.Errorf("failed to create group: %w", err)
+	}
+	if err := orm.AddMember(s.DB, uint(group.GroupId), organizerID); err != nil {
+		return nil, fmt.Errorf("failed to add organizer to group %d: %w", group.GroupId, err)
+	}
+	log.Infof(ctx, "Created group %d %q organized by user %d", group.GroupId, name, organizerID)
+	return orm.GetTravelGroupMembers(s.DB, uint(group.GroupId))
+}
+
+// AddMember adds a user to a group
+func (s *GroupService) AddMember(ctx context.Context, groupID, userID uint) error {
+	if _, err := orm.GetTravelGroupMembers(s.DB, groupID); err != nil {
+		return fmt.Errorf("group %d: %w", groupID, err)
+	}
+	if err := orm.AddMember(s.DB, groupID, userID); err != nil {
+		return fmt.Errorf("failed to add user %d to group %d: %w", userID, groupID, err)
+	}
+	log.Infof(ctx, "Added user %d to group %d", userID, groupID)
+	return nil
+}
+
+// PlanTrip plans a trip for a group on behalf of its organizer. The planner is told the
+// group's size, and every planned itinerary is set to the group's traveller count and
+// tagged with the group before it is verified, so prices are for the whole group. The
+// group's destination and travel date are pinned, so revisions cannot move them.
+func (s *GroupService) PlanTrip(ctx context.Context, groupID, organizerID uint, query string) (string, []*pb.Itinerary, error) {
+	group, err := orm.GetTravelGroupMembers(s.DB, groupID)
+	if err != nil {
+		return "", nil, fmt.Errorf("group %d: %w", groupID, err)
+	}
+	if group.OrganizerId != int64(organizerID) {
+		return "", nil, ErrNotOrganizer
+	}
+	travelers := int32(len(group.Members))
+	if travelers == 0 {
+		return "", nil, fmt.Errorf("group %d has no members", groupID)
+	}
+
+	log.Infof(ctx, "Planning for group %d (%d travelers): %s", groupID, travelers, query)
+	query = fmt.Sprintf("%s\nWe are a group of %d travelers.", query, travelers)
+	pins := TripPins{Destination: group.Destination, Travelers: travelers}
+	if group.TravelDate != nil {
+		pins.StartDate = group.TravelDate.AsTime()
+	}
+	return s.Agent.orchestrate(ctx, query, "", pins, func(it *pb.Itinerary) {
+		setGroup(it, group.GroupId, travelers)
+	})
+}
+
+// BookFlight books a flight offer for every member of a group and records the booking
+// against the group
+func (s *GroupService) BookFlight(ctx context.Context, groupID uint, offer amadeus.FlightOffer, split *pb.PaymentSplit) (*FlightBooking, error) {
+	group, err := orm.GetTravelGroupMembers(s.DB, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("group %d: %w", groupID, err)
+	}
+	booking, err := s.Bookings.BookFlight(ctx, offer, group.Members, split)
+	if err != nil {
+		return booking, err
+	}
+	if booking.Order.BookingID == 0 {
+		return booking, fmt.Errorf("order %s was placed but not stored, so it was not recorded for group %d", booking.Order.Data.ID, groupID)
+	}
+	if err := orm.SetBookingGroup(s.DB, booking.Order.BookingID, groupID); err != nil {
+		return booking, fmt.Errorf("order %s was placed but not recorded for group %d: %w", booking.Order.Data.ID, groupID, err)
+	}
+	return booking, nil
+}
+
+// setGroup makes it a plan for a group of travelers: the itinerary, every transport
+// and every stay, sub-graphs included, are for all of them and belong to the group
+func setGroup(it *pb.Itinerary, groupID int64, travelers int32) {
+	it.GroupId = groupID
+	it.Travelers = travelers
+	var walk func(g *pb.Graph)
+	walk = func(g *pb.Graph) {
+		for _, n := range g.GetNodes() {
+			if n.Stay != nil {
+				n.Stay.GroupId = groupID
+				n.Stay.TravelerCount = travelers
+			}
+			walk(n.SubGraph)
+		}
+		for _, e := range g.GetEdges() {
+			if e.Transport != nil {
+				e.Transport.TravelerCount = travelers
+			}
+		}
+	}
+	walk(it.GetGraph())
+}
@@ -0,0 +1,80 @@
+package agents
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/va6996/travelingman/orm"
+	"github.com/va6996/travelingman/pb"
+)
+
+func TestGroupService_PlanTrip(t *testing.T) {
+	ctx := context.Background()
+	bt, _ := setupBookingTracker(t, nil)
+	assert.NoError(t, bt.DB.AutoMigrate(&orm.User{}, &orm.TravelGroup{}))
+	booker := &fakeBooker{db: bt.DB}
+	bt.Booker = booker
+
+	var members []*pb.User
+	for _, name := range []string{"Ada Lovelace", "Alan Turing", "Grace Hopper"} {
+		u := &pb.User{FullName: name}
+		assert.NoError(t, orm.CreateUser(bt.DB, u))
+		members = append(members, u)
+	}
+	organizer := uint(members[0].Id)
+
+	// The planner proposes a trip for one; the group has three
+	mockPlanner := new(MockPlanner)
+	mockPlanner.On("Plan", mock.Anything, mock.MatchedBy(func(req PlanRequest) bool {
+		return req.UserQuery == "London to New York in two weeks\nWe are a group of 3 travelers."
+	})).Return(&PlanResult{
+		PossibleItineraries: []*pb.Itinerary{draftItinerary(time.Now().Add(14 * 24 * time.Hour).UTC().Truncate(time.Hour))},
+	}, nil).Once()
+	desk := &stubDesk{}
+	groups := NewGroupService(bt.DB, NewTravelAgent(mockPlanner, desk), bt)
+
+	group, err := groups.CreateGroup(ctx, "Pioneers", organizer, "New York", time.Now().Add(14*24*time.Hour))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, group.Members, 1, "the organizer is a member")
+	groupID := uint(group.GroupId)
+	assert.NoError(t, groups.AddMember(ctx, groupID, uint(members[1].Id)))
+	assert.NoError(t, groups.AddMember(ctx, groupID, uint(members[2].Id)))
+
+	// Only the organizer plans for the group
+	_, _, err = groups.PlanTrip(ctx, groupID, uint(members[1].Id), "London to New York in two weeks")
+	assert.ErrorIs(t, err, ErrNotOrganizer)
+
+	_, itineraries, err := groups.PlanTrip(ctx, groupID, organizer, "London to New York in two weeks")
+	if !assert.NoError(t, err) || !assert.Len(t, itineraries, 1) {
+		return
+	}
+	mockPlanner.AssertExpectations(t)
+
+	// Everything is verified for the whole group
+	if assert.Len(t, desk.checked, 1) {
+		checked := desk.checked[0]
+		assert.Equal(t, int32(3), checked.Travelers)
+		assert.Equal(t, group.GroupId, checked.GroupId)
+		assert.Equal(t, int32(3), checked.Graph.Edges[0].Transport.TravelerCount)
+		assert.Equal(t, int32(3), checked.Graph.Nodes[1].Stay.TravelerCount)
+		assert.Equal(t, group.GroupId, checked.Graph.Nodes[1].Stay.GroupId)
+	}
+	assert.Equal(t, group.GroupId, itineraries[0].GroupId)
+
+	// A flight booked for the group is booked for every member and recorded against it
+	booking, err := groups.BookFlight(ctx, groupID, bookedOffer("2030-06-01T09:30:00", "2030-06-01T21:30:00"), nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, booking.Order.TravelerUserIDs, 3)
+	stored, err := orm.GetGroupBookings(bt.DB, groupID)
+	if assert.NoError(t, err) && assert.Len(t, stored, 1) {
+		assert.Equal(t, booking.Order.BookingID, stored[0].ID)
+		assert.ElementsMatch(t, []int64{members[0].Id, members[1].Id, members[2].Id}, stored[0].Travelers)
+	}
+}
@@ -0,0 +1,67 @@
+package agents
+
+import (
+	"sort"
+
+	"github.com/va6996/travelingman/plugins/amadeus"
+)
+
+// pickHotels chooses which hotels of a city list to price, at most limit of them from
+// the first consider. The list is in the provider's order, so taking its top misses
+// better-priced hotels further down. Instead every rating tier, best first, gets a
+// slot in turn, and each tier's slots are spread evenly through that tier. The IDs
+// are returned in list order.
+func pickHotels(hotels []amadeus.HotelData, consider, limit int) []string {
+	consider = max(consider, limit)
+	if len(hotels) > consider {
+		hotels = hotels[:consider]
+	}
+
+	picked := make([]bool, len(hotels))
+	if len(hotels) <= limit {
+		for i := range picked {
+			picked[i] = true
+		}
+		return pickedHotelIDs(hotels, picked)
+	}
+
+	// Indexes into hotels by rating; unrated hotels sort last
+	tiers := map[int][]int{}
+	var ratings []int
+	for i, h := range hotels {
+		if _, ok := tiers[h.Rating]; !ok {
+			ratings = append(ratings, h.Rating)
+		}
+		tiers[h.Rating] = append(tiers[h.Rating], i)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(ratings)))
+
+	// Deal the slots one per tier until they run out
+	shares := make([]int, len(ratings))
+	for n := 0; n < limit; {
+		for i, r := range ratings {
+			if n < limit && shares[i] < len(tiers[r]) {
+				shares[i]++
+				n++
+			}
+		}
+	}
+
+	for i, r := range ratings {
+		tier := tiers[r]
+		for j := 0; j < shares[i]; j++ {
+			picked[tier[j*len(tier)/shares[i]]] = true
+		}
+	}
+	return pickedHotelIDs(hotels, picked)
+}
+
+func pickedHotelIDs(hotels []amadeus.HotelData, picked []bool) []string {
+	var ids []string
+	for i, h := range hotels {
+		if picked[i] {
+			ids = append(ids, h.HotelId)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,45 @@
+package agents
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/plugins/amadeus"
+)
+
+func TestPickHotels(t *testing.T) {
+	hotels := []amadeus.HotelData{
+		{HotelId: "A", Rating: 5},
+		{HotelId: "B", Rating: 3},
+		{HotelId: "C", Rating: 5},
+		{HotelId: "D"},
+		{HotelId: "E", Rating: 3},
+		{HotelId: "F", Rating: 5},
+		{HotelId: "G", Rating: 4},
+	}
+
+	tests := []struct {
+		name     string
+		consider int
+		limit    int
+		want     []string
+	}{
+		{"OnePerTier", 7, 4, []string{"A", "B", "D", "G"}},
+		{"BestTierFirst", 7, 5, []string{"A", "B", "C", "D", "G"}},
+		{"ConsiderCapsTheList", 3, 2, []string{"A", "B"}},
+		{"ConsiderBelowLimit", 0, 2, []string{"A", "B"}},
+		{"ShortList", 10, 10, []string{"A", "B", "C", "D", "E", "F", "G"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, pickHotels(hotels, tt.consider, tt.limit))
+		})
+	}
+
+	// Unrated hotels are sampled evenly through the list
+	var unrated []amadeus.HotelData
+	for _, id := range []string{"H1", "H2", "H3", "H4", "H5", "H6", "H7", "H8", "H9"} {
+		unrated = append(unrated, amadeus.HotelData{HotelId: id})
+	}
+	assert.Equal(t, []string{"H1", "H4", "H7"}, pickHotels(unrated, 9, 3))
+}
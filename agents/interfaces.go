@@ -4,13 +4,63 @@ import (
 	"context"
 
 	"github.com/va6996/travelingman/pb"
+	"github.com/va6996/travelingman/plugins/amadeus"
 )
 
 type Planner interface {
 	Plan(ctx context.Context, req PlanRequest) (*PlanResult, error)
+	// Capabilities tells the orchestrator what the planner does itself, so it can make
+	// up for the rest
+	Capabilities() PlannerCapabilities
+}
+
+// PlannerCapabilities describes what a planner supports
+type PlannerCapabilities struct {
+	// Streaming is set when the planner passes its text and tool calls to the sinks on
+	// the request context as it goes
+	Streaming bool
+	// ToolCalling is set when the planner calls tools while planning
+	ToolCalling bool
 }
 
 type Assistant interface {
 	CheckAvailability(ctx context.Context, req *pb.Itinerary) (*pb.Itinerary, error)
 }
 
+// DestinationProber prices a candidate destination of an exploratory plan with a single
+// cheap search instead of a full availability check. It returns the cheapest option for
+// the candidate's outbound leg. An Assistant may implement it.
+type DestinationProber interface {
+	ProbeDestination(ctx context.Context, it *pb.Itinerary) (*pb.Transport, error)
+}
+
+// TransportSearcher finds bookable options for a transport leg. A searcher may also
+// implement MapError(error) pb.ErrorCode to classify its errors.
+type TransportSearcher interface {
+	SearchTransport(ctx context.Context, t *pb.Transport) ([]*pb.Transport, error)
+}
+
+// FlightOrderSource looks up and cancels flight orders at the provider they were booked
+// with. It returns amadeus.ErrOrderNotFound for orders that no longer exist.
+type FlightOrderSource interface {
+	GetFlightOrder(ctx context.Context, orderID string) (*amadeus.FlightOrderResponse, error)
+	CancelFlightOrder(ctx context.Context, orderID string) error
+}
+
+// FlightBooker places flight orders for users with a provider
+type FlightBooker interface {
+	BookFlight(ctx context.Context, offer amadeus.FlightOffer, users []*pb.User) (*amadeus.FlightOrderResponse, error)
+}
+
+// HotelBooker books hotel offers and looks up and cancels the orders it placed. It
+// returns amadeus.ErrOrderNotFound for orders that no longer exist.
+type HotelBooker interface {
+	BookHotel(ctx context.Context, offerID string, guests []amadeus.HotelGuest, payment amadeus.HotelPayment) (*amadeus.HotelOrderResponse, error)
+	GetHotelOrder(ctx context.Context, orderID string) (*amadeus.HotelOrder, error)
+	CancelHotelOrder(ctx context.Context, orderID string) error
+}
+
+// BookingNotifier tells the traveler about a change to one of their bookings
+type BookingNotifier interface {
+	NotifyBookingChange(ctx context.Context, change *pb.BookingStatusChange) error
+}
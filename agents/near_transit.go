@@ -0,0 +1,63 @@
+package agents
+
+import (
+	tmcore "github.com/va6996/travelingman/core"
+	"github.com/va6996/travelingman/pb"
+)
+
+// Tags of stays close to the airport or station the traveller arrives at, for users
+// who want to stay near transit
+const (
+	TagNearAirport = "Near Airport"
+	TagNearStation = "Near Station"
+)
+
+// nearTransitKm is how close to the arrival airport or station a stay must be to be
+// tagged as near it
+const nearTransitKm = 5.0
+
+// transitValuePerKm is what each kilometre between a stay and the arrival airport or
+// station is worth to a user who wants to stay near transit, roughly a taxi fare for
+// the ride there and back
+const transitValuePerKm = 4.0
+
+// arrival is where the traveller arrives at a node by air or rail
+type arrival struct {
+	lat, lng float64
+	tag      string
+}
+
+// arrivalAt finds the airport or station of the transport arriving at a node, or nil
+// if none arrives there or it cannot be placed. The edges' options are expected to be
+// ranked already, so the transport is the one selected.
+func (ta *TravelAgent) arrivalAt(g *pb.Graph, nodeID string) *arrival {
+	for _, edge := range g.GetEdges() {
+		t := edge.GetTransport()
+		if edge.GetToId() != nodeID || t == nil {
+			continue
+		}
+		var tag string
+		switch t.GetType() {
+		case pb.TransportType_TRANSPORT_TYPE_FLIGHT:
+			tag = TagNearAirport
+		case pb.TransportType_TRANSPORT_TYPE_TRAIN:
+			tag = TagNearStation
+		default:
+			continue
+		}
+		if lat, lng, ok := ta.Places.LocateAirport(t.GetDestinationLocation()); ok {
+			return &arrival{lat: lat, lng: lng, tag: tag}
+		}
+	}
+	return nil
+}
+
+// distanceKm is how far a stay is from the arrival, if the stay has a geocode
+func (a *arrival) distanceKm(s *pb.Accommodation) (float64, bool) {
+	lat, lng, ok := tmcore.ParseGeocode(s.GetLocation().GetGeocode())
+	// Hotels without coordinates come back at 0,0
+	if !ok || (lat == 0 && lng == 0) {
+		return 0, false
+	}
+	return tmcore.DistanceKm(a.lat, a.lng, lat, lng), true
+}
@@ -0,0 +1,90 @@
+package agents
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// transitItinerary flies into JFK for one night, with a cheaper hotel in Midtown and
+// a pricier one by the airport
+func transitItinerary(nearTransit bool) *pb.Itinerary {
+	checkIn := time.Date(2026, 6, 1, 22, 0, 0, 0, time.UTC)
+	offer := func(name string, price float64, geocode string) *pb.Accommodation {
+		return &pb.Accommodation{
+			Name:     name,
+			HotelId:  name,
+			CheckIn:  timestamppb.New(checkIn),
+			CheckOut: timestamppb.New(checkIn.Add(12 * time.Hour)),
+			Cost:     &pb.Cost{Value: price, Currency: "USD"},
+			Location: &pb.Location{CityCode: "NYC", Geocode: geocode},
+		}
+	}
+	return &pb.Itinerary{Graph: &pb.Graph{
+		Nodes: []*pb.Node{
+			{Id: "london"},
+			{
+				Id:   "nyc",
+				Stay: &pb.Accommodation{Preferences: &pb.AccommodationPreferences{NearTransit: nearTransit}},
+				StayOptions: []*pb.Accommodation{
+					offer("Midtown", 250, "40.758000,-73.985500"),
+					offer("JFK Airport Inn", 300, "40.661000,-73.792000"),
+				},
+			},
+		},
+		Edges: []*pb.Edge{{
+			FromId: "london",
+			ToId:   "nyc",
+			Transport: &pb.Transport{
+				Type:                pb.TransportType_TRANSPORT_TYPE_FLIGHT,
+				DestinationLocation: &pb.Location{CityCode: "NYC", IataCodes: []string{"JFK"}},
+				Cost:                &pb.Cost{Value: 500, Currency: "USD"},
+			},
+		}},
+	}}
+}
+
+func TestTravelAgent_ScoreAndTag_NearTransit(t *testing.T) {
+	t.Run("Wanted", func(t *testing.T) {
+		it := transitItinerary(true)
+		NewTravelAgent(nil, nil).scoreAndTag([]*pb.Itinerary{it})
+
+		// Midtown is about 20 km from JFK, which outweighs the 50 it saves
+		node := it.Graph.Nodes[1]
+		assert.Equal(t, "JFK Airport Inn", node.Stay.Name)
+		assert.ElementsMatch(t, []string{TagNearAirport, "Best Value"}, node.Stay.Tags)
+		assert.Equal(t, []string{"Cheapest"}, node.StayOptions[1].Tags)
+		assert.Equal(t, 800.0, calculateItineraryScore(it), "totals are actual prices")
+	})
+
+	t.Run("NotWanted", func(t *testing.T) {
+		it := transitItinerary(false)
+		NewTravelAgent(nil, nil).scoreAndTag([]*pb.Itinerary{it})
+		node := it.Graph.Nodes[1]
+		assert.Equal(t, "Midtown", node.Stay.Name)
+		assert.NotContains(t, node.StayOptions[1].Tags, TagNearAirport)
+	})
+
+	t.Run("ArrivalByGeocode", func(t *testing.T) {
+		// Without a place index the airport is placed by its own coordinates
+		it := transitItinerary(true)
+		it.Graph.Edges[0].Transport.DestinationLocation.Geocode = "40.641300,-73.778100"
+		agent := NewTravelAgent(nil, nil)
+		agent.Places = nil
+		agent.scoreAndTag([]*pb.Itinerary{it})
+		assert.Equal(t, "JFK Airport Inn", it.Graph.Nodes[1].Stay.Name)
+	})
+
+	t.Run("NoArrival", func(t *testing.T) {
+		// Driving in, there is no airport or station to be near
+		it := transitItinerary(true)
+		it.Graph.Edges[0].Transport.Type = pb.TransportType_TRANSPORT_TYPE_CAR
+		NewTravelAgent(nil, nil).scoreAndTag([]*pb.Itinerary{it})
+		node := it.Graph.Nodes[1]
+		assert.Equal(t, "Midtown", node.Stay.Name)
+		assert.NotContains(t, node.StayOptions[1].Tags, TagNearAirport)
+	})
+}
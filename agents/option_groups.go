@@ -0,0 +1,178 @@
+package agents
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/va6996/travelingman/agents/options"
+	"github.com/va6996/travelingman/pb"
+)
+
+// Departure windows used to group similar transport options
+const (
+	WindowEarlyMorning = "early_morning"
+	WindowMorning      = "morning"
+	WindowAfternoon    = "afternoon"
+	WindowEvening      = "evening"
+	WindowNight        = "night"
+)
+
+// headlineTags are kept visible when an edge's options are grouped
+var headlineTags = map[string]bool{"Cheapest": true, "Fastest": true, "Best Value": true}
+
+// departureWindow buckets a departure time. Providers report local times, which are
+// stored as is, so the hour is the local hour at the origin.
+func departureWindow(dep time.Time) string {
+	switch h := dep.Hour(); {
+	case h < 5:
+		return WindowNight
+	case h < 8:
+		return WindowEarlyMorning
+	case h < 12:
+		return WindowMorning
+	case h < 17:
+		return WindowAfternoon
+	case h < 21:
+		return WindowEvening
+	default:
+		return WindowNight
+	}
+}
+
+// optionIDs names each of an edge's options, e.g. "UA455-202606010800" for a flight.
+// Options sharing a name, such as two fares on one flight, get a "~2", "~3" suffix.
+func optionIDs(e *pb.Edge) []string {
+	ids := make([]string, len(e.TransportOptions))
+	seen := map[string]int{}
+	for i, t := range e.TransportOptions {
+		id := options.Transport{Transport: t}.Fingerprint()
+		seen[id]++
+		if n := seen[id]; n > 1 {
+			id = fmt.Sprintf("%s~%d", id, n)
+		}
+		ids[i] = id
+	}
+	return ids
+}
+
+// ErrOptionNotFound is returned when a plan has no such edge or visible option
+var ErrOptionNotFound = errors.New("option not found")
+
+// optionByID finds one of an edge's options, collapsed or not, by its ID
+func optionByID(e *pb.Edge, id string) *pb.Transport {
+	for i, optID := range optionIDs(e) {
+		if optID == id {
+			return e.TransportOptions[i]
+		}
+	}
+	return nil
+}
+
+// MoreOptions returns the options collapsed behind a visible option, in their group's
+// order. The edge is keyed "from->to" as in edgeKey and the option by its ID in the
+// edge's option groups.
+func MoreOptions(it *pb.Itinerary, edge, optionID string) ([]*pb.Transport, error) {
+	e, ok := edgesByKey(it.GetGraph())[edge]
+	if !ok {
+		return nil, fmt.Errorf("%w: no edge %s", ErrOptionNotFound, edge)
+	}
+	for _, g := range e.OptionGroups {
+		if !slices.Contains(g.VisibleIds, optionID) {
+			continue
+		}
+		more := make([]*pb.Transport, 0, len(g.CollapsedIds))
+		for _, id := range g.CollapsedIds {
+			if t := optionByID(e, id); t != nil {
+				more = append(more, t)
+			}
+		}
+		return more, nil
+	}
+	return nil, fmt.Errorf("%w: edge %s shows no option %s", ErrOptionNotFound, edge, optionID)
+}
+
+// groupOptions groups an edge's scored options by departure window and carrier so that
+// near-identical options can be shown as one. Within a group, options carrying a
+// headline tag stay visible, or the best scored one if none does; the rest are
+// collapsed. The options and the selected transport are left as they are.
+func groupOptions(e *pb.Edge) {
+	e.OptionGroups = nil
+	if len(e.TransportOptions) < 2 {
+		return
+	}
+
+	ids := optionIDs(e)
+	groups := map[string]*pb.OptionGroup{}
+	members := map[*pb.OptionGroup][]int{}
+	for i, t := range e.TransportOptions {
+		dep := options.Transport{Transport: t}.Departure()
+		if dep == nil {
+			continue
+		}
+		window, carrier := departureWindow(dep.AsTime()), t.GetFlight().GetCarrierCode()
+		key := window + "/" + carrier
+		g, ok := groups[key]
+		if !ok {
+			g = &pb.OptionGroup{TimeWindow: window, Carrier: carrier}
+			groups[key] = g
+			// Options are sorted by score, so groups are too
+			e.OptionGroups = append(e.OptionGroups, g)
+		}
+		members[g] = append(members[g], i)
+	}
+
+	for _, g := range e.OptionGroups {
+		visible := map[int]bool{}
+		for _, i := range members[g] {
+			for _, tag := range e.TransportOptions[i].Tags {
+				if headlineTags[tag] {
+					visible[i] = true
+				}
+			}
+		}
+		if len(visible) == 0 {
+			visible[members[g][0]] = true
+		}
+		for _, i := range members[g] {
+			if visible[i] {
+				g.VisibleIds = append(g.VisibleIds, ids[i])
+			} else {
+				g.CollapsedIds = append(g.CollapsedIds, ids[i])
+			}
+		}
+	}
+}
+
+// formatSimilarOptions notes how many options are collapsed behind the selected one,
+// e.g. " 4 similar options."
+func formatSimilarOptions(e *pb.Edge) string {
+	n := 0
+	for i, t := range e.TransportOptions {
+		if t == e.Transport {
+			n = collapsedWith(e, optionIDs(e)[i])
+			break
+		}
+	}
+	switch n {
+	case 0:
+		return ""
+	case 1:
+		return " 1 similar option."
+	default:
+		return fmt.Sprintf(" %d similar options.", n)
+	}
+}
+
+// collapsedWith counts the options collapsed in the group showing the given option
+func collapsedWith(e *pb.Edge, id string) int {
+	for _, g := range e.OptionGroups {
+		for _, v := range g.VisibleIds {
+			if v == id {
+				return len(g.CollapsedIds)
+			}
+		}
+	}
+	return 0
+}
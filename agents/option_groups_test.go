@@ -0,0 +1,150 @@
+package agents
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// groupingItinerary has one edge with twelve flights across two carriers and the day
+func groupingItinerary() *pb.Itinerary {
+	day := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	flights := []struct {
+		carrier string
+		dep     time.Duration
+		hours   int
+		price   float64
+	}{
+		{"UA", 6*time.Hour + 10*time.Minute, 3, 300},
+		{"UA", 7*time.Hour + 30*time.Minute, 3, 305},
+		{"UA", 9 * time.Hour, 3, 250},
+		{"UA", 10*time.Hour + 15*time.Minute, 3, 255},
+		{"UA", 11*time.Hour + 40*time.Minute, 3, 260},
+		{"AA", 9*time.Hour + 30*time.Minute, 3, 270},
+		{"AA", 10*time.Hour + 45*time.Minute, 2, 400},
+		{"AA", 14 * time.Hour, 3, 240},
+		{"AA", 15*time.Hour + 30*time.Minute, 3, 245},
+		{"UA", 18 * time.Hour, 3, 280},
+		{"UA", 22*time.Hour + 30*time.Minute, 3, 290},
+		{"UA", 25 * time.Hour, 3, 295},
+	}
+
+	edge := &pb.Edge{FromId: "sfo", ToId: "ord"}
+	for i, f := range flights {
+		dep := day.Add(f.dep)
+		edge.TransportOptions = append(edge.TransportOptions, &pb.Transport{
+			Type: pb.TransportType_TRANSPORT_TYPE_FLIGHT,
+			Cost: &pb.Cost{Value: f.price, Currency: "USD"},
+			Details: &pb.Transport_Flight{Flight: &pb.Flight{
+				CarrierCode:   f.carrier,
+				FlightNumber:  fmt.Sprint(101 + i),
+				DepartureTime: timestamppb.New(dep),
+				ArrivalTime:   timestamppb.New(dep.Add(time.Duration(f.hours) * time.Hour)),
+			}},
+		})
+	}
+	return &pb.Itinerary{Graph: &pb.Graph{
+		Nodes: []*pb.Node{{Id: "sfo"}, {Id: "ord"}},
+		Edges: []*pb.Edge{edge},
+	}}
+}
+
+func TestGroupOptions(t *testing.T) {
+	it := groupingItinerary()
+	NewTravelAgent(nil, nil).scoreAndTag([]*pb.Itinerary{it})
+	edge := it.Graph.Edges[0]
+
+	// Groups follow the score order of their best option
+	type group struct {
+		window, carrier    string
+		visible, collapsed []string
+	}
+	var got []group
+	for _, g := range edge.OptionGroups {
+		got = append(got, group{g.TimeWindow, g.Carrier, g.VisibleIds, g.CollapsedIds})
+	}
+	assert.Equal(t, []group{
+		{WindowAfternoon, "AA", []string{"AA108-202606011400"}, []string{"AA109-202606011530"}},
+		{WindowMorning, "UA", []string{"UA103-202606010900"}, []string{"UA104-202606011015", "UA105-202606011140"}},
+		// The fastest flight is shown in place of the better scored one
+		{WindowMorning, "AA", []string{"AA107-202606011045"}, []string{"AA106-202606010930"}},
+		{WindowEvening, "UA", []string{"UA110-202606011800"}, nil},
+		{WindowNight, "UA", []string{"UA111-202606012230"}, []string{"UA112-202606020100"}},
+		{WindowEarlyMorning, "UA", []string{"UA101-202606010610"}, []string{"UA102-202606010730"}},
+	}, got)
+
+	// Tagged options stay visible
+	for _, g := range edge.OptionGroups {
+		for _, id := range g.CollapsedIds {
+			for _, tag := range optionByID(edge, id).Tags {
+				assert.False(t, headlineTags[tag], "%s is collapsed but tagged %s", id, tag)
+			}
+		}
+	}
+	assert.Contains(t, optionByID(edge, "AA107-202606011045").Tags, "Fastest")
+	assert.Contains(t, optionByID(edge, "AA108-202606011400").Tags, "Cheapest")
+
+	// Grouping leaves the options and the selection alone
+	assert.Len(t, edge.TransportOptions, 12)
+	assert.Equal(t, "108", edge.Transport.GetFlight().FlightNumber)
+	assert.Equal(t, " 1 similar option.", formatSimilarOptions(edge))
+
+	// Collapsed options can still be fetched through the option shown for them
+	more, err := MoreOptions(it, "sfo->ord", "UA103-202606010900")
+	assert.NoError(t, err)
+	if assert.Len(t, more, 2) {
+		assert.Equal(t, "104", more[0].GetFlight().FlightNumber)
+		assert.Equal(t, 260.0, more[1].Cost.Value)
+	}
+	more, err = MoreOptions(it, "sfo->ord", "UA110-202606011800")
+	assert.NoError(t, err)
+	assert.Empty(t, more)
+
+	_, err = MoreOptions(it, "sfo->ord", "UA104-202606011015")
+	assert.ErrorIs(t, err, ErrOptionNotFound, "a collapsed option has nothing behind it")
+	_, err = MoreOptions(it, "ord->sfo", "UA103-202606010900")
+	assert.ErrorIs(t, err, ErrOptionNotFound)
+}
+
+func TestGroupOptions_SingleOption(t *testing.T) {
+	it := groupingItinerary()
+	edge := it.Graph.Edges[0]
+	edge.TransportOptions = edge.TransportOptions[:1]
+	NewTravelAgent(nil, nil).scoreAndTag([]*pb.Itinerary{it})
+
+	assert.Empty(t, edge.OptionGroups)
+	assert.Empty(t, formatSimilarOptions(edge))
+}
+
+func TestOptionIDs(t *testing.T) {
+	edge := groupingItinerary().Graph.Edges[0]
+	edge.TransportOptions = append(edge.TransportOptions[:2], edge.TransportOptions[0], &pb.Transport{Type: pb.TransportType_TRANSPORT_TYPE_CAR})
+
+	// Two fares on one flight are told apart
+	assert.Equal(t, []string{"UA101-202606010610", "UA102-202606010730", "UA101-202606010610~2", "TRANSPORT_TYPE_CAR"}, optionIDs(edge))
+}
+
+func TestDepartureWindow(t *testing.T) {
+	tests := []struct {
+		hour, minute int
+		want         string
+	}{
+		{0, 30, WindowNight},
+		{4, 59, WindowNight},
+		{5, 0, WindowEarlyMorning},
+		{7, 59, WindowEarlyMorning},
+		{8, 0, WindowMorning},
+		{12, 0, WindowAfternoon},
+		{16, 59, WindowAfternoon},
+		{17, 0, WindowEvening},
+		{21, 0, WindowNight},
+	}
+	for _, tt := range tests {
+		dep := time.Date(2026, 6, 1, tt.hour, tt.minute, 0, 0, time.UTC)
+		assert.Equal(t, tt.want, departureWindow(dep), dep.Format("15:04"))
+	}
+}
@@ -0,0 +1,260 @@
+// Package options lets ranking and display logic treat an edge's transport options
+// and a node's stay options alike. The adapters wrap the protos in place, so setting
+// tags through an Option changes the underlying message.
+package options
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Kind tells transport and stay options apart
+type Kind int
+
+const (
+	KindTransport Kind = iota
+	KindStay
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindTransport:
+		return "transport"
+	case KindStay:
+		return "stay"
+	default:
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+}
+
+// Option is one bookable alternative for an edge or a node
+type Option interface {
+	Kind() Kind
+	// Cost is the price in the option's own currency, 0 if unknown
+	Cost() float64
+	// Duration is the travel time of a transport or the nights of a stay, 0 if unknown
+	Duration() time.Duration
+	// Fingerprint names the option by what is booked, e.g. "UA455-202606010800" for a
+	// flight. Different fares or rooms for the same thing may share it.
+	Fingerprint() string
+	Tags() []string
+	SetTags(tags []string)
+	// Provenance is the plugin that found the option, if known
+	Provenance() string
+}
+
+// Transport adapts a transport option
+type Transport struct {
+	Transport *pb.Transport
+}
+
+func (o Transport) Kind() Kind         { return KindTransport }
+func (o Transport) Cost() float64      { return o.Transport.GetCost().GetValue() }
+func (o Transport) Tags() []string     { return o.Transport.Tags }
+func (o Transport) SetTags(t []string) { o.Transport.Tags = t }
+func (o Transport) Provenance() string { return o.Transport.GetPlugin() }
+
+// Duration is the time from departure to arrival of a flight or train
+func (o Transport) Duration() time.Duration {
+	var dep, arr *timestamppb.Timestamp
+	if f := o.Transport.GetFlight(); f != nil {
+		dep, arr = f.DepartureTime, f.ArrivalTime
+	} else if tr := o.Transport.GetTrain(); tr != nil {
+		dep, arr = tr.DepartureTime, tr.ArrivalTime
+	}
+	if dep == nil || arr == nil {
+		return 0
+	}
+	return arr.AsTime().Sub(dep.AsTime())
+}
+
+// Departure is when a flight or train leaves, or nil if it has no time
+func (o Transport) Departure() *timestamppb.Timestamp {
+	if dep := o.Transport.GetFlight().GetDepartureTime(); dep != nil {
+		return dep
+	}
+	return o.Transport.GetTrain().GetDepartureTime()
+}
+
+// Fingerprint is the flight or train number and the departure time, or the transport
+// type for options without either
+func (o Transport) Fingerprint() string {
+	t := o.Transport
+	id := t.Type.String()
+	if f := t.GetFlight(); f != nil {
+		id = f.CarrierCode + f.FlightNumber
+	} else if tr := t.GetTrain(); tr != nil && tr.TrainNumber != "" {
+		id = tr.TrainNumber
+	}
+	if dep := o.Departure(); dep != nil {
+		id += "-" + dep.AsTime().Format("200601021504")
+	}
+	return id
+}
+
+// Stay adapts a stay option
+type Stay struct {
+	Stay *pb.Accommodation
+}
+
+func (o Stay) Kind() Kind         { return KindStay }
+func (o Stay) Cost() float64      { return o.Stay.GetCost().GetValue() }
+func (o Stay) Tags() []string     { return o.Stay.Tags }
+func (o Stay) SetTags(t []string) { o.Stay.Tags = t }
+func (o Stay) Provenance() string { return "" } // Stays do not record their plugin
+
+// Duration is the nights of the stay
+func (o Stay) Duration() time.Duration {
+	s := o.Stay
+	if s.CheckIn == nil || s.CheckOut == nil {
+		return 0
+	}
+	return time.Duration(Nights(s.CheckIn.AsTime(), s.CheckOut.AsTime())) * 24 * time.Hour
+}
+
+// Fingerprint is the hotel and the dates, e.g. "H1-20260601-20260603"
+func (o Stay) Fingerprint() string {
+	id := o.Hotel()
+	if s := o.Stay; s.CheckIn != nil && s.CheckOut != nil {
+		id += "-" + s.CheckIn.AsTime().Format("20060102") + "-" + s.CheckOut.AsTime().Format("20060102")
+	}
+	return id
+}
+
+// Hotel identifies the property: the provider's hotel ID, else the name
+func (o Stay) Hotel() string {
+	if o.Stay.HotelId != "" {
+		return o.Stay.HotelId
+	}
+	return o.Stay.Name
+}
+
+// Nights counts the nights between a check-in and a check-out, at least one for any
+// stay that ends after it starts
+func Nights(checkIn, checkOut time.Time) int {
+	if !checkOut.After(checkIn) {
+		return 0
+	}
+	date := func(t time.Time) time.Time { return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC) }
+	nights := int(math.Round(date(checkOut).Sub(date(checkIn)).Hours() / 24))
+	if nights < 1 {
+		return 1
+	}
+	return nights
+}
+
+// Transports wraps transport options
+func Transports(ts []*pb.Transport) []Option {
+	opts := make([]Option, len(ts))
+	for i, t := range ts {
+		opts[i] = Transport{Transport: t}
+	}
+	return opts
+}
+
+// Stays wraps stay options
+func Stays(ss []*pb.Accommodation) []Option {
+	opts := make([]Option, len(ss))
+	for i, s := range ss {
+		opts[i] = Stay{Stay: s}
+	}
+	return opts
+}
+
+// ToTransports unwraps transport options; it panics on any other kind
+func ToTransports(opts []Option) []*pb.Transport {
+	ts := make([]*pb.Transport, len(opts))
+	for i, o := range opts {
+		ts[i] = o.(Transport).Transport
+	}
+	return ts
+}
+
+// ToStays unwraps stay options; it panics on any other kind
+func ToStays(opts []Option) []*pb.Accommodation {
+	ss := make([]*pb.Accommodation, len(opts))
+	for i, o := range opts {
+		ss[i] = o.(Stay).Stay
+	}
+	return ss
+}
+
+// MinCost is the lowest cost among the options, or 0 if there are none
+func MinCost(opts []Option) float64 {
+	if len(opts) == 0 {
+		return 0
+	}
+	lowest := math.MaxFloat64
+	for _, o := range opts {
+		lowest = min(lowest, o.Cost())
+	}
+	return lowest
+}
+
+// MinDuration is the shortest known duration among the options, or 0 if none is known
+func MinDuration(opts []Option) time.Duration {
+	var shortest time.Duration
+	for _, o := range opts {
+		if d := o.Duration(); d > 0 && (shortest == 0 || d < shortest) {
+			shortest = d
+		}
+	}
+	return shortest
+}
+
+// SortByScore returns the options ordered by score, lowest first. Equal scores are
+// ordered by fingerprint and then provenance, so the order does not depend on the order
+// the providers answered in; options equal in all three keep their order.
+func SortByScore(opts []Option, scores []float64) []Option {
+	idx := make([]int, len(opts))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool {
+		i, j := idx[a], idx[b]
+		if scores[i] != scores[j] {
+			return scores[i] < scores[j]
+		}
+		if fi, fj := opts[i].Fingerprint(), opts[j].Fingerprint(); fi != fj {
+			return fi < fj
+		}
+		return opts[i].Provenance() < opts[j].Provenance()
+	})
+	sorted := make([]Option, len(opts))
+	for i, j := range idx {
+		sorted[i] = opts[j]
+	}
+	return sorted
+}
+
+// SpreadBy moves the first option of each key, e.g. each hotel, ahead of the others,
+// so one key with many options cannot crowd out the rest. Order is kept otherwise.
+func SpreadBy(opts []Option, key func(Option) string) []Option {
+	var first, rest []Option
+	seen := map[string]bool{}
+	for _, o := range opts {
+		k := key(o)
+		if seen[k] {
+			rest = append(rest, o)
+			continue
+		}
+		seen[k] = true
+		first = append(first, o)
+	}
+	return append(first, rest...)
+}
+
+// HasTag reports whether an option carries a tag
+func HasTag(o Option, tag string) bool {
+	for _, t := range o.Tags() {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
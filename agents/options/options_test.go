@@ -0,0 +1,150 @@
+package options
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var day = time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func flight(number string, price float64, depHour, hours int) *pb.Transport {
+	dep := day.Add(time.Duration(depHour) * time.Hour)
+	return &pb.Transport{
+		Type:   pb.TransportType_TRANSPORT_TYPE_FLIGHT,
+		Plugin: "amadeus",
+		Cost:   &pb.Cost{Value: price, Currency: "USD"},
+		Details: &pb.Transport_Flight{Flight: &pb.Flight{
+			CarrierCode:   "UA",
+			FlightNumber:  number,
+			DepartureTime: timestamppb.New(dep),
+			ArrivalTime:   timestamppb.New(dep.Add(time.Duration(hours) * time.Hour)),
+		}},
+	}
+}
+
+func stay(hotel string, price float64, nights int) *pb.Accommodation {
+	return &pb.Accommodation{
+		HotelId:  hotel,
+		Name:     "Hotel " + hotel,
+		Cost:     &pb.Cost{Value: price, Currency: "USD"},
+		CheckIn:  timestamppb.New(day.Add(15 * time.Hour)),
+		CheckOut: timestamppb.New(day.AddDate(0, 0, nights).Add(11 * time.Hour)),
+	}
+}
+
+func TestAdapters(t *testing.T) {
+	tests := []struct {
+		name        string
+		opt         Option
+		kind        Kind
+		cost        float64
+		duration    time.Duration
+		fingerprint string
+		provenance  string
+	}{
+		{"Flight", Transport{Transport: flight("455", 320, 8, 5)}, KindTransport, 320, 5 * time.Hour, "UA455-202606010800", "amadeus"},
+		{"Train", Transport{Transport: &pb.Transport{
+			Type: pb.TransportType_TRANSPORT_TYPE_TRAIN,
+			Details: &pb.Transport_Train{Train: &pb.Train{
+				TrainNumber:   "ICE 71",
+				DepartureTime: timestamppb.New(day.Add(9 * time.Hour)),
+				ArrivalTime:   timestamppb.New(day.Add(13*time.Hour + 30*time.Minute)),
+			}},
+		}}, KindTransport, 0, 4*time.Hour + 30*time.Minute, "ICE 71-202606010900", ""},
+		{"Car", Transport{Transport: &pb.Transport{Type: pb.TransportType_TRANSPORT_TYPE_CAR}}, KindTransport, 0, 0, "TRANSPORT_TYPE_CAR", ""},
+		{"Stay", Stay{Stay: stay("H1", 450, 3)}, KindStay, 450, 72 * time.Hour, "H1-20260601-20260604", ""},
+		{"StayByName", Stay{Stay: &pb.Accommodation{Name: "Pension Rosa"}}, KindStay, 0, 0, "Pension Rosa", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.kind, tt.opt.Kind())
+			assert.Equal(t, tt.cost, tt.opt.Cost())
+			assert.Equal(t, tt.duration, tt.opt.Duration())
+			assert.Equal(t, tt.fingerprint, tt.opt.Fingerprint())
+			assert.Equal(t, tt.provenance, tt.opt.Provenance())
+
+			// Tags are written through to the proto
+			tt.opt.SetTags([]string{"Cheapest"})
+			assert.Equal(t, []string{"Cheapest"}, tt.opt.Tags())
+			assert.True(t, HasTag(tt.opt, "Cheapest"))
+			assert.False(t, HasTag(tt.opt, "Fastest"))
+		})
+	}
+
+	f := flight("455", 320, 8, 5)
+	Transport{Transport: f}.SetTags([]string{"Best Value"})
+	assert.Equal(t, []string{"Best Value"}, f.Tags)
+	s := stay("H1", 450, 3)
+	Stay{Stay: s}.SetTags([]string{"Best Value"})
+	assert.Equal(t, []string{"Best Value"}, s.Tags)
+}
+
+func TestSharedHelpers(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		scores   []float64
+		minCost  float64
+		minDur   time.Duration
+		byScore  []string
+		spreadBy []string
+	}{
+		{
+			name:     "Transports",
+			opts:     Transports([]*pb.Transport{flight("1", 300, 8, 6), flight("2", 250, 9, 7), flight("1", 280, 8, 6), flight("3", 250, 14, 5)}),
+			scores:   []float64{350, 390, 360, 400},
+			minCost:  250,
+			minDur:   5 * time.Hour,
+			byScore:  []string{"UA1-202606010800", "UA1-202606010800", "UA2-202606010900", "UA3-202606011400"},
+			spreadBy: []string{"UA1-202606010800", "UA2-202606010900", "UA3-202606011400", "UA1-202606010800"},
+		},
+		{
+			name:     "Stays",
+			opts:     Stays([]*pb.Accommodation{stay("A", 400, 2), stay("B", 150, 2), stay("A", 130, 2), stay("C", 130, 1)}),
+			scores:   []float64{400, 150, 130, 130},
+			minCost:  130,
+			minDur:   24 * time.Hour,
+			byScore:  []string{"A-20260601-20260603", "C-20260601-20260602", "B-20260601-20260603", "A-20260601-20260603"},
+			spreadBy: []string{"A-20260601-20260603", "C-20260601-20260602", "B-20260601-20260603", "A-20260601-20260603"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.minCost, MinCost(tt.opts))
+			assert.Equal(t, tt.minDur, MinDuration(tt.opts))
+
+			// Ties go by fingerprint: A before C for the stays
+			sorted := SortByScore(tt.opts, tt.scores)
+			assert.Equal(t, tt.byScore, fingerprints(sorted))
+			assert.Equal(t, tt.spreadBy, fingerprints(SpreadBy(sorted, Option.Fingerprint)))
+		})
+	}
+
+	assert.Equal(t, 0.0, MinCost(nil))
+	assert.Equal(t, time.Duration(0), MinDuration(Stays([]*pb.Accommodation{{Name: "No dates"}})))
+}
+
+func TestSpreadBy(t *testing.T) {
+	// Each hotel's first offer comes before any hotel's second
+	opts := Stays([]*pb.Accommodation{stay("A", 110, 2), stay("A", 130, 2), stay("B", 150, 2), stay("A", 400, 2)})
+	spread := SpreadBy(opts, func(o Option) string { return o.(Stay).Hotel() })
+
+	var order []float64
+	for _, o := range spread {
+		order = append(order, o.Cost())
+	}
+	assert.Equal(t, []float64{110, 150, 130, 400}, order)
+	assert.Equal(t, []*pb.Accommodation{opts[0].(Stay).Stay, opts[2].(Stay).Stay, opts[1].(Stay).Stay, opts[3].(Stay).Stay}, ToStays(spread))
+}
+
+func fingerprints(opts []Option) []string {
+	var out []string
+	for _, o := range opts {
+		out = append(out, o.Fingerprint())
+	}
+	return out
+}
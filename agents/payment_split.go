@@ -0,0 +1,242 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/va6996/travelingman/log"
+	"github.com/va6996/travelingman/orm"
+	"github.com/va6996/travelingman/pb"
+	"golang.org/x/text/currency"
+)
+
+// ErrInvalidSplit is returned for a payment split that cannot be applied to a booking
+var ErrInvalidSplit = errors.New("invalid payment split")
+
+// SplitPayment divides total among users as split describes and returns one share per
+// user. Amounts are worked out in the currency's minor units, so shares always add up
+// to exactly total:
+//   - EQUAL divides total among split.UserIds; the minor units left over go one each
+//     to the first users listed, e.g. 100.01 over three is 33.34, 33.34, 33.33.
+//   - EXPLICIT takes the amounts in split.Shares.
+//   - BY_TRAVELER takes each traveler's fare from fares, adding up the fares of a
+//     user who pays for several travelers.
+//
+// Explicit and per-traveler amounts may be off the total by up to one minor unit per
+// share to allow for rounding; the difference goes to the first share. Larger
+// differences, amounts in another currency and missing users are rejected.
+func SplitPayment(split *pb.PaymentSplit, total, currencyCode string, fares []*pb.Payment) ([]*pb.Payment, error) {
+	scale, err := currencyScale(currencyCode)
+	if err != nil {
+		return nil, err
+	}
+	totalUnits, err := parseMinorUnits(total, scale)
+	if err != nil {
+		return nil, fmt.Errorf("%w: total: %v", ErrInvalidSplit, err)
+	}
+
+	var users []int64
+	var units []int64
+	switch split.GetMode() {
+	case pb.SplitMode_SPLIT_MODE_EQUAL:
+		users = split.GetUserIds()
+		if err := checkUsers(users); err != nil {
+			return nil, err
+		}
+		n := int64(len(users))
+		for i := range users {
+			share := totalUnits / n
+			if int64(i) < totalUnits%n {
+				share++
+			}
+			units = append(units, share)
+		}
+	case pb.SplitMode_SPLIT_MODE_EXPLICIT:
+		if users, units, err = shareUnits(split.GetShares(), currencyCode, scale); err != nil {
+			return nil, err
+		}
+		if err := checkUsers(users); err != nil {
+			return nil, err
+		}
+		if err := settleRemainder(units, totalUnits, scale); err != nil {
+			return nil, err
+		}
+	case pb.SplitMode_SPLIT_MODE_BY_TRAVELER:
+		fareUsers, fareUnits, err := shareUnits(fares, currencyCode, scale)
+		if err != nil {
+			return nil, err
+		}
+		// A user paying for several travelers owes the sum of their fares
+		index := map[int64]int{}
+		for i, user := range fareUsers {
+			if j, ok := index[user]; ok {
+				units[j] += fareUnits[i]
+				continue
+			}
+			index[user] = len(users)
+			users = append(users, user)
+			units = append(units, fareUnits[i])
+		}
+		if err := checkUsers(users); err != nil {
+			return nil, err
+		}
+		if err := settleRemainder(units, totalUnits, scale); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown mode %s", ErrInvalidSplit, split.GetMode())
+	}
+
+	shares := make([]*pb.Payment, len(users))
+	for i, user := range users {
+		shares[i] = &pb.Payment{
+			UserId:   user,
+			Amount:   formatMinorUnits(units[i], scale),
+			Currency: strings.ToUpper(currencyCode),
+			Status:   orm.PaymentStatusPending,
+		}
+	}
+	return shares, nil
+}
+
+// SumPayments adds up shares, which must all be in the same currency. It returns the
+// total and its currency, or "" for both if there are no shares.
+func SumPayments(shares []*pb.Payment) (string, string, error) {
+	if len(shares) == 0 {
+		return "", "", nil
+	}
+	code := shares[0].Currency
+	scale, err := currencyScale(code)
+	if err != nil {
+		return "", "", err
+	}
+	_, units, err := shareUnits(shares, code, scale)
+	if err != nil {
+		return "", "", err
+	}
+	var total int64
+	for _, u := range units {
+		total += u
+	}
+	return formatMinorUnits(total, scale), strings.ToUpper(code), nil
+}
+
+// RecordSplit splits a booking's total as SplitPayment does and replaces the booking's
+// shares with the result, which it returns as stored
+func (bt *BookingTracker) RecordSplit(ctx context.Context, bookingID uint, split *pb.PaymentSplit, total, currencyCode string, fares []*pb.Payment) ([]*pb.Payment, error) {
+	if _, err := orm.GetBooking(bt.DB, bookingID); err != nil {
+		return nil, err
+	}
+	shares, err := SplitPayment(split, total, currencyCode, fares)
+	if err != nil {
+		return nil, err
+	}
+	if err := orm.RecordPaymentSplit(bt.DB, bookingID, shares); err != nil {
+		return nil, err
+	}
+	log.Infof(ctx, "BookingTracker: booking %d split %s %s %d ways (%s)", bookingID, total, currencyCode, len(shares), split.GetMode())
+	return orm.BookingPayments(bt.DB, bookingID)
+}
+
+// currencyScale returns the number of decimals amounts in the currency are kept to
+func currencyScale(code string) (int, error) {
+	unit, err := currency.ParseISO(code)
+	if err != nil {
+		return 0, fmt.Errorf("%w: currency %q: %v", ErrInvalidSplit, code, err)
+	}
+	scale, _ := currency.Standard.Rounding(unit)
+	return scale, nil
+}
+
+// shareUnits returns the users and amounts, in minor units, of shares. Shares without a
+// currency are taken to be in code.
+func shareUnits(shares []*pb.Payment, code string, scale int) ([]int64, []int64, error) {
+	users := make([]int64, len(shares))
+	units := make([]int64, len(shares))
+	for i, share := range shares {
+		if share.GetCurrency() != "" && !strings.EqualFold(share.GetCurrency(), code) {
+			return nil, nil, fmt.Errorf("%w: share %d is in %s, not %s", ErrInvalidSplit, i, share.GetCurrency(), code)
+		}
+		u, err := parseMinorUnits(share.GetAmount(), scale)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: share %d: %v", ErrInvalidSplit, i, err)
+		}
+		users[i], units[i] = share.GetUserId(), u
+	}
+	return users, units, nil
+}
+
+// checkUsers rejects an empty list of users, unknown (zero) users and duplicates
+func checkUsers(users []int64) error {
+	if len(users) == 0 {
+		return fmt.Errorf("%w: no users to split between", ErrInvalidSplit)
+	}
+	seen := make(map[int64]bool, len(users))
+	for _, user := range users {
+		if user == 0 {
+			return fmt.Errorf("%w: share without a user", ErrInvalidSplit)
+		}
+		if seen[user] {
+			return fmt.Errorf("%w: user %d appears more than once", ErrInvalidSplit, user)
+		}
+		seen[user] = true
+	}
+	return nil
+}
+
+// settleRemainder makes units add up to total by adjusting the first share, if they
+// are off by no more than one minor unit per share
+func settleRemainder(units []int64, total int64, scale int) error {
+	var sum int64
+	for _, u := range units {
+		sum += u
+	}
+	diff := total - sum
+	if diff < -int64(len(units)) || diff > int64(len(units)) {
+		return fmt.Errorf("%w: shares add up to %s, not %s", ErrInvalidSplit, formatMinorUnits(sum, scale), formatMinorUnits(total, scale))
+	}
+	if units[0]+diff < 0 {
+		return fmt.Errorf("%w: shares add up to more than %s", ErrInvalidSplit, formatMinorUnits(total, scale))
+	}
+	units[0] += diff
+	return nil
+}
+
+// parseMinorUnits parses a non-negative decimal amount such as "100.01" into minor
+// units, e.g. 10001 cents. Digits beyond the currency's scale must be zero.
+func parseMinorUnits(amount string, scale int) (int64, error) {
+	whole, frac, _ := strings.Cut(strings.TrimSpace(amount), ".")
+	if whole == "" && frac == "" {
+		return 0, fmt.Errorf("missing amount")
+	}
+	if trimmed := strings.TrimRight(frac, "0"); len(trimmed) > scale {
+		return 0, fmt.Errorf("amount %q has more than %d decimals", amount, scale)
+	} else if len(frac) > scale {
+		frac = frac[:scale]
+	}
+	frac += strings.Repeat("0", scale-len(frac))
+	if whole == "" {
+		whole = "0"
+	}
+	units, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil || strings.ContainsAny(whole+frac, "+-") {
+		return 0, fmt.Errorf("invalid amount %q", amount)
+	}
+	return units, nil
+}
+
+// formatMinorUnits formats minor units as a decimal amount with the currency's scale
+func formatMinorUnits(units int64, scale int) string {
+	if scale == 0 {
+		return strconv.FormatInt(units, 10)
+	}
+	sign := ""
+	if units < 0 {
+		sign, units = "-", -units
+	}
+	s := fmt.Sprintf("%0*d", scale+1, units)
+	return sign + s[:len(s)-scale] + "." + s[len(s)-scale:]
+}
@@ -0,0 +1,134 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/orm"
+	"github.com/va6996/travelingman/pb"
+	"gorm.io/gorm"
+)
+
+// shareAmounts formats shares as "user:amount"
+func shareAmounts(shares []*pb.Payment) []string {
+	var out []string
+	for _, s := range shares {
+		out = append(out, fmt.Sprintf("%d:%s", s.UserId, s.Amount))
+	}
+	return out
+}
+
+func TestSplitPayment_Equal(t *testing.T) {
+	split := &pb.PaymentSplit{Mode: pb.SplitMode_SPLIT_MODE_EQUAL, UserIds: []int64{7, 3, 5}}
+
+	// The leftover cents go one each to the first users listed
+	shares, err := SplitPayment(split, "100.01", "usd", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"7:33.34", "3:33.34", "5:33.33"}, shareAmounts(shares))
+	for _, s := range shares {
+		assert.Equal(t, "USD", s.Currency)
+		assert.Equal(t, orm.PaymentStatusPending, s.Status)
+	}
+
+	shares, err = SplitPayment(split, "100.02", "USD", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"7:33.34", "3:33.34", "5:33.34"}, shareAmounts(shares))
+
+	// Currencies without minor units split in whole units
+	shares, err = SplitPayment(split, "10000", "JPY", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"7:3334", "3:3333", "5:3333"}, shareAmounts(shares))
+
+	for name, tc := range map[string]struct {
+		split *pb.PaymentSplit
+		total string
+	}{
+		"duplicate user": {&pb.PaymentSplit{Mode: pb.SplitMode_SPLIT_MODE_EQUAL, UserIds: []int64{1, 1}}, "10"},
+		"no users":       {&pb.PaymentSplit{Mode: pb.SplitMode_SPLIT_MODE_EQUAL}, "10"},
+		"sub-cent total": {split, "100.001"},
+		"negative total": {split, "-10"},
+		"no mode":        {&pb.PaymentSplit{UserIds: []int64{1}}, "10"},
+	} {
+		_, err := SplitPayment(tc.split, tc.total, "USD", nil)
+		assert.ErrorIs(t, err, ErrInvalidSplit, name)
+	}
+}
+
+func TestSplitPayment_Explicit(t *testing.T) {
+	explicit := func(amounts ...string) *pb.PaymentSplit {
+		split := &pb.PaymentSplit{Mode: pb.SplitMode_SPLIT_MODE_EXPLICIT}
+		for i, a := range amounts {
+			split.Shares = append(split.Shares, &pb.Payment{UserId: int64(i + 1), Amount: a})
+		}
+		return split
+	}
+
+	shares, err := SplitPayment(explicit("60", "40.01"), "100.01", "USD", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1:60.00", "2:40.01"}, shareAmounts(shares))
+
+	// Rounding each share to the cent may leave up to a cent per share, which goes to the first
+	shares, err = SplitPayment(explicit("33.33", "33.33", "33.33"), "100.01", "USD", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1:33.35", "2:33.33", "3:33.33"}, shareAmounts(shares))
+
+	// Shares that do not add up to the total are rejected
+	_, err = SplitPayment(explicit("60", "30"), "100.01", "USD", nil)
+	assert.ErrorIs(t, err, ErrInvalidSplit)
+	assert.ErrorContains(t, err, "add up to 90.00, not 100.01")
+	_, err = SplitPayment(explicit("60", "50"), "100", "USD", nil)
+	assert.ErrorIs(t, err, ErrInvalidSplit)
+
+	// So are shares in another currency
+	split := explicit("60", "40")
+	split.Shares[1].Currency = "EUR"
+	_, err = SplitPayment(split, "100", "USD", nil)
+	assert.ErrorIs(t, err, ErrInvalidSplit)
+	assert.ErrorContains(t, err, "EUR")
+}
+
+func TestSplitPayment_ByTraveler(t *testing.T) {
+	// User 1 pays for themselves and a child
+	fares := []*pb.Payment{
+		{UserId: 1, Amount: "420.50", Currency: "EUR"},
+		{UserId: 2, Amount: "420.50", Currency: "EUR"},
+		{UserId: 1, Amount: "315.38", Currency: "EUR"},
+	}
+	split := &pb.PaymentSplit{Mode: pb.SplitMode_SPLIT_MODE_BY_TRAVELER}
+	shares, err := SplitPayment(split, "1156.38", "EUR", fares)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1:735.88", "2:420.50"}, shareAmounts(shares))
+
+	// A traveler without a paying user cannot be charged
+	_, err = SplitPayment(split, "420.50", "EUR", []*pb.Payment{{Amount: "420.50"}})
+	assert.ErrorIs(t, err, ErrInvalidSplit)
+}
+
+func TestBookingTracker_RecordSplit(t *testing.T) {
+	bt, _ := setupBookingTracker(t, nil)
+	ctx := context.Background()
+	booking, err := orm.CreateBooking(bt.DB, "ORDER1", &pb.Flight{CarrierCode: "BA", FlightNumber: "178"}, nil)
+	assert.NoError(t, err)
+
+	split := &pb.PaymentSplit{Mode: pb.SplitMode_SPLIT_MODE_EQUAL, UserIds: []int64{1, 2, 3}}
+	shares, err := bt.RecordSplit(ctx, booking.ID, split, "100.01", "USD", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1:33.34", "2:33.34", "3:33.33"}, shareAmounts(shares))
+
+	total, currency, err := SumPayments(shares)
+	assert.NoError(t, err)
+	assert.Equal(t, "100.01", total)
+	assert.Equal(t, "USD", currency)
+
+	// An invalid split keeps the recorded one
+	_, err = bt.RecordSplit(ctx, booking.ID, &pb.PaymentSplit{Mode: pb.SplitMode_SPLIT_MODE_EQUAL}, "100.01", "USD", nil)
+	assert.ErrorIs(t, err, ErrInvalidSplit)
+	stored, err := orm.BookingPayments(bt.DB, booking.ID)
+	assert.NoError(t, err)
+	assert.Len(t, stored, 3)
+
+	_, err = bt.RecordSplit(ctx, 999, split, "100.01", "USD", nil)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
@@ -0,0 +1,24 @@
+package agents
+
+import "time"
+
+// Phases of OrchestrateRequest, as reported to a PhaseObserver
+const (
+	PhasePlanning     = "planning"     // The planner proposing itineraries, LLM and tool calls included
+	PhaseVerification = "verification" // TravelDesk checking the proposals with the providers
+	PhaseScoring      = "scoring"      // Tagging and ordering the valid itineraries
+)
+
+// PhaseObserver records how long each phase of planning a request took, e.g. as a
+// histogram per phase. A request that re-plans reports each phase once per round.
+type PhaseObserver interface {
+	ObserveDuration(phase string, d time.Duration)
+}
+
+// observePhase reports the time since start for a phase, if the agent has an observer.
+// Latency is wall time, whatever the agent's Clock says.
+func (ta *TravelAgent) observePhase(phase string, start time.Time) {
+	if ta.Phases != nil {
+		ta.Phases.ObserveDuration(phase, time.Since(start))
+	}
+}
@@ -0,0 +1,38 @@
+package agents
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/va6996/travelingman/pb"
+)
+
+// fakePhases records the phases it is told about, in order
+type fakePhases struct {
+	mu     sync.Mutex
+	phases []string
+}
+
+func (f *fakePhases) ObserveDuration(phase string, d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.phases = append(f.phases, phase)
+}
+
+func TestTravelAgent_OrchestrateRequest_ObservesPhases(t *testing.T) {
+	planner := new(MockPlanner)
+	planner.On("Plan", mock.Anything, mock.Anything).Return(&PlanResult{PossibleItineraries: []*pb.Itinerary{stayPlan("clean", nil)}}, nil)
+
+	phases := &fakePhases{}
+	agent := NewTravelAgent(planner, passDesk{})
+	agent.Phases = phases
+
+	_, itineraries, err := agent.OrchestrateRequest(context.Background(), "Paris", "")
+	assert.NoError(t, err)
+	assert.Len(t, itineraries, 1)
+	assert.Equal(t, []string{PhasePlanning, PhaseVerification, PhaseScoring}, phases.phases)
+}
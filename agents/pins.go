@@ -0,0 +1,103 @@
+package agents
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// TripPins are the parts of a trip the user fixed. The planner is told to keep them,
+// and plans that change them are sent back before they are searched. Zero fields pin
+// nothing.
+type TripPins struct {
+	StartDate   time.Time // Day the trip starts; only the date counts
+	EndDate     time.Time // Day the trip ends; only the date counts
+	Destination string    // City, or its IATA code
+	Travelers   int32
+}
+
+// IsZero reports whether nothing is pinned
+func (p TripPins) IsZero() bool {
+	return p == TripPins{}
+}
+
+// Lines describes the pins for the planner, one per line
+func (p TripPins) Lines() []string {
+	var lines []string
+	if !p.StartDate.IsZero() {
+		lines = append(lines, "The trip starts on "+p.StartDate.Format(time.DateOnly))
+	}
+	if !p.EndDate.IsZero() {
+		lines = append(lines, "The trip ends on "+p.EndDate.Format(time.DateOnly))
+	}
+	if p.Destination != "" {
+		lines = append(lines, "The destination is "+p.Destination)
+	}
+	if p.Travelers > 0 {
+		lines = append(lines, fmt.Sprintf("There are %d travelers", p.Travelers))
+	}
+	return lines
+}
+
+// Violations lists how a planned itinerary departs from the pins
+func (p TripPins) Violations(it *pb.Itinerary) []string {
+	var violations []string
+	if !p.StartDate.IsZero() {
+		if got := planDate(it.GetStartTime()); got != p.StartDate.Format(time.DateOnly) {
+			violations = append(violations, fmt.Sprintf("starts on %s, but the user fixed %s", got, p.StartDate.Format(time.DateOnly)))
+		}
+	}
+	if !p.EndDate.IsZero() {
+		if got := planDate(it.GetEndTime()); got != p.EndDate.Format(time.DateOnly) {
+			violations = append(violations, fmt.Sprintf("ends on %s, but the user fixed %s", got, p.EndDate.Format(time.DateOnly)))
+		}
+	}
+	if p.Destination != "" && !visits(it.GetGraph(), p.Destination) {
+		violations = append(violations, fmt.Sprintf("does not go to %s, which the user fixed", p.Destination))
+	}
+	if p.Travelers > 0 && it.GetTravelers() != p.Travelers {
+		violations = append(violations, fmt.Sprintf("is for %d travelers, but the user fixed %d", it.GetTravelers(), p.Travelers))
+	}
+	return violations
+}
+
+// planDate is the date of a planned time, or "no date" if it is unset
+func planDate(ts *timestamppb.Timestamp) string {
+	if ts == nil {
+		return "no date"
+	}
+	return ts.AsTime().Format(time.DateOnly)
+}
+
+// visits reports whether any node of g, sub-graphs included, is at place: a city, part
+// of one's name, or one of its codes. A graph whose nodes only have codes the place
+// does not match counts as a visit, since its cities are unknown until it is enriched.
+func visits(g *pb.Graph, place string) bool {
+	named := false
+	var walk func(g *pb.Graph) bool
+	walk = func(g *pb.Graph) bool {
+		for _, n := range g.GetNodes() {
+			loc := n.GetLocation()
+			if city := strings.ToLower(loc.GetCity()); city != "" {
+				named = true
+				if strings.Contains(city, strings.ToLower(place)) || strings.Contains(strings.ToLower(place), city) {
+					return true
+				}
+			}
+			if strings.EqualFold(loc.GetCityCode(), place) || slices.ContainsFunc(loc.GetIataCodes(), func(code string) bool {
+				return strings.EqualFold(code, place)
+			}) {
+				return true
+			}
+			if walk(n.GetSubGraph()) {
+				return true
+			}
+		}
+		return false
+	}
+	return walk(g) || !named
+}
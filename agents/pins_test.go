@@ -0,0 +1,93 @@
+package agents
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// checkedDesk returns every itinerary as planned and records which it was given
+type checkedDesk struct {
+	checked []string
+}
+
+func (d *checkedDesk) CheckAvailability(ctx context.Context, it *pb.Itinerary) (*pb.Itinerary, error) {
+	d.checked = append(d.checked, it.Title)
+	return it, nil
+}
+
+// datedPlan is a Paris stay for two starting on day, with problem on the stay, if any
+func datedPlan(title string, day time.Time, problem *pb.Error) *pb.Itinerary {
+	it := stayPlan(title, problem)
+	it.StartTime = timestamppb.New(day)
+	it.Travelers = 2
+	return it
+}
+
+func TestTripPins_Violations(t *testing.T) {
+	pins := TripPins{
+		StartDate:   time.Date(2026, 6, 5, 0, 0, 0, 0, time.UTC),
+		Destination: "Paris",
+		Travelers:   2,
+	}
+	kept := datedPlan("kept", time.Date(2026, 6, 5, 18, 30, 0, 0, time.UTC), nil)
+	assert.Empty(t, pins.Violations(kept))
+	assert.Empty(t, TripPins{}.Violations(&pb.Itinerary{}), "nothing pinned")
+
+	moved := datedPlan("moved", time.Date(2026, 6, 6, 9, 0, 0, 0, time.UTC), nil)
+	moved.Travelers = 3
+	moved.Graph.Nodes[0].Location = &pb.Location{City: "Lyon", IataCodes: []string{"LYS"}}
+	assert.Equal(t, []string{
+		"starts on 2026-06-06, but the user fixed 2026-06-05",
+		"does not go to Paris, which the user fixed",
+		"is for 3 travelers, but the user fixed 2",
+	}, pins.Violations(moved))
+
+	// Until a plan is enriched its cities may only be known by their codes
+	coded := datedPlan("coded", pins.StartDate, nil)
+	coded.Graph.Nodes[0].Location = &pb.Location{IataCodes: []string{"CDG"}}
+	assert.Empty(t, pins.Violations(coded))
+	assert.Empty(t, TripPins{Destination: "New York"}.Violations(&pb.Itinerary{Graph: &pb.Graph{Nodes: []*pb.Node{
+		{Location: &pb.Location{City: "London"}},
+		{SubGraph: &pb.Graph{Nodes: []*pb.Node{{Location: &pb.Location{City: "New York City"}}}}},
+	}}}))
+
+	assert.Equal(t, []string{"starts on no date, but the user fixed 2026-06-05"}, TripPins{StartDate: pins.StartDate}.Violations(&pb.Itinerary{}))
+	assert.Equal(t, []string{"The trip starts on 2026-06-05", "The destination is Paris", "There are 2 travelers"}, pins.Lines())
+}
+
+func TestTravelAgent_Orchestrate_PinnedDate(t *testing.T) {
+	friday := time.Date(2026, 6, 5, 0, 0, 0, 0, time.UTC)
+	pins := TripPins{StartDate: friday}
+	soldOut := &pb.Error{Message: "sold out", Severity: pb.ErrorSeverity_ERROR_SEVERITY_ERROR}
+
+	// The first plan is sold out; the revision moves the trip a day to find a room, and
+	// only the one after keeps the date
+	planner := new(MockPlanner)
+	planner.On("Plan", mock.Anything, mock.Anything).Return(&PlanResult{PossibleItineraries: []*pb.Itinerary{datedPlan("Friday", friday, soldOut)}}, nil).Once()
+	planner.On("Plan", mock.Anything, mock.Anything).Return(&PlanResult{PossibleItineraries: []*pb.Itinerary{datedPlan("Saturday", friday.AddDate(0, 0, 1), nil)}}, nil).Once()
+	planner.On("Plan", mock.Anything, mock.Anything).Return(&PlanResult{PossibleItineraries: []*pb.Itinerary{datedPlan("Friday again", friday, nil)}}, nil).Once()
+
+	desk := &checkedDesk{}
+	_, itineraries, err := NewTravelAgent(planner, desk).orchestrate(context.Background(), "Paris from June 5", "", pins, nil)
+	assert.NoError(t, err)
+	if assert.Len(t, itineraries, 1) {
+		assert.Equal(t, "Friday again", itineraries[0].Title)
+	}
+
+	// The moved plan is never searched
+	assert.Equal(t, []string{"Friday", "Friday again"}, desk.checked)
+
+	// Every round is told the pins, and the last why the moved plan was rejected
+	planner.AssertNumberOfCalls(t, "Plan", 3)
+	for _, call := range planner.Calls {
+		assert.Equal(t, pins, call.Arguments.Get(1).(PlanRequest).Pins)
+	}
+	last := planner.Calls[2].Arguments.Get(1).(PlanRequest)
+	assert.Contains(t, last.History, "Plan 'Saturday' starts on 2026-06-06, but the user fixed 2026-06-05")
+}
@@ -0,0 +1,105 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tmcontext "github.com/va6996/travelingman/context"
+	"github.com/va6996/travelingman/log"
+	"github.com/va6996/travelingman/pb"
+	"github.com/va6996/travelingman/plugins/amadeus"
+	"github.com/va6996/travelingman/plugins/core"
+	"google.golang.org/protobuf/proto"
+)
+
+// TagUnverifiedDraft marks quick-mode itineraries whose availability and prices were not checked
+const TagUnverifiedDraft = "Unverified draft"
+
+// QuickPlan runs the planner once with a reduced turn budget and returns its itineraries
+// as unverified drafts. TravelDesk is skipped and the planner gets no Amadeus tools, so
+// prices are only its estimates. Drafts must still pass structural validation, but ones
+// starting in the past are moved forward. VerifyPlan checks a draft fully later.
+func (ta *TravelAgent) QuickPlan(ctx context.Context, userQuery string, maxTurns int) (string, []*pb.Itinerary, error) {
+	ctx = ta.withClock(ctx)
+	stats := tmcontext.RequestStatsFromContext(ctx)
+	stats.AddIteration()
+
+	log.Infof(ctx, "QuickPlan: Requesting draft from TripPlanner (max %d turns)...", maxTurns)
+	planRes, err := ta.planner.Plan(ctx, PlanRequest{
+		UserQuery:    userQuery,
+		MaxTurns:     maxTurns,
+		Timeout:      ta.QuickTimeout,
+		WithoutTools: []string{amadeus.ToolPrefix},
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("planner error: %w", err)
+	}
+
+	if planRes.NeedsClarification {
+		log.Infof(ctx, "TripPlanner requests clarification: %q", planRes.Question)
+		stats.SetOutcome(OutcomeClarification)
+		return planRes.Question, nil, nil
+	}
+
+	var drafts []*pb.Itinerary
+	var issues []string
+	for _, it := range planRes.PossibleItineraries {
+		applyGraphDefaults(it.GetGraph())
+		core.RepairPastDates(ctx, it)
+		if err := core.ValidateItinerary(ctx, it); err != nil {
+			log.Warnf(ctx, "QuickPlan: Dropping draft %q: %v", it.Title, err)
+			issues = append(issues, fmt.Sprintf("Plan '%s': %v", it.Title, err))
+			continue
+		}
+		it.Tags = []string{TagUnverifiedDraft}
+		it.Stats = computeItineraryStats(it)
+		drafts = append(drafts, it)
+	}
+	if len(drafts) == 0 {
+		if len(issues) == 0 {
+			return "", nil, fmt.Errorf("planner returned no itinerary and no question")
+		}
+		return "", nil, fmt.Errorf("planner returned no valid draft:\n%s", strings.Join(issues, "\n"))
+	}
+
+	var response strings.Builder
+	fmt.Fprintf(&response, "Here is a quick draft based on your request. Availability and prices have not been checked yet.\n\n%s\n\n", planRes.Reasoning)
+	for i, it := range drafts {
+		fmt.Fprintf(&response, "### Option %d: %s %s\n", i+1, it.Title, formatTags(it.Tags))
+		response.WriteString(ta.formatItinerary(it, 0))
+		response.WriteString("\n")
+	}
+	return response.String(), drafts, nil
+}
+
+// VerifyPlan runs the full availability check and scoring on a draft from QuickPlan.
+// The draft itself is not modified. When TravelDesk reports problems the verified
+// itinerary is still returned, with Error describing them, so the caller can save it.
+func (ta *TravelAgent) VerifyPlan(ctx context.Context, draft *pb.Itinerary) (*pb.Itinerary, error) {
+	if draft == nil {
+		return nil, fmt.Errorf("draft itinerary is required")
+	}
+	ctx = ta.withClock(ctx)
+	log.Infof(ctx, "VerifyPlan: Verifying draft %d: %s", draft.Id, draft.Title)
+
+	it := proto.Clone(draft).(*pb.Itinerary)
+	it.Error = nil
+
+	verified, err := ta.desk.CheckAvailability(ctx, it)
+	if err != nil {
+		return nil, fmt.Errorf("verification failed: %w", err)
+	}
+
+	// Scoring replaces the draft tag with the regular ones
+	ta.scoreAndTag([]*pb.Itinerary{verified})
+
+	if issues := availabilityIssues(verified); len(issues) > 0 {
+		log.Warnf(ctx, "VerifyPlan: Issues for %s: %v", verified.Title, issues)
+		verified.Error = &pb.Error{
+			Message:  strings.Join(issues, "; "),
+			Severity: pb.ErrorSeverity_ERROR_SEVERITY_ERROR,
+		}
+	}
+	return verified, nil
+}
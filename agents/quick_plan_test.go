@@ -0,0 +1,255 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/va6996/travelingman/pb"
+	"github.com/va6996/travelingman/plugins/amadeus"
+	"github.com/va6996/travelingman/tools"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// draftItinerary is a London to New York trip with a stay, starting at start
+func draftItinerary(start time.Time) *pb.Itinerary {
+	return &pb.Itinerary{
+		Title:       "London to New York",
+		StartTime:   timestamppb.New(start),
+		EndTime:     timestamppb.New(start.Add(4 * 24 * time.Hour)),
+		Travelers:   1,
+		JourneyType: pb.JourneyType_JOURNEY_TYPE_ONE_WAY,
+		Graph: &pb.Graph{
+			Nodes: []*pb.Node{
+				{Id: "n1", Location: &pb.Location{IataCodes: []string{"LHR"}}},
+				{Id: "n2", Location: &pb.Location{IataCodes: []string{"JFK"}}, Stay: &pb.Accommodation{
+					TravelerCount: 1,
+					CheckIn:       timestamppb.New(start.Add(4 * time.Hour)),
+					CheckOut:      timestamppb.New(start.Add(4 * 24 * time.Hour)),
+					Cost:          &pb.Cost{Value: 450},
+				}},
+			},
+			Edges: []*pb.Edge{{
+				FromId: "n1",
+				ToId:   "n2",
+				Transport: &pb.Transport{
+					Type:                pb.TransportType_TRANSPORT_TYPE_FLIGHT,
+					OriginLocation:      &pb.Location{IataCodes: []string{"LHR"}},
+					DestinationLocation: &pb.Location{IataCodes: []string{"JFK"}},
+					TravelerCount:       1,
+					Cost:                &pb.Cost{Value: 380},
+					Details:             &pb.Transport_Flight{Flight: &pb.Flight{DepartureTime: timestamppb.New(start)}},
+				},
+			}},
+		},
+	}
+}
+
+func TestTravelAgent_QuickPlan_SkipsVerification(t *testing.T) {
+	var providerCalls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&providerCalls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	client, err := amadeus.NewClient(amadeus.Config{
+		ClientID: "id", ClientSecret: "secret", IsProduction: false,
+		FlightLimit: 10, HotelLimit: 10, Timeout: 30,
+		CacheTTL: amadeus.CacheTTLConfig{Location: 24, Flight: 24, Hotel: 24},
+	}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	client.BaseURL = ts.URL
+
+	// The planner picked last year's dates
+	start := time.Now().AddDate(-1, 0, 14).UTC().Truncate(time.Hour)
+
+	mockPlanner := new(MockPlanner)
+	mockPlanner.On("Plan", mock.Anything, mock.MatchedBy(func(req PlanRequest) bool {
+		return req.MaxTurns == 3
+	})).After(50*time.Millisecond).Return(&PlanResult{
+		PossibleItineraries: []*pb.Itinerary{draftItinerary(start)},
+		Reasoning:           "Two weeks from now",
+	}, nil).Once()
+
+	agent := NewTravelAgent(mockPlanner, NewTravelDesk(client))
+
+	// The mocked LLM takes 50ms; quick mode must not add provider round trips on top
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	response, drafts, err := agent.QuickPlan(ctx, "London to New York in two weeks", 3)
+	if err != nil {
+		t.Fatalf("QuickPlan failed: %v", err)
+	}
+	mockPlanner.AssertExpectations(t)
+
+	assert.Equal(t, int32(0), atomic.LoadInt32(&providerCalls), "quick mode must not call Amadeus")
+	if !assert.Len(t, drafts, 1) {
+		return
+	}
+	draft := drafts[0]
+	assert.Equal(t, []string{TagUnverifiedDraft}, draft.Tags)
+	assert.Contains(t, response, TagUnverifiedDraft)
+	assert.Contains(t, response, "London to New York")
+
+	// Past dates are moved forward a year instead of failing validation
+	next := start.AddDate(1, 0, 0)
+	assert.Equal(t, next, draft.StartTime.AsTime())
+	assert.Equal(t, next, draft.Graph.Edges[0].Transport.GetFlight().DepartureTime.AsTime())
+	assert.Equal(t, next.Add(4*time.Hour), draft.Graph.Nodes[1].Stay.CheckIn.AsTime())
+
+	// Indicative prices from the planner are kept, with the default currency
+	assert.Equal(t, 380.0, draft.Graph.Edges[0].Transport.Cost.Value)
+	assert.Equal(t, "USD", draft.Graph.Edges[0].Transport.Cost.Currency)
+	assert.Empty(t, draft.Graph.Edges[0].TransportOptions)
+	assert.NotNil(t, draft.Stats)
+}
+
+func TestTravelAgent_QuickPlan_WithholdsAmadeusTools(t *testing.T) {
+	var providerCalls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&providerCalls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	ctx := context.Background()
+	gk := genkit.Init(ctx)
+	registry := tools.NewRegistry()
+	client, err := amadeus.NewClient(amadeus.Config{
+		ClientID: "id", ClientSecret: "secret", IsProduction: false,
+		FlightLimit: 10, HotelLimit: 10, Timeout: 30,
+		CacheTTL: amadeus.CacheTTLConfig{Location: 24, Flight: 24, Hotel: 24},
+	}, gk, registry, nil)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	client.BaseURL = ts.URL
+
+	// The model looks up an airport whenever it is offered the tool, then answers
+	draft, err := protojson.Marshal(draftItinerary(time.Now().Add(14 * 24 * time.Hour).UTC().Truncate(time.Hour)))
+	if err != nil {
+		t.Fatalf("Failed to marshal draft: %v", err)
+	}
+	var deadline time.Time
+	model := genkit.DefineModel(gk, "test/planner", &ai.ModelOptions{Supports: &ai.ModelSupports{Tools: true, Multiturn: true, SystemRole: true}},
+		func(ctx context.Context, req *ai.ModelRequest, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+			deadline, _ = ctx.Deadline()
+			asked := false
+			for _, m := range req.Messages {
+				for _, p := range m.Content {
+					asked = asked || p.IsToolRequest()
+				}
+			}
+			for _, tool := range req.Tools {
+				if tool.Name == amadeus.ToolPrefix+"location_tool" && !asked {
+					return &ai.ModelResponse{Request: req, Message: ai.NewModelMessage(ai.NewToolRequestPart(&ai.ToolRequest{
+						Name: tool.Name, Input: map[string]any{"keyword": "London"},
+					}))}, nil
+				}
+			}
+			answer := fmt.Sprintf(`{"itineraries": [%s], "reasoning": "Two weeks from now"}`, draft)
+			return &ai.ModelResponse{Request: req, Message: ai.NewModelTextMessage(answer), FinishReason: ai.FinishReasonStop}, nil
+		})
+
+	agent := NewTravelAgent(NewTripPlanner(gk, registry, model), NewTravelDesk(client))
+	agent.QuickTimeout = 30 * time.Second
+
+	start := time.Now()
+	_, drafts, err := agent.QuickPlan(ctx, "London to New York in two weeks", 3)
+	if err != nil {
+		t.Fatalf("QuickPlan failed: %v", err)
+	}
+	assert.Len(t, drafts, 1)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&providerCalls), "quick mode must not call Amadeus")
+	assert.WithinDuration(t, start.Add(agent.QuickTimeout), deadline, 5*time.Second, "quick mode uses its own timeout")
+
+	// A full plan is offered the same tools and does reach Amadeus
+	agent.planner.Plan(ctx, PlanRequest{UserQuery: "London to New York in two weeks"})
+	assert.NotZero(t, atomic.LoadInt32(&providerCalls))
+}
+
+func TestTravelAgent_QuickPlan_InvalidDraft(t *testing.T) {
+	broken := draftItinerary(time.Now().Add(24 * time.Hour))
+	broken.Graph.Edges[0].ToId = "missing"
+
+	mockPlanner := new(MockPlanner)
+	mockPlanner.On("Plan", mock.Anything, mock.Anything).Return(&PlanResult{
+		PossibleItineraries: []*pb.Itinerary{broken},
+	}, nil).Once()
+
+	agent := NewTravelAgent(mockPlanner, nil)
+	_, drafts, err := agent.QuickPlan(context.Background(), "London to New York tomorrow", 3)
+	assert.Error(t, err, "structural validation still runs")
+	assert.Contains(t, err.Error(), "ToId 'missing' not found in nodes")
+	assert.Empty(t, drafts)
+}
+
+func TestTravelAgent_VerifyPlan(t *testing.T) {
+	ts := mockAmadeusServer()
+	defer ts.Close()
+
+	client, err := amadeus.NewClient(amadeus.Config{
+		ClientID: "id", ClientSecret: "secret", IsProduction: false,
+		FlightLimit: 10, HotelLimit: 10, Timeout: 30,
+		CacheTTL: amadeus.CacheTTLConfig{Location: 24, Flight: 24, Hotel: 24},
+	}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	client.BaseURL = ts.URL
+
+	mockPlanner := new(MockPlanner)
+	mockPlanner.On("Plan", mock.Anything, mock.Anything).Return(&PlanResult{
+		PossibleItineraries: []*pb.Itinerary{draftItinerary(time.Now().Add(7 * 24 * time.Hour).UTC().Truncate(time.Hour))},
+	}, nil).Once()
+
+	agent := NewTravelAgent(mockPlanner, NewTravelDesk(client))
+	_, drafts, err := agent.QuickPlan(context.Background(), "London to New York next week", 3)
+	if err != nil {
+		t.Fatalf("QuickPlan failed: %v", err)
+	}
+	if !assert.Len(t, drafts, 1) {
+		return
+	}
+	drafts[0].Id = 42
+	drafts[0].Version = 1
+
+	verified, err := agent.VerifyPlan(context.Background(), drafts[0])
+	if err != nil {
+		t.Fatalf("VerifyPlan failed: %v", err)
+	}
+
+	// Verification replaces the draft tag with the regular scoring
+	assert.NotContains(t, verified.Tags, TagUnverifiedDraft)
+	assert.Contains(t, verified.Tags, "Lowest Overall Cost")
+	assert.Nil(t, verified.Error)
+	assert.Equal(t, int64(42), verified.Id, "the plan keeps its identity so it can be saved over the draft")
+	assert.Equal(t, int64(1), verified.Version)
+
+	flight := verified.Graph.Edges[0]
+	if assert.NotEmpty(t, flight.TransportOptions) {
+		assert.Equal(t, 100.0, flight.Transport.GetCost().GetValue())
+		assert.Contains(t, flight.Transport.Tags, "Best Value")
+	}
+	stay := verified.Graph.Nodes[1]
+	if assert.NotEmpty(t, stay.StayOptions) {
+		assert.Equal(t, 500.0, stay.Stay.GetCost().GetValue())
+	}
+	assert.NotNil(t, verified.Stats)
+
+	// The draft itself is left untouched
+	assert.Equal(t, []string{TagUnverifiedDraft}, drafts[0].Tags)
+	assert.Empty(t, drafts[0].Graph.Edges[0].TransportOptions)
+}
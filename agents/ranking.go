@@ -0,0 +1,102 @@
+package agents
+
+import (
+	"time"
+
+	"github.com/va6996/travelingman/agents/options"
+)
+
+// timeValuePerHour is what an hour of travel is worth when comparing transport options
+const timeValuePerHour = 20.0
+
+// TagPreferredChain marks stays at a hotel of one of the preferred chains
+const TagPreferredChain = "Preferred Chain"
+
+// preferredChainDiscount is how much cheaper a preferred-chain stay scores, as a
+// share of its price, for the loyalty points it earns
+const preferredChainDiscount = 0.1
+
+// optionScorer scores one option, lower being better, and returns its kind-specific
+// tags. fastest is the shortest known duration among the options being ranked.
+type optionScorer func(o options.Option, fastest time.Duration) (score float64, tags []string)
+
+// rankOptions tags one edge's or node's options and orders them best first. Every kind
+// is tagged Cheapest and Best Value the same way; score adds the rest. If spread is
+// set, the best option of each of its keys comes before the others.
+func (ta *TravelAgent) rankOptions(opts []options.Option, score optionScorer, spread func(options.Option) string) []options.Option {
+	cheapest, fastest := options.MinCost(opts), options.MinDuration(opts)
+	scores := make([]float64, len(opts))
+	for i, o := range opts {
+		tags := []string{}
+		if o.Cost() == cheapest {
+			tags = append(tags, "Cheapest")
+		}
+		s, extra := score(o, fastest)
+		o.SetTags(append(tags, extra...))
+		scores[i] = s
+	}
+
+	ranked := options.SortByScore(opts, scores)
+	if spread != nil {
+		ranked = options.SpreadBy(ranked, spread)
+	}
+	ranked[0].SetTags(append(ranked[0].Tags(), "Best Value"))
+	return ranked
+}
+
+// scoreTransport scores a transport by its price, the value of its travel time and the
+// risk of its connections
+func (ta *TravelAgent) scoreTransport(o options.Option, fastest time.Duration) (float64, []string) {
+	var tags []string
+	score := o.Cost()
+	if d := o.Duration(); d > 0 {
+		if d == fastest {
+			tags = append(tags, "Fastest")
+		}
+		score += d.Hours() * timeValuePerHour
+	}
+
+	// Layovers below the airport's minimum connection time risk a missed flight
+	tight := ta.tightConnections(o.(options.Transport).Transport)
+	if tight > 0 {
+		tags = append(tags, TagTightConnection)
+	}
+	score += float64(tight) * tightConnectionPenalty
+	return score, tags
+}
+
+// stayScorer scores stays by their price, less preferredChainDiscount at a preferred
+// chain, plus breakfast bought separately if the traveller wants it. If near is set,
+// the user wants to stay near transit: the distance to it is added at
+// transitValuePerKm, and stays close to it are tagged. Stays without coordinates are
+// ranked on the rest alone. A stay's duration is its nights, so none is the fastest.
+func (ta *TravelAgent) stayScorer(wantsBreakfast bool, near *arrival) optionScorer {
+	return func(o options.Option, _ time.Duration) (float64, []string) {
+		s := o.(options.Stay).Stay
+		var tags []string
+		if s.BreakfastIncluded {
+			tags = append(tags, TagBreakfastIncluded)
+		}
+		price := o.Cost()
+		if s.PreferredChain {
+			tags = append(tags, TagPreferredChain)
+			price *= 1 - preferredChainDiscount
+		}
+		score := price + ta.breakfastCost(wantsBreakfast, s)
+		if near == nil {
+			return score, tags
+		}
+		if km, ok := near.distanceKm(s); ok {
+			if km <= nearTransitKm {
+				tags = append(tags, near.tag)
+			}
+			score += km * transitValuePerKm
+		}
+		return score, tags
+	}
+}
+
+// stayHotel keys stays by property
+func stayHotel(o options.Option) string {
+	return o.(options.Stay).Hotel()
+}
@@ -0,0 +1,117 @@
+package agents
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/agents/options"
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func rankingFlight(number string, price float64, hours int) *pb.Transport {
+	dep := time.Date(2026, 6, 1, 8, 0, 0, 0, time.UTC)
+	return &pb.Transport{
+		Type: pb.TransportType_TRANSPORT_TYPE_FLIGHT,
+		Cost: &pb.Cost{Value: price, Currency: "USD"},
+		Details: &pb.Transport_Flight{Flight: &pb.Flight{
+			CarrierCode:   "UA",
+			FlightNumber:  number,
+			DepartureTime: timestamppb.New(dep),
+			ArrivalTime:   timestamppb.New(dep.Add(time.Duration(hours) * time.Hour)),
+		}},
+	}
+}
+
+func rankingStay(hotel string, price float64, nights int) *pb.Accommodation {
+	checkIn := time.Date(2026, 6, 1, 15, 0, 0, 0, time.UTC)
+	return &pb.Accommodation{
+		HotelId:  hotel,
+		Name:     "Hotel " + hotel,
+		Cost:     &pb.Cost{Value: price, Currency: "USD"},
+		CheckIn:  timestamppb.New(checkIn),
+		CheckOut: timestamppb.New(checkIn.AddDate(0, 0, nights).Add(-4 * time.Hour)),
+	}
+}
+
+// ranked formats options as "cost:tag,tag"
+func ranked(opts []options.Option) []string {
+	var out []string
+	for _, o := range opts {
+		out = append(out, fmt.Sprintf("%.0f:%v", o.Cost(), o.Tags()))
+	}
+	return out
+}
+
+func TestTravelAgent_RankOptions_SharedPath(t *testing.T) {
+	ta := NewTravelAgent(nil, nil)
+	byCost := func(o options.Option, _ time.Duration) (float64, []string) { return o.Cost(), nil }
+
+	// Both kinds go through the same tagging and ordering
+	tests := []struct {
+		name string
+		opts []options.Option
+	}{
+		{"Transports", options.Transports([]*pb.Transport{rankingFlight("1", 300, 5), rankingFlight("2", 200, 7), rankingFlight("3", 250, 6)})},
+		{"Stays", options.Stays([]*pb.Accommodation{rankingStay("A", 300, 2), rankingStay("B", 200, 3), rankingStay("C", 250, 2)})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, []string{"200:[Cheapest Best Value]", "250:[]", "300:[]"}, ranked(ta.rankOptions(tt.opts, byCost, nil)))
+		})
+	}
+}
+
+func TestTravelAgent_RankOptions_KindHooks(t *testing.T) {
+	ta := NewTravelAgent(nil, nil)
+
+	// The shortest flight is the fastest, and its time is worth $20 an hour
+	flights := ta.rankOptions(options.Transports([]*pb.Transport{
+		rankingFlight("1", 300, 5),
+		rankingFlight("2", 200, 11),
+		rankingFlight("3", 250, 6),
+	}), ta.scoreTransport, nil)
+	assert.Equal(t, []string{"250:[Best Value]", "300:[Fastest]", "200:[Cheapest]"}, ranked(flights))
+
+	// The shortest stay is not the "fastest"; a hotel's second rate waits for the others
+	stays := ta.rankOptions(options.Stays([]*pb.Accommodation{
+		rankingStay("A", 220, 1),
+		rankingStay("A", 200, 2),
+		rankingStay("B", 250, 2),
+	}), ta.stayScorer(false, nil), stayHotel)
+	assert.Equal(t, []string{"200:[Cheapest Best Value]", "250:[]", "220:[]"}, ranked(stays))
+	assert.Equal(t, "B", options.ToStays(stays)[1].HotelId)
+}
+
+func TestTravelAgent_RankOptions_TiesAreDeterministic(t *testing.T) {
+	ta := NewTravelAgent(nil, nil)
+
+	// Two flights at the same price and duration: the best value does not depend on
+	// the order the provider listed them in
+	for _, order := range [][]string{{"2", "1"}, {"1", "2"}} {
+		var flights []*pb.Transport
+		for _, number := range order {
+			flights = append(flights, rankingFlight(number, 200, 6))
+		}
+		best := options.ToTransports(ta.rankOptions(options.Transports(flights), ta.scoreTransport, nil))[0]
+		assert.Equal(t, "1", best.GetFlight().FlightNumber, "order %v", order)
+		assert.Contains(t, best.Tags, "Best Value")
+	}
+}
+
+func TestTravelAgent_RankOptions_PreferredChain(t *testing.T) {
+	ta := NewTravelAgent(nil, nil)
+
+	// The preferred chain wins within 10% of the cheapest, but not beyond
+	marriott := rankingStay("MC1", 210, 2)
+	marriott.PreferredChain = true
+	stays := ta.rankOptions(options.Stays([]*pb.Accommodation{rankingStay("A", 200, 2), marriott}), ta.stayScorer(false, nil), stayHotel)
+	assert.Equal(t, []string{"210:[Preferred Chain Best Value]", "200:[Cheapest]"}, ranked(stays))
+
+	marriott = rankingStay("MC1", 250, 2)
+	marriott.PreferredChain = true
+	stays = ta.rankOptions(options.Stays([]*pb.Accommodation{rankingStay("A", 200, 2), marriott}), ta.stayScorer(false, nil), stayHotel)
+	assert.Equal(t, []string{"200:[Cheapest Best Value]", "250:[Preferred Chain]"}, ranked(stays))
+}
@@ -0,0 +1,62 @@
+package agents
+
+import (
+	"fmt"
+
+	"github.com/va6996/travelingman/pb"
+)
+
+// ReplanPolicy decides which availability problems send a plan back to the planner
+type ReplanPolicy string
+
+const (
+	// ReplanOnErrors re-plans only when a transport or stay could not be found
+	ReplanOnErrors ReplanPolicy = "errors"
+	// ReplanOnWarnings also re-plans for warnings such as tight connections or holiday pricing
+	ReplanOnWarnings ReplanPolicy = "warnings"
+)
+
+// threshold is the lowest severity that triggers a re-plan under the policy
+func (p ReplanPolicy) threshold() pb.ErrorSeverity {
+	if p == ReplanOnWarnings {
+		return pb.ErrorSeverity_ERROR_SEVERITY_WARNING
+	}
+	return pb.ErrorSeverity_ERROR_SEVERITY_ERROR
+}
+
+// availabilityIssues lists the transport and stay errors TravelDesk reported for an itinerary
+func availabilityIssues(it *pb.Itinerary) []string {
+	return issuesFrom(it, pb.ErrorSeverity_ERROR_SEVERITY_ERROR)
+}
+
+// issuesFrom lists the transport and stay problems at or above the given severity
+func issuesFrom(it *pb.Itinerary, min pb.ErrorSeverity) []string {
+	var issues []string
+	if it.GetGraph() == nil {
+		return nil
+	}
+	// Check Flights
+	for _, edge := range it.Graph.Edges {
+		if e := edge.GetTransport().GetError(); e.GetSeverity() >= min {
+			issues = append(issues, fmt.Sprintf("Transport %s: %s", severityLabel(e.GetSeverity()), e.Message))
+		}
+	}
+	// Check Accommodation
+	for _, node := range it.Graph.Nodes {
+		if e := node.GetStay().GetError(); e.GetSeverity() >= min {
+			issues = append(issues, fmt.Sprintf("Stay %s: %s", severityLabel(e.GetSeverity()), e.Message))
+		}
+	}
+	return issues
+}
+
+func severityLabel(s pb.ErrorSeverity) string {
+	switch s {
+	case pb.ErrorSeverity_ERROR_SEVERITY_ERROR:
+		return "error"
+	case pb.ErrorSeverity_ERROR_SEVERITY_WARNING:
+		return "warning"
+	default:
+		return "notice"
+	}
+}
@@ -0,0 +1,135 @@
+package agents
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	tmcontext "github.com/va6996/travelingman/context"
+	"github.com/va6996/travelingman/pb"
+)
+
+// passDesk returns every itinerary as the planner proposed it
+type passDesk struct{}
+
+func (passDesk) CheckAvailability(ctx context.Context, it *pb.Itinerary) (*pb.Itinerary, error) {
+	return it, nil
+}
+
+// stayPlan is a one-hotel itinerary whose stay carries the given problem, if any
+func stayPlan(title string, problem *pb.Error) *pb.Itinerary {
+	return &pb.Itinerary{
+		Title: title,
+		Graph: &pb.Graph{Nodes: []*pb.Node{{
+			Id:       "paris",
+			Location: &pb.Location{City: "Paris"},
+			Stay:     &pb.Accommodation{Name: "Hotel " + title, Error: problem},
+		}}},
+	}
+}
+
+func TestTravelAgent_OrchestrateRequest_ReplanPolicy(t *testing.T) {
+	holiday := &pb.Error{Message: "holiday pricing in effect", Severity: pb.ErrorSeverity_ERROR_SEVERITY_WARNING}
+	revised := func(req PlanRequest) bool { return strings.Contains(req.History, "The proposed plans had issues") }
+
+	tests := []struct {
+		policy    ReplanPolicy
+		wantTitle string
+		wantPlans int
+	}{
+		{policy: "", wantTitle: "warned", wantPlans: 1},
+		{policy: ReplanOnErrors, wantTitle: "warned", wantPlans: 1},
+		{policy: ReplanOnWarnings, wantTitle: "clean", wantPlans: 2},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			planner := new(MockPlanner)
+			planner.On("Plan", mock.Anything, mock.MatchedBy(func(req PlanRequest) bool { return !revised(req) })).
+				Return(&PlanResult{PossibleItineraries: []*pb.Itinerary{stayPlan("warned", holiday)}}, nil).Maybe()
+			planner.On("Plan", mock.Anything, mock.MatchedBy(revised)).
+				Return(&PlanResult{PossibleItineraries: []*pb.Itinerary{stayPlan("clean", nil)}}, nil).Maybe()
+
+			agent := NewTravelAgent(planner, passDesk{})
+			agent.ReplanPolicy = tt.policy
+
+			_, itineraries, err := agent.OrchestrateRequest(context.Background(), "Paris", "")
+			assert.NoError(t, err)
+			if assert.Len(t, itineraries, 1) {
+				assert.Equal(t, tt.wantTitle, itineraries[0].Title)
+			}
+			planner.AssertNumberOfCalls(t, "Plan", tt.wantPlans)
+		})
+	}
+}
+
+func TestTravelAgent_OrchestrateRequest_ReplanOnWarningsFallsBack(t *testing.T) {
+	holiday := &pb.Error{Message: "holiday pricing in effect", Severity: pb.ErrorSeverity_ERROR_SEVERITY_WARNING}
+	missing := &pb.Error{Message: "no hotels found", Severity: pb.ErrorSeverity_ERROR_SEVERITY_ERROR}
+
+	// Every attempt still has a warning, so the last attempt's warned plan is returned
+	planner := new(MockPlanner)
+	planner.On("Plan", mock.Anything, mock.Anything).Return(&PlanResult{PossibleItineraries: []*pb.Itinerary{
+		stayPlan("warned", holiday),
+		stayPlan("failed", missing),
+	}}, nil)
+
+	agent := NewTravelAgent(planner, passDesk{})
+	agent.ReplanPolicy = ReplanOnWarnings
+
+	_, itineraries, err := agent.OrchestrateRequest(context.Background(), "Paris", "")
+	assert.NoError(t, err)
+	if assert.Len(t, itineraries, 1) {
+		assert.Equal(t, "warned", itineraries[0].Title)
+	}
+	planner.AssertNumberOfCalls(t, "Plan", 5)
+}
+
+func TestIssuesFrom(t *testing.T) {
+	it := stayPlan("warned", &pb.Error{Message: "tight connection", Severity: pb.ErrorSeverity_ERROR_SEVERITY_WARNING})
+
+	assert.Empty(t, issuesFrom(it, ReplanOnErrors.threshold()))
+	assert.Equal(t, []string{"Stay warning: tight connection"}, issuesFrom(it, ReplanOnWarnings.threshold()))
+}
+
+// budgetDesk spends one provider call per plan and marks the stay unavailable once
+// the request's call budget is spent
+type budgetDesk struct{}
+
+func (budgetDesk) CheckAvailability(ctx context.Context, it *pb.Itinerary) (*pb.Itinerary, error) {
+	if !tmcontext.TakeCall(ctx) {
+		it.Graph.Nodes[0].Stay.Error = &pb.Error{Message: tmcontext.ErrCallBudgetExhausted.Error(), Code: pb.ErrorCode_ERROR_CODE_API_LIMIT_REACHED}
+	}
+	return it, nil
+}
+
+func TestTravelAgent_OrchestrateRequest_CallBudgetExhausted(t *testing.T) {
+	t.Run("ReturnsBestSoFar", func(t *testing.T) {
+		planner := new(MockPlanner)
+		planner.On("Plan", mock.Anything, mock.Anything).
+			Return(&PlanResult{PossibleItineraries: []*pb.Itinerary{stayPlan("Paris", nil)}}, nil)
+
+		ctx := tmcontext.WithCallBudget(context.Background(), tmcontext.NewCallBudget(0))
+		_, itineraries, err := NewTravelAgent(planner, budgetDesk{}).OrchestrateRequest(ctx, "Paris", "")
+		assert.NoError(t, err)
+		if assert.Len(t, itineraries, 1) {
+			assert.Equal(t, "Paris", itineraries[0].Title)
+		}
+		// No re-planning once the searches have run out
+		planner.AssertNumberOfCalls(t, "Plan", 1)
+	})
+
+	t.Run("WithinBudget", func(t *testing.T) {
+		planner := new(MockPlanner)
+		planner.On("Plan", mock.Anything, mock.Anything).
+			Return(&PlanResult{PossibleItineraries: []*pb.Itinerary{stayPlan("Paris", nil)}}, nil)
+
+		budget := tmcontext.NewCallBudget(5)
+		ctx := tmcontext.WithCallBudget(context.Background(), budget)
+		_, itineraries, err := NewTravelAgent(planner, budgetDesk{}).OrchestrateRequest(ctx, "Paris", "")
+		assert.NoError(t, err)
+		assert.Len(t, itineraries, 1)
+		assert.False(t, budget.Exhausted())
+	})
+}
@@ -0,0 +1,128 @@
+package agents
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	tmcontext "github.com/va6996/travelingman/context"
+	"github.com/va6996/travelingman/pb"
+	"github.com/va6996/travelingman/plugins/amadeus"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// fixedRun plans a London to New York trip as test mode would: on a fixed clock, with
+// seeded request IDs, against a canned provider. It returns the serialized response.
+func fixedRun(t *testing.T) string {
+	now := time.Date(2030, 1, 7, 9, 0, 0, 0, time.UTC)
+	tmcontext.SetIDGenerator(tmcontext.SeededIDs(1))
+	t.Cleanup(func() { tmcontext.SetIDGenerator(nil) })
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/security/oauth2/token":
+			json.NewEncoder(w).Encode(amadeus.AuthToken{AccessToken: "token", ExpiresIn: 1800})
+		case "/v1/reference-data/locations":
+			code := r.URL.Query().Get("keyword")
+			json.NewEncoder(w).Encode(amadeus.LocationSearchResponse{
+				Data: []amadeus.LocationData{{
+					SubType: "AIRPORT", Name: code, JobCode: code,
+					Address: amadeus.Address{CityName: "City " + code, CityCode: code, CountryName: "TEST", CountryCode: "TS"},
+				}},
+			})
+		case "/v2/shopping/flight-offers":
+			json.NewEncoder(w).Encode(amadeus.FlightSearchResponse{
+				Data: []amadeus.FlightOffer{{
+					ID:    "1",
+					Price: amadeus.Price{Currency: "EUR", Total: "420.00"},
+					Itineraries: []amadeus.Itinerary{{Segments: []amadeus.Segment{{
+						CarrierCode: "BA", Number: "117",
+						Departure: amadeus.FlightEndPoint{IataCode: "LHR", At: "2030-02-01T10:00:00"},
+						Arrival:   amadeus.FlightEndPoint{IataCode: "JFK", At: "2030-02-01T13:00:00"},
+					}}}},
+				}},
+			})
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	client, err := amadeus.NewClient(amadeus.Config{
+		ClientID: "id", ClientSecret: "secret", FlightLimit: 10, HotelLimit: 10, Timeout: 30,
+		CacheTTL: amadeus.CacheTTLConfig{Location: 24, Flight: 24, Hotel: 24},
+	}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	client.BaseURL = ts.URL
+	client.Cache.Clock = tmcontext.FixedClock(now)
+
+	departure := timestamppb.New(time.Date(2030, 2, 1, 10, 0, 0, 0, time.UTC))
+	planner := new(MockPlanner)
+	planner.On("Plan", mock.Anything, mock.Anything).Return(&PlanResult{PossibleItineraries: []*pb.Itinerary{{
+		Title:       "London to New York",
+		StartTime:   departure,
+		EndTime:     timestamppb.New(departure.AsTime().Add(8 * time.Hour)),
+		Travelers:   1,
+		JourneyType: pb.JourneyType_JOURNEY_TYPE_ONE_WAY,
+		Graph: &pb.Graph{
+			Nodes: []*pb.Node{
+				{Id: "london", Location: &pb.Location{IataCodes: []string{"LHR"}}},
+				{Id: "new_york", Location: &pb.Location{IataCodes: []string{"JFK"}}},
+			},
+			Edges: []*pb.Edge{{
+				FromId: "london",
+				ToId:   "new_york",
+				Transport: &pb.Transport{
+					Type:                pb.TransportType_TRANSPORT_TYPE_FLIGHT,
+					OriginLocation:      &pb.Location{IataCodes: []string{"LHR"}},
+					DestinationLocation: &pb.Location{IataCodes: []string{"JFK"}},
+					TravelerCount:       1,
+					Details:             &pb.Transport_Flight{Flight: &pb.Flight{DepartureTime: departure}},
+				},
+			}},
+		},
+	}}}, nil)
+
+	agent := NewTravelAgent(planner, NewTravelDesk(client))
+	agent.Clock = tmcontext.FixedClock(now)
+
+	requestID := tmcontext.NewRequestID()
+	ctx := tmcontext.WithRequestID(context.Background(), requestID)
+	_, itineraries, err := agent.OrchestrateRequest(ctx, "London to New York on February 1st", "")
+	if err != nil {
+		t.Fatalf("OrchestrateRequest failed: %v", err)
+	}
+
+	b, err := protojson.Marshal(&pb.PlanTripResponse{Itineraries: itineraries})
+	if err != nil {
+		t.Fatalf("Failed to encode response: %v", err)
+	}
+	// protojson varies its whitespace between builds; re-indent for a stable diff
+	var out bytes.Buffer
+	if err := json.Indent(&out, b, "", "  "); err != nil {
+		t.Fatalf("Failed to indent response: %v", err)
+	}
+	return "request_id: " + requestID + "\n" + out.String() + "\n"
+}
+
+func TestTravelAgent_OrchestrateRequest_Reproducible(t *testing.T) {
+	first := fixedRun(t)
+	assert.Equal(t, first, fixedRun(t), "two runs on the fixed clock differ")
+
+	golden, err := os.ReadFile("testdata/plan_fixed_clock.golden")
+	if err != nil {
+		t.Fatalf("Failed to read golden file: %v", err)
+	}
+	assert.Equal(t, string(golden), first)
+}
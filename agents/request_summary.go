@@ -0,0 +1,51 @@
+package agents
+
+import (
+	"context"
+	"errors"
+
+	tmcontext "github.com/va6996/travelingman/context"
+	"github.com/va6996/travelingman/log"
+	"github.com/va6996/travelingman/pb"
+)
+
+// Outcomes reported in the request summary
+const (
+	OutcomeOK            = "ok"
+	OutcomeClarification = "clarification"
+	OutcomeTimeout       = "timeout"
+	OutcomeError         = "error"
+)
+
+// summaryQueryLen is the number of characters of the query kept in the summary
+const summaryQueryLen = 80
+
+// LogRequestSummary logs one info line summarizing a planning request from the
+// stats accumulated in ctx, so operators can grep a single line per plan.
+func LogRequestSummary(ctx context.Context, query string, itineraries []*pb.Itinerary, err error) {
+	stats := tmcontext.RequestStatsFromContext(ctx)
+
+	outcome := OutcomeOK
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		outcome = OutcomeTimeout
+	case err != nil:
+		outcome = OutcomeError
+	case stats.Outcome() != "":
+		outcome = stats.Outcome()
+	case len(itineraries) == 0:
+		outcome = OutcomeError
+	}
+
+	log.Infof(ctx, "PlanTrip summary: query=%q outcome=%s itineraries=%d %s",
+		truncateQuery(query), outcome, len(itineraries), stats)
+}
+
+// truncateQuery shortens the query to summaryQueryLen characters
+func truncateQuery(query string) string {
+	runes := []rune(query)
+	if len(runes) <= summaryQueryLen {
+		return query
+	}
+	return string(runes[:summaryQueryLen]) + "…"
+}
@@ -0,0 +1,144 @@
+package agents
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	tmcontext "github.com/va6996/travelingman/context"
+	"github.com/va6996/travelingman/log"
+	"github.com/va6996/travelingman/pb"
+	"github.com/va6996/travelingman/plugins/amadeus"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestLogRequestSummary(t *testing.T) {
+	var buf bytes.Buffer
+	origOut, origFormatter, origLevel := log.Logger.Out, log.Logger.Formatter, log.Logger.GetLevel()
+	log.SetOutput(&buf)
+	log.SetFormatter(&log.CustomFormatter{TimestampFormat: "2006-01-02 15:04:05"})
+	log.SetLevel(logrus.InfoLevel)
+	defer func() {
+		log.SetOutput(origOut)
+		log.SetFormatter(origFormatter)
+		log.SetLevel(origLevel)
+	}()
+
+	ts := mockAmadeusServer()
+	defer ts.Close()
+
+	client, err := amadeus.NewClient(amadeus.Config{
+		ClientID: "id", ClientSecret: "secret", IsProduction: false,
+		FlightLimit: 10, HotelLimit: 10, Timeout: 30,
+		CacheTTL: amadeus.CacheTTLConfig{Location: 24, Flight: 24, Hotel: 24},
+	}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	client.BaseURL = ts.URL
+
+	departure := timestamppb.New(time.Now().Add(24 * time.Hour))
+	itin := &pb.Itinerary{
+		Title:       "London to New York",
+		StartTime:   departure,
+		EndTime:     timestamppb.New(time.Now().Add(48 * time.Hour)),
+		Travelers:   1,
+		JourneyType: pb.JourneyType_JOURNEY_TYPE_ONE_WAY,
+		Graph: &pb.Graph{
+			Nodes: []*pb.Node{
+				{Id: "n1", Location: &pb.Location{IataCodes: []string{"LHR"}}},
+				{Id: "n2", Location: &pb.Location{IataCodes: []string{"JFK"}}},
+			},
+			Edges: []*pb.Edge{{
+				FromId: "n1",
+				ToId:   "n2",
+				Transport: &pb.Transport{
+					Type:                pb.TransportType_TRANSPORT_TYPE_FLIGHT,
+					OriginLocation:      &pb.Location{IataCodes: []string{"LHR"}},
+					DestinationLocation: &pb.Location{IataCodes: []string{"JFK"}},
+					TravelerCount:       1,
+					Details:             &pb.Transport_Flight{Flight: &pb.Flight{DepartureTime: departure}},
+				},
+			}},
+		},
+	}
+
+	// The mocked planner records the conversation the real planner would have had
+	mockPlanner := new(MockPlanner)
+	mockPlanner.On("Plan", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		ctx := args.Get(0).(context.Context)
+		recordPlannerActivity(tmcontext.RequestStatsFromContext(ctx), []*ai.Message{
+			ai.NewUserTextMessage("London to New York tomorrow"),
+			ai.NewModelMessage(ai.NewToolRequestPart(&ai.ToolRequest{Name: "dateTool"})),
+			ai.NewModelTextMessage(`{"itineraries": []}`),
+		})
+	}).Return(&PlanResult{PossibleItineraries: []*pb.Itinerary{itin}}, nil).Once()
+
+	agent := NewTravelAgent(mockPlanner, NewTravelDesk(client))
+
+	ctx := tmcontext.WithRequestStats(context.Background(), tmcontext.NewRequestStats())
+	query := "London to New York tomorrow, " + strings.Repeat("flexible on times ", 10)
+	_, itineraries, err := agent.OrchestrateRequest(ctx, query, "")
+	LogRequestSummary(ctx, query, itineraries, err)
+
+	if err != nil {
+		t.Fatalf("OrchestrateRequest failed: %v", err)
+	}
+
+	var summary string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if strings.Contains(line, "PlanTrip summary:") {
+			summary = line
+		}
+	}
+	if !assert.NotEmpty(t, summary, "summary line not logged") {
+		return
+	}
+	assert.Contains(t, summary, "outcome=ok")
+	assert.Contains(t, summary, "itineraries=1")
+	assert.Contains(t, summary, "iterations=1")
+	assert.Contains(t, summary, "planner_steps=2")
+	assert.Contains(t, summary, "tools=dateTool:1")
+	assert.Contains(t, summary, "amadeus/v2/shopping/flight-offers:1")
+	assert.Contains(t, summary, "flight:0/1")
+	assert.Contains(t, summary, "…", "the query is truncated")
+	assert.NotContains(t, summary, strings.Repeat("flexible on times ", 10))
+
+	// Per-offer lines stay out of info logs
+	assert.NotContains(t, buf.String(), "PopulateAncillaryBaggagePricing")
+	assert.NotContains(t, buf.String(), "flight options")
+	assert.NotContains(t, buf.String(), "TravelDesk itinerary")
+}
+
+func TestLogRequestSummary_Outcomes(t *testing.T) {
+	var buf bytes.Buffer
+	origOut, origFormatter := log.Logger.Out, log.Logger.Formatter
+	log.SetOutput(&buf)
+	log.SetFormatter(&log.CustomFormatter{TimestampFormat: "2006-01-02 15:04:05"})
+	defer func() {
+		log.SetOutput(origOut)
+		log.SetFormatter(origFormatter)
+	}()
+
+	stats := tmcontext.NewRequestStats()
+	ctx := tmcontext.WithRequestStats(context.Background(), stats)
+
+	LogRequestSummary(ctx, "q", nil, context.DeadlineExceeded)
+	assert.Contains(t, buf.String(), "outcome=timeout")
+
+	buf.Reset()
+	stats.SetOutcome(OutcomeClarification)
+	LogRequestSummary(ctx, "q", nil, nil)
+	assert.Contains(t, buf.String(), "outcome=clarification")
+
+	// Without stats in the context the summary still logs
+	buf.Reset()
+	LogRequestSummary(context.Background(), "q", nil, nil)
+	assert.Contains(t, buf.String(), "outcome=error")
+}
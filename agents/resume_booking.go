@@ -0,0 +1,208 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/va6996/travelingman/log"
+	"github.com/va6996/travelingman/orm"
+	"github.com/va6996/travelingman/pb"
+	"github.com/va6996/travelingman/plugins/amadeus"
+)
+
+// Statuses of the transports and stays booked by ResumeBooking and cancelled by
+// CancelItinerary
+const (
+	SegmentBooked    = "BOOKED"
+	SegmentCancelled = "CANCELLED"
+)
+
+// ItineraryBooking is what is needed to book an itinerary's segments
+type ItineraryBooking struct {
+	Users []*pb.User
+	// FlightOffers are the offers to book for flight edges, keyed by "fromID->toID"
+	FlightOffers map[string]amadeus.FlightOffer
+	// HotelOffers are the offer IDs to book for stays, keyed by node ID
+	HotelOffers map[string]string
+	Guests      []amadeus.HotelGuest
+	Payment     amadeus.HotelPayment
+}
+
+// ResumeBooking books the segments of it that are not booked yet and returns how many
+// it booked. It is safe to call again after a booking was interrupted: a segment with
+// a reference is checked with its provider and only booked again if the provider no
+// longer has its order or the order was cancelled. A reference that cannot be checked
+// stops the booking, so nothing is booked twice. Segments without an offer in req and
+// segments cancelled with CancelItinerary are left alone. References of what is booked
+// are written to it as it goes, so it should be stored even when an error is returned.
+func (bt *BookingTracker) ResumeBooking(ctx context.Context, it *pb.Itinerary, req ItineraryBooking) (int, error) {
+	booked := 0
+	var walk func(g *pb.Graph) error
+	walk = func(g *pb.Graph) error {
+		for _, e := range g.GetEdges() {
+			offer, ok := req.FlightOffers[e.FromId+"->"+e.ToId]
+			if !ok || e.Transport == nil || e.Transport.Status == SegmentCancelled {
+				continue
+			}
+			done, err := bt.flightBooked(ctx, e.Transport.ReferenceNumber)
+			if err != nil {
+				return fmt.Errorf("flight %s->%s: %w", e.FromId, e.ToId, err)
+			}
+			if done {
+				continue
+			}
+			booking, err := bt.BookFlight(ctx, offer, req.Users, nil)
+			if booking != nil {
+				e.Transport.ReferenceNumber = booking.Order.Data.ID
+				e.Transport.BookingId = int64(booking.Order.BookingID)
+				e.Transport.Status = SegmentBooked
+				booked++
+			}
+			if err != nil {
+				return fmt.Errorf("flight %s->%s: %w", e.FromId, e.ToId, err)
+			}
+			log.Infof(ctx, "ResumeBooking: booked flight %s->%s as order %s", e.FromId, e.ToId, e.Transport.ReferenceNumber)
+		}
+		for _, n := range g.GetNodes() {
+			if offerID, ok := req.HotelOffers[n.Id]; ok && n.Stay != nil && n.Stay.Status != SegmentCancelled {
+				done, err := bt.hotelBooked(ctx, n.Stay.BookingReference)
+				if err != nil {
+					return fmt.Errorf("stay %s: %w", n.Id, err)
+				}
+				if !done {
+					if bt.Hotels == nil {
+						return errors.New("no hotel booking provider configured")
+					}
+					order, err := bt.Hotels.BookHotel(ctx, offerID, req.Guests, req.Payment)
+					if err != nil {
+						return fmt.Errorf("stay %s: %w", n.Id, err)
+					}
+					if len(order.Data) == 0 {
+						return fmt.Errorf("stay %s: provider returned no order", n.Id)
+					}
+					n.Stay.BookingReference = order.Data[0].ID
+					n.Stay.Status = SegmentBooked
+					booked++
+					log.Infof(ctx, "ResumeBooking: booked stay %s as order %s", n.Id, n.Stay.BookingReference)
+				}
+			}
+			if err := walk(n.SubGraph); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	err := walk(it.GetGraph())
+	return booked, err
+}
+
+// flightBooked reports whether the flight order ref still stands at the provider
+func (bt *BookingTracker) flightBooked(ctx context.Context, ref string) (bool, error) {
+	if ref == "" {
+		return false, nil
+	}
+	if bt.Orders == nil {
+		return false, errors.New("no flight order provider configured")
+	}
+	_, err := bt.Orders.GetFlightOrder(ctx, ref)
+	if errors.Is(err, amadeus.ErrOrderNotFound) {
+		log.Warnf(ctx, "ResumeBooking: flight order %s is gone, booking again", ref)
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// hotelBooked reports whether the hotel order ref still stands at the provider
+func (bt *BookingTracker) hotelBooked(ctx context.Context, ref string) (bool, error) {
+	if ref == "" {
+		return false, nil
+	}
+	if bt.Hotels == nil {
+		return false, errors.New("no hotel booking provider configured")
+	}
+	order, err := bt.Hotels.GetHotelOrder(ctx, ref)
+	if errors.Is(err, amadeus.ErrOrderNotFound) || (err == nil && order.Cancelled()) {
+		log.Warnf(ctx, "ResumeBooking: hotel order %s is gone, booking again", ref)
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// LockPlan serializes the booking and cancelling of a saved plan within this process.
+// It waits until no other caller holds the plan and returns the function that releases
+// it. Callers load the plan after locking it, so they see the references an earlier
+// caller stored and do not book the same segment again.
+func (bt *BookingTracker) LockPlan(planID int64) (unlock func()) {
+	mu, _ := bt.plans.LoadOrStore(planID, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	return mu.(*sync.Mutex).Unlock
+}
+
+// SaveBookedPlan stores a plan whose segments were just booked or cancelled. If the plan
+// was edited in the meantime, the references and statuses of its segments are copied
+// onto the latest version instead, so that no booked order is lost. A booked segment
+// the latest version no longer has is reported in the error. The stored plan is
+// returned.
+func (bt *BookingTracker) SaveBookedPlan(ctx context.Context, it *pb.Itinerary) (*pb.Itinerary, error) {
+	err := orm.UpdateSavedTrip(bt.DB, it)
+	if !errors.Is(err, orm.ErrVersionConflict) {
+		return it, err
+	}
+
+	latest, err := orm.GetSavedTrip(bt.DB, uint(it.Id))
+	if err != nil {
+		return nil, err
+	}
+	var lost []string
+	copySegments(latest.GetGraph(), it.GetGraph(), &lost)
+	if err := orm.UpdateSavedTrip(bt.DB, latest); err != nil {
+		return nil, err
+	}
+	log.Warnf(ctx, "SaveBookedPlan: plan %d was edited while booking; copied its segment references onto version %d", it.Id, latest.Version)
+	if len(lost) > 0 {
+		return latest, fmt.Errorf("plan %d was edited while booking and no longer has the booked %s", it.Id, strings.Join(lost, ", "))
+	}
+	return latest, nil
+}
+
+// copySegments copies the booking references and statuses of src's transports and
+// stays onto the same segments of dst, sub-graphs included. Booked segments dst does
+// not have are added to lost as "segment (order ref)".
+func copySegments(dst, src *pb.Graph, lost *[]string) {
+	dstEdges := edgesByKey(dst)
+	for key, e := range edgesByKey(src) {
+		if e.Transport.GetReferenceNumber() == "" {
+			continue
+		}
+		d := dstEdges[key]
+		if d.GetTransport() == nil {
+			*lost = append(*lost, fmt.Sprintf("%s (order %s)", key, e.Transport.ReferenceNumber))
+			continue
+		}
+		d.Transport.ReferenceNumber = e.Transport.ReferenceNumber
+		d.Transport.BookingId = e.Transport.BookingId
+		d.Transport.Status = e.Transport.Status
+	}
+
+	dstNodes := map[string]*pb.Node{}
+	for _, n := range dst.GetNodes() {
+		dstNodes[n.Id] = n
+	}
+	for _, n := range src.GetNodes() {
+		d := dstNodes[n.Id]
+		if ref := n.Stay.GetBookingReference(); ref != "" {
+			if d.GetStay() == nil {
+				*lost = append(*lost, fmt.Sprintf("%s (order %s)", n.Id, ref))
+			} else {
+				d.Stay.BookingReference = ref
+				d.Stay.Status = n.Stay.Status
+			}
+		}
+		if n.SubGraph != nil {
+			copySegments(d.GetSubGraph(), n.SubGraph, lost)
+		}
+	}
+}
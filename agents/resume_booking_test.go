@@ -0,0 +1,178 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/orm"
+	"github.com/va6996/travelingman/pb"
+	"github.com/va6996/travelingman/plugins/amadeus"
+)
+
+// fakeHotels knows the hotel orders in orders and adds new ones as "HOTEL1".."n".
+// Cancelling an order in stuck fails.
+type fakeHotels struct {
+	orders    map[string]*amadeus.HotelOrder
+	booked    []string
+	cancelled []string
+	stuck     map[string]bool
+}
+
+func (f *fakeHotels) BookHotel(ctx context.Context, offerID string, guests []amadeus.HotelGuest, payment amadeus.HotelPayment) (*amadeus.HotelOrderResponse, error) {
+	f.booked = append(f.booked, offerID)
+	resp := &amadeus.HotelOrderResponse{}
+	resp.Data = append(resp.Data, struct {
+		Type string `json:"type"`
+		ID   string `json:"id"`
+	}{Type: "hotel-order", ID: fmt.Sprintf("HOTEL%d", len(f.booked))})
+	f.orders[resp.Data[0].ID] = &amadeus.HotelOrder{}
+	return resp, nil
+}
+
+func (f *fakeHotels) GetHotelOrder(ctx context.Context, orderID string) (*amadeus.HotelOrder, error) {
+	order, ok := f.orders[orderID]
+	if !ok {
+		return nil, amadeus.ErrOrderNotFound
+	}
+	return order, nil
+}
+
+func (f *fakeHotels) CancelHotelOrder(ctx context.Context, orderID string) error {
+	if f.stuck[orderID] {
+		return errors.New("hotel order cancellation failed: 500 Internal Server Error")
+	}
+	f.cancelled = append(f.cancelled, orderID)
+	delete(f.orders, orderID)
+	return nil
+}
+
+func TestBookingTracker_ResumeBooking(t *testing.T) {
+	// The flight to London was booked before the crash; the one to Paris was not
+	placed := &amadeus.FlightOrderResponse{}
+	placed.Data.ID = "PLACED"
+	orders := map[string]*amadeus.FlightOrderResponse{"PLACED": placed}
+	bt, _ := setupBookingTracker(t, orders)
+	booker := &fakeBooker{db: bt.DB}
+	bt.Booker = booker
+	hotels := &fakeHotels{orders: map[string]*amadeus.HotelOrder{"HOTEL-LON": {}}}
+	bt.Hotels = hotels
+
+	flight := func(ref string) *pb.Transport {
+		return &pb.Transport{Type: pb.TransportType_TRANSPORT_TYPE_FLIGHT, ReferenceNumber: ref}
+	}
+	it := &pb.Itinerary{Graph: &pb.Graph{
+		Nodes: []*pb.Node{
+			{Id: "nyc"},
+			{Id: "lon", Stay: &pb.Accommodation{BookingReference: "HOTEL-LON", Status: SegmentBooked}},
+			{Id: "par", Stay: &pb.Accommodation{}},
+		},
+		Edges: []*pb.Edge{
+			{FromId: "nyc", ToId: "lon", Transport: flight("PLACED")},
+			{FromId: "lon", ToId: "par", Transport: flight("")},
+		},
+	}}
+	req := ItineraryBooking{
+		Users:        []*pb.User{{Id: 1, FullName: "Ada Lovelace"}},
+		FlightOffers: map[string]amadeus.FlightOffer{"nyc->lon": bookedOffer("2026-06-01T09:30:00", "2026-06-01T21:30:00"), "lon->par": {ID: "2"}},
+		HotelOffers:  map[string]string{"lon": "OFFER-LON", "par": "OFFER-PAR"},
+	}
+
+	n, err := bt.ResumeBooking(context.Background(), it, req)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, 1, booker.orders, "only the unbooked flight is submitted")
+	assert.Equal(t, []string{"OFFER-PAR"}, hotels.booked, "only the unbooked stay is submitted")
+	assert.Equal(t, "PLACED", it.Graph.Edges[0].Transport.ReferenceNumber)
+	assert.Equal(t, "ORDER1", it.Graph.Edges[1].Transport.ReferenceNumber)
+	assert.Equal(t, SegmentBooked, it.Graph.Edges[1].Transport.Status)
+	assert.NotZero(t, it.Graph.Edges[1].Transport.BookingId)
+	assert.Equal(t, "HOTEL1", it.Graph.Nodes[2].Stay.BookingReference)
+
+	// Resuming a fully booked itinerary books nothing
+	orders["ORDER1"] = &amadeus.FlightOrderResponse{}
+	n, err = bt.ResumeBooking(context.Background(), it, req)
+	assert.NoError(t, err)
+	assert.Zero(t, n)
+	assert.Equal(t, 1, booker.orders)
+
+	// An order the provider lost is booked again
+	it.Graph.Edges[0].Transport.ReferenceNumber = "LOST"
+	n, err = bt.ResumeBooking(context.Background(), it, req)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, "ORDER2", it.Graph.Edges[0].Transport.ReferenceNumber)
+
+	// Segments the user cancelled are not booked again, though their orders are gone
+	orders["ORDER2"] = &amadeus.FlightOrderResponse{}
+	delete(orders, "ORDER1")
+	delete(hotels.orders, "HOTEL1")
+	it.Graph.Edges[1].Transport.Status = SegmentCancelled
+	it.Graph.Nodes[2].Stay.Status = SegmentCancelled
+	n, err = bt.ResumeBooking(context.Background(), it, req)
+	assert.NoError(t, err)
+	assert.Zero(t, n)
+	assert.Equal(t, 2, booker.orders)
+	assert.Equal(t, []string{"OFFER-PAR"}, hotels.booked)
+}
+
+func TestBookingTracker_SaveBookedPlan(t *testing.T) {
+	bt, _ := setupBookingTracker(t, nil)
+	assert.NoError(t, bt.DB.AutoMigrate(&orm.SavedTrip{}))
+
+	saved := &pb.Itinerary{Graph: &pb.Graph{
+		Nodes: []*pb.Node{{Id: "nyc"}, {Id: "lon", Stay: &pb.Accommodation{}}, {Id: "par", Stay: &pb.Accommodation{}}},
+		Edges: []*pb.Edge{
+			{FromId: "nyc", ToId: "lon", Transport: &pb.Transport{}},
+			{FromId: "lon", ToId: "par", Transport: &pb.Transport{}},
+		},
+	}}
+	assert.NoError(t, orm.CreateSavedTrip(bt.DB, saved))
+
+	// Booking works on the loaded copy while the user drops Paris from the plan
+	booking, err := orm.GetSavedTrip(bt.DB, uint(saved.Id))
+	assert.NoError(t, err)
+	booking.Graph.Edges[0].Transport.ReferenceNumber = "ORDER1"
+	booking.Graph.Edges[0].Transport.Status = SegmentBooked
+	booking.Graph.Edges[1].Transport.ReferenceNumber = "ORDER2"
+	booking.Graph.Nodes[1].Stay.BookingReference = "HOTEL1"
+	booking.Graph.Nodes[1].Stay.Status = SegmentBooked
+
+	saved.Graph.Nodes = saved.Graph.Nodes[:2]
+	saved.Graph.Edges = saved.Graph.Edges[:1]
+	assert.NoError(t, orm.UpdateSavedTrip(bt.DB, saved))
+
+	it, err := bt.SaveBookedPlan(context.Background(), booking)
+	assert.ErrorContains(t, err, "lon->par (order ORDER2)", "the booked order the plan no longer has is reported")
+	assert.Equal(t, int64(3), it.Version)
+	stored, err := orm.GetSavedTrip(bt.DB, uint(saved.Id))
+	assert.NoError(t, err)
+	assert.Len(t, stored.Graph.Edges, 1, "the edit is kept")
+	assert.Equal(t, "ORDER1", stored.Graph.Edges[0].Transport.ReferenceNumber)
+	assert.Equal(t, SegmentBooked, stored.Graph.Edges[0].Transport.Status)
+	assert.Equal(t, "HOTEL1", stored.Graph.Nodes[1].Stay.BookingReference)
+}
+
+func TestBookingTracker_LockPlan(t *testing.T) {
+	bt := &BookingTracker{}
+	unlock := bt.LockPlan(1)
+
+	// Another plan is not held up
+	bt.LockPlan(2)()
+
+	locked := make(chan struct{})
+	go func() {
+		bt.LockPlan(1)()
+		close(locked)
+	}()
+	select {
+	case <-locked:
+		t.Fatal("plan 1 was locked twice")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock()
+	<-locked
+}
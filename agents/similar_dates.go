@@ -0,0 +1,90 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	tmcontext "github.com/va6996/travelingman/context"
+	"github.com/va6996/travelingman/log"
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// similarDateOffsets are the days around a requested date that are searched when it
+// has no flights, nearest first
+var similarDateOffsets = []int{1, -1, 2, -2}
+
+// similarDates searches the days around a flight's date that have not passed with
+// provider and describes those with options, e.g. "2026-06-06 has options from 420.00
+// USD". It returns "" if there are none or the leg is not a flight with a date. The
+// days are searched at once, and no more of them than the request's call budget has
+// calls left, nearest first.
+func similarDates(ctx context.Context, t *pb.Transport, provider TransportSearcher) string {
+	f := t.GetFlight()
+	if t.GetType() != pb.TransportType_TRANSPORT_TYPE_FLIGHT || f.GetDepartureTime() == nil {
+		return ""
+	}
+	today := civilDate(tmcontext.Now(ctx))
+
+	var offsets []int
+	for _, days := range similarDateOffsets {
+		if !civilDate(f.DepartureTime.AsTime().AddDate(0, 0, days)).Before(today) {
+			offsets = append(offsets, days)
+		}
+	}
+	if left := tmcontext.CallBudgetFromContext(ctx).Remaining(); left >= 0 && left < len(offsets) {
+		offsets = offsets[:left]
+	}
+
+	found := make([]string, len(offsets))
+	var wg sync.WaitGroup
+	for i, days := range offsets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			shifted := proto.Clone(t).(*pb.Transport)
+			sf := shifted.GetFlight()
+			dep := sf.DepartureTime.AsTime().AddDate(0, 0, days)
+			sf.DepartureTime = timestamppb.New(dep)
+			if sf.ArrivalTime != nil {
+				sf.ArrivalTime = timestamppb.New(sf.ArrivalTime.AsTime().AddDate(0, 0, days))
+			}
+
+			options, err := provider.SearchTransport(ctx, shifted)
+			if err != nil {
+				log.Debugf(ctx, "TravelDesk: Search for flights on %s failed: %v", dep.Format("2006-01-02"), err)
+				return
+			}
+			if len(options) == 0 {
+				return
+			}
+			suggestion := dep.Format("2006-01-02") + " has options"
+			if c := cheapestCost(options); c != nil {
+				suggestion += fmt.Sprintf(" from %.2f %s", c.Value, c.Currency)
+			}
+			found[i] = suggestion
+		}()
+	}
+	wg.Wait()
+
+	found = slices.DeleteFunc(found, func(s string) bool { return s == "" })
+	return strings.Join(found, " and ")
+}
+
+// cheapestCost returns the lowest cost of options in the currency of the first priced
+// one, or nil if none is priced
+func cheapestCost(options []*pb.Transport) *pb.Cost {
+	var cheapest *pb.Cost
+	for _, opt := range options {
+		c := opt.GetCost()
+		if c.GetValue() <= 0 || (cheapest != nil && (c.Currency != cheapest.Currency || c.Value >= cheapest.Value)) {
+			continue
+		}
+		cheapest = c
+	}
+	return cheapest
+}
@@ -0,0 +1,97 @@
+package agents
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	tmcontext "github.com/va6996/travelingman/context"
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// datedFlights offers flights at the given prices on some days only
+type datedFlights struct {
+	prices map[string][]float64
+
+	mu    sync.Mutex
+	asked []string
+}
+
+func (f *datedFlights) SearchTransport(ctx context.Context, t *pb.Transport) ([]*pb.Transport, error) {
+	if !tmcontext.TakeCall(ctx) {
+		return nil, tmcontext.ErrCallBudgetExhausted
+	}
+	day := t.GetFlight().GetDepartureTime().AsTime().Format("2006-01-02")
+	f.mu.Lock()
+	f.asked = append(f.asked, day)
+	f.mu.Unlock()
+	var options []*pb.Transport
+	for _, p := range f.prices[day] {
+		options = append(options, &pb.Transport{Type: t.Type, Cost: &pb.Cost{Value: p, Currency: "USD"}})
+	}
+	return options, nil
+}
+
+func TestTravelDesk_SuggestsSimilarDates(t *testing.T) {
+	ctx := tmcontext.WithClock(context.Background(), tmcontext.FixedClock(time.Date(2026, 6, 4, 12, 0, 0, 0, time.UTC)))
+	flight := func() *pb.Transport {
+		return &pb.Transport{
+			Type:           pb.TransportType_TRANSPORT_TYPE_FLIGHT,
+			OriginLocation: &pb.Location{IataCodes: []string{"JFK"}},
+			Details: &pb.Transport_Flight{Flight: &pb.Flight{
+				DepartureTime: timestamppb.New(time.Date(2026, 6, 5, 9, 0, 0, 0, time.UTC)),
+				ArrivalTime:   timestamppb.New(time.Date(2026, 6, 6, 13, 0, 0, 0, time.UTC)),
+			}},
+		}
+	}
+	check := func(provider TransportSearcher, budget *tmcontext.CallBudget) *pb.Edge {
+		edge := &pb.Edge{FromId: "nyc", ToId: "tyo", Transport: flight()}
+		NewTravelDesk(nil).checkTransport(tmcontext.WithCallBudget(ctx, budget), edge, provider)
+		return edge
+	}
+
+	t.Run("NearbyDates", func(t *testing.T) {
+		provider := &datedFlights{prices: map[string][]float64{
+			"2026-06-06": {520, 480},
+			"2026-06-07": {450},
+		}}
+		edge := check(provider, nil)
+		assert.Empty(t, edge.TransportOptions)
+		if assert.NotNil(t, edge.Transport.Error) {
+			assert.Equal(t, "No flights found for [JFK] on 2026-06-05, but 2026-06-06 has options from 480.00 USD and 2026-06-07 has options from 450.00 USD", edge.Transport.Error.Message)
+			assert.Equal(t, pb.ErrorCode_ERROR_CODE_NO_AVAILABILITY, edge.Transport.Error.Code)
+			assert.Equal(t, pb.ErrorSeverity_ERROR_SEVERITY_ERROR, edge.Transport.Error.Severity)
+		}
+		// Days that have passed are not searched
+		assert.ElementsMatch(t, []string{"2026-06-05", "2026-06-06", "2026-06-04", "2026-06-07"}, provider.asked)
+	})
+
+	t.Run("CallBudget", func(t *testing.T) {
+		provider := &datedFlights{prices: map[string][]float64{"2026-06-07": {450}}}
+		edge := check(provider, tmcontext.NewCallBudget(3))
+		if assert.NotNil(t, edge.Transport.Error) {
+			assert.Equal(t, "No flights found for [JFK] on 2026-06-05", edge.Transport.Error.Message)
+		}
+		// Only the nearest days the budget has calls left for are searched
+		assert.ElementsMatch(t, []string{"2026-06-05", "2026-06-06", "2026-06-04"}, provider.asked)
+	})
+
+	t.Run("NoNearbyDates", func(t *testing.T) {
+		edge := check(&datedFlights{}, nil)
+		if assert.NotNil(t, edge.Transport.Error) {
+			assert.Equal(t, "No flights found for [JFK] on 2026-06-05", edge.Transport.Error.Message)
+			assert.Equal(t, pb.ErrorCode_ERROR_CODE_DATA_NOT_FOUND, edge.Transport.Error.Code)
+		}
+	})
+
+	t.Run("RequestedDateHasFlights", func(t *testing.T) {
+		provider := &datedFlights{prices: map[string][]float64{"2026-06-05": {610}}}
+		edge := check(provider, nil)
+		assert.Nil(t, edge.Transport.Error)
+		assert.Len(t, edge.TransportOptions, 1)
+		assert.Equal(t, []string{"2026-06-05"}, provider.asked, "nearby dates are only searched when needed")
+	})
+}
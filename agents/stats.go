@@ -0,0 +1,263 @@
+package agents
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tmcore "github.com/va6996/travelingman/core"
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// transferThreshold is the longest stop at a node without a stay that still counts
+// as a transfer between legs (transit) rather than time spent there.
+const transferThreshold = 6 * time.Hour
+
+// placeTime is a destination's time plus when the visit starts, for ordering
+type placeTime struct {
+	dest  *pb.DestinationTime
+	start time.Time
+}
+
+// graphTotals accumulates stats over a graph and its sub-graphs
+type graphTotals struct {
+	transit time.Duration
+	hops    int32
+	partial bool
+	places  []placeTime
+}
+
+func (t *graphTotals) merge(o graphTotals) {
+	t.transit += o.transit
+	t.hops += o.hops
+	t.partial = t.partial || o.partial
+	t.places = append(t.places, o.places...)
+}
+
+// computeItineraryStats works out how much of the trip is spent in transit and how
+// much at each destination. Sub-graphs (e.g. day trips) are folded into the totals,
+// with their time taken out of the parent node's stay.
+func computeItineraryStats(it *pb.Itinerary) *pb.ItineraryStats {
+	stats := &pb.ItineraryStats{}
+	if it.Graph == nil {
+		return stats
+	}
+
+	totals := graphStats(it.Graph)
+	sort.SliceStable(totals.places, func(i, j int) bool {
+		return totals.places[i].start.Before(totals.places[j].start)
+	})
+
+	stats.TransitSeconds = int64(totals.transit.Seconds())
+	stats.HopCount = totals.hops
+	stats.Partial = totals.partial
+	for _, p := range totals.places {
+		stats.Destinations = append(stats.Destinations, p.dest)
+		stats.DestinationSeconds += p.dest.Seconds
+	}
+	if total := stats.TransitSeconds + stats.DestinationSeconds; total > 0 {
+		stats.TransitRatio = float64(stats.TransitSeconds) / float64(total)
+	}
+	return stats
+}
+
+func graphStats(g *pb.Graph) graphTotals {
+	var totals graphTotals
+
+	// 1. Transport legs
+	for _, e := range g.Edges {
+		if e.Transport == nil {
+			continue
+		}
+		totals.hops++
+		dep, arr, ok := legTimes(e)
+		if !ok {
+			totals.partial = true
+			continue
+		}
+		totals.transit += arr.Sub(dep)
+	}
+
+	// 2. Time at each node
+	for _, n := range g.Nodes {
+		var sub graphTotals
+		if n.SubGraph != nil {
+			sub = graphStats(n.SubGraph)
+			totals.merge(sub)
+		}
+
+		start, end, ok := nodeWindow(g, n)
+		if !ok {
+			continue
+		}
+		stay := end.Sub(start)
+
+		// A short, unplanned stop between two legs (no hotel, no times of its own) is a connection
+		isConnection := n.Stay == nil && n.FromTimestamp == nil && n.ToTimestamp == nil &&
+			len(tmcore.GetEdgesToNode(g, n.Id)) > 0 && len(tmcore.GetEdgesFromNode(g, n.Id)) > 0
+		if isConnection && stay <= transferThreshold && n.SubGraph == nil {
+			totals.transit += stay
+			continue
+		}
+
+		// Day trips happen inside this node's window
+		stay -= sub.transit
+		for _, p := range sub.places {
+			stay -= time.Duration(p.dest.Seconds) * time.Second
+		}
+		if stay < 0 {
+			stay = 0
+		}
+
+		totals.places = append(totals.places, placeTime{
+			dest: &pb.DestinationTime{
+				NodeId:  n.Id,
+				Place:   nodePlace(n),
+				Seconds: int64(stay.Seconds()),
+			},
+			start: start,
+		})
+	}
+
+	// 3. Intra-city details
+	if g.SubGraph != nil {
+		totals.merge(graphStats(g.SubGraph))
+	}
+
+	return totals
+}
+
+// legTimes returns the departure and arrival of an edge's transport.
+// Flights fall back to their segments, and a missing arrival is derived from the
+// edge duration. ok is false if either time is still unknown.
+func legTimes(e *pb.Edge) (dep, arr time.Time, ok bool) {
+	t := e.Transport
+	var depTS, arrTS *timestamppb.Timestamp
+	switch {
+	case t.GetFlight() != nil:
+		f := t.GetFlight()
+		depTS, arrTS = f.DepartureTime, f.ArrivalTime
+		if n := len(f.Segments); n > 0 {
+			if depTS == nil {
+				depTS = f.Segments[0].DepartureTime
+			}
+			if arrTS == nil {
+				arrTS = f.Segments[n-1].ArrivalTime
+			}
+		}
+	case t.GetTrain() != nil:
+		depTS, arrTS = t.GetTrain().DepartureTime, t.GetTrain().ArrivalTime
+	case t.GetCarRental() != nil:
+		depTS, arrTS = t.GetCarRental().PickupTime, t.GetCarRental().DropoffTime
+	}
+
+	if depTS == nil {
+		return dep, arr, false
+	}
+	dep = depTS.AsTime()
+	switch {
+	case arrTS != nil:
+		arr = arrTS.AsTime()
+	case e.DurationSeconds > 0:
+		arr = dep.Add(time.Duration(e.DurationSeconds) * time.Second)
+	default:
+		return dep, arr, false
+	}
+	return dep, arr, arr.After(dep)
+}
+
+// nodeWindow returns when the traveller is at a node. Explicit node times or the stay's
+// check-in/out are used when set and clipped so they do not overlap adjacent legs;
+// otherwise the window runs from the earliest arrival to the next departure.
+// Trip start/end nodes (no arrival, or no departure after it) have no window.
+func nodeWindow(g *pb.Graph, n *pb.Node) (start, end time.Time, ok bool) {
+	switch {
+	case n.FromTimestamp != nil:
+		start = n.FromTimestamp.AsTime()
+	case n.Stay.GetCheckIn() != nil:
+		start = n.Stay.CheckIn.AsTime()
+	}
+	switch {
+	case n.ToTimestamp != nil:
+		end = n.ToTimestamp.AsTime()
+	case n.Stay.GetCheckOut() != nil:
+		end = n.Stay.CheckOut.AsTime()
+	}
+
+	if start.IsZero() {
+		for _, e := range tmcore.GetEdgesToNode(g, n.Id) {
+			if _, arr, known := legTimes(e); known && (start.IsZero() || arr.Before(start)) {
+				start = arr
+			}
+		}
+		if start.IsZero() {
+			return start, end, false
+		}
+	}
+	if end.IsZero() {
+		for _, e := range tmcore.GetEdgesFromNode(g, n.Id) {
+			if dep, _, known := legTimes(e); known && dep.After(start) && (end.IsZero() || dep.Before(end)) {
+				end = dep
+			}
+		}
+		if end.IsZero() {
+			return start, end, false
+		}
+	}
+
+	// Clip to adjacent legs
+	for _, e := range tmcore.GetEdgesToNode(g, n.Id) {
+		if _, arr, known := legTimes(e); known && arr.After(start) && arr.Before(end) {
+			start = arr
+		}
+	}
+	for _, e := range tmcore.GetEdgesFromNode(g, n.Id) {
+		if dep, _, known := legTimes(e); known && dep.After(start) && dep.Before(end) {
+			end = dep
+		}
+	}
+
+	return start, end, end.After(start)
+}
+
+// formatStats renders stats as e.g. "You'll spend 9h traveling and 52h in Paris"
+func formatStats(s *pb.ItineraryStats) string {
+	if s == nil || (s.TransitSeconds == 0 && len(s.Destinations) == 0) {
+		return ""
+	}
+
+	parts := []string{fmt.Sprintf("%s traveling", formatSeconds(s.TransitSeconds))}
+
+	// Combine repeat visits to the same place
+	var order []string
+	byPlace := map[string]int64{}
+	for _, d := range s.Destinations {
+		if _, seen := byPlace[d.Place]; !seen {
+			order = append(order, d.Place)
+		}
+		byPlace[d.Place] += d.Seconds
+	}
+	for _, place := range order {
+		parts = append(parts, fmt.Sprintf("%s in %s", formatSeconds(byPlace[place]), place))
+	}
+
+	text := parts[0]
+	if len(parts) > 1 {
+		text = strings.Join(parts[:len(parts)-1], ", ") + " and " + parts[len(parts)-1]
+	}
+	text = "You'll spend " + text
+	if s.Partial {
+		text += " (estimate: some times are missing)"
+	}
+	return text
+}
+
+func formatSeconds(sec int64) string {
+	h, m := sec/3600, (sec%3600)/60
+	if m == 0 {
+		return fmt.Sprintf("%dh", h)
+	}
+	return fmt.Sprintf("%dh %dm", h, m)
+}
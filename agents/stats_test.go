@@ -0,0 +1,153 @@
+package agents
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func statsTime(day, hour int) *timestamppb.Timestamp {
+	return timestamppb.New(time.Date(2030, time.May, day, hour, 0, 0, 0, time.UTC))
+}
+
+func flightEdge(from, to string, f *pb.Flight) *pb.Edge {
+	return &pb.Edge{FromId: from, ToId: to, Transport: &pb.Transport{
+		Type:    pb.TransportType_TRANSPORT_TYPE_FLIGHT,
+		Details: &pb.Transport_Flight{Flight: f},
+	}}
+}
+
+func trainEdge(from, to string, dep, arr *timestamppb.Timestamp) *pb.Edge {
+	return &pb.Edge{FromId: from, ToId: to, Transport: &pb.Transport{
+		Type:    pb.TransportType_TRANSPORT_TYPE_TRAIN,
+		Details: &pb.Transport_Train{Train: &pb.Train{DepartureTime: dep, ArrivalTime: arr}},
+	}}
+}
+
+func TestComputeItineraryStats_Layovers(t *testing.T) {
+	// LHR -> FRA -> CDG with a 2h layover; only the segments carry the arrival time
+	outbound := &pb.Flight{
+		DepartureTime: statsTime(10, 8),
+		Segments: []*pb.FlightSegment{
+			{DepartureTime: statsTime(10, 8), ArrivalTime: statsTime(10, 10)},
+			{DepartureTime: statsTime(10, 12), ArrivalTime: statsTime(10, 14)},
+		},
+	}
+	inbound := &pb.Flight{DepartureTime: statsTime(13, 12), ArrivalTime: statsTime(13, 13)}
+
+	it := &pb.Itinerary{Graph: &pb.Graph{
+		Nodes: []*pb.Node{
+			{Id: "london", Location: &pb.Location{City: "London"}},
+			{Id: "paris", Location: &pb.Location{City: "Paris"}, Stay: &pb.Accommodation{
+				CheckIn: statsTime(10, 15), CheckOut: statsTime(13, 10),
+			}},
+		},
+		Edges: []*pb.Edge{
+			flightEdge("london", "paris", outbound),
+			flightEdge("paris", "london", inbound),
+		},
+	}}
+
+	stats := computeItineraryStats(it)
+
+	assert.Equal(t, int64(7*3600), stats.TransitSeconds, "6h outbound incl. layover + 1h inbound")
+	assert.Equal(t, int64(67*3600), stats.DestinationSeconds)
+	assert.Equal(t, int32(2), stats.HopCount)
+	assert.InDelta(t, 7.0/74.0, stats.TransitRatio, 1e-9)
+	assert.False(t, stats.Partial)
+	if assert.Len(t, stats.Destinations, 1, "home is not a destination") {
+		assert.Equal(t, "Paris", stats.Destinations[0].Place)
+	}
+	assert.Equal(t, "You'll spend 7h traveling and 67h in Paris", formatStats(stats))
+}
+
+func TestComputeItineraryStats_Transfer(t *testing.T) {
+	// A 2h connection in Frankfurt without a hotel counts as transit
+	it := &pb.Itinerary{Graph: &pb.Graph{
+		Nodes: []*pb.Node{
+			{Id: "london", Location: &pb.Location{City: "London"}},
+			{Id: "frankfurt", Location: &pb.Location{City: "Frankfurt"}},
+			{Id: "rome", Location: &pb.Location{City: "Rome"}, FromTimestamp: statsTime(10, 18), ToTimestamp: statsTime(11, 18)},
+		},
+		Edges: []*pb.Edge{
+			flightEdge("london", "frankfurt", &pb.Flight{DepartureTime: statsTime(10, 8), ArrivalTime: statsTime(10, 10)}),
+			trainEdge("frankfurt", "rome", statsTime(10, 12), statsTime(10, 18)),
+		},
+	}}
+
+	stats := computeItineraryStats(it)
+
+	assert.Equal(t, int64(10*3600), stats.TransitSeconds)
+	assert.Equal(t, int64(24*3600), stats.DestinationSeconds)
+	assert.Len(t, stats.Destinations, 1)
+	assert.Equal(t, "You'll spend 10h traveling and 24h in Rome", formatStats(stats))
+}
+
+func TestComputeItineraryStats_SubGraphDayTrip(t *testing.T) {
+	dayTrip := &pb.Graph{
+		Nodes: []*pb.Node{
+			{Id: "hotel", Location: &pb.Location{City: "Paris"}},
+			{Id: "versailles", Location: &pb.Location{City: "Versailles"}, FromTimestamp: statsTime(11, 10), ToTimestamp: statsTime(11, 16)},
+		},
+		Edges: []*pb.Edge{
+			trainEdge("hotel", "versailles", statsTime(11, 9), statsTime(11, 10)),
+			trainEdge("versailles", "hotel", statsTime(11, 16), statsTime(11, 17)),
+		},
+	}
+	it := &pb.Itinerary{Graph: &pb.Graph{
+		Nodes: []*pb.Node{
+			{Id: "london", Location: &pb.Location{City: "London"}},
+			{Id: "paris", Location: &pb.Location{City: "Paris"}, FromTimestamp: statsTime(10, 12), ToTimestamp: statsTime(12, 12), SubGraph: dayTrip},
+		},
+		Edges: []*pb.Edge{
+			flightEdge("london", "paris", &pb.Flight{DepartureTime: statsTime(10, 9), ArrivalTime: statsTime(10, 11)}),
+		},
+	}}
+
+	stats := computeItineraryStats(it)
+
+	// 2h flight + 2h of day-trip trains
+	assert.Equal(t, int64(4*3600), stats.TransitSeconds)
+	assert.Equal(t, int32(3), stats.HopCount)
+	// Paris 48h window minus the day trip (2h trains + 6h in Versailles)
+	if assert.Len(t, stats.Destinations, 2) {
+		assert.Equal(t, "paris", stats.Destinations[0].NodeId)
+		assert.Equal(t, int64(40*3600), stats.Destinations[0].Seconds)
+		assert.Equal(t, "versailles", stats.Destinations[1].NodeId)
+		assert.Equal(t, int64(6*3600), stats.Destinations[1].Seconds)
+	}
+	assert.Equal(t, int64(46*3600), stats.DestinationSeconds)
+	assert.InDelta(t, 4.0/50.0, stats.TransitRatio, 1e-9)
+	assert.Equal(t, "You'll spend 4h traveling, 40h in Paris and 6h in Versailles", formatStats(stats))
+}
+
+func TestComputeItineraryStats_MissingArrival(t *testing.T) {
+	train := trainEdge("london", "paris", statsTime(10, 8), nil)
+	// No arrival time, but the edge duration is known
+	flight := flightEdge("paris", "rome", &pb.Flight{DepartureTime: statsTime(10, 14)})
+	flight.DurationSeconds = 2 * 3600
+
+	it := &pb.Itinerary{Graph: &pb.Graph{
+		Nodes: []*pb.Node{
+			{Id: "london", Location: &pb.Location{City: "London"}},
+			{Id: "paris", Location: &pb.Location{City: "Paris"}},
+			{Id: "rome", Location: &pb.Location{City: "Rome"}, Stay: &pb.Accommodation{
+				CheckIn: statsTime(10, 17), CheckOut: statsTime(12, 10),
+			}},
+		},
+		Edges: []*pb.Edge{train, flight},
+	}}
+
+	stats := computeItineraryStats(it)
+
+	assert.True(t, stats.Partial)
+	assert.Equal(t, int32(2), stats.HopCount)
+	assert.Equal(t, int64(2*3600), stats.TransitSeconds, "the train without an arrival is left out")
+	if assert.Len(t, stats.Destinations, 1, "Paris has no known arrival") {
+		assert.Equal(t, int64(41*3600), stats.Destinations[0].Seconds)
+	}
+	assert.Contains(t, formatStats(stats), "(estimate: some times are missing)")
+}
@@ -0,0 +1,45 @@
+package agents
+
+import (
+	"context"
+	"strings"
+
+	"github.com/va6996/travelingman/pb"
+)
+
+// TransportProviders routes the availability search of each transport type to the
+// provider serving it. Types without a provider are left unchecked.
+type TransportProviders map[pb.TransportType]TransportSearcher
+
+// Register makes s the provider searched for transports of type tt, replacing any
+// provider registered before
+func (p TransportProviders) Register(tt pb.TransportType, s TransportSearcher) {
+	p[tt] = s
+}
+
+// errorCode maps a provider's search error to an error code, if the provider can
+func errorCode(s TransportSearcher, err error) pb.ErrorCode {
+	if m, ok := s.(interface{ MapError(error) pb.ErrorCode }); ok {
+		return m.MapError(err)
+	}
+	return pb.ErrorCode_ERROR_CODE_UNSPECIFIED
+}
+
+// amadeusFlights is the default flight provider: Amadeus, searched within the budget cap
+type amadeusFlights struct {
+	td *TravelDesk
+}
+
+func (f amadeusFlights) SearchTransport(ctx context.Context, t *pb.Transport) ([]*pb.Transport, error) {
+	return f.td.searchFlights(ctx, t)
+}
+
+func (f amadeusFlights) MapError(err error) pb.ErrorCode {
+	return f.td.amadeus.MapError(err)
+}
+
+// transportLabel names a transport type for messages, e.g. "Train"
+func transportLabel(tt pb.TransportType) string {
+	name := strings.ToLower(strings.TrimPrefix(tt.String(), "TRANSPORT_TYPE_"))
+	return strings.ToUpper(name[:1]) + name[1:]
+}
@@ -0,0 +1,73 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// fakeTransports records the legs it is asked about and offers one option for each,
+// or fails with err
+type fakeTransports struct {
+	name  string
+	asked []*pb.Transport
+	err   error
+}
+
+func (f *fakeTransports) SearchTransport(ctx context.Context, t *pb.Transport) ([]*pb.Transport, error) {
+	f.asked = append(f.asked, t)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []*pb.Transport{{Type: t.Type, Plugin: f.name}}, nil
+}
+
+func TestTravelDesk_TransportProviders(t *testing.T) {
+	day := timestamppb.New(time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC))
+	flight := &pb.Transport{Type: pb.TransportType_TRANSPORT_TYPE_FLIGHT, Details: &pb.Transport_Flight{Flight: &pb.Flight{DepartureTime: day}}}
+	train := &pb.Transport{Type: pb.TransportType_TRANSPORT_TYPE_TRAIN, Details: &pb.Transport_Train{Train: &pb.Train{DepartureTime: day}}}
+	walk := &pb.Transport{Type: pb.TransportType_TRANSPORT_TYPE_WALKING}
+	it := &pb.Itinerary{Graph: &pb.Graph{
+		Nodes: []*pb.Node{{Id: "lon"}, {Id: "par"}, {Id: "bru"}, {Id: "ams"}},
+		Edges: []*pb.Edge{
+			{FromId: "lon", ToId: "par", Transport: flight},
+			{FromId: "par", ToId: "bru", Transport: train},
+			{FromId: "bru", ToId: "ams", Transport: walk},
+		},
+	}}
+
+	// Nothing is looked up with Amadeus, so the desk needs no client
+	desk := NewTravelDesk(nil)
+	flights := &fakeTransports{name: "skies"}
+	rail := &fakeTransports{name: "rail"}
+	desk.Transports.Register(pb.TransportType_TRANSPORT_TYPE_FLIGHT, flights)
+	desk.Transports.Register(pb.TransportType_TRANSPORT_TYPE_TRAIN, rail)
+	desk.checkRecursive(context.Background(), it)
+
+	assert.Equal(t, []*pb.Transport{flight}, flights.asked)
+	assert.Equal(t, []*pb.Transport{train}, rail.asked)
+	edges := it.Graph.Edges
+	if assert.Len(t, edges[0].TransportOptions, 1) {
+		assert.Equal(t, "skies", edges[0].TransportOptions[0].Plugin)
+	}
+	if assert.Len(t, edges[1].TransportOptions, 1) {
+		assert.Equal(t, "rail", edges[1].TransportOptions[0].Plugin)
+	}
+	assert.Empty(t, edges[2].TransportOptions, "types without a provider are left unchecked")
+	assert.Nil(t, walk.Error)
+
+	// A provider's failure is reported on its leg only
+	rail.err = errors.New("rail API unavailable")
+	train.Error, edges[1].TransportOptions = nil, nil
+	desk.checkRecursive(context.Background(), it)
+	if assert.NotNil(t, train.Error) {
+		assert.Equal(t, "Train search failed: rail API unavailable", train.Error.Message)
+		assert.Equal(t, pb.ErrorCode_ERROR_CODE_UNSPECIFIED, train.Error.Code)
+	}
+	assert.Nil(t, flight.Error)
+}
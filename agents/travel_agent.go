@@ -2,31 +2,93 @@ package agents
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/va6996/travelingman/agents/options"
+	tmcontext "github.com/va6996/travelingman/context"
+	tmcore "github.com/va6996/travelingman/core"
 	"github.com/va6996/travelingman/log"
 	"github.com/va6996/travelingman/pb"
+	"github.com/va6996/travelingman/plugins/amadeus"
+	"github.com/va6996/travelingman/plugins/core"
 )
 
 // TravelAgent is the main orchestrator
 type TravelAgent struct {
 	planner Planner
 	desk    Assistant
+
+	// targetOptions is the number of fully-valid itineraries after which the
+	// remaining in-flight verifications are cancelled. Zero verifies every plan.
+	targetOptions atomic.Int64
+
+	// ConnectionTimes is the per-airport minimum connection time table used to
+	// judge layovers. Nil uses the built-in table.
+	ConnectionTimes *core.ConnectionTimes
+	// SelfTransferBuffer is added to the minimum when connecting flights are booked separately
+	SelfTransferBuffer time.Duration
+
+	// BreakfastValue is what breakfast is estimated to cost per traveller per night. It is
+	// added to stays without breakfast when the user wants it, so stays that include it
+	// compete fairly. Zero compares stays on price alone.
+	BreakfastValue float64
+
+	// ReplanPolicy decides whether warnings, not just errors, send plans back to the
+	// planner. Empty re-plans on errors only.
+	ReplanPolicy ReplanPolicy
+
+	// QuickTimeout caps a quick plan; zero uses the planner's own timeout
+	QuickTimeout time.Duration
+
+	// Clock decides what "today" is for planning and validation. Nil uses the wall
+	// clock, or a clock already attached to the request context.
+	Clock tmcontext.Clock
+
+	// Phases is told how long planning, verification and scoring took; nil skips it
+	Phases PhaseObserver
+
+	// Places locates the airports and stations stays are ranked by when the user wants
+	// to stay near transit. Nil places them by their geocode only.
+	Places *core.PlaceIndex
 }
 
 // NewTravelAgent creates a new TravelAgent
 func NewTravelAgent(p Planner, d Assistant) *TravelAgent {
 	return &TravelAgent{
-		planner: p,
-		desk:    d,
+		planner:            p,
+		desk:               d,
+		SelfTransferBuffer: DefaultSelfTransferBuffer,
+		BreakfastValue:     DefaultBreakfastValue,
+		Places:             core.DefaultPlaceIndex(),
+	}
+}
+
+// TargetOptions returns how many valid itineraries end verification early (0 verifies all)
+func (ta *TravelAgent) TargetOptions() int {
+	return int(ta.targetOptions.Load())
+}
+
+// SetTargetOptions changes how many valid itineraries end verification early. It is
+// safe to call while requests are being planned.
+func (ta *TravelAgent) SetTargetOptions(n int) {
+	ta.targetOptions.Store(int64(n))
+}
+
+// withClock attaches the agent's clock to the request context so the planner, its
+// tools and the validator all agree on the current date
+func (ta *TravelAgent) withClock(ctx context.Context) context.Context {
+	if ta.Clock == nil {
+		return ctx
 	}
+	return tmcontext.WithClock(ctx, ta.Clock)
 }
 
 // isToolError checks if an error is related to tool execution failures
@@ -42,31 +104,68 @@ func isToolError(err error) bool {
 		strings.Contains(errMsg, "tool error")
 }
 
-// OrchestrateRequest handles the end-to-end planning process
+// verifiedSearchTools are withheld from the planner when its plans are verified
+var verifiedSearchTools = []string{amadeus.FlightToolName, amadeus.HotelListToolName, amadeus.HotelOffersToolName}
+
+// OrchestrateRequest handles the end-to-end planning process. The constraints the query
+// states, such as the cabin or a budget, are written into every plan before it is verified.
 func (ta *TravelAgent) OrchestrateRequest(ctx context.Context, userQuery string, history string) (string, []*pb.Itinerary, error) {
+	return ta.orchestrate(ctx, userQuery, history, TripPins{}, nil)
+}
+
+// orchestrate is OrchestrateRequest for a trip with pins, whose plans must keep them,
+// and with prepare, if set, applied to every planned itinerary after the query's
+// constraints and before it is verified
+func (ta *TravelAgent) orchestrate(ctx context.Context, userQuery string, history string, pins TripPins, prepare func(*pb.Itinerary)) (string, []*pb.Itinerary, error) {
+	ctx = ta.withClock(ctx)
 	currentHistory := history
 	maxIterations := 5
 
+	stats := tmcontext.RequestStatsFromContext(ctx)
+	sink := tmcontext.ItinerarySinkFromContext(ctx)
+	progress := tmcontext.ProgressSinkFromContext(ctx)
+	caps := ta.planner.Capabilities()
+	constraints := ParseQueryConstraints(ctx, userQuery)
+
+	// Every itinerary and re-planning iteration of this request resolves each location once
+	if tmcontext.LocationMemoFromContext(ctx) == nil {
+		ctx = tmcontext.WithLocationMemo(ctx, tmcontext.NewLocationMemo())
+	}
+
 	for i := range maxIterations {
 		log.Debugf(ctx, "Orchestration iteration %d", i+1)
+		stats.AddIteration()
+		progress.Report(pb.PlanStage_PLAN_STAGE_PLANNING, "")
 
 		// 1. Ask Planner for a plan (with retry logic for tool errors)
 		log.Infof(ctx, "STEP 1: Requesting trip plan from TripPlanner...")
 		planReq := PlanRequest{
 			UserQuery: userQuery,
 			History:   currentHistory,
+			Pins:      pins,
+		}
+		if caps.ToolCalling {
+			// TravelDesk searches the flights and hotels of every plan, so the planner
+			// searching them too would only double the calls
+			planReq.WithoutTools = verifiedSearchTools
 		}
 
 		var planRes *PlanResult
 		var err error
 		maxPlannerRetries := 3
 
+		planStart := time.Now()
 		for retryCount := range maxPlannerRetries {
 			planRes, err = ta.planner.Plan(ctx, planReq)
 
 			// Check if error is a tool error and we have retries left
 			if err != nil {
 				if isToolError(err) && retryCount < maxPlannerRetries-1 {
+					if !tmcontext.TakeRetry(ctx) {
+						log.Warnf(ctx, "Tool error in planning (attempt %d/%d): %v. Retry budget exhausted, giving up",
+							retryCount+1, maxPlannerRetries, err)
+						return "", nil, fmt.Errorf("planner error: %w", err)
+					}
 					log.Warnf(ctx, "Tool error in planning (attempt %d/%d): %v. Retrying...",
 						retryCount+1, maxPlannerRetries, err)
 					continue
@@ -77,6 +176,7 @@ func (ta *TravelAgent) OrchestrateRequest(ctx context.Context, userQuery string,
 			// Success, break out of retry loop
 			break
 		}
+		ta.observePhase(PhasePlanning, planStart)
 
 		if err != nil {
 			return "", nil, fmt.Errorf("planner error after retries: %w", err)
@@ -85,91 +185,178 @@ func (ta *TravelAgent) OrchestrateRequest(ctx context.Context, userQuery string,
 		// If Planner needs user clarification, return immediately
 		if planRes.NeedsClarification {
 			log.Infof(ctx, "TripPlanner requests clarification: %q", planRes.Question)
+			stats.SetOutcome(OutcomeClarification)
 			return planRes.Question, nil, nil
 		}
 
+		// A streaming client sees the reasoning of a planner that cannot stream once it is done
+		if text := tmcontext.TextSinkFromContext(ctx); text != nil && !caps.Streaming && planRes.Reasoning != "" {
+			text(planRes.Reasoning)
+		}
+
 		if len(planRes.PossibleItineraries) == 0 {
 			log.Errorf(ctx, "ERROR: TripPlanner returned no itinerary.")
 			return "", nil, fmt.Errorf("planner returned no itinerary and no question")
 		}
+		for _, it := range planRes.PossibleItineraries {
+			progress.Report(pb.PlanStage_PLAN_STAGE_PROPOSED, it.GetTitle())
+		}
+		// A flexible destination is answered with priced destinations, not verified trips
+		if planRes.Exploratory {
+			return ta.exploreDestinations(ctx, planRes)
+		}
+		for _, it := range planRes.PossibleItineraries {
+			constraints.Apply(it)
+			if prepare != nil {
+				prepare(it)
+			}
+		}
 
 		var successfulItineraries []*pb.Itinerary
-		var errors []string
+		// Plans with warnings but no errors, used if re-planning on warnings never clears them
+		var warnedItineraries []*pb.Itinerary
+		// Plans with errors, the best there is if the provider call ceiling stops the search
+		var failedItineraries []*pb.Itinerary
+		var planIssues []string
+		// Plans whose cheapest options are over the budget
+		var budgetConflicts []*BudgetConflict
+
+		// Plans that change what the user fixed are sent back without being searched
+		var itinerariesToCheck []*pb.Itinerary
+		for _, it := range planRes.PossibleItineraries {
+			if violations := pins.Violations(it); len(violations) > 0 {
+				log.Warnf(ctx, "Plan %q breaks the user's pins: %v", it.GetTitle(), violations)
+				planIssues = append(planIssues, fmt.Sprintf("Plan '%s' %s", it.GetTitle(), strings.Join(violations, "; ")))
+				continue
+			}
+			itinerariesToCheck = append(itinerariesToCheck, it)
+		}
+		if len(itinerariesToCheck) == 0 {
+			log.Warnf(ctx, "STEP 2: Every plan breaks the user's pins. Initiating re-planning...")
+			currentHistory += fmt.Sprintf("\nSystem: The proposed plans had issues:\n%s\nPlease revise.", strings.Join(planIssues, "\n"))
+			continue
+		}
 
 		// 2. Parallel Verification for each proposed itinerary
 		log.Infof(ctx, "STEP 2: Verifying itineraries with TravelDesk...")
 
-		itinerariesToCheck := planRes.PossibleItineraries
+		verifyStart := time.Now()
 
 		type deskResult struct {
+			title     string
 			itinerary *pb.Itinerary
 			err       error
 		}
 
+		// Buffered so verifications still running after cancellation never block
 		resChan := make(chan deskResult, len(itinerariesToCheck))
 
+		// Cancelled once enough itineraries are valid, to stop the remaining searches
+		verifyCtx, cancelVerify := context.WithCancel(ctx)
+
 		for _, it := range itinerariesToCheck {
+			progress.Report(pb.PlanStage_PLAN_STAGE_VERIFICATION_STARTED, it.GetTitle())
 			go func(it *pb.Itinerary) {
-				itinerary, err := ta.desk.CheckAvailability(ctx, it)
+				itinerary, err := ta.desk.CheckAvailability(verifyCtx, it)
 				if err != nil {
-					resChan <- deskResult{err: err}
+					resChan <- deskResult{title: it.GetTitle(), err: err}
 					return
 				}
-				resChan <- deskResult{itinerary: itinerary}
+				resChan <- deskResult{title: it.GetTitle(), itinerary: itinerary}
 			}(it)
 		}
 
 		for range itinerariesToCheck {
+			if target := ta.TargetOptions(); target > 0 && len(successfulItineraries) >= target {
+				log.Infof(ctx, "Found %d valid itineraries, cancelling remaining verifications", len(successfulItineraries))
+				break
+			}
+
 			res := <-resChan
+			progress.Report(pb.PlanStage_PLAN_STAGE_VERIFICATION_FINISHED, res.title)
 			if res.err != nil {
 				log.Errorf(ctx, "TravelDesk verification error: %v", res.err)
+				// Empty plans are sent back to the planner to be filled in
+				if errors.Is(res.err, core.ErrIncompleteItinerary) {
+					planIssues = append(planIssues, fmt.Sprintf("Plan '%s': %v. Every plan needs at least one transport leg or one stay", res.title, res.err))
+				}
+				var conflict *BudgetConflict
+				if errors.As(res.err, &conflict) {
+					budgetConflicts = append(budgetConflicts, conflict)
+					planIssues = append(planIssues, fmt.Sprintf("Plan '%s': %v", res.title, res.err))
+				}
 				continue
 			}
 
 			// Check for errors in the itinerary
-			var itineraryIssues []string
-			if res.itinerary.Graph != nil {
-				// Check Flights
-				for _, edge := range res.itinerary.Graph.Edges {
-					if edge.Transport != nil && edge.Transport.Error != nil && edge.Transport.Error.Severity == pb.ErrorSeverity_ERROR_SEVERITY_ERROR {
-						itineraryIssues = append(itineraryIssues, fmt.Sprintf("Transport error: %s", edge.Transport.Error.Message))
-					}
-				}
-				// Check Accommodation
-				for _, node := range res.itinerary.Graph.Nodes {
-					if node.Stay != nil && node.Stay.Error != nil && node.Stay.Error.Severity == pb.ErrorSeverity_ERROR_SEVERITY_ERROR {
-						itineraryIssues = append(itineraryIssues, fmt.Sprintf("Stay error: %s", node.Stay.Error.Message))
-					}
-				}
-			}
+			itineraryIssues := issuesFrom(res.itinerary, ta.ReplanPolicy.threshold())
 
 			// Log itinerary as JSON
-			if b, err := json.MarshalIndent(res.itinerary, "", "  "); err == nil {
-				log.Debugf(ctx, "TravelDesk itinerary: %s", string(b))
+			if b, err := tmcore.MarshalJSON(res.itinerary); err == nil {
+				log.Tracef(ctx, "TravelDesk itinerary: %s", string(b))
 			} else {
-				log.Debugf(ctx, "TravelDesk itinerary: %v", res.itinerary)
+				log.Tracef(ctx, "TravelDesk itinerary: %v", res.itinerary)
 			}
 
 			if len(itineraryIssues) > 0 {
 				log.Warnf(ctx, "TravelDesk issues for %s: %v", res.itinerary.Title, itineraryIssues)
-				errors = append(errors, fmt.Sprintf("Plan '%s': %s", res.itinerary.Title, strings.Join(itineraryIssues, "; ")))
+				planIssues = append(planIssues, fmt.Sprintf("Plan '%s': %s", res.itinerary.Title, strings.Join(itineraryIssues, "; ")))
+				if len(availabilityIssues(res.itinerary)) == 0 {
+					warnedItineraries = append(warnedItineraries, res.itinerary)
+				} else {
+					failedItineraries = append(failedItineraries, res.itinerary)
+				}
 			} else {
 				successfulItineraries = append(successfulItineraries, res.itinerary)
+				if sink != nil {
+					sink(res.itinerary)
+				}
 			}
 		}
-		close(resChan)
+		cancelVerify()
+		ta.observePhase(PhaseVerification, verifyStart)
 
 		// 3. check results
+		if len(successfulItineraries) == 0 && len(warnedItineraries) > 0 && i == maxIterations-1 {
+			log.Warnf(ctx, "STEP 3: Out of re-planning attempts, returning %d plans with warnings", len(warnedItineraries))
+			successfulItineraries = warnedItineraries
+		}
+		// Past the provider call ceiling re-planning could not search anything, so the
+		// plans are returned as far as they got
+		if len(successfulItineraries) == 0 && tmcontext.CallBudgetFromContext(ctx).Exhausted() {
+			successfulItineraries = append(warnedItineraries, failedItineraries...)
+			if len(successfulItineraries) == 0 {
+				log.Warnf(ctx, "STEP 3: Provider call limit reached before any plan was checked")
+				return "I reached the limit of searches for one request before I could check a plan. Can we narrow the trip down?", nil, nil
+			}
+			log.Warnf(ctx, "STEP 3: Provider call limit reached, returning %d plans found so far", len(successfulItineraries))
+		}
+		// When every plan is over the budget, re-planning the same trip will not help;
+		// the user is asked whether to relax the budget or what else the plans ask for
+		if len(successfulItineraries) == 0 && len(budgetConflicts) == len(itinerariesToCheck) {
+			cheapest := budgetConflicts[0]
+			for _, c := range budgetConflicts[1:] {
+				if c.Cheapest < cheapest.Cheapest {
+					cheapest = c
+				}
+			}
+			question := cheapest.Question()
+			log.Infof(ctx, "STEP 3: All plans are over the budget, asking: %q", question)
+			stats.SetOutcome(OutcomeClarification)
+			return question, nil, nil
+		}
 		if len(successfulItineraries) == 0 {
 			log.Warnf(ctx, "STEP 3: All plans had issues. Initiating re-planning...")
 			// Feed issues back to Planner
-			issueStr := strings.Join(errors, "\n")
+			issueStr := strings.Join(planIssues, "\n")
 			currentHistory += fmt.Sprintf("\nSystem: The proposed plans had issues:\n%s\nPlease revise.", issueStr)
 			continue // Loop back to planner
 		}
 
 		// Score, Tag and Sort Itineraries and Options
+		scoreStart := time.Now()
 		ta.scoreAndTag(successfulItineraries)
+		ta.observePhase(PhaseScoring, scoreStart)
 
 		// 4. Success! Formulate final response
 		var finalResponse strings.Builder
@@ -177,13 +364,23 @@ func (ta *TravelAgent) OrchestrateRequest(ctx context.Context, userQuery string,
 
 		for i, itin := range successfulItineraries {
 			fmt.Fprintf(&finalResponse, "### Option %d: %s %s\n", i+1, itin.Title, formatTags(itin.Tags))
+			if summary := formatCostBreakdown(itin.CostBreakdown); summary != "" {
+				fmt.Fprintf(&finalResponse, "%s\n", summary)
+			}
+			if split := itin.BudgetSplit; split != nil {
+				fmt.Fprintf(&finalResponse, "Budget: %s.", formatBudgetSplit(itin.Budget, split))
+				if split.Note != "" {
+					fmt.Fprintf(&finalResponse, " %s.", split.Note)
+				}
+				finalResponse.WriteString("\n")
+			}
 			finalResponse.WriteString(ta.formatItinerary(itin, 0))
 			finalResponse.WriteString("\n")
 
 			// Pretty print the itinerary JSON
-			b, err := json.MarshalIndent(itin, "", "  ")
+			b, err := tmcore.MarshalJSON(itin)
 			if err == nil {
-				log.Debugf(ctx, "Final Itinerary JSON (Option %d):\n%s", i+1, string(b))
+				log.Tracef(ctx, "Final Itinerary JSON (Option %d):\n%s", i+1, string(b))
 			}
 		}
 
@@ -205,19 +402,19 @@ func (ta *TravelAgent) formatItinerary(it *pb.Itinerary, indentLevel int) string
 	var items []itineraryItem
 	indent := strings.Repeat("  ", indentLevel)
 
-	if it.Graph == nil {
+	if it.GetGraph() == nil {
 		return ""
 	}
 
 	// Collect Accommodation (Nodes)
 	for _, node := range it.Graph.Nodes {
-		if acc := node.Stay; acc != nil {
+		if acc := node.GetStay(); acc != nil {
 			start := acc.CheckIn.AsTime()
 			end := acc.CheckOut.AsTime()
 			items = append(items, itineraryItem{
 				Time:    start.Format("Jan 02 15:04"),
 				EndTime: end.Format("Jan 02 15:04"),
-				Details: fmt.Sprintf("Stay at %s (%s). Ref: %s. Price: %.2f %s %s", acc.Name, acc.Location.City, acc.BookingReference, acc.GetCost().GetValue(), acc.GetCost().GetCurrency(), formatTags(acc.Tags)),
+				Details: fmt.Sprintf("Stay at %s (%s)%s. Ref: %s. Price: %.2f %s%s %s%s", acc.Name, acc.GetLocation().GetCity(), formatRoomType(acc), acc.BookingReference, acc.GetCost().GetValue(), acc.GetCost().GetCurrency(), formatNightly(acc), formatTags(acc.Tags), formatNotice(acc.Error)),
 				SortKey: start.Format(time.RFC3339),
 			})
 		}
@@ -225,7 +422,7 @@ func (ta *TravelAgent) formatItinerary(it *pb.Itinerary, indentLevel int) string
 
 	// Collect Transport (Edges)
 	for _, edge := range it.Graph.Edges {
-		if t := edge.Transport; t != nil {
+		if t := edge.GetTransport(); t != nil {
 			// Try to find a time for sorting
 			var sortTime string
 			var description string
@@ -262,6 +459,8 @@ func (ta *TravelAgent) formatItinerary(it *pb.Itinerary, indentLevel int) string
 				description = fmt.Sprintf("Transport: %s", t.Type)
 			}
 
+			description += formatNotice(t.Error) + formatSimilarOptions(edge)
+
 			items = append(items, itineraryItem{
 				Time:    "", // Already in description if relevant
 				Details: fmt.Sprintf("%s Ref: %s", description, t.ReferenceNumber),
@@ -298,13 +497,17 @@ func (ta *TravelAgent) formatItinerary(it *pb.Itinerary, indentLevel int) string
 			sb.WriteString(fmt.Sprintf("%s- %s\n", indent, item.Details))
 		}
 	}
+	if summary := formatStats(it.Stats); summary != "" {
+		sb.WriteString(fmt.Sprintf("%s%s\n", indent, summary))
+	}
 	return sb.String()
 }
 
-// scoreAndTag scores, tags, and selects the best options in the itineraries
+// scoreAndTag scores, tags, and selects the best options in the itineraries,
+// then computes each itinerary's journey stats
 func (ta *TravelAgent) scoreAndTag(itineraries []*pb.Itinerary) {
 	for _, it := range itineraries {
-		if it.Graph == nil {
+		if it.GetGraph() == nil {
 			continue
 		}
 
@@ -318,95 +521,8 @@ func (ta *TravelAgent) scoreAndTag(itineraries []*pb.Itinerary) {
 			}
 
 			if len(edge.TransportOptions) > 0 {
-				// Calculate scores and find min/max for tagging
-				var minPrice float64 = math.MaxFloat64
-				var minDuration int64 = math.MaxInt64
-
-				for _, t := range edge.TransportOptions {
-					if t.GetCost().GetValue() < minPrice {
-						minPrice = t.GetCost().GetValue()
-					}
-
-					// Calculate duration
-					var duration int64
-					if t.Type == pb.TransportType_TRANSPORT_TYPE_FLIGHT && t.GetFlight() != nil {
-						f := t.GetFlight()
-						if f.ArrivalTime != nil && f.DepartureTime != nil {
-							duration = f.ArrivalTime.Seconds - f.DepartureTime.Seconds
-						}
-					}
-
-					if duration > 0 && duration < minDuration {
-						minDuration = duration
-					}
-				}
-
-				// Assign Tags and Score
-				type scoredTransport struct {
-					t     *pb.Transport
-					score float64
-				}
-				var scored []*scoredTransport
-
-				for _, t := range edge.TransportOptions {
-					t.Tags = []string{} // Reset tags
-
-					// Tagging
-					if t.GetCost().GetValue() == minPrice {
-						t.Tags = append(t.Tags, "Cheapest")
-					}
-
-					var duration int64
-					if t.Type == pb.TransportType_TRANSPORT_TYPE_FLIGHT && t.GetFlight() != nil {
-						f := t.GetFlight()
-						if f.ArrivalTime != nil && f.DepartureTime != nil {
-							duration = f.ArrivalTime.Seconds - f.DepartureTime.Seconds
-						}
-					}
-
-					if duration > 0 && duration == minDuration {
-						t.Tags = append(t.Tags, "Fastest")
-					}
-
-					// Scoring (Lower is better)
-					// Base score = Price
-					score := t.GetCost().GetValue()
-
-					// Adjust for duration (value of time?)
-					// Let's say we value 1 hour at $20
-					if duration > 0 {
-						hours := float64(duration) / 3600.0
-						score += hours * 20.0
-					}
-
-					scored = append(scored, &scoredTransport{t: t, score: score})
-				}
-
-				// Identify Best Value (Lowest Score)
-				sort.Slice(scored, func(i, j int) bool {
-					return scored[i].score < scored[j].score
-				})
-
-				// Tag the best score as "Best Value"
-				hasBestValue := false
-				for _, tag := range scored[0].t.Tags {
-					if tag == "Best Value" {
-						hasBestValue = true
-						break
-					}
-				}
-				if !hasBestValue {
-					scored[0].t.Tags = append(scored[0].t.Tags, "Best Value")
-				}
-
-				// Reorder options
-				newOptions := make([]*pb.Transport, len(scored))
-				for i, s := range scored {
-					newOptions[i] = s.t
-				}
-				edge.TransportOptions = newOptions
-
-				// Set best option
+				ranked := ta.rankOptions(options.Transports(edge.TransportOptions), ta.scoreTransport, nil)
+				edge.TransportOptions = options.ToTransports(ranked)
 				edge.Transport = edge.TransportOptions[0]
 
 				// Add to itinerary total score
@@ -414,6 +530,9 @@ func (ta *TravelAgent) scoreAndTag(itineraries []*pb.Itinerary) {
 			}
 		}
 
+		// Separately booked flights meeting at a connection
+		ta.checkSelfTransfers(it.Graph)
+
 		// 2. Nodes (Accommodation)
 		for _, node := range it.Graph.Nodes {
 			if len(node.StayOptions) == 0 && node.Stay != nil {
@@ -421,56 +540,31 @@ func (ta *TravelAgent) scoreAndTag(itineraries []*pb.Itinerary) {
 			}
 
 			if len(node.StayOptions) > 0 {
-				var minPrice float64 = math.MaxFloat64
-
-				for _, s := range node.StayOptions {
-					p := s.GetCost().GetValue()
-					if p < minPrice {
-						minPrice = p
-					}
-				}
-
-				type scoredStay struct {
-					s     *pb.Accommodation
-					score float64
-					price float64
+				// Rank hotels by their best offer first, then list the other rates,
+				// so a single property with many rooms cannot flood the options
+				prefs := node.Stay.GetPreferences()
+				var near *arrival
+				if prefs.GetNearTransit() {
+					near = ta.arrivalAt(it.Graph, node.Id)
 				}
-				var scored []*scoredStay
-
-				for _, s := range node.StayOptions {
-					s.Tags = []string{}
-					p := s.GetCost().GetValue()
-
-					if p == minPrice {
-						s.Tags = append(s.Tags, "Cheapest")
-					}
-
-					// Score = Price
-					score := p
-
-					scored = append(scored, &scoredStay{s: s, score: score, price: p})
-				}
-
-				// Sort
-				sort.Slice(scored, func(i, j int) bool {
-					return scored[i].score < scored[j].score
-				})
-
-				// Best Value tag for top 1
-				scored[0].s.Tags = append(scored[0].s.Tags, "Best Value")
-
-				newOptions := make([]*pb.Accommodation, len(scored))
-				for i, s := range scored {
-					newOptions[i] = s.s
-				}
-				node.StayOptions = newOptions
+				ranked := ta.rankOptions(options.Stays(node.StayOptions), ta.stayScorer(prefs.GetBreakfast(), near), stayHotel)
+				node.StayOptions = options.ToStays(ranked)
 				node.Stay = node.StayOptions[0]
 
-				totalScore += scored[0].price
+				totalScore += node.Stay.GetCost().GetValue()
 			}
 		}
 	}
 
+	// Journey stats and costs depend on the options selected above
+	for _, it := range itineraries {
+		it.Stats = computeItineraryStats(it)
+		it.CostBreakdown = computeCostBreakdown(it)
+		for _, edge := range it.Graph.GetEdges() {
+			groupOptions(edge)
+		}
+	}
+
 	// Second pass: Tag Itineraries
 	if len(itineraries) > 0 {
 		var minTotalScore float64 = math.MaxFloat64
@@ -495,8 +589,8 @@ func (ta *TravelAgent) scoreAndTag(itineraries []*pb.Itinerary) {
 			}
 		}
 
-		// Sort itineraries by score
-		sort.Slice(itineraries, func(i, j int) bool {
+		// Sort itineraries by score; equal ones keep the planner's order
+		sort.SliceStable(itineraries, func(i, j int) bool {
 			si := calculateItineraryScore(itineraries[i])
 			sj := calculateItineraryScore(itineraries[j])
 			return si < sj
@@ -506,7 +600,7 @@ func (ta *TravelAgent) scoreAndTag(itineraries []*pb.Itinerary) {
 
 func calculateItineraryScore(it *pb.Itinerary) float64 {
 	var total float64
-	if it.Graph == nil {
+	if it.GetGraph() == nil {
 		return 0
 	}
 	for _, e := range it.Graph.Edges {
@@ -532,9 +626,38 @@ func parsePrice(s string) float64 {
 	return val
 }
 
+// formatNotice renders a warning or note on an option, e.g. " Warning: self-transfer."
+func formatNotice(e *pb.Error) string {
+	switch e.GetSeverity() {
+	case pb.ErrorSeverity_ERROR_SEVERITY_WARNING:
+		return fmt.Sprintf(" Warning: %s.", e.Message)
+	case pb.ErrorSeverity_ERROR_SEVERITY_INFO:
+		return fmt.Sprintf(" Note: %s.", e.Message)
+	}
+	return ""
+}
+
 func formatTags(tags []string) string {
 	if len(tags) == 0 {
 		return ""
 	}
 	return fmt.Sprintf("[%s]", strings.Join(tags, ", "))
 }
+
+// formatNightly shows the price per night next to a stay's total, e.g. " (150.00 USD per night)"
+func formatNightly(acc *pb.Accommodation) string {
+	n := acc.GetNightlyCost()
+	if n.GetValue() == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%.2f %s per night)", n.Value, n.Currency)
+}
+
+// formatRoomType describes the chosen room, e.g. ", deluxe room" for DELUXE_ROOM
+func formatRoomType(acc *pb.Accommodation) string {
+	roomType := strings.ToLower(strings.ReplaceAll(acc.GetPreferences().GetRoomType(), "_", " "))
+	if roomType == "" {
+		return ""
+	}
+	return fmt.Sprintf(", %s room", strings.TrimSuffix(roomType, " room"))
+}
@@ -3,22 +3,34 @@ package agents
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	tmcontext "github.com/va6996/travelingman/context"
 	"github.com/va6996/travelingman/pb"
 	"github.com/va6996/travelingman/plugins/amadeus"
+	"github.com/va6996/travelingman/tools"
+	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // MockPlanner
 type MockPlanner struct {
 	mock.Mock
+	Caps PlannerCapabilities
+}
+
+func (m *MockPlanner) Capabilities() PlannerCapabilities {
+	return m.Caps
 }
 
 func (m *MockPlanner) Plan(ctx context.Context, req PlanRequest) (*PlanResult, error) {
@@ -261,3 +273,426 @@ func TestTravelAgent_OrchestrateRequest_RetryOnFailure(t *testing.T) {
 	assert.Contains(t, response, "Good Plan")
 	mockPlanner.AssertExpectations(t)
 }
+
+func TestTravelAgent_OrchestrateRequest_ReplansIncompleteItinerary(t *testing.T) {
+	// The empty plan is rejected before any provider is asked, so the desk needs no client
+	agent := NewTravelAgent(new(MockPlanner), NewTravelDesk(nil))
+	mockPlanner := agent.planner.(*MockPlanner)
+
+	empty := &pb.Itinerary{
+		Title:       "Empty Plan",
+		StartTime:   timestamppb.New(time.Now().AddDate(0, 0, 7)),
+		EndTime:     timestamppb.New(time.Now().AddDate(0, 0, 10)),
+		Travelers:   1,
+		JourneyType: pb.JourneyType_JOURNEY_TYPE_ONE_WAY,
+		Graph:       &pb.Graph{Nodes: []*pb.Node{{Id: "n1", Location: &pb.Location{City: "London"}}}},
+	}
+	mockPlanner.On("Plan", mock.Anything, mock.MatchedBy(func(req PlanRequest) bool {
+		return req.History == ""
+	})).Return(&PlanResult{PossibleItineraries: []*pb.Itinerary{empty}}, nil).Once()
+
+	// The planner is told what was wrong and asks the user instead
+	mockPlanner.On("Plan", mock.Anything, mock.MatchedBy(func(req PlanRequest) bool {
+		return strings.Contains(req.History, "Plan 'Empty Plan': itinerary has no transport and no stay")
+	})).Return(&PlanResult{NeedsClarification: true, Question: "Where would you like to go?"}, nil).Once()
+
+	response, itineraries, err := agent.OrchestrateRequest(context.Background(), "Plan a trip", "")
+	assert.NoError(t, err)
+	assert.Empty(t, itineraries)
+	assert.Equal(t, "Where would you like to go?", response)
+	mockPlanner.AssertExpectations(t)
+}
+
+// racingDesk verifies "fast" itineraries immediately and blocks the others until cancelled
+type racingDesk struct {
+	cancelled chan string
+}
+
+func (d *racingDesk) CheckAvailability(ctx context.Context, it *pb.Itinerary) (*pb.Itinerary, error) {
+	if strings.HasPrefix(it.Title, "fast") {
+		return it, nil
+	}
+	select {
+	case <-ctx.Done():
+		d.cancelled <- it.Title
+		return nil, ctx.Err()
+	case <-time.After(5 * time.Second):
+		return it, nil
+	}
+}
+
+func TestTravelAgent_OrchestrateRequest_CancelsRemainingVerifications(t *testing.T) {
+	mockPlanner := new(MockPlanner)
+	desk := &racingDesk{cancelled: make(chan string, 2)}
+	agent := NewTravelAgent(mockPlanner, desk)
+	agent.SetTargetOptions(2)
+
+	var plans []*pb.Itinerary
+	for _, title := range []string{"fast 1", "slow 1", "fast 2", "slow 2"} {
+		plans = append(plans, &pb.Itinerary{Title: title, Graph: &pb.Graph{}})
+	}
+	mockPlanner.On("Plan", mock.Anything, mock.Anything).Return(&PlanResult{PossibleItineraries: plans}, nil).Once()
+
+	start := time.Now()
+	_, itineraries, err := agent.OrchestrateRequest(context.Background(), "Trip", "")
+	if err != nil {
+		t.Fatalf("OrchestrateRequest failed: %v", err)
+	}
+
+	if assert.Len(t, itineraries, 2) {
+		assert.True(t, strings.HasPrefix(itineraries[0].Title, "fast"))
+		assert.True(t, strings.HasPrefix(itineraries[1].Title, "fast"))
+	}
+	assert.Less(t, time.Since(start), 5*time.Second, "should not wait for the slow verifications")
+
+	// Both slow searches see their context cancelled
+	var cancelled []string
+	for range 2 {
+		select {
+		case title := <-desk.cancelled:
+			cancelled = append(cancelled, title)
+		case <-time.After(time.Second):
+			t.Fatal("slow verification was not cancelled")
+		}
+	}
+	assert.ElementsMatch(t, []string{"slow 1", "slow 2"}, cancelled)
+	mockPlanner.AssertExpectations(t)
+}
+
+func TestTravelAgent_ScoreAndTag_RanksHotelsByBestOffer(t *testing.T) {
+	stay := func(hotel, room string, price float64) *pb.Accommodation {
+		return &pb.Accommodation{
+			HotelId:     hotel,
+			Name:        "Hotel " + hotel,
+			Location:    &pb.Location{City: "Paris"},
+			Preferences: &pb.AccommodationPreferences{RoomType: room},
+			Cost:        &pb.Cost{Value: price, Currency: "EUR"},
+			CheckIn:     timestamppb.New(time.Date(2026, 6, 1, 15, 0, 0, 0, time.UTC)),
+			CheckOut:    timestamppb.New(time.Date(2026, 6, 3, 11, 0, 0, 0, time.UTC)),
+		}
+	}
+
+	// Hotel A has three rates, two of them cheaper than hotel B's only rate
+	it := &pb.Itinerary{Graph: &pb.Graph{Nodes: []*pb.Node{{
+		Id: "paris",
+		StayOptions: []*pb.Accommodation{
+			stay("A", "SUITE", 400),
+			stay("B", "STANDARD_ROOM", 150),
+			stay("A", "SUPERIOR_ROOM", 130),
+			stay("A", "DELUXE_ROOM", 110),
+		},
+	}}}}
+
+	agent := NewTravelAgent(nil, nil)
+	agent.scoreAndTag([]*pb.Itinerary{it})
+
+	node := it.Graph.Nodes[0]
+	var order []string
+	for _, s := range node.StayOptions {
+		order = append(order, fmt.Sprintf("%s/%.0f", s.HotelId, s.Cost.Value))
+	}
+	// Each hotel's best rate comes first, ranked across hotels, then the remaining rates
+	assert.Equal(t, []string{"A/110", "B/150", "A/130", "A/400"}, order)
+	assert.Equal(t, "DELUXE_ROOM", node.Stay.Preferences.RoomType)
+
+	assert.Contains(t, agent.formatItinerary(it, 0), "Stay at Hotel A (Paris), deluxe room.")
+}
+
+func TestTravelAgent_OrchestrateRequest_SharesLocationLookups(t *testing.T) {
+	var locationCalls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/security/oauth2/token":
+			json.NewEncoder(w).Encode(amadeus.AuthToken{AccessToken: "token"})
+		case "/v1/reference-data/locations":
+			atomic.AddInt32(&locationCalls, 1)
+			code := r.URL.Query().Get("keyword")
+			json.NewEncoder(w).Encode(amadeus.LocationSearchResponse{
+				Data: []amadeus.LocationData{{
+					SubType: "AIRPORT", Name: code, JobCode: code,
+					Address: amadeus.Address{CityName: "City " + code, CityCode: code, CountryName: "TEST", CountryCode: "TS"},
+				}},
+			})
+		case "/v2/shopping/flight-offers":
+			json.NewEncoder(w).Encode(amadeus.FlightSearchResponse{
+				Data: []amadeus.FlightOffer{{
+					ID:    "flight1",
+					Price: amadeus.Price{Total: "200.00"},
+					Itineraries: []amadeus.Itinerary{{Segments: []amadeus.Segment{{
+						CarrierCode: "BA", Number: "123",
+						Departure: amadeus.FlightEndPoint{IataCode: "LHR", At: "2026-06-01T10:00:00"},
+						Arrival:   amadeus.FlightEndPoint{IataCode: "JFK", At: "2026-06-01T14:00:00"},
+					}}}},
+				}},
+			})
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer ts.Close()
+
+	// Location results are not cached by the client, so only the memo can dedupe them
+	client, err := amadeus.NewClient(amadeus.Config{
+		ClientID: "id", ClientSecret: "secret", IsProduction: false,
+		FlightLimit: 10, HotelLimit: 10, Timeout: 30,
+		CacheTTL: amadeus.CacheTTLConfig{Location: 0, Flight: 24, Hotel: 24},
+	}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	client.BaseURL = ts.URL
+
+	// The desk fills in the plans it checks, so each request gets fresh ones
+	newPlans := func(days int) []*pb.Itinerary {
+		var plans []*pb.Itinerary
+		for day := 1; day <= days; day++ {
+			departure := timestamppb.New(time.Now().Add(time.Duration(day) * 24 * time.Hour))
+			plans = append(plans, &pb.Itinerary{
+				Title:       fmt.Sprintf("London to New York, day %d", day),
+				StartTime:   departure,
+				EndTime:     timestamppb.New(departure.AsTime().Add(8 * time.Hour)),
+				Travelers:   1,
+				JourneyType: pb.JourneyType_JOURNEY_TYPE_ONE_WAY,
+				Graph: &pb.Graph{
+					Nodes: []*pb.Node{
+						{Id: "n1", Location: &pb.Location{IataCodes: []string{"LHR"}}},
+						{Id: "n2", Location: &pb.Location{IataCodes: []string{"JFK"}}},
+					},
+					Edges: []*pb.Edge{{
+						FromId: "n1",
+						ToId:   "n2",
+						Transport: &pb.Transport{
+							Type:                pb.TransportType_TRANSPORT_TYPE_FLIGHT,
+							OriginLocation:      &pb.Location{IataCodes: []string{"LHR"}},
+							DestinationLocation: &pb.Location{IataCodes: []string{"JFK"}},
+							TravelerCount:       1,
+							Details:             &pb.Transport_Flight{Flight: &pb.Flight{DepartureTime: departure}},
+						},
+					}},
+				},
+			})
+		}
+		return plans
+	}
+
+	mockPlanner := new(MockPlanner)
+	mockPlanner.On("Plan", mock.Anything, mock.Anything).Return(&PlanResult{PossibleItineraries: newPlans(3)}, nil).Once()
+
+	agent := NewTravelAgent(mockPlanner, NewTravelDesk(client))
+	_, itineraries, err := agent.OrchestrateRequest(context.Background(), "London to New York this week", "")
+	if err != nil {
+		t.Fatalf("OrchestrateRequest failed: %v", err)
+	}
+
+	assert.Len(t, itineraries, 3)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&locationCalls), "each code is resolved once per request")
+	for _, it := range itineraries {
+		assert.Equal(t, "City LHR", it.Graph.Edges[0].Transport.OriginLocation.City)
+		assert.Equal(t, "City JFK", it.Graph.Nodes[1].Location.City)
+	}
+
+	// A new request starts with an empty memo
+	mockPlanner.On("Plan", mock.Anything, mock.Anything).Return(&PlanResult{PossibleItineraries: newPlans(1)}, nil).Once()
+	_, _, err = agent.OrchestrateRequest(context.Background(), "London to New York tomorrow", "")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&locationCalls))
+}
+
+// pricedDesk finds one flight and one stay option at the given prices for every plan
+type pricedDesk struct {
+	flight, stay float64
+}
+
+func (d pricedDesk) CheckAvailability(ctx context.Context, it *pb.Itinerary) (*pb.Itinerary, error) {
+	planBudget(ctx, it)
+	for _, e := range it.Graph.Edges {
+		e.TransportOptions = []*pb.Transport{{Cost: &pb.Cost{Value: d.flight, Currency: "USD"}}}
+	}
+	for _, n := range it.Graph.Nodes {
+		if n.Stay != nil {
+			n.StayOptions = []*pb.Accommodation{{Cost: &pb.Cost{Value: d.stay, Currency: "USD"}}}
+		}
+	}
+	if conflict := budgetConflict(it); conflict != nil {
+		return nil, conflict
+	}
+	return it, nil
+}
+
+func TestTravelAgent_OrchestrateRequest_AsksWhenOverBudget(t *testing.T) {
+	mockPlanner := new(MockPlanner)
+	agent := NewTravelAgent(mockPlanner, pricedDesk{flight: 1800, stay: 450})
+
+	// Business class from New York to Tokyo for 200 USD
+	plan := func(title string) *pb.Itinerary {
+		it := budgetFixture()
+		it.Title = title
+		it.Budget.Value = 200
+		for _, e := range it.Graph.Edges {
+			e.Transport.FlightPreferences = &pb.FlightPreferences{TravelClass: pb.Class_CLASS_BUSINESS}
+		}
+		return it
+	}
+	mockPlanner.On("Plan", mock.Anything, mock.Anything).
+		Return(&PlanResult{PossibleItineraries: []*pb.Itinerary{plan("Tokyo"), plan("Tokyo via Seoul")}}, nil).Once()
+
+	response, itineraries, err := agent.OrchestrateRequest(context.Background(), "Business class under $200 NYC to Tokyo", "")
+	assert.NoError(t, err)
+	assert.Empty(t, itineraries)
+	assert.Equal(t, "I couldn't find a trip within your budget: the cheapest business options come to 4050.00 USD, over your 200.00 USD. "+
+		"Would you like to raise the budget, or fly economy instead?", response)
+	mockPlanner.AssertExpectations(t)
+}
+
+func TestTravelAgent_OrchestrateRequest_SearchesEachRouteOnce(t *testing.T) {
+	upstream := mockAmadeusServer()
+	defer upstream.Close()
+	var flightSearches atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/shopping/flight-offers" {
+			flightSearches.Add(1)
+		}
+		upstream.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	ctx := context.Background()
+	gk := genkit.Init(ctx)
+	registry := tools.NewRegistry()
+	client, err := amadeus.NewClient(amadeus.Config{
+		ClientID: "id", ClientSecret: "secret", IsProduction: false,
+		FlightLimit: 10, HotelLimit: 10, Timeout: 30,
+		CacheTTL: amadeus.CacheTTLConfig{Location: 24, Flight: 24, Hotel: 24},
+	}, gk, registry, nil)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	client.BaseURL = ts.URL
+
+	// The model prices the flight itself whenever it is offered the tool, then answers
+	start := time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)
+	draft, err := protojson.Marshal(draftItinerary(start))
+	if err != nil {
+		t.Fatalf("Failed to marshal draft: %v", err)
+	}
+	var offered []string
+	model := genkit.DefineModel(gk, "test/searching-planner", &ai.ModelOptions{Supports: &ai.ModelSupports{Tools: true, Multiturn: true, SystemRole: true}},
+		func(ctx context.Context, req *ai.ModelRequest, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+			asked := false
+			for _, m := range req.Messages {
+				for _, p := range m.Content {
+					asked = asked || p.IsToolRequest()
+				}
+			}
+			offered = offered[:0]
+			for _, tool := range req.Tools {
+				offered = append(offered, tool.Name)
+				if tool.Name == amadeus.FlightToolName && !asked {
+					return &ai.ModelResponse{Request: req, Message: ai.NewModelMessage(ai.NewToolRequestPart(&ai.ToolRequest{
+						Name: tool.Name, Input: map[string]any{
+							"origin":      map[string]any{"iata_codes": []string{"LHR"}},
+							"destination": map[string]any{"iata_codes": []string{"JFK"}},
+							"date":        "2026-06-01",
+							"adults":      1,
+						},
+					}))}, nil
+				}
+			}
+			answer := fmt.Sprintf(`{"itineraries": [%s], "reasoning": "June 1st"}`, draft)
+			return &ai.ModelResponse{Request: req, Message: ai.NewModelTextMessage(answer), FinishReason: ai.FinishReasonStop}, nil
+		})
+
+	agent := NewTravelAgent(NewTripPlanner(gk, registry, model), NewTravelDesk(client))
+	agent.Clock = tmcontext.FixedClock(start.AddDate(0, -1, 0))
+	_, itineraries, err := agent.OrchestrateRequest(ctx, "London to New York on June 1st", "")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, itineraries)
+
+	// Only TravelDesk searched LHR-JFK; the planner kept its other tools
+	assert.Equal(t, int32(1), flightSearches.Load())
+	assert.NotContains(t, offered, amadeus.FlightToolName)
+	assert.NotContains(t, offered, amadeus.HotelOffersToolName)
+	assert.Contains(t, offered, amadeus.ToolPrefix+"location_tool")
+}
+
+func TestTravelAgent_OrchestrateRequest_StreamsVerified(t *testing.T) {
+	unavailable := &pb.Error{Message: "sold out", Severity: pb.ErrorSeverity_ERROR_SEVERITY_ERROR}
+	planner := new(MockPlanner)
+	planner.On("Plan", mock.Anything, mock.Anything).Return(&PlanResult{PossibleItineraries: []*pb.Itinerary{
+		stayPlan("Left Bank", nil), stayPlan("Sold out", unavailable), stayPlan("Marais", nil),
+	}}, nil).Once()
+
+	var streamed []string
+	ctx := tmcontext.WithItinerarySink(context.Background(), func(it *pb.Itinerary) { streamed = append(streamed, it.Title) })
+	_, itineraries, err := NewTravelAgent(planner, passDesk{}).OrchestrateRequest(ctx, "Paris", "")
+	assert.NoError(t, err)
+
+	// Each verified plan is streamed once, before the call returns; failed ones never are
+	var titles []string
+	for _, it := range itineraries {
+		titles = append(titles, it.Title)
+	}
+	assert.ElementsMatch(t, []string{"Left Bank", "Marais"}, streamed)
+	assert.ElementsMatch(t, titles, streamed)
+}
+
+func TestTravelAgent_OrchestrateRequest_ReportsProgress(t *testing.T) {
+	planner := new(MockPlanner)
+	planner.On("Plan", mock.Anything, mock.Anything).Return(&PlanResult{PossibleItineraries: []*pb.Itinerary{
+		stayPlan("Left Bank", nil), stayPlan("Marais", nil),
+	}}, nil).Once()
+
+	steps := map[pb.PlanStage][]string{}
+	ctx := tmcontext.WithProgressSink(context.Background(), func(stage pb.PlanStage, detail string) {
+		steps[stage] = append(steps[stage], detail)
+	})
+	_, _, err := NewTravelAgent(planner, passDesk{}).OrchestrateRequest(ctx, "Paris", "")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{""}, steps[pb.PlanStage_PLAN_STAGE_PLANNING])
+	assert.Equal(t, []string{"Left Bank", "Marais"}, steps[pb.PlanStage_PLAN_STAGE_PROPOSED])
+	assert.Equal(t, []string{"Left Bank", "Marais"}, steps[pb.PlanStage_PLAN_STAGE_VERIFICATION_STARTED])
+	assert.ElementsMatch(t, []string{"Left Bank", "Marais"}, steps[pb.PlanStage_PLAN_STAGE_VERIFICATION_FINISHED])
+}
+
+func TestTravelAgent_OrchestrateRequest_PlannerCapabilities(t *testing.T) {
+	tests := []struct {
+		name         string
+		caps         PlannerCapabilities
+		wantText     []string
+		wantWithheld []string
+	}{
+		{
+			name:         "StreamsAndCallsTools",
+			caps:         PlannerCapabilities{Streaming: true, ToolCalling: true},
+			wantWithheld: verifiedSearchTools,
+		},
+		{
+			// The orchestrator streams the reasoning itself and has no tools to withhold
+			name:     "AnswersOnly",
+			caps:     PlannerCapabilities{},
+			wantText: []string{"Two hotels near the Seine"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			planner := &MockPlanner{Caps: tt.caps}
+			var req PlanRequest
+			planner.On("Plan", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				req = args.Get(1).(PlanRequest)
+			}).Return(&PlanResult{
+				Reasoning:           "Two hotels near the Seine",
+				PossibleItineraries: []*pb.Itinerary{stayPlan("Left Bank", nil)},
+			}, nil).Once()
+
+			var text []string
+			ctx := tmcontext.WithTextSink(context.Background(), func(chunk string) { text = append(text, chunk) })
+			_, itineraries, err := NewTravelAgent(planner, passDesk{}).OrchestrateRequest(ctx, "Paris", "")
+			assert.NoError(t, err)
+			assert.Len(t, itineraries, 1)
+			assert.Equal(t, tt.wantText, text)
+			assert.Equal(t, tt.wantWithheld, req.WithoutTools)
+		})
+	}
+}
@@ -3,33 +3,75 @@ package agents
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 
+	tmcontext "github.com/va6996/travelingman/context"
+	tmcore "github.com/va6996/travelingman/core"
 	"github.com/va6996/travelingman/log"
 	"github.com/va6996/travelingman/pb"
 	"github.com/va6996/travelingman/plugins/amadeus"
 	"github.com/va6996/travelingman/plugins/core"
+	"google.golang.org/protobuf/proto"
 )
 
 // TravelDesk is responsible for checking availability and booking
 type TravelDesk struct {
 	amadeus *amadeus.Client
+
+	// Places locates airports for the feasibility check of flight times. Nil skips
+	// that part of the check.
+	Places *core.PlaceIndex
+
+	// Transports searches each transport leg with the provider of its type. Flights
+	// go to Amadeus unless another provider is registered.
+	Transports TransportProviders
 }
 
 // NewTravelDesk creates a new TravelDesk
 func NewTravelDesk(client *amadeus.Client) *TravelDesk {
-	return &TravelDesk{
+	td := &TravelDesk{
 		amadeus: client,
+		Places:  core.DefaultPlaceIndex(),
 	}
+	td.Transports = TransportProviders{pb.TransportType_TRANSPORT_TYPE_FLIGHT: amadeusFlights{td}}
+	return td
 }
 
 // CheckAvailability validates the itinerary against real availability
 func (td *TravelDesk) CheckAvailability(ctx context.Context, itinerary *pb.Itinerary) (*pb.Itinerary, error) {
-	log.Infof(ctx, "TravelDesk: Starting availability check for: %s", itinerary.Title)
+	log.Infof(ctx, "TravelDesk: Starting availability check for: %s", itinerary.GetTitle())
+
+	// Without a graph there is nothing to enrich or check; validation says what is wrong
+	if itinerary.GetGraph() == nil {
+		err := core.ValidateItinerary(ctx, itinerary)
+		log.Errorf(ctx, "TravelDesk: Initial validation failed: %v", err)
+		return nil, err
+	}
+
+	// A plan with nothing to book is useless however valid it is. What is malformed
+	// about it is reported first, as the graph may just be full of holes.
+	if err := core.CheckCompleteness(itinerary); err != nil {
+		if verr := core.ValidateItinerary(ctx, itinerary); verr != nil {
+			log.Errorf(ctx, "TravelDesk: Initial validation failed: %v", verr)
+			return nil, verr
+		}
+		log.Errorf(ctx, "TravelDesk: Completeness check failed: %v", err)
+		return nil, err
+	}
+
+	// Reject plans that cannot be flown before spending any provider calls on them
+	if err := core.CheckFeasibility(ctx, itinerary, td.Places); err != nil {
+		log.Errorf(ctx, "TravelDesk: Feasibility check failed: %v", err)
+		return nil, err
+	}
 
 	// Enrich graph first (resolve codes, set currencies)
 	td.EnrichGraph(ctx, itinerary)
 
+	// Split the budget, if any, into caps for the searches below
+	planBudget(ctx, itinerary)
+
 	// Validate Itinerary first
 	if err := core.ValidateItinerary(ctx, itinerary); err != nil {
 		log.Errorf(ctx, "TravelDesk: Initial validation failed: %v", err)
@@ -37,6 +79,14 @@ func (td *TravelDesk) CheckAvailability(ctx context.Context, itinerary *pb.Itine
 	}
 
 	td.checkRecursive(ctx, itinerary)
+	if note := rebalanceBudget(itinerary); note != "" {
+		log.Infof(ctx, "TravelDesk: %s", note)
+	}
+	// No plan of this trip fits a budget its cheapest options are over
+	if conflict := budgetConflict(itinerary); conflict != nil {
+		log.Warnf(ctx, "TravelDesk: Budget conflict: %v", conflict)
+		return nil, conflict
+	}
 	log.Infof(ctx, "TravelDesk: Finished check.")
 
 	return itinerary, nil
@@ -44,50 +94,27 @@ func (td *TravelDesk) CheckAvailability(ctx context.Context, itinerary *pb.Itine
 
 // EnrichGraph resolves missing city codes, names and ensures global currency
 func (td *TravelDesk) EnrichGraph(ctx context.Context, itinerary *pb.Itinerary) {
-	if itinerary.Graph == nil {
+	if itinerary.GetGraph() == nil {
 		return
 	}
 
-	globalCurrency := "USD"
-
-	// Apply global currency to all nodes and edges where missing
-	for _, edge := range itinerary.Graph.Edges {
-		if edge.Transport != nil {
-			if edge.Transport.Cost == nil {
-				edge.Transport.Cost = &pb.Cost{}
-			}
-			if edge.Transport.Cost.Currency == "" {
-				edge.Transport.Cost.Currency = globalCurrency
-			}
-		}
-	}
-	for _, node := range itinerary.Graph.Nodes {
-		if node.Stay != nil {
-			if node.Stay.Cost == nil {
-				node.Stay.Cost = &pb.Cost{}
-			}
-			if node.Stay.Cost.Currency == "" {
-				node.Stay.Cost.Currency = globalCurrency
-			}
-		}
-	}
+	applyGraphDefaults(itinerary.Graph)
 
 	// Enrich location information
 	for _, node := range itinerary.Graph.Nodes {
+		if node == nil {
+			continue
+		}
 		if node.Location != nil {
 			if err := td.enrichLocation(ctx, node.Location); err != nil {
 				log.Errorf(ctx, "TravelDesk: Location enrichment failed for %s: %v", node.Location, err)
 			}
 		}
 
-		if node.Stay == nil {
+		if node.Stay == nil || node.Stay.Location == nil {
 			continue
 		}
 
-		if node.Stay.Location == nil && node.Location != nil {
-			node.Stay.Location = node.Location
-		}
-
 		if err := td.enrichLocation(ctx, node.Stay.Location); err != nil {
 			log.Errorf(ctx, "TravelDesk: Location enrichment failed for %s: %v", node.Stay.Location, err)
 		}
@@ -95,7 +122,7 @@ func (td *TravelDesk) EnrichGraph(ctx context.Context, itinerary *pb.Itinerary)
 
 	// Enrich transport information
 	for _, edge := range itinerary.Graph.Edges {
-		if edge.Transport == nil || edge.Transport.OriginLocation == nil {
+		if edge.GetTransport().GetOriginLocation() == nil {
 			continue
 		}
 
@@ -117,6 +144,40 @@ func (td *TravelDesk) EnrichGraph(ctx context.Context, itinerary *pb.Itinerary)
 	}
 }
 
+// applyGraphDefaults fills what the planner may leave out without any provider lookups:
+// the global currency on every cost and the node location on stays without one
+func applyGraphDefaults(g *pb.Graph) {
+	if g == nil {
+		return
+	}
+	globalCurrency := "USD"
+
+	// Apply global currency to all nodes and edges where missing
+	for _, edge := range g.Edges {
+		if edge.GetTransport() != nil {
+			if edge.Transport.Cost == nil {
+				edge.Transport.Cost = &pb.Cost{}
+			}
+			if edge.Transport.Cost.Currency == "" {
+				edge.Transport.Cost.Currency = globalCurrency
+			}
+		}
+	}
+	for _, node := range g.Nodes {
+		if node.GetStay() != nil {
+			if node.Stay.Cost == nil {
+				node.Stay.Cost = &pb.Cost{}
+			}
+			if node.Stay.Cost.Currency == "" {
+				node.Stay.Cost.Currency = globalCurrency
+			}
+			if node.Stay.Location == nil && node.Location != nil {
+				node.Stay.Location = node.Location
+			}
+		}
+	}
+}
+
 func (td *TravelDesk) enrichLocation(ctx context.Context, loc *pb.Location) error {
 	keywords := []string{}
 
@@ -136,122 +197,198 @@ func (td *TravelDesk) enrichLocation(ctx context.Context, loc *pb.Location) erro
 			continue
 		}
 
-		location, err := td.amadeus.SearchLocations(ctx, keyword)
+		// An airport code the user gave is looked up as is; nearby airports only help
+		// with places that have none of their own
+		nearby := len(loc.IataCodes) == 0 || keyword != loc.IataCodes[0]
+
+		// Itineraries verified for the same request share lookups through the memo
+		location, err := tmcontext.LocationMemoFromContext(ctx).Resolve(ctx, locationMemoKey(keyword, nearby), func(ctx context.Context, _ string) ([]*pb.Location, error) {
+			return td.amadeus.SearchLocations(ctx, keyword, nearby)
+		})
 		if err != nil {
 			log.Warnf(ctx, "TravelDesk: Location search failed for '%s': %v. Trying next fallback.", keyword, err)
 			continue
 		}
 
-		if len(location) > 0 {
-			// Found a match, populate and return
-			bestMatch := location[0]
-			maxScore := -1
-
-			for _, l := range location {
-				score := 0
-
-				// Check IATA codes
-				for _, code := range loc.IataCodes {
-					for _, candidateCode := range l.IataCodes {
-						if code == candidateCode {
-							score += 5
-							break
-						}
-					}
-				}
+		// Found a match, populate and return
+		if bestMatch := bestLocationMatch(loc, location); bestMatch != nil {
+			applyLocationMatch(loc, bestMatch)
+			return nil
+		}
+	}
 
-				// Check City Code
-				if loc.CityCode != "" && l.CityCode == loc.CityCode {
-					score += 4
-				}
+	log.Warnf(ctx, "TravelDesk: Could not enrich location for %v", loc)
+	return nil // Not strictly an error, just failed to enrich
+}
 
-				// Check City Name
-				if loc.City != "" {
-					if strings.EqualFold(l.City, loc.City) {
-						score += 3
-					} else if strings.Contains(strings.ToLower(l.City), strings.ToLower(loc.City)) {
-						score += 1
-					}
-				}
+// locationMemoKey is what a location search is remembered by. A keyword searched with
+// nearby airports finds more than the same keyword searched as is, so the two are kept
+// apart.
+func locationMemoKey(keyword string, nearby bool) string {
+	if nearby {
+		return keyword + "|nearby"
+	}
+	return keyword
+}
 
-				// Check Country
-				if loc.Country != "" && strings.EqualFold(l.Country, loc.Country) {
-					score += 2
-				}
+// applyLocationMatch fills loc in from match without losing what loc already had: the
+// codes the user gave stay first, so they are still the ones searched
+func applyLocationMatch(loc, match *pb.Location) {
+	if match.City != "" {
+		loc.City = match.City
+	}
+	if match.Country != "" {
+		loc.Country = match.Country
+	}
+	if match.CityCode != "" {
+		loc.CityCode = match.CityCode
+	}
+	for _, code := range match.IataCodes {
+		if code != "" && !slices.Contains(loc.IataCodes, code) {
+			loc.IataCodes = append(loc.IataCodes, code)
+		}
+	}
+	if lat, lng, ok := tmcore.ParseGeocode(match.Geocode); loc.Geocode == "" && ok && (lat != 0 || lng != 0) {
+		loc.Geocode = match.Geocode
+	}
+}
 
-				// Prefer results with City populated
-				if l.City != "" {
-					score += 1
-				}
+// bestLocationMatch picks the candidate that best matches what is already known about
+// loc, or nil if none matches its codes or city name. A candidate with one of loc's
+// IATA codes is taken outright. Otherwise loc's country, when given, outweighs the
+// city name, so a name shared by cities in several countries ("San Jose") resolves to
+// the one in that country.
+func bestLocationMatch(loc *pb.Location, candidates []*pb.Location) *pb.Location {
+	for _, l := range candidates {
+		for _, code := range loc.IataCodes {
+			if slices.Contains(l.IataCodes, code) {
+				return l
+			}
+		}
+	}
 
-				if score > maxScore {
-					maxScore = score
-					bestMatch = l
-				}
+	var bestMatch *pb.Location
+	maxScore := 0
+	for _, l := range candidates {
+		score := 0
+
+		// Check City Code
+		if loc.CityCode != "" && l.CityCode == loc.CityCode {
+			score += 4
+		}
+
+		// Check City Name
+		if loc.City != "" {
+			if strings.EqualFold(l.City, loc.City) {
+				score += 3
+			} else if strings.Contains(strings.ToLower(l.City), strings.ToLower(loc.City)) {
+				score += 1
 			}
+		}
 
-			loc.City = bestMatch.City
-			loc.Country = bestMatch.Country
-			loc.CityCode = bestMatch.CityCode
-			loc.IataCodes = bestMatch.IataCodes
-			return nil
+		// Anything else found for the keyword is not this location
+		if score == 0 {
+			continue
+		}
+
+		// Check Country, a city in another country is the wrong one whatever its name
+		if same, ok := sameCountry(l.Country, loc.Country); ok && same {
+			score += 6
+		} else if ok {
+			score -= 6
+		}
+
+		// Prefer results with City populated
+		if l.City != "" {
+			score += 1
+		}
+
+		if score > maxScore {
+			maxScore = score
+			bestMatch = l
 		}
 	}
+	return bestMatch
+}
 
-	log.Warnf(ctx, "TravelDesk: Could not enrich location for %v", loc)
-	return nil // Not strictly an error, just failed to enrich
+// countryAliases map the short and informal names of countries to the names Amadeus
+// gives them, e.g. "UK" to "UNITED KINGDOM"
+var countryAliases = map[string]string{
+	"UK": "UNITED KINGDOM", "GB": "UNITED KINGDOM", "GREAT BRITAIN": "UNITED KINGDOM", "BRITAIN": "UNITED KINGDOM", "ENGLAND": "UNITED KINGDOM",
+	"US": "UNITED STATES OF AMERICA", "USA": "UNITED STATES OF AMERICA", "UNITED STATES": "UNITED STATES OF AMERICA", "AMERICA": "UNITED STATES OF AMERICA",
+	"UAE": "UNITED ARAB EMIRATES", "AE": "UNITED ARAB EMIRATES",
+	"FR": "FRANCE", "DE": "GERMANY", "ES": "SPAIN", "IT": "ITALY", "NL": "NETHERLANDS", "THE NETHERLANDS": "NETHERLANDS", "HOLLAND": "NETHERLANDS",
+	"PT": "PORTUGAL", "IE": "IRELAND", "CH": "SWITZERLAND", "JP": "JAPAN", "CN": "CHINA", "IN": "INDIA", "CA": "CANADA", "MX": "MEXICO",
+	"AU": "AUSTRALIA", "NZ": "NEW ZEALAND", "SG": "SINGAPORE", "CR": "COSTA RICA", "BR": "BRAZIL", "KR": "KOREA, REPUBLIC OF", "SOUTH KOREA": "KOREA, REPUBLIC OF",
+}
+
+// sameCountry reports whether two country names or codes name the same country. ok is
+// false when either is empty, or is a code that cannot be compared with a name.
+func sameCountry(a, b string) (same, ok bool) {
+	a, b = strings.ToUpper(strings.TrimSpace(a)), strings.ToUpper(strings.TrimSpace(b))
+	if a == "" || b == "" {
+		return false, false
+	}
+	if alias, found := countryAliases[a]; found {
+		a = alias
+	}
+	if alias, found := countryAliases[b]; found {
+		b = alias
+	}
+	if a == b {
+		return true, true
+	}
+	// An unknown code may still be the other's country
+	if len(a) <= 3 || len(b) <= 3 {
+		return false, false
+	}
+	return false, true
 }
 
 func (td *TravelDesk) checkRecursive(ctx context.Context, itinerary *pb.Itinerary) {
-	if itinerary.Graph == nil {
+	if itinerary.GetGraph() == nil {
 		return
 	}
 
-	// 1. Check Flights (Edges)
+	// 1. Check Transports (Edges) with the provider of their type
 	for _, edge := range itinerary.Graph.Edges {
-		if t := edge.Transport; t != nil {
-			if t.Type == pb.TransportType_TRANSPORT_TYPE_FLIGHT {
-				if flight := t.GetFlight(); flight != nil {
-					log.Debugf(ctx, "TravelDesk: Checking flights on %s", flight.DepartureTime.AsTime().Format("2006-01-02"))
-
-					// SearchFlights handles location extraction internally
-					transports, err := td.amadeus.SearchFlights(ctx, t)
-
-					if err != nil {
-						errMsg := fmt.Sprintf("Flight search failed: %s", err)
-						log.Errorf(ctx, "TravelDesk: ISSUE: %s", errMsg)
-						t.Error = &pb.Error{
-							Message:  errMsg,
-							Code:     td.amadeus.MapError(err),
-							Severity: pb.ErrorSeverity_ERROR_SEVERITY_ERROR,
-						}
-					} else if len(transports) > 0 {
-						// Collect ALL flight options
-						edge.TransportOptions = transports
-						log.Infof(ctx, "TravelDesk: Found %d flight options", len(transports))
-					} else {
-						// ... existing error handling ...
-						errMsg := fmt.Sprintf("No flights found for %s on %s", t.OriginLocation.IataCodes, flight.DepartureTime.AsTime().Format("2006-01-02"))
-						log.Errorf(ctx, "TravelDesk: ISSUE: %s", errMsg)
-						t.Error = &pb.Error{
-							Message:  errMsg,
-							Code:     pb.ErrorCode_ERROR_CODE_DATA_NOT_FOUND,
-							Severity: pb.ErrorSeverity_ERROR_SEVERITY_ERROR,
-						}
-					}
-				}
-			}
+		t := edge.GetTransport()
+		if t == nil {
+			continue
+		}
+		provider := td.Transports[t.Type]
+		if provider == nil {
+			continue
+		}
+		// Flights can only be searched once the planner gave them a date
+		if t.Type == pb.TransportType_TRANSPORT_TYPE_FLIGHT && t.GetFlight() == nil {
+			continue
 		}
+		td.checkTransport(ctx, edge, provider)
 	}
 
 	// 2. Check Hotels (Nodes)
 	for _, node := range itinerary.Graph.Nodes {
-		if acc := node.Stay; acc != nil {
+		if acc := node.GetStay(); acc != nil {
+			// Sub-graphs are not validated, so a stay there may have nowhere to search
+			if acc.Location == nil {
+				acc.Error = &pb.Error{
+					Message:  "Stay has no location to search hotels in",
+					Code:     pb.ErrorCode_ERROR_CODE_INVALID_INPUT,
+					Severity: pb.ErrorSeverity_ERROR_SEVERITY_ERROR,
+				}
+				continue
+			}
 			log.Debugf(ctx, "TravelDesk: Checking hotels in city %s", acc.Location.City)
 
 			// Direct API Flow:
-			// A. Search hotels by city to            // Use preferences
-			listResp, err := td.amadeus.SearchHotelsByCity(ctx, acc)
+			// A. Search hotels by city, loosening the preferences if nothing matches them
+			listResp, relaxation, err := td.amadeus.SearchHotelsByCityRelaxed(ctx, acc)
+			var coverage *amadeus.Coverage
+			if err == nil && len(listResp.Data) == 0 {
+				// Small places are often listed under a bigger city nearby
+				listResp, relaxation, coverage, err = td.amadeus.SearchHotelsNearby(ctx, acc)
+			}
 			if err != nil {
 				errMsg := fmt.Sprintf("Hotel city search failed for %s: %s", acc.Location.City, err)
 				log.Errorf(ctx, "TravelDesk: ISSUE: %s", errMsg)
@@ -274,18 +411,9 @@ func (td *TravelDesk) checkRecursive(ctx context.Context, itinerary *pb.Itinerar
 				continue
 			}
 
-			// B. Pick top hotels to check for offers
-			var hotelIds []string
-			limit := td.amadeus.Config.HotelLimit
-
-			count := 0
-			for _, hotel := range listResp.Data {
-				if count >= limit {
-					break
-				}
-				hotelIds = append(hotelIds, hotel.HotelId)
-				count++
-			}
+			// B. Pick hotels to check for offers from across the top of the list
+			_, limit := td.amadeus.Limits()
+			hotelIds := pickHotels(listResp.Data, td.amadeus.HotelListLimit(), limit)
 
 			// C. Search offers for these hotels
 
@@ -301,7 +429,7 @@ func (td *TravelDesk) checkRecursive(ctx context.Context, itinerary *pb.Itinerar
 			}
 
 			log.Debugf(ctx, "TravelDesk: Checking offers for %d hotels for %d adults...", len(hotelIds), adults)
-			accommodations, err := td.amadeus.SearchHotelOffers(ctx, hotelIds, acc)
+			accommodations, err := td.searchHotelOffers(ctx, hotelIds, acc)
 			if err != nil {
 				// SearchHotelOffers might error if none available or API error
 				errMsg := fmt.Sprintf("Hotel offers search failed: %s", err)
@@ -315,15 +443,32 @@ func (td *TravelDesk) checkRecursive(ctx context.Context, itinerary *pb.Itinerar
 				continue
 			} else if len(accommodations) > 0 {
 				node.StayOptions = accommodations
+				var notes []string
+				if coverage != nil {
+					notes = append(notes, coverage.Message())
+				}
+				if relaxation != nil {
+					notes = append(notes, relaxation.Message())
+				}
+				if len(notes) > 0 {
+					// The selected option replaces the stay, so every option carries the warning
+					msg := strings.Join(notes, "; ")
+					log.Infof(ctx, "TravelDesk: %s: %s", acc.Location.City, msg)
+					acc.Error = relaxationWarning(acc.Error, msg)
+					for _, opt := range accommodations {
+						opt.Error = relaxationWarning(opt.Error, msg)
+					}
+				}
 
-				log.Infof(ctx, "TravelDesk: Found %d hotel options", len(accommodations))
+				log.Debugf(ctx, "TravelDesk: Found %d hotel options", len(accommodations))
 			} else {
-				// No data returned
+				// The city has hotels, just none free on these dates
 				acc.Status = "NO_OFFERS"
-				errMsg := fmt.Sprintf("No hotel offers found in %s", acc.Location.City)
+				errMsg := fmt.Sprintf("No hotel offers found in %s for %s to %s", acc.Location.City,
+					acc.GetCheckIn().AsTime().Format("2006-01-02"), acc.GetCheckOut().AsTime().Format("2006-01-02"))
 				acc.Error = &pb.Error{
 					Message:  errMsg,
-					Code:     pb.ErrorCode_ERROR_CODE_DATA_NOT_FOUND,
+					Code:     pb.ErrorCode_ERROR_CODE_NO_AVAILABILITY,
 					Severity: pb.ErrorSeverity_ERROR_SEVERITY_ERROR,
 				}
 				log.Infof(ctx, "TravelDesk: %s", errMsg)
@@ -337,3 +482,96 @@ func (td *TravelDesk) checkRecursive(ctx context.Context, itinerary *pb.Itinerar
 		td.checkRecursive(ctx, subItin)
 	}
 }
+
+// checkTransport searches options for an edge's transport with provider and sets them
+// on the edge, or sets an error on the transport if there are none
+func (td *TravelDesk) checkTransport(ctx context.Context, edge *pb.Edge, provider TransportSearcher) {
+	t := edge.Transport
+	label := transportLabel(t.Type)
+	day := "an unknown date"
+	if dep, _, ok := legTimes(edge); ok {
+		day = dep.Format("2006-01-02")
+	}
+	log.Debugf(ctx, "TravelDesk: Checking %s options on %s", strings.ToLower(label), day)
+
+	transports, err := provider.SearchTransport(ctx, t)
+	if err != nil {
+		errMsg := fmt.Sprintf("%s search failed: %s", label, err)
+		log.Errorf(ctx, "TravelDesk: ISSUE: %s", errMsg)
+		t.Error = &pb.Error{
+			Message:  errMsg,
+			Code:     errorCode(provider, err),
+			Severity: pb.ErrorSeverity_ERROR_SEVERITY_ERROR,
+		}
+		return
+	}
+	if len(transports) == 0 {
+		errMsg := fmt.Sprintf("No %s options found for %s on %s", strings.ToLower(label), t.GetOriginLocation().GetIataCodes(), day)
+		code := pb.ErrorCode_ERROR_CODE_DATA_NOT_FOUND
+		if t.Type == pb.TransportType_TRANSPORT_TYPE_FLIGHT {
+			errMsg = fmt.Sprintf("No flights found for %s on %s", t.GetOriginLocation().GetIataCodes(), day)
+			// The route is flown on other days, so the date is what has no availability
+			if nearby := similarDates(ctx, t, provider); nearby != "" {
+				errMsg += ", but " + nearby
+				code = pb.ErrorCode_ERROR_CODE_NO_AVAILABILITY
+			}
+		}
+		log.Errorf(ctx, "TravelDesk: ISSUE: %s", errMsg)
+		t.Error = &pb.Error{
+			Message:  errMsg,
+			Code:     code,
+			Severity: pb.ErrorSeverity_ERROR_SEVERITY_ERROR,
+		}
+		return
+	}
+
+	// Collect ALL options
+	edge.TransportOptions = transports
+	log.Debugf(ctx, "TravelDesk: Found %d %s options", len(transports), strings.ToLower(label))
+}
+
+// searchFlights searches for flights within the budget cap, if there is one. The cap is
+// a soft filter: when no flight is within it, the search is repeated without it.
+func (td *TravelDesk) searchFlights(ctx context.Context, t *pb.Transport) ([]*pb.Transport, error) {
+	transports, err := td.amadeus.SearchFlights(ctx, t)
+	maxPrice := t.GetFlightPreferences().GetMaxPrice()
+	if err != nil || len(transports) > 0 || maxPrice <= 0 {
+		return transports, err
+	}
+
+	log.Infof(ctx, "TravelDesk: No flights within the budget cap of %.2f %s, searching without it", maxPrice, t.GetCost().GetCurrency())
+	uncapped := proto.Clone(t).(*pb.Transport)
+	uncapped.FlightPreferences.MaxPrice = 0
+	return td.amadeus.SearchFlights(ctx, uncapped)
+}
+
+// searchHotelOffers searches for offers within the nightly budget cap, if there is one.
+// The cap is a soft filter: when no offer is within it, the search is repeated without it.
+func (td *TravelDesk) searchHotelOffers(ctx context.Context, hotelIds []string, acc *pb.Accommodation) ([]*pb.Accommodation, error) {
+	accommodations, err := td.amadeus.SearchHotelOffers(ctx, hotelIds, acc)
+	maxNightly := acc.GetPreferences().GetMaxNightlyPrice()
+	if err != nil || len(accommodations) > 0 || maxNightly <= 0 {
+		return accommodations, err
+	}
+
+	log.Infof(ctx, "TravelDesk: No hotel offers within the budget cap of %.2f %s a night, searching without it", maxNightly, acc.GetCost().GetCurrency())
+	uncapped := proto.Clone(acc).(*pb.Accommodation)
+	uncapped.Preferences.MaxNightlyPrice = 0
+	return td.amadeus.SearchHotelOffers(ctx, hotelIds, uncapped)
+}
+
+// relaxationWarning returns a warning that the hotel search was widened, keeping
+// any provider note already on the option. A warning or error already there, such as
+// a deposit requirement, keeps its severity and code and gets the note appended.
+func relaxationWarning(existing *pb.Error, msg string) *pb.Error {
+	if existing.GetSeverity() >= pb.ErrorSeverity_ERROR_SEVERITY_WARNING {
+		if strings.Contains(existing.Message, msg) {
+			return existing
+		}
+		return &pb.Error{Message: existing.Message + "; " + msg, Code: existing.Code, Severity: existing.Severity}
+	}
+	if existing.GetMessage() != "" {
+		msg += "; " + existing.Message
+	}
+	return &pb.Error{Message: msg, Severity: pb.ErrorSeverity_ERROR_SEVERITY_WARNING}
+}
@@ -6,12 +6,16 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	tmcontext "github.com/va6996/travelingman/context"
 	"github.com/va6996/travelingman/pb"
 	"github.com/va6996/travelingman/plugins/amadeus"
+	"github.com/va6996/travelingman/plugins/core"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -186,7 +190,11 @@ func TestTravelDesk_CheckAvailability_NoAvailability(t *testing.T) {
 		Graph: &pb.Graph{
 			Nodes: []*pb.Node{
 				{Id: "n1", Location: &pb.Location{IataCodes: []string{"LHR"}}},
-				{Id: "n2", Location: &pb.Location{IataCodes: []string{"JFK"}}, Stay: &pb.Accommodation{CheckIn: timestamppb.Now(), CheckOut: timestamppb.Now()}},
+				{Id: "n2", Location: &pb.Location{City: "New York", IataCodes: []string{"JFK"}}, Stay: &pb.Accommodation{
+					TravelerCount: 1,
+					CheckIn:       timestamppb.New(time.Now().Add(30 * time.Hour)),
+					CheckOut:      timestamppb.New(time.Now().Add(72 * time.Hour)),
+				}},
 			},
 			Edges: []*pb.Edge{{
 				FromId: "n1",
@@ -218,9 +226,773 @@ func TestTravelDesk_CheckAvailability_NoAvailability(t *testing.T) {
 	assert.NotEmpty(t, updatedItin.Graph.Nodes)
 	assert.Greater(t, len(updatedItin.Graph.Nodes), 1)
 
-	// When no hotels are available, Stay should still exist with an error
+	// When the city has no hotels, Stay should still exist with an error
 	if updatedItin.Graph.Nodes[1].Stay != nil {
 		assert.NotNil(t, updatedItin.Graph.Nodes[1].Stay.Error)
 		assert.Equal(t, pb.ErrorCode_ERROR_CODE_DATA_NOT_FOUND, updatedItin.Graph.Nodes[1].Stay.Error.Code)
 	}
 }
+
+func TestTravelDesk_CheckAvailability_NoOffersOnDates(t *testing.T) {
+	// The city has hotels, but none of them has a room on the requested dates
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/security/oauth2/token":
+			json.NewEncoder(w).Encode(amadeus.AuthToken{AccessToken: "token", ExpiresIn: 1800})
+		case "/v1/reference-data/locations/hotels/by-city":
+			json.NewEncoder(w).Encode(amadeus.HotelListResponse{Data: []amadeus.HotelData{{HotelId: "NYC1", Name: "Hotel NYC1"}}})
+		case "/v3/shopping/hotel-offers":
+			json.NewEncoder(w).Encode(amadeus.HotelSearchResponse{Data: []amadeus.HotelOfferData{}})
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer ts.Close()
+
+	client, _ := amadeus.NewClient(amadeus.Config{
+		ClientID: "id", ClientSecret: "secret", IsProduction: false,
+		FlightLimit: 10, HotelLimit: 10, Timeout: 30,
+		CacheTTL: amadeus.CacheTTLConfig{Location: 24, Flight: 24, Hotel: 24},
+	}, nil, nil, nil)
+	client.BaseURL = ts.URL
+	desk := NewTravelDesk(client)
+
+	checkIn := time.Now().AddDate(0, 1, 0).UTC().Truncate(24 * time.Hour).Add(15 * time.Hour)
+	itin := &pb.Itinerary{
+		Title:       "No Offers Test",
+		StartTime:   timestamppb.New(checkIn),
+		EndTime:     timestamppb.New(checkIn.Add(48 * time.Hour)),
+		Travelers:   1,
+		JourneyType: pb.JourneyType_JOURNEY_TYPE_ONE_WAY,
+		Graph: &pb.Graph{Nodes: []*pb.Node{{
+			Id:       "nyc",
+			Location: &pb.Location{City: "New York", CityCode: "NYC"},
+			Stay: &pb.Accommodation{
+				TravelerCount: 1,
+				CheckIn:       timestamppb.New(checkIn),
+				CheckOut:      timestamppb.New(checkIn.Add(44 * time.Hour)),
+			},
+		}}},
+	}
+
+	updatedItin, err := desk.CheckAvailability(context.Background(), itin)
+	if !assert.NoError(t, err) {
+		return
+	}
+	stay := updatedItin.Graph.Nodes[0].Stay
+	if assert.NotNil(t, stay.Error) {
+		assert.Equal(t, pb.ErrorCode_ERROR_CODE_NO_AVAILABILITY, stay.Error.Code)
+		want := fmt.Sprintf("No hotel offers found in New York for %s to %s",
+			checkIn.Format("2006-01-02"), checkIn.Add(44*time.Hour).Format("2006-01-02"))
+		assert.Equal(t, want, stay.Error.Message)
+	}
+}
+
+func TestTravelDesk_CheckAvailability_RelaxedHotelSearch(t *testing.T) {
+	// Nothing matches the amenity filter; hotels are found once it is dropped
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/security/oauth2/token":
+			json.NewEncoder(w).Encode(amadeus.AuthToken{AccessToken: "token", ExpiresIn: 1800})
+		case "/v2/shopping/flight-offers":
+			json.NewEncoder(w).Encode(amadeus.FlightSearchResponse{Data: []amadeus.FlightOffer{}})
+		case "/v1/reference-data/locations/hotels/by-city":
+			resp := amadeus.HotelListResponse{Data: []amadeus.HotelData{}}
+			if r.URL.Query().Get("amenities") == "" {
+				resp.Data = append(resp.Data, amadeus.HotelData{HotelId: "H1", Name: "Test Hotel"})
+			}
+			json.NewEncoder(w).Encode(resp)
+		case "/v3/shopping/hotel-offers":
+			json.NewEncoder(w).Encode(amadeus.HotelSearchResponse{
+				Data: []amadeus.HotelOfferData{{
+					Available: true,
+					Hotel:     amadeus.HotelInfo{HotelId: "H1", Name: "Test Hotel", CityCode: "NYC"},
+					Offers: []amadeus.HotelOffer{{
+						ID:     "offer1",
+						Price:  amadeus.HotelPrice{Total: "500.00"},
+						Guests: amadeus.HotelGuests{Adults: 1},
+					}},
+				}},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client, _ := amadeus.NewClient(amadeus.Config{
+		ClientID: "id", ClientSecret: "secret", IsProduction: false,
+		FlightLimit: 10, HotelLimit: 10, Timeout: 30,
+		HotelRelaxation: amadeus.HotelRelaxationConfig{Steps: []string{amadeus.RelaxAmenities, amadeus.RelaxRating}, MinRating: 1},
+		CacheTTL:        amadeus.CacheTTLConfig{Location: 24, Flight: 24, Hotel: 24},
+	}, nil, nil, nil)
+	client.BaseURL = ts.URL
+	desk := NewTravelDesk(client)
+
+	start := time.Now().AddDate(0, 1, 0).UTC().Truncate(time.Hour)
+	itin := &pb.Itinerary{
+		Title:       "Relaxed Hotel Test",
+		StartTime:   timestamppb.New(start),
+		EndTime:     timestamppb.New(start.Add(72 * time.Hour)),
+		Travelers:   1,
+		JourneyType: pb.JourneyType_JOURNEY_TYPE_ONE_WAY,
+		Graph: &pb.Graph{
+			Nodes: []*pb.Node{
+				{Id: "n1", Location: &pb.Location{IataCodes: []string{"LHR"}}},
+				{Id: "n2", Location: &pb.Location{City: "New York", IataCodes: []string{"JFK"}}, Stay: &pb.Accommodation{
+					TravelerCount: 1,
+					CheckIn:       timestamppb.New(start.Add(6 * time.Hour)),
+					CheckOut:      timestamppb.New(start.Add(72 * time.Hour)),
+					Preferences:   &pb.AccommodationPreferences{Rating: 4, Amenities: []string{"SPA"}},
+				}},
+			},
+			Edges: []*pb.Edge{{
+				FromId: "n1",
+				ToId:   "n2",
+				Transport: &pb.Transport{
+					Type:                pb.TransportType_TRANSPORT_TYPE_FLIGHT,
+					OriginLocation:      &pb.Location{IataCodes: []string{"LHR"}},
+					DestinationLocation: &pb.Location{IataCodes: []string{"JFK"}},
+					TravelerCount:       1,
+					Details:             &pb.Transport_Flight{Flight: &pb.Flight{DepartureTime: timestamppb.New(start)}},
+				},
+			}},
+		},
+	}
+
+	updatedItin, err := desk.CheckAvailability(context.Background(), itin)
+	assert.NoError(t, err)
+
+	node := updatedItin.Graph.Nodes[1]
+	if assert.NotNil(t, node.Stay.Error) {
+		assert.Equal(t, pb.ErrorSeverity_ERROR_SEVERITY_WARNING, node.Stay.Error.Severity)
+		assert.Equal(t, "no 4-star hotels with spa found; showing 4-star options", node.Stay.Error.Message)
+	}
+	if assert.NotEmpty(t, node.StayOptions) {
+		assert.Equal(t, node.Stay.Error.GetMessage(), node.StayOptions[0].Error.GetMessage(), "options carry the warning too")
+	}
+}
+
+// checkListedHotels runs availability for a one-night-stay itinerary whose city lists
+// the given hotels, and returns the hotel IDs of each offers request and the stay node
+func checkListedHotels(t *testing.T, hotels []amadeus.HotelData, hotelLimit, hotelListLimit int) ([]string, *pb.Node) {
+	var offerIDs []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/security/oauth2/token":
+			json.NewEncoder(w).Encode(amadeus.AuthToken{AccessToken: "token", ExpiresIn: 1800})
+		case "/v2/shopping/flight-offers":
+			json.NewEncoder(w).Encode(amadeus.FlightSearchResponse{Data: []amadeus.FlightOffer{}})
+		case "/v1/reference-data/locations/hotels/by-city":
+			json.NewEncoder(w).Encode(amadeus.HotelListResponse{Data: hotels})
+		case "/v3/shopping/hotel-offers":
+			offerIDs = append(offerIDs, r.URL.Query().Get("hotelIds"))
+			var data []amadeus.HotelOfferData
+			for i, id := range strings.Split(r.URL.Query().Get("hotelIds"), ",") {
+				data = append(data, amadeus.HotelOfferData{
+					Available: true,
+					Hotel:     amadeus.HotelInfo{HotelId: id, Name: "Hotel " + id, CityCode: "NYC"},
+					Offers: []amadeus.HotelOffer{{
+						ID:     "offer_" + id,
+						Price:  amadeus.HotelPrice{Total: fmt.Sprintf("%d.00", 300+100*i)},
+						Guests: amadeus.HotelGuests{Adults: 1},
+					}},
+				})
+			}
+			json.NewEncoder(w).Encode(amadeus.HotelSearchResponse{Data: data})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client, _ := amadeus.NewClient(amadeus.Config{
+		ClientID: "id", ClientSecret: "secret", IsProduction: false,
+		FlightLimit: 10, HotelLimit: hotelLimit, HotelListLimit: hotelListLimit, Timeout: 30,
+		CacheTTL: amadeus.CacheTTLConfig{Location: 24, Flight: 24, Hotel: 24},
+	}, nil, nil, nil)
+	client.BaseURL = ts.URL
+	desk := NewTravelDesk(client)
+
+	start := time.Now().AddDate(0, 1, 0).UTC().Truncate(time.Hour)
+	itin := &pb.Itinerary{
+		Title:       "Hotel List Test",
+		StartTime:   timestamppb.New(start),
+		EndTime:     timestamppb.New(start.Add(72 * time.Hour)),
+		Travelers:   1,
+		JourneyType: pb.JourneyType_JOURNEY_TYPE_ONE_WAY,
+		Graph: &pb.Graph{
+			Nodes: []*pb.Node{
+				{Id: "n1", Location: &pb.Location{IataCodes: []string{"LHR"}}},
+				{Id: "n2", Location: &pb.Location{City: "New York", IataCodes: []string{"JFK"}}, Stay: &pb.Accommodation{
+					TravelerCount: 1,
+					CheckIn:       timestamppb.New(start.Add(6 * time.Hour)),
+					CheckOut:      timestamppb.New(start.Add(72 * time.Hour)),
+				}},
+			},
+			Edges: []*pb.Edge{{
+				FromId: "n1",
+				ToId:   "n2",
+				Transport: &pb.Transport{
+					Type:                pb.TransportType_TRANSPORT_TYPE_FLIGHT,
+					OriginLocation:      &pb.Location{IataCodes: []string{"LHR"}},
+					DestinationLocation: &pb.Location{IataCodes: []string{"JFK"}},
+					TravelerCount:       1,
+					Details:             &pb.Transport_Flight{Flight: &pb.Flight{DepartureTime: timestamppb.New(start)}},
+				},
+			}},
+		},
+	}
+
+	updatedItin, err := desk.CheckAvailability(context.Background(), itin)
+	assert.NoError(t, err)
+	return offerIDs, updatedItin.Graph.Nodes[1]
+}
+
+func TestTravelDesk_CheckAvailability_HotelListThenOffers(t *testing.T) {
+	// The city search only lists hotels; their offers must be fetched to produce options
+	offerIDs, node := checkListedHotels(t, []amadeus.HotelData{
+		{HotelId: "H1", Name: "First Hotel"},
+		{HotelId: "H2", Name: "Second Hotel"},
+		{HotelId: "H3", Name: "Third Hotel"},
+	}, 2, 0)
+
+	// Offers are fetched for the listed hotels, up to the hotel limit, and attached
+	assert.Equal(t, []string{"H1,H2"}, offerIDs)
+	assert.Nil(t, node.Stay.Error)
+	if assert.Len(t, node.StayOptions, 2) {
+		assert.Equal(t, "Hotel H1", node.StayOptions[0].Name)
+		assert.Equal(t, "H1", node.StayOptions[0].HotelId)
+		assert.Equal(t, 300.0, node.StayOptions[0].Cost.GetValue())
+		assert.Equal(t, "Hotel H2", node.StayOptions[1].Name)
+	}
+}
+
+func TestTravelDesk_CheckAvailability_HotelListLimit(t *testing.T) {
+	var hotels []amadeus.HotelData
+	for i := 1; i <= 6; i++ {
+		hotels = append(hotels, amadeus.HotelData{HotelId: fmt.Sprintf("H%d", i)})
+	}
+
+	// With a larger list limit, hotels further down the list are priced too
+	offerIDs, node := checkListedHotels(t, hotels, 2, 6)
+	assert.Equal(t, []string{"H1,H4"}, offerIDs)
+	assert.Len(t, node.StayOptions, 2)
+
+	// Without one, only the top of the list is
+	offerIDs, _ = checkListedHotels(t, hotels, 2, 0)
+	assert.Equal(t, []string{"H1,H2"}, offerIDs)
+}
+
+func TestTravelDesk_CheckAvailability_BudgetCaps(t *testing.T) {
+	var flightQueries, offerQueries []url.Values
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/security/oauth2/token":
+			json.NewEncoder(w).Encode(amadeus.AuthToken{AccessToken: "token", ExpiresIn: 1800})
+		case "/v2/shopping/flight-offers":
+			flightQueries = append(flightQueries, r.URL.Query())
+			json.NewEncoder(w).Encode(amadeus.FlightSearchResponse{Data: []amadeus.FlightOffer{}})
+		case "/v1/reference-data/locations/hotels/by-city":
+			json.NewEncoder(w).Encode(amadeus.HotelListResponse{Data: []amadeus.HotelData{{HotelId: "H1", Name: "Test Hotel"}}})
+		case "/v3/shopping/hotel-offers":
+			offerQueries = append(offerQueries, r.URL.Query())
+			json.NewEncoder(w).Encode(amadeus.HotelSearchResponse{
+				Data: []amadeus.HotelOfferData{{
+					Available: true,
+					Hotel:     amadeus.HotelInfo{HotelId: "H1", Name: "Test Hotel", CityCode: "NYC"},
+					Offers: []amadeus.HotelOffer{{
+						ID:     "offer1",
+						Price:  amadeus.HotelPrice{Total: "450.00"},
+						Guests: amadeus.HotelGuests{Adults: 1},
+					}},
+				}},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client, _ := amadeus.NewClient(amadeus.Config{
+		ClientID: "id", ClientSecret: "secret", IsProduction: false,
+		FlightLimit: 10, HotelLimit: 10, Timeout: 30,
+		CacheTTL: amadeus.CacheTTLConfig{Location: 24, Flight: 24, Hotel: 24},
+	}, nil, nil, nil)
+	client.BaseURL = ts.URL
+	desk := NewTravelDesk(client)
+
+	start := time.Date(time.Now().Year()+1, 6, 1, 8, 0, 0, 0, time.UTC)
+	itin := &pb.Itinerary{
+		Title:       "Budget Test",
+		StartTime:   timestamppb.New(start),
+		EndTime:     timestamppb.New(start.Add(74 * time.Hour)),
+		Travelers:   1,
+		JourneyType: pb.JourneyType_JOURNEY_TYPE_ONE_WAY,
+		Budget:      &pb.Cost{Value: 1000, Currency: "USD"},
+		Graph: &pb.Graph{
+			Nodes: []*pb.Node{
+				{Id: "n1", Location: &pb.Location{IataCodes: []string{"LHR"}}},
+				{Id: "n2", Location: &pb.Location{City: "New York", IataCodes: []string{"JFK"}}, Stay: &pb.Accommodation{
+					TravelerCount: 1,
+					CheckIn:       timestamppb.New(start.Add(6 * time.Hour)),
+					CheckOut:      timestamppb.New(start.Add(74 * time.Hour)),
+				}},
+			},
+			Edges: []*pb.Edge{{
+				FromId: "n1",
+				ToId:   "n2",
+				Transport: &pb.Transport{
+					Type:                pb.TransportType_TRANSPORT_TYPE_FLIGHT,
+					OriginLocation:      &pb.Location{IataCodes: []string{"LHR"}},
+					DestinationLocation: &pb.Location{IataCodes: []string{"JFK"}},
+					TravelerCount:       1,
+					Details:             &pb.Transport_Flight{Flight: &pb.Flight{DepartureTime: timestamppb.New(start)}},
+				},
+			}},
+		},
+	}
+
+	updatedItin, err := desk.CheckAvailability(context.Background(), itin)
+	assert.NoError(t, err)
+
+	// 1 leg and 3 nights: the 900 left after the buffer splits 369/531
+	split := updatedItin.BudgetSplit
+	if assert.NotNil(t, split) {
+		assert.Equal(t, 369.0, split.Flights.GetValue())
+		assert.Equal(t, 531.0, split.Hotels.GetValue())
+		assert.Equal(t, 100.0, split.Buffer.GetValue())
+	}
+
+	// The caps reach the provider; with no flight under the cap it is searched again without one.
+	// The days around it are searched after that for similar dates.
+	if assert.Len(t, flightQueries, 2*(1+len(similarDateOffsets))) {
+		day := start.Format("2006-01-02")
+		assert.Equal(t, "369", flightQueries[0].Get("maxPrice"))
+		assert.Equal(t, day, flightQueries[0].Get("departureDate"))
+		assert.Empty(t, flightQueries[1].Get("maxPrice"))
+		assert.Equal(t, day, flightQueries[1].Get("departureDate"))
+	}
+	if assert.Len(t, offerQueries, 1) {
+		assert.Equal(t, "-177", offerQueries[0].Get("priceRange"))
+		assert.Equal(t, "USD", offerQueries[0].Get("currency"))
+	}
+	assert.NotEmpty(t, updatedItin.Graph.Nodes[1].StayOptions)
+}
+
+func TestTravelDesk_CheckAvailability_MalformedItinerary(t *testing.T) {
+	// Nothing is looked up for these, so the desk needs no client
+	desk := NewTravelDesk(nil)
+
+	tests := []struct {
+		name      string
+		itinerary *pb.Itinerary
+		problem   string
+	}{
+		{"NilItinerary", nil, "Itinerary is missing"},
+		{"NilGraph", &pb.Itinerary{Title: "No graph", Travelers: 1}, "Graph is missing"},
+		{"NilNodeAndEdge", &pb.Itinerary{
+			Title:     "Holes",
+			Travelers: 1,
+			Graph:     &pb.Graph{Nodes: []*pb.Node{nil}, Edges: []*pb.Edge{nil}},
+		}, "Node 0 is nil"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var updated *pb.Itinerary
+			var err error
+			assert.NotPanics(t, func() {
+				updated, err = desk.CheckAvailability(context.Background(), tt.itinerary)
+			})
+			assert.Nil(t, updated)
+
+			var validationErr *core.ValidationError
+			if assert.ErrorAs(t, err, &validationErr) {
+				assert.Contains(t, validationErr.Problems, tt.problem)
+			}
+		})
+	}
+}
+
+func TestTravelDesk_CheckAvailability_InfeasibleFlight(t *testing.T) {
+	// A flight given in local times that lands "before" it leaves is rejected before
+	// any provider is asked about it
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	client, _ := amadeus.NewClient(amadeus.Config{
+		ClientID: "id", ClientSecret: "secret", FlightLimit: 10, HotelLimit: 10, Timeout: 30,
+		CacheTTL: amadeus.CacheTTLConfig{Location: 24, Flight: 24, Hotel: 24},
+	}, nil, nil, nil)
+	client.BaseURL = ts.URL
+	desk := NewTravelDesk(client)
+
+	dep := time.Now().AddDate(0, 1, 0).UTC().Truncate(24 * time.Hour).Add(18 * time.Hour)
+	itin := &pb.Itinerary{
+		Title:       "New York to London",
+		StartTime:   timestamppb.New(dep),
+		EndTime:     timestamppb.New(dep.Add(24 * time.Hour)),
+		Travelers:   1,
+		JourneyType: pb.JourneyType_JOURNEY_TYPE_ONE_WAY,
+		Graph: &pb.Graph{
+			Nodes: []*pb.Node{
+				{Id: "nyc", Location: &pb.Location{City: "New York", IataCodes: []string{"JFK"}}},
+				{Id: "london", Location: &pb.Location{City: "London", IataCodes: []string{"LHR"}}},
+			},
+			Edges: []*pb.Edge{{
+				FromId: "nyc",
+				ToId:   "london",
+				Transport: &pb.Transport{
+					Type:                pb.TransportType_TRANSPORT_TYPE_FLIGHT,
+					TravelerCount:       1,
+					OriginLocation:      &pb.Location{IataCodes: []string{"JFK"}},
+					DestinationLocation: &pb.Location{IataCodes: []string{"LHR"}},
+					Details: &pb.Transport_Flight{Flight: &pb.Flight{
+						DepartureTime: timestamppb.New(dep),
+						ArrivalTime:   timestamppb.New(dep.Add(-11 * time.Hour)),
+					}},
+				},
+			}},
+		},
+	}
+
+	updated, err := desk.CheckAvailability(context.Background(), itin)
+	assert.Nil(t, updated)
+	var validationErr *core.ValidationError
+	if assert.ErrorAs(t, err, &validationErr) && assert.Len(t, validationErr.Problems, 1) {
+		assert.Contains(t, validationErr.Problems[0], "Edge 0 (nyc -> london): Flight arrives")
+	}
+	assert.Zero(t, calls, "no provider calls are made for an infeasible plan")
+}
+
+func TestTravelDesk_CheckAvailability_SplitStay(t *testing.T) {
+	// The offers echo the dates they were searched for, so each stay shows what it asked for
+	var offerQueries []url.Values
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/security/oauth2/token":
+			json.NewEncoder(w).Encode(amadeus.AuthToken{AccessToken: "token", ExpiresIn: 1800})
+		case "/v2/shopping/flight-offers":
+			json.NewEncoder(w).Encode(amadeus.FlightSearchResponse{Data: []amadeus.FlightOffer{}})
+		case "/v1/reference-data/locations/hotels/by-city":
+			json.NewEncoder(w).Encode(amadeus.HotelListResponse{Data: []amadeus.HotelData{{HotelId: "H1", Name: "Hotel A"}}})
+		case "/v3/shopping/hotel-offers":
+			q := r.URL.Query()
+			offerQueries = append(offerQueries, q)
+			json.NewEncoder(w).Encode(amadeus.HotelSearchResponse{
+				Data: []amadeus.HotelOfferData{{
+					Available: true,
+					Hotel:     amadeus.HotelInfo{HotelId: "H1", Name: "Hotel A", CityCode: "PAR"},
+					Offers: []amadeus.HotelOffer{{
+						ID:           "offer-" + q.Get("checkInDate"),
+						CheckInDate:  q.Get("checkInDate"),
+						CheckOutDate: q.Get("checkOutDate"),
+						Price:        amadeus.HotelPrice{Total: "400.00"},
+						Guests:       amadeus.HotelGuests{Adults: 2},
+					}},
+				}},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client, _ := amadeus.NewClient(amadeus.Config{
+		ClientID: "id", ClientSecret: "secret", IsProduction: false,
+		FlightLimit: 10, HotelLimit: 10, Timeout: 30,
+		CacheTTL: amadeus.CacheTTLConfig{Location: 24, Flight: 24, Hotel: 24},
+	}, nil, nil, nil)
+	client.BaseURL = ts.URL
+	desk := NewTravelDesk(client)
+
+	// Two nights at one hotel, then two at another in the same city
+	day := time.Now().AddDate(0, 1, 0).UTC().Truncate(24 * time.Hour)
+	at := func(days, hours int) *timestamppb.Timestamp {
+		return timestamppb.New(day.AddDate(0, 0, days).Add(time.Duration(hours) * time.Hour))
+	}
+	paris := func() *pb.Location { return &pb.Location{City: "Paris", CityCode: "PAR", IataCodes: []string{"CDG"}} }
+	itin := &pb.Itinerary{
+		Title:       "Paris, two hotels",
+		StartTime:   at(0, 8),
+		EndTime:     at(4, 11),
+		Travelers:   2,
+		JourneyType: pb.JourneyType_JOURNEY_TYPE_ONE_WAY,
+		Graph: &pb.Graph{
+			Nodes: []*pb.Node{
+				{Id: "start_loc", Location: &pb.Location{IataCodes: []string{"LHR"}}},
+				{Id: "paris_1", Location: paris(), Stay: &pb.Accommodation{TravelerCount: 2, CheckIn: at(0, 14), CheckOut: at(2, 11)}},
+				{Id: "paris_2", Location: paris(), Stay: &pb.Accommodation{TravelerCount: 2, CheckIn: at(2, 14), CheckOut: at(4, 11)}},
+			},
+			Edges: []*pb.Edge{
+				{FromId: "start_loc", ToId: "paris_1", Transport: &pb.Transport{
+					Type:                pb.TransportType_TRANSPORT_TYPE_FLIGHT,
+					OriginLocation:      &pb.Location{IataCodes: []string{"LHR"}},
+					DestinationLocation: paris(),
+					TravelerCount:       2,
+					Details:             &pb.Transport_Flight{Flight: &pb.Flight{DepartureTime: at(0, 8)}},
+				}},
+				{FromId: "paris_1", ToId: "paris_2", Transport: &pb.Transport{
+					Type:                pb.TransportType_TRANSPORT_TYPE_CAR,
+					OriginLocation:      paris(),
+					DestinationLocation: paris(),
+					TravelerCount:       2,
+				}},
+			},
+		},
+	}
+
+	updatedItin, err := desk.CheckAvailability(context.Background(), itin)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Each stay is searched and answered for its own nights
+	date := func(days int) string { return day.AddDate(0, 0, days).Format("2006-01-02") }
+	if assert.Len(t, offerQueries, 2) {
+		assert.Equal(t, []string{date(0), date(2)}, []string{offerQueries[0].Get("checkInDate"), offerQueries[1].Get("checkInDate")})
+		assert.Equal(t, []string{date(2), date(4)}, []string{offerQueries[0].Get("checkOutDate"), offerQueries[1].Get("checkOutDate")})
+	}
+	for i, nights := range [][2]int{{0, 2}, {2, 4}} {
+		node := updatedItin.Graph.Nodes[i+1]
+		assert.Nil(t, node.Stay.Error, node.Id)
+		if assert.Len(t, node.StayOptions, 1, node.Id) {
+			opt := node.StayOptions[0]
+			assert.Equal(t, date(nights[0]), opt.CheckIn.AsTime().Format("2006-01-02"), node.Id)
+			assert.Equal(t, date(nights[1]), opt.CheckOut.AsTime().Format("2006-01-02"), node.Id)
+		}
+	}
+}
+
+func TestTravelDesk_CheckAvailability_NearbyCityHotels(t *testing.T) {
+	// Amadeus lists no hotels under Mechelen, only under Brussels
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/security/oauth2/token":
+			json.NewEncoder(w).Encode(amadeus.AuthToken{AccessToken: "token", ExpiresIn: 1800})
+		case "/v2/shopping/flight-offers":
+			json.NewEncoder(w).Encode(amadeus.FlightSearchResponse{Data: []amadeus.FlightOffer{}})
+		case "/v1/reference-data/locations/hotels/by-city", "/v1/reference-data/locations/hotels/by-geocode":
+			resp := amadeus.HotelListResponse{Data: []amadeus.HotelData{}}
+			if r.URL.Query().Get("cityCode") == "BRU" {
+				resp.Data = append(resp.Data, amadeus.HotelData{HotelId: "B1", Name: "Hotel Brussels"})
+			}
+			json.NewEncoder(w).Encode(resp)
+		case "/v3/shopping/hotel-offers":
+			json.NewEncoder(w).Encode(amadeus.HotelSearchResponse{
+				Data: []amadeus.HotelOfferData{{
+					Available: true,
+					Hotel:     amadeus.HotelInfo{HotelId: "B1", Name: "Hotel Brussels", CityCode: "BRU"},
+					Offers: []amadeus.HotelOffer{{
+						ID:     "offer1",
+						Price:  amadeus.HotelPrice{Total: "300.00"},
+						Guests: amadeus.HotelGuests{Adults: 1},
+					}},
+				}},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client, _ := amadeus.NewClient(amadeus.Config{
+		ClientID: "id", ClientSecret: "secret", IsProduction: false,
+		FlightLimit: 10, HotelLimit: 10, Timeout: 30,
+		HotelFallbackRadiusKm: 40,
+		CacheTTL:              amadeus.CacheTTLConfig{Location: 24, Flight: 24, Hotel: 24},
+	}, nil, nil, nil)
+	client.BaseURL = ts.URL
+	client.Nearby = core.DefaultPlaceIndex()
+	desk := NewTravelDesk(client)
+
+	start := time.Now().AddDate(0, 1, 0).UTC().Truncate(time.Hour)
+	itinerary := func(strictLocation bool) *pb.Itinerary {
+		mechelen := &pb.Location{City: "Mechelen", Country: "BE", Geocode: "51.0259,4.4776"}
+		return &pb.Itinerary{
+			Title:       "Mechelen",
+			StartTime:   timestamppb.New(start),
+			EndTime:     timestamppb.New(start.Add(48 * time.Hour)),
+			Travelers:   1,
+			JourneyType: pb.JourneyType_JOURNEY_TYPE_ONE_WAY,
+			Graph: &pb.Graph{
+				Nodes: []*pb.Node{
+					{Id: "n1", Location: &pb.Location{IataCodes: []string{"LHR"}}},
+					{Id: "n2", Location: mechelen, Stay: &pb.Accommodation{
+						TravelerCount: 1,
+						CheckIn:       timestamppb.New(start.Add(6 * time.Hour)),
+						CheckOut:      timestamppb.New(start.Add(48 * time.Hour)),
+						Preferences:   &pb.AccommodationPreferences{StrictLocation: strictLocation},
+					}},
+				},
+				Edges: []*pb.Edge{{
+					FromId: "n1",
+					ToId:   "n2",
+					Transport: &pb.Transport{
+						Type:                pb.TransportType_TRANSPORT_TYPE_FLIGHT,
+						OriginLocation:      &pb.Location{IataCodes: []string{"LHR"}},
+						DestinationLocation: &pb.Location{IataCodes: []string{"BRU"}},
+						TravelerCount:       1,
+						Details:             &pb.Transport_Flight{Flight: &pb.Flight{DepartureTime: timestamppb.New(start)}},
+					},
+				}},
+			},
+		}
+	}
+
+	// Hotels in the nearest covered city are offered with a warning saying how far it is
+	updatedItin, err := desk.CheckAvailability(context.Background(), itinerary(false))
+	if !assert.NoError(t, err) {
+		return
+	}
+	node := updatedItin.Graph.Nodes[1]
+	if assert.NotNil(t, node.Stay.Error) {
+		assert.Equal(t, pb.ErrorSeverity_ERROR_SEVERITY_WARNING, node.Stay.Error.Severity)
+		assert.Equal(t, "hotels in Brussels, 14 km from Mechelen", node.Stay.Error.Message)
+	}
+	if assert.Len(t, node.StayOptions, 1) {
+		assert.Equal(t, "hotels in Brussels, 14 km from Mechelen", node.StayOptions[0].Error.GetMessage())
+	}
+
+	// A strict location keeps the original not-found error
+	updatedItin, err = desk.CheckAvailability(context.Background(), itinerary(true))
+	if !assert.NoError(t, err) {
+		return
+	}
+	node = updatedItin.Graph.Nodes[1]
+	assert.Empty(t, node.StayOptions)
+	if assert.NotNil(t, node.Stay.Error) {
+		assert.Equal(t, pb.ErrorCode_ERROR_CODE_DATA_NOT_FOUND, node.Stay.Error.Code)
+		assert.Equal(t, "No hotels found in city Mechelen", node.Stay.Error.Message)
+	}
+}
+
+func TestRelaxationWarning(t *testing.T) {
+	const note = "hotels in Brussels, 14 km from Mechelen"
+
+	w := relaxationWarning(nil, note)
+	assert.Equal(t, note, w.Message)
+	assert.Equal(t, pb.ErrorSeverity_ERROR_SEVERITY_WARNING, w.Severity)
+
+	// A provider note is kept after the warning
+	w = relaxationWarning(&pb.Error{Message: "Amadeus 123: rate may change", Severity: pb.ErrorSeverity_ERROR_SEVERITY_INFO}, note)
+	assert.Equal(t, note+"; Amadeus 123: rate may change", w.Message)
+	assert.Equal(t, pb.ErrorSeverity_ERROR_SEVERITY_WARNING, w.Severity)
+
+	// An existing warning keeps its severity, and the note is not lost
+	deposit := &pb.Error{Message: "Deposit of 100.00 EUR required at booking", Severity: pb.ErrorSeverity_ERROR_SEVERITY_WARNING}
+	w = relaxationWarning(deposit, note)
+	assert.Equal(t, "Deposit of 100.00 EUR required at booking; "+note, w.Message)
+	assert.Equal(t, pb.ErrorSeverity_ERROR_SEVERITY_WARNING, w.Severity)
+	assert.Equal(t, w, relaxationWarning(w, note), "the note is added once")
+}
+
+func TestTravelDesk_EnrichLocation_CountryHint(t *testing.T) {
+	sanJoseCR := &pb.Location{City: "SAN JOSE", Country: "COSTA RICA", CityCode: "SJO", IataCodes: []string{"SJO"}}
+	// Only a partial name match, but in the right country
+	sanJoseUS := &pb.Location{City: "SAN JOSE CA", Country: "UNITED STATES OF AMERICA", CityCode: "SJC", IataCodes: []string{"SJC"}}
+
+	// The memo answers the search, so no provider is needed
+	ctx := tmcontext.WithLocationMemo(context.Background(), tmcontext.NewLocationMemo())
+	tmcontext.LocationMemoFromContext(ctx).Resolve(ctx, locationMemoKey("San Jose", true), func(context.Context, string) ([]*pb.Location, error) {
+		return []*pb.Location{sanJoseCR, sanJoseUS}, nil
+	})
+	desk := NewTravelDesk(nil)
+
+	loc := &pb.Location{City: "San Jose", Country: "United States of America"}
+	assert.NoError(t, desk.enrichLocation(ctx, loc))
+	assert.Equal(t, []string{"SJC"}, loc.IataCodes)
+	assert.Equal(t, "SJC", loc.CityCode)
+
+	// Abbreviations match the full names Amadeus gives
+	loc = &pb.Location{City: "San Jose", Country: "USA"}
+	assert.NoError(t, desk.enrichLocation(ctx, loc))
+	assert.Equal(t, []string{"SJC"}, loc.IataCodes)
+
+	// Without a hint the exact name wins
+	loc = &pb.Location{City: "San Jose"}
+	assert.NoError(t, desk.enrichLocation(ctx, loc))
+	assert.Equal(t, []string{"SJO"}, loc.IataCodes)
+}
+
+func TestTravelDesk_EnrichLocation_NearbySearchedApart(t *testing.T) {
+	ctx := tmcontext.WithLocationMemo(context.Background(), tmcontext.NewLocationMemo())
+	memo := tmcontext.LocationMemoFromContext(ctx)
+	memo.Resolve(ctx, locationMemoKey("PAR", false), func(context.Context, string) ([]*pb.Location, error) {
+		return []*pb.Location{{City: "PARIS", Country: "FRANCE", CityCode: "PAR", IataCodes: []string{"PAR"}}}, nil
+	})
+	memo.Resolve(ctx, locationMemoKey("PAR", true), func(context.Context, string) ([]*pb.Location, error) {
+		return []*pb.Location{{City: "PARIS", Country: "FRANCE", CityCode: "PAR", IataCodes: []string{"CDG", "ORY"}}}, nil
+	})
+	desk := NewTravelDesk(nil)
+
+	// The code the user gave is searched as is
+	airport := &pb.Location{IataCodes: []string{"PAR"}}
+	assert.NoError(t, desk.enrichLocation(ctx, airport))
+	assert.Equal(t, []string{"PAR"}, airport.IataCodes)
+
+	// The same code as a city gets the nearby airports, not the remembered exact search
+	city := &pb.Location{CityCode: "PAR"}
+	assert.NoError(t, desk.enrichLocation(ctx, city))
+	assert.Equal(t, []string{"CDG", "ORY"}, city.IataCodes)
+}
+
+func TestBestLocationMatch_ExactIata(t *testing.T) {
+	city := &pb.Location{City: "San Jose", Country: "Costa Rica", CityCode: "SJO"}
+	airport := &pb.Location{IataCodes: []string{"SJO"}}
+
+	// The airport matches on nothing but its code, which is enough
+	loc := &pb.Location{City: "San Jose", Country: "Costa Rica", CityCode: "SJO", IataCodes: []string{"SJO"}}
+	assert.Same(t, airport, bestLocationMatch(loc, []*pb.Location{city, airport}))
+
+	loc.IataCodes = nil
+	assert.Same(t, city, bestLocationMatch(loc, []*pb.Location{city, airport}))
+}
+
+func TestBestLocationMatch_CountryAbbreviation(t *testing.T) {
+	london := &pb.Location{City: "LONDON", Country: "UNITED KINGDOM", CityCode: "LON"}
+	londonCA := &pb.Location{City: "LONDON", Country: "CANADA", CityCode: "YXU"}
+
+	for _, country := range []string{"UK", "GB", "United Kingdom"} {
+		assert.Same(t, london, bestLocationMatch(&pb.Location{City: "London", Country: country}, []*pb.Location{londonCA, london}), country)
+	}
+	assert.Same(t, londonCA, bestLocationMatch(&pb.Location{City: "London", Country: "CA"}, []*pb.Location{london, londonCA}))
+
+	// A code with no known name is not held against the city
+	assert.NotNil(t, bestLocationMatch(&pb.Location{City: "London", Country: "XK"}, []*pb.Location{london}))
+}
+
+func TestTravelDesk_EnrichGraph_KeepsLocationWithoutMatch(t *testing.T) {
+	// The memo answers the searches, so no provider is needed
+	ctx := tmcontext.WithLocationMemo(context.Background(), tmcontext.NewLocationMemo())
+	memo := tmcontext.LocationMemoFromContext(ctx)
+	memo.Resolve(ctx, "XNA", func(context.Context, string) ([]*pb.Location, error) {
+		// Only something unrelated that mentions the keyword
+		return []*pb.Location{{City: "BENTONVILLE", Country: "UNITED STATES OF AMERICA", IataCodes: []string{""}}}, nil
+	})
+	memo.Resolve(ctx, locationMemoKey("Rogers", true), func(context.Context, string) ([]*pb.Location, error) {
+		return nil, nil
+	})
+
+	edge := &pb.Edge{FromId: "a", ToId: "b", Transport: &pb.Transport{
+		Type:           pb.TransportType_TRANSPORT_TYPE_FLIGHT,
+		OriginLocation: &pb.Location{City: "Rogers", Country: "US", IataCodes: []string{"XNA"}},
+	}}
+	NewTravelDesk(nil).EnrichGraph(ctx, &pb.Itinerary{Graph: &pb.Graph{Edges: []*pb.Edge{edge}}})
+
+	// The flight search still has the code the user gave
+	origin := edge.Transport.OriginLocation
+	assert.Equal(t, []string{"XNA"}, origin.IataCodes)
+	assert.Equal(t, "Rogers", origin.City)
+	assert.Equal(t, "US", origin.Country)
+}
@@ -0,0 +1,338 @@
+package agents
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tmcore "github.com/va6996/travelingman/core"
+	"github.com/va6996/travelingman/orm"
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+// TripFromItinerary lays an itinerary out day by day for the calendar view. Every node,
+// activities in sub-graphs included, becomes a place on the day it is first visited,
+// and every edge a transport. ItineraryFromTrip turns the result back into the
+// itinerary. The itinerary is not modified.
+func TripFromItinerary(it *pb.Itinerary, groupID uint) (*orm.Trip, error) {
+	details := proto.Clone(it).(*pb.Itinerary)
+	details.Graph = nil
+	data, err := protojson.Marshal(details)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode itinerary: %w", err)
+	}
+	trip := &orm.Trip{GroupID: groupID, Name: it.Title, DetailsJSON: string(data)}
+
+	var places []orm.Place
+	if err := collectPlaces(it.GetGraph(), "", &places, &trip.Transports); err != nil {
+		return nil, err
+	}
+
+	// The trip runs over its own dates, stretched to every place visited
+	start, end := it.GetStartTime().AsTime(), it.GetEndTime().AsTime()
+	if it.StartTime == nil {
+		start = time.Time{}
+	}
+	if it.EndTime == nil {
+		end = time.Time{}
+	}
+	for _, p := range places {
+		if p.VisitTime.IsZero() {
+			continue
+		}
+		if start.IsZero() || p.VisitTime.Before(start) {
+			start = p.VisitTime
+		}
+		if end.IsZero() || p.VisitTime.After(end) {
+			end = p.VisitTime
+		}
+	}
+	if end.Before(start) {
+		end = start
+	}
+	trip.StartDate, trip.EndDate = civilDate(start), civilDate(end)
+
+	for d, n := trip.StartDate, 1; !d.After(trip.EndDate); d, n = d.AddDate(0, 0, 1), n+1 {
+		trip.Days = append(trip.Days, orm.TripDay{DayNumber: n, Date: d})
+	}
+
+	// Places go on the day they are visited, in visiting order; those with no known
+	// time go on the first day
+	sort.SliceStable(places, func(i, j int) bool {
+		ti, tj := places[i].VisitTime, places[j].VisitTime
+		if ti.IsZero() || tj.IsZero() {
+			return !ti.IsZero() && tj.IsZero()
+		}
+		return ti.Before(tj)
+	})
+	for _, p := range places {
+		day := 0
+		if !p.VisitTime.IsZero() {
+			day = int(civilDate(p.VisitTime).Sub(trip.StartDate) / (24 * time.Hour))
+		}
+		p.OrderIndex = len(trip.Days[day].Places)
+		trip.Days[day].Places = append(trip.Days[day].Places, p)
+	}
+
+	// Each day is spent where the traveller stays that night, or else the last place reached
+	location := ""
+	for i := range trip.Days {
+		day := &trip.Days[i]
+		for _, p := range day.Places {
+			if p.ParentNodeID == "" {
+				location = nodePlace(tmcore.GetNodeByID(it.Graph, p.NodeID))
+			}
+		}
+		for _, n := range it.GetGraph().GetNodes() {
+			in, out := n.GetStay().GetCheckIn(), n.GetStay().GetCheckOut()
+			if in != nil && out != nil && !civilDate(in.AsTime()).After(day.Date) && civilDate(out.AsTime()).After(day.Date) {
+				location = nodePlace(n)
+			}
+		}
+		day.Location = location
+	}
+
+	// The destination is the first place stayed at, or else where the trip ends
+	for _, n := range it.GetGraph().GetNodes() {
+		if n.Stay != nil {
+			trip.Destination = nodePlace(n)
+			break
+		}
+	}
+	if trip.Destination == "" {
+		trip.Destination = location
+	}
+	return trip, nil
+}
+
+// collectPlaces adds the nodes and edges of g, and of the sub-graphs within it, to
+// places and transports. parent is the ID of the node whose sub-graph g is.
+func collectPlaces(g *pb.Graph, parent string, places *[]orm.Place, transports *[]orm.TripTransport) error {
+	for _, n := range g.GetNodes() {
+		node := proto.Clone(n).(*pb.Node)
+		node.SubGraph = nil
+		data, err := protojson.Marshal(node)
+		if err != nil {
+			return fmt.Errorf("failed to encode node %q: %w", n.Id, err)
+		}
+
+		p := orm.Place{
+			NodeID:       n.Id,
+			ParentNodeID: parent,
+			Name:         placeName(n),
+			Address:      n.GetLocation().GetAddress(),
+			PlaceType:    orm.PlaceTypeStop,
+			Notes:        n.Notes,
+			VisitTime:    nodeTime(g, n),
+			DetailsJSON:  string(data),
+		}
+		if p.Address == "" {
+			p.Address = n.GetStay().GetLocation().GetAddress()
+		}
+		if n.Stay != nil {
+			p.PlaceType = orm.PlaceTypeStay
+		} else if parent != "" {
+			p.PlaceType = orm.PlaceTypeActivity
+		}
+		if pos, ok := nodePosition(n); ok {
+			p.Latitude, p.Longitude = pos.Lat, pos.Lng
+		}
+		*places = append(*places, p)
+	}
+
+	for _, e := range g.GetEdges() {
+		data, err := protojson.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("failed to encode edge %s->%s: %w", e.FromId, e.ToId, err)
+		}
+		tt := orm.TripTransport{
+			ParentNodeID:  parent,
+			FromLocation:  e.FromId,
+			ToLocation:    e.ToId,
+			TransportMode: strings.TrimPrefix(e.GetTransport().GetType().String(), "TRANSPORT_TYPE_"),
+			DetailsJSON:   string(data),
+		}
+		if n := tmcore.GetNodeByID(g, e.FromId); n != nil {
+			tt.FromLocation = nodePlace(n)
+		}
+		if n := tmcore.GetNodeByID(g, e.ToId); n != nil {
+			tt.ToLocation = nodePlace(n)
+		}
+		if e.Transport != nil {
+			tt.DepartureTime, tt.ArrivalTime, _ = legTimes(e)
+		}
+		*transports = append(*transports, tt)
+	}
+
+	for _, n := range g.GetNodes() {
+		if n.SubGraph != nil {
+			if err := collectPlaces(n.SubGraph, n.Id, places, transports); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// placeName names a place for the calendar: the hotel for stays, else the location
+func placeName(n *pb.Node) string {
+	if name := n.GetStay().GetName(); name != "" {
+		return name
+	}
+	if name := n.GetLocation().GetName(); name != "" {
+		return name
+	}
+	return nodePlace(n)
+}
+
+// placeKey identifies a place within its trip. Node IDs are only unique within their
+// graph, so sub-graphs of different nodes may reuse them.
+type placeKey struct {
+	parent, node string
+}
+
+// ItineraryFromTrip rebuilds the itinerary of a trip made by TripFromItinerary. Notes
+// edited on a place are kept; nodes are listed in visiting order.
+func ItineraryFromTrip(trip *orm.Trip) (*pb.Itinerary, error) {
+	it := &pb.Itinerary{}
+	if trip.DetailsJSON != "" {
+		if err := protojson.Unmarshal([]byte(trip.DetailsJSON), it); err != nil {
+			return nil, fmt.Errorf("failed to decode trip %d: %w", trip.ID, err)
+		}
+	}
+	if it.Title == "" {
+		it.Title = trip.Name
+	}
+
+	// Nodes first, so activities can be put in their city's sub-graph whatever the order
+	nodes := map[placeKey]*pb.Node{}
+	byID := map[string][]placeKey{}
+	var places []orm.Place
+	for _, day := range trip.Days {
+		for _, p := range day.Places {
+			n := &pb.Node{}
+			if err := protojson.Unmarshal([]byte(p.DetailsJSON), n); err != nil {
+				return nil, fmt.Errorf("failed to decode place %q: %w", p.NodeID, err)
+			}
+			n.Notes = p.Notes
+			key := placeKey{p.ParentNodeID, p.NodeID}
+			if _, ok := nodes[key]; ok {
+				return nil, fmt.Errorf("trip %d has place %q twice", trip.ID, p.NodeID)
+			}
+			nodes[key] = n
+			byID[p.NodeID] = append(byID[p.NodeID], key)
+			places = append(places, p)
+		}
+	}
+
+	// A parent is only known by its node ID; one in the top-level graph is preferred,
+	// as that is where the nodes with sub-graphs are
+	root := &pb.Graph{}
+	graphOf := func(parent string) (*pb.Graph, error) {
+		if parent == "" {
+			return root, nil
+		}
+		n, ok := nodes[placeKey{"", parent}]
+		if !ok {
+			keys := byID[parent]
+			if len(keys) != 1 {
+				return nil, fmt.Errorf("trip %d has %d places %q, not one", trip.ID, len(keys), parent)
+			}
+			n = nodes[keys[0]]
+		}
+		if n.SubGraph == nil {
+			n.SubGraph = &pb.Graph{}
+		}
+		return n.SubGraph, nil
+	}
+	for _, p := range places {
+		g, err := graphOf(p.ParentNodeID)
+		if err != nil {
+			return nil, err
+		}
+		g.Nodes = append(g.Nodes, nodes[placeKey{p.ParentNodeID, p.NodeID}])
+	}
+	for _, tt := range trip.Transports {
+		e := &pb.Edge{}
+		if err := protojson.Unmarshal([]byte(tt.DetailsJSON), e); err != nil {
+			return nil, fmt.Errorf("failed to decode transport %d: %w", tt.ID, err)
+		}
+		g, err := graphOf(tt.ParentNodeID)
+		if err != nil {
+			return nil, err
+		}
+		g.Edges = append(g.Edges, e)
+	}
+
+	if len(root.Nodes) > 0 || len(root.Edges) > 0 {
+		it.Graph = root
+	}
+	return it, nil
+}
+
+// StoreTripDays lays a saved plan out day by day with TripFromItinerary and stores the
+// result in place of the plan's previous layout, so the calendar follows every save
+func StoreTripDays(db *gorm.DB, it *pb.Itinerary) error {
+	if it.Id == 0 {
+		return errors.New("itinerary has not been saved")
+	}
+	trip, err := TripFromItinerary(it, uint(it.GroupId))
+	if err != nil {
+		return err
+	}
+	trip.SavedTripID = uint(it.Id)
+	return orm.ReplacePlanTrip(db, trip)
+}
+
+// BuildTripCalendar converts a stored trip for the calendar view
+func BuildTripCalendar(trip *orm.Trip) *pb.TripCalendar {
+	cal := &pb.TripCalendar{Destination: trip.Destination}
+	for _, day := range trip.Days {
+		d := &pb.TripDay{
+			DayNumber: int32(day.DayNumber),
+			Date:      timestamppb.New(day.Date),
+			Location:  day.Location,
+		}
+		for _, p := range day.Places {
+			place := &pb.TripPlace{
+				NodeId:       p.NodeID,
+				ParentNodeId: p.ParentNodeID,
+				Name:         p.Name,
+				Address:      p.Address,
+				PlaceType:    p.PlaceType,
+				Notes:        p.Notes,
+				VisitTime:    optionalTimestamp(p.VisitTime),
+			}
+			if p.Latitude != 0 || p.Longitude != 0 {
+				place.Position = &pb.LatLng{Lat: p.Latitude, Lng: p.Longitude}
+			}
+			d.Places = append(d.Places, place)
+		}
+		cal.Days = append(cal.Days, d)
+	}
+	for _, tt := range trip.Transports {
+		cal.Legs = append(cal.Legs, &pb.TripLeg{
+			ParentNodeId:  tt.ParentNodeID,
+			From:          tt.FromLocation,
+			To:            tt.ToLocation,
+			Mode:          tt.TransportMode,
+			DepartureTime: optionalTimestamp(tt.DepartureTime),
+			ArrivalTime:   optionalTimestamp(tt.ArrivalTime),
+		})
+	}
+	return cal
+}
+
+// optionalTimestamp converts t, leaving the zero time unset
+func optionalTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
@@ -0,0 +1,195 @@
+package agents
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/orm"
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/proto"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// twoDayItinerary is London -> Paris on the 10th, a day in Paris with dinner on the
+// 11th, and the train home that evening
+func twoDayItinerary() *pb.Itinerary {
+	outbound := flightEdge("london", "paris", &pb.Flight{
+		CarrierCode: "BA", FlightNumber: "304",
+		DepartureTime: statsTime(10, 8), ArrivalTime: statsTime(10, 10),
+	})
+	return &pb.Itinerary{
+		Title:     "Paris",
+		StartTime: statsTime(10, 8),
+		EndTime:   statsTime(11, 22),
+		Travelers: 2,
+		Graph: &pb.Graph{
+			Nodes: []*pb.Node{
+				{Id: "london", Location: &pb.Location{City: "London", IataCodes: []string{"LHR"}}},
+				{
+					Id:       "paris",
+					Location: &pb.Location{City: "Paris", Geocode: "48.856600,2.352200"},
+					Stay:     &pb.Accommodation{Name: "Hotel Lutetia", CheckIn: statsTime(10, 15), CheckOut: statsTime(11, 11), Cost: &pb.Cost{Value: 320, Currency: "EUR"}},
+					SubGraph: &pb.Graph{
+						Nodes: []*pb.Node{
+							{Id: "louvre", Location: &pb.Location{Name: "Louvre"}, FromTimestamp: statsTime(11, 12)},
+							{Id: "dinner", Location: &pb.Location{Name: "Le Comptoir"}, FromTimestamp: statsTime(11, 18), Notes: "Dinner at Le Comptoir on day 2"},
+						},
+						Edges: []*pb.Edge{{FromId: "louvre", ToId: "dinner", Transport: &pb.Transport{Type: pb.TransportType_TRANSPORT_TYPE_WALKING}}},
+					},
+				},
+			},
+			Edges: []*pb.Edge{outbound, trainEdge("paris", "london", statsTime(11, 20), statsTime(11, 22))},
+		},
+	}
+}
+
+func TestTripFromItinerary(t *testing.T) {
+	it := twoDayItinerary()
+	original := proto.Clone(it)
+
+	trip, err := TripFromItinerary(it, 7)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, proto.Equal(original, it), "the itinerary must not be modified")
+
+	assert.Equal(t, uint(7), trip.GroupID)
+	assert.Equal(t, "Paris", trip.Name)
+	assert.Equal(t, "Paris", trip.Destination)
+	assert.Equal(t, time.Date(2030, time.May, 10, 0, 0, 0, 0, time.UTC), trip.StartDate)
+	assert.Equal(t, time.Date(2030, time.May, 11, 0, 0, 0, 0, time.UTC), trip.EndDate)
+
+	if assert.Len(t, trip.Days, 2) {
+		first, second := trip.Days[0], trip.Days[1]
+		assert.Equal(t, 1, first.DayNumber)
+		assert.Equal(t, "Paris", first.Location, "the first night is spent in Paris")
+		if assert.Len(t, first.Places, 2) {
+			assert.Equal(t, "london", first.Places[0].NodeID)
+			assert.Equal(t, orm.PlaceTypeStop, first.Places[0].PlaceType)
+			assert.Equal(t, "Hotel Lutetia", first.Places[1].Name)
+			assert.Equal(t, orm.PlaceTypeStay, first.Places[1].PlaceType)
+			assert.Equal(t, 48.8566, first.Places[1].Latitude)
+			assert.Equal(t, 1, first.Places[1].OrderIndex)
+		}
+
+		assert.Equal(t, 2, second.DayNumber)
+		assert.Equal(t, "Paris", second.Location, "the day is spent in Paris")
+		if assert.Len(t, second.Places, 2) {
+			assert.Equal(t, "Louvre", second.Places[0].Name)
+			assert.Equal(t, "paris", second.Places[0].ParentNodeID)
+			assert.Equal(t, orm.PlaceTypeActivity, second.Places[0].PlaceType)
+			assert.Equal(t, "Le Comptoir", second.Places[1].Name)
+			assert.Equal(t, "Dinner at Le Comptoir on day 2", second.Places[1].Notes)
+			assert.Equal(t, statsTime(11, 18).AsTime(), second.Places[1].VisitTime)
+		}
+	}
+
+	if assert.Len(t, trip.Transports, 3) {
+		assert.Equal(t, "London", trip.Transports[0].FromLocation)
+		assert.Equal(t, "Paris", trip.Transports[0].ToLocation)
+		assert.Equal(t, "FLIGHT", trip.Transports[0].TransportMode)
+		assert.Equal(t, statsTime(10, 10).AsTime(), trip.Transports[0].ArrivalTime)
+		assert.Equal(t, "TRAIN", trip.Transports[1].TransportMode)
+		assert.Equal(t, "paris", trip.Transports[2].ParentNodeID)
+		assert.Equal(t, "Louvre", trip.Transports[2].FromLocation)
+	}
+}
+
+func TestItineraryFromTrip_RoundTrip(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	assert.NoError(t, db.AutoMigrate(&orm.Trip{}, &orm.TripDay{}, &orm.Place{}, &orm.TripTransport{}))
+
+	it := twoDayItinerary()
+	trip, err := TripFromItinerary(it, 7)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.NoError(t, orm.CreateTrip(db, trip)) {
+		return
+	}
+
+	// The calendar edits a note
+	loaded, err := orm.GetTrip(db, trip.ID)
+	if !assert.NoError(t, err) || !assert.Len(t, loaded.Days, 2) {
+		return
+	}
+	assert.Len(t, loaded.Days[1].Places, 2)
+	loaded.Days[1].Places[1].Notes = "Dinner at Le Comptoir at 8pm"
+
+	back, err := ItineraryFromTrip(loaded)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	want := twoDayItinerary()
+	want.Graph.Nodes[1].SubGraph.Nodes[1].Notes = "Dinner at Le Comptoir at 8pm"
+	assert.True(t, proto.Equal(want, back), "want %v, got %v", want, back)
+}
+
+func TestItineraryFromTrip_SharedNodeIDs(t *testing.T) {
+	// Both cities' day plans have a node called "dinner"
+	it := twoDayItinerary()
+	it.Graph.Nodes[0].SubGraph = &pb.Graph{Nodes: []*pb.Node{
+		{Id: "dinner", Location: &pb.Location{Name: "Dishoom"}, FromTimestamp: statsTime(10, 6)},
+	}}
+
+	trip, err := TripFromItinerary(it, 7)
+	if !assert.NoError(t, err) {
+		return
+	}
+	back, err := ItineraryFromTrip(trip)
+	if assert.NoError(t, err) {
+		assert.True(t, proto.Equal(it, back), "want %v, got %v", it, back)
+	}
+}
+
+func TestStoreTripDays(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	assert.NoError(t, db.AutoMigrate(&orm.Trip{}, &orm.TripDay{}, &orm.Place{}, &orm.TripTransport{}))
+
+	it := twoDayItinerary()
+	assert.Error(t, StoreTripDays(db, it), "only saved plans are laid out")
+	it.Id = 5
+	assert.NoError(t, StoreTripDays(db, it))
+
+	// Saving the plan again replaces its layout
+	it.Graph.Nodes[1].SubGraph.Nodes[1].Notes = "Dinner at 8pm"
+	assert.NoError(t, StoreTripDays(db, it))
+	var trips, places int64
+	db.Model(&orm.Trip{}).Count(&trips)
+	db.Model(&orm.Place{}).Count(&places)
+	assert.Equal(t, int64(1), trips)
+	assert.Equal(t, int64(4), places)
+
+	trip, err := orm.GetPlanTrip(db, 5)
+	if !assert.NoError(t, err) {
+		return
+	}
+	cal := BuildTripCalendar(trip)
+	assert.Equal(t, "Paris", cal.Destination)
+	if assert.Len(t, cal.Days, 2) && assert.Len(t, cal.Days[1].Places, 2) {
+		assert.Equal(t, int32(2), cal.Days[1].DayNumber)
+		dinner := cal.Days[1].Places[1]
+		assert.Equal(t, "Dinner at 8pm", dinner.Notes)
+		assert.Equal(t, "paris", dinner.ParentNodeId)
+		assert.Equal(t, statsTime(11, 18).AsTime(), dinner.VisitTime.AsTime())
+		assert.Nil(t, dinner.Position)
+	}
+	assert.Equal(t, 48.8566, cal.Days[0].Places[1].Position.GetLat())
+	if assert.Len(t, cal.Legs, 3) {
+		assert.Equal(t, "FLIGHT", cal.Legs[0].Mode)
+		assert.Equal(t, "London", cal.Legs[0].From)
+		assert.Nil(t, cal.Legs[2].DepartureTime, "the walk has no times")
+	}
+
+	_, err = orm.GetPlanTrip(db, 6)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
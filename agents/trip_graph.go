@@ -0,0 +1,278 @@
+package agents
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	tmcore "github.com/va6996/travelingman/core"
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// polylineSegments is the number of great-circle segments an edge is drawn with
+const polylineSegments = 32
+
+// BuildTripGraph turns an itinerary into a render model for the map view: nodes with
+// coordinates in visiting order, edges as great-circle polylines in departure order, and
+// sub-graphs as nested groups. Nodes without coordinates, and the edges touching them,
+// are left out and listed in the warnings. The itinerary is not modified.
+func BuildTripGraph(it *pb.Itinerary) *pb.TripGraph {
+	var warnings []string
+	tg := buildTripGraph(it.GetGraph(), "", &warnings)
+	tg.Warnings = warnings
+	return tg
+}
+
+func buildTripGraph(g *pb.Graph, path string, warnings *[]string) *pb.TripGraph {
+	tg := &pb.TripGraph{}
+	if g == nil {
+		return tg
+	}
+
+	// Order nodes by when they are visited; nodes with no known time go last
+	nodes := make([]*pb.Node, len(g.Nodes))
+	copy(nodes, g.Nodes)
+	sort.SliceStable(nodes, func(i, j int) bool {
+		ti, tj := nodeTime(g, nodes[i]), nodeTime(g, nodes[j])
+		if ti.IsZero() || tj.IsZero() {
+			return !ti.IsZero() && tj.IsZero()
+		}
+		return ti.Before(tj)
+	})
+
+	positions := map[string]*pb.LatLng{}
+	for i, n := range nodes {
+		pos, ok := nodePosition(n)
+		if !ok {
+			*warnings = append(*warnings, fmt.Sprintf("node %q (%s) has no coordinates and is not shown", path+n.Id, nodePlace(n)))
+		} else {
+			positions[n.Id] = pos
+			start, end := nodeTimes(g, n)
+			tg.Nodes = append(tg.Nodes, &pb.TripGraphNode{
+				Id:        n.Id,
+				Position:  pos,
+				Label:     nodePlace(n),
+				Type:      nodeType(g, n, i == 0),
+				StartTime: start,
+				EndTime:   end,
+				Notes:     n.Notes,
+			})
+		}
+
+		if n.SubGraph != nil {
+			tg.Groups = append(tg.Groups, &pb.TripGraphGroup{
+				NodeId: n.Id,
+				Graph:  buildTripGraph(n.SubGraph, path+n.Id+"/", warnings),
+			})
+		}
+	}
+
+	// Order edges by departure; edges with no known departure go last
+	edges := make([]*pb.Edge, len(g.Edges))
+	copy(edges, g.Edges)
+	sort.SliceStable(edges, func(i, j int) bool {
+		di, dj := edgeDeparture(edges[i]), edgeDeparture(edges[j])
+		if di.IsZero() || dj.IsZero() {
+			return !di.IsZero() && dj.IsZero()
+		}
+		return di.Before(dj)
+	})
+
+	for _, e := range edges {
+		from, to := positions[e.FromId], positions[e.ToId]
+		if from == nil || to == nil {
+			*warnings = append(*warnings, fmt.Sprintf("edge %s%s->%s is not shown: an endpoint has no coordinates", path, e.FromId, e.ToId))
+			continue
+		}
+		tg.Edges = append(tg.Edges, &pb.TripGraphEdge{
+			FromId:          e.FromId,
+			ToId:            e.ToId,
+			Mode:            e.GetTransport().GetType(),
+			Polyline:        greatCircle(from, to, polylineSegments),
+			DurationSeconds: edgeDuration(e),
+			Summary:         transportSummary(e.Transport),
+		})
+	}
+
+	if g.SubGraph != nil {
+		tg.Groups = append(tg.Groups, &pb.TripGraphGroup{
+			Graph: buildTripGraph(g.SubGraph, path, warnings),
+		})
+	}
+
+	return tg
+}
+
+// nodeTime is the time a node is first reached, for ordering. The trip's start node
+// has no arrival, so its departure is used.
+func nodeTime(g *pb.Graph, n *pb.Node) time.Time {
+	start, end := nodeTimes(g, n)
+	if start != nil {
+		return start.AsTime()
+	}
+	if end != nil {
+		return end.AsTime()
+	}
+	return time.Time{}
+}
+
+// nodeTimes returns when the traveller arrives at and leaves a node, from the node's own
+// times, the stay, or else the adjacent legs. A node that is left before it is first
+// reached, like home on a round trip, is where the trip starts and only gets a departure.
+func nodeTimes(g *pb.Graph, n *pb.Node) (start, end *timestamppb.Timestamp) {
+	start, end = n.FromTimestamp, n.ToTimestamp
+	if start == nil {
+		start = n.Stay.GetCheckIn()
+	}
+	if end == nil {
+		end = n.Stay.GetCheckOut()
+	}
+	if start != nil && end != nil {
+		return start, end
+	}
+
+	var arrival, departure time.Time
+	for _, e := range tmcore.GetEdgesToNode(g, n.Id) {
+		if _, arr, ok := legTimes(e); ok && (arrival.IsZero() || arr.Before(arrival)) {
+			arrival = arr
+		}
+	}
+	for _, e := range tmcore.GetEdgesFromNode(g, n.Id) {
+		if dep := edgeDeparture(e); !dep.IsZero() && (departure.IsZero() || dep.Before(departure)) {
+			departure = dep
+		}
+	}
+	if start == nil && !arrival.IsZero() && (departure.IsZero() || !departure.Before(arrival)) {
+		start = timestamppb.New(arrival)
+	}
+	if end == nil && !departure.IsZero() {
+		end = timestamppb.New(departure)
+	}
+	return start, end
+}
+
+// nodeType classifies a node for the map legend. The first node visited is the origin
+// unless the traveller stays there, as is any node that is only ever left.
+func nodeType(g *pb.Graph, n *pb.Node, first bool) pb.TripGraphNodeType {
+	switch {
+	case n.Stay != nil:
+		return pb.TripGraphNodeType_TRIP_GRAPH_NODE_TYPE_STAY
+	case first || (len(tmcore.GetEdgesToNode(g, n.Id)) == 0 && len(tmcore.GetEdgesFromNode(g, n.Id)) > 0):
+		return pb.TripGraphNodeType_TRIP_GRAPH_NODE_TYPE_ORIGIN
+	default:
+		return pb.TripGraphNodeType_TRIP_GRAPH_NODE_TYPE_DESTINATION
+	}
+}
+
+// nodePosition returns the coordinates of a node's location, falling back to its stay's
+func nodePosition(n *pb.Node) (*pb.LatLng, bool) {
+	if pos, ok := parseGeocode(n.GetLocation().GetGeocode()); ok {
+		return pos, true
+	}
+	return parseGeocode(n.GetStay().GetLocation().GetGeocode())
+}
+
+// parseGeocode parses a "lat,lng" geocode as set by the location and hotel searches
+func parseGeocode(s string) (*pb.LatLng, bool) {
+	lat, lng, ok := tmcore.ParseGeocode(s)
+	if !ok {
+		return nil, false
+	}
+	return &pb.LatLng{Lat: lat, Lng: lng}, true
+}
+
+// edgeDeparture returns when an edge's transport leaves, or the zero time if unknown
+func edgeDeparture(e *pb.Edge) time.Time {
+	if e.Transport == nil {
+		return time.Time{}
+	}
+	dep, _, _ := legTimes(e)
+	return dep
+}
+
+// edgeDuration returns the edge's duration, or the time between its departure and arrival
+func edgeDuration(e *pb.Edge) int64 {
+	if e.DurationSeconds > 0 || e.Transport == nil {
+		return e.DurationSeconds
+	}
+	if dep, arr, ok := legTimes(e); ok {
+		return int64(arr.Sub(dep).Seconds())
+	}
+	return 0
+}
+
+// transportSummary describes the selected option, e.g. "Flight BA 117 LHR-CDG, 120.00 EUR"
+func transportSummary(t *pb.Transport) string {
+	if t == nil {
+		return ""
+	}
+	var parts []string
+	switch {
+	case t.GetFlight() != nil:
+		f := t.GetFlight()
+		parts = append(parts, "Flight", f.CarrierCode, f.FlightNumber)
+	case t.GetTrain() != nil:
+		parts = append(parts, "Train", t.GetTrain().TrainNumber)
+	case t.GetCarRental() != nil:
+		parts = append(parts, "Car", t.GetCarRental().Company, t.GetCarRental().CarType)
+	default:
+		parts = append(parts, transportTypeName(t.Type))
+	}
+	if from, to := firstIata(t.OriginLocation), firstIata(t.DestinationLocation); from != "" && to != "" {
+		parts = append(parts, from+"-"+to)
+	}
+	summary := strings.Join(strings.Fields(strings.Join(parts, " ")), " ")
+	if c := t.GetCost(); c.GetValue() > 0 {
+		summary += fmt.Sprintf(", %.2f %s", c.Value, c.Currency)
+	}
+	return summary
+}
+
+func transportTypeName(t pb.TransportType) string {
+	switch t {
+	case pb.TransportType_TRANSPORT_TYPE_FLIGHT:
+		return "Flight"
+	case pb.TransportType_TRANSPORT_TYPE_TRAIN:
+		return "Train"
+	case pb.TransportType_TRANSPORT_TYPE_CAR:
+		return "Car"
+	case pb.TransportType_TRANSPORT_TYPE_WALKING:
+		return "Walk"
+	default:
+		return "Transport"
+	}
+}
+
+// greatCircle samples the shortest path over the earth between two points.
+// The first and last points are exactly a and b.
+func greatCircle(a, b *pb.LatLng, segments int) []*pb.LatLng {
+	toVec := func(p *pb.LatLng) [3]float64 {
+		lat, lng := p.Lat*math.Pi/180, p.Lng*math.Pi/180
+		return [3]float64{math.Cos(lat) * math.Cos(lng), math.Cos(lat) * math.Sin(lng), math.Sin(lat)}
+	}
+	va, vb := toVec(a), toVec(b)
+	dot := va[0]*vb[0] + va[1]*vb[1] + va[2]*vb[2]
+	angle := math.Acos(math.Max(-1, math.Min(1, dot)))
+
+	// Identical or antipodal points have no single great circle between them
+	if angle < 1e-9 || math.Pi-angle < 1e-9 {
+		return []*pb.LatLng{{Lat: a.Lat, Lng: a.Lng}, {Lat: b.Lat, Lng: b.Lng}}
+	}
+
+	points := make([]*pb.LatLng, 0, segments+1)
+	points = append(points, &pb.LatLng{Lat: a.Lat, Lng: a.Lng})
+	sin := math.Sin(angle)
+	for i := 1; i < segments; i++ {
+		f := float64(i) / float64(segments)
+		wa, wb := math.Sin((1-f)*angle)/sin, math.Sin(f*angle)/sin
+		x, y, z := wa*va[0]+wb*vb[0], wa*va[1]+wb*vb[1], wa*va[2]+wb*vb[2]
+		points = append(points, &pb.LatLng{
+			Lat: math.Atan2(z, math.Hypot(x, y)) * 180 / math.Pi,
+			Lng: math.Atan2(y, x) * 180 / math.Pi,
+		})
+	}
+	return append(points, &pb.LatLng{Lat: b.Lat, Lng: b.Lng})
+}
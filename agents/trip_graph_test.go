@@ -0,0 +1,123 @@
+package agents
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// tripGraphFixture is a London -> Paris -> London trip with a day trip to Versailles.
+// Nodes and edges are deliberately out of order.
+func tripGraphFixture() *pb.Itinerary {
+	outbound := flightEdge("london", "paris", &pb.Flight{
+		CarrierCode: "BA", FlightNumber: "304",
+		DepartureTime: statsTime(10, 8), ArrivalTime: statsTime(10, 10),
+	})
+	outbound.Transport.OriginLocation = &pb.Location{IataCodes: []string{"LHR"}}
+	outbound.Transport.DestinationLocation = &pb.Location{IataCodes: []string{"CDG"}}
+	outbound.Transport.Cost = &pb.Cost{Value: 120, Currency: "EUR"}
+
+	return &pb.Itinerary{Title: "Paris", Graph: &pb.Graph{
+		Nodes: []*pb.Node{
+			{
+				Id:       "paris",
+				Location: &pb.Location{City: "Paris", Geocode: "48.856600,2.352200"},
+				Stay:     &pb.Accommodation{Name: "Hotel Lutetia", CheckIn: statsTime(10, 15), CheckOut: statsTime(13, 10)},
+				SubGraph: &pb.Graph{
+					Nodes: []*pb.Node{
+						{Id: "versailles", Location: &pb.Location{City: "Versailles", Geocode: "48.804900,2.120400"}, FromTimestamp: statsTime(11, 10), Notes: "Picnic by the Grand Canal"},
+					},
+				},
+			},
+			{Id: "london", Location: &pb.Location{City: "London", Geocode: "51.507400,-0.127800"}},
+		},
+		Edges: []*pb.Edge{
+			trainEdge("paris", "london", statsTime(13, 12), statsTime(13, 14)),
+			outbound,
+		},
+	}}
+}
+
+func TestBuildTripGraph(t *testing.T) {
+	it := tripGraphFixture()
+	original := proto.Clone(it)
+
+	tg := BuildTripGraph(it)
+
+	assert.True(t, proto.Equal(original, it), "the itinerary must not be modified")
+	assert.Empty(t, tg.Warnings)
+
+	// Nodes in visiting order
+	if assert.Len(t, tg.Nodes, 2) {
+		assert.Equal(t, "london", tg.Nodes[0].Id)
+		assert.Equal(t, pb.TripGraphNodeType_TRIP_GRAPH_NODE_TYPE_ORIGIN, tg.Nodes[0].Type)
+		assert.Equal(t, statsTime(10, 8).AsTime(), tg.Nodes[0].EndTime.AsTime(), "the origin is left on the first departure")
+
+		assert.Equal(t, "paris", tg.Nodes[1].Id)
+		assert.Equal(t, "Paris", tg.Nodes[1].Label)
+		assert.Equal(t, pb.TripGraphNodeType_TRIP_GRAPH_NODE_TYPE_STAY, tg.Nodes[1].Type)
+		assert.Equal(t, statsTime(10, 15).AsTime(), tg.Nodes[1].StartTime.AsTime())
+		assert.Equal(t, statsTime(13, 10).AsTime(), tg.Nodes[1].EndTime.AsTime())
+	}
+
+	// Edges in departure order, drawn from node to node
+	if assert.Len(t, tg.Edges, 2) {
+		out, back := tg.Edges[0], tg.Edges[1]
+		assert.Equal(t, "london", out.FromId)
+		assert.Equal(t, pb.TransportType_TRANSPORT_TYPE_FLIGHT, out.Mode)
+		assert.Equal(t, int64(2*3600), out.DurationSeconds)
+		assert.Equal(t, "Flight BA 304 LHR-CDG, 120.00 EUR", out.Summary)
+		if assert.Len(t, out.Polyline, polylineSegments+1) {
+			assert.True(t, proto.Equal(tg.Nodes[0].Position, out.Polyline[0]))
+			assert.True(t, proto.Equal(tg.Nodes[1].Position, out.Polyline[polylineSegments]))
+			for _, p := range out.Polyline[1:polylineSegments] {
+				assert.Greater(t, p.Lat, 48.8566)
+				assert.Less(t, p.Lat, 51.5074)
+			}
+		}
+
+		assert.Equal(t, "paris", back.FromId)
+		assert.Equal(t, pb.TransportType_TRANSPORT_TYPE_TRAIN, back.Mode)
+		assert.Equal(t, "Train", back.Summary)
+		if assert.NotEmpty(t, back.Polyline) {
+			assert.True(t, proto.Equal(tg.Nodes[1].Position, back.Polyline[0]))
+			assert.True(t, proto.Equal(tg.Nodes[0].Position, back.Polyline[len(back.Polyline)-1]))
+		}
+	}
+
+	// The day trip is a group inside Paris
+	if assert.Len(t, tg.Groups, 1) {
+		assert.Equal(t, "paris", tg.Groups[0].NodeId)
+		if assert.Len(t, tg.Groups[0].Graph.Nodes, 1) {
+			assert.Equal(t, "versailles", tg.Groups[0].Graph.Nodes[0].Id)
+			assert.Equal(t, "Picnic by the Grand Canal", tg.Groups[0].Graph.Nodes[0].Notes, "the user's notes are shown with the node")
+		}
+	}
+}
+
+func TestBuildTripGraph_MissingCoordinates(t *testing.T) {
+	it := tripGraphFixture()
+	it.Graph.Nodes[0].Location.Geocode = ""
+	it.Graph.Nodes[0].SubGraph.Nodes[0].Location.Geocode = "not a geocode"
+
+	tg := BuildTripGraph(it)
+
+	if assert.Len(t, tg.Nodes, 1) {
+		assert.Equal(t, "london", tg.Nodes[0].Id)
+	}
+	assert.Empty(t, tg.Edges, "edges to a node that is not shown are left out")
+	assert.Equal(t, []string{
+		`node "paris" (Paris) has no coordinates and is not shown`,
+		`node "paris/versailles" (Versailles) has no coordinates and is not shown`,
+		"edge london->paris is not shown: an endpoint has no coordinates",
+		"edge paris->london is not shown: an endpoint has no coordinates",
+	}, tg.Warnings)
+
+	// The stay's location is used when the node has none
+	it.Graph.Nodes[0].Stay.Location = &pb.Location{Geocode: "48.8566,2.3522"}
+	tg = BuildTripGraph(it)
+	assert.Len(t, tg.Nodes, 2)
+	assert.Len(t, tg.Edges, 2)
+}
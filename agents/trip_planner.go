@@ -3,14 +3,23 @@ package agents
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/firebase/genkit/go/ai"
+	genkitcore "github.com/firebase/genkit/go/core"
 	"github.com/firebase/genkit/go/genkit"
+	tmcontext "github.com/va6996/travelingman/context"
+	"github.com/va6996/travelingman/core"
 	"github.com/va6996/travelingman/log"
 	"github.com/va6996/travelingman/pb"
+	"github.com/va6996/travelingman/prompts"
 	"github.com/va6996/travelingman/tools"
 	"google.golang.org/protobuf/encoding/protojson"
 )
@@ -21,12 +30,40 @@ type TripPlanner struct {
 	registry *tools.Registry
 	model    ai.Model
 	// askUser  ai.Tool
+
+	// schema validates the planner output; schemaJSON is the schema given in the prompt
+	// and example a complete answer that follows it
+	schema     *core.Schema
+	schemaJSON string
+	example    string
+
+	// Prompts supplies the system prompt template, so it can be changed without a rebuild
+	Prompts *prompts.Library
+
+	// Clock decides the date given to the model as "today" and the date tool's 'now'.
+	// Nil uses the wall clock, or a clock already attached to the request context.
+	Clock tmcontext.Clock
+
+	// Timeout caps a planning request, tool calls included; zero uses defaultTimeout
+	Timeout time.Duration
+
+	// Fallbacks are tried in order when the model is unavailable, e.g. out of quota or
+	// overloaded. The first that answers also makes any correction of its answer.
+	Fallbacks []ai.Model
 }
 
 // PlanRequest contains the user's query and context
 type PlanRequest struct {
 	UserQuery string
 	History   string
+	// MaxTurns caps the model turns (tool round trips) per generation; zero uses defaultMaxTurns
+	MaxTurns int
+	// Timeout replaces the planner's timeout for this request when set
+	Timeout time.Duration
+	// WithoutTools withholds the tools whose names start with any of these prefixes
+	WithoutTools []string
+	// Pins are the parts of the trip the user fixed, which every plan must keep
+	Pins TripPins
 }
 
 // PlanResult contains the generated itinerary or a clarifying question
@@ -36,6 +73,9 @@ type PlanResult struct {
 	NeedsClarification  bool
 	Question            string
 	Reasoning           string
+	// Exploratory is set when the user is flexible on the destination; each itinerary
+	// is then one candidate destination
+	Exploratory bool
 }
 
 // AskUserRequest is the input for the askUser tool
@@ -43,124 +83,11 @@ type AskUserRequest struct {
 	Question string `json:"question" description:"The clarifying question to ask the user"`
 }
 
-const SYSTEM_PROMPT = `You are an expert Trip Planner. Your goal is to create a high-level travel itinerary.
-
-IMPORTANT WORKFLOW:
-1. First, gather information using tools ONLY if needed:
-   - ALWAYS use dateTool to calculate dates. usage:
-     - The tool returns a JSON list of ISO strings: ["2026-01-25", "2026-01-28"]
-     - For ONE-WAY trips, use the first date.
-     - For RETURN/ROUND trips, use the first date as start and second as end.
-     - For EXTENDED/MULTI-CITY trips, request multiple dates.
-
-2. Then, create the itinerary JSON with the gathered information:
-   - DO NOT call hotelTool or flightTool - these are for the TravelDesk, not for planning
-   - Return the itinerary json with destination, dates, and activities
-   - If the user only requests for flights/hotels, return the itinerary json with only flights/hotels
-
-CURRENCY HANDLING:
-- The system will automatically infer the currency based on the origin country (e.g. US -> USD, UK -> GBP).
-- YOU MUST use this inferred currency for ALL cost calculations and bookings (including hotels in other countries). Do not switch currencies.
-- Ensure all prices are in the same currency (e.g. if flying from US, hotel price must be in USD).
-
-CRITICAL RULES:
-- If the user specifies a timeframe (like "next weekend"), use dateTool to calculate it, then create the itinerary
-- Structure your response exactly as the JSON schema below. Use camelCase for keys
-- If the user requests a round/circle trip, the final edge must return to the ID of the starting Node. Do NOT create a duplicate 'Home' node.
-- Do not ask for clarifications. Infer everything you need from the user's query from the perspective of source location
-- Source Location Node: You MUST include the starting node (e.g., 'start_loc') in the 'nodes' array.
-
-BROAD SEARCH:
-- If the user request is broad (e.g., "any weekend in April"), you MUST generate multiple distinct itineraries (e.g., 3-4 options for different weekends) in the "itineraries" JSON array.
-- Each itinerary in the array must be a complete, valid trip plan.
-
-DAY ACTIVITIES:
-- For detailed daily plans, populate the "sub_graph" field within the specific Node (e.g., the 'Paris' node). This sub-graph should contain nodes for activities (restaurants, museums) and edges for travel between them.
-
-Final Answer Schema:
-{
-  "itineraries": [
-    {
-      "title": "Weekend in Paris",
-      "description": "A wonderful weekend trip to Paris visiting key landmarks.",
-      "startTime": "2026-01-25T10:00:00Z",
-      "endTime": "2026-01-27T18:00:00Z",
-      "travelers": 2,
-      "journeyType": "JOURNEY_TYPE_RETURN",
-      "graph": {
-        "nodes": [
-          {
-            "id": "start_loc",
-            "location": { "iataCodes": ["JFK"], "city": "New York", "country": "USA" }
-          },
-          {
-            "id": "node_1",
-            "location": { "cityCode": "PAR" },
-            "fromTimestamp": "2026-01-25T14:00:00Z",
-            "toTimestamp": "2026-01-27T11:00:00Z",
-            "stay": {
-              "name": "Hotel Paris",
-              "location": { "iataCodes": ["CDG"], "city": "Paris", "country": "France" },
-              "checkIn": "2026-01-25T14:00:00Z",
-              "checkOut": "2026-01-27T11:00:00Z",
-              "travelerCount": 2,
-              "preferences": {
-                "roomType": "Standard",
-                "area": "City Center",
-                "rating": 4,
-                "amenities": ["wifi", "breakfast"]
-              }
-            },
-            "sub_graph": {
-                "nodes": [
-                    { "id": "act_1", "location": "Eiffel Tower", "type": "ACTIVITY" }
-                ],
-                "edges": [
-                    { "fromId": "node_1", "toId": "act_1", "transport": { "type": "TRANSPORT_TYPE_TAXI" } }
-                ]
-            }
-          }
-        ],
-        "edges": [
-          {
-            "fromId": "start_loc",
-            "toId": "node_1",
-            "durationSeconds": 25200,
-            "transport": {
-              "type": "TRANSPORT_TYPE_FLIGHT",
-              "travelerCount": 2,
-              "flightPreferences": { "travelClass": "CLASS_ECONOMY" },
-              "flight": {
-                "departureTime": "2026-01-25T10:00:00Z",
-                "arrivalTime": "2026-01-25T17:00:00Z"
-              },
-              "originLocation": { "iataCodes": ["JFK"] },
-              "destinationLocation": { "iataCodes": ["CDG"] }
-            }
-            }
-          },
-          {
-            "fromId": "node_1",
-            "toId": "start_loc",
-            "durationSeconds": 28800,
-            "transport": {
-              "type": "TRANSPORT_TYPE_FLIGHT",
-              "travelerCount": 2,
-              "flightPreferences": { "travelClass": "CLASS_ECONOMY" },
-              "flight": {
-                "departureTime": "2026-01-27T11:00:00Z",
-                "arrivalTime": "2026-01-27T19:00:00Z"
-              },
-              "originLocation": { "iataCodes": ["CDG"] },
-              "destinationLocation": { "iataCodes": ["JFK"] }
-            }
-          }
-        ]
-      }
-    }
-  ],
-  "reasoning": "Calculated next weekend as Jan 25-27, 2026 and constructed graph with flight to Paris and hotel stay."
-}`
+// defaultMaxTurns is the automatic tool-calling iteration limit for a full plan
+const defaultMaxTurns = 15
+
+// defaultTimeout caps a planning request when the planner has no Timeout
+const defaultTimeout = 220 * time.Second
 
 // NewTripPlanner creates a new TripPlanner with Genkit native tool calling
 func NewTripPlanner(gk *genkit.Genkit, registry *tools.Registry, model ai.Model) *TripPlanner {
@@ -178,48 +105,104 @@ func NewTripPlanner(gk *genkit.Genkit, registry *tools.Registry, model ai.Model)
 
 	// toolRefs = append(toolRefs, p.askUser)
 
+	// The schema only depends on the compiled protos, so build it once
+	schema := core.PlannerResponseSchema()
+	schemaJSON, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		// Schema is built from static descriptors; this cannot fail at runtime
+		panic(fmt.Sprintf("failed to marshal planner schema: %v", err))
+	}
+
 	return &TripPlanner{
 		genkit:   gk,
 		registry: registry,
 		model:    model,
 		// askUser:  askUser,
+		schema:     schema,
+		schemaJSON: string(schemaJSON),
+		example:    core.ReferenceAnswer(),
+		Prompts:    prompts.New(),
 	}
 }
 
+// Capabilities reports that the planner validates its answers against the schema,
+// streams to the request's sinks and calls tools
+func (p *TripPlanner) Capabilities() PlannerCapabilities {
+	return PlannerCapabilities{Streaming: true, ToolCalling: true}
+}
+
 func (p *TripPlanner) Plan(ctx context.Context, req PlanRequest) (*PlanResult, error) {
 	log.Infof(ctx, "TripPlanner: Planning for query: %s", req.UserQuery)
 
-	// Inject current date context into system prompt
-	today := time.Now().Format("2006-01-02")
-	systemPromptWithDate := fmt.Sprintf("Today is %s.\n%s", today, SYSTEM_PROMPT)
-	log.Debugf(ctx, "Full system prompt: %s", systemPromptWithDate)
+	if p.Clock != nil {
+		ctx = tmcontext.WithClock(ctx, p.Clock)
+	}
 
-	log.Debugf(ctx, "Calling genkit.Generate with model: %v, tools: %d", p.model, len(p.registry.GetTools()))
+	systemPrompt, err := p.systemPrompt(ctx, req)
+	if err != nil {
+		log.Errorf(ctx, "TripPlanner: %v", err)
+		return nil, fmt.Errorf("planning failed: %w", err)
+	}
+	log.Tracef(ctx, "Full system prompt: %s", systemPrompt)
 
-	// Use configured timeout for the planning process
-	// Default to 220s if not set (though Config should handle defaults)
-	// We'll hardcode here if needed or pass config to TripPlanner?
-	// The TripPlanner struct doesn't have the config yet.
-	// For this task, we will stick to the hardcoded/env default from config via setup but TripPlanner needs access.
-	// Let's assume the context passed in already has a deadline or we rely on the caller (TravelAgent) to set it?
-	// But `genkit.Generate` is called here.
-	// The config says "PlannerConfig.Timeout". We should ideally pass this to NewTripPlanner.
+	toolRefs := p.registry.GetToolRefsExcept(req.WithoutTools...)
+	log.Debugf(ctx, "Calling genkit.Generate with model: %v, tools: %d", p.model, len(toolRefs))
 
-	// For now, I'll update NewTripPlanner signature in next step or just hardcode to match the config default if I can't change signature easily without cascading.
-	// Wait, I updated Config with `PlannerConfig`. I should pass the timeout value to `NewTripPlanner`.
+	maxTurns := req.MaxTurns
+	if maxTurns <= 0 {
+		maxTurns = defaultMaxTurns
+	}
+	timeout := req.Timeout
+	if timeout <= 0 {
+		timeout = p.Timeout
+	}
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
 
-	tCtx, cancel := context.WithTimeout(ctx, 220*time.Second) // Default 2 minutes -> Updated to 220s default in config
+	// A tool that fails for good is not called again within this plan, and none may run
+	// longer than the registry allows
+	tCtx, cancel := context.WithTimeout(tools.WithToolTimeouts(tools.WithToolFailures(ctx), p.registry), timeout)
 	defer cancel()
 
+	// Streaming requests see the model's text as it arrives and each tool it ran
+	var streaming []ai.GenerateOption
+	sink, progress := tmcontext.TextSinkFromContext(ctx), tmcontext.ProgressSinkFromContext(ctx)
+	if sink != nil || progress != nil {
+		streaming = append(streaming, ai.WithStreaming(func(ctx context.Context, chunk *ai.ModelResponseChunk) error {
+			if text := chunk.Text(); text != "" && sink != nil {
+				sink(text)
+			}
+			for _, part := range chunk.Content {
+				if part.IsToolResponse() {
+					progress.Report(pb.PlanStage_PLAN_STAGE_TOOL_CALL, part.ToolResponse.Name)
+				}
+			}
+			return nil
+		}))
+	}
+
 	// Use Genkit's native tool calling with automatic iteration
-	response, err := genkit.Generate(tCtx,
-		p.genkit,
-		ai.WithModel(p.model),
-		ai.WithSystem(systemPromptWithDate),
-		ai.WithPrompt(req.UserQuery),
-		ai.WithTools(p.registry.GetToolRefs()...),
-		ai.WithMaxTurns(15), // Automatic iteration limit
-	)
+	models := append([]ai.Model{p.model}, p.Fallbacks...)
+	var model ai.Model
+	var response *ai.ModelResponse
+	for i := range models {
+		model = models[i]
+		response, err = genkit.Generate(tCtx,
+			p.genkit,
+			append([]ai.GenerateOption{
+				ai.WithModel(model),
+				ai.WithSystem(systemPrompt),
+				ai.WithPrompt(req.UserQuery),
+				ai.WithTools(toolRefs...),
+				ai.WithMaxTurns(maxTurns), // Automatic iteration limit
+			}, streaming...)...,
+		)
+		if err == nil || i == len(models)-1 || !modelUnavailable(err) {
+			break
+		}
+		log.Warnf(ctx, "TripPlanner: Model %s unavailable (%v), falling back to %s", model.Name(), err, models[i+1].Name())
+	}
 	if err != nil {
 		log.Errorf(ctx, "TripPlanner: Generate error: %v", err)
 		return nil, fmt.Errorf("planning failed: %w", err)
@@ -227,42 +210,44 @@ func (p *TripPlanner) Plan(ctx context.Context, req PlanRequest) (*PlanResult, e
 
 	log.Infof(ctx, "Response finish reason: %v", response.FinishReason)
 
-	// Handle interrupts (askUser tool calls)
-	for response.FinishReason == ai.FinishReasonInterrupted {
-		var answers []*ai.Part
-		// for _, part := range response.Interrupts() {
-		// 	if part.ToolRequest.Name == "askUser" {
-		// 		// Extract the question from metadata
-		// 		question := part.ToolRequest.Input.(map[string]any)["question"]
-		// 		log.Printf(ctx, "TripPlanner: Asking user: %s", question)
-
-		// 		// Return the question to the user
-		// 		return &PlanResult{
-		// 			NeedsClarification: true,
-		// 			Question:           fmt.Sprintf("%v", question),
-		// 		}, nil
-		// 	}
-		// }
-
-		// If we handled all interrupts, continue generation
-		if len(answers) > 0 {
-			response, err = genkit.Generate(tCtx,
-				p.genkit,
-				ai.WithMessages(response.History()...),
-				ai.WithTools(p.registry.GetToolRefs()...),
-				ai.WithToolResponses(answers...),
-				ai.WithMaxTurns(15),
-			)
-			if err != nil {
-				return nil, fmt.Errorf("planning continuation failed: %w", err)
-			}
-		} else {
-			break
-		}
+	// Failed tools interrupt generation, which goes on with the model told their errors
+	continueOpts := append([]ai.GenerateOption{
+		ai.WithModel(model),
+		ai.WithTools(toolRefs...),
+		ai.WithMaxTurns(maxTurns),
+	}, streaming...)
+	response, err = p.answerToolErrors(tCtx, response, maxTurns, continueOpts)
+	if err != nil {
+		return nil, fmt.Errorf("planning continuation failed: %w", err)
 	}
 
 	text := response.Text()
-	log.Infof(ctx, "LLM Final Response: %s", text)
+	log.Debugf(ctx, "LLM Final Response: %s", text)
+
+	// Some models answer with the code of a tool call instead of the itinerary.
+	// Ask once more within this plan, so it does not cost the agent an iteration.
+	if looksLikeCode(text) {
+		log.Warnf(ctx, "TripPlanner: Response is code instead of JSON, asking for the itinerary again")
+
+		response, err = genkit.Generate(tCtx,
+			p.genkit,
+			append([]ai.GenerateOption{
+				ai.WithModel(model),
+				ai.WithMessages(append(response.History(), ai.NewUserTextMessage(codeInstruction))...),
+				ai.WithTools(toolRefs...),
+				ai.WithMaxTurns(maxTurns),
+			}, streaming...)...,
+		)
+		if err == nil {
+			response, err = p.answerToolErrors(tCtx, response, maxTurns, continueOpts)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("planning correction failed: %w", err)
+		}
+
+		text = response.Text()
+		log.Debugf(ctx, "LLM Response after code: %s", text)
+	}
 
 	// Extract JSON from response
 	extractedJSON := extractUsageJSON(text)
@@ -270,49 +255,223 @@ func (p *TripPlanner) Plan(ctx context.Context, req PlanRequest) (*PlanResult, e
 		text = extractedJSON
 	}
 
-	// Try to parse as final answer
-	var finalAnswer struct {
+	// Check the output against the schema before conversion, since protojson
+	// silently drops fields the model got wrong. Give the model one chance to fix them.
+	if violations := p.schema.Validate([]byte(text)); len(violations) > 0 {
+		log.Warnf(ctx, "TripPlanner: Response has %d schema violations: %v", len(violations), violations)
+
+		response, err = genkit.Generate(tCtx,
+			p.genkit,
+			append([]ai.GenerateOption{
+				ai.WithModel(model),
+				ai.WithMessages(append(response.History(), ai.NewUserTextMessage(correctiveInstruction(violations)))...),
+				ai.WithTools(toolRefs...),
+				ai.WithMaxTurns(maxTurns),
+			}, streaming...)...,
+		)
+		if err == nil {
+			response, err = p.answerToolErrors(tCtx, response, maxTurns, continueOpts)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("planning correction failed: %w", err)
+		}
+
+		text = response.Text()
+		log.Debugf(ctx, "LLM Corrected Response: %s", text)
+		if extractedJSON := extractUsageJSON(text); extractedJSON != "" {
+			text = extractedJSON
+		}
+		if violations := p.schema.Validate([]byte(text)); len(violations) > 0 {
+			log.Warnf(ctx, "TripPlanner: Corrected response still has %d schema violations: %v", len(violations), violations)
+		}
+	}
+
+	// The final history includes every turn, including any correction
+	recordPlannerActivity(tmcontext.RequestStatsFromContext(ctx), response.History())
+
+	if result := parsePlannerAnswer(ctx, text); result != nil {
+		return result, nil
+	}
+
+	// Fallback: return raw text
+	log.Warnf(ctx, "TripPlanner: Could not parse response, returning raw text %s", text)
+	return &PlanResult{
+		Question: "I couldn't generate a proper itinerary. Here's what I found: " + text,
+	}, nil
+}
+
+// answerToolErrors continues a generation that failed tools interrupted, giving the
+// model each tool's error as the tool's response so it can call the tool again, e.g.
+// with other arguments, or do without it. It stops when the generation is not
+// interrupted by failed tools or after maxTurns continuations.
+func (p *TripPlanner) answerToolErrors(ctx context.Context, response *ai.ModelResponse, maxTurns int, opts []ai.GenerateOption) (*ai.ModelResponse, error) {
+	for range maxTurns {
+		if response.FinishReason != ai.FinishReasonInterrupted {
+			break
+		}
+		answered, responses := []*ai.Part{}, []*ai.Part{}
+		for _, part := range response.Message.Content {
+			if !part.IsToolRequest() {
+				answered = append(answered, part)
+				continue
+			}
+			var output any
+			if te, ok := tools.ToolErrorOf(part); ok {
+				log.Warnf(ctx, "TripPlanner: Tool %s failed (%s, retryable: %v): %s", part.ToolRequest.Name, te.Code, te.Retryable, te.Message)
+				output = map[string]any{"error": te}
+			} else if pending, ok := part.Metadata["pendingOutput"]; ok {
+				output = pending
+			} else {
+				// Interrupted for another reason, which the planner cannot answer
+				return response, nil
+			}
+			req := *part
+			req.Metadata = maps.Clone(part.Metadata)
+			delete(req.Metadata, "interrupt")
+			delete(req.Metadata, "pendingOutput")
+			answered = append(answered, &req)
+			responses = append(responses, ai.NewResponseForToolRequest(part, output))
+		}
+
+		history := slices.Clone(response.Request.Messages)
+		history = append(history,
+			&ai.Message{Role: ai.RoleModel, Content: answered},
+			&ai.Message{Role: ai.RoleTool, Content: responses},
+		)
+		var err error
+		response, err = genkit.Generate(ctx, p.genkit, append([]ai.GenerateOption{ai.WithMessages(history...)}, opts...)...)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return response, nil
+}
+
+// modelUnavailable reports whether a generation error means the model cannot serve
+// requests right now, e.g. it is out of quota or overloaded, rather than that the
+// request was bad or timed out
+func modelUnavailable(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var gerr *genkitcore.GenkitError
+	if errors.As(err, &gerr) && (gerr.Status == genkitcore.RESOURCE_EXHAUSTED || gerr.Status == genkitcore.UNAVAILABLE) {
+		return true
+	}
+	// Provider errors are passed through as text, e.g. "Error 429, RESOURCE_EXHAUSTED"
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"resource_exhausted", "unavailable", "quota", "overloaded", "rate limit", "429", "503"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// parsePlannerAnswer converts the planner's final answer, a JSON object with the
+// itineraries as graphs, into a result. It returns nil if text is not such an answer.
+// Itineraries that do not convert are logged and left out.
+func parsePlannerAnswer(ctx context.Context, text string) *PlanResult {
+	var answer struct {
 		Itineraries []json.RawMessage `json:"itineraries"`
 		Reasoning   string            `json:"reasoning"`
+		Exploratory bool              `json:"exploratory"`
+	}
+	if err := json.Unmarshal([]byte(text), &answer); err != nil || len(answer.Itineraries) == 0 {
+		return nil
+	}
+	log.Infof(ctx, "TripPlanner: Generated %d itineraries", len(answer.Itineraries))
+
+	result := &PlanResult{Reasoning: answer.Reasoning, Exploratory: answer.Exploratory}
+	// Fields outside the schema are dropped rather than failing the whole itinerary
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	for i, raw := range answer.Itineraries {
+		log.Tracef(ctx, "TripPlanner: Itinerary %d: %s", i, string(raw))
+		it := &pb.Itinerary{}
+		if err := unmarshaler.Unmarshal(raw, it); err != nil {
+			log.Warnf(ctx, "TripPlanner: Failed to unmarshal itinerary %d: %v", i, err)
+			continue
+		}
+		result.PossibleItineraries = append(result.PossibleItineraries, it)
 	}
+	return result
+}
 
-	if err := json.Unmarshal([]byte(text), &finalAnswer); err == nil {
-		// Handle itineraries
-		if len(finalAnswer.Itineraries) > 0 {
-			log.Infof(ctx, "TripPlanner: Generated %d itineraries", len(finalAnswer.Itineraries))
+// systemPrompt renders the planner's prompt template for the request and records
+// which version of it was used
+func (p *TripPlanner) systemPrompt(ctx context.Context, req PlanRequest) (string, error) {
+	tmpl, ok := p.Prompts.Get(prompts.TripPlanner)
+	if !ok {
+		return "", fmt.Errorf("no %s prompt template", prompts.TripPlanner)
+	}
 
-			for i, itinerary := range finalAnswer.Itineraries {
-				log.Infof(ctx, "TripPlanner: Itinerary %d: %s", i, string(itinerary))
-			}
+	var toolNames []string
+	for _, t := range p.registry.GetToolRefsExcept(req.WithoutTools...) {
+		toolNames = append(toolNames, t.Name())
+	}
+	sort.Strings(toolNames)
+
+	text, err := tmpl.Render(prompts.Vars{
+		Tools:        toolNames,
+		Now:          tmcontext.Now(ctx),
+		Schema:       p.schemaJSON,
+		ExampleQuery: core.ReferenceQuery,
+		Example:      p.example,
+		Query:        req.UserQuery,
+		Issues:       req.History,
+		Pins:         req.Pins.Lines(),
+	})
+	if err != nil {
+		return "", err
+	}
+	log.Debugf(ctx, "TripPlanner: Using prompt %s", tmpl.Version)
+	tmcontext.RequestStatsFromContext(ctx).SetPrompt(prompts.TripPlanner, tmpl.Version)
+	return text, nil
+}
 
-			result := &PlanResult{
-				Reasoning: finalAnswer.Reasoning,
+// recordPlannerActivity counts the model turns and tool calls in a planning conversation
+func recordPlannerActivity(stats *tmcontext.RequestStats, history []*ai.Message) {
+	for _, msg := range history {
+		if msg.Role != ai.RoleModel {
+			continue
+		}
+		stats.AddPlannerSteps(1)
+		for _, part := range msg.Content {
+			if part.IsToolRequest() {
+				stats.AddToolCall(part.ToolRequest.Name)
 			}
+		}
+	}
+}
 
-			// Configure protojson unmarshaler to discard unknown fields
-			unmarshaler := protojson.UnmarshalOptions{
-				DiscardUnknown: true,
-			}
+// correctiveInstruction asks the model to fix the listed schema violations
+func correctiveInstruction(violations []core.SchemaViolation) string {
+	var sb strings.Builder
+	sb.WriteString("Your JSON does not match the Final Answer Schema. Fix these problems and return the complete corrected JSON:\n")
+	for _, v := range violations {
+		sb.WriteString("- ")
+		sb.WriteString(v.String())
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
 
-			// Convert possible itineraries
-			for i := range finalAnswer.Itineraries {
-				pbItin := &pb.Itinerary{}
-				if err := unmarshaler.Unmarshal(finalAnswer.Itineraries[i], pbItin); err == nil {
-					result.PossibleItineraries = append(result.PossibleItineraries, pbItin)
-				} else {
-					log.Warnf(ctx, "TripPlanner: Failed to unmarshal itinerary %d: %v", i, err)
-				}
-			}
+// codeInstruction asks a model that answered with code for the itinerary JSON
+const codeInstruction = "You wrote code instead of the itinerary. Do not write functions or scripts: " +
+	"call the tools you need directly, then return only the JSON object described by the Final Answer Schema."
+
+// codePattern matches a function definition at the start of a line, or a code fence
+// for a language other than JSON
+var codePattern = regexp.MustCompile("(?m)^\\s*(?:(?:async\\s+)?(?:def|function)\\s+\\w*\\s*\\(|```[ \\t]*(?:[A-Za-z][\\w+#-]*))")
 
-			return result, nil
+// looksLikeCode reports whether a response is source code rather than JSON
+func looksLikeCode(text string) bool {
+	for _, m := range codePattern.FindAllString(text, -1) {
+		if lang := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(m), "```")); !strings.EqualFold(lang, "json") {
+			return true
 		}
 	}
-
-	// Fallback: return raw text
-	log.Warnf(ctx, "TripPlanner: Could not parse response, returning raw text %s", text)
-	return &PlanResult{
-		Question: "I couldn't generate a proper itinerary. Here's what I found: " + text,
-	}, nil
+	return false
 }
 
 // Helper to map string class to pb enum
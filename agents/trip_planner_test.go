@@ -1,5 +1,26 @@
 package agents
 
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+	genkitcore "github.com/firebase/genkit/go/core"
+	"github.com/firebase/genkit/go/genkit"
+	"github.com/stretchr/testify/assert"
+	tmcontext "github.com/va6996/travelingman/context"
+	"github.com/va6996/travelingman/core"
+	"github.com/va6996/travelingman/pb"
+	"github.com/va6996/travelingman/tools"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
 /*
 // MockLLMClient
 type MockLLMClient struct {
@@ -27,3 +48,314 @@ func TestTripPlanner_Plan_MultipleItineraries(t *testing.T) {
 	// ... (content commented out)
 }
 */
+
+func TestParsePlannerAnswer(t *testing.T) {
+	ctx := context.Background()
+	want := core.ReferenceItinerary()
+
+	// The worked example in the prompt converts to the graph it was built from
+	result := parsePlannerAnswer(ctx, core.ReferenceAnswer())
+	if assert.NotNil(t, result) && assert.Len(t, result.PossibleItineraries, 1) {
+		assert.True(t, proto.Equal(want, result.PossibleItineraries[0]), "reference answer converted to a different graph")
+		assert.NotEmpty(t, result.Reasoning)
+	}
+
+	// The same plan written with proto field names and a field outside the schema
+	// converts to the same graph
+	raw, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(want)
+	if !assert.NoError(t, err) {
+		return
+	}
+	answer := `{"itineraries": [` + strings.Replace(string(raw), `{`, `{"mood": "relaxed", `, 1) + `]}`
+	result = parsePlannerAnswer(ctx, answer)
+	if assert.NotNil(t, result) && assert.Len(t, result.PossibleItineraries, 1) {
+		assert.True(t, proto.Equal(want, result.PossibleItineraries[0]), "equivalent answer converted to a different graph")
+	}
+
+	// Candidate destinations are marked as such
+	result = parsePlannerAnswer(ctx, `{"itineraries": [`+string(raw)+`], "exploratory": true}`)
+	if assert.NotNil(t, result) {
+		assert.True(t, result.Exploratory)
+	}
+
+	assert.Nil(t, parsePlannerAnswer(ctx, `{"itineraries": []}`))
+	assert.Nil(t, parsePlannerAnswer(ctx, "Here is your trip to Paris"))
+}
+
+func TestTripPlanner_Plan_FallbackModel(t *testing.T) {
+	ctx := context.Background()
+	gk := genkit.Init(ctx)
+
+	var calls []string
+	failing := func(name string, err error) ai.Model {
+		return genkit.DefineModel(gk, name, &ai.ModelOptions{Supports: &ai.ModelSupports{Multiturn: true, SystemRole: true}},
+			func(ctx context.Context, req *ai.ModelRequest, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+				calls = append(calls, name)
+				return nil, err
+			})
+	}
+	outOfQuota := failing("test/out-of-quota", genkitcore.NewError(genkitcore.RESOURCE_EXHAUSTED, "quota exceeded"))
+	broken := failing("test/broken", errors.New("invalid request"))
+	backup := genkit.DefineModel(gk, "test/backup", &ai.ModelOptions{Supports: &ai.ModelSupports{Multiturn: true, SystemRole: true}},
+		func(ctx context.Context, req *ai.ModelRequest, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+			calls = append(calls, "test/backup")
+			return &ai.ModelResponse{Request: req, Message: ai.NewModelTextMessage(core.ReferenceAnswer()), FinishReason: ai.FinishReasonStop}, nil
+		})
+
+	// The backup answers when the model is out of quota
+	planner := NewTripPlanner(gk, tools.NewRegistry(), outOfQuota)
+	planner.Fallbacks = []ai.Model{backup}
+	result, err := planner.Plan(ctx, PlanRequest{UserQuery: "London to New York"})
+	if assert.NoError(t, err) {
+		assert.Len(t, result.PossibleItineraries, 1)
+	}
+	assert.Equal(t, []string{"test/out-of-quota", "test/backup"}, calls)
+
+	// Other errors are returned without trying the fallbacks
+	calls = nil
+	planner = NewTripPlanner(gk, tools.NewRegistry(), broken)
+	planner.Fallbacks = []ai.Model{backup}
+	_, err = planner.Plan(ctx, PlanRequest{UserQuery: "London to New York"})
+	assert.ErrorContains(t, err, "invalid request")
+	assert.Equal(t, []string{"test/broken"}, calls)
+
+	// The last error is returned when every model is unavailable
+	calls = nil
+	planner = NewTripPlanner(gk, tools.NewRegistry(), outOfQuota)
+	planner.Fallbacks = []ai.Model{failing("test/overloaded", genkitcore.NewError(genkitcore.UNAVAILABLE, "model overloaded"))}
+	_, err = planner.Plan(ctx, PlanRequest{UserQuery: "London to New York"})
+	assert.ErrorContains(t, err, "model overloaded")
+	assert.Equal(t, []string{"test/out-of-quota", "test/overloaded"}, calls)
+}
+
+func TestTripPlanner_Plan_StreamsText(t *testing.T) {
+	ctx := context.Background()
+	gk := genkit.Init(ctx)
+
+	chunks := []string{"Looking at ", "flights from London, ", "then hotels in New York."}
+	var streamed bool
+	model := genkit.DefineModel(gk, "test/streaming", &ai.ModelOptions{Supports: &ai.ModelSupports{Multiturn: true, SystemRole: true}},
+		func(ctx context.Context, req *ai.ModelRequest, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+			streamed = cb != nil
+			if cb != nil {
+				for _, c := range chunks {
+					if err := cb(ctx, &ai.ModelResponseChunk{Content: []*ai.Part{ai.NewTextPart(c)}}); err != nil {
+						return nil, err
+					}
+				}
+			}
+			return &ai.ModelResponse{Request: req, Message: ai.NewModelTextMessage(core.ReferenceAnswer()), FinishReason: ai.FinishReasonStop}, nil
+		})
+	planner := NewTripPlanner(gk, tools.NewRegistry(), model)
+
+	var events []string
+	ctx = tmcontext.WithTextSink(ctx, func(chunk string) { events = append(events, chunk) })
+	result, err := planner.Plan(ctx, PlanRequest{UserQuery: "London to New York"})
+	if assert.NoError(t, err) {
+		assert.Len(t, result.PossibleItineraries, 1)
+		events = append(events, "result")
+	}
+	assert.Equal(t, append(chunks, "result"), events, "chunks arrive in order before the result")
+
+	// Without a sink the model is not asked to stream
+	_, err = planner.Plan(context.Background(), PlanRequest{UserQuery: "London to New York"})
+	assert.NoError(t, err)
+	assert.False(t, streamed)
+}
+
+func TestTripPlanner_Plan_ReportsToolCalls(t *testing.T) {
+	ctx := context.Background()
+	gk := genkit.Init(ctx)
+	registry := tools.NewRegistry()
+	registry.Register(genkit.DefineTool[string, string](gk, "test_weather", "Weather of a city",
+		func(ctx *ai.ToolContext, city string) (string, error) { return "sunny", nil },
+	), nil)
+
+	// The model checks the weather once, then answers
+	model := genkit.DefineModel(gk, "test/weather", &ai.ModelOptions{Supports: &ai.ModelSupports{Multiturn: true, SystemRole: true, Tools: true}},
+		func(ctx context.Context, req *ai.ModelRequest, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+			if last := req.Messages[len(req.Messages)-1]; last.Role != ai.RoleTool {
+				call := ai.NewToolRequestPart(&ai.ToolRequest{Name: "test_weather", Input: "Paris"})
+				return &ai.ModelResponse{Request: req, Message: &ai.Message{Role: ai.RoleModel, Content: []*ai.Part{call}}, FinishReason: ai.FinishReasonStop}, nil
+			}
+			return &ai.ModelResponse{Request: req, Message: ai.NewModelTextMessage(core.ReferenceAnswer()), FinishReason: ai.FinishReasonStop}, nil
+		})
+
+	var steps []string
+	ctx = tmcontext.WithProgressSink(ctx, func(stage pb.PlanStage, detail string) {
+		steps = append(steps, stage.String()+" "+detail)
+	})
+	_, err := NewTripPlanner(gk, registry, model).Plan(ctx, PlanRequest{UserQuery: "Paris next weekend"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"PLAN_STAGE_TOOL_CALL test_weather"}, steps)
+}
+
+func TestTripPlanner_Plan_RetriesCode(t *testing.T) {
+	ctx := context.Background()
+	gk := genkit.Init(ctx)
+
+	var calls int
+	var retried bool
+	model := genkit.DefineModel(gk, "test/coder", &ai.ModelOptions{Supports: &ai.ModelSupports{Multiturn: true, SystemRole: true}},
+		func(ctx context.Context, req *ai.ModelRequest, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+			calls++
+			last := req.Messages[len(req.Messages)-1]
+			if last.Role == ai.RoleUser && last.Text() == codeInstruction {
+				retried = true
+				return &ai.ModelResponse{Request: req, Message: ai.NewModelTextMessage(core.ReferenceAnswer()), FinishReason: ai.FinishReasonStop}, nil
+			}
+			code := "def hotelTool():\n    return search_hotels('Paris')\n"
+			return &ai.ModelResponse{Request: req, Message: ai.NewModelTextMessage(code), FinishReason: ai.FinishReasonStop}, nil
+		})
+
+	result, err := NewTripPlanner(gk, tools.NewRegistry(), model).Plan(ctx, PlanRequest{UserQuery: "Paris next weekend"})
+	if assert.NoError(t, err) {
+		assert.Len(t, result.PossibleItineraries, 1)
+	}
+	assert.True(t, retried, "the model is told it wrote code")
+	assert.Equal(t, 2, calls)
+}
+
+func TestLooksLikeCode(t *testing.T) {
+	assert.True(t, looksLikeCode("def hotelTool(): ..."))
+	assert.True(t, looksLikeCode("Here you go:\nfunction planTrip(query) {\n  return {};\n}"))
+	assert.True(t, looksLikeCode("async function search() {}"))
+	assert.True(t, looksLikeCode("```python\nprint(flightTool('LHR'))\n```"))
+
+	assert.False(t, looksLikeCode("```json\n{\"itineraries\": []}\n```"))
+	assert.False(t, looksLikeCode("```\n{\"itineraries\": []}\n```"))
+	assert.False(t, looksLikeCode(core.ReferenceAnswer()))
+	assert.False(t, looksLikeCode(`{"reasoning": "The museum's opening function (def. hours) is 9-5"}`))
+}
+
+func TestTripPlanner_Plan_ToolErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		code      pb.ErrorCode
+		retryable bool
+		runs      int
+	}{
+		// The model may call the tool again, e.g. with other arguments
+		{"retryable", pb.ErrorCode_ERROR_CODE_DATA_NOT_FOUND, true, 2},
+		// The tool is not called again within the plan
+		{"not retryable", pb.ErrorCode_ERROR_CODE_AUTHENTICATION_FAILED, false, 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			gk := genkit.Init(ctx)
+			registry := tools.NewRegistry()
+
+			runs := 0
+			search := func(ctx context.Context, city string) (string, error) {
+				runs++
+				return "", errors.New("provider said no")
+			}
+			registry.Register(genkit.DefineTool[string, string](gk, "test_search", "Searches a city",
+				func(ctx *ai.ToolContext, city string) (string, error) {
+					return tools.Run(ctx, "test_search", func(error) pb.ErrorCode { return tc.code }, city, search)
+				},
+			), nil)
+
+			// The model calls the tool twice, then answers
+			var seen []string
+			model := genkit.DefineModel(gk, "test/tools", &ai.ModelOptions{Supports: &ai.ModelSupports{Multiturn: true, SystemRole: true, Tools: true}},
+				func(ctx context.Context, req *ai.ModelRequest, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+					seen = seen[:0]
+					for _, msg := range req.Messages {
+						for _, part := range msg.Content {
+							if part.IsToolResponse() {
+								b, _ := json.Marshal(part.ToolResponse.Output)
+								seen = append(seen, string(b))
+							}
+						}
+					}
+					if len(seen) < 2 {
+						call := ai.NewToolRequestPart(&ai.ToolRequest{Name: "test_search", Input: "Paris"})
+						return &ai.ModelResponse{Request: req, Message: &ai.Message{Role: ai.RoleModel, Content: []*ai.Part{call}}, FinishReason: ai.FinishReasonStop}, nil
+					}
+					return &ai.ModelResponse{Request: req, Message: ai.NewModelTextMessage(core.ReferenceAnswer()), FinishReason: ai.FinishReasonStop}, nil
+				})
+
+			result, err := NewTripPlanner(gk, registry, model).Plan(ctx, PlanRequest{UserQuery: "Paris next weekend"})
+			if assert.NoError(t, err) {
+				assert.Len(t, result.PossibleItineraries, 1)
+			}
+			assert.Equal(t, tc.runs, runs)
+			if assert.Len(t, seen, 2) {
+				for _, out := range seen {
+					var resp struct{ Error tools.ToolError }
+					if assert.NoError(t, json.Unmarshal([]byte(out), &resp)) {
+						assert.Equal(t, tc.retryable, resp.Error.Retryable)
+						assert.Equal(t, strings.TrimPrefix(tc.code.String(), "ERROR_CODE_"), resp.Error.Code)
+						assert.Equal(t, "provider said no", resp.Error.Message)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestTripPlanner_Plan_ToolTimeout(t *testing.T) {
+	ctx := context.Background()
+	gk := genkit.Init(ctx)
+	registry := tools.NewRegistry()
+	registry.SetToolTimeoutFor("test_search", 50*time.Millisecond)
+
+	// A hung provider call that ignores its context
+	release := make(chan struct{})
+	defer close(release)
+	var mu sync.Mutex
+	runs := map[string]int{}
+	search := func(ctx context.Context, city string) (string, error) {
+		mu.Lock()
+		runs[city]++
+		mu.Unlock()
+		<-release
+		return "late", nil
+	}
+	registry.Register(genkit.DefineTool[string, string](gk, "test_search", "Searches a city",
+		func(ctx *ai.ToolContext, city string) (string, error) {
+			return tools.Run(ctx, "test_search", nil, city, search)
+		},
+	), nil)
+
+	// The model searches Paris twice and Lyon once, then answers with what it got back
+	cities := []string{"Paris", "Paris", "Lyon"}
+	var seen []string
+	model := genkit.DefineModel(gk, "test/slow-tool", &ai.ModelOptions{Supports: &ai.ModelSupports{Multiturn: true, SystemRole: true, Tools: true}},
+		func(ctx context.Context, req *ai.ModelRequest, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+			seen = seen[:0]
+			for _, msg := range req.Messages {
+				for _, part := range msg.Content {
+					if part.IsToolResponse() {
+						b, _ := json.Marshal(part.ToolResponse.Output)
+						seen = append(seen, string(b))
+					}
+				}
+			}
+			if len(seen) < len(cities) {
+				call := ai.NewToolRequestPart(&ai.ToolRequest{Name: "test_search", Input: cities[len(seen)]})
+				return &ai.ModelResponse{Request: req, Message: &ai.Message{Role: ai.RoleModel, Content: []*ai.Part{call}}, FinishReason: ai.FinishReasonStop}, nil
+			}
+			return &ai.ModelResponse{Request: req, Message: ai.NewModelTextMessage(core.ReferenceAnswer()), FinishReason: ai.FinishReasonStop}, nil
+		})
+
+	start := time.Now()
+	_, err := NewTripPlanner(gk, registry, model).Plan(ctx, PlanRequest{UserQuery: "Paris next weekend"})
+	assert.NoError(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	// The model is told the tool ran out of time; the same search is not made again,
+	// but the tool still serves other ones
+	assert.Equal(t, map[string]int{"Paris": 1, "Lyon": 1}, runs)
+	if assert.Len(t, seen, len(cities)) {
+		for _, out := range seen {
+			var resp struct{ Error tools.ToolError }
+			if assert.NoError(t, json.Unmarshal([]byte(out), &resp)) {
+				assert.Equal(t, "CONNECTION_FAILED", resp.Error.Code)
+				assert.True(t, resp.Error.Retryable)
+				assert.Contains(t, resp.Error.Message, "timed out after 50ms")
+			}
+		}
+	}
+}
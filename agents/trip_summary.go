@@ -0,0 +1,110 @@
+package agents
+
+import (
+	"time"
+
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// SummarizeTrip builds the header of a plan from its best itinerary, the first of the
+// ranked itineraries, so clients can show it without walking the graph. It returns nil
+// if there are no itineraries.
+func SummarizeTrip(itineraries []*pb.Itinerary) *pb.TripSummary {
+	if len(itineraries) == 0 {
+		return nil
+	}
+	it := itineraries[0]
+	summary := &pb.TripSummary{
+		Destination: tripDestination(it.GetGraph()),
+		StartTime:   it.StartTime,
+		EndTime:     it.EndTime,
+		Travelers:   tripTravelers(it),
+		OptionCount: int32(len(itineraries)),
+	}
+
+	// Verified itineraries carry their breakdown; drafts are added up here
+	breakdown := it.CostBreakdown
+	if breakdown == nil {
+		breakdown = computeCostBreakdown(it)
+	}
+	if breakdown.GetTotal().GetValue() > 0 {
+		summary.Total = breakdown.Total
+	}
+
+	// The selected legs and stays are more precise than the planner's dates
+	if start, end, ok := tripWindow(it.GetGraph()); ok {
+		summary.StartTime = timestamppb.New(start)
+		summary.EndTime = timestamppb.New(end)
+	}
+	return summary
+}
+
+// tripDestination is the city of the first stay, or where the first leg arrives if the
+// trip has no stays
+func tripDestination(g *pb.Graph) string {
+	for _, n := range g.GetNodes() {
+		if n.Stay != nil {
+			return nodePlace(n)
+		}
+	}
+	var first *pb.Edge
+	var firstDep time.Time
+	for _, e := range g.GetEdges() {
+		if e.Transport == nil {
+			continue
+		}
+		dep, _, ok := legTimes(e)
+		if first == nil || (ok && dep.Before(firstDep)) {
+			first, firstDep = e, dep
+		}
+	}
+	for _, n := range g.GetNodes() {
+		if first != nil && n.Id == first.ToId {
+			return nodePlace(n)
+		}
+	}
+	return ""
+}
+
+// tripTravelers is the itinerary's party size, falling back to the first stay's and
+// then to one traveller
+func tripTravelers(it *pb.Itinerary) int32 {
+	if it.Travelers > 0 {
+		return it.Travelers
+	}
+	for _, n := range it.GetGraph().GetNodes() {
+		if c := n.GetStay().GetTravelerCount(); c > 0 {
+			return c
+		}
+	}
+	return 1
+}
+
+// tripWindow spans the selected legs and stays of a graph, from the first departure or
+// check-in to the last arrival or check-out. ok is false if none of them have times.
+func tripWindow(g *pb.Graph) (start, end time.Time, ok bool) {
+	extend := func(from, to time.Time) {
+		if !ok || from.Before(start) {
+			start = from
+		}
+		if !ok || to.After(end) {
+			end = to
+		}
+		ok = true
+	}
+	for _, e := range g.GetEdges() {
+		if e.Transport == nil {
+			continue
+		}
+		if dep, arr, legOK := legTimes(e); legOK {
+			extend(dep, arr)
+		}
+	}
+	for _, n := range g.GetNodes() {
+		if s := n.GetStay(); s.GetCheckIn() != nil && s.GetCheckOut() != nil {
+			extend(s.CheckIn.AsTime(), s.CheckOut.AsTime())
+		}
+	}
+	return start, end, ok
+}
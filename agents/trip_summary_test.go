@@ -0,0 +1,85 @@
+package agents
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// summaryItinerary is a weekend from Berlin in Paris for two: flights out on Friday
+// and back on Sunday, with a hotel in between
+func summaryItinerary() *pb.Itinerary {
+	friday := time.Date(2026, 6, 5, 8, 0, 0, 0, time.UTC)
+	flight := func(from, to string, dep time.Time, price float64) *pb.Edge {
+		return &pb.Edge{FromId: from, ToId: to, Transport: &pb.Transport{
+			Type: pb.TransportType_TRANSPORT_TYPE_FLIGHT,
+			Cost: &pb.Cost{Value: price, Currency: "EUR"},
+			Details: &pb.Transport_Flight{Flight: &pb.Flight{
+				DepartureTime: timestamppb.New(dep),
+				ArrivalTime:   timestamppb.New(dep.Add(2 * time.Hour)),
+			}},
+		}}
+	}
+	return &pb.Itinerary{
+		Title:     "Paris weekend",
+		Travelers: 2,
+		// The planner's dates, before the flights were picked
+		StartTime: timestamppb.New(time.Date(2026, 6, 5, 0, 0, 0, 0, time.UTC)),
+		EndTime:   timestamppb.New(time.Date(2026, 6, 7, 0, 0, 0, 0, time.UTC)),
+		Graph: &pb.Graph{
+			Nodes: []*pb.Node{
+				{Id: "berlin", Location: &pb.Location{City: "Berlin"}},
+				{Id: "paris", Location: &pb.Location{City: "Paris"}, Stay: &pb.Accommodation{
+					Name:     "Hotel du Nord",
+					Cost:     &pb.Cost{Value: 300, Currency: "EUR"},
+					CheckIn:  timestamppb.New(friday.Add(6 * time.Hour)),
+					CheckOut: timestamppb.New(friday.AddDate(0, 0, 2).Add(3 * time.Hour)),
+				}},
+			},
+			Edges: []*pb.Edge{
+				flight("berlin", "paris", friday, 120),
+				flight("paris", "berlin", friday.AddDate(0, 0, 2).Add(10*time.Hour), 140),
+			},
+		},
+	}
+}
+
+func TestSummarizeTrip(t *testing.T) {
+	it := summaryItinerary()
+	it.CostBreakdown = computeCostBreakdown(it)
+	other := summaryItinerary()
+	other.Title = "Paris by train"
+
+	summary := SummarizeTrip([]*pb.Itinerary{it, other})
+	assert.Equal(t, "Paris", summary.Destination)
+	assert.Equal(t, time.Date(2026, 6, 5, 8, 0, 0, 0, time.UTC), summary.StartTime.AsTime())
+	assert.Equal(t, time.Date(2026, 6, 7, 20, 0, 0, 0, time.UTC), summary.EndTime.AsTime())
+	assert.Equal(t, int32(2), summary.Travelers)
+	assert.Equal(t, 560.0, summary.Total.Value)
+	assert.Equal(t, "EUR", summary.Total.Currency)
+	assert.Equal(t, int32(2), summary.OptionCount)
+
+	assert.Nil(t, SummarizeTrip(nil))
+}
+
+func TestSummarizeTrip_Draft(t *testing.T) {
+	// A draft has no stays, prices or times yet
+	it := summaryItinerary()
+	it.Travelers = 0
+	it.Graph.Nodes[1].Stay = nil
+	for _, e := range it.Graph.Edges {
+		e.Transport.Cost = nil
+		e.Transport.Details = nil
+	}
+
+	summary := SummarizeTrip([]*pb.Itinerary{it})
+	assert.Equal(t, "Paris", summary.Destination, "where the first leg arrives")
+	assert.Equal(t, it.StartTime, summary.StartTime)
+	assert.Equal(t, it.EndTime, summary.EndTime)
+	assert.Equal(t, int32(1), summary.Travelers)
+	assert.Nil(t, summary.Total)
+	assert.Equal(t, int32(1), summary.OptionCount)
+}
@@ -0,0 +1,490 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	tmcore "github.com/va6996/travelingman/core"
+	"github.com/va6996/travelingman/log"
+	"github.com/va6996/travelingman/pb"
+	"github.com/va6996/travelingman/plugins/core"
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrVersionConflict is returned when an edit is based on an outdated version of a saved trip
+var ErrVersionConflict = errors.New("itinerary was modified since it was loaded")
+
+// UpdateTrip applies a user's edit to a saved itinerary.
+// The edit is validated, checked for timeline conflicts, and only the components the
+// user touched are re-verified with the TravelDesk. If the edit is consistent the
+// re-scored itinerary is returned; otherwise the conflicts are returned with suggestions.
+// Persisting the result (and bumping the version) is left to the caller.
+func (ta *TravelAgent) UpdateTrip(ctx context.Context, saved, edited *pb.Itinerary) (*pb.Itinerary, []*pb.TripConflict, error) {
+	if saved == nil || edited == nil {
+		return nil, nil, fmt.Errorf("saved and edited itineraries are required")
+	}
+	ctx = ta.withClock(ctx)
+	if edited.Version != saved.Version {
+		return nil, nil, fmt.Errorf("%w: edit is based on version %d, current version is %d", ErrVersionConflict, edited.Version, saved.Version)
+	}
+
+	touched := diffItineraries(saved, edited)
+	log.Infof(ctx, "UpdateTrip: %d node(s) and %d edge(s) changed in %q", len(touched.nodes), len(touched.edges), edited.Title)
+	if touched.empty() {
+		return edited, nil, nil
+	}
+
+	// 1. Structural validation
+	var conflicts []*pb.TripConflict
+	var invalid *core.ValidationError
+	if err := core.ValidateItinerary(ctx, edited); errors.As(err, &invalid) {
+		for _, problem := range invalid.Problems {
+			conflicts = append(conflicts, &pb.TripConflict{
+				Message:    problem,
+				Suggestion: "Fix the highlighted field and save again",
+			})
+		}
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	// 2. Temporal feasibility and overlaps involving the edited components
+	conflicts = append(conflicts, checkTimeline(edited, touched)...)
+	if len(conflicts) > 0 {
+		log.Warnf(ctx, "UpdateTrip: edit rejected with %d conflict(s)", len(conflicts))
+		return nil, conflicts, nil
+	}
+
+	// 3. Re-verify availability for the touched components only
+	if conflicts, err := ta.reverify(ctx, edited, touched); err != nil || len(conflicts) > 0 {
+		return nil, conflicts, err
+	}
+
+	edited.Error = nil
+	ta.scoreAndTag([]*pb.Itinerary{edited})
+	return edited, nil, nil
+}
+
+// touchedComponents records which nodes and edges differ between two versions of an itinerary.
+// Edges are keyed by edgeKey.
+type touchedComponents struct {
+	nodes map[string]bool
+	edges map[string]bool
+}
+
+func (tc touchedComponents) empty() bool {
+	return len(tc.nodes) == 0 && len(tc.edges) == 0
+}
+
+// diffItineraries compares the user-editable parts of each node and edge, a node's
+// sub-graph included. Options, errors and tags are ignored since they are produced by
+// verification, not edits. A deleted node or edge is touched, and so are the remaining
+// nodes it connected, whose stays the deletion may have moved.
+func diffItineraries(saved, edited *pb.Itinerary) touchedComponents {
+	tc := touchedComponents{nodes: map[string]bool{}, edges: map[string]bool{}}
+
+	savedNodes := map[string]*pb.Node{}
+	savedEdges := map[string]*pb.Edge{}
+	if saved.Graph != nil {
+		for _, n := range saved.Graph.Nodes {
+			savedNodes[n.Id] = n
+		}
+		for key, e := range edgesByKey(saved.Graph) {
+			savedEdges[key] = e
+		}
+	}
+	if edited.Graph == nil {
+		return tc
+	}
+
+	for _, n := range edited.Graph.Nodes {
+		if old, ok := savedNodes[n.Id]; !ok || !proto.Equal(editableNode(old), editableNode(n)) {
+			tc.nodes[n.Id] = true
+		}
+	}
+	editedEdges := edgesByKey(edited.Graph)
+	for key, e := range editedEdges {
+		if old, ok := savedEdges[key]; !ok || !proto.Equal(editableEdge(old), editableEdge(e)) {
+			tc.edges[key] = true
+		}
+	}
+
+	// Deletions
+	editedNodes := map[string]bool{}
+	for _, n := range edited.Graph.Nodes {
+		editedNodes[n.Id] = true
+	}
+	for id := range savedNodes {
+		if !editedNodes[id] {
+			tc.nodes[id] = true
+		}
+	}
+	for key, e := range savedEdges {
+		if _, ok := editedEdges[key]; ok {
+			continue
+		}
+		tc.edges[key] = true
+		for _, id := range []string{e.FromId, e.ToId} {
+			if editedNodes[id] {
+				tc.nodes[id] = true
+			}
+		}
+	}
+	return tc
+}
+
+func editableNode(n *pb.Node) *pb.Node {
+	c := proto.Clone(n).(*pb.Node)
+	c.StayOptions = nil
+	if c.Stay != nil {
+		c.Stay.Error = nil
+		c.Stay.Tags = nil
+	}
+	stripVerification(c.SubGraph)
+	return c
+}
+
+func editableEdge(e *pb.Edge) *pb.Edge {
+	c := proto.Clone(e).(*pb.Edge)
+	c.TransportOptions = nil
+	if c.Transport != nil {
+		c.Transport.Error = nil
+		c.Transport.Tags = nil
+	}
+	return c
+}
+
+// stripVerification clears what verification set on the nodes and edges of a graph
+// and its sub-graphs, leaving what the user can edit
+func stripVerification(g *pb.Graph) {
+	if g == nil {
+		return
+	}
+	for i, n := range g.Nodes {
+		g.Nodes[i] = editableNode(n)
+	}
+	for i, e := range g.Edges {
+		g.Edges[i] = editableEdge(e)
+	}
+	stripVerification(g.SubGraph)
+}
+
+// edgeKey identifies an edge as "from->to"; parallel edges get a "#n" suffix
+func edgeKey(e *pb.Edge, occurrence int) string {
+	if occurrence == 0 {
+		return fmt.Sprintf("%s->%s", e.FromId, e.ToId)
+	}
+	return fmt.Sprintf("%s->%s#%d", e.FromId, e.ToId, occurrence)
+}
+
+func edgesByKey(g *pb.Graph) map[string]*pb.Edge {
+	seen := map[string]int{}
+	out := map[string]*pb.Edge{}
+	for _, e := range g.Edges {
+		base := edgeKey(e, 0)
+		out[edgeKey(e, seen[base])] = e
+		seen[base]++
+	}
+	return out
+}
+
+// transportWindow returns the departure and arrival time of a transport, if known
+func transportWindow(t *pb.Transport) (dep, arr time.Time, ok bool) {
+	if t == nil {
+		return
+	}
+	switch {
+	case t.GetFlight() != nil:
+		dep, arr = t.GetFlight().GetDepartureTime().AsTime(), t.GetFlight().GetArrivalTime().AsTime()
+		ok = t.GetFlight().GetDepartureTime() != nil
+		if t.GetFlight().GetArrivalTime() == nil {
+			arr = dep
+		}
+	case t.GetTrain() != nil:
+		dep, arr = t.GetTrain().GetDepartureTime().AsTime(), t.GetTrain().GetArrivalTime().AsTime()
+		ok = t.GetTrain().GetDepartureTime() != nil
+		if t.GetTrain().GetArrivalTime() == nil {
+			arr = dep
+		}
+	case t.GetCarRental() != nil:
+		dep, arr = t.GetCarRental().GetPickupTime().AsTime(), t.GetCarRental().GetDropoffTime().AsTime()
+		ok = t.GetCarRental().GetPickupTime() != nil
+		if t.GetCarRental().GetDropoffTime() == nil {
+			arr = dep
+		}
+	}
+	return
+}
+
+func transportName(t *pb.Transport) string {
+	switch t.Type {
+	case pb.TransportType_TRANSPORT_TYPE_FLIGHT:
+		return "flight"
+	case pb.TransportType_TRANSPORT_TYPE_TRAIN:
+		return "train"
+	case pb.TransportType_TRANSPORT_TYPE_CAR:
+		return "car rental"
+	}
+	return "transport"
+}
+
+func nodePlace(n *pb.Node) string {
+	if loc := n.GetLocation(); loc != nil {
+		if loc.City != "" {
+			return loc.City
+		}
+		if loc.Name != "" {
+			return loc.Name
+		}
+	}
+	return n.Id
+}
+
+// civilDate truncates t to its calendar day in UTC
+func civilDate(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// dayPhrase renders t as "the 12th", or "Mar 12" when it falls in a different month than ref
+func dayPhrase(t, ref time.Time) string {
+	t, ref = t.UTC(), ref.UTC()
+	if t.Year() != ref.Year() || t.Month() != ref.Month() {
+		return t.Format("Jan 2")
+	}
+	d := t.Day()
+	suffix := "th"
+	if d%100 < 11 || d%100 > 13 {
+		switch d % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return fmt.Sprintf("the %d%s", d, suffix)
+}
+
+// checkTimeline reports temporal conflicts between stays and transports.
+// Only conflicts involving at least one touched component are returned, so
+// pre-existing issues elsewhere in the trip do not block an unrelated edit.
+func checkTimeline(it *pb.Itinerary, touched touchedComponents) []*pb.TripConflict {
+	if it.Graph == nil {
+		return nil
+	}
+	var conflicts []*pb.TripConflict
+
+	keys := map[*pb.Edge]string{}
+	for key, e := range edgesByKey(it.Graph) {
+		keys[e] = key
+	}
+
+	order := map[*pb.Edge]int{}
+	for i, e := range it.Graph.Edges {
+		order[e] = i
+	}
+
+	for i, e := range it.Graph.Edges {
+		dep, arr, ok := transportWindow(e.Transport)
+		if !ok {
+			continue
+		}
+		key := keys[e]
+		kind := transportName(e.Transport)
+
+		// Check-out must not be after the day we leave
+		if from := tmcore.GetNodeByID(it.Graph, e.FromId); from != nil && from.Stay.GetCheckOut() != nil {
+			checkOut := from.Stay.CheckOut.AsTime()
+			if civilDate(checkOut).After(civilDate(dep)) && (touched.nodes[from.Id] || touched.edges[key]) {
+				c := &pb.TripConflict{
+					Suggestion: fmt.Sprintf("Check out on %s, or move the %s to %s", dayPhrase(dep, checkOut), kind, dayPhrase(checkOut, dep)),
+				}
+				if touched.nodes[from.Id] {
+					c.ComponentId = from.Id
+					c.Message = fmt.Sprintf("Moving check-out in %s to %s conflicts with your %s on %s", nodePlace(from), dayPhrase(checkOut, dep), kind, dayPhrase(dep, checkOut))
+				} else {
+					c.ComponentId = key
+					c.Message = fmt.Sprintf("Moving your %s to %s conflicts with check-out in %s on %s", kind, dayPhrase(dep, checkOut), nodePlace(from), dayPhrase(checkOut, dep))
+				}
+				conflicts = append(conflicts, c)
+			}
+		}
+
+		// Check-in must not be before the day we arrive
+		if to := tmcore.GetNodeByID(it.Graph, e.ToId); to != nil && to.Stay.GetCheckIn() != nil {
+			checkIn := to.Stay.CheckIn.AsTime()
+			if civilDate(checkIn).Before(civilDate(arr)) && (touched.nodes[to.Id] || touched.edges[key]) {
+				c := &pb.TripConflict{
+					Suggestion: fmt.Sprintf("Check in on %s, or arrive by %s", dayPhrase(arr, checkIn), dayPhrase(checkIn, arr)),
+				}
+				if touched.nodes[to.Id] {
+					c.ComponentId = to.Id
+					c.Message = fmt.Sprintf("Moving check-in in %s to %s conflicts with your %s arriving on %s", nodePlace(to), dayPhrase(checkIn, arr), kind, dayPhrase(arr, checkIn))
+				} else {
+					c.ComponentId = key
+					c.Message = fmt.Sprintf("Moving your %s to arrive on %s conflicts with check-in in %s on %s", kind, dayPhrase(arr, checkIn), nodePlace(to), dayPhrase(checkIn, arr))
+				}
+				conflicts = append(conflicts, c)
+			}
+		}
+
+		// The next leg out of the destination must leave after this one arrives. Earlier
+		// legs out of it, such as the outbound flight of a return trip, are not connections.
+		place := e.ToId
+		if to := tmcore.GetNodeByID(it.Graph, e.ToId); to != nil {
+			place = nodePlace(to)
+		}
+		for _, next := range tmcore.GetEdgesFromNode(it.Graph, e.ToId) {
+			nextDep, _, ok := transportWindow(next.Transport)
+			nextKey := keys[next]
+			if !ok || order[next] <= i || !nextDep.Before(arr) || !(touched.edges[key] || touched.edges[nextKey]) {
+				continue
+			}
+			componentID := key
+			if !touched.edges[key] {
+				componentID = nextKey
+			}
+			conflicts = append(conflicts, &pb.TripConflict{
+				ComponentId: componentID,
+				Message: fmt.Sprintf("Your %s out of %s departs at %s, before your %s arrives at %s",
+					transportName(next.Transport), place, nextDep.Format("Jan 2 15:04"), kind, arr.Format("Jan 2 15:04")),
+				Suggestion: fmt.Sprintf("Pick a %s departing after %s", transportName(next.Transport), arr.Format("Jan 2 15:04")),
+			})
+		}
+	}
+
+	// Stays must not overlap each other
+	var stays []*pb.Node
+	for _, n := range it.Graph.Nodes {
+		if n.Stay.GetCheckIn() != nil && n.Stay.GetCheckOut() != nil {
+			stays = append(stays, n)
+		}
+	}
+	sort.Slice(stays, func(i, j int) bool {
+		return stays[i].Stay.CheckIn.AsTime().Before(stays[j].Stay.CheckIn.AsTime())
+	})
+	for i := 1; i < len(stays); i++ {
+		prev, cur := stays[i-1], stays[i]
+		prevOut, curIn := prev.Stay.CheckOut.AsTime(), cur.Stay.CheckIn.AsTime()
+		if !civilDate(curIn).Before(civilDate(prevOut)) || !(touched.nodes[prev.Id] || touched.nodes[cur.Id]) {
+			continue
+		}
+		componentID := cur.Id
+		if !touched.nodes[cur.Id] {
+			componentID = prev.Id
+		}
+		conflicts = append(conflicts, &pb.TripConflict{
+			ComponentId: componentID,
+			Message: fmt.Sprintf("Your stay in %s (until %s) overlaps your stay in %s (from %s)",
+				nodePlace(prev), dayPhrase(prevOut, curIn), nodePlace(cur), dayPhrase(curIn, prevOut)),
+			Suggestion: fmt.Sprintf("Check out of %s on %s, or check in to %s on %s",
+				nodePlace(prev), dayPhrase(curIn, prevOut), nodePlace(cur), dayPhrase(prevOut, curIn)),
+		})
+	}
+
+	return conflicts
+}
+
+// reverify runs availability checks on a sub-itinerary made of the touched components
+// and merges the refreshed options back into it. Untouched components keep their
+// previously verified options. Availability errors are returned as conflicts.
+func (ta *TravelAgent) reverify(ctx context.Context, it *pb.Itinerary, touched touchedComponents) ([]*pb.TripConflict, error) {
+	if it.Graph == nil {
+		return nil, nil
+	}
+
+	sub := &pb.Itinerary{
+		Title:     it.Title,
+		StartTime: it.StartTime,
+		EndTime:   it.EndTime,
+		Travelers: it.Travelers,
+		// The partial graph may not contain the full cycle of a return trip
+		JourneyType: pb.JourneyType_JOURNEY_TYPE_MULTI_CITY,
+		Graph:       &pb.Graph{},
+	}
+
+	included := map[string]bool{}
+	addNode := func(id string, withStay bool) {
+		if included[id] {
+			return
+		}
+		n := tmcore.GetNodeByID(it.Graph, id)
+		if n == nil {
+			return
+		}
+		c := proto.Clone(n).(*pb.Node)
+		if !withStay {
+			// Context-only node for an edge endpoint; its stay is not re-checked
+			c.Stay = nil
+			c.StayOptions = nil
+			c.SubGraph = nil
+		}
+		sub.Graph.Nodes = append(sub.Graph.Nodes, c)
+		included[id] = true
+	}
+
+	for id := range touched.nodes {
+		addNode(id, true)
+	}
+	// Parallel edges get their own keys within the sub-itinerary; origKeys maps them back
+	origKeys := map[string]string{}
+	seen := map[string]int{}
+	for key, e := range edgesByKey(it.Graph) {
+		if !touched.edges[key] {
+			continue
+		}
+		addNode(e.FromId, touched.nodes[e.FromId])
+		addNode(e.ToId, touched.nodes[e.ToId])
+		sub.Graph.Edges = append(sub.Graph.Edges, proto.Clone(e).(*pb.Edge))
+		base := edgeKey(e, 0)
+		origKeys[edgeKey(e, seen[base])] = key
+		seen[base]++
+	}
+
+	checked, err := ta.desk.CheckAvailability(ctx, sub)
+	if err != nil {
+		return nil, fmt.Errorf("re-verification failed: %w", err)
+	}
+
+	var conflicts []*pb.TripConflict
+	for _, n := range checked.Graph.GetNodes() {
+		if !touched.nodes[n.Id] {
+			continue
+		}
+		orig := tmcore.GetNodeByID(it.Graph, n.Id)
+		// The sub-graph holds the node's activities, which may follow the stay dates
+		orig.Stay, orig.StayOptions, orig.Location, orig.SubGraph = n.Stay, n.StayOptions, n.Location, n.SubGraph
+		if n.Stay.GetError().GetSeverity() == pb.ErrorSeverity_ERROR_SEVERITY_ERROR {
+			conflicts = append(conflicts, &pb.TripConflict{
+				ComponentId: n.Id,
+				Message:     n.Stay.Error.Message,
+				Suggestion:  "Try different dates or another area for this stay",
+			})
+		}
+	}
+
+	// The desk's edges are matched by their ends, not by their order
+	edges := edgesByKey(it.Graph)
+	for subKey, e := range edgesByKey(checked.Graph) {
+		key, ok := origKeys[subKey]
+		if !ok {
+			continue
+		}
+		orig := edges[key]
+		orig.Transport, orig.TransportOptions = e.Transport, e.TransportOptions
+		if e.Transport.GetError().GetSeverity() == pb.ErrorSeverity_ERROR_SEVERITY_ERROR {
+			conflicts = append(conflicts, &pb.TripConflict{
+				ComponentId: key,
+				Message:     e.Transport.Error.Message,
+				Suggestion:  fmt.Sprintf("Try another date for this %s", transportName(e.Transport)),
+			})
+		}
+	}
+
+	return conflicts, nil
+}
@@ -0,0 +1,304 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// stubDesk records what it was asked to verify and marks everything available
+type stubDesk struct {
+	checked []*pb.Itinerary
+}
+
+func (d *stubDesk) CheckAvailability(ctx context.Context, it *pb.Itinerary) (*pb.Itinerary, error) {
+	d.checked = append(d.checked, proto.Clone(it).(*pb.Itinerary))
+	for _, n := range it.Graph.Nodes {
+		if n.Stay != nil {
+			opt := proto.Clone(n.Stay).(*pb.Accommodation)
+			opt.Name = "Rechecked Hotel"
+			opt.Cost = &pb.Cost{Value: 300, Currency: "EUR"}
+			n.StayOptions = []*pb.Accommodation{opt}
+		}
+	}
+	for _, e := range it.Graph.Edges {
+		e.TransportOptions = []*pb.Transport{proto.Clone(e.Transport).(*pb.Transport)}
+	}
+	return it, nil
+}
+
+// savedParisTrip is a verified LHR -> Paris -> LHR trip: flights on the 10th and 14th,
+// hotel from the 10th to the 14th
+func savedParisTrip() *pb.Itinerary {
+	y := time.Now().Year() + 1
+	day := func(d, h int) *timestamppb.Timestamp {
+		return timestamppb.New(time.Date(y, time.March, d, h, 0, 0, 0, time.UTC))
+	}
+	flight := func(from, to string, d int) *pb.Transport {
+		return &pb.Transport{
+			Type:                pb.TransportType_TRANSPORT_TYPE_FLIGHT,
+			TravelerCount:       2,
+			OriginLocation:      &pb.Location{IataCodes: []string{from}},
+			DestinationLocation: &pb.Location{IataCodes: []string{to}},
+			Cost:                &pb.Cost{Value: 150, Currency: "EUR"},
+			Details:             &pb.Transport_Flight{Flight: &pb.Flight{DepartureTime: day(d, 9), ArrivalTime: day(d, 11)}},
+		}
+	}
+
+	return &pb.Itinerary{
+		Id:          7,
+		Version:     3,
+		Title:       "Paris Getaway",
+		StartTime:   day(10, 0),
+		EndTime:     day(14, 23),
+		Travelers:   2,
+		JourneyType: pb.JourneyType_JOURNEY_TYPE_RETURN,
+		Graph: &pb.Graph{
+			Nodes: []*pb.Node{
+				{Id: "london", Location: &pb.Location{City: "London"}},
+				{Id: "paris", Location: &pb.Location{City: "Paris"}, Stay: &pb.Accommodation{
+					Name:          "Hotel du Nord",
+					Location:      &pb.Location{City: "Paris"},
+					CheckIn:       day(10, 15),
+					CheckOut:      day(14, 8),
+					TravelerCount: 2,
+					Cost:          &pb.Cost{Value: 600, Currency: "EUR"},
+				}},
+			},
+			Edges: []*pb.Edge{
+				{FromId: "london", ToId: "paris", Transport: flight("LHR", "CDG", 10)},
+				{FromId: "paris", ToId: "london", Transport: flight("CDG", "LHR", 14)},
+			},
+		},
+	}
+}
+
+func TestTravelAgent_UpdateTrip_AcceptedEdit(t *testing.T) {
+	desk := &stubDesk{}
+	agent := NewTravelAgent(new(MockPlanner), desk)
+
+	saved := savedParisTrip()
+	edited := proto.Clone(saved).(*pb.Itinerary)
+	// Check in a little later on the same day
+	stay := edited.Graph.Nodes[1].Stay
+	stay.CheckIn = timestamppb.New(stay.CheckIn.AsTime().Add(2 * time.Hour))
+
+	updated, conflicts, err := agent.UpdateTrip(context.Background(), saved, edited)
+	if err != nil {
+		t.Fatalf("UpdateTrip failed: %v", err)
+	}
+	assert.Empty(t, conflicts)
+	if !assert.NotNil(t, updated) {
+		return
+	}
+
+	// Only the edited hotel is re-verified; flights are not searched again
+	if assert.Len(t, desk.checked, 1) {
+		assert.Empty(t, desk.checked[0].Graph.Edges)
+		assert.Len(t, desk.checked[0].Graph.Nodes, 1)
+		assert.Equal(t, "paris", desk.checked[0].Graph.Nodes[0].Id)
+	}
+
+	// Re-scored with the refreshed options
+	paris := updated.Graph.Nodes[1]
+	if assert.Len(t, paris.StayOptions, 1) {
+		assert.Equal(t, "Rechecked Hotel", paris.StayOptions[0].Name)
+	}
+	assert.NotEmpty(t, updated.Graph.Edges[0].TransportOptions)
+	assert.Equal(t, saved.Version, updated.Version, "version is bumped by the store, not the agent")
+}
+
+func TestTravelAgent_UpdateTrip_KeepsSubGraph(t *testing.T) {
+	desk := &stubDesk{}
+	agent := NewTravelAgent(new(MockPlanner), desk)
+
+	// The Paris stay has a day of activities
+	saved := savedParisTrip()
+	paris := saved.Graph.Nodes[1]
+	paris.SubGraph = &pb.Graph{Nodes: []*pb.Node{{Id: "louvre", Location: &pb.Location{Name: "Louvre"}}}}
+
+	edited := proto.Clone(saved).(*pb.Itinerary)
+	stay := edited.Graph.Nodes[1].Stay
+	stay.CheckIn = timestamppb.New(stay.CheckIn.AsTime().Add(2 * time.Hour))
+
+	updated, conflicts, err := agent.UpdateTrip(context.Background(), saved, edited)
+	if err != nil {
+		t.Fatalf("UpdateTrip failed: %v", err)
+	}
+	assert.Empty(t, conflicts)
+	if !assert.NotNil(t, updated) {
+		return
+	}
+
+	// The activities are kept
+	sub := updated.Graph.Nodes[1].SubGraph
+	if assert.Len(t, sub.GetNodes(), 1) {
+		assert.Equal(t, "louvre", sub.Nodes[0].Id)
+	}
+}
+
+// reversingDesk verifies like stubDesk but returns the edges in reverse order
+type reversingDesk struct{ stubDesk }
+
+func (d *reversingDesk) CheckAvailability(ctx context.Context, it *pb.Itinerary) (*pb.Itinerary, error) {
+	checked, err := d.stubDesk.CheckAvailability(ctx, it)
+	slices.Reverse(checked.Graph.Edges)
+	return checked, err
+}
+
+func TestTravelAgent_UpdateTrip_MatchesEdgesByEnds(t *testing.T) {
+	agent := NewTravelAgent(new(MockPlanner), &reversingDesk{})
+
+	// Both flights leave an hour later
+	saved := savedParisTrip()
+	edited := proto.Clone(saved).(*pb.Itinerary)
+	for _, e := range edited.Graph.Edges {
+		f := e.Transport.GetFlight()
+		f.DepartureTime = timestamppb.New(f.DepartureTime.AsTime().Add(time.Hour))
+		f.ArrivalTime = timestamppb.New(f.ArrivalTime.AsTime().Add(time.Hour))
+	}
+
+	updated, conflicts, err := agent.UpdateTrip(context.Background(), saved, edited)
+	assert.NoError(t, err)
+	assert.Empty(t, conflicts)
+	if assert.NotNil(t, updated) {
+		origins := map[string]string{"london": "LHR", "paris": "CDG"}
+		for _, e := range updated.Graph.Edges {
+			assert.Equal(t, []string{origins[e.FromId]}, e.Transport.OriginLocation.IataCodes, "flight from %s", e.FromId)
+			if assert.Len(t, e.TransportOptions, 1) {
+				assert.Equal(t, []string{origins[e.FromId]}, e.TransportOptions[0].OriginLocation.IataCodes, "options from %s", e.FromId)
+			}
+		}
+	}
+}
+
+func TestDiffItineraries_SubGraphAndDeletions(t *testing.T) {
+	saved := savedParisTrip()
+	saved.Graph.Nodes[1].SubGraph = &pb.Graph{
+		Nodes: []*pb.Node{{Id: "louvre"}, {Id: "orsay"}},
+		Edges: []*pb.Edge{{FromId: "louvre", ToId: "orsay", Transport: &pb.Transport{Type: pb.TransportType_TRANSPORT_TYPE_TRAIN}}},
+	}
+
+	// Options found for an activity are not an edit
+	edited := proto.Clone(saved).(*pb.Itinerary)
+	sub := edited.Graph.Nodes[1].SubGraph
+	sub.Edges[0].TransportOptions = []*pb.Transport{{Type: pb.TransportType_TRANSPORT_TYPE_TRAIN}}
+	assert.True(t, diffItineraries(saved, edited).empty())
+
+	// Changing or dropping an activity touches its node
+	sub.Nodes[1].Notes = "Impressionists first"
+	assert.Equal(t, map[string]bool{"paris": true}, diffItineraries(saved, edited).nodes)
+	sub.Nodes[1].Notes = ""
+	sub.Nodes = sub.Nodes[:1]
+	sub.Edges = nil
+	assert.Equal(t, map[string]bool{"paris": true}, diffItineraries(saved, edited).nodes)
+
+	// Dropping the flight home touches it and both its ends
+	edited = proto.Clone(saved).(*pb.Itinerary)
+	edited.Graph.Edges = edited.Graph.Edges[:1]
+	tc := diffItineraries(saved, edited)
+	assert.Equal(t, map[string]bool{"paris->london": true}, tc.edges)
+	assert.Equal(t, map[string]bool{"paris": true, "london": true}, tc.nodes)
+}
+
+func TestTravelAgent_UpdateTrip_InvalidEdit(t *testing.T) {
+	desk := &stubDesk{}
+	agent := NewTravelAgent(new(MockPlanner), desk)
+
+	saved := savedParisTrip()
+	edited := proto.Clone(saved).(*pb.Itinerary)
+	edited.Graph.Edges[0].ToId = "rome"
+
+	updated, conflicts, err := agent.UpdateTrip(context.Background(), saved, edited)
+	assert.NoError(t, err)
+	assert.Nil(t, updated)
+	assert.Empty(t, desk.checked)
+	// Each validation problem is a conflict of its own
+	if assert.NotEmpty(t, conflicts) {
+		assert.NotContains(t, conflicts[0].Message, "Validation Failed")
+		assert.Equal(t, "Fix the highlighted field and save again", conflicts[0].Suggestion)
+	}
+}
+
+func TestTravelAgent_UpdateTrip_ConflictingEdit(t *testing.T) {
+	desk := &stubDesk{}
+	agent := NewTravelAgent(new(MockPlanner), desk)
+
+	saved := savedParisTrip()
+	edited := proto.Clone(saved).(*pb.Itinerary)
+	// Move the return flight to the 11th while the hotel still checks out on the 14th
+	ret := edited.Graph.Edges[1].Transport.GetFlight()
+	ret.DepartureTime = timestamppb.New(ret.DepartureTime.AsTime().AddDate(0, 0, -3))
+	ret.ArrivalTime = timestamppb.New(ret.ArrivalTime.AsTime().AddDate(0, 0, -3))
+
+	updated, conflicts, err := agent.UpdateTrip(context.Background(), saved, edited)
+	if err != nil {
+		t.Fatalf("UpdateTrip failed: %v", err)
+	}
+	assert.Nil(t, updated)
+	assert.Empty(t, desk.checked, "conflicting edits are not re-verified")
+	if assert.Len(t, conflicts, 1) {
+		assert.Equal(t, "paris->london", conflicts[0].ComponentId)
+		assert.Equal(t, "Moving your flight to the 11th conflicts with check-out in Paris on the 14th", conflicts[0].Message)
+		assert.Equal(t, "Check out on the 11th, or move the flight to the 14th", conflicts[0].Suggestion)
+	}
+
+	// The same conflict introduced from the hotel side is phrased around the check-out
+	edited = proto.Clone(saved).(*pb.Itinerary)
+	stay := edited.Graph.Nodes[1].Stay
+	stay.CheckOut = timestamppb.New(stay.CheckOut.AsTime().AddDate(0, 0, 1))
+
+	_, conflicts, err = agent.UpdateTrip(context.Background(), saved, edited)
+	assert.NoError(t, err)
+	if assert.Len(t, conflicts, 1) {
+		assert.Equal(t, "paris", conflicts[0].ComponentId)
+		assert.Equal(t, "Moving check-out in Paris to the 15th conflicts with your flight on the 14th", conflicts[0].Message)
+	}
+}
+
+func TestTravelAgent_UpdateTrip_MissedConnection(t *testing.T) {
+	agent := NewTravelAgent(new(MockPlanner), &stubDesk{})
+
+	saved := savedParisTrip()
+	edited := proto.Clone(saved).(*pb.Itinerary)
+	// Fly out on the 14th, landing after the flight home has left
+	out := edited.Graph.Edges[0].Transport.GetFlight()
+	out.DepartureTime = timestamppb.New(out.DepartureTime.AsTime().AddDate(0, 0, 4).Add(time.Hour))
+	out.ArrivalTime = timestamppb.New(out.ArrivalTime.AsTime().AddDate(0, 0, 4).Add(time.Hour))
+
+	_, conflicts, err := agent.UpdateTrip(context.Background(), saved, edited)
+	assert.NoError(t, err)
+	var messages []string
+	for _, c := range conflicts {
+		messages = append(messages, c.Message)
+	}
+	assert.Contains(t, messages, "Your flight out of Paris departs at Mar 14 09:00, before your flight arrives at Mar 14 12:00")
+	// The flight home is a later leg, so leaving London earlier is no conflict
+	for _, m := range messages {
+		assert.NotContains(t, m, "out of London")
+	}
+}
+
+func TestTravelAgent_UpdateTrip_StaleVersion(t *testing.T) {
+	desk := &stubDesk{}
+	agent := NewTravelAgent(new(MockPlanner), desk)
+
+	saved := savedParisTrip()
+	edited := proto.Clone(saved).(*pb.Itinerary)
+	edited.Title = "Paris Long Weekend"
+	// Someone else saved a newer version in the meantime
+	saved.Version++
+
+	updated, conflicts, err := agent.UpdateTrip(context.Background(), saved, edited)
+	assert.True(t, errors.Is(err, ErrVersionConflict))
+	assert.Nil(t, updated)
+	assert.Empty(t, conflicts)
+	assert.Empty(t, desk.checked)
+}
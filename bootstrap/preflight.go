@@ -0,0 +1,112 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/va6996/travelingman/config"
+	"github.com/va6996/travelingman/log"
+	"github.com/va6996/travelingman/plugins/amadeus"
+	"github.com/va6996/travelingman/plugins/nager"
+)
+
+// GeminiModelsURL is the endpoint used to check that a Gemini API key works
+const GeminiModelsURL = "https://generativelanguage.googleapis.com/v1beta/models"
+
+// ZaiBaseURL is the OpenAI-compatible Z.ai API; its models endpoint checks the API key
+const ZaiBaseURL = "https://api.z.ai/api/coding/paas/v4/"
+
+// PreflightCheck is a named connectivity check run once at startup
+type PreflightCheck struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// RunPreflight runs every check, logging each result, and returns an error
+// listing all failed checks (nil if they all passed).
+// Each check gets its own timeout so one hung provider does not stall the rest.
+func RunPreflight(ctx context.Context, checks []PreflightCheck, timeout time.Duration) error {
+	var failures []string
+	for _, check := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, timeout)
+		start := time.Now()
+		err := check.Run(checkCtx)
+		cancel()
+
+		if err != nil {
+			log.Errorf(ctx, "Preflight: %s FAILED after %v: %v", check.Name, time.Since(start).Round(time.Millisecond), err)
+			failures = append(failures, fmt.Sprintf("%s: %v", check.Name, err))
+			continue
+		}
+		log.Infof(ctx, "Preflight: %s OK (%v)", check.Name, time.Since(start).Round(time.Millisecond))
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("preflight failed (%d of %d checks):\n- %s", len(failures), len(checks), strings.Join(failures, "\n- "))
+	}
+	return nil
+}
+
+// preflightChecks builds the checks for the configured providers
+func preflightChecks(cfg *config.Config, amadeusClient *amadeus.Client, nagerClient *nager.Client) []PreflightCheck {
+	checks := []PreflightCheck{AmadeusPreflight(amadeusClient)}
+
+	switch cfg.AI.Plugin {
+	case "gemini":
+		// The key goes in a header so it cannot end up in a logged URL
+		checks = append(checks, HTTPPreflight("Gemini", GeminiModelsURL, http.Header{"X-Goog-Api-Key": {cfg.AI.Gemini.APIKey}}))
+	case "ollama":
+		checks = append(checks, HTTPPreflight("Ollama", strings.TrimSuffix(cfg.AI.Ollama.BaseURL, "/")+"/api/tags", nil))
+	case "zai":
+		checks = append(checks, HTTPPreflight("Z.ai", ZaiBaseURL+"models", http.Header{"Authorization": {"Bearer " + cfg.AI.Zai.APIKey}}))
+	}
+
+	if nagerClient != nil {
+		checks = append(checks, PreflightCheck{
+			Name: "Nager",
+			Run: func(ctx context.Context) error {
+				_, err := nagerClient.GetAvailableCountries(ctx)
+				return err
+			},
+		})
+	}
+	return checks
+}
+
+// AmadeusPreflight checks that the Amadeus credentials can obtain a token
+func AmadeusPreflight(client *amadeus.Client) PreflightCheck {
+	return PreflightCheck{
+		Name: "Amadeus",
+		Run: func(ctx context.Context) error {
+			return client.Authenticate(ctx)
+		},
+	}
+}
+
+// HTTPPreflight checks that a GET to endpoint (with the given headers) succeeds with a 2xx status
+func HTTPPreflight(name, endpoint string, header http.Header) PreflightCheck {
+	return PreflightCheck{
+		Name: name,
+		Run: func(ctx context.Context) error {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+			if err != nil {
+				return err
+			}
+			for k, v := range header {
+				req.Header[k] = v
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+				return fmt.Errorf("unexpected status: %s", resp.Status)
+			}
+			return nil
+		},
+	}
+}
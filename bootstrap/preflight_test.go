@@ -0,0 +1,110 @@
+package bootstrap
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/config"
+	"github.com/va6996/travelingman/plugins/amadeus"
+)
+
+func TestRunPreflight(t *testing.T) {
+	// Amadeus rejects the credentials
+	amadeusServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":"invalid_client"}`))
+	}))
+	defer amadeusServer.Close()
+
+	client, err := amadeus.NewClient(amadeus.Config{ClientID: "bad", ClientSecret: "bad", Timeout: 5}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	client.BaseURL = amadeusServer.URL
+
+	// The AI provider is reachable and receives the key as a header
+	var gotKey string
+	aiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKey = r.Header.Get("X-Goog-Api-Key")
+		w.Write([]byte(`{"models":[]}`))
+	}))
+	defer aiServer.Close()
+
+	checks := []PreflightCheck{
+		AmadeusPreflight(client),
+		HTTPPreflight("Gemini", aiServer.URL, http.Header{"X-Goog-Api-Key": {"test-key"}}),
+	}
+
+	err = RunPreflight(context.Background(), checks, 5*time.Second)
+	if !assert.Error(t, err) {
+		return
+	}
+	assert.Contains(t, err.Error(), "1 of 2 checks")
+	assert.Contains(t, err.Error(), "Amadeus: authentication failed: 401")
+	assert.NotContains(t, err.Error(), "Gemini")
+	assert.Equal(t, "test-key", gotKey)
+}
+
+func TestRunPreflight_AllPass(t *testing.T) {
+	ok := PreflightCheck{Name: "ok", Run: func(ctx context.Context) error { return nil }}
+	assert.NoError(t, RunPreflight(context.Background(), []PreflightCheck{ok, ok}, time.Second))
+}
+
+func TestRunPreflight_Timeout(t *testing.T) {
+	hung := PreflightCheck{Name: "hung", Run: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+
+	err := RunPreflight(context.Background(), []PreflightCheck{hung}, 10*time.Millisecond)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "hung: context deadline exceeded")
+	}
+}
+
+func TestAmadeusPreflight_Timeout(t *testing.T) {
+	// The token endpoint never answers
+	release := make(chan struct{})
+	amadeusServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer amadeusServer.Close()
+	defer close(release)
+
+	client, err := amadeus.NewClient(amadeus.Config{ClientID: "id", ClientSecret: "secret", Timeout: 30}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	client.BaseURL = amadeusServer.URL
+
+	start := time.Now()
+	err = RunPreflight(context.Background(), []PreflightCheck{AmadeusPreflight(client)}, 50*time.Millisecond)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "context deadline exceeded")
+	}
+	assert.Less(t, time.Since(start), 5*time.Second, "the check gives up at its own timeout, not the client's")
+}
+
+func TestPreflightChecks_AIPlugin(t *testing.T) {
+	client, err := amadeus.NewClient(amadeus.Config{ClientID: "id", ClientSecret: "secret", Timeout: 5}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	for plugin, want := range map[string]string{"gemini": "Gemini", "ollama": "Ollama", "zai": "Z.ai"} {
+		cfg := &config.Config{}
+		cfg.AI.Plugin = plugin
+		var names []string
+		for _, check := range preflightChecks(cfg, client, nil) {
+			names = append(names, check.Name)
+		}
+		assert.Equal(t, []string{"Amadeus", want}, names, plugin)
+	}
+}
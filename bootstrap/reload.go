@@ -0,0 +1,130 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/sirupsen/logrus"
+	"github.com/va6996/travelingman/config"
+	"github.com/va6996/travelingman/log"
+)
+
+// Reload re-reads the configuration and applies the fields that are safe to change
+// while the server is running. If the new configuration fails to load or validate,
+// the running configuration is kept.
+func (a *App) Reload(ctx context.Context) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("reload failed, keeping current config: %w", err)
+	}
+	a.ApplyTunables(ctx, cfg)
+	return nil
+}
+
+// ApplyTunables applies the runtime-tunable subset of cfg to the running app:
+// the log level, the Amadeus result limits, the planner's retry budget and target
+// options, the autocomplete rate and the prompt templates. The server applies the new
+// autocomplete rate to its limiter after a reload.
+// Other settings (credentials, AI plugin, port, timeouts, retries, cache TTLs and size) are
+// only read at startup; changes to them are logged and require a restart.
+func (a *App) ApplyTunables(ctx context.Context, cfg *config.Config) {
+	a.tunablesMu.Lock()
+	defer a.tunablesMu.Unlock()
+	if a.Config == nil {
+		a.Config = &config.Config{}
+	}
+	current := a.Config
+
+	// Log level
+	if cfg.Log.Level != current.Log.Level {
+		level, err := logrus.ParseLevel(cfg.Log.Level)
+		if err != nil {
+			log.Warnf(ctx, "Reload: invalid log level %q, keeping %q", cfg.Log.Level, current.Log.Level)
+		} else {
+			log.SetLevel(level)
+			log.Infof(ctx, "Reload: log level changed from %q to %q", current.Log.Level, cfg.Log.Level)
+			current.Log.Level = cfg.Log.Level
+		}
+	}
+
+	// Amadeus limits
+	if cfg.Amadeus.Limit != current.Amadeus.Limit {
+		if a.Amadeus != nil {
+			a.Amadeus.SetLimits(cfg.Amadeus.Limit.Flight, cfg.Amadeus.Limit.Hotel)
+			a.Amadeus.SetHotelListLimit(cfg.Amadeus.Limit.HotelList)
+		}
+		log.Infof(ctx, "Reload: Amadeus limits changed to flight=%d hotel=%d hotel_list=%d", cfg.Amadeus.Limit.Flight, cfg.Amadeus.Limit.Hotel, cfg.Amadeus.Limit.HotelList)
+		current.Amadeus.Limit = cfg.Amadeus.Limit
+	}
+
+	// Planner knobs; the retry budget and call ceiling are read from the config for each request
+	if cfg.Planner.RetryBudget != current.Planner.RetryBudget {
+		log.Infof(ctx, "Reload: planner retry budget changed from %d to %d", current.Planner.RetryBudget, cfg.Planner.RetryBudget)
+		current.Planner.RetryBudget = cfg.Planner.RetryBudget
+	}
+	if cfg.Planner.MaxProviderCalls != current.Planner.MaxProviderCalls {
+		log.Infof(ctx, "Reload: planner max provider calls changed from %d to %d", current.Planner.MaxProviderCalls, cfg.Planner.MaxProviderCalls)
+		current.Planner.MaxProviderCalls = cfg.Planner.MaxProviderCalls
+	}
+	if cfg.Planner.TargetOptions != current.Planner.TargetOptions {
+		if a.TravelAgent != nil {
+			a.TravelAgent.SetTargetOptions(cfg.Planner.TargetOptions)
+		}
+		log.Infof(ctx, "Reload: planner target options changed from %d to %d", current.Planner.TargetOptions, cfg.Planner.TargetOptions)
+		current.Planner.TargetOptions = cfg.Planner.TargetOptions
+	}
+
+	// Autocomplete rate limit
+	if cfg.Server.AutocompleteRate != current.Server.AutocompleteRate {
+		log.Infof(ctx, "Reload: autocomplete rate changed from %d to %d per minute", current.Server.AutocompleteRate, cfg.Server.AutocompleteRate)
+		current.Server.AutocompleteRate = cfg.Server.AutocompleteRate
+	}
+
+	// Prompt templates are re-read even if the directory is unchanged, to pick up edits
+	if a.Prompts != nil {
+		a.Prompts.Load(ctx, cfg.Planner.PromptDir)
+	}
+	if cfg.Planner.PromptDir != current.Planner.PromptDir {
+		log.Infof(ctx, "Reload: prompt directory changed from %q to %q", current.Planner.PromptDir, cfg.Planner.PromptDir)
+		current.Planner.PromptDir = cfg.Planner.PromptDir
+	}
+
+	// Everything else needs a restart
+	if cfg.Server != current.Server ||
+		cfg.AI != current.AI ||
+		!reflect.DeepEqual(cfg.Planner, current.Planner) ||
+		cfg.Amadeus.ClientID != current.Amadeus.ClientID ||
+		cfg.Amadeus.ClientSecret != current.Amadeus.ClientSecret ||
+		cfg.Amadeus.Environment != current.Amadeus.Environment ||
+		cfg.Amadeus.Timeout != current.Amadeus.Timeout ||
+		cfg.Amadeus.HotelOffers != current.Amadeus.HotelOffers ||
+		cfg.Amadeus.HotelRelaxation != current.Amadeus.HotelRelaxation ||
+		cfg.Amadeus.HotelFallback != current.Amadeus.HotelFallback ||
+		cfg.Amadeus.CacheTTL != current.Amadeus.CacheTTL ||
+		cfg.Amadeus.CacheMaxEntries != current.Amadeus.CacheMaxEntries ||
+		cfg.Amadeus.Retry != current.Amadeus.Retry ||
+		cfg.Log.Tail != current.Log.Tail ||
+		cfg.Tavily != current.Tavily ||
+		cfg.DB != current.DB ||
+		cfg.Preflight != current.Preflight ||
+		cfg.Bookings != current.Bookings ||
+		cfg.TestMode != current.TestMode {
+		log.Warnf(ctx, "Reload: some changed settings only take effect after a restart")
+	}
+}
+
+// PlannerBudgets returns the retry budget and provider call ceiling (0 for none) a new
+// planning request starts with
+func (a *App) PlannerBudgets() (retryBudget, maxProviderCalls int) {
+	a.tunablesMu.RLock()
+	defer a.tunablesMu.RUnlock()
+	return a.Config.Planner.RetryBudget, a.Config.Planner.MaxProviderCalls
+}
+
+// AutocompleteRate returns the autocomplete requests allowed per client per minute
+func (a *App) AutocompleteRate() int {
+	a.tunablesMu.RLock()
+	defer a.tunablesMu.RUnlock()
+	return a.Config.Server.AutocompleteRate
+}
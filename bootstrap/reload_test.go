@@ -0,0 +1,154 @@
+package bootstrap
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/agents"
+	"github.com/va6996/travelingman/config"
+	"github.com/va6996/travelingman/log"
+	"github.com/va6996/travelingman/plugins/amadeus"
+	"github.com/va6996/travelingman/prompts"
+)
+
+func writeConfig(t *testing.T, contents string) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	t.Setenv("CONFIG_PATH", path)
+	t.Setenv("GEMINI_API_KEY", "test-key")
+	t.Setenv("AMADEUS_CLIENT_ID", "test-id")
+	t.Setenv("AMADEUS_CLIENT_SECRET", "test-secret")
+}
+
+func TestApp_Reload(t *testing.T) {
+	origLevel := log.Logger.GetLevel()
+	defer log.SetLevel(origLevel)
+
+	writeConfig(t, "log:\n  level: info\namadeus:\n  limit:\n    flight: 10\n    hotel: 10\n")
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	log.SetLevel(logrus.InfoLevel)
+
+	client, err := amadeus.NewClient(amadeus.Config{FlightLimit: 10, HotelLimit: 10}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	app := &App{Config: cfg, Amadeus: client}
+
+	t.Run("LogLevelAndLimits", func(t *testing.T) {
+		writeConfig(t, "log:\n  level: debug\namadeus:\n  limit:\n    flight: 3\n    hotel: 5\n")
+
+		err := app.Reload(context.Background())
+		assert.NoError(t, err)
+
+		assert.Equal(t, logrus.DebugLevel, log.Logger.GetLevel())
+		flight, hotel := client.Limits()
+		assert.Equal(t, 3, flight)
+		assert.Equal(t, 5, hotel)
+		assert.Equal(t, "debug", app.Config.Log.Level)
+	})
+
+	t.Run("InvalidConfigKeepsCurrent", func(t *testing.T) {
+		writeConfig(t, "log:\n  level: warn\namadeus:\n  limit:\n    flight: -1\n")
+
+		err := app.Reload(context.Background())
+		assert.Error(t, err)
+
+		assert.Equal(t, logrus.DebugLevel, log.Logger.GetLevel())
+		flight, _ := client.Limits()
+		assert.Equal(t, 3, flight)
+	})
+
+	t.Run("PlannerKnobsAndRates", func(t *testing.T) {
+		app.TravelAgent = agents.NewTravelAgent(nil, nil)
+		app.TravelAgent.SetTargetOptions(cfg.Planner.TargetOptions)
+		writeConfig(t, "log:\n  level: debug\namadeus:\n  limit:\n    flight: 3\n    hotel: 5\n"+
+			"server:\n  autocomplete_rate: 30\nplanner:\n  retry_budget: 4\n  target_options: 1\n")
+
+		assert.NoError(t, app.Reload(context.Background()))
+		assert.Equal(t, 1, app.TravelAgent.TargetOptions())
+		assert.Equal(t, 1, app.Config.Planner.TargetOptions)
+		retries, _ := app.PlannerBudgets()
+		assert.Equal(t, 4, retries)
+		assert.Equal(t, 30, app.AutocompleteRate())
+	})
+
+	t.Run("PromptOverrides", func(t *testing.T) {
+		app.Prompts = prompts.New()
+		dir := t.TempDir()
+		override := filepath.Join(dir, prompts.TripPlanner+".tmpl")
+		if err := os.WriteFile(override, []byte("v1 {{.Tools}} {{.Schema}}"), 0o600); err != nil {
+			t.Fatalf("Failed to write prompt: %v", err)
+		}
+		writeConfig(t, "log:\n  level: debug\namadeus:\n  limit:\n    flight: 3\n    hotel: 5\nplanner:\n  prompt_dir: "+dir+"\n")
+
+		assert.NoError(t, app.Reload(context.Background()))
+		tmpl, _ := app.Prompts.Get(prompts.TripPlanner)
+		v1 := tmpl.Version
+		assert.True(t, strings.HasPrefix(v1, "override@"), v1)
+		assert.Equal(t, dir, app.Config.Planner.PromptDir)
+
+		// An edited template is picked up by the next reload
+		if err := os.WriteFile(override, []byte("v2 {{.Tools}} {{.Schema}}"), 0o600); err != nil {
+			t.Fatalf("Failed to write prompt: %v", err)
+		}
+		assert.NoError(t, app.Reload(context.Background()))
+		tmpl, _ = app.Prompts.Get(prompts.TripPlanner)
+		text, err := tmpl.Render(prompts.Vars{})
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(text, "v2 "), text)
+		assert.NotEqual(t, v1, tmpl.Version)
+	})
+}
+
+func TestApp_ApplyTunables_Concurrent(t *testing.T) {
+	origLevel := log.Logger.GetLevel()
+	defer log.SetLevel(origLevel)
+
+	app := &App{Config: &config.Config{}}
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+
+	// Requests read the knobs while reloads change them; run with -race
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+					retries, calls := app.PlannerBudgets()
+					assert.Equal(t, retries*2, calls)
+					app.AutocompleteRate()
+				}
+			}
+		}()
+	}
+	for i := range 100 {
+		cfg := &config.Config{}
+		cfg.Log.Level = origLevel.String()
+		cfg.Planner.RetryBudget = i
+		cfg.Planner.MaxProviderCalls = i * 2
+		cfg.Server.AutocompleteRate = i
+		app.ApplyTunables(context.Background(), cfg)
+	}
+	close(done)
+	wg.Wait()
+
+	retries, calls := app.PlannerBudgets()
+	assert.Equal(t, 99, retries)
+	assert.Equal(t, 198, calls)
+	assert.Equal(t, 99, app.AutocompleteRate())
+}
@@ -3,7 +3,12 @@ package bootstrap
 import (
 	"context"
 	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/firebase/genkit/go/ai"
 	"github.com/firebase/genkit/go/genkit"
@@ -13,12 +18,15 @@ import (
 	"github.com/va6996/travelingman/agents"
 	zaiconfig "github.com/va6996/travelingman/bootstrap/zai"
 	"github.com/va6996/travelingman/config"
+	tmcontext "github.com/va6996/travelingman/context"
 	"github.com/va6996/travelingman/log"
+	"github.com/va6996/travelingman/metrics"
 	"github.com/va6996/travelingman/orm"
 	"github.com/va6996/travelingman/plugins/amadeus"
 	"github.com/va6996/travelingman/plugins/core"
 	"github.com/va6996/travelingman/plugins/nager"
 	"github.com/va6996/travelingman/plugins/tavily"
+	"github.com/va6996/travelingman/prompts"
 	"github.com/va6996/travelingman/tools"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -27,9 +35,23 @@ import (
 // App holds the initialized components of the application
 type App struct {
 	TravelAgent *agents.TravelAgent
+	Bookings    *agents.BookingTracker
+	Groups      *agents.GroupService
 	Genkit      *genkit.Genkit
 	Registry    *tools.Registry
 	Model       ai.Model
+	Amadeus     *amadeus.Client
+	LogTail     *log.Tail        // Recent lines per request ID; nil if capture is off
+	Prompts     *prompts.Library // Prompt templates, re-read on reload
+	DB          *gorm.DB
+	Config      *config.Config
+	Clock       tmcontext.Clock    // Fixed in test mode; nil uses the wall clock
+	PlanPhases  *metrics.Histogram // PlanTrip latency by phase, served at /metrics
+
+	// tunablesMu guards the fields of Config a reload changes while requests are served:
+	// the log level, the planner's knobs and the autocomplete rate. Handlers read them
+	// through PlannerBudgets and AutocompleteRate.
+	tunablesMu sync.RWMutex
 }
 
 // Setup initializes the application components based on the configuration
@@ -42,9 +64,21 @@ func Setup(ctx context.Context, cfg *config.Config) (*App, error) {
 	log.SetLevel(level)
 	log.Infof(ctx, "Log level set to: %s", level)
 
+	var logTail *log.Tail
+	if cfg.Log.Tail.Lines > 0 {
+		logTail = log.NewTail(log.TailConfig{
+			LinesPerRequest: cfg.Log.Tail.Lines,
+			MaxRequests:     cfg.Log.Tail.Requests,
+			Retention:       time.Duration(cfg.Log.Tail.Retention) * time.Minute,
+		})
+		log.Logger.AddHook(logTail)
+	}
+
 	// 1. Setup Genkit with AI Plugin
 	var gk *genkit.Genkit
 	var model ai.Model
+	// defineModel returns a model of the chosen plugin by name, for the fallbacks
+	var defineModel func(name string) ai.Model
 
 	if cfg.AI.Plugin == "ollama" {
 		log.Infof(ctx, "Using Ollama Plugin (Model: %s)...", cfg.AI.Ollama.Model)
@@ -54,17 +88,20 @@ func Setup(ctx context.Context, cfg *config.Config) (*App, error) {
 		gk = genkit.Init(ctx, genkit.WithPlugins(ollamaPlugin))
 
 		// Define the model with capabilities - explicitly enable tool support
-		model = ollamaPlugin.DefineModel(gk, ollama.ModelDefinition{
-			Name: cfg.AI.Ollama.Model,
-			Type: "chat",
-		}, &ai.ModelOptions{
-			Supports: &ai.ModelSupports{
-				Multiturn:  true,
-				SystemRole: true,
-				Tools:      true, // Enable tool support
-				Media:      false,
-			},
-		})
+		defineModel = func(name string) ai.Model {
+			return ollamaPlugin.DefineModel(gk, ollama.ModelDefinition{
+				Name: name,
+				Type: "chat",
+			}, &ai.ModelOptions{
+				Supports: &ai.ModelSupports{
+					Multiturn:  true,
+					SystemRole: true,
+					Tools:      true, // Enable tool support
+					Media:      false,
+				},
+			})
+		}
+		model = defineModel(cfg.AI.Ollama.Model)
 	} else if cfg.AI.Plugin == "zai" {
 		log.Infof(ctx, "Using Z.ai Plugin (Model: %s)...", cfg.AI.Zai.Model)
 		if cfg.AI.Zai.APIKey == "" {
@@ -74,10 +111,11 @@ func Setup(ctx context.Context, cfg *config.Config) (*App, error) {
 		// Z.ai is OpenAI-compatible with base URL https://api.z.ai/api/paas/v4/
 		zaiPlugin := &zaiconfig.Zai{
 			APIKey:  cfg.AI.Zai.APIKey,
-			BaseURL: "https://api.z.ai/api/coding/paas/v4/",
+			BaseURL: ZaiBaseURL,
 		}
 		gk = genkit.Init(ctx, genkit.WithPlugins(zaiPlugin))
-		model = zaiPlugin.Model(gk, cfg.AI.Zai.Model)
+		defineModel = func(name string) ai.Model { return zaiPlugin.Model(gk, name) }
+		model = defineModel(cfg.AI.Zai.Model)
 	} else {
 		log.Info(context.Background(), "Using Gemini Plugin...")
 		if cfg.AI.Gemini.APIKey == "" {
@@ -87,7 +125,30 @@ func Setup(ctx context.Context, cfg *config.Config) (*App, error) {
 		gk = genkit.Init(ctx, genkit.WithPlugins(&googlegenai.GoogleAI{
 			APIKey: cfg.AI.Gemini.APIKey,
 		}))
-		model = googlegenai.GoogleAIModel(gk, cfg.AI.Gemini.Model)
+		defineModel = func(name string) ai.Model { return googlegenai.GoogleAIModel(gk, name) }
+		model = defineModel(cfg.AI.Gemini.Model)
+	}
+
+	var fallbacks []ai.Model
+	for _, name := range cfg.AI.Fallbacks() {
+		m := defineModel(name)
+		if m == nil {
+			log.Warnf(ctx, "Fallback model %s is not known to the %s plugin, skipping it", name, cfg.AI.Plugin)
+			continue
+		}
+		fallbacks = append(fallbacks, m)
+	}
+
+	// Test mode fixes the clock and seeds request IDs so that runs are reproducible
+	var clock tmcontext.Clock
+	if cfg.TestMode.Enabled {
+		now, err := cfg.TestMode.Clock()
+		if err != nil {
+			return nil, fmt.Errorf("invalid test_mode.now: %w", err)
+		}
+		clock = tmcontext.FixedClock(now)
+		tmcontext.SetIDGenerator(tmcontext.SeededIDs(cfg.TestMode.Seed))
+		log.Warnf(ctx, "Test mode: clock fixed at %s, request IDs seeded with %d", now.Format(time.RFC3339), cfg.TestMode.Seed)
 	}
 
 	// 1.5 Setup Database
@@ -95,7 +156,7 @@ func Setup(ctx context.Context, cfg *config.Config) (*App, error) {
 	// unless specifically configured otherwise. For now, we enforce SQLite to fix the error.
 	dbFile := "travelingman.db"
 	log.Infof(ctx, "Connecting to SQLite database at %s...", dbFile)
-	db, err := gorm.Open(sqlite.Open(dbFile), &gorm.Config{})
+	db, err := gorm.Open(sqlite.Open(dbFile), &gorm.Config{NowFunc: clock})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
@@ -112,18 +173,39 @@ func Setup(ctx context.Context, cfg *config.Config) (*App, error) {
 		&orm.Accommodation{},
 		&orm.Transport{},
 		&orm.APICache{},
+		&orm.SavedTrip{},
+		&orm.Trip{},
+		&orm.TripDay{},
+		&orm.Place{},
+		&orm.TripTransport{},
+		&orm.User{},
+		&orm.TravelGroup{},
+		&orm.Booking{},
+		&orm.BookingStatusChange{},
+		&orm.Payment{},
+		&orm.Location{},
 	); err != nil {
 		return nil, fmt.Errorf("failed to migrate database schema: %w", err)
 	}
 
 	// 2. Init Tools Registry
 	registry := tools.NewRegistry()
+	registry.SetMaxResultBytes(cfg.Planner.MaxToolResultBytes)
+	registry.SetToolTimeout(time.Duration(cfg.Planner.ToolTimeout) * time.Second)
+	for name, secs := range cfg.Planner.ToolTimeouts {
+		registry.SetToolTimeoutFor(name, time.Duration(secs)*time.Second)
+	}
 
 	// Core Tools
 	core.NewClient(gk, registry)
+	core.SetTripLengthLimits(core.TripLengthLimits{
+		Min: time.Duration(cfg.Planner.MinTripHours) * time.Hour,
+		Max: time.Duration(cfg.Planner.MaxTripDays) * 24 * time.Hour,
+	})
 
 	// Nager Holiday API
-	nager.NewClient(gk, registry)
+	nagerClient := nager.NewClient(gk, registry)
+	nagerClient.UserAgent = cfg.Server.OutboundUserAgent()
 
 	// Amadeus
 	if cfg.Amadeus.ClientID == "" || cfg.Amadeus.ClientSecret == "" {
@@ -140,16 +222,34 @@ func Setup(ctx context.Context, cfg *config.Config) (*App, error) {
 
 	// Initializing Amadeus client registers its tools automatically
 	amadeusConfig := amadeus.Config{
-		ClientID:     cfg.Amadeus.ClientID,
-		ClientSecret: cfg.Amadeus.ClientSecret,
-		IsProduction: isProd,
-		FlightLimit:  cfg.Amadeus.Limit.Flight,
-		HotelLimit:   cfg.Amadeus.Limit.Hotel,
-		Timeout:      cfg.Amadeus.Timeout,
+		ClientID:       cfg.Amadeus.ClientID,
+		ClientSecret:   cfg.Amadeus.ClientSecret,
+		IsProduction:   isProd,
+		FlightLimit:    cfg.Amadeus.Limit.Flight,
+		HotelLimit:     cfg.Amadeus.Limit.Hotel,
+		HotelListLimit: cfg.Amadeus.Limit.HotelList,
+		HotelOffers: amadeus.HotelOffersConfig{
+			BestRateOnly:    cfg.Amadeus.HotelOffers.BestRateOnly,
+			PerHotel:        cfg.Amadeus.HotelOffers.PerHotel,
+			PreferredChains: cfg.Amadeus.PreferredChains(),
+		},
+		HotelRelaxation: amadeus.HotelRelaxationConfig{
+			Steps:     cfg.Amadeus.RelaxationSteps(),
+			MinRating: cfg.Amadeus.HotelRelaxation.MinRating,
+		},
+		HotelFallbackRadiusKm: cfg.Amadeus.HotelFallback.RadiusKm,
+		Timeout:               cfg.Amadeus.Timeout,
+		UserAgent:             cfg.Server.OutboundUserAgent(),
 		CacheTTL: amadeus.CacheTTLConfig{
 			Location: cfg.Amadeus.CacheTTL.Location,
 			Flight:   cfg.Amadeus.CacheTTL.Flight,
 			Hotel:    cfg.Amadeus.CacheTTL.Hotel,
+			Calendar: cfg.Amadeus.CacheTTL.Calendar,
+		},
+		CacheMaxEntries: cfg.Amadeus.CacheMaxEntries,
+		Retry: amadeus.RetryConfig{
+			MaxRetries:  cfg.Amadeus.Retry.MaxRetries,
+			BaseDelayMs: cfg.Amadeus.Retry.BaseDelayMs,
 		},
 	}
 
@@ -162,6 +262,9 @@ func Setup(ctx context.Context, cfg *config.Config) (*App, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize Amadeus client: %w", err)
 	}
+	// Stays in places without hotel coverage fall back to the nearest covered city
+	amadeusClient.Nearby = core.DefaultPlaceIndex()
+	amadeusClient.Cache.Clock = clock
 
 	// Tavily Search API (optional - if API key is provided)
 	if cfg.Tavily.APIKey != "" {
@@ -171,16 +274,80 @@ func Setup(ctx context.Context, cfg *config.Config) (*App, error) {
 		log.Info(ctx, "Tavily API key not provided, Tavily tools will not be available")
 	}
 
+	// 2.5 Optional connectivity checks, so bad credentials surface now rather than on the first request
+	if cfg.Preflight.Enabled {
+		checks := preflightChecks(cfg, amadeusClient, nagerClient)
+		if err := RunPreflight(ctx, checks, time.Duration(cfg.Preflight.Timeout)*time.Second); err != nil {
+			if cfg.Preflight.FailFast {
+				return nil, err
+			}
+			log.Warnf(ctx, "Continuing despite preflight failures (set PREFLIGHT_FAIL_FAST=true to abort)")
+		}
+	}
+
 	// 3. Init New Agents
 	log.Info(context.Background(), "Initializing New Agents...")
 	tripPlanner := agents.NewTripPlanner(gk, registry, model)
+	tripPlanner.Prompts.Load(ctx, cfg.Planner.PromptDir)
+	tripPlanner.Timeout = time.Duration(cfg.Planner.Timeout) * time.Second
+	tripPlanner.Fallbacks = fallbacks
 	travelDesk := agents.NewTravelDesk(amadeusClient)
 	travelAgent := agents.NewTravelAgent(tripPlanner, travelDesk)
+	travelAgent.SetTargetOptions(cfg.Planner.TargetOptions)
+	travelAgent.BreakfastValue = float64(cfg.Planner.BreakfastValue)
+	travelAgent.ReplanPolicy = agents.ReplanPolicy(cfg.Planner.ReplanOn)
+	travelAgent.QuickTimeout = time.Duration(cfg.Planner.QuickTimeout) * time.Second
+	travelAgent.Clock = clock
+	planPhases := metrics.NewHistogram("travelingman_plantrip_phase_seconds",
+		"Time spent in each phase of planning a trip, per planning round.", "phase", metrics.DefaultBuckets)
+	travelAgent.Phases = planPhases
+	travelAgent.SelfTransferBuffer = time.Duration(cfg.Connections.SelfTransferBuffer) * time.Minute
+	if len(cfg.Connections.Overrides) > 0 {
+		overrides := make(map[string]core.MinConnectionTime, len(cfg.Connections.Overrides))
+		for code, o := range cfg.Connections.Overrides {
+			overrides[code] = core.MinConnectionTime{
+				Country:       o.Country,
+				Domestic:      o.Domestic,
+				International: o.International,
+				InterTerminal: o.InterTerminal,
+			}
+		}
+		travelAgent.ConnectionTimes = core.NewConnectionTimes(overrides)
+	}
+
+	bookings := agents.NewBookingTracker(amadeusClient, db)
+	bookings.Booker = amadeusClient
+	bookings.Hotels = amadeusClient
+	var notifiers agents.BookingNotifiers
+	if cfg.Bookings.WebhookURL != "" {
+		notifiers = append(notifiers, &agents.WebhookNotifier{URL: cfg.Bookings.WebhookURL, Client: &http.Client{Timeout: 10 * time.Second}})
+	}
+	if email := cfg.Bookings.Email; email.SMTPAddr != "" {
+		mailer := &agents.EmailNotifier{DB: db, Addr: email.SMTPAddr, From: email.From}
+		if email.Username != "" {
+			host, _, _ := net.SplitHostPort(email.SMTPAddr)
+			mailer.Auth = smtp.PlainAuth("", email.Username, email.Password, host)
+		}
+		notifiers = append(notifiers, mailer)
+		bookings.Mailer = mailer
+	}
+	if len(notifiers) > 0 {
+		bookings.Notifier = notifiers
+	}
 
 	return &App{
 		TravelAgent: travelAgent,
+		Bookings:    bookings,
+		Groups:      agents.NewGroupService(db, travelAgent, bookings),
 		Genkit:      gk,
 		Registry:    registry,
+		Prompts:     tripPlanner.Prompts,
 		Model:       model,
+		Amadeus:     amadeusClient,
+		LogTail:     logTail,
+		PlanPhases:  planPhases,
+		DB:          db,
+		Config:      cfg,
+		Clock:       clock,
 	}, nil
 }
@@ -1,23 +1,133 @@
 package config
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
+// DefaultPath is the config file read by Load when CONFIG_PATH is not set
+const DefaultPath = "config.yaml"
+
+// Version is the app version, set at build time with
+// -ldflags "-X github.com/va6996/travelingman/config.Version=..."
+var Version = "dev"
+
 // Config aggregates all application configuration
 type Config struct {
+	Server  ServerConfig   `yaml:"server"`
 	AI      AIConfig       `yaml:"ai"`
 	Planner PlannerConfig  `yaml:"planner"`
 	Amadeus AmadeusConfig  `yaml:"amadeus"`
 	Tavily  TavilyConfig   `yaml:"tavily"`
 	Log     LogConfig      `yaml:"log"`
 	DB      DatabaseConfig `yaml:"database"`
+
+	Preflight   PreflightConfig   `yaml:"preflight"`
+	Connections ConnectionsConfig `yaml:"connections"`
+	Bookings    BookingsConfig    `yaml:"bookings"`
+	TestMode    TestModeConfig    `yaml:"test_mode"`
+}
+
+type ServerConfig struct {
+	Port string `yaml:"port" env:"PORT" env-default:"8000"`
+	// Location autocomplete requests allowed per minute from one client IP (0 disables the limit)
+	AutocompleteRate int `yaml:"autocomplete_rate" env:"SERVER_AUTOCOMPLETE_RATE" env-default:"120"`
+	// Sent with outbound provider requests; empty sends travelingman/<version>
+	UserAgent string `yaml:"user_agent" env:"SERVER_USER_AGENT"`
+	// Bearer token for the /debug/logs, /debug/cache and /metrics endpoints; empty disables them
+	DebugToken string `yaml:"debug_token" env:"SERVER_DEBUG_TOKEN"`
+	// Bearer token for BookFlight, CancelBooking and ResumeBooking, which place and cancel
+	// real orders; empty disables them
+	BookingToken string `yaml:"booking_token" env:"SERVER_BOOKING_TOKEN"`
+	// Cache-Control sent with GetItinerary responses, which also carry an ETag
+	ItineraryCacheControl string `yaml:"itinerary_cache_control" env:"SERVER_ITINERARY_CACHE_CONTROL" env-default:"private, no-cache"`
+}
+
+// OutboundUserAgent is the User-Agent sent to providers
+func (s ServerConfig) OutboundUserAgent() string {
+	if s.UserAgent != "" {
+		return s.UserAgent
+	}
+	return "travelingman/" + Version
+}
+
+// PreflightConfig controls the connectivity checks run once at startup
+type PreflightConfig struct {
+	Enabled  bool `yaml:"enabled" env:"PREFLIGHT_ENABLED" env-default:"false"`
+	FailFast bool `yaml:"fail_fast" env:"PREFLIGHT_FAIL_FAST" env-default:"false"` // Abort startup if a check fails
+	Timeout  int  `yaml:"timeout" env:"PREFLIGHT_TIMEOUT" env-default:"10"`        // Seconds, per check
+}
+
+// TestModeConfig makes runs reproducible for demos and golden tests: the clock is
+// fixed and request IDs come from a seeded sequence
+type TestModeConfig struct {
+	Enabled bool   `yaml:"enabled" env:"TEST_MODE_ENABLED" env-default:"false"`
+	Now     string `yaml:"now" env:"TEST_MODE_NOW" env-default:"2030-01-07T09:00:00Z"` // RFC 3339 time the clock is fixed at
+	Seed    int64  `yaml:"seed" env:"TEST_MODE_SEED" env-default:"1"`                  // Seeds the request ID sequence
+}
+
+// Clock returns the time the clock is fixed at
+func (t TestModeConfig) Clock() (time.Time, error) {
+	return time.Parse(time.RFC3339, t.Now)
+}
+
+// BookingsConfig controls how booked orders are kept up to date
+type BookingsConfig struct {
+	PollInterval int    `yaml:"poll_interval" env:"BOOKINGS_POLL_INTERVAL" env-default:"60"` // Minutes between refreshes of upcoming bookings (0 disables polling)
+	WebhookURL   string `yaml:"webhook_url" env:"BOOKINGS_WEBHOOK_URL"`                      // Schedule changes and cancellations are posted here, if set
+	// Schedule changes and cancellations are emailed to the travelers when an SMTP server is set
+	Email struct {
+		SMTPAddr string `yaml:"smtp_addr" env:"BOOKINGS_EMAIL_SMTP_ADDR"` // host:port
+		From     string `yaml:"from" env:"BOOKINGS_EMAIL_FROM"`
+		Username string `yaml:"username" env:"BOOKINGS_EMAIL_USERNAME"` // Empty sends without authenticating
+		Password string `yaml:"password" env:"BOOKINGS_EMAIL_PASSWORD"`
+	} `yaml:"email"`
+}
+
+// ConnectionsConfig tunes how layovers between flights are judged
+type ConnectionsConfig struct {
+	SelfTransferBuffer int `yaml:"self_transfer_buffer" env:"CONNECTIONS_SELF_TRANSFER_BUFFER" env-default:"60"` // Minutes added when connecting flights are booked separately
+	// Corrections to the built-in minimum connection time table, keyed by IATA code.
+	// CONNECTIONS_OVERRIDES replaces them with a JSON object of the same shape.
+	Overrides MCTOverrides `yaml:"overrides" env:"CONNECTIONS_OVERRIDES"`
+}
+
+// MCTOverrides are minimum connection time corrections keyed by IATA code
+type MCTOverrides map[string]MCTOverride
+
+// SetValue parses the JSON form used in the environment,
+// e.g. {"LHR":{"international":90,"inter_terminal":105}}
+func (o *MCTOverrides) SetValue(s string) error {
+	overrides := MCTOverrides{}
+	if err := json.Unmarshal([]byte(s), &overrides); err != nil {
+		return fmt.Errorf("invalid connection overrides JSON: %w", err)
+	}
+	*o = overrides
+	return nil
+}
+
+// MCTOverride replaces an airport's minimum connection times in minutes; zero keeps the built-in value
+type MCTOverride struct {
+	Country       string `yaml:"country" json:"country"`
+	Domestic      int    `yaml:"domestic" json:"domestic"`
+	International int    `yaml:"international" json:"international"`
+	InterTerminal int    `yaml:"inter_terminal" json:"inter_terminal"`
 }
 
 type LogConfig struct {
 	Level string `yaml:"level" env:"LOG_LEVEL" env-default:"info"`
+	// Recent lines kept per request ID and served at /debug/logs/{request_id}
+	Tail struct {
+		Lines     int `yaml:"lines" env:"LOG_TAIL_LINES" env-default:"500"`        // Per request (0 disables capture)
+		Requests  int `yaml:"requests" env:"LOG_TAIL_REQUESTS" env-default:"50"`   // Most recent requests kept
+		Retention int `yaml:"retention" env:"LOG_TAIL_RETENTION" env-default:"30"` // Minutes kept after a request's last line
+	} `yaml:"tail"`
 }
 
 type AIConfig struct {
@@ -25,6 +135,20 @@ type AIConfig struct {
 	Gemini GeminiConfig `yaml:"gemini"`
 	Ollama OllamaConfig `yaml:"ollama"`
 	Zai    ZaiConfig    `yaml:"zai"`
+	// Comma-separated models of the same plugin, tried in order when the model is
+	// out of quota or unavailable
+	FallbackModels string `yaml:"fallback_models" env:"AI_FALLBACK_MODELS"`
+}
+
+// Fallbacks returns the fallback model names, e.g. [gemini-2.5-flash]
+func (a AIConfig) Fallbacks() []string {
+	var models []string
+	for _, m := range strings.Split(a.FallbackModels, ",") {
+		if m = strings.TrimSpace(m); m != "" {
+			models = append(models, m)
+		}
+	}
+	return models
 }
 
 type GeminiConfig struct {
@@ -47,15 +171,63 @@ type AmadeusConfig struct {
 	ClientSecret string `yaml:"client_secret" env:"AMADEUS_CLIENT_SECRET"`
 	Environment  string `yaml:"environment" env:"AMADEUS_ENV" env-default:"test"`
 	Limit        struct {
-		Flight int `yaml:"flight" env:"AMADEUS_LIMIT_FLIGHT" env-default:"10"`
-		Hotel  int `yaml:"hotel" env:"AMADEUS_LIMIT_HOTEL" env-default:"10"`
+		Flight int `yaml:"flight" env:"AMADEUS_LIMIT_FLIGHT,AMADEUS_FLIGHT_LIMIT" env-default:"10"`
+		Hotel  int `yaml:"hotel" env:"AMADEUS_LIMIT_HOTEL,AMADEUS_HOTEL_LIMIT" env-default:"10"`
+		// Listed hotels considered when picking the hotel limit to price
+		HotelList int `yaml:"hotel_list" env:"AMADEUS_LIMIT_HOTEL_LIST" env-default:"30"`
 	} `yaml:"limit"`
+	HotelOffers struct {
+		BestRateOnly bool `yaml:"best_rate_only" env:"AMADEUS_HOTEL_BEST_RATE_ONLY" env-default:"false"` // One rate per hotel instead of several rooms
+		PerHotel     int  `yaml:"per_hotel" env:"AMADEUS_HOTEL_OFFERS_PER_HOTEL" env-default:"3"`        // Max offers kept per hotel
+		// Comma-separated chain codes, e.g. "MC,HH", whose hotels are listed first
+		PreferredChains string `yaml:"preferred_chains" env:"AMADEUS_HOTEL_PREFERRED_CHAINS"`
+	} `yaml:"hotel_offers"`
+	// Retries of a hotel search whose rating/amenity filters leave no hotels
+	HotelRelaxation struct {
+		Steps     string `yaml:"steps" env:"AMADEUS_HOTEL_RELAXATION_STEPS" env-default:"amenities,rating"` // Comma-separated, applied in order
+		MinRating int    `yaml:"min_rating" env:"AMADEUS_HOTEL_RELAXATION_MIN_RATING" env-default:"1"`      // Never lower the rating below this
+	} `yaml:"hotel_relaxation"`
+	// Search of a nearby city when the stay's city has no hotels
+	HotelFallback struct {
+		RadiusKm int `yaml:"radius_km" env:"AMADEUS_HOTEL_FALLBACK_RADIUS_KM" env-default:"50"` // 0 disables
+	} `yaml:"hotel_fallback"`
 	Timeout  int `yaml:"timeout" env:"AMADEUS_TIMEOUT" env-default:"30"` // Seconds
 	CacheTTL struct {
 		Location int `yaml:"location" env:"AMADEUS_CACHE_TTL_LOCATION" env-default:"24"` // Hours
 		Flight   int `yaml:"flight" env:"AMADEUS_CACHE_TTL_FLIGHT" env-default:"1"`      // Hours
 		Hotel    int `yaml:"hotel" env:"AMADEUS_CACHE_TTL_HOTEL" env-default:"1"`        // Hours
+		Calendar int `yaml:"calendar" env:"AMADEUS_CACHE_TTL_CALENDAR" env-default:"24"` // Hours
 	} `yaml:"cache_ttl"`
+	// Entries kept in the in-memory search cache; the least recently used go first (0 for no limit)
+	CacheMaxEntries int `yaml:"cache_max_entries" env:"AMADEUS_CACHE_MAX_ENTRIES" env-default:"5000"`
+	// Retries of lookups that are rate limited (429) or hit a server error; bookings and
+	// cancellations are never retried
+	Retry struct {
+		MaxRetries  int `yaml:"max_retries" env:"AMADEUS_RETRY_MAX_RETRIES" env-default:"2"`       // 0 disables
+		BaseDelayMs int `yaml:"base_delay_ms" env:"AMADEUS_RETRY_BASE_DELAY_MS" env-default:"500"` // Doubled for each retry
+	} `yaml:"retry"`
+}
+
+// PreferredChains returns the preferred hotel chain codes, e.g. [MC HH]
+func (a AmadeusConfig) PreferredChains() []string {
+	var chains []string
+	for _, chain := range strings.Split(a.HotelOffers.PreferredChains, ",") {
+		if chain = strings.ToUpper(strings.TrimSpace(chain)); chain != "" {
+			chains = append(chains, chain)
+		}
+	}
+	return chains
+}
+
+// RelaxationSteps returns the hotel relaxation ladder, e.g. [amenities rating]
+func (a AmadeusConfig) RelaxationSteps() []string {
+	var steps []string
+	for _, step := range strings.Split(a.HotelRelaxation.Steps, ",") {
+		if step = strings.ToLower(strings.TrimSpace(step)); step != "" {
+			steps = append(steps, step)
+		}
+	}
+	return steps
 }
 
 type TavilyConfig struct {
@@ -64,7 +236,30 @@ type TavilyConfig struct {
 }
 
 type PlannerConfig struct {
-	Timeout int `yaml:"timeout" env:"PLANNER_TIMEOUT" env-default:"220"` // Seconds
+	Timeout     int `yaml:"timeout" env:"PLANNER_TIMEOUT" env-default:"220"`          // Seconds
+	RetryBudget int `yaml:"retry_budget" env:"PLANNER_RETRY_BUDGET" env-default:"10"` // Total provider retries per planning request
+	// Provider calls per planning request, retries included; searches past it are skipped (0 = unlimited)
+	MaxProviderCalls int `yaml:"max_provider_calls" env:"PLANNER_MAX_PROVIDER_CALLS" env-default:"200"`
+	// Stop verifying the remaining plans once this many are valid (0 verifies all)
+	TargetOptions int `yaml:"target_options" env:"PLANNER_TARGET_OPTIONS" env-default:"3"`
+	// Model turn budget for quick (unverified draft) planning
+	QuickMaxTurns int `yaml:"quick_max_turns" env:"PLANNER_QUICK_MAX_TURNS" env-default:"4"`
+	QuickTimeout  int `yaml:"quick_timeout" env:"PLANNER_QUICK_TIMEOUT" env-default:"60"` // Seconds
+	// Byte cap on each tool result handed back to the model (0 uses the default)
+	MaxToolResultBytes int `yaml:"max_tool_result_bytes" env:"PLANNER_MAX_TOOL_RESULT_BYTES" env-default:"16384"`
+	// Seconds a tool run through the registry may take (0 disables), and per-tool overrides by tool name
+	ToolTimeout  int            `yaml:"tool_timeout" env:"PLANNER_TOOL_TIMEOUT" env-default:"60"`
+	ToolTimeouts map[string]int `yaml:"tool_timeouts" env:"PLANNER_TOOL_TIMEOUTS"`
+	// Plans shorter or longer than these are sent back for re-planning (0 disables the check)
+	MinTripHours int `yaml:"min_trip_hours" env:"PLANNER_MIN_TRIP_HOURS" env-default:"2"`
+	MaxTripDays  int `yaml:"max_trip_days" env:"PLANNER_MAX_TRIP_DAYS" env-default:"90"`
+	// Estimated breakfast cost per traveller per night, in the stay's currency, added when comparing
+	// stays without breakfast for a user who wants it (0 compares on price alone)
+	BreakfastValue int `yaml:"breakfast_value" env:"PLANNER_BREAKFAST_VALUE" env-default:"15"`
+	// Directory of prompt template overrides, e.g. trip_planner.tmpl; re-read on reload (empty uses the built-in prompts)
+	PromptDir string `yaml:"prompt_dir" env:"PLANNER_PROMPT_DIR"`
+	// Availability problems that send plans back for re-planning: errors, or warnings too
+	ReplanOn string `yaml:"replan_on" env:"PLANNER_REPLAN_ON" env-default:"errors"`
 }
 
 type DatabaseConfig struct {
@@ -76,23 +271,144 @@ type DatabaseConfig struct {
 	SSLMode  string `yaml:"sslmode" env:"DB_SSLMODE" env-default:"disable"`
 }
 
-// Load reads configuration from config.yaml and environment variables
+// Load reads configuration from the config file and environment variables.
+// Every field can be overridden by the environment variable(s) named in its env tag.
 // Priority: Env Vars > Config File > Defaults
+//
+// The file is CONFIG_PATH if set, otherwise config.yaml. A missing file is not an
+// error (env vars and defaults are used), but a file that fails to parse is.
 func Load() (*Config, error) {
 	var cfg Config
 
-	// 1. Try to load from config.yaml if it exists
-	// We ignore error here because we fallback to env vars,
-	// unless it's a specific parsing error which cleanenv handles well by just populating what it can.
-	// But commonly one might want to enforce file existence if explicit.
-	// Here we just say "read config.yaml if present, then override with envs".
-	err := cleanenv.ReadConfig("config.yaml", &cfg)
-	if err != nil {
-		// If file doesn't exist, just read env vars
+	path := os.Getenv("CONFIG_PATH")
+	if path == "" {
+		path = DefaultPath
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		// ReadConfig parses the file, then applies env overrides and defaults
+		if err := cleanenv.ReadConfig(path, &cfg); err != nil {
+			return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+		}
+	} else if errors.Is(err, os.ErrNotExist) {
 		if err := cleanenv.ReadEnv(&cfg); err != nil {
 			return nil, fmt.Errorf("failed to read env config: %w", err)
 		}
+	} else {
+		return nil, fmt.Errorf("failed to stat config file %s: %w", path, err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
 	}
 
 	return &cfg, nil
 }
+
+// Validate checks that required values are present and numeric settings are sane,
+// so that misconfiguration fails at startup instead of deep inside a request.
+func (c *Config) Validate() error {
+	var problems []string
+	require := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	// Server
+	require(c.Server.Port != "", "server.port (PORT) is required")
+	require(c.Server.AutocompleteRate >= 0, "server.autocomplete_rate (SERVER_AUTOCOMPLETE_RATE) must be >= 0, got %d", c.Server.AutocompleteRate)
+
+	// Log
+	require(c.Log.Tail.Lines >= 0, "log.tail.lines (LOG_TAIL_LINES) must be >= 0, got %d", c.Log.Tail.Lines)
+	require(c.Log.Tail.Requests > 0, "log.tail.requests (LOG_TAIL_REQUESTS) must be > 0, got %d", c.Log.Tail.Requests)
+	require(c.Log.Tail.Retention > 0, "log.tail.retention (LOG_TAIL_RETENTION) must be > 0, got %d", c.Log.Tail.Retention)
+
+	// AI
+	switch c.AI.Plugin {
+	case "gemini":
+		require(c.AI.Gemini.APIKey != "", "ai.gemini.api_key (GEMINI_API_KEY) is required when ai.plugin is gemini")
+		require(c.AI.Gemini.Model != "", "ai.gemini.model (GEMINI_MODEL) is required")
+	case "ollama":
+		require(c.AI.Ollama.Model != "", "ai.ollama.model (OLLAMA_MODEL) is required")
+		require(c.AI.Ollama.BaseURL != "", "ai.ollama.base_url (OLLAMA_BASE_URL) is required")
+	case "zai":
+		require(c.AI.Zai.APIKey != "", "ai.zai.api_key (ZAI_API_KEY) is required when ai.plugin is zai")
+		require(c.AI.Zai.Model != "", "ai.zai.model (ZAI_MODEL) is required")
+	default:
+		problems = append(problems, fmt.Sprintf("ai.plugin (AI_PLUGIN) must be one of gemini, ollama, zai; got %q", c.AI.Plugin))
+	}
+
+	// Planner
+	require(c.Planner.Timeout > 0, "planner.timeout (PLANNER_TIMEOUT) must be > 0, got %d", c.Planner.Timeout)
+	require(c.Planner.RetryBudget >= 0, "planner.retry_budget (PLANNER_RETRY_BUDGET) must be >= 0, got %d", c.Planner.RetryBudget)
+	require(c.Planner.MaxProviderCalls >= 0, "planner.max_provider_calls (PLANNER_MAX_PROVIDER_CALLS) must be >= 0, got %d", c.Planner.MaxProviderCalls)
+	require(c.Planner.TargetOptions >= 0, "planner.target_options (PLANNER_TARGET_OPTIONS) must be >= 0, got %d", c.Planner.TargetOptions)
+	require(c.Planner.QuickMaxTurns > 0, "planner.quick_max_turns (PLANNER_QUICK_MAX_TURNS) must be > 0, got %d", c.Planner.QuickMaxTurns)
+	require(c.Planner.QuickTimeout > 0, "planner.quick_timeout (PLANNER_QUICK_TIMEOUT) must be > 0, got %d", c.Planner.QuickTimeout)
+	require(c.Planner.MaxToolResultBytes >= 0, "planner.max_tool_result_bytes (PLANNER_MAX_TOOL_RESULT_BYTES) must be >= 0, got %d", c.Planner.MaxToolResultBytes)
+	require(c.Planner.ToolTimeout >= 0, "planner.tool_timeout (PLANNER_TOOL_TIMEOUT) must be >= 0, got %d", c.Planner.ToolTimeout)
+	for name, secs := range c.Planner.ToolTimeouts {
+		require(secs >= 0, "planner.tool_timeouts.%s (PLANNER_TOOL_TIMEOUTS) must be >= 0, got %d", name, secs)
+	}
+	require(c.Planner.MinTripHours >= 0, "planner.min_trip_hours (PLANNER_MIN_TRIP_HOURS) must be >= 0, got %d", c.Planner.MinTripHours)
+	require(c.Planner.MaxTripDays >= 0, "planner.max_trip_days (PLANNER_MAX_TRIP_DAYS) must be >= 0, got %d", c.Planner.MaxTripDays)
+	require(c.Planner.ReplanOn == "errors" || c.Planner.ReplanOn == "warnings", "planner.replan_on (PLANNER_REPLAN_ON) must be errors or warnings, got %q", c.Planner.ReplanOn)
+	require(c.Planner.BreakfastValue >= 0, "planner.breakfast_value (PLANNER_BREAKFAST_VALUE) must be >= 0, got %d", c.Planner.BreakfastValue)
+
+	// Amadeus
+	require(c.Amadeus.ClientID != "", "amadeus.client_id (AMADEUS_CLIENT_ID) is required")
+	require(c.Amadeus.ClientSecret != "", "amadeus.client_secret (AMADEUS_CLIENT_SECRET) is required")
+	env := strings.ToLower(c.Amadeus.Environment)
+	require(env == "test" || env == "production", "amadeus.environment (AMADEUS_ENV) must be test or production, got %q", c.Amadeus.Environment)
+	require(c.Amadeus.Limit.Flight > 0, "amadeus.limit.flight (AMADEUS_LIMIT_FLIGHT) must be > 0, got %d", c.Amadeus.Limit.Flight)
+	require(c.Amadeus.Limit.Hotel > 0, "amadeus.limit.hotel (AMADEUS_LIMIT_HOTEL) must be > 0, got %d", c.Amadeus.Limit.Hotel)
+	require(c.Amadeus.Limit.HotelList >= c.Amadeus.Limit.Hotel, "amadeus.limit.hotel_list (AMADEUS_LIMIT_HOTEL_LIST) must be >= amadeus.limit.hotel, got %d", c.Amadeus.Limit.HotelList)
+	require(c.Amadeus.HotelOffers.PerHotel > 0, "amadeus.hotel_offers.per_hotel (AMADEUS_HOTEL_OFFERS_PER_HOTEL) must be > 0, got %d", c.Amadeus.HotelOffers.PerHotel)
+	for _, step := range c.Amadeus.RelaxationSteps() {
+		require(step == "amenities" || step == "rating", "amadeus.hotel_relaxation.steps (AMADEUS_HOTEL_RELAXATION_STEPS) must only contain amenities or rating, got %q", step)
+	}
+	require(c.Amadeus.HotelRelaxation.MinRating >= 0 && c.Amadeus.HotelRelaxation.MinRating <= 5, "amadeus.hotel_relaxation.min_rating (AMADEUS_HOTEL_RELAXATION_MIN_RATING) must be between 0 and 5, got %d", c.Amadeus.HotelRelaxation.MinRating)
+	require(c.Amadeus.HotelFallback.RadiusKm >= 0, "amadeus.hotel_fallback.radius_km (AMADEUS_HOTEL_FALLBACK_RADIUS_KM) must not be negative, got %d", c.Amadeus.HotelFallback.RadiusKm)
+	require(c.Amadeus.Timeout > 0, "amadeus.timeout (AMADEUS_TIMEOUT) must be > 0, got %d", c.Amadeus.Timeout)
+	require(c.Amadeus.CacheTTL.Location > 0, "amadeus.cache_ttl.location (AMADEUS_CACHE_TTL_LOCATION) must be > 0, got %d", c.Amadeus.CacheTTL.Location)
+	require(c.Amadeus.CacheTTL.Flight > 0, "amadeus.cache_ttl.flight (AMADEUS_CACHE_TTL_FLIGHT) must be > 0, got %d", c.Amadeus.CacheTTL.Flight)
+	require(c.Amadeus.CacheTTL.Hotel > 0, "amadeus.cache_ttl.hotel (AMADEUS_CACHE_TTL_HOTEL) must be > 0, got %d", c.Amadeus.CacheTTL.Hotel)
+	require(c.Amadeus.CacheTTL.Calendar > 0, "amadeus.cache_ttl.calendar (AMADEUS_CACHE_TTL_CALENDAR) must be > 0, got %d", c.Amadeus.CacheTTL.Calendar)
+	require(c.Amadeus.CacheMaxEntries >= 0, "amadeus.cache_max_entries (AMADEUS_CACHE_MAX_ENTRIES) must be >= 0, got %d", c.Amadeus.CacheMaxEntries)
+	require(c.Amadeus.Retry.MaxRetries >= 0, "amadeus.retry.max_retries (AMADEUS_RETRY_MAX_RETRIES) must be >= 0, got %d", c.Amadeus.Retry.MaxRetries)
+	require(c.Amadeus.Retry.BaseDelayMs >= 0, "amadeus.retry.base_delay_ms (AMADEUS_RETRY_BASE_DELAY_MS) must be >= 0, got %d", c.Amadeus.Retry.BaseDelayMs)
+
+	// Connections
+	require(c.Connections.SelfTransferBuffer >= 0, "connections.self_transfer_buffer (CONNECTIONS_SELF_TRANSFER_BUFFER) must be >= 0, got %d", c.Connections.SelfTransferBuffer)
+	for code, o := range c.Connections.Overrides {
+		require(o.Domestic >= 0 && o.International >= 0 && o.InterTerminal >= 0, "connections.overrides.%s must not have negative minutes", code)
+	}
+
+	// Bookings
+	require(c.Bookings.PollInterval >= 0, "bookings.poll_interval (BOOKINGS_POLL_INTERVAL) must be >= 0, got %d", c.Bookings.PollInterval)
+	if c.Bookings.Email.SMTPAddr != "" {
+		require(c.Bookings.Email.From != "", "bookings.email.from (BOOKINGS_EMAIL_FROM) is required when bookings.email.smtp_addr is set")
+	}
+
+	// Preflight
+	if c.Preflight.Enabled {
+		require(c.Preflight.Timeout > 0, "preflight.timeout (PREFLIGHT_TIMEOUT) must be > 0, got %d", c.Preflight.Timeout)
+	}
+
+	// Test mode
+	if c.TestMode.Enabled {
+		_, err := c.TestMode.Clock()
+		require(err == nil, "test_mode.now (TEST_MODE_NOW) must be an RFC 3339 time, got %q", c.TestMode.Now)
+	}
+
+	// Tavily is optional, but its timeout must be usable when it is enabled
+	if c.Tavily.APIKey != "" {
+		require(c.Tavily.Timeout > 0, "tavily.timeout (TAVILY_TIMEOUT) must be > 0, got %d", c.Tavily.Timeout)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration (%d problems):\n- %s", len(problems), strings.Join(problems, "\n- "))
+	}
+	return nil
+}
@@ -2,7 +2,9 @@ package config
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -11,30 +13,18 @@ func TestLoad(t *testing.T) {
 	t.Run("DefaultConfig", func(t *testing.T) {
 		// Save original env vars
 		origAIPlugin := os.Getenv("AI_PLUGIN")
-		origGeminiKey := os.Getenv("GEMINI_API_KEY")
-		origAmadeusID := os.Getenv("AMADEUS_CLIENT_ID")
-		origAmadeusSecret := os.Getenv("AMADEUS_CLIENT_SECRET")
 
 		// Clear env vars for this test
 		os.Unsetenv("AI_PLUGIN")
-		os.Unsetenv("GEMINI_API_KEY")
-		os.Unsetenv("AMADEUS_CLIENT_ID")
-		os.Unsetenv("AMADEUS_CLIENT_SECRET")
+
+		// Required credentials have no defaults
+		setRequiredEnv(t)
 
 		defer func() {
 			// Restore original env vars
 			if origAIPlugin != "" {
 				os.Setenv("AI_PLUGIN", origAIPlugin)
 			}
-			if origGeminiKey != "" {
-				os.Setenv("GEMINI_API_KEY", origGeminiKey)
-			}
-			if origAmadeusID != "" {
-				os.Setenv("AMADEUS_CLIENT_ID", origAmadeusID)
-			}
-			if origAmadeusSecret != "" {
-				os.Setenv("AMADEUS_CLIENT_SECRET", origAmadeusSecret)
-			}
 		}()
 
 		cfg, err := Load()
@@ -48,6 +38,8 @@ func TestLoad(t *testing.T) {
 	})
 
 	t.Run("EnvironmentVariables", func(t *testing.T) {
+		setRequiredEnv(t)
+
 		// Save original env vars
 		origAIPlugin := os.Getenv("AI_PLUGIN")
 		origGeminiKey := os.Getenv("GEMINI_API_KEY")
@@ -77,3 +69,139 @@ func TestLoad(t *testing.T) {
 		assert.Equal(t, "test-key", cfg.AI.Gemini.APIKey)
 	})
 }
+
+func TestLoad_Validation(t *testing.T) {
+	t.Run("MissingAmadeusKey", func(t *testing.T) {
+		setRequiredEnv(t)
+		os.Unsetenv("AMADEUS_CLIENT_ID")
+
+		cfg, err := Load()
+		assert.Error(t, err)
+		assert.Nil(t, cfg)
+		assert.Contains(t, err.Error(), "AMADEUS_CLIENT_ID")
+	})
+
+	t.Run("NegativeLimit", func(t *testing.T) {
+		setRequiredEnv(t)
+		t.Setenv("AMADEUS_LIMIT_FLIGHT", "-1")
+
+		cfg, err := Load()
+		assert.Error(t, err)
+		assert.Nil(t, cfg)
+		assert.Contains(t, err.Error(), "AMADEUS_LIMIT_FLIGHT")
+		assert.Contains(t, err.Error(), "got -1")
+	})
+
+	t.Run("TestModeClock", func(t *testing.T) {
+		setRequiredEnv(t)
+		t.Setenv("TEST_MODE_ENABLED", "true")
+		t.Setenv("TEST_MODE_NOW", "next tuesday")
+
+		_, err := Load()
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "TEST_MODE_NOW")
+		}
+
+		t.Setenv("TEST_MODE_NOW", "2030-01-07T09:00:00Z")
+		cfg, err := Load()
+		if assert.NoError(t, err) {
+			now, err := cfg.TestMode.Clock()
+			assert.NoError(t, err)
+			assert.Equal(t, time.Date(2030, 1, 7, 9, 0, 0, 0, time.UTC), now)
+		}
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		setRequiredEnv(t)
+
+		cfg, err := Load()
+		assert.NoError(t, err)
+		assert.NotNil(t, cfg)
+	})
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	content := `
+amadeus:
+  limit:
+    flight: 10
+    hotel: 7
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	t.Run("EnvOverridesFile", func(t *testing.T) {
+		setRequiredEnv(t)
+		t.Setenv("CONFIG_PATH", path)
+		t.Setenv("AMADEUS_FLIGHT_LIMIT", "5")
+
+		cfg, err := Load()
+		assert.NoError(t, err)
+		if assert.NotNil(t, cfg) {
+			assert.Equal(t, 5, cfg.Amadeus.Limit.Flight)
+			assert.Equal(t, 7, cfg.Amadeus.Limit.Hotel, "file value should be kept when no env override")
+			assert.Equal(t, 30, cfg.Amadeus.Timeout, "default should apply when neither file nor env set it")
+		}
+	})
+
+	t.Run("FileOverridesDefault", func(t *testing.T) {
+		setRequiredEnv(t)
+		t.Setenv("CONFIG_PATH", path)
+
+		cfg, err := Load()
+		assert.NoError(t, err)
+		if assert.NotNil(t, cfg) {
+			assert.Equal(t, 10, cfg.Amadeus.Limit.Flight)
+			assert.Equal(t, "8000", cfg.Server.Port)
+		}
+	})
+
+	t.Run("ConnectionOverridesFromEnv", func(t *testing.T) {
+		setRequiredEnv(t)
+		t.Setenv("CONFIG_PATH", path)
+		t.Setenv("CONNECTIONS_OVERRIDES", `{"LHR":{"international":90,"inter_terminal":105},"JFK":{"domestic":45}}`)
+
+		cfg, err := Load()
+		assert.NoError(t, err)
+		if assert.NotNil(t, cfg) {
+			assert.Equal(t, MCTOverrides{
+				"LHR": {International: 90, InterTerminal: 105},
+				"JFK": {Domestic: 45},
+			}, cfg.Connections.Overrides)
+		}
+
+		t.Setenv("CONNECTIONS_OVERRIDES", `{"LHR":`)
+		_, err = Load()
+		assert.Error(t, err)
+	})
+
+	t.Run("InvalidFile", func(t *testing.T) {
+		setRequiredEnv(t)
+		bad := filepath.Join(dir, "bad.yaml")
+		if err := os.WriteFile(bad, []byte("amadeus: [unclosed"), 0o644); err != nil {
+			t.Fatalf("failed to write config file: %v", err)
+		}
+		t.Setenv("CONFIG_PATH", bad)
+
+		cfg, err := Load()
+		assert.Error(t, err)
+		assert.Nil(t, cfg)
+	})
+}
+
+func TestServerConfig_OutboundUserAgent(t *testing.T) {
+	assert.Equal(t, "travelingman/"+Version, ServerConfig{}.OutboundUserAgent())
+	assert.Equal(t, "acme-trips/2.0", ServerConfig{UserAgent: "acme-trips/2.0"}.OutboundUserAgent())
+}
+
+// setRequiredEnv sets the credentials that Validate requires for the default (gemini) plugin.
+// Values are restored when the test finishes.
+func setRequiredEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("GEMINI_API_KEY", "test-gemini-key")
+	t.Setenv("AMADEUS_CLIENT_ID", "test-client-id")
+	t.Setenv("AMADEUS_CLIENT_SECRET", "test-client-secret")
+}
@@ -0,0 +1,84 @@
+package context
+
+import (
+	stdctx "context"
+	"errors"
+	"sync/atomic"
+)
+
+// ErrCallBudgetExhausted is returned instead of making a provider call once the
+// request's call budget is spent
+var ErrCallBudgetExhausted = errors.New("provider call limit for this request reached")
+
+// CallBudget caps the total number of provider calls made on behalf of one request,
+// retries included. A pathological plan (many itineraries, legs and dates) stops
+// searching once it is spent instead of issuing hundreds of calls. It is safe for
+// concurrent use.
+type CallBudget struct {
+	remaining atomic.Int64
+	used      atomic.Int64
+	refused   atomic.Int64
+}
+
+// NewCallBudget creates a budget allowing n calls in total
+func NewCallBudget(n int) *CallBudget {
+	b := &CallBudget{}
+	b.remaining.Store(int64(n))
+	return b
+}
+
+// Take consumes one call, reporting false if the budget is exhausted
+func (b *CallBudget) Take() bool {
+	for {
+		n := b.remaining.Load()
+		if n <= 0 {
+			b.refused.Add(1)
+			return false
+		}
+		if b.remaining.CompareAndSwap(n, n-1) {
+			b.used.Add(1)
+			return true
+		}
+	}
+}
+
+// Used returns the number of calls made so far
+func (b *CallBudget) Used() int {
+	return int(b.used.Load())
+}
+
+// Remaining returns the number of calls left, or -1 for a nil budget, which is unlimited
+func (b *CallBudget) Remaining() int {
+	if b == nil {
+		return -1
+	}
+	return int(max(b.remaining.Load(), 0))
+}
+
+// Exhausted reports whether a call was refused because the budget was spent
+func (b *CallBudget) Exhausted() bool {
+	return b != nil && b.refused.Load() > 0
+}
+
+// WithCallBudget attaches a call budget to the context
+func WithCallBudget(parent stdctx.Context, budget *CallBudget) stdctx.Context {
+	return stdctx.WithValue(parent, CallBudgetKey, budget)
+}
+
+// CallBudgetFromContext extracts the call budget from the context, or nil if there is none
+func CallBudgetFromContext(ctx stdctx.Context) *CallBudget {
+	if budget, ok := ctx.Value(CallBudgetKey).(*CallBudget); ok {
+		return budget
+	}
+	return nil
+}
+
+// TakeCall consumes one call from the context's budget and reports whether the
+// caller may make it. Contexts without a budget are not limited.
+func TakeCall(ctx stdctx.Context) bool {
+	budget := CallBudgetFromContext(ctx)
+	if budget == nil {
+		return true
+	}
+	return budget.Take()
+}
@@ -0,0 +1,40 @@
+package context
+
+import (
+	stdctx "context"
+	"time"
+)
+
+// Clock returns the current time. Tests freeze it so that date handling does not
+// depend on when they run.
+type Clock func() time.Time
+
+// SystemClock is the wall clock
+var SystemClock Clock = time.Now
+
+// FixedClock returns a clock that is always at t
+func FixedClock(t time.Time) Clock {
+	return func() time.Time { return t }
+}
+
+// WithClock attaches a clock to the context
+func WithClock(parent stdctx.Context, clock Clock) stdctx.Context {
+	return stdctx.WithValue(parent, ClockKey, clock)
+}
+
+// ClockFromContext extracts the clock from the context, or nil if there is none
+func ClockFromContext(ctx stdctx.Context) Clock {
+	if clock, ok := ctx.Value(ClockKey).(Clock); ok {
+		return clock
+	}
+	return nil
+}
+
+// Now returns the current time according to the context's clock, or the wall clock
+// if the context has none
+func Now(ctx stdctx.Context) time.Time {
+	if clock := ClockFromContext(ctx); clock != nil {
+		return clock()
+	}
+	return time.Now()
+}
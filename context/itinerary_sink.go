@@ -0,0 +1,25 @@
+package context
+
+import (
+	stdctx "context"
+
+	"github.com/va6996/travelingman/pb"
+)
+
+// ItinerarySink receives each itinerary as soon as it passes verification, before the
+// rest are checked. Streaming requests attach one so the client can show the first
+// options while the others are still being searched.
+type ItinerarySink func(it *pb.Itinerary)
+
+// WithItinerarySink attaches an itinerary sink to the context
+func WithItinerarySink(parent stdctx.Context, sink ItinerarySink) stdctx.Context {
+	return stdctx.WithValue(parent, ItinerarySinkKey, sink)
+}
+
+// ItinerarySinkFromContext extracts the itinerary sink from the context, or nil if there is none
+func ItinerarySinkFromContext(ctx stdctx.Context) ItinerarySink {
+	if sink, ok := ctx.Value(ItinerarySinkKey).(ItinerarySink); ok {
+		return sink
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package context
+
+import (
+	stdctx "context"
+	"sync"
+
+	"github.com/va6996/travelingman/pb"
+)
+
+// LocationMemo remembers location search results for the lifetime of one request,
+// so itineraries verified in parallel resolve each keyword only once. Concurrent
+// lookups of the same keyword wait for the first one instead of searching again.
+// Failed lookups are not remembered. It is safe for concurrent use; a nil memo
+// performs every lookup.
+type LocationMemo struct {
+	mu      sync.Mutex
+	entries map[string]*locationEntry
+}
+
+type locationEntry struct {
+	done      chan struct{}
+	locations []*pb.Location
+	err       error
+}
+
+// NewLocationMemo creates an empty memo
+func NewLocationMemo() *LocationMemo {
+	return &LocationMemo{entries: make(map[string]*locationEntry)}
+}
+
+// Resolve returns the remembered result for keyword, calling lookup if there is none yet
+func (m *LocationMemo) Resolve(ctx stdctx.Context, keyword string, lookup func(stdctx.Context, string) ([]*pb.Location, error)) ([]*pb.Location, error) {
+	if m == nil {
+		return lookup(ctx, keyword)
+	}
+
+	m.mu.Lock()
+	if e, ok := m.entries[keyword]; ok {
+		m.mu.Unlock()
+		select {
+		case <-e.done:
+			return e.locations, e.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	e := &locationEntry{done: make(chan struct{})}
+	m.entries[keyword] = e
+	m.mu.Unlock()
+
+	e.locations, e.err = lookup(ctx, keyword)
+	if e.err != nil {
+		// Let later callers try again
+		m.mu.Lock()
+		delete(m.entries, keyword)
+		m.mu.Unlock()
+	}
+	close(e.done)
+	return e.locations, e.err
+}
+
+// WithLocationMemo attaches a location memo to the context
+func WithLocationMemo(parent stdctx.Context, memo *LocationMemo) stdctx.Context {
+	return stdctx.WithValue(parent, LocationMemoKey, memo)
+}
+
+// LocationMemoFromContext extracts the location memo from the context, or nil if there is none
+func LocationMemoFromContext(ctx stdctx.Context) *LocationMemo {
+	if memo, ok := ctx.Value(LocationMemoKey).(*LocationMemo); ok {
+		return memo
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package context
+
+import (
+	stdctx "context"
+
+	"github.com/va6996/travelingman/pb"
+)
+
+// ProgressSink receives the steps of planning as they happen: planning rounds, tool
+// calls and the verification of each itinerary. Streaming requests attach one so the
+// client can show live progress. It may be called from several goroutines.
+type ProgressSink func(stage pb.PlanStage, detail string)
+
+// Report passes a step to the sink; a nil sink ignores it
+func (s ProgressSink) Report(stage pb.PlanStage, detail string) {
+	if s != nil {
+		s(stage, detail)
+	}
+}
+
+// WithProgressSink attaches a progress sink to the context
+func WithProgressSink(parent stdctx.Context, sink ProgressSink) stdctx.Context {
+	return stdctx.WithValue(parent, ProgressSinkKey, sink)
+}
+
+// ProgressSinkFromContext extracts the progress sink from the context, or nil if there is none
+func ProgressSinkFromContext(ctx stdctx.Context) ProgressSink {
+	if sink, ok := ctx.Value(ProgressSinkKey).(ProgressSink); ok {
+		return sink
+	}
+	return nil
+}
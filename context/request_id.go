@@ -3,6 +3,8 @@ package context
 
 import (
 	stdctx "context"
+	"math/rand"
+	"sync"
 
 	"github.com/google/uuid"
 )
@@ -13,11 +15,65 @@ type contextKey int
 const (
 	// RequestIDKey is the context key for request IDs
 	RequestIDKey contextKey = iota
+	// RetryBudgetKey is the context key for the per-request retry budget
+	RetryBudgetKey
+	// RequestStatsKey is the context key for the per-request activity counters
+	RequestStatsKey
+	// LocationMemoKey is the context key for the per-request location search memo
+	LocationMemoKey
+	// ClockKey is the context key for the clock used to resolve "today"
+	ClockKey
+	// TextSinkKey is the context key for the receiver of streamed planner text
+	TextSinkKey
+	// CallBudgetKey is the context key for the per-request provider call budget
+	CallBudgetKey
+	// ItinerarySinkKey is the context key for the receiver of itineraries as they are verified
+	ItinerarySinkKey
+	// ProgressSinkKey is the context key for the receiver of planning progress
+	ProgressSinkKey
 )
 
+// IDGenerator returns a new unique request ID
+type IDGenerator func() string
+
+var (
+	idMu  sync.RWMutex
+	newID IDGenerator = RandomIDs
+)
+
+// RandomIDs generates random UUIDs; it is the default generator
+func RandomIDs() string {
+	return uuid.New().String()
+}
+
+// SeededIDs returns a generator of UUIDs that yields the same sequence for the same
+// seed, so that test-mode runs are reproducible
+func SeededIDs(seed int64) IDGenerator {
+	var mu sync.Mutex
+	r := rand.New(rand.NewSource(seed))
+	return func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		return uuid.Must(uuid.NewRandomFromReader(r)).String()
+	}
+}
+
+// SetIDGenerator replaces the generator used by NewRequestID; nil restores RandomIDs
+func SetIDGenerator(g IDGenerator) {
+	if g == nil {
+		g = RandomIDs
+	}
+	idMu.Lock()
+	defer idMu.Unlock()
+	newID = g
+}
+
 // NewRequestID generates a new unique request ID
 func NewRequestID() string {
-	return uuid.New().String()
+	idMu.RLock()
+	g := newID
+	idMu.RUnlock()
+	return g()
 }
 
 // WithRequestID adds a request ID to the context
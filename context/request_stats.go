@@ -0,0 +1,200 @@
+package context
+
+import (
+	stdctx "context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RequestStats accumulates what happened while serving one request, so a single
+// summary line can be logged at the end instead of reconstructing it from
+// hundreds of interleaved lines. It is safe for concurrent use, and all methods
+// are no-ops on a nil receiver so callers do not need to check for a context
+// without stats.
+type RequestStats struct {
+	mu sync.Mutex
+
+	start         time.Time
+	outcome       string
+	iterations    int
+	plannerSteps  int
+	tools         map[string]int
+	requests      map[string]int
+	requestErrors int
+	cacheHits     map[string]int
+	cacheLookups  map[string]int
+	warnings      map[string]int
+	prompts       map[string]string
+}
+
+// NewRequestStats creates empty stats starting now
+func NewRequestStats() *RequestStats {
+	return &RequestStats{
+		start:        time.Now(),
+		tools:        make(map[string]int),
+		requests:     make(map[string]int),
+		cacheHits:    make(map[string]int),
+		cacheLookups: make(map[string]int),
+		warnings:     make(map[string]int),
+		prompts:      make(map[string]string),
+	}
+}
+
+// WithRequestStats attaches request stats to the context
+func WithRequestStats(parent stdctx.Context, stats *RequestStats) stdctx.Context {
+	return stdctx.WithValue(parent, RequestStatsKey, stats)
+}
+
+// RequestStatsFromContext extracts the request stats from the context, or nil if there are none
+func RequestStatsFromContext(ctx stdctx.Context) *RequestStats {
+	if stats, ok := ctx.Value(RequestStatsKey).(*RequestStats); ok {
+		return stats
+	}
+	return nil
+}
+
+// AddIteration counts one plan/verify round of the orchestration
+func (s *RequestStats) AddIteration() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.iterations++
+}
+
+// AddPlannerSteps counts model turns taken by the planner
+func (s *RequestStats) AddPlannerSteps(n int) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.plannerSteps += n
+}
+
+// AddToolCall counts one call of the named tool
+func (s *RequestStats) AddToolCall(name string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tools[name]++
+}
+
+// AddProviderRequest counts one request to an external provider endpoint
+// (e.g. "amadeus/v2/shopping/flight-offers"), and whether it failed
+func (s *RequestStats) AddProviderRequest(endpoint string, failed bool) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests[endpoint]++
+	if failed {
+		s.requestErrors++
+	}
+}
+
+// AddCacheLookup counts one lookup in the named cache and whether it hit
+func (s *RequestStats) AddCacheLookup(cache string, hit bool) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cacheLookups[cache]++
+	if hit {
+		s.cacheHits[cache]++
+	}
+}
+
+// AddProviderWarning counts one warning returned by a provider with an otherwise
+// successful response, keyed by provider and code (e.g. "amadeus/4926")
+func (s *RequestStats) AddProviderWarning(code string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.warnings[code]++
+}
+
+// SetPrompt records the version of the named prompt template the request was planned with
+func (s *RequestStats) SetPrompt(name, version string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prompts[name] = version
+}
+
+// SetOutcome records how the request ended when it cannot be told from its result
+func (s *RequestStats) SetOutcome(outcome string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.outcome = outcome
+}
+
+// Outcome returns the outcome set with SetOutcome, or "" if none was set
+func (s *RequestStats) Outcome() string {
+	if s == nil {
+		return ""
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.outcome
+}
+
+// String formats the counters as space-separated key=value pairs, e.g.
+// iterations=1 planner_steps=3 tools=dateTool:2 requests=amadeus/v2/shopping/flight-offers:2 request_errors=0 warnings=amadeus/4926:1 cache=flight:1/2 prompts=trip_planner:default@1a2b3c4d duration=3.2s
+func (s *RequestStats) String() string {
+	if s == nil {
+		return ""
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cache := make(map[string]string, len(s.cacheLookups))
+	for name, lookups := range s.cacheLookups {
+		cache[name] = fmt.Sprintf("%d/%d", s.cacheHits[name], lookups)
+	}
+
+	return fmt.Sprintf("iterations=%d planner_steps=%d tools=%s requests=%s request_errors=%d warnings=%s cache=%s prompts=%s duration=%v",
+		s.iterations, s.plannerSteps, joinCounts(s.tools), joinCounts(s.requests), s.requestErrors,
+		joinCounts(s.warnings), joinPairs(cache), joinPairs(s.prompts), time.Since(s.start).Round(time.Millisecond))
+}
+
+// joinCounts formats a counter map as "a:1,b:2" sorted by key, or "none"
+func joinCounts(m map[string]int) string {
+	pairs := make(map[string]string, len(m))
+	for k, v := range m {
+		pairs[k] = fmt.Sprint(v)
+	}
+	return joinPairs(pairs)
+}
+
+func joinPairs(m map[string]string) string {
+	if len(m) == 0 {
+		return "none"
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + ":" + m[k]
+	}
+	return strings.Join(parts, ",")
+}
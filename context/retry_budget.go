@@ -0,0 +1,70 @@
+package context
+
+import (
+	stdctx "context"
+	"sync/atomic"
+)
+
+// RetryBudget caps the total number of retries made on behalf of one request.
+// It is shared by every provider call made while serving the request, so the
+// verification fan-out cannot multiply per-call retries into a retry storm.
+// It is safe for concurrent use.
+type RetryBudget struct {
+	remaining atomic.Int64
+	used      atomic.Int64
+}
+
+// NewRetryBudget creates a budget allowing n retries in total
+func NewRetryBudget(n int) *RetryBudget {
+	b := &RetryBudget{}
+	b.remaining.Store(int64(n))
+	return b
+}
+
+// Take consumes one retry, reporting false if the budget is exhausted
+func (b *RetryBudget) Take() bool {
+	for {
+		n := b.remaining.Load()
+		if n <= 0 {
+			return false
+		}
+		if b.remaining.CompareAndSwap(n, n-1) {
+			b.used.Add(1)
+			return true
+		}
+	}
+}
+
+// Remaining returns the number of retries left
+func (b *RetryBudget) Remaining() int {
+	return int(b.remaining.Load())
+}
+
+// Used returns the number of retries taken so far
+func (b *RetryBudget) Used() int {
+	return int(b.used.Load())
+}
+
+// WithRetryBudget attaches a retry budget to the context
+func WithRetryBudget(parent stdctx.Context, budget *RetryBudget) stdctx.Context {
+	return stdctx.WithValue(parent, RetryBudgetKey, budget)
+}
+
+// RetryBudgetFromContext extracts the retry budget from the context, or nil if there is none
+func RetryBudgetFromContext(ctx stdctx.Context) *RetryBudget {
+	if budget, ok := ctx.Value(RetryBudgetKey).(*RetryBudget); ok {
+		return budget
+	}
+	return nil
+}
+
+// TakeRetry consumes one retry from the context's budget and reports whether
+// the caller may retry. Contexts without a budget (startup checks, background
+// jobs) are not limited beyond the caller's own attempt cap.
+func TakeRetry(ctx stdctx.Context) bool {
+	budget := RetryBudgetFromContext(ctx)
+	if budget == nil {
+		return true
+	}
+	return budget.Take()
+}
@@ -0,0 +1,23 @@
+package context
+
+import (
+	stdctx "context"
+)
+
+// TextSink receives model text as it is generated, one chunk at a time and in order.
+// Streaming requests attach one so the client sees the planner thinking while the
+// searches run.
+type TextSink func(chunk string)
+
+// WithTextSink attaches a text sink to the context
+func WithTextSink(parent stdctx.Context, sink TextSink) stdctx.Context {
+	return stdctx.WithValue(parent, TextSinkKey, sink)
+}
+
+// TextSinkFromContext extracts the text sink from the context, or nil if there is none
+func TextSinkFromContext(ctx stdctx.Context) TextSink {
+	if sink, ok := ctx.Value(TextSinkKey).(TextSink); ok {
+		return sink
+	}
+	return nil
+}
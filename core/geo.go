@@ -0,0 +1,44 @@
+package core
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/va6996/travelingman/pb"
+)
+
+const earthRadiusKm = 6371.0
+
+// NearbyCity is a city found near some location and how far away it is
+type NearbyCity struct {
+	Location   *pb.Location
+	DistanceKm float64
+}
+
+// ParseGeocode parses a "lat,lng" geocode as set by the location and hotel searches
+func ParseGeocode(s string) (lat, lng float64, ok bool) {
+	latStr, lngStr, found := strings.Cut(s, ",")
+	if !found {
+		return 0, 0, false
+	}
+	lat, err := strconv.ParseFloat(strings.TrimSpace(latStr), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return 0, 0, false
+	}
+	lng, err = strconv.ParseFloat(strings.TrimSpace(lngStr), 64)
+	if err != nil || lng < -180 || lng > 180 {
+		return 0, 0, false
+	}
+	return lat, lng, true
+}
+
+// DistanceKm returns the great-circle distance between two points in kilometres
+func DistanceKm(lat1, lng1, lat2, lng2 float64) float64 {
+	rad := math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLng := (lng2 - lng1) * rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
@@ -0,0 +1,25 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGeocode(t *testing.T) {
+	lat, lng, ok := ParseGeocode("48.856600, 2.352200")
+	assert.True(t, ok)
+	assert.Equal(t, 48.8566, lat)
+	assert.Equal(t, 2.3522, lng)
+
+	for _, s := range []string{"", "48.8566", "north,2.35", "91,0", "0,181"} {
+		_, _, ok := ParseGeocode(s)
+		assert.False(t, ok, s)
+	}
+}
+
+func TestDistanceKm(t *testing.T) {
+	// Paris to London
+	assert.InDelta(t, 344, DistanceKm(48.8566, 2.3522, 51.5074, -0.1278), 1)
+	assert.Zero(t, DistanceKm(48.8566, 2.3522, 48.8566, 2.3522))
+}
@@ -64,13 +64,13 @@ func ValidateNodes(g *pb.Graph) error {
 	}
 	nodeIDs := make(map[string]bool)
 	for _, n := range g.Nodes {
-		if n.Id == "" {
+		if n.GetId() == "" {
 			return fmt.Errorf("found node with missing ID")
 		}
-		if nodeIDs[n.Id] {
-			return fmt.Errorf("duplicate Node ID found: %s", n.Id)
+		if nodeIDs[n.GetId()] {
+			return fmt.Errorf("duplicate Node ID found: %s", n.GetId())
 		}
-		nodeIDs[n.Id] = true
+		nodeIDs[n.GetId()] = true
 	}
 	return nil
 }
@@ -89,26 +89,26 @@ func ValidateGraph(g *pb.Graph) error {
 	// Create node ID map for edge validation
 	nodeIDs := make(map[string]bool)
 	for _, n := range g.Nodes {
-		nodeIDs[n.Id] = true
+		nodeIDs[n.GetId()] = true
 	}
 
 	// Validate edges and collect all errors
 	var errors []string
 	for i, edge := range g.Edges {
-		if edge.FromId == "" {
+		if edge.GetFromId() == "" {
 			errors = append(errors, fmt.Sprintf("edge %d: FromId is empty", i))
 		}
-		if edge.ToId == "" {
+		if edge.GetToId() == "" {
 			errors = append(errors, fmt.Sprintf("edge %d: ToId is empty", i))
 		}
-		if !nodeIDs[edge.FromId] {
-			errors = append(errors, fmt.Sprintf("edge %d: FromId '%s' not found in nodes", i, edge.FromId))
+		if !nodeIDs[edge.GetFromId()] {
+			errors = append(errors, fmt.Sprintf("edge %d: FromId '%s' not found in nodes", i, edge.GetFromId()))
 		}
-		if !nodeIDs[edge.ToId] {
-			errors = append(errors, fmt.Sprintf("edge %d: ToId '%s' not found in nodes", i, edge.ToId))
+		if !nodeIDs[edge.GetToId()] {
+			errors = append(errors, fmt.Sprintf("edge %d: ToId '%s' not found in nodes", i, edge.GetToId()))
 		}
-		if edge.DurationSeconds < 0 {
-			errors = append(errors, fmt.Sprintf("edge %d: negative duration %d", i, edge.DurationSeconds))
+		if edge.GetDurationSeconds() < 0 {
+			errors = append(errors, fmt.Sprintf("edge %d: negative duration %d", i, edge.GetDurationSeconds()))
 		}
 	}
 
@@ -128,7 +128,7 @@ func HasCycle(g *pb.Graph) bool {
 	// Adjacency list
 	adj := make(map[string][]string)
 	for _, e := range g.Edges {
-		adj[e.FromId] = append(adj[e.FromId], e.ToId)
+		adj[e.GetFromId()] = append(adj[e.GetFromId()], e.GetToId())
 	}
 
 	visited := make(map[string]bool)
@@ -156,11 +156,11 @@ func HasCycle(g *pb.Graph) bool {
 	// Check all starting points (nodes and any IDs used in edges)
 	allIDs := make(map[string]bool)
 	for _, n := range g.Nodes {
-		allIDs[n.Id] = true
+		allIDs[n.GetId()] = true
 	}
 	for _, e := range g.Edges {
-		allIDs[e.FromId] = true
-		allIDs[e.ToId] = true
+		allIDs[e.GetFromId()] = true
+		allIDs[e.GetToId()] = true
 	}
 
 	for id := range allIDs {
@@ -0,0 +1,27 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// MarshalJSON renders a proto message, such as an itinerary, as indented JSON in its
+// canonical form: timestamps as RFC 3339 strings, enums by name and fields by their
+// JSON names. encoding/json gets all three wrong for generated types.
+//
+// protojson varies its spacing between builds, so the output is re-indented; the same
+// message always gives the same bytes.
+func MarshalJSON(m proto.Message) ([]byte, error) {
+	raw, err := protojson.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	if err := json.Indent(&b, raw, "", "  "); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
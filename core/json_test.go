@@ -0,0 +1,28 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	it := &pb.Itinerary{
+		Title:       "Paris",
+		StartTime:   timestamppb.New(time.Date(2026, 5, 15, 9, 30, 0, 0, time.UTC)),
+		JourneyType: pb.JourneyType_JOURNEY_TYPE_RETURN,
+	}
+
+	b, err := MarshalJSON(it)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, `{
+  "startTime": "2026-05-15T09:30:00Z",
+  "title": "Paris",
+  "journeyType": "JOURNEY_TYPE_RETURN"
+}`, string(b))
+}
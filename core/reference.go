@@ -0,0 +1,98 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ReferenceQuery is the request the reference itinerary answers
+const ReferenceQuery = "A long weekend in Paris from New York for two, with a hotel near the Louvre"
+
+// ReferenceItinerary is a worked example of a planner itinerary: a return trip with
+// a flight each way and a stay at the destination, using only planner fields
+func ReferenceItinerary() *pb.Itinerary {
+	at := func(day, hour int) *timestamppb.Timestamp {
+		return timestamppb.New(time.Date(2026, 5, day, hour, 0, 0, 0, time.UTC))
+	}
+	newYork := func() *pb.Location {
+		return &pb.Location{City: "New York", Country: "US", CityCode: "NYC", IataCodes: []string{"JFK"}}
+	}
+	paris := func() *pb.Location {
+		return &pb.Location{City: "Paris", Country: "FR", CityCode: "PAR", IataCodes: []string{"CDG"}}
+	}
+	flight := func(from, to func() *pb.Location, departs *timestamppb.Timestamp) *pb.Transport {
+		return &pb.Transport{
+			Type:                pb.TransportType_TRANSPORT_TYPE_FLIGHT,
+			TravelerCount:       2,
+			OriginLocation:      from(),
+			DestinationLocation: to(),
+			Cost:                &pb.Cost{Currency: "USD"},
+			FlightPreferences:   &pb.FlightPreferences{TravelClass: pb.Class_CLASS_ECONOMY},
+			Details:             &pb.Transport_Flight{Flight: &pb.Flight{DepartureTime: departs}},
+		}
+	}
+
+	return &pb.Itinerary{
+		Title:       "Long weekend in Paris",
+		Description: "Fly from New York to Paris on Friday evening and back on Monday",
+		StartTime:   at(15, 18),
+		EndTime:     at(18, 20),
+		Travelers:   2,
+		JourneyType: pb.JourneyType_JOURNEY_TYPE_RETURN,
+		Graph: &pb.Graph{
+			Nodes: []*pb.Node{
+				{Id: "start_loc", Location: newYork(), FromTimestamp: at(15, 18)},
+				{
+					Id:            "paris",
+					Location:      paris(),
+					FromTimestamp: at(16, 8),
+					ToTimestamp:   at(18, 11),
+					Stay: &pb.Accommodation{
+						CheckIn:       at(16, 14),
+						CheckOut:      at(18, 11),
+						TravelerCount: 2,
+						Location:      &pb.Location{City: "Paris", Country: "FR", CityCode: "PAR", Area: "Louvre"},
+						Cost:          &pb.Cost{Currency: "USD"},
+					},
+				},
+			},
+			Edges: []*pb.Edge{
+				{FromId: "start_loc", ToId: "paris", Transport: flight(newYork, paris, at(15, 18))},
+				// A return trip ends back at the starting node rather than a copy of it
+				{FromId: "paris", ToId: "start_loc", Transport: flight(paris, newYork, at(18, 13))},
+			},
+		},
+	}
+}
+
+// ReferenceAnswer is the planner's final answer for ReferenceQuery, as indented JSON
+func ReferenceAnswer() string {
+	itinerary, err := protojson.Marshal(ReferenceItinerary())
+	if err != nil {
+		// Built from static values; this cannot fail at runtime
+		panic(fmt.Sprintf("failed to marshal reference itinerary: %v", err))
+	}
+	answer, err := json.Marshal(struct {
+		Itineraries []json.RawMessage `json:"itineraries"`
+		Reasoning   string            `json:"reasoning"`
+	}{
+		Itineraries: []json.RawMessage{itinerary},
+		Reasoning:   "A long weekend is Friday to Monday; dateTool gave the next one",
+	})
+	if err != nil {
+		panic(fmt.Sprintf("failed to marshal reference answer: %v", err))
+	}
+
+	// protojson varies its spacing between builds, so indent it ourselves
+	var b bytes.Buffer
+	if err := json.Indent(&b, answer, "", "  "); err != nil {
+		panic(fmt.Sprintf("failed to indent reference answer: %v", err))
+	}
+	return b.String()
+}
@@ -0,0 +1,31 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestReferenceAnswer(t *testing.T) {
+	answer := ReferenceAnswer()
+
+	// The example shown to the planner must follow the schema it is asked to follow
+	assert.Empty(t, PlannerResponseSchema().Validate([]byte(answer)))
+
+	var parsed struct {
+		Itineraries []json.RawMessage `json:"itineraries"`
+	}
+	if err := json.Unmarshal([]byte(answer), &parsed); err != nil || len(parsed.Itineraries) != 1 {
+		t.Fatalf("Failed to parse reference answer: %v", err)
+	}
+	it := &pb.Itinerary{}
+	assert.NoError(t, protojson.Unmarshal(parsed.Itineraries[0], it))
+	assert.True(t, proto.Equal(ReferenceItinerary(), it), "reference answer does not round-trip")
+
+	assert.NoError(t, ValidateGraph(it.Graph))
+	assert.True(t, HasCycle(it.Graph))
+}
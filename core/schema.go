@@ -0,0 +1,406 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/va6996/travelingman/pb"
+	"github.com/va6996/travelingman/protos"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// PlannerFields lists, per message, the fields the planner may fill in.
+// Everything else (ids, booking state, errors, options, tags, stats) is set by the server
+// and left out of the planner's schema. Car rentals are left out, as no provider can
+// check them.
+var PlannerFields = map[protoreflect.FullName][]protoreflect.Name{
+	"travelingman.Itinerary":                {"title", "description", "start_time", "end_time", "travelers", "journey_type", "graph", "budget"},
+	"travelingman.Graph":                    {"nodes", "edges"},
+	"travelingman.Node":                     {"id", "location", "from_timestamp", "to_timestamp", "stay", "sub_graph", "notes"},
+	"travelingman.Edge":                     {"from_id", "to_id", "duration_seconds", "transport"},
+	"travelingman.Location":                 {"area", "city", "country", "iata_codes", "city_code", "name", "address"},
+	"travelingman.Accommodation":            {"name", "check_in", "check_out", "cost", "preferences", "traveler_count", "child_ages", "location"},
+	"travelingman.AccommodationPreferences": {"room_type", "area", "rating", "amenities", "breakfast", "strict_location", "near_transit"},
+	"travelingman.Transport":                {"type", "traveler_count", "origin_location", "destination_location", "cost", "flight_preferences", "train_preferences", "flight", "train"},
+	"travelingman.FlightPreferences":        {"travel_class", "max_stops", "preferred_origin_airports", "preferred_destination_airports", "baggage"},
+	"travelingman.TrainPreferences":         {"travel_class", "seat_type"},
+	"travelingman.BaggagePreferences":       {"checked_bags", "carryon_bags"},
+	"travelingman.Flight":                   {"carrier_code", "flight_number", "departure_time", "arrival_time"},
+	"travelingman.Train":                    {"departure_time", "arrival_time", "train_number"},
+	"travelingman.Cost":                     {"value", "currency"},
+}
+
+// Schema is the subset of JSON Schema used to describe planner output
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// PlannerResponseSchema returns the schema of the planner's final answer:
+// {"itineraries": [Itinerary...], "reasoning": "...", "exploratory": bool}, with Itinerary generated
+// from the pb descriptors restricted to PlannerFields.
+func PlannerResponseSchema() *Schema {
+	defs := map[string]*Schema{}
+	comments := protoComments()
+	itinerary := messageSchema((&pb.Itinerary{}).ProtoReflect().Descriptor(), defs, comments)
+
+	closed := false
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"itineraries": {Type: "array", Description: "One or more complete trip plans", Items: itinerary},
+			"reasoning":   {Type: "string", Description: "How the dates and plan were worked out"},
+			"exploratory": {Type: "boolean", Description: "The user is flexible on the destination: each itinerary is one candidate destination, to be priced and ranked"},
+		},
+		Required:             []string{"itineraries"},
+		AdditionalProperties: &closed,
+		Defs:                 defs,
+	}
+}
+
+// messageSchema registers md (and the messages it references) in defs and returns a $ref to it
+func messageSchema(md protoreflect.MessageDescriptor, defs map[string]*Schema, comments map[string]string) *Schema {
+	name := string(md.Name())
+	ref := &Schema{Ref: "#/$defs/" + name}
+	if _, ok := defs[name]; ok {
+		return ref
+	}
+
+	closed := false
+	s := &Schema{
+		Type:                 "object",
+		Description:          comments[string(md.FullName())],
+		Properties:           map[string]*Schema{},
+		AdditionalProperties: &closed,
+	}
+	// Register before recursing so self-references (Node.sub_graph -> Graph) terminate
+	defs[name] = s
+
+	for _, fieldName := range PlannerFields[md.FullName()] {
+		fd := md.Fields().ByName(fieldName)
+		if fd == nil {
+			continue
+		}
+		prop := fieldSchema(fd, defs, comments)
+		if oneof := fd.ContainingOneof(); oneof != nil && !oneof.IsSynthetic() {
+			var names []string
+			for i := 0; i < oneof.Fields().Len(); i++ {
+				names = append(names, oneof.Fields().Get(i).JSONName())
+			}
+			prop.Description = strings.TrimSpace(prop.Description + " (set only one of: " + strings.Join(names, ", ") + ")")
+		}
+		s.Properties[fd.JSONName()] = prop
+	}
+	return ref
+}
+
+func fieldSchema(fd protoreflect.FieldDescriptor, defs map[string]*Schema, comments map[string]string) *Schema {
+	var s *Schema
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		s = &Schema{Type: "boolean"}
+	case protoreflect.StringKind:
+		s = &Schema{Type: "string"}
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		s = &Schema{Type: "number"}
+	case protoreflect.EnumKind:
+		s = &Schema{Type: "string"}
+		values := fd.Enum().Values()
+		for i := 0; i < values.Len(); i++ {
+			if v := values.Get(i); v.Number() != 0 {
+				s.Enum = append(s.Enum, string(v.Name()))
+			}
+		}
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		if fd.Message().FullName() == "google.protobuf.Timestamp" {
+			s = &Schema{Type: "string", Format: "date-time"}
+		} else {
+			s = messageSchema(fd.Message(), defs, comments)
+		}
+	case protoreflect.BytesKind:
+		s = &Schema{Type: "string"}
+	default:
+		// All remaining kinds are integers
+		s = &Schema{Type: "integer"}
+	}
+
+	desc := comments[string(fd.FullName())]
+	if fd.IsList() {
+		return &Schema{Type: "array", Description: desc, Items: s}
+	}
+	s.Description = desc
+	return s
+}
+
+var (
+	protoPackagePattern = regexp.MustCompile(`^package\s+([\w.]+)\s*;`)
+	protoBlockPattern   = regexp.MustCompile(`^(message|enum|oneof|service)\s+(\w+)\s*\{`)
+	protoFieldPattern   = regexp.MustCompile(`^(?:repeated\s+|optional\s+)?[\w.]+\s+(\w+)\s*=\s*\d+\s*;\s*(?://\s*(.*))?$`)
+)
+
+// protoComments maps "pkg.Message" and "pkg.Message.field" to the comment written
+// next to (or right above) it in the embedded .proto sources.
+func protoComments() map[string]string {
+	comments := map[string]string{}
+	files, _ := fs.Glob(protos.Files, "*.proto")
+	for _, file := range files {
+		data, err := protos.Files.ReadFile(file)
+		if err != nil {
+			continue
+		}
+
+		type block struct {
+			kind string // message, enum, oneof or service
+			name string
+		}
+		var (
+			pkg     string
+			stack   []block
+			pending []string
+		)
+		messagePath := func() string {
+			var parts []string
+			for _, b := range stack {
+				if b.kind == "message" {
+					parts = append(parts, b.name)
+				}
+			}
+			return pkg + "." + strings.Join(parts, ".")
+		}
+
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			switch {
+			case line == "":
+				pending = nil
+			case strings.HasPrefix(line, "//"):
+				pending = append(pending, strings.TrimSpace(strings.TrimPrefix(line, "//")))
+			case protoPackagePattern.MatchString(line):
+				pkg = protoPackagePattern.FindStringSubmatch(line)[1]
+				pending = nil
+			case protoBlockPattern.MatchString(line):
+				m := protoBlockPattern.FindStringSubmatch(line)
+				stack = append(stack, block{kind: m[1], name: m[2]})
+				if m[1] == "message" && len(pending) > 0 {
+					comments[messagePath()] = strings.Join(pending, " ")
+				}
+				pending = nil
+			case strings.HasPrefix(line, "}"):
+				if len(stack) > 0 {
+					stack = stack[:len(stack)-1]
+				}
+				pending = nil
+			default:
+				inFields := len(stack) > 0 && (stack[len(stack)-1].kind == "message" || stack[len(stack)-1].kind == "oneof")
+				if m := protoFieldPattern.FindStringSubmatch(line); m != nil && inFields {
+					text := strings.TrimSpace(m[2])
+					if text == "" {
+						text = strings.Join(pending, " ")
+					}
+					if text != "" {
+						comments[messagePath()+"."+m[1]] = text
+					}
+				}
+				pending = nil
+			}
+		}
+	}
+	return comments
+}
+
+// SchemaViolation is a mismatch between a JSON document and a Schema
+type SchemaViolation struct {
+	Path    string
+	Message string
+}
+
+func (v SchemaViolation) String() string {
+	return fmt.Sprintf("%s: %s", v.Path, v.Message)
+}
+
+// Validate checks doc against s and returns every violation found, with JSON paths
+// such as "itineraries[0].graph.edges[1].transport.flightPreferences.class".
+// Keys may use either the camelCase or the original proto field name, as protojson accepts both.
+func (s *Schema) Validate(doc []byte) []SchemaViolation {
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return []SchemaViolation{{Path: "$", Message: fmt.Sprintf("invalid JSON: %v", err)}}
+	}
+	var out []SchemaViolation
+	s.validate(v, "", s.Defs, &out)
+	return out
+}
+
+func (s *Schema) validate(v interface{}, path string, defs map[string]*Schema, out *[]SchemaViolation) {
+	if s.Ref != "" {
+		def, ok := defs[strings.TrimPrefix(s.Ref, "#/$defs/")]
+		if !ok {
+			return
+		}
+		s = def
+	}
+	at := path
+	if at == "" {
+		at = "$"
+	}
+	fail := func(format string, args ...interface{}) {
+		*out = append(*out, SchemaViolation{Path: at, Message: fmt.Sprintf(format, args...)})
+	}
+	if v == nil {
+		// protojson treats null as unset
+		return
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			fail("expected an object, got %s", jsonType(v))
+			return
+		}
+		for _, req := range s.Required {
+			if _, ok := obj[req]; !ok {
+				fail("missing required field %q", req)
+			}
+		}
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			prop, ok := s.Properties[k]
+			if !ok {
+				prop, ok = s.Properties[jsonCamelCase(k)]
+			}
+			child := k
+			if path != "" {
+				child = path + "." + k
+			}
+			if !ok {
+				if s.AdditionalProperties == nil || *s.AdditionalProperties {
+					continue
+				}
+				*out = append(*out, SchemaViolation{Path: child, Message: fmt.Sprintf("unknown field; expected one of: %s", strings.Join(sortedKeys(s.Properties), ", "))})
+				continue
+			}
+			prop.validate(obj[k], child, defs, out)
+		}
+	case "array":
+		arr, ok := v.([]interface{})
+		if !ok {
+			fail("expected an array, got %s", jsonType(v))
+			return
+		}
+		for i, item := range arr {
+			s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i), defs, out)
+		}
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			fail("expected a string, got %s", jsonType(v))
+			return
+		}
+		if len(s.Enum) > 0 && !contains(s.Enum, str) {
+			fail("invalid value %q; expected one of: %s", str, strings.Join(s.Enum, ", "))
+		}
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, str); err != nil {
+				fail("invalid timestamp %q; expected RFC 3339, e.g. 2026-01-25T10:00:00Z", str)
+			}
+		}
+	case "integer":
+		switch n := v.(type) {
+		case json.Number:
+			if _, err := n.Int64(); err != nil {
+				fail("expected an integer, got %s", n)
+			}
+		case string:
+			// protojson accepts 64-bit integers as strings
+			if _, err := strconv.ParseInt(n, 10, 64); err != nil {
+				fail("expected an integer, got %q", n)
+			}
+		default:
+			fail("expected an integer, got %s", jsonType(v))
+		}
+	case "number":
+		if _, ok := v.(json.Number); !ok {
+			fail("expected a number, got %s", jsonType(v))
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			fail("expected a boolean, got %s", jsonType(v))
+		}
+	}
+}
+
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "an array"
+	case string:
+		return "a string"
+	case json.Number:
+		return "a number"
+	case bool:
+		return "a boolean"
+	}
+	return "null"
+}
+
+// jsonCamelCase converts a proto field name (sub_graph) to its JSON name (subGraph)
+func jsonCamelCase(s string) string {
+	var b strings.Builder
+	upper := false
+	for _, r := range s {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper && r >= 'a' && r <= 'z' {
+			r -= 'a' - 'A'
+		}
+		upper = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func sortedKeys(m map[string]*Schema) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+package core
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// resolve walks a dotted path of property names through the schema, following $refs
+func resolve(t *testing.T, root *Schema, path string) *Schema {
+	s := root
+	for _, part := range strings.Split(path, ".") {
+		for s.Ref != "" || s.Type == "array" {
+			if s.Ref != "" {
+				s = root.Defs[strings.TrimPrefix(s.Ref, "#/$defs/")]
+			} else {
+				s = s.Items
+			}
+		}
+		next, ok := s.Properties[part]
+		if !ok {
+			t.Errorf("schema has no %q (looking up %s)", part, path)
+			return &Schema{}
+		}
+		s = next
+	}
+	return s
+}
+
+func TestPlannerResponseSchema_CoversConvertedFields(t *testing.T) {
+	schema := PlannerResponseSchema()
+
+	// Fields read after protojson conversion by the validator, TravelDesk and scoring
+	for _, path := range []string{
+		"itineraries.title",
+		"itineraries.startTime",
+		"itineraries.endTime",
+		"itineraries.travelers",
+		"itineraries.journeyType",
+		"itineraries.budget.value",
+		"itineraries.graph.nodes.id",
+		"itineraries.graph.nodes.location.iataCodes",
+		"itineraries.graph.nodes.location.cityCode",
+		"itineraries.graph.nodes.stay.checkIn",
+		"itineraries.graph.nodes.stay.checkOut",
+		"itineraries.graph.nodes.stay.travelerCount",
+		"itineraries.graph.nodes.stay.preferences.rating",
+		"itineraries.graph.nodes.stay.preferences.breakfast",
+		"itineraries.graph.nodes.stay.preferences.strictLocation",
+		"itineraries.graph.nodes.subGraph.nodes.id",
+		"itineraries.graph.edges.fromId",
+		"itineraries.graph.edges.toId",
+		"itineraries.graph.edges.transport.type",
+		"itineraries.graph.edges.transport.travelerCount",
+		"itineraries.graph.edges.transport.originLocation.iataCodes",
+		"itineraries.graph.edges.transport.flightPreferences.travelClass",
+		"itineraries.graph.edges.transport.flight.departureTime",
+		"reasoning",
+		"exploratory",
+	} {
+		resolve(t, schema, path)
+	}
+
+	// Server-only fields are not offered to the planner
+	itinerary := schema.Defs["Itinerary"]
+	for _, field := range []string{"id", "version", "stats", "error", "tags", "budgetSplit"} {
+		assert.NotContains(t, itinerary.Properties, field)
+	}
+	assert.NotContains(t, schema.Defs["Edge"].Properties, "transportOptions")
+	assert.NotContains(t, schema.Defs["FlightPreferences"].Properties, "maxPrice")
+
+	// Car rentals cannot be checked without a provider, so they are not offered either
+	assert.NotContains(t, schema.Defs["Transport"].Properties, "carRental")
+	assert.NotContains(t, schema.Defs, "CarRentalPreferences")
+
+	// Enums and descriptions come from the proto definitions
+	assert.Contains(t, resolve(t, schema, "itineraries.journeyType").Enum, "JOURNEY_TYPE_RETURN")
+	assert.NotContains(t, resolve(t, schema, "itineraries.journeyType").Enum, "JOURNEY_TYPE_UNSPECIFIED")
+	assert.Equal(t, "date-time", resolve(t, schema, "itineraries.startTime").Format)
+	assert.Equal(t, "Arrival time at this node", resolve(t, schema, "itineraries.graph.nodes.fromTimestamp").Description)
+
+	// The schema must be serializable for the prompt
+	_, err := json.Marshal(schema)
+	assert.NoError(t, err)
+}
+
+func TestSchemaValidate(t *testing.T) {
+	schema := PlannerResponseSchema()
+
+	valid := `{
+		"itineraries": [{
+			"title": "Weekend in Paris",
+			"startTime": "2026-01-25T10:00:00Z",
+			"endTime": "2026-01-27T18:00:00Z",
+			"travelers": 2,
+			"journeyType": "JOURNEY_TYPE_RETURN",
+			"graph": {
+				"nodes": [
+					{"id": "start_loc", "location": {"iataCodes": ["JFK"], "city": "New York"}},
+					{"id": "node_1", "location": {"cityCode": "PAR"},
+					 "stay": {"checkIn": "2026-01-25T14:00:00Z", "checkOut": "2026-01-27T11:00:00Z", "travelerCount": 2},
+					 "sub_graph": {"nodes": [{"id": "act_1", "location": {"name": "Eiffel Tower"}}]}}
+				],
+				"edges": [{
+					"fromId": "start_loc", "toId": "node_1", "durationSeconds": "25200",
+					"transport": {
+						"type": "TRANSPORT_TYPE_FLIGHT",
+						"travelerCount": 2,
+						"flightPreferences": {"travelClass": "CLASS_ECONOMY"},
+						"flight": {"departureTime": "2026-01-25T10:00:00Z"}
+					}
+				}]
+			}
+		}],
+		"reasoning": "Next weekend"
+	}`
+	assert.Empty(t, schema.Validate([]byte(valid)))
+
+	t.Run("DriftedField", func(t *testing.T) {
+		drifted := strings.Replace(valid, `"travelClass": "CLASS_ECONOMY"`, `"class": "ECONOMY"`, 1)
+
+		violations := schema.Validate([]byte(drifted))
+		if assert.Len(t, violations, 1) {
+			assert.Equal(t, "itineraries[0].graph.edges[0].transport.flightPreferences.class", violations[0].Path)
+			assert.Contains(t, violations[0].Message, "unknown field")
+			assert.Contains(t, violations[0].Message, "travelClass")
+		}
+	})
+
+	t.Run("WrongTypesAndEnums", func(t *testing.T) {
+		drifted := strings.Replace(valid, `"travelers": 2`, `"travelers": "two"`, 1)
+		drifted = strings.Replace(drifted, `"type": "TRANSPORT_TYPE_FLIGHT"`, `"type": "TRANSPORT_TYPE_TAXI"`, 1)
+		drifted = strings.Replace(drifted, `{"name": "Eiffel Tower"}`, `"Eiffel Tower"`, 1)
+		drifted = strings.Replace(drifted, `"startTime": "2026-01-25T10:00:00Z"`, `"startTime": "next saturday"`, 1)
+
+		var paths []string
+		for _, v := range schema.Validate([]byte(drifted)) {
+			paths = append(paths, v.Path)
+		}
+		assert.ElementsMatch(t, []string{
+			"itineraries[0].travelers",
+			"itineraries[0].startTime",
+			"itineraries[0].graph.edges[0].transport.type",
+			"itineraries[0].graph.nodes[1].sub_graph.nodes[0].location",
+		}, paths)
+	})
+
+	t.Run("MissingItineraries", func(t *testing.T) {
+		violations := schema.Validate([]byte(`{"reasoning": "none"}`))
+		if assert.Len(t, violations, 1) {
+			assert.Equal(t, "$", violations[0].Path)
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package httpcache
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/pb"
+	"github.com/va6996/travelingman/pb/pbconnect"
+)
+
+// itineraryServer serves one itinerary the way TravelServer.GetItinerary does
+type itineraryServer struct {
+	pbconnect.UnimplementedTravelServiceHandler
+	itinerary *pb.Itinerary
+	calls     int
+}
+
+func (s *itineraryServer) GetItinerary(ctx context.Context, req *connect.Request[pb.GetItineraryRequest]) (*connect.Response[pb.GetItineraryResponse], error) {
+	s.calls++
+	etag, err := ETag(s.itinerary)
+	if err != nil {
+		return nil, err
+	}
+	resp := connect.NewResponse(&pb.GetItineraryResponse{Itinerary: s.itinerary})
+	resp.Header().Set("ETag", etag)
+	resp.Header().Set("Cache-Control", "private, no-cache")
+	return resp, nil
+}
+
+func TestConditional_GetItinerary(t *testing.T) {
+	srv := &itineraryServer{itinerary: &pb.Itinerary{Id: 7, Title: "Weekend in Rome"}}
+	path, handler := pbconnect.NewTravelServiceHandler(srv)
+	mux := http.NewServeMux()
+	mux.Handle(path, Conditional(handler))
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	get := func(ifNoneMatch string) *http.Response {
+		u := ts.URL + pbconnect.TravelServiceGetItineraryProcedure + "?encoding=json&message=" + url.QueryEscape(`{"planId":"7"}`)
+		req, _ := http.NewRequest(http.MethodGet, u, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET failed: %v", err)
+		}
+		return resp
+	}
+
+	first := get("")
+	body, _ := io.ReadAll(first.Body)
+	first.Body.Close()
+	assert.Equal(t, http.StatusOK, first.StatusCode)
+	assert.Contains(t, string(body), "Weekend in Rome")
+	assert.Equal(t, "private, no-cache", first.Header.Get("Cache-Control"))
+	etag := first.Header.Get("ETag")
+	assert.Regexp(t, `^W/"[0-9a-f]{32}"$`, etag)
+
+	// The prior ETag revalidates the unchanged itinerary without sending it again
+	second := get(etag)
+	body, _ = io.ReadAll(second.Body)
+	second.Body.Close()
+	assert.Equal(t, http.StatusNotModified, second.StatusCode)
+	assert.Empty(t, body)
+	assert.Equal(t, etag, second.Header.Get("ETag"))
+
+	// Once the itinerary changes, so does its ETag
+	srv.itinerary = &pb.Itinerary{Id: 7, Title: "Long weekend in Rome"}
+	third := get(etag)
+	body, _ = io.ReadAll(third.Body)
+	third.Body.Close()
+	assert.Equal(t, http.StatusOK, third.StatusCode)
+	assert.Contains(t, string(body), "Long weekend in Rome")
+	assert.NotEqual(t, etag, third.Header.Get("ETag"))
+	assert.Equal(t, 3, srv.calls)
+}
+
+func TestMatches(t *testing.T) {
+	assert.True(t, matches(`W/"abc"`, `W/"abc"`))
+	assert.True(t, matches(`"abc"`, `W/"abc"`), "If-None-Match compares weakly")
+	assert.True(t, matches(`"x", W/"abc"`, `W/"abc"`))
+	assert.True(t, matches(`*`, `W/"abc"`))
+	assert.False(t, matches(`W/"abd"`, `W/"abc"`))
+	assert.False(t, matches(`*`, ``))
+}
@@ -0,0 +1,187 @@
+// Package httpcompress gzips responses for clients that accept it. Connect compresses
+// its own responses, so those pass through untouched and this covers the rest: the UI
+// bundle and the JSON debug endpoints.
+package httpcompress
+
+import (
+	"compress/gzip"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var gzipWriters = sync.Pool{New: func() any { return gzip.NewWriter(nil) }}
+
+// Gzip compresses responses of h with gzip when the request accepts it and the body
+// is at least minBytes long. Bodies already encoded, such as Connect's own compressed
+// responses, streaming RPCs and types that do not compress well are sent as they are.
+func Gzip(h http.Handler, minBytes int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if r.Method == http.MethodHead || !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+			h.ServeHTTP(w, r)
+			return
+		}
+		gw := &gzipWriter{ResponseWriter: w, minBytes: minBytes}
+		defer gw.close()
+		h.ServeHTTP(gw, r)
+	})
+}
+
+// gzipWriter holds the start of the body back until it knows whether to compress it
+type gzipWriter struct {
+	http.ResponseWriter
+	minBytes int
+
+	code    int
+	buf     []byte
+	decided bool
+	gz      *gzip.Writer
+}
+
+func (w *gzipWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+}
+
+func (w *gzipWriter) Write(b []byte) (int, error) {
+	if w.decided {
+		return w.write(b)
+	}
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	w.buf = append(w.buf, b...)
+	if len(w.buf) < w.minBytes && w.compressible() {
+		return len(b), nil
+	}
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", http.DetectContentType(w.buf))
+	}
+	w.decide(w.compressible())
+	if _, err := w.write(w.buf); err != nil {
+		return 0, err
+	}
+	w.buf = nil
+	return len(b), nil
+}
+
+// Flush sends what is held back uncompressed, since a handler that flushes wants it
+// to arrive now
+func (w *gzipWriter) Flush() {
+	if !w.decided {
+		w.decide(false)
+		w.write(w.buf)
+		w.buf = nil
+	}
+	if w.gz != nil {
+		w.gz.Flush()
+	}
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *gzipWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// close finishes the response once the handler returns. A body shorter than minBytes
+// is only sent now, uncompressed.
+func (w *gzipWriter) close() {
+	if !w.decided {
+		if w.code == 0 {
+			// Nothing was written, so the server sends its default response
+			return
+		}
+		w.decide(false)
+		w.write(w.buf)
+	}
+	if w.gz != nil {
+		w.gz.Close()
+		gzipWriters.Put(w.gz)
+	}
+}
+
+// decide sends the header, with the gzip encoding if compress is set
+func (w *gzipWriter) decide(compress bool) {
+	w.decided = true
+	if compress {
+		h := w.Header()
+		h.Set("Content-Encoding", "gzip")
+		h.Del("Content-Length")
+		// A strong validator names the uncompressed bytes
+		if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			h.Set("ETag", "W/"+etag)
+		}
+		w.gz = gzipWriters.Get().(*gzip.Writer)
+		w.gz.Reset(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(w.code)
+}
+
+func (w *gzipWriter) write(b []byte) (int, error) {
+	if len(b) == 0 {
+		return 0, nil
+	}
+	if w.gz != nil {
+		return w.gz.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// compressible reports whether the response so far is worth compressing
+func (w *gzipWriter) compressible() bool {
+	h := w.Header()
+	if w.code != http.StatusOK || h.Get("Content-Encoding") != "" || h.Get("Content-Range") != "" {
+		return false
+	}
+	contentType := h.Get("Content-Type")
+	if contentType == "" {
+		// Sniffed once the body is known
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch {
+	case strings.HasPrefix(mediaType, "text/"):
+		return true
+	case strings.HasPrefix(mediaType, "application/connect+"), strings.HasPrefix(mediaType, "application/grpc"):
+		// Streams are enveloped and compressed per message by Connect
+		return false
+	}
+	switch mediaType {
+	case "application/json", "application/javascript", "application/proto", "application/xml", "image/svg+xml":
+		return true
+	}
+	return false
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip. An explicit gzip
+// entry wins over "*".
+func acceptsGzip(accept string) bool {
+	gzipQ, anyQ := -1.0, -1.0
+	for _, part := range strings.Split(accept, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		switch coding = strings.TrimSpace(coding); {
+		case strings.EqualFold(coding, "gzip"):
+			gzipQ = q
+		case coding == "*":
+			anyQ = q
+		}
+	}
+	if gzipQ >= 0 {
+		return gzipQ > 0
+	}
+	return anyQ > 0
+}
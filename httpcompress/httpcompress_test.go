@@ -0,0 +1,150 @@
+package httpcompress
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/pb"
+	"github.com/va6996/travelingman/pb/pbconnect"
+)
+
+// planServer answers PlanTrip with many itineraries, like a plan with lots of options
+type planServer struct {
+	pbconnect.UnimplementedTravelServiceHandler
+}
+
+func (planServer) PlanTrip(ctx context.Context, req *connect.Request[pb.PlanTripRequest]) (*connect.Response[pb.PlanTripResponse], error) {
+	resp := &pb.PlanTripResponse{}
+	for i := range 50 {
+		resp.Itineraries = append(resp.Itineraries, &pb.Itinerary{
+			Title:       fmt.Sprintf("Option %d: a week in Lisbon", i),
+			Description: strings.Repeat("Fly out Friday, ferry to Cacilhas on Sunday. ", 10),
+		})
+	}
+	return connect.NewResponse(resp), nil
+}
+
+func newServer(minBytes int) *httptest.Server {
+	mux := http.NewServeMux()
+	path, handler := pbconnect.NewTravelServiceHandler(planServer{}, connect.WithCompressMinBytes(minBytes))
+	mux.Handle(path, handler)
+	mux.HandleFunc("/debug/cache", func(w http.ResponseWriter, r *http.Request) {
+		entries := "0"
+		if r.URL.Query().Has("large") {
+			entries = strings.Repeat("0, ", 1000) + entries
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"entries": [%s]}`, entries)
+	})
+	return httptest.NewServer(Gzip(mux, minBytes))
+}
+
+func get(t *testing.T, url, acceptEncoding string) (*http.Response, []byte) {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	// Setting Accept-Encoding stops the transport from decompressing on its own
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp, body
+}
+
+func gunzip(t *testing.T, body []byte) string {
+	t.Helper()
+	zr, err := gzip.NewReader(strings.NewReader(string(body)))
+	if err != nil {
+		t.Fatalf("body is not gzip: %v", err)
+	}
+	plain, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("gunzip failed: %v", err)
+	}
+	return string(plain)
+}
+
+func TestGzip_LargePlanTripResponse(t *testing.T) {
+	ts := newServer(1024)
+	defer ts.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, ts.URL+pbconnect.TravelServicePlanTripProcedure, strings.NewReader(`{"query":"Lisbon"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip, deflate, br")
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("PlanTrip failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+	assert.Less(t, len(body), 2048)
+	// Compressed once, by Connect: one gunzip gives the message
+	assert.Contains(t, gunzip(t, body), "Option 49: a week in Lisbon")
+
+	// A Connect client reads it back
+	client := pbconnect.NewTravelServiceClient(http.DefaultClient, ts.URL, connect.WithSendGzip())
+	res, err := client.PlanTrip(context.Background(), connect.NewRequest(&pb.PlanTripRequest{Query: "Lisbon"}))
+	if assert.NoError(t, err) {
+		assert.Len(t, res.Msg.Itineraries, 50)
+	}
+}
+
+func TestGzip(t *testing.T) {
+	ts := newServer(1024)
+	defer ts.Close()
+
+	t.Run("Large", func(t *testing.T) {
+		resp, body := get(t, ts.URL+"/debug/cache?large", "gzip")
+		assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		assert.Contains(t, resp.Header.Values("Vary"), "Accept-Encoding")
+		assert.True(t, strings.HasPrefix(gunzip(t, body), `{"entries": [0, 0, `))
+	})
+
+	t.Run("NotAccepted", func(t *testing.T) {
+		for _, accept := range []string{"", "identity", "gzip;q=0", "*;q=0", "br"} {
+			resp, body := get(t, ts.URL+"/debug/cache?large", accept)
+			assert.Empty(t, resp.Header.Get("Content-Encoding"), accept)
+			assert.True(t, strings.HasPrefix(string(body), `{"entries": [0, 0, `), accept)
+		}
+	})
+
+	t.Run("Small", func(t *testing.T) {
+		resp, body := get(t, ts.URL+"/debug/cache", "gzip")
+		assert.Empty(t, resp.Header.Get("Content-Encoding"))
+		assert.Equal(t, `{"entries": [0]}`, string(body))
+	})
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	for accept, want := range map[string]bool{
+		"gzip":                  true,
+		"gzip, deflate, br":     true,
+		"br;q=1.0, gzip;q=0.8":  true,
+		"*":                     true,
+		"GZIP":                  true,
+		"":                      false,
+		"deflate":               false,
+		"gzip;q=0":              false,
+		"*, gzip;q=0":           false,
+		"identity, *;q=0":       false,
+		"deflate, gzip;q=0.001": true,
+	} {
+		assert.Equal(t, want, acceptsGzip(accept), accept)
+	}
+}
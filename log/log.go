@@ -103,8 +103,8 @@ func (f *CustomFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 		fmt.Fprintf(b, "[%s:%d] ", filename, line)
 	}
 
-	// Message
-	b.WriteString(entry.Message)
+	// Message (credentials and PII are masked before they reach the output)
+	b.WriteString(Redact(entry.Message))
 
 	// Add fields if any (handle request_id specially)
 	if len(entry.Data) > 0 {
@@ -116,7 +116,7 @@ func (f *CustomFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 		// Add any other fields (excluding request_id which we already handled)
 		for key, value := range entry.Data {
 			if key != "request_id" {
-				fmt.Fprintf(b, " %s=%v", key, value)
+				fmt.Fprintf(b, " %s=%s", key, Redact(fmt.Sprintf("%v", value)))
 			}
 		}
 	}
@@ -152,6 +152,12 @@ func Info(ctx context.Context, args ...interface{}) {
 	withRequestIDField(ctx).Info(args...)
 }
 
+// Tracef logs formatted message at trace level.
+// Use it for per-offer and payload dumps that would drown out debug logs.
+func Tracef(ctx context.Context, format string, args ...interface{}) {
+	withRequestIDField(ctx).Tracef(format, args...)
+}
+
 // Debugf logs formatted message at debug level
 func Debugf(ctx context.Context, format string, args ...interface{}) {
 	withRequestIDField(ctx).Debugf(format, args...)
@@ -0,0 +1,72 @@
+package log
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Mask is the placeholder written in place of redacted values.
+const Mask = "[REDACTED]"
+
+var (
+	// Bearer tokens in headers or dumped requests, e.g. "Authorization: Bearer abc123"
+	bearerPattern = regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9\-._~+/]+=*`)
+
+	// Token, secret and card fields in JSON ("key":"value") or %+v (Key:value) form
+	sensitiveFieldPattern = regexp.MustCompile(`(?i)("?\b(?:access_?token|client_?secret|api_?key|password(?:_?hash)?|passport_?number|card_?number|security_?code|cvv)"?\s*[:=]\s*"?)([^"\s,}\]]+)`)
+
+	// Document number inside a passport struct (Amadeus Document or pb.Passport)
+	passportNumberPattern = regexp.MustCompile(`(?i)(passport[^}]*?\bnumber"?\s*[:=]\s*"?)([^"\s,}\]]+)`)
+
+	// Email addresses
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+	// Candidate card numbers: 13-19 digits, optionally separated by spaces or dashes
+	cardPattern = regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`)
+)
+
+// Redact masks card numbers, passport/document numbers, emails and bearer
+// tokens in s so that it is safe to write to the logs.
+func Redact(s string) string {
+	if s == "" {
+		return s
+	}
+	s = bearerPattern.ReplaceAllString(s, "${1}"+Mask)
+	s = sensitiveFieldPattern.ReplaceAllString(s, "${1}"+Mask)
+	s = passportNumberPattern.ReplaceAllString(s, "${1}"+Mask)
+	s = emailPattern.ReplaceAllString(s, Mask)
+	s = cardPattern.ReplaceAllStringFunc(s, maskCardNumber)
+	return s
+}
+
+// maskCardNumber keeps the last four digits of a Luhn-valid card number.
+// Digit runs that fail the Luhn check (timestamps, ids) are left untouched.
+func maskCardNumber(match string) string {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, match)
+	if !luhnValid(digits) {
+		return match
+	}
+	return "****" + digits[len(digits)-4:]
+}
+
+func luhnValid(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
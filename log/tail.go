@@ -0,0 +1,247 @@
+package log
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TailLine is one log line captured for a request
+type TailLine struct {
+	Time    time.Time `json:"time"`
+	Level   string    `json:"level"`
+	Message string    `json:"message"`
+}
+
+// TailConfig bounds what a Tail keeps
+type TailConfig struct {
+	LinesPerRequest int           // A request's oldest lines are dropped beyond this
+	MaxRequests     int           // The request that logged least recently is dropped beyond this
+	Retention       time.Duration // A request is dropped this long after its last line
+}
+
+// Tail is a logrus hook that keeps the last lines logged under each recent request
+// ID, so a misbehaving request can be inspected without reproducing it. Only lines
+// at or above the logger's level reach it.
+type Tail struct {
+	cfg TailConfig
+	now func() time.Time
+
+	mu       sync.Mutex
+	requests map[string]*tailBuffer
+}
+
+// tailBuffer is a ring of one request's lines and the streams following it
+type tailBuffer struct {
+	lines    []TailLine
+	next     int // Where the next line goes once the ring is full
+	lastSeen time.Time
+	subs     map[chan TailLine]struct{}
+}
+
+// NewTail creates a Tail; add it to the logger with Logger.AddHook
+func NewTail(cfg TailConfig) *Tail {
+	return &Tail{cfg: cfg, now: time.Now, requests: map[string]*tailBuffer{}}
+}
+
+// Levels captures every level
+func (t *Tail) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire captures an entry logged with a request ID
+func (t *Tail) Fire(entry *logrus.Entry) error {
+	id, _ := entry.Data["request_id"].(string)
+	if id == "" {
+		return nil
+	}
+	line := TailLine{Time: entry.Time, Level: strings.ToUpper(entry.Level.String()), Message: Redact(entry.Message)}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b := t.buffer(id)
+	if len(b.lines) < t.cfg.LinesPerRequest {
+		b.lines = append(b.lines, line)
+	} else if len(b.lines) > 0 {
+		b.lines[b.next] = line
+		b.next = (b.next + 1) % len(b.lines)
+	}
+	for sub := range b.subs {
+		// A stream that cannot keep up misses lines rather than blocking logging
+		select {
+		case sub <- line:
+		default:
+		}
+	}
+	return nil
+}
+
+// Lines returns a request's captured lines, oldest first, and whether any are kept
+func (t *Tail) Lines(requestID string) ([]TailLine, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.evictExpired()
+	b, ok := t.requests[requestID]
+	if !ok {
+		return nil, false
+	}
+	return b.ordered(), true
+}
+
+// Subscribe returns a request's captured lines and a channel of the lines it logs
+// from now on. The channel is closed when cancel is called or the request is
+// dropped. Only requests with captured lines can be followed; ok is false for
+// any other ID, which is never given a buffer of its own.
+func (t *Tail) Subscribe(requestID string) (past []TailLine, lines <-chan TailLine, cancel func(), ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.evictExpired()
+	b, ok := t.requests[requestID]
+	if !ok {
+		return nil, nil, nil, false
+	}
+	ch := make(chan TailLine, 64)
+	b.subs[ch] = struct{}{}
+	return b.ordered(), ch, func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if _, ok := b.subs[ch]; ok {
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}, true
+}
+
+// buffer returns a request's buffer, creating it and making room if needed. The
+// caller holds t.mu.
+func (t *Tail) buffer(id string) *tailBuffer {
+	now := t.now()
+	if b, ok := t.requests[id]; ok {
+		b.lastSeen = now
+		return b
+	}
+
+	t.evictExpired()
+	for len(t.requests) > 0 && len(t.requests) >= t.cfg.MaxRequests {
+		oldest := ""
+		for rid, b := range t.requests {
+			if oldest == "" || b.lastSeen.Before(t.requests[oldest].lastSeen) {
+				oldest = rid
+			}
+		}
+		t.drop(oldest)
+	}
+	b := &tailBuffer{lastSeen: now, subs: map[chan TailLine]struct{}{}}
+	t.requests[id] = b
+	return b
+}
+
+// evictExpired drops the requests that have not logged within the retention. The
+// caller holds t.mu.
+func (t *Tail) evictExpired() {
+	cutoff := t.now().Add(-t.cfg.Retention)
+	for id, b := range t.requests {
+		if b.lastSeen.Before(cutoff) {
+			t.drop(id)
+		}
+	}
+}
+
+// drop forgets a request and ends its streams. The caller holds t.mu.
+func (t *Tail) drop(id string) {
+	b := t.requests[id]
+	for sub := range b.subs {
+		delete(b.subs, sub)
+		close(sub)
+	}
+	delete(t.requests, id)
+}
+
+func (b *tailBuffer) ordered() []TailLine {
+	out := make([]TailLine, 0, len(b.lines))
+	out = append(out, b.lines[b.next:]...)
+	return append(out, b.lines[:b.next]...)
+}
+
+// Handler serves a request's lines as JSON at GET /debug/logs/{id}, and streams
+// them as server-sent events at GET /debug/logs/{id}/stream until the client goes
+// away. Callers must send token as a bearer token; an empty token disables both.
+func (t *Tail) Handler(token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /debug/logs/{id}", t.serveLines)
+	mux.HandleFunc("GET /debug/logs/{id}/stream", t.serveStream)
+	return DebugAuth(token, mux)
+}
+
+// DebugAuth guards a debug endpoint: requests must send token as a bearer token,
+// and an empty token hides the endpoint altogether
+func DebugAuth(token string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token == "" {
+			http.NotFound(w, r)
+			return
+		}
+		got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func (t *Tail) serveLines(w http.ResponseWriter, r *http.Request) {
+	lines, ok := t.Lines(r.PathValue("id"))
+	if !ok {
+		http.Error(w, "no logs for this request", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(lines)
+}
+
+func (t *Tail) serveStream(w http.ResponseWriter, r *http.Request) {
+	past, lines, cancel, ok := t.Subscribe(r.PathValue("id"))
+	if !ok {
+		http.Error(w, "no logs for this request", http.StatusNotFound)
+		return
+	}
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	rc := http.NewResponseController(w)
+	send := func(line TailLine) error {
+		data, _ := json.Marshal(line)
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	for _, line := range past {
+		if send(line) != nil {
+			return
+		}
+	}
+	// Send the headers even if there is nothing to replay yet
+	if rc.Flush() != nil {
+		return
+	}
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case line, ok := <-lines:
+			if !ok || send(line) != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package log
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	tmcontext "github.com/va6996/travelingman/context"
+)
+
+// withTail captures the test's logging in a Tail, out of the test output
+func withTail(t *testing.T, cfg TailConfig) *Tail {
+	tail := NewTail(cfg)
+	origOut, origLevel := Logger.Out, Logger.GetLevel()
+	origHooks := Logger.ReplaceHooks(make(logrus.LevelHooks))
+	Logger.AddHook(tail)
+	SetOutput(io.Discard)
+	SetLevel(logrus.DebugLevel)
+	t.Cleanup(func() {
+		Logger.ReplaceHooks(origHooks)
+		SetOutput(origOut)
+		SetLevel(origLevel)
+	})
+	return tail
+}
+
+func getLines(t *testing.T, srv *httptest.Server, id, token string) (int, []TailLine) {
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/debug/logs/"+id, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET logs: %v", err)
+	}
+	defer resp.Body.Close()
+	var lines []TailLine
+	if resp.StatusCode == http.StatusOK {
+		json.NewDecoder(resp.Body).Decode(&lines)
+	}
+	return resp.StatusCode, lines
+}
+
+func messages(lines []TailLine) []string {
+	out := make([]string, len(lines))
+	for i, l := range lines {
+		out[i] = l.Message
+	}
+	return out
+}
+
+func TestTail_PlanLogs(t *testing.T) {
+	tail := withTail(t, TailConfig{LinesPerRequest: 10, MaxRequests: 2, Retention: time.Hour})
+	srv := httptest.NewServer(tail.Handler("secret"))
+	defer srv.Close()
+
+	// Two plans interleave their logging; each keeps only its own lines
+	plan := tmcontext.WithRequestID(context.Background(), "req-1")
+	other := tmcontext.WithRequestID(context.Background(), "req-2")
+	Infof(plan, "Received planning request: %s", "Paris in May")
+	Debugf(other, "Received planning request: %s", "Rome")
+	Warnf(plan, "Hotel search returned no offers")
+	Errorf(plan, "Error processing request: %v", "timeout")
+	Infof(context.Background(), "Server listening")
+
+	code, lines := getLines(t, srv, "req-1", "secret")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{
+		"Received planning request: Paris in May",
+		"Hotel search returned no offers",
+		"Error processing request: timeout",
+	}, messages(lines))
+	if assert.Len(t, lines, 3) {
+		assert.Equal(t, "WARNING", lines[1].Level)
+	}
+
+	code, _ = getLines(t, srv, "req-1", "")
+	assert.Equal(t, http.StatusUnauthorized, code)
+	code, _ = getLines(t, srv, "req-1", "wrong")
+	assert.Equal(t, http.StatusUnauthorized, code)
+	code, _ = getLines(t, srv, "unknown", "secret")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	// A third request drops the one that logged least recently
+	Infof(tmcontext.WithRequestID(context.Background(), "req-3"), "Received planning request: %s", "Oslo")
+	code, _ = getLines(t, srv, "req-2", "secret")
+	assert.Equal(t, http.StatusNotFound, code)
+	code, _ = getLines(t, srv, "req-1", "secret")
+	assert.Equal(t, http.StatusOK, code)
+}
+
+func TestDebugAuth(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	status := func(h http.Handler, auth string) int {
+		req := httptest.NewRequest(http.MethodGet, "/debug/cache", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	guarded := DebugAuth("secret", ok)
+	assert.Equal(t, http.StatusOK, status(guarded, "Bearer secret"))
+	assert.Equal(t, http.StatusUnauthorized, status(guarded, ""))
+	assert.Equal(t, http.StatusUnauthorized, status(guarded, "Bearer wrong"))
+
+	// Without a token the endpoint is hidden, even from an empty bearer
+	assert.Equal(t, http.StatusNotFound, status(DebugAuth("", ok), "Bearer "))
+}
+
+func TestTail_Eviction(t *testing.T) {
+	tail := withTail(t, TailConfig{LinesPerRequest: 3, MaxRequests: 10, Retention: 30 * time.Minute})
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	tail.now = func() time.Time { return now }
+
+	ctx := tmcontext.WithRequestID(context.Background(), "req-1")
+	for i := 1; i <= 5; i++ {
+		Infof(ctx, "line %d", i)
+	}
+	// Only the last lines are kept, oldest first
+	lines, ok := tail.Lines("req-1")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"line 3", "line 4", "line 5"}, messages(lines))
+
+	// Card numbers are masked as in the log output
+	Infof(ctx, "card 4111111111111111")
+	lines, _ = tail.Lines("req-1")
+	assert.Equal(t, "card ****1111", lines[2].Message)
+
+	now = now.Add(31 * time.Minute)
+	_, ok = tail.Lines("req-1")
+	assert.False(t, ok)
+}
+
+func TestTail_Stream(t *testing.T) {
+	tail := withTail(t, TailConfig{LinesPerRequest: 10, MaxRequests: 10, Retention: time.Hour})
+	srv := httptest.NewServer(tail.Handler("secret"))
+	defer srv.Close()
+
+	ctx := tmcontext.WithRequestID(context.Background(), "req-1")
+	Infof(ctx, "before the stream")
+
+	reqCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(reqCtx, http.MethodGet, srv.URL+"/debug/logs/req-1/stream", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET stream: %v", err)
+	}
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	events := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+				var line TailLine
+				json.Unmarshal([]byte(data), &line)
+				events <- line.Message
+			}
+		}
+		close(events)
+	}()
+
+	next := func() string {
+		select {
+		case msg := <-events:
+			return msg
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for a streamed line")
+			return ""
+		}
+	}
+	// The stream replays what was logged before it opened, then follows the request
+	assert.Equal(t, "before the stream", next())
+	Infof(ctx, "while streaming")
+	assert.Equal(t, "while streaming", next())
+}
+
+func TestTail_StreamUnknownRequest(t *testing.T) {
+	tail := withTail(t, TailConfig{LinesPerRequest: 10, MaxRequests: 1, Retention: time.Hour})
+	srv := httptest.NewServer(tail.Handler("secret"))
+	defer srv.Close()
+
+	Infof(tmcontext.WithRequestID(context.Background(), "req-1"), "kept")
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/debug/logs/unknown/stream", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET stream: %v", err)
+	}
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	// Following an unknown ID must not take the only slot from a real request
+	lines, ok := tail.Lines("req-1")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"kept"}, messages(lines))
+	_, ok = tail.Lines("unknown")
+	assert.False(t, ok)
+}
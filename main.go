@@ -3,32 +3,96 @@ package main
 import (
 	"embed"
 	"context"
+	"crypto/subtle"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"io/fs"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	pathpkg "path"
 	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	"connectrpc.com/connect"
+	"github.com/va6996/travelingman/agents"
 	"github.com/va6996/travelingman/bootstrap"
 	"github.com/va6996/travelingman/config"
 	logcontext "github.com/va6996/travelingman/context"
+	"github.com/va6996/travelingman/httpcache"
+	"github.com/va6996/travelingman/httpcompress"
 	"github.com/va6996/travelingman/log"
+	"github.com/va6996/travelingman/metrics"
+	"github.com/va6996/travelingman/orm"
 	pb "github.com/va6996/travelingman/pb"
 	"github.com/va6996/travelingman/pb/pbconnect"
+	"github.com/va6996/travelingman/plugins/amadeus"
+	"github.com/va6996/travelingman/plugins/core"
 	"golang.org/x/net/http2"
 	"golang.org/x/net/http2/h2c"
+	"gorm.io/gorm"
 )
 
 //go:embed ui/dist
 var uiFS embed.FS
 
+// requestIDHeader carries a plan's request ID back to the client
+const requestIDHeader = "X-Request-Id"
+
+// compressMinBytes is the smallest response body sent gzipped to clients that accept it
+const compressMinBytes = 1024
+
 type TravelServer struct {
 	app *bootstrap.App
+
+	places             *core.PlaceIndex
+	autocompleteLimits *ipLimiter
+}
+
+// ipLimiter allows each client IP a number of requests per window. A rate of 0 allows everything.
+type ipLimiter struct {
+	rate   int
+	window time.Duration
+
+	mu      sync.Mutex
+	started time.Time
+	counts  map[string]int
+}
+
+func newIPLimiter(rate int, window time.Duration) *ipLimiter {
+	return &ipLimiter{rate: rate, window: window, counts: map[string]int{}}
+}
+
+// SetRate changes the number of requests allowed per window
+func (l *ipLimiter) SetRate(rate int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rate = rate
+}
+
+// Allow counts a request from addr and reports whether it is within the limit
+func (l *ipLimiter) Allow(addr string) bool {
+	ip, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		ip = addr
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.rate <= 0 {
+		return true
+	}
+	if now := time.Now(); now.Sub(l.started) >= l.window {
+		l.started = now
+		l.counts = map[string]int{}
+	}
+	l.counts[ip]++
+	return l.counts[ip] <= l.rate
 }
 
 func (s *TravelServer) PlanTrip(ctx context.Context, req *connect.Request[pb.PlanTripRequest]) (*connect.Response[pb.PlanTripResponse], error) {
@@ -37,38 +101,666 @@ func (s *TravelServer) PlanTrip(ctx context.Context, req *connect.Request[pb.Pla
 		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("query is required"))
 	}
 
+	ctx, requestID := s.planContext(ctx)
+	log.Infof(ctx, "Received planning request: %s", query)
+
+	resp, err := s.planTrip(ctx, req.Msg)
+	// The ID finds this request's lines at /debug/logs/{request_id}
+	if err != nil {
+		var connectErr *connect.Error
+		if errors.As(err, &connectErr) {
+			connectErr.Meta().Set(requestIDHeader, requestID)
+		}
+		return nil, err
+	}
+	resp.Header().Set(requestIDHeader, requestID)
+	return resp, nil
+}
+
+// PlanTripStream plans like PlanTrip, streaming the planner's text while the model
+// works, the steps of planning and each itinerary as soon as it is verified. The last
+// message carries the itineraries, scored, or the reason there are none.
+func (s *TravelServer) PlanTripStream(ctx context.Context, req *connect.Request[pb.PlanTripRequest], stream *connect.ServerStream[pb.PlanTripResponse]) error {
+	query := req.Msg.Query
+	if query == "" {
+		return connect.NewError(connect.CodeInvalidArgument, errors.New("query is required"))
+	}
+
+	ctx, requestID := s.planContext(ctx)
+	stream.ResponseHeader().Set(requestIDHeader, requestID)
+	log.Infof(ctx, "Received streaming planning request: %s", query)
+
+	// A client that went away stops receiving updates; planning ends with its context
+	var mu sync.Mutex
+	var sendErr error
+	send := func(msg *pb.PlanTripResponse) {
+		mu.Lock()
+		defer mu.Unlock()
+		if sendErr == nil {
+			sendErr = stream.Send(msg)
+		}
+	}
+	ctx = logcontext.WithTextSink(ctx, func(chunk string) { send(&pb.PlanTripResponse{Text: chunk}) })
+	ctx = logcontext.WithItinerarySink(ctx, func(it *pb.Itinerary) { send(&pb.PlanTripResponse{Verified: it}) })
+	ctx = logcontext.WithProgressSink(ctx, func(stage pb.PlanStage, detail string) {
+		send(&pb.PlanTripResponse{Progress: &pb.PlanProgress{Stage: stage, Detail: detail}})
+	})
+
+	// A plan that failed ends the stream with its error, after what was streamed so far
+	resp, err := s.planTrip(ctx, req.Msg)
+	if err != nil {
+		var connectErr *connect.Error
+		if ctx.Err() != nil || !errors.As(err, &connectErr) {
+			return err
+		}
+		resp = connect.NewResponse(&pb.PlanTripResponse{Error: &pb.Error{
+			Message:  connectErr.Message(),
+			Code:     pb.ErrorCode_ERROR_CODE_INTERNAL_SERVER_ERROR,
+			Severity: pb.ErrorSeverity_ERROR_SEVERITY_ERROR,
+		}})
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if sendErr != nil {
+		return sendErr
+	}
+	return stream.Send(resp.Msg)
+}
+
+// planContext gives a planning request its ID, retry and call budgets and activity counters
+func (s *TravelServer) planContext(ctx context.Context) (context.Context, string) {
 	// Generate request ID for tracking
 	// Connect might already have one, but let's keep our context logic
 	requestID := logcontext.NewRequestID()
 	ctx = logcontext.WithRequestID(ctx, requestID)
 
-	log.Infof(ctx, "Received planning request: %s", query)
+	ctx = s.withBudgets(ctx)
+	ctx = logcontext.WithRequestStats(ctx, logcontext.NewRequestStats())
+	return ctx, requestID
+}
 
-	res, itineraries, err := s.app.TravelAgent.OrchestrateRequest(ctx, query, "")
+// withBudgets gives a request the configured retry budget, which all provider retries
+// made while planning draw from, and caps its provider calls at the configured ceiling,
+// if any
+func (s *TravelServer) withBudgets(ctx context.Context) context.Context {
+	retries, calls := s.app.PlannerBudgets()
+	ctx = logcontext.WithRetryBudget(ctx, logcontext.NewRetryBudget(retries))
+	if calls > 0 {
+		ctx = logcontext.WithCallBudget(ctx, logcontext.NewCallBudget(calls))
+	}
+	return ctx
+}
+
+// planTrip plans the trip of a request whose context is set up
+func (s *TravelServer) planTrip(ctx context.Context, msg *pb.PlanTripRequest) (*connect.Response[pb.PlanTripResponse], error) {
+	query := msg.Query
+
+	var res string
+	var itineraries []*pb.Itinerary
+	var err error
+	if msg.Quick {
+		res, itineraries, err = s.app.TravelAgent.QuickPlan(ctx, query, s.app.Config.Planner.QuickMaxTurns)
+	} else {
+		res, itineraries, err = s.app.TravelAgent.OrchestrateRequest(ctx, query, "")
+	}
+	agents.LogRequestSummary(ctx, query, itineraries, err)
 	if err != nil {
 		log.Errorf(ctx, "Error processing request: %v", err)
 		return nil, connect.NewError(connect.CodeInternal, err)
 	}
 
+	// Drafts are saved so the client can verify them later by plan ID
+	if msg.Quick {
+		for _, it := range itineraries {
+			if err := orm.CreateSavedTrip(s.app.DB, it); err != nil {
+				log.Errorf(ctx, "Error saving draft plan %q: %v", it.Title, err)
+				return nil, connect.NewError(connect.CodeInternal, err)
+			}
+			log.Infof(ctx, "Saved draft plan %d: %s", it.Id, it.Title)
+			s.storeTripDays(ctx, it)
+		}
+	}
+
 	response := &pb.PlanTripResponse{}
 
 	if len(itineraries) > 0 {
 		response.Itineraries = itineraries
-	} else if res != "" {
-		// Wrap text result (likely error or explanation) in an Itinerary with Error
-		response.Itineraries = []*pb.Itinerary{
-			{
-				Error: &pb.Error{
-					Message:  res,
-					Severity: pb.ErrorSeverity_ERROR_SEVERITY_ERROR,
-				},
-			},
+		response.Summary = agents.SummarizeTrip(itineraries)
+	} else {
+		response.Error = &pb.Error{
+			Message:  res,
+			Severity: pb.ErrorSeverity_ERROR_SEVERITY_ERROR,
+		}
+		if res == "" {
+			response.Error.Message = "No itinerary could be planned for this request"
+		} else {
+			// Wrap text result (likely error or explanation) in an Itinerary with Error
+			response.Itineraries = []*pb.Itinerary{{Error: response.Error}}
 		}
 	}
 
 	return connect.NewResponse(response), nil
 }
 
+func (s *TravelServer) GetPriceCalendar(ctx context.Context, req *connect.Request[pb.GetPriceCalendarRequest]) (*connect.Response[pb.GetPriceCalendarResponse], error) {
+	if req.Msg.Origin == "" || req.Msg.Destination == "" {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("origin and destination are required"))
+	}
+	month, err := time.Parse("2006-01", req.Msg.Month)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("month must be in YYYY-MM format"))
+	}
+
+	requestID := logcontext.NewRequestID()
+	ctx = logcontext.WithRequestID(ctx, requestID)
+
+	log.Infof(ctx, "Received price calendar request: %s-%s %s", req.Msg.Origin, req.Msg.Destination, req.Msg.Month)
+
+	calendar, err := s.app.Amadeus.GetPriceCalendar(ctx, req.Msg.Origin, req.Msg.Destination, month, int(req.Msg.Adults), req.Msg.Currency)
+	if err != nil {
+		log.Errorf(ctx, "Error building price calendar: %v", err)
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	return connect.NewResponse(&pb.GetPriceCalendarResponse{Calendar: calendar}), nil
+}
+
+func (s *TravelServer) GetFareTrend(ctx context.Context, req *connect.Request[pb.GetFareTrendRequest]) (*connect.Response[pb.GetFareTrendResponse], error) {
+	if req.Msg.Origin == "" || req.Msg.Destination == "" {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("origin and destination are required"))
+	}
+	from, err := time.Parse("2006-01-02", req.Msg.FromDate)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("from_date must be in YYYY-MM-DD format"))
+	}
+	to, err := time.Parse("2006-01-02", req.Msg.ToDate)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("to_date must be in YYYY-MM-DD format"))
+	}
+
+	requestID := logcontext.NewRequestID()
+	ctx = logcontext.WithRequestID(ctx, requestID)
+
+	log.Infof(ctx, "Received fare trend request: %s-%s %s..%s", req.Msg.Origin, req.Msg.Destination, req.Msg.FromDate, req.Msg.ToDate)
+
+	trend, err := s.app.Amadeus.GetFareTrend(ctx, req.Msg.Origin, req.Msg.Destination, from, to, int(req.Msg.Adults), req.Msg.Currency)
+	if err != nil {
+		log.Errorf(ctx, "Error building fare trend: %v", err)
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	return connect.NewResponse(&pb.GetFareTrendResponse{Trend: trend}), nil
+}
+
+func (s *TravelServer) SaveTrip(ctx context.Context, req *connect.Request[pb.SaveTripRequest]) (*connect.Response[pb.SaveTripResponse], error) {
+	if req.Msg.Itinerary == nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("itinerary is required"))
+	}
+
+	requestID := logcontext.NewRequestID()
+	ctx = logcontext.WithRequestID(ctx, requestID)
+
+	if err := orm.CreateSavedTrip(s.app.DB, req.Msg.Itinerary); err != nil {
+		log.Errorf(ctx, "Error saving trip: %v", err)
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	log.Infof(ctx, "Saved trip %d: %s", req.Msg.Itinerary.Id, req.Msg.Itinerary.Title)
+	s.storeTripDays(ctx, req.Msg.Itinerary)
+
+	return connect.NewResponse(&pb.SaveTripResponse{Itinerary: req.Msg.Itinerary}), nil
+}
+
+func (s *TravelServer) UpdateTrip(ctx context.Context, req *connect.Request[pb.UpdateTripRequest]) (*connect.Response[pb.UpdateTripResponse], error) {
+	edited := req.Msg.Itinerary
+	if edited == nil || edited.Id == 0 {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("itinerary with an id is required"))
+	}
+
+	requestID := logcontext.NewRequestID()
+	ctx = logcontext.WithRequestID(ctx, requestID)
+
+	log.Infof(ctx, "Received update for trip %d (version %d)", edited.Id, edited.Version)
+
+	saved, err := orm.GetSavedTrip(s.app.DB, uint(edited.Id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, connect.NewError(connect.CodeNotFound, err)
+	} else if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	updated, conflicts, err := s.app.TravelAgent.UpdateTrip(ctx, saved, edited)
+	if errors.Is(err, agents.ErrVersionConflict) {
+		return nil, connect.NewError(connect.CodeAborted, err)
+	} else if err != nil {
+		log.Errorf(ctx, "Error updating trip: %v", err)
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	if len(conflicts) > 0 {
+		return connect.NewResponse(&pb.UpdateTripResponse{Conflicts: conflicts}), nil
+	}
+
+	// The write only succeeds if nobody saved the trip since it was loaded above
+	if err := orm.UpdateSavedTrip(s.app.DB, updated); errors.Is(err, orm.ErrVersionConflict) {
+		return nil, connect.NewError(connect.CodeAborted, err)
+	} else if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	s.storeTripDays(ctx, updated)
+
+	return connect.NewResponse(&pb.UpdateTripResponse{Itinerary: updated}), nil
+}
+
+func (s *TravelServer) VerifyPlan(ctx context.Context, req *connect.Request[pb.VerifyPlanRequest]) (*connect.Response[pb.VerifyPlanResponse], error) {
+	if req.Msg.PlanId == 0 {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("plan_id is required"))
+	}
+
+	requestID := logcontext.NewRequestID()
+	ctx = logcontext.WithRequestID(ctx, requestID)
+	ctx = s.withBudgets(ctx)
+
+	log.Infof(ctx, "Received verification request for plan %d", req.Msg.PlanId)
+
+	draft, err := orm.GetSavedTrip(s.app.DB, uint(req.Msg.PlanId))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, connect.NewError(connect.CodeNotFound, err)
+	} else if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	verified, err := s.app.TravelAgent.VerifyPlan(ctx, draft)
+	if err != nil {
+		log.Errorf(ctx, "Error verifying plan %d: %v", req.Msg.PlanId, err)
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	// The write only succeeds if nobody saved the plan since it was loaded above
+	if err := orm.UpdateSavedTrip(s.app.DB, verified); errors.Is(err, orm.ErrVersionConflict) {
+		return nil, connect.NewError(connect.CodeAborted, err)
+	} else if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	s.storeTripDays(ctx, verified)
+
+	return connect.NewResponse(&pb.VerifyPlanResponse{Itinerary: verified}), nil
+}
+
+// GetItinerary returns a saved plan with an ETag of its content and the configured
+// Cache-Control. Served over GET, a request whose If-None-Match matches is answered
+// 304 Not Modified by httpcache.Conditional.
+func (s *TravelServer) GetItinerary(ctx context.Context, req *connect.Request[pb.GetItineraryRequest]) (*connect.Response[pb.GetItineraryResponse], error) {
+	if req.Msg.PlanId == 0 {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("plan_id is required"))
+	}
+	it, err := orm.GetSavedTrip(s.app.DB, uint(req.Msg.PlanId))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, connect.NewError(connect.CodeNotFound, err)
+	} else if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	etag, err := httpcache.ETag(it)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	resp := connect.NewResponse(&pb.GetItineraryResponse{Itinerary: it})
+	resp.Header().Set("ETag", etag)
+	if cc := s.app.Config.Server.ItineraryCacheControl; cc != "" {
+		resp.Header().Set("Cache-Control", cc)
+	}
+	return resp, nil
+}
+
+func (s *TravelServer) GetTripGraph(ctx context.Context, req *connect.Request[pb.GetTripGraphRequest]) (*connect.Response[pb.GetTripGraphResponse], error) {
+	requestID := logcontext.NewRequestID()
+	ctx = logcontext.WithRequestID(ctx, requestID)
+
+	it := req.Msg.Itinerary
+	if req.Msg.PlanId != 0 {
+		saved, err := orm.GetSavedTrip(s.app.DB, uint(req.Msg.PlanId))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, connect.NewError(connect.CodeNotFound, err)
+		} else if err != nil {
+			return nil, connect.NewError(connect.CodeInternal, err)
+		}
+		it = saved
+	}
+	if it == nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("plan_id or itinerary is required"))
+	}
+
+	graph := agents.BuildTripGraph(it)
+	for _, w := range graph.Warnings {
+		log.Debugf(ctx, "Trip graph for %q: %s", it.Title, w)
+	}
+
+	return connect.NewResponse(&pb.GetTripGraphResponse{Graph: graph}), nil
+}
+
+// GetTripCalendar returns a saved plan laid out day by day, as stored whenever the plan
+// is saved. Plans saved before the layout was stored are laid out on first request.
+func (s *TravelServer) GetTripCalendar(ctx context.Context, req *connect.Request[pb.GetTripCalendarRequest]) (*connect.Response[pb.GetTripCalendarResponse], error) {
+	if req.Msg.PlanId == 0 {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("plan_id is required"))
+	}
+
+	trip, err := orm.GetPlanTrip(s.app.DB, uint(req.Msg.PlanId))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		it, err := orm.GetSavedTrip(s.app.DB, uint(req.Msg.PlanId))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, connect.NewError(connect.CodeNotFound, err)
+		} else if err != nil {
+			return nil, connect.NewError(connect.CodeInternal, err)
+		}
+		if err := agents.StoreTripDays(s.app.DB, it); err != nil {
+			return nil, connect.NewError(connect.CodeInternal, err)
+		}
+		trip, err = orm.GetPlanTrip(s.app.DB, uint(req.Msg.PlanId))
+		if err != nil {
+			return nil, connect.NewError(connect.CodeInternal, err)
+		}
+	} else if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	return connect.NewResponse(&pb.GetTripCalendarResponse{Calendar: agents.BuildTripCalendar(trip)}), nil
+}
+
+// GetMoreOptions returns the transport options collapsed behind one a saved plan shows
+func (s *TravelServer) GetMoreOptions(ctx context.Context, req *connect.Request[pb.GetMoreOptionsRequest]) (*connect.Response[pb.GetMoreOptionsResponse], error) {
+	if req.Msg.PlanId == 0 || req.Msg.Edge == "" || req.Msg.OptionId == "" {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("plan_id, edge and option_id are required"))
+	}
+	it, err := orm.GetSavedTrip(s.app.DB, uint(req.Msg.PlanId))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, connect.NewError(connect.CodeNotFound, err)
+	} else if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	options, err := agents.MoreOptions(it, req.Msg.Edge, req.Msg.OptionId)
+	if errors.Is(err, agents.ErrOptionNotFound) {
+		return nil, connect.NewError(connect.CodeNotFound, err)
+	} else if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	return connect.NewResponse(&pb.GetMoreOptionsResponse{Options: options}), nil
+}
+
+// storeTripDays stores the day-by-day layout of a plan that was just saved. The saved
+// plan stands if this fails, and the calendar shows the previous layout until the plan
+// is saved again.
+func (s *TravelServer) storeTripDays(ctx context.Context, it *pb.Itinerary) {
+	if err := agents.StoreTripDays(s.app.DB, it); err != nil {
+		log.Errorf(ctx, "Failed to store the days of plan %d: %v", it.Id, err)
+	}
+}
+
+// AutocompleteLocations suggests cities and airports from the in-memory index. It calls
+// no provider, so it is safe to serve per keystroke; callers are only rate-limited by IP.
+func (s *TravelServer) AutocompleteLocations(ctx context.Context, req *connect.Request[pb.AutocompleteLocationsRequest]) (*connect.Response[pb.AutocompleteLocationsResponse], error) {
+	query := strings.TrimSpace(req.Msg.Query)
+	if query == "" {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("query is required"))
+	}
+	if !s.autocompleteLimits.Allow(req.Peer().Addr) {
+		return nil, connect.NewError(connect.CodeResourceExhausted, errors.New("too many autocomplete requests, slow down"))
+	}
+
+	locations := s.places.Search(query, int(req.Msg.Limit))
+	return connect.NewResponse(&pb.AutocompleteLocationsResponse{Locations: locations}), nil
+}
+
+// BookFlight orders a flight offer for the given users and, if a split is given,
+// records what each of them owes. The confirmation is emailed to the travelers and
+// returned as the summary.
+func (s *TravelServer) BookFlight(ctx context.Context, req *connect.Request[pb.BookFlightRequest]) (*connect.Response[pb.BookFlightResponse], error) {
+	requestID := logcontext.NewRequestID()
+	ctx = logcontext.WithRequestID(ctx, requestID)
+
+	var offer amadeus.FlightOffer
+	if err := json.Unmarshal([]byte(req.Msg.OfferJson), &offer); err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("offer_json must be a flight offer"))
+	}
+	users, err := s.travelers(req.Msg.TravelerIds)
+	if err != nil {
+		return nil, err
+	}
+
+	booking, err := s.app.Bookings.BookFlight(ctx, offer, users, req.Msg.Split)
+	if booking == nil {
+		if errors.Is(err, agents.ErrInvalidSplit) || errors.Is(err, amadeus.ErrInvalidTravelers) {
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		}
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	// The order stands even if its split could not be recorded
+	if err != nil {
+		log.Errorf(ctx, "BookFlight: %v", err)
+	}
+
+	return connect.NewResponse(&pb.BookFlightResponse{
+		BookingId: int64(booking.Order.BookingID),
+		OrderId:   booking.Order.Data.ID,
+		Shares:    booking.Shares,
+		Summary:   booking.Summary,
+	}), nil
+}
+
+// travelers loads the users with ids, in order
+func (s *TravelServer) travelers(ids []int64) ([]*pb.User, error) {
+	if len(ids) == 0 {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("traveler_ids is required"))
+	}
+	users := make([]*pb.User, 0, len(ids))
+	for _, id := range ids {
+		user, err := orm.GetUser(s.app.DB, uint(id))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("traveler %d not found", id))
+		} else if err != nil {
+			return nil, connect.NewError(connect.CodeInternal, err)
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+// RefreshBookingStatus checks a booking against the provider's order and returns its
+// status, the schedule changes this refresh found and the full status history
+func (s *TravelServer) RefreshBookingStatus(ctx context.Context, req *connect.Request[pb.RefreshBookingStatusRequest]) (*connect.Response[pb.RefreshBookingStatusResponse], error) {
+	requestID := logcontext.NewRequestID()
+	ctx = logcontext.WithRequestID(ctx, requestID)
+
+	change, err := s.app.Bookings.Refresh(ctx, uint(req.Msg.BookingId))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, connect.NewError(connect.CodeNotFound, err)
+	} else if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	history, err := orm.BookingHistory(s.app.DB, uint(req.Msg.BookingId))
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	return connect.NewResponse(&pb.RefreshBookingStatusResponse{
+		Status:  change.To,
+		Changes: change.Changes,
+		History: history,
+	}), nil
+}
+
+// GetBookingSplits returns what each user owes of a booking split when it was booked
+// with BookFlight. A booking that was not split has no shares.
+func (s *TravelServer) GetBookingSplits(ctx context.Context, req *connect.Request[pb.GetBookingSplitsRequest]) (*connect.Response[pb.GetBookingSplitsResponse], error) {
+	if _, err := orm.GetBooking(s.app.DB, uint(req.Msg.BookingId)); errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, connect.NewError(connect.CodeNotFound, err)
+	} else if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	shares, err := orm.BookingPayments(s.app.DB, uint(req.Msg.BookingId))
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	total, currency, err := agents.SumPayments(shares)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	return connect.NewResponse(&pb.GetBookingSplitsResponse{
+		Shares:   shares,
+		Total:    total,
+		Currency: currency,
+	}), nil
+}
+
+// CancelBooking cancels the provider orders of a saved plan and saves the plan with
+// what was cancelled. Segments that could not be cancelled are returned as failures
+// and stay booked; calling it again retries them.
+func (s *TravelServer) CancelBooking(ctx context.Context, req *connect.Request[pb.CancelBookingRequest]) (*connect.Response[pb.CancelBookingResponse], error) {
+	if req.Msg.PlanId == 0 {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("plan_id is required"))
+	}
+
+	requestID := logcontext.NewRequestID()
+	ctx = logcontext.WithRequestID(ctx, requestID)
+
+	unlock := s.app.Bookings.LockPlan(req.Msg.PlanId)
+	defer unlock()
+	it, err := orm.GetSavedTrip(s.app.DB, uint(req.Msg.PlanId))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, connect.NewError(connect.CodeNotFound, err)
+	} else if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	failures := s.app.Bookings.CancelItinerary(ctx, it)
+	log.Infof(ctx, "Cancelled plan %d with %d failures", req.Msg.PlanId, len(failures))
+
+	// A plan edited meanwhile gets the new statuses copied onto it
+	it, err = s.app.Bookings.SaveBookedPlan(ctx, it)
+	if it == nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	} else if err != nil {
+		log.Errorf(ctx, "Plan %d: %v", req.Msg.PlanId, err)
+		return nil, connect.NewError(connect.CodeAborted, err)
+	}
+	s.storeTripDays(ctx, it)
+
+	return connect.NewResponse(&pb.CancelBookingResponse{Itinerary: it, Failures: failures}), nil
+}
+
+// ResumeBooking books the segments of a saved plan that are not booked yet, e.g. after
+// a booking was interrupted, and saves the plan with their references. Segments already
+// booked are checked with the provider and cancelled segments are left alone, so it is
+// safe to call again until every segment is booked.
+func (s *TravelServer) ResumeBooking(ctx context.Context, req *connect.Request[pb.ResumeBookingRequest]) (*connect.Response[pb.ResumeBookingResponse], error) {
+	if req.Msg.PlanId == 0 {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("plan_id is required"))
+	}
+	if len(req.Msg.HotelOffers) > 0 && (req.Msg.CardNumber == "" || req.Msg.CardExpiry == "") {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("card_number and card_expiry are required to book stays"))
+	}
+
+	requestID := logcontext.NewRequestID()
+	ctx = logcontext.WithRequestID(ctx, requestID)
+
+	users, err := s.travelers(req.Msg.TravelerIds)
+	if err != nil {
+		return nil, err
+	}
+	booking := agents.ItineraryBooking{
+		Users:        users,
+		FlightOffers: make(map[string]amadeus.FlightOffer, len(req.Msg.FlightOffers)),
+		HotelOffers:  req.Msg.HotelOffers,
+		Payment: amadeus.HotelPayment{
+			Method: "CREDIT_CARD",
+			Card: &amadeus.PaymentCard{
+				VendorCode: amadeus.CardVendor(req.Msg.CardNumber),
+				CardNumber: req.Msg.CardNumber,
+				ExpiryDate: req.Msg.CardExpiry,
+			},
+		},
+	}
+	for key, offerJSON := range req.Msg.FlightOffers {
+		var offer amadeus.FlightOffer
+		if err := json.Unmarshal([]byte(offerJSON), &offer); err != nil {
+			return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("flight_offers[%s] must be a flight offer", key))
+		}
+		booking.FlightOffers[key] = offer
+	}
+	for i, user := range users {
+		booking.Guests = append(booking.Guests, amadeus.NewHotelGuest(i+1, user))
+	}
+
+	unlock := s.app.Bookings.LockPlan(req.Msg.PlanId)
+	defer unlock()
+	it, err := orm.GetSavedTrip(s.app.DB, uint(req.Msg.PlanId))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, connect.NewError(connect.CodeNotFound, err)
+	} else if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	// What was booked before an error is saved, so the next call does not book it again
+	booked, bookErr := s.app.Bookings.ResumeBooking(ctx, it, booking)
+	log.Infof(ctx, "Resumed booking of plan %d: booked %d segments", req.Msg.PlanId, booked)
+	it, err = s.app.Bookings.SaveBookedPlan(ctx, it)
+	if it == nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	} else if err != nil {
+		log.Errorf(ctx, "Plan %d: %v", req.Msg.PlanId, err)
+		return nil, connect.NewError(connect.CodeAborted, err)
+	}
+	s.storeTripDays(ctx, it)
+	if bookErr != nil {
+		if errors.Is(bookErr, amadeus.ErrInvalidTravelers) {
+			return nil, connect.NewError(connect.CodeInvalidArgument, bookErr)
+		}
+		return nil, connect.NewError(connect.CodeInternal, bookErr)
+	}
+
+	return connect.NewResponse(&pb.ResumeBookingResponse{Itinerary: it, Booked: int32(booked)}), nil
+}
+
+// bookingProcedures place or cancel real orders
+var bookingProcedures = map[string]bool{
+	pbconnect.TravelServiceBookFlightProcedure:    true,
+	pbconnect.TravelServiceCancelBookingProcedure: true,
+	pbconnect.TravelServiceResumeBookingProcedure: true,
+}
+
+// bookingAuth guards the booking RPCs as log.DebugAuth guards the debug endpoints:
+// requests must send token as a bearer token, and an empty token refuses them all
+func bookingAuth(token string) connect.UnaryInterceptorFunc {
+	return func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			if !bookingProcedures[req.Spec().Procedure] {
+				return next(ctx, req)
+			}
+			if token == "" {
+				return nil, connect.NewError(connect.CodePermissionDenied, errors.New("booking is disabled: no booking token is configured"))
+			}
+			got := strings.TrimPrefix(req.Header().Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("a valid booking token is required"))
+			}
+			return next(ctx, req)
+		}
+	}
+}
+
+// withClock runs every request on clock, e.g. the fixed clock of test mode. A nil
+// clock leaves requests on the wall clock.
+func withClock(clock logcontext.Clock, h http.Handler) http.Handler {
+	if clock == nil {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.ServeHTTP(w, r.WithContext(logcontext.WithClock(r.Context(), clock)))
+	})
+}
+
 func main() {
 	// Initialize logging
 	log.Init()
@@ -97,20 +789,54 @@ func main() {
 	if err != nil {
 		log.Fatalf(context.Background(), "Setup failed: %v", err)
 	}
+	if app.Clock != nil {
+		ctx = logcontext.WithClock(ctx, app.Clock)
+	}
 
-	// 4. Start API Server
-	port := envPort()
-	if port == "" {
-		port = "8000"
+	// Keep upcoming bookings in sync with the provider
+	if cfg.Bookings.PollInterval > 0 {
+		go app.Bookings.Poll(ctx, time.Duration(cfg.Bookings.PollInterval)*time.Minute)
 	}
 
+	// 4. Start API Server
+	port := cfg.Server.Port
+
 	mux := http.NewServeMux()
 
+	// Autocomplete knows the built-in table plus locations earlier lookups resolved
+	learned, err := orm.ListLocations(app.DB)
+	if err != nil {
+		log.Warnf(ctx, "Failed to load learned locations: %v", err)
+	}
+	log.Infof(ctx, "Place index: %d learned locations", len(learned))
+
 	// Create Connect handler
-	traveler := &TravelServer{app: app}
-	path, handler := pbconnect.NewTravelServiceHandler(traveler)
+	traveler := &TravelServer{
+		app:                app,
+		places:             core.NewPlaceIndex(learned...),
+		autocompleteLimits: newIPLimiter(cfg.Server.AutocompleteRate, time.Minute),
+	}
+	path, handler := pbconnect.NewTravelServiceHandler(traveler,
+		connect.WithCompressMinBytes(compressMinBytes),
+		connect.WithInterceptors(bookingAuth(cfg.Server.BookingToken)))
+	handler = withClock(app.Clock, handler)
+	handler = httpcache.Conditional(handler)
 	mux.Handle(path, handler)
 
+	// Reload runtime-tunable config (log level, limits, rates) on SIGHUP
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
+	go func() {
+		for range hupChan {
+			log.Info(context.Background(), "Received SIGHUP, reloading config...")
+			if err := app.Reload(context.Background()); err != nil {
+				log.Errorf(context.Background(), "%v", err)
+				continue
+			}
+			traveler.autocompleteLimits.SetRate(app.AutocompleteRate())
+		}
+	}()
+
 	// Create a sub-filesystem for ui/dist
 	uiSubFS, err := fs.Sub(uiFS, "ui/dist")
 	if err != nil {
@@ -177,6 +903,20 @@ func main() {
 		}))
 	})
 
+	// Amadeus cache counters, for tuning the cache TTLs; behind the same token as the logs
+	mux.Handle("/debug/cache", log.DebugAuth(cfg.Server.DebugToken, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(app.Amadeus.Cache.Stats())
+	})))
+
+	// Prometheus scrape of the planning latency, with the debug token as bearer token
+	mux.Handle("/metrics", log.DebugAuth(cfg.Server.DebugToken, metrics.Handler(app.PlanPhases)))
+
+	// Recent log lines of a request, by the ID in its X-Request-Id header
+	if app.LogTail != nil {
+		mux.Handle("/debug/logs/", app.LogTail.Handler(cfg.Server.DebugToken))
+	}
+
 	// Register UI handler for all non-API routes
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		// API routes go to Connect handler
@@ -194,7 +934,8 @@ func main() {
 			// Allow all origins for now (dev mode)
 			w.Header().Set("Access-Control-Allow-Origin", "*")
 			w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Connect-Protocol-Version")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Connect-Protocol-Version, Authorization")
+			w.Header().Set("Access-Control-Expose-Headers", requestIDHeader)
 			if r.Method == "OPTIONS" {
 				w.WriteHeader(http.StatusOK)
 				return
@@ -206,7 +947,7 @@ func main() {
 	// Use h2c for HTTP/2 without TLS (common for dev and internal services)
 	srv := &http.Server{
 		Addr:    ":" + port,
-		Handler: h2c.NewHandler(corsHandler(mux), &http2.Server{}),
+		Handler: h2c.NewHandler(corsHandler(httpcompress.Gzip(mux, compressMinBytes)), &http2.Server{}),
 	}
 
 	go func() {
@@ -220,7 +961,3 @@ func main() {
 		log.Fatalf(context.Background(), "Server failed: %v", err)
 	}
 }
-
-func envPort() string {
-	return os.Getenv("PORT")
-}
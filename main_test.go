@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/agents"
+	"github.com/va6996/travelingman/bootstrap"
+	"github.com/va6996/travelingman/config"
+	logcontext "github.com/va6996/travelingman/context"
+	"github.com/va6996/travelingman/pb"
+	"github.com/va6996/travelingman/pb/pbconnect"
+)
+
+// streamingPlanner streams a line of text, then waits for release before answering
+// with plans
+type streamingPlanner struct {
+	plans   []*pb.Itinerary
+	release chan struct{}
+}
+
+func (p *streamingPlanner) Plan(ctx context.Context, req agents.PlanRequest) (*agents.PlanResult, error) {
+	if sink := logcontext.TextSinkFromContext(ctx); sink != nil {
+		sink("Looking at flights to Paris")
+	}
+	select {
+	case <-p.release:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return &agents.PlanResult{PossibleItineraries: p.plans}, nil
+}
+
+func (p *streamingPlanner) Capabilities() agents.PlannerCapabilities {
+	return agents.PlannerCapabilities{Streaming: true}
+}
+
+// availableDesk finds every itinerary available as planned
+type availableDesk struct{}
+
+func (availableDesk) CheckAvailability(ctx context.Context, it *pb.Itinerary) (*pb.Itinerary, error) {
+	return it, nil
+}
+
+// planStreamClient serves PlanTripStream over HTTP with planner
+func planStreamClient(t *testing.T, planner agents.Planner) pbconnect.TravelServiceClient {
+	app := &bootstrap.App{
+		TravelAgent: agents.NewTravelAgent(planner, availableDesk{}),
+		Config:      &config.Config{},
+	}
+	mux := http.NewServeMux()
+	mux.Handle(pbconnect.NewTravelServiceHandler(&TravelServer{app: app}))
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return pbconnect.NewTravelServiceClient(ts.Client(), ts.URL)
+}
+
+func TestPlanTripStream(t *testing.T) {
+	plan := &pb.Itinerary{Title: "Paris", Graph: &pb.Graph{Nodes: []*pb.Node{{Id: "par", Location: &pb.Location{City: "Paris"}}}}}
+	planner := &streamingPlanner{plans: []*pb.Itinerary{plan}, release: make(chan struct{})}
+	client := planStreamClient(t, planner)
+
+	stream, err := client.PlanTripStream(context.Background(), connect.NewRequest(&pb.PlanTripRequest{Query: "A weekend in Paris"}))
+	assert.NoError(t, err)
+	defer stream.Close()
+
+	// The planner is still working when its text arrives, after any progress
+	var text string
+	for text == "" && stream.Receive() {
+		text = stream.Msg().Text
+	}
+	assert.Equal(t, "Looking at flights to Paris", text)
+	close(planner.release)
+
+	var last *pb.PlanTripResponse
+	for stream.Receive() {
+		last = stream.Msg()
+	}
+	assert.NoError(t, stream.Err())
+	if assert.NotNil(t, last) && assert.Len(t, last.Itineraries, 1) {
+		assert.Equal(t, "Paris", last.Itineraries[0].Title)
+		assert.Nil(t, last.Error)
+	}
+}
+
+func TestPlanTripStream_NoItinerary(t *testing.T) {
+	planner := &streamingPlanner{release: make(chan struct{})}
+	close(planner.release)
+	client := planStreamClient(t, planner)
+
+	stream, err := client.PlanTripStream(context.Background(), connect.NewRequest(&pb.PlanTripRequest{Query: "A weekend on Mars"}))
+	assert.NoError(t, err)
+	defer stream.Close()
+
+	var last *pb.PlanTripResponse
+	for stream.Receive() {
+		last = stream.Msg()
+	}
+	assert.NoError(t, stream.Err(), "the stream ends with a message, not an error")
+	if assert.NotNil(t, last) {
+		assert.Empty(t, last.Itineraries)
+		if assert.NotNil(t, last.Error) {
+			assert.NotEmpty(t, last.Error.Message)
+		}
+	}
+}
+
+func TestBookingAuth(t *testing.T) {
+	serve := func(token string) pbconnect.TravelServiceClient {
+		mux := http.NewServeMux()
+		mux.Handle(pbconnect.NewTravelServiceHandler(&TravelServer{app: &bootstrap.App{Config: &config.Config{}}},
+			connect.WithInterceptors(bookingAuth(token))))
+		ts := httptest.NewServer(mux)
+		t.Cleanup(ts.Close)
+		return pbconnect.NewTravelServiceClient(ts.Client(), ts.URL)
+	}
+	book := func(client pbconnect.TravelServiceClient, auth string) connect.Code {
+		req := connect.NewRequest(&pb.BookFlightRequest{})
+		if auth != "" {
+			req.Header().Set("Authorization", auth)
+		}
+		_, err := client.BookFlight(context.Background(), req)
+		return connect.CodeOf(err)
+	}
+
+	client := serve("secret")
+	assert.Equal(t, connect.CodeUnauthenticated, book(client, ""))
+	assert.Equal(t, connect.CodeUnauthenticated, book(client, "Bearer wrong"))
+	// Past the check, the empty offer is rejected by the handler
+	assert.Equal(t, connect.CodeInvalidArgument, book(client, "Bearer secret"))
+
+	_, err := client.CancelBooking(context.Background(), connect.NewRequest(&pb.CancelBookingRequest{PlanId: 1}))
+	assert.Equal(t, connect.CodeUnauthenticated, connect.CodeOf(err))
+	_, err = client.ResumeBooking(context.Background(), connect.NewRequest(&pb.ResumeBookingRequest{PlanId: 1}))
+	assert.Equal(t, connect.CodeUnauthenticated, connect.CodeOf(err))
+
+	// Without a token booking is off for everyone
+	assert.Equal(t, connect.CodePermissionDenied, book(serve(""), "Bearer "))
+}
@@ -0,0 +1,118 @@
+// Package metrics keeps latency histograms and serves them in the Prometheus text
+// format, so they can be scraped without pulling in the Prometheus client.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are upper bounds in seconds, from a cached search to a slow plan
+var DefaultBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 30, 60, 120}
+
+// Histogram counts observations into buckets per value of one label, e.g. per phase.
+// It is safe for concurrent use.
+type Histogram struct {
+	name    string
+	help    string
+	label   string
+	buckets []float64
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+// series is the histogram of one label value
+type series struct {
+	counts []uint64 // Per bucket, not cumulative; the last is +Inf
+	sum    float64
+	count  uint64
+}
+
+// NewHistogram creates a histogram named name, split by label, with the given bucket
+// upper bounds in increasing order
+func NewHistogram(name, help, label string, buckets []float64) *Histogram {
+	return &Histogram{
+		name:    name,
+		help:    help,
+		label:   label,
+		buckets: buckets,
+		series:  make(map[string]*series),
+	}
+}
+
+// Observe records one observation for a label value
+func (h *Histogram) Observe(value string, v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.series[value]
+	if !ok {
+		s = &series{counts: make([]uint64, len(h.buckets)+1)}
+		h.series[value] = s
+	}
+	s.counts[sort.SearchFloat64s(h.buckets, v)]++
+	s.sum += v
+	s.count++
+}
+
+// ObserveDuration records a duration in seconds
+func (h *Histogram) ObserveDuration(value string, d time.Duration) {
+	h.Observe(value, d.Seconds())
+}
+
+// Count is the number of observations for a label value
+func (h *Histogram) Count(value string) uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if s, ok := h.series[value]; ok {
+		return s.count
+	}
+	return 0
+}
+
+// WriteTo writes the histogram in the Prometheus text format, label values in order
+func (h *Histogram) WriteTo(w io.Writer) (int64, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name)
+	values := make([]string, 0, len(h.series))
+	for v := range h.series {
+		values = append(values, v)
+	}
+	sort.Strings(values)
+	for _, v := range values {
+		s := h.series[v]
+		label := fmt.Sprintf("%s=%q", h.label, v)
+		var cumulative uint64
+		for i, n := range s.counts {
+			cumulative += n
+			le := "+Inf"
+			if i < len(h.buckets) {
+				le = strconv.FormatFloat(h.buckets[i], 'g', -1, 64)
+			}
+			fmt.Fprintf(&b, "%s_bucket{%s,le=%q} %d\n", h.name, label, le, cumulative)
+		}
+		fmt.Fprintf(&b, "%s_sum{%s} %s\n", h.name, label, strconv.FormatFloat(s.sum, 'g', -1, 64))
+		fmt.Fprintf(&b, "%s_count{%s} %d\n", h.name, label, s.count)
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// Handler serves the histograms for a Prometheus scrape
+func Handler(hs ...*Histogram) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		for _, h := range hs {
+			h.WriteTo(w)
+		}
+	})
+}
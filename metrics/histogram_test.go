@@ -0,0 +1,35 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistogram(t *testing.T) {
+	h := NewHistogram("plan_seconds", "Time to plan.", "phase", []float64{1, 5})
+	h.ObserveDuration("planning", 3*time.Second)
+	h.ObserveDuration("planning", 10*time.Second)
+	h.Observe("scoring", 0.5)
+	assert.Equal(t, uint64(2), h.Count("planning"))
+	assert.Equal(t, uint64(0), h.Count("verification"))
+
+	rec := httptest.NewRecorder()
+	Handler(h).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, "text/plain; version=0.0.4", rec.Header().Get("Content-Type"))
+	assert.Equal(t, `# HELP plan_seconds Time to plan.
+# TYPE plan_seconds histogram
+plan_seconds_bucket{phase="planning",le="1"} 0
+plan_seconds_bucket{phase="planning",le="5"} 1
+plan_seconds_bucket{phase="planning",le="+Inf"} 2
+plan_seconds_sum{phase="planning"} 13
+plan_seconds_count{phase="planning"} 2
+plan_seconds_bucket{phase="scoring",le="1"} 1
+plan_seconds_bucket{phase="scoring",le="5"} 1
+plan_seconds_bucket{phase="scoring",le="+Inf"} 1
+plan_seconds_sum{phase="scoring"} 0.5
+plan_seconds_count{phase="scoring"} 1
+`, rec.Body.String())
+}
@@ -161,6 +161,7 @@ func RunMigrations(db *sql.DB) error {
 			"destination" TEXT,
 			"start_date" DATETIME,
 			"end_date" DATETIME,
+			"details_json" TEXT,
 			"created_at" DATETIME DEFAULT CURRENT_TIMESTAMP,
 			FOREIGN KEY(group_id) REFERENCES travel_groups(id)
 		);`,
@@ -175,6 +176,8 @@ func RunMigrations(db *sql.DB) error {
 		`CREATE TABLE IF NOT EXISTS places (
 			"id" INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
 			"trip_day_id" INTEGER NOT NULL,
+			"node_id" TEXT,
+			"parent_node_id" TEXT,
 			"name" TEXT NOT NULL,
 			"address" TEXT,
 			"place_type" TEXT,
@@ -183,6 +186,7 @@ func RunMigrations(db *sql.DB) error {
 			"notes" TEXT,
 			"visit_time" DATETIME,
 			"order_index" INTEGER DEFAULT 0,
+			"details_json" TEXT,
 			FOREIGN KEY(trip_day_id) REFERENCES trip_days(id) ON DELETE CASCADE
 		);`,
 		`CREATE TABLE IF NOT EXISTS trip_travelers (
@@ -197,6 +201,7 @@ func RunMigrations(db *sql.DB) error {
 		`CREATE TABLE IF NOT EXISTS trip_transports (
 			"id" INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
 			"trip_id" INTEGER NOT NULL,
+			"parent_node_id" TEXT,
 			"from_location" TEXT NOT NULL,
 			"to_location" TEXT NOT NULL,
 			"transport_mode" TEXT NOT NULL,
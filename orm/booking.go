@@ -0,0 +1,138 @@
+package orm
+
+import (
+	"time"
+
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/proto"
+	"gorm.io/gorm"
+)
+
+// Booking is a flight order placed with a provider. Flight is the serialized pb.Flight
+// as last confirmed by the provider, so later refreshes can be diffed against it.
+type Booking struct {
+	ID            uint   `gorm:"primaryKey"`
+	Reference     string `gorm:"index"` // Provider order ID
+	Status        pb.BookingStatus
+	DepartureTime time.Time `gorm:"index"`
+	Flight        []byte
+	Travelers     []int64 `gorm:"serializer:json"` // User IDs of the travelers on the order
+	GroupID       uint    `gorm:"index"`           // Travel group the order was booked for, if any
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// BookingStatusChange is one entry of a booking's status history, stored as a
+// serialized pb.BookingStatusChange
+type BookingStatusChange struct {
+	ID        uint `gorm:"primaryKey"`
+	BookingID uint `gorm:"index"`
+	Data      []byte
+	CreatedAt time.Time
+}
+
+// CreateBooking stores a new pending booking of a flight for travelers under the
+// provider's order ID
+func CreateBooking(db *gorm.DB, reference string, flight *pb.Flight, travelers []int64) (*Booking, error) {
+	data, err := proto.Marshal(flight)
+	if err != nil {
+		return nil, err
+	}
+	b := &Booking{
+		Reference:     reference,
+		Status:        pb.BookingStatus_BOOKING_STATUS_PENDING,
+		DepartureTime: flight.GetDepartureTime().AsTime(),
+		Flight:        data,
+		Travelers:     travelers,
+	}
+	if err := db.Create(b).Error; err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// SetBookingGroup records that a booking was made for a travel group
+func SetBookingGroup(db *gorm.DB, bookingID, groupID uint) error {
+	return db.Model(&Booking{ID: bookingID}).Update("group_id", groupID).Error
+}
+
+// GetGroupBookings loads the bookings made for a travel group, oldest first
+func GetGroupBookings(db *gorm.DB, groupID uint) ([]Booking, error) {
+	var bookings []Booking
+	if err := db.Where("group_id = ?", groupID).Order("id").Find(&bookings).Error; err != nil {
+		return nil, err
+	}
+	return bookings, nil
+}
+
+// GetBooking loads a booking by ID
+func GetBooking(db *gorm.DB, id uint) (*Booking, error) {
+	var b Booking
+	if err := db.First(&b, id).Error; err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// BookedFlight returns the stored flight details
+func (b *Booking) BookedFlight() (*pb.Flight, error) {
+	flight := &pb.Flight{}
+	if err := proto.Unmarshal(b.Flight, flight); err != nil {
+		return nil, err
+	}
+	return flight, nil
+}
+
+// UpcomingBookings returns the bookings that depart after now and are not cancelled
+func UpcomingBookings(db *gorm.DB, now time.Time) ([]Booking, error) {
+	var bookings []Booking
+	err := db.Where("departure_time > ? AND status <> ?", now, pb.BookingStatus_BOOKING_STATUS_CANCELLED).
+		Order("departure_time").
+		Find(&bookings).Error
+	return bookings, err
+}
+
+// RecordBookingStatus moves a booking to change.To, replaces its stored flight and
+// appends the change to its history, all in one transaction
+func RecordBookingStatus(db *gorm.DB, b *Booking, change *pb.BookingStatusChange, flight *pb.Flight) error {
+	flightData, err := proto.Marshal(flight)
+	if err != nil {
+		return err
+	}
+	changeData, err := proto.Marshal(change)
+	if err != nil {
+		return err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(b).Updates(map[string]interface{}{
+			"status":         change.To,
+			"departure_time": flight.GetDepartureTime().AsTime(),
+			"flight":         flightData,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&BookingStatusChange{BookingID: b.ID, Data: changeData}).Error
+	})
+	if err != nil {
+		return err
+	}
+	b.Status = change.To
+	return nil
+}
+
+// BookingHistory returns a booking's status changes, oldest first
+func BookingHistory(db *gorm.DB, bookingID uint) ([]*pb.BookingStatusChange, error) {
+	var rows []BookingStatusChange
+	if err := db.Where("booking_id = ?", bookingID).Order("id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	history := make([]*pb.BookingStatusChange, len(rows))
+	for i, row := range rows {
+		history[i] = &pb.BookingStatusChange{}
+		if err := proto.Unmarshal(row.Data, history[i]); err != nil {
+			return nil, err
+		}
+	}
+	return history, nil
+}
@@ -0,0 +1,71 @@
+package orm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestBookingStatusHistory(t *testing.T) {
+	db := SetupTestDB(t)
+	now := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	flight := func(number string, departure time.Time) *pb.Flight {
+		return &pb.Flight{CarrierCode: "BA", FlightNumber: number, DepartureTime: timestamppb.New(departure)}
+	}
+
+	upcoming, err := CreateBooking(db, "ORDER1", flight("178", now.Add(48*time.Hour)), []int64{7, 8})
+	assert.NoError(t, err)
+	assert.Equal(t, pb.BookingStatus_BOOKING_STATUS_PENDING, upcoming.Status)
+	past, err := CreateBooking(db, "ORDER2", flight("112", now.Add(-48*time.Hour)), nil)
+	assert.NoError(t, err)
+	cancelled, err := CreateBooking(db, "ORDER3", flight("117", now.Add(72*time.Hour)), nil)
+	assert.NoError(t, err)
+
+	// A schedule change replaces the stored flight and is kept in the history
+	change := &pb.BookingStatusChange{
+		BookingId: int64(upcoming.ID),
+		From:      pb.BookingStatus_BOOKING_STATUS_PENDING,
+		To:        pb.BookingStatus_BOOKING_STATUS_CHANGED,
+		Changes:   []*pb.FlightChange{{Field: "departure_time", OldValue: "09:30", NewValue: "11:00"}},
+	}
+	err = RecordBookingStatus(db, upcoming, change, flight("178", now.Add(50*time.Hour)))
+	assert.NoError(t, err)
+	assert.Equal(t, pb.BookingStatus_BOOKING_STATUS_CHANGED, upcoming.Status)
+
+	stored, err := GetBooking(db, upcoming.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, pb.BookingStatus_BOOKING_STATUS_CHANGED, stored.Status)
+	assert.True(t, stored.DepartureTime.Equal(now.Add(50*time.Hour)))
+	assert.Equal(t, []int64{7, 8}, stored.Travelers)
+	storedFlight, err := stored.BookedFlight()
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(50*time.Hour), storedFlight.DepartureTime.AsTime())
+
+	err = RecordBookingStatus(db, cancelled, &pb.BookingStatusChange{
+		BookingId: int64(cancelled.ID),
+		From:      pb.BookingStatus_BOOKING_STATUS_PENDING,
+		To:        pb.BookingStatus_BOOKING_STATUS_CANCELLED,
+	}, flight("117", now.Add(72*time.Hour)))
+	assert.NoError(t, err)
+
+	history, err := BookingHistory(db, upcoming.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, history, 1) {
+		assert.Equal(t, pb.BookingStatus_BOOKING_STATUS_CHANGED, history[0].To)
+		assert.Equal(t, "11:00", history[0].Changes[0].NewValue)
+	}
+
+	// Only future, live bookings are polled
+	bookings, err := UpcomingBookings(db, now)
+	assert.NoError(t, err)
+	var refs []string
+	for _, b := range bookings {
+		refs = append(refs, b.Reference)
+	}
+	assert.Contains(t, refs, "ORDER1")
+	assert.NotContains(t, refs, past.Reference)
+	assert.NotContains(t, refs, cancelled.Reference)
+}
@@ -18,7 +18,7 @@ type APICache struct {
 func GetCacheEntry(db *gorm.DB, key string) (*APICache, error) {
 	var entry APICache
 	// Check for existence and expiry
-	err := db.Where("key = ? AND expires_at > ?", key, time.Now()).First(&entry).Error
+	err := db.Where("key = ? AND expires_at > ?", key, db.NowFunc()).First(&entry).Error
 	if err != nil {
 		return nil, err
 	}
@@ -30,8 +30,8 @@ func SetCacheEntry(db *gorm.DB, key string, value []byte, ttl time.Duration) err
 	entry := APICache{
 		Key:       key,
 		Value:     value,
-		CreatedAt: time.Now(),
-		ExpiresAt: time.Now().Add(ttl),
+		CreatedAt: db.NowFunc(),
+		ExpiresAt: db.NowFunc().Add(ttl),
 	}
 	// Upsert (On Conflict Do Update)
 	return db.Save(&entry).Error
@@ -39,5 +39,5 @@ func SetCacheEntry(db *gorm.DB, key string, value []byte, ttl time.Duration) err
 
 // CleanupCache removes expired entries
 func CleanupCache(db *gorm.DB) error {
-	return db.Where("expires_at < ?", time.Now()).Delete(&APICache{}).Error
+	return db.Where("expires_at < ?", db.NowFunc()).Delete(&APICache{}).Error
 }
@@ -94,6 +94,15 @@ func GetTravelGroup(db *gorm.DB, id uint) (*pb.TravelGroup, error) {
 	return group.ToPB(), nil
 }
 
+// GetTravelGroupMembers loads a group with its members only
+func GetTravelGroupMembers(db *gorm.DB, id uint) (*pb.TravelGroup, error) {
+	var group TravelGroup
+	if err := db.Preload("Members").First(&group, id).Error; err != nil {
+		return nil, err
+	}
+	return group.ToPB(), nil
+}
+
 func AddMember(db *gorm.DB, groupID uint, userID uint) error {
 	return db.Model(&TravelGroup{ID: groupID}).Association("Members").Append(&User{ID: userID})
 }
@@ -0,0 +1,90 @@
+package orm
+
+import (
+	"time"
+
+	"github.com/va6996/travelingman/pb"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Location is a city or airport learned from a provider lookup, kept so that the
+// autocomplete index knows it on the next start. A city and its main airport often
+// share a code, so the code is unique per kind.
+type Location struct {
+	Code      string `gorm:"primaryKey"`
+	Airport   bool   `gorm:"primaryKey"`
+	Name      string
+	City      string
+	CityCode  string
+	Country   string
+	Geocode   string
+	UpdatedAt time.Time
+}
+
+// LocationFromPB converts a provider result. Its first IATA code is its own; a city
+// without one is keyed by its city code.
+func LocationFromPB(loc *pb.Location, airport bool) *Location {
+	l := &Location{
+		Airport:  airport,
+		Name:     loc.Name,
+		City:     loc.City,
+		CityCode: loc.CityCode,
+		Country:  loc.Country,
+		Geocode:  loc.Geocode,
+	}
+	if len(loc.IataCodes) > 0 {
+		l.Code = loc.IataCodes[0]
+	}
+	if l.Code == "" && !airport {
+		l.Code = loc.CityCode
+	}
+	return l
+}
+
+// ToPB converts back to a pb.Location. Only an airport carries IATA codes, which is
+// how the place index tells the two apart.
+func (l *Location) ToPB() *pb.Location {
+	loc := &pb.Location{
+		Name:     l.Name,
+		City:     l.City,
+		CityCode: l.CityCode,
+		Country:  l.Country,
+		Geocode:  l.Geocode,
+	}
+	if l.Airport {
+		loc.IataCodes = []string{l.Code}
+	} else if loc.CityCode == "" {
+		loc.CityCode = l.Code
+	}
+	return loc
+}
+
+// SaveLocations upserts learned locations; ones without a code are skipped
+func SaveLocations(db *gorm.DB, locations []*Location) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, l := range locations {
+			if l.Code == "" {
+				continue
+			}
+			// Save would insert a city, whose Airport key is zero, every time
+			if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(l).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ListLocations returns every learned location
+func ListLocations(db *gorm.DB) ([]*pb.Location, error) {
+	var rows []Location
+	if err := db.Order("code, airport").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	locations := make([]*pb.Location, 0, len(rows))
+	for i := range rows {
+		locations = append(locations, rows[i].ToPB())
+	}
+	return locations, nil
+}
@@ -0,0 +1,38 @@
+package orm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/pb"
+)
+
+func TestSaveLocations(t *testing.T) {
+	db := SetupTestDB(t)
+
+	// Nice is both a city and its airport's code
+	city := &pb.Location{City: "NICE", Country: "FRANCE", IataCodes: []string{"NCE"}, Geocode: "43.7,7.26"}
+	airport := &pb.Location{Name: "COTE D'AZUR", City: "NICE", CityCode: "NCE", Country: "FRANCE", IataCodes: []string{"NCE"}}
+	assert.NoError(t, SaveLocations(db, []*Location{LocationFromPB(city, false), LocationFromPB(airport, true), LocationFromPB(&pb.Location{Name: "NOWHERE"}, true)}))
+
+	// Saving again updates rather than duplicates
+	airport.Name = "NICE COTE D'AZUR"
+	assert.NoError(t, SaveLocations(db, []*Location{LocationFromPB(airport, true)}))
+
+	var nice []*pb.Location
+	locations, err := ListLocations(db)
+	assert.NoError(t, err)
+	for _, loc := range locations {
+		if loc.City == "NICE" {
+			nice = append(nice, loc)
+		}
+	}
+	if assert.Len(t, nice, 2) {
+		// Only the airport keeps IATA codes; the city is known by its city code
+		assert.Empty(t, nice[0].IataCodes)
+		assert.Equal(t, "NCE", nice[0].CityCode)
+		assert.Equal(t, "43.7,7.26", nice[0].Geocode)
+		assert.Equal(t, []string{"NCE"}, nice[1].IataCodes)
+		assert.Equal(t, "NICE COTE D'AZUR", nice[1].Name)
+	}
+}
@@ -0,0 +1,94 @@
+package orm
+
+import (
+	"time"
+
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+// Payment is one user's share of a booking's total. Amount is a decimal string in
+// Currency, as providers send it.
+type Payment struct {
+	ID            uint `gorm:"primaryKey"`
+	BookingID     uint `gorm:"index"`
+	UserID        int64
+	Amount        string
+	Currency      string
+	Status        string
+	TransactionID string // Set once the share is settled
+	CreatedAt     time.Time
+}
+
+// Payment statuses. Shares start PENDING; when their booking is cancelled, unsettled
+// shares become CANCELLED and settled ones REFUND_DUE.
+const (
+	PaymentStatusPending   = "PENDING"
+	PaymentStatusCancelled = "CANCELLED"
+	PaymentStatusRefundDue = "REFUND_DUE"
+)
+
+// RecordPaymentSplit replaces a booking's shares with shares, in one transaction
+func RecordPaymentSplit(db *gorm.DB, bookingID uint, shares []*pb.Payment) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("booking_id = ?", bookingID).Delete(&Payment{}).Error; err != nil {
+			return err
+		}
+		for _, share := range shares {
+			status := share.Status
+			if status == "" {
+				status = PaymentStatusPending
+			}
+			row := &Payment{
+				BookingID:     bookingID,
+				UserID:        share.UserId,
+				Amount:        share.Amount,
+				Currency:      share.Currency,
+				Status:        status,
+				TransactionID: share.TransactionId,
+			}
+			if err := tx.Create(row).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CancelBookingPayments marks a cancelled booking's shares: unsettled ones are
+// cancelled and settled ones are due a refund
+func CancelBookingPayments(db *gorm.DB, bookingID uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&Payment{}).
+			Where("booking_id = ? AND transaction_id = ''", bookingID).
+			Update("status", PaymentStatusCancelled).Error; err != nil {
+			return err
+		}
+		return tx.Model(&Payment{}).
+			Where("booking_id = ? AND transaction_id <> ''", bookingID).
+			Update("status", PaymentStatusRefundDue).Error
+	})
+}
+
+// BookingPayments returns a booking's shares in the order they were recorded
+func BookingPayments(db *gorm.DB, bookingID uint) ([]*pb.Payment, error) {
+	var rows []Payment
+	if err := db.Where("booking_id = ?", bookingID).Order("id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	payments := make([]*pb.Payment, len(rows))
+	for i, row := range rows {
+		payments[i] = &pb.Payment{
+			Id:            int64(row.ID),
+			BookingId:     int64(row.BookingID),
+			UserId:        row.UserID,
+			Amount:        row.Amount,
+			Currency:      row.Currency,
+			Status:        row.Status,
+			TransactionId: row.TransactionID,
+			CreatedAt:     timestamppb.New(row.CreatedAt),
+		}
+	}
+	return payments, nil
+}
@@ -0,0 +1,53 @@
+package orm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/pb"
+)
+
+func TestPaymentSplit(t *testing.T) {
+	db := SetupTestDB(t)
+	booking, err := CreateBooking(db, "ORDER1", &pb.Flight{CarrierCode: "BA", FlightNumber: "178"}, nil)
+	assert.NoError(t, err)
+	other, err := CreateBooking(db, "ORDER2", &pb.Flight{CarrierCode: "BA", FlightNumber: "112"}, nil)
+	assert.NoError(t, err)
+
+	err = RecordPaymentSplit(db, booking.ID, []*pb.Payment{
+		{UserId: 1, Amount: "33.34", Currency: "USD"},
+		{UserId: 2, Amount: "33.33", Currency: "USD"},
+		{UserId: 3, Amount: "33.33", Currency: "USD", Status: "PAID", TransactionId: "tx-3"},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, RecordPaymentSplit(db, other.ID, []*pb.Payment{{UserId: 1, Amount: "80.00", Currency: "USD"}}))
+
+	// Shares come back in the order they were recorded, linked to their booking
+	shares, err := BookingPayments(db, booking.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, shares, 3) {
+		assert.Equal(t, int64(booking.ID), shares[0].BookingId)
+		assert.Equal(t, int64(1), shares[0].UserId)
+		assert.Equal(t, "33.34", shares[0].Amount)
+		assert.Equal(t, "USD", shares[0].Currency)
+		assert.Equal(t, PaymentStatusPending, shares[0].Status)
+		assert.NotZero(t, shares[0].Id)
+		assert.Equal(t, "PAID", shares[2].Status)
+		assert.Equal(t, "tx-3", shares[2].TransactionId)
+	}
+
+	// Recording again replaces the booking's shares only
+	assert.NoError(t, RecordPaymentSplit(db, booking.ID, []*pb.Payment{{UserId: 4, Amount: "100.01", Currency: "USD"}}))
+	shares, err = BookingPayments(db, booking.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, shares, 1) {
+		assert.Equal(t, int64(4), shares[0].UserId)
+	}
+	shares, err = BookingPayments(db, other.ID)
+	assert.NoError(t, err)
+	assert.Len(t, shares, 1)
+
+	shares, err = BookingPayments(db, 999)
+	assert.NoError(t, err)
+	assert.Empty(t, shares)
+}
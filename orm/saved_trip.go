@@ -0,0 +1,81 @@
+package orm
+
+import (
+	"errors"
+	"time"
+
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/proto"
+	"gorm.io/gorm"
+)
+
+// ErrVersionConflict is returned when a saved trip was modified since the caller last read it
+var ErrVersionConflict = errors.New("saved trip was modified by another request")
+
+// SavedTrip stores a user's itinerary as a serialized pb.Itinerary.
+// Version is bumped on every update and used for optimistic concurrency.
+type SavedTrip struct {
+	ID        uint `gorm:"primaryKey"`
+	Version   int64
+	Data      []byte
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// CreateSavedTrip stores a new trip and writes the assigned ID and version back to it
+func CreateSavedTrip(db *gorm.DB, it *pb.Itinerary) error {
+	it.Version = 1
+	data, err := proto.Marshal(it)
+	if err != nil {
+		return err
+	}
+	trip := &SavedTrip{Version: it.Version, Data: data}
+	if err := db.Create(trip).Error; err != nil {
+		return err
+	}
+	// Write back ID; the stored copy is refreshed so it carries the ID as well
+	it.Id = int64(trip.ID)
+	if data, err = proto.Marshal(it); err != nil {
+		return err
+	}
+	return db.Model(trip).Update("data", data).Error
+}
+
+// GetSavedTrip loads a saved trip by ID
+func GetSavedTrip(db *gorm.DB, id uint) (*pb.Itinerary, error) {
+	var trip SavedTrip
+	if err := db.First(&trip, id).Error; err != nil {
+		return nil, err
+	}
+	it := &pb.Itinerary{}
+	if err := proto.Unmarshal(trip.Data, it); err != nil {
+		return nil, err
+	}
+	it.Id = int64(trip.ID)
+	it.Version = trip.Version
+	return it, nil
+}
+
+// UpdateSavedTrip replaces a saved trip if its stored version still matches it.Version.
+// On success it.Version is bumped; otherwise ErrVersionConflict is returned.
+func UpdateSavedTrip(db *gorm.DB, it *pb.Itinerary) error {
+	expected := it.Version
+	next := proto.Clone(it).(*pb.Itinerary)
+	next.Version = expected + 1
+	data, err := proto.Marshal(next)
+	if err != nil {
+		return err
+	}
+
+	res := db.Model(&SavedTrip{}).
+		Where("id = ? AND version = ?", it.Id, expected).
+		Updates(map[string]interface{}{"version": next.Version, "data": data, "updated_at": db.NowFunc()})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+	it.Version = next.Version
+	return nil
+}
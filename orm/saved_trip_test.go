@@ -0,0 +1,66 @@
+package orm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/pb"
+)
+
+func TestSavedTripOptimisticConcurrency(t *testing.T) {
+	db := SetupTestDB(t)
+
+	trip := &pb.Itinerary{Title: "Paris Weekend"}
+	err := CreateSavedTrip(db, trip)
+	assert.NoError(t, err)
+	assert.NotZero(t, trip.Id)
+	assert.Equal(t, int64(1), trip.Version)
+
+	// Two clients read the same version
+	first, err := GetSavedTrip(db, uint(trip.Id))
+	assert.NoError(t, err)
+	second, err := GetSavedTrip(db, uint(trip.Id))
+	assert.NoError(t, err)
+
+	first.Title = "Paris Long Weekend"
+	err = UpdateSavedTrip(db, first)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), first.Version)
+
+	// The second write is based on a stale version and must be rejected
+	second.Title = "Lyon Weekend"
+	err = UpdateSavedTrip(db, second)
+	assert.ErrorIs(t, err, ErrVersionConflict)
+	assert.Equal(t, int64(1), second.Version)
+
+	fetched, err := GetSavedTrip(db, uint(trip.Id))
+	assert.NoError(t, err)
+	assert.Equal(t, "Paris Long Weekend", fetched.Title)
+	assert.Equal(t, int64(2), fetched.Version)
+}
+
+func TestSavedTripKeepsNodeNotes(t *testing.T) {
+	db := SetupTestDB(t)
+
+	trip := &pb.Itinerary{Title: "Paris Weekend", Graph: &pb.Graph{Nodes: []*pb.Node{{
+		Id:       "paris",
+		Location: &pb.Location{City: "Paris"},
+		SubGraph: &pb.Graph{Nodes: []*pb.Node{
+			{Id: "paris_day2_dinner", Location: &pb.Location{Name: "Le Comptoir"}, Notes: "Dinner at Le Comptoir on day 2"},
+		}},
+	}}}}
+	assert.NoError(t, CreateSavedTrip(db, trip))
+
+	fetched, err := GetSavedTrip(db, uint(trip.Id))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Dinner at Le Comptoir on day 2", fetched.GetGraph().GetNodes()[0].GetSubGraph().GetNodes()[0].GetNotes())
+	}
+
+	// Notes edited later are kept too
+	fetched.Graph.Nodes[0].Notes = "Ask the hotel about a late check-out"
+	assert.NoError(t, UpdateSavedTrip(db, fetched))
+	fetched, err = GetSavedTrip(db, uint(trip.Id))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Ask the hotel about a late check-out", fetched.Graph.Nodes[0].Notes)
+	}
+}
@@ -12,7 +12,7 @@ func SetupTestDB(t *testing.T) *gorm.DB {
 	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
 	assert.NoError(t, err)
 
-	err = db.AutoMigrate(&Itinerary{}, &Transport{}, &Accommodation{}, &Flight{}, &Train{}, &CarRental{}, &User{}, &TravelGroup{})
+	err = db.AutoMigrate(&Itinerary{}, &Transport{}, &Accommodation{}, &Flight{}, &Train{}, &CarRental{}, &User{}, &TravelGroup{}, &SavedTrip{}, &Trip{}, &TripDay{}, &Place{}, &TripTransport{}, &Booking{}, &BookingStatusChange{}, &Payment{}, &Location{})
 	assert.NoError(t, err)
 
 	return db
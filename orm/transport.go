@@ -38,7 +38,7 @@ type Transport struct {
 
 type FlightPreferences struct {
 	TravelClass                  int32
-	MaxStops                     int32
+	MaxStops                     *int32   // Nil for any number of stops
 	PreferredOriginAirports      []string `gorm:"type:text"` // Check GORM string array support or use serializer
 	PreferredDestinationAirports []string `gorm:"type:text"`
 }
@@ -0,0 +1,129 @@
+package orm
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Place types
+const (
+	PlaceTypeStop     = "stop"     // A city or airport the trip passes through or starts from
+	PlaceTypeStay     = "stay"     // A node with accommodation
+	PlaceTypeActivity = "activity" // A node in a city's day plan
+)
+
+// Trip is a planned itinerary laid out day by day for the calendar: each day lists the
+// places visited in order, and the legs between them are kept alongside. DetailsJSON
+// holds the itinerary without its graph, so the itinerary can be rebuilt exactly.
+type Trip struct {
+	ID          uint `gorm:"primaryKey"`
+	SavedTripID uint `gorm:"index"` // The saved plan this lays out, 0 for none
+	GroupID     uint
+	Name        string
+	Destination string
+	StartDate   time.Time
+	EndDate     time.Time
+	DetailsJSON string
+	CreatedAt   time.Time
+	// Relationships
+	Days       []TripDay       `gorm:"foreignKey:TripID;constraint:OnDelete:CASCADE"`
+	Transports []TripTransport `gorm:"foreignKey:TripID;constraint:OnDelete:CASCADE"`
+}
+
+// TripDay is one calendar day of a trip
+type TripDay struct {
+	ID        uint `gorm:"primaryKey"`
+	TripID    uint // FK
+	DayNumber int  // 1 for the first day
+	Date      time.Time
+	Location  string  // Where the traveller is that day
+	Places    []Place `gorm:"foreignKey:TripDayID;constraint:OnDelete:CASCADE"`
+}
+
+// Place is an itinerary node, listed on the day it is first visited.
+// DetailsJSON holds the node without its sub-graph, whose nodes are places of their own.
+type Place struct {
+	ID           uint `gorm:"primaryKey"`
+	TripDayID    uint // FK
+	NodeID       string
+	ParentNodeID string // Set for activities, to the node whose sub-graph they are in
+	Name         string
+	Address      string
+	PlaceType    string
+	Latitude     float64
+	Longitude    float64
+	Notes        string
+	VisitTime    time.Time
+	OrderIndex   int
+	DetailsJSON  string
+}
+
+// TripTransport is an itinerary edge. DetailsJSON holds the edge itself.
+type TripTransport struct {
+	ID            uint   `gorm:"primaryKey"`
+	TripID        uint   // FK
+	ParentNodeID  string // Set for legs between activities, as for Place
+	FromLocation  string
+	ToLocation    string
+	TransportMode string
+	DepartureTime time.Time
+	ArrivalTime   time.Time
+	DetailsJSON   string
+}
+
+// CreateTrip stores a trip with its days, places and transports
+func CreateTrip(db *gorm.DB, trip *Trip) error {
+	return db.Create(trip).Error
+}
+
+// GetTrip loads a trip with its days and places in order, and its transports
+func GetTrip(db *gorm.DB, id uint) (*Trip, error) {
+	var trip Trip
+	err := db.
+		Preload("Days", func(db *gorm.DB) *gorm.DB { return db.Order("day_number") }).
+		Preload("Days.Places", func(db *gorm.DB) *gorm.DB { return db.Order("order_index") }).
+		Preload("Transports", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		First(&trip, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &trip, nil
+}
+
+// GetPlanTrip loads the trip laid out for a saved plan, as GetTrip does. It returns
+// gorm.ErrRecordNotFound if the plan has none.
+func GetPlanTrip(db *gorm.DB, savedTripID uint) (*Trip, error) {
+	var trip Trip
+	if err := db.Select("id").Where("saved_trip_id = ?", savedTripID).Order("id desc").First(&trip).Error; err != nil {
+		return nil, err
+	}
+	return GetTrip(db, trip.ID)
+}
+
+// ReplacePlanTrip stores trip as the layout of its saved plan, deleting the one stored
+// before with its days, places and transports
+func ReplacePlanTrip(db *gorm.DB, trip *Trip) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var old []uint
+		if err := tx.Model(&Trip{}).Where("saved_trip_id = ?", trip.SavedTripID).Pluck("id", &old).Error; err != nil {
+			return err
+		}
+		if len(old) > 0 {
+			days := tx.Model(&TripDay{}).Select("id").Where("trip_id IN ?", old)
+			if err := tx.Where("trip_day_id IN (?)", days).Delete(&Place{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("trip_id IN ?", old).Delete(&TripDay{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("trip_id IN ?", old).Delete(&TripTransport{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Delete(&Trip{}, old).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(trip).Error
+	})
+}
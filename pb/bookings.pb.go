@@ -132,6 +132,118 @@ func (Plugin) EnumDescriptor() ([]byte, []int) {
 	return file_protos_bookings_proto_rawDescGZIP(), []int{1}
 }
 
+// BookingStatus is where a booking is in its lifecycle
+type BookingStatus int32
+
+const (
+	BookingStatus_BOOKING_STATUS_UNSPECIFIED BookingStatus = 0
+	BookingStatus_BOOKING_STATUS_PENDING     BookingStatus = 1 // Requested, not yet confirmed by the provider
+	BookingStatus_BOOKING_STATUS_CONFIRMED   BookingStatus = 2 // Confirmed, tickets not yet issued
+	BookingStatus_BOOKING_STATUS_TICKETED    BookingStatus = 3 // Tickets issued
+	BookingStatus_BOOKING_STATUS_CANCELLED   BookingStatus = 4 // Cancelled, e.g. by the airline
+	BookingStatus_BOOKING_STATUS_CHANGED     BookingStatus = 5 // The provider changed the schedule since it was last checked
+)
+
+// Enum value maps for BookingStatus.
+var (
+	BookingStatus_name = map[int32]string{
+		0: "BOOKING_STATUS_UNSPECIFIED",
+		1: "BOOKING_STATUS_PENDING",
+		2: "BOOKING_STATUS_CONFIRMED",
+		3: "BOOKING_STATUS_TICKETED",
+		4: "BOOKING_STATUS_CANCELLED",
+		5: "BOOKING_STATUS_CHANGED",
+	}
+	BookingStatus_value = map[string]int32{
+		"BOOKING_STATUS_UNSPECIFIED": 0,
+		"BOOKING_STATUS_PENDING":     1,
+		"BOOKING_STATUS_CONFIRMED":   2,
+		"BOOKING_STATUS_TICKETED":    3,
+		"BOOKING_STATUS_CANCELLED":   4,
+		"BOOKING_STATUS_CHANGED":     5,
+	}
+)
+
+func (x BookingStatus) Enum() *BookingStatus {
+	p := new(BookingStatus)
+	*p = x
+	return p
+}
+
+func (x BookingStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (BookingStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_protos_bookings_proto_enumTypes[2].Descriptor()
+}
+
+func (BookingStatus) Type() protoreflect.EnumType {
+	return &file_protos_bookings_proto_enumTypes[2]
+}
+
+func (x BookingStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use BookingStatus.Descriptor instead.
+func (BookingStatus) EnumDescriptor() ([]byte, []int) {
+	return file_protos_bookings_proto_rawDescGZIP(), []int{2}
+}
+
+// SplitMode is how a booking's total is divided among the users paying for it
+type SplitMode int32
+
+const (
+	SplitMode_SPLIT_MODE_UNSPECIFIED SplitMode = 0
+	SplitMode_SPLIT_MODE_EQUAL       SplitMode = 1 // Equally among user_ids
+	SplitMode_SPLIT_MODE_BY_TRAVELER SplitMode = 2 // Each traveler owes their own fare
+	SplitMode_SPLIT_MODE_EXPLICIT    SplitMode = 3 // The amounts in shares
+)
+
+// Enum value maps for SplitMode.
+var (
+	SplitMode_name = map[int32]string{
+		0: "SPLIT_MODE_UNSPECIFIED",
+		1: "SPLIT_MODE_EQUAL",
+		2: "SPLIT_MODE_BY_TRAVELER",
+		3: "SPLIT_MODE_EXPLICIT",
+	}
+	SplitMode_value = map[string]int32{
+		"SPLIT_MODE_UNSPECIFIED": 0,
+		"SPLIT_MODE_EQUAL":       1,
+		"SPLIT_MODE_BY_TRAVELER": 2,
+		"SPLIT_MODE_EXPLICIT":    3,
+	}
+)
+
+func (x SplitMode) Enum() *SplitMode {
+	p := new(SplitMode)
+	*p = x
+	return p
+}
+
+func (x SplitMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SplitMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_protos_bookings_proto_enumTypes[3].Descriptor()
+}
+
+func (SplitMode) Type() protoreflect.EnumType {
+	return &file_protos_bookings_proto_enumTypes[3]
+}
+
+func (x SplitMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SplitMode.Descriptor instead.
+func (SplitMode) EnumDescriptor() ([]byte, []int) {
+	return file_protos_bookings_proto_rawDescGZIP(), []int{3}
+}
+
 type FlightOffer struct {
 	state            protoimpl.MessageState `protogen:"open.v1"`
 	Id               int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -364,6 +476,152 @@ func (x *HotelOffer) GetOfferId() string {
 	return ""
 }
 
+// FlightChange is one difference between a booked flight and the provider's current order
+type FlightChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Segment       int32                  `protobuf:"varint,1,opt,name=segment,proto3" json:"segment,omitempty"` // Index of the segment in the flight
+	Field         string                 `protobuf:"bytes,2,opt,name=field,proto3" json:"field,omitempty"`      // e.g. departure_time, arrival_time, flight_number
+	OldValue      string                 `protobuf:"bytes,3,opt,name=old_value,json=oldValue,proto3" json:"old_value,omitempty"`
+	NewValue      string                 `protobuf:"bytes,4,opt,name=new_value,json=newValue,proto3" json:"new_value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FlightChange) Reset() {
+	*x = FlightChange{}
+	mi := &file_protos_bookings_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FlightChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FlightChange) ProtoMessage() {}
+
+func (x *FlightChange) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_bookings_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FlightChange.ProtoReflect.Descriptor instead.
+func (*FlightChange) Descriptor() ([]byte, []int) {
+	return file_protos_bookings_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *FlightChange) GetSegment() int32 {
+	if x != nil {
+		return x.Segment
+	}
+	return 0
+}
+
+func (x *FlightChange) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *FlightChange) GetOldValue() string {
+	if x != nil {
+		return x.OldValue
+	}
+	return ""
+}
+
+func (x *FlightChange) GetNewValue() string {
+	if x != nil {
+		return x.NewValue
+	}
+	return ""
+}
+
+// BookingStatusChange records a status transition and the schedule changes behind it
+type BookingStatusChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BookingId     int64                  `protobuf:"varint,1,opt,name=booking_id,json=bookingId,proto3" json:"booking_id,omitempty"`
+	From          BookingStatus          `protobuf:"varint,2,opt,name=from,proto3,enum=travelingman.BookingStatus" json:"from,omitempty"`
+	To            BookingStatus          `protobuf:"varint,3,opt,name=to,proto3,enum=travelingman.BookingStatus" json:"to,omitempty"`
+	Changes       []*FlightChange        `protobuf:"bytes,4,rep,name=changes,proto3" json:"changes,omitempty"`
+	ChangedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=changed_at,json=changedAt,proto3" json:"changed_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BookingStatusChange) Reset() {
+	*x = BookingStatusChange{}
+	mi := &file_protos_bookings_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BookingStatusChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BookingStatusChange) ProtoMessage() {}
+
+func (x *BookingStatusChange) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_bookings_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BookingStatusChange.ProtoReflect.Descriptor instead.
+func (*BookingStatusChange) Descriptor() ([]byte, []int) {
+	return file_protos_bookings_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *BookingStatusChange) GetBookingId() int64 {
+	if x != nil {
+		return x.BookingId
+	}
+	return 0
+}
+
+func (x *BookingStatusChange) GetFrom() BookingStatus {
+	if x != nil {
+		return x.From
+	}
+	return BookingStatus_BOOKING_STATUS_UNSPECIFIED
+}
+
+func (x *BookingStatusChange) GetTo() BookingStatus {
+	if x != nil {
+		return x.To
+	}
+	return BookingStatus_BOOKING_STATUS_UNSPECIFIED
+}
+
+func (x *BookingStatusChange) GetChanges() []*FlightChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+func (x *BookingStatusChange) GetChangedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ChangedAt
+	}
+	return nil
+}
+
 type Booking struct {
 	state                    protoimpl.MessageState `protogen:"open.v1"`
 	Id                       int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -379,7 +637,7 @@ type Booking struct {
 
 func (x *Booking) Reset() {
 	*x = Booking{}
-	mi := &file_protos_bookings_proto_msgTypes[2]
+	mi := &file_protos_bookings_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -391,7 +649,7 @@ func (x *Booking) String() string {
 func (*Booking) ProtoMessage() {}
 
 func (x *Booking) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_bookings_proto_msgTypes[2]
+	mi := &file_protos_bookings_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -404,7 +662,7 @@ func (x *Booking) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Booking.ProtoReflect.Descriptor instead.
 func (*Booking) Descriptor() ([]byte, []int) {
-	return file_protos_bookings_proto_rawDescGZIP(), []int{2}
+	return file_protos_bookings_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *Booking) GetId() int64 {
@@ -472,7 +730,7 @@ type Payment struct {
 
 func (x *Payment) Reset() {
 	*x = Payment{}
-	mi := &file_protos_bookings_proto_msgTypes[3]
+	mi := &file_protos_bookings_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -484,7 +742,7 @@ func (x *Payment) String() string {
 func (*Payment) ProtoMessage() {}
 
 func (x *Payment) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_bookings_proto_msgTypes[3]
+	mi := &file_protos_bookings_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -497,7 +755,7 @@ func (x *Payment) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Payment.ProtoReflect.Descriptor instead.
 func (*Payment) Descriptor() ([]byte, []int) {
-	return file_protos_bookings_proto_rawDescGZIP(), []int{3}
+	return file_protos_bookings_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *Payment) GetId() int64 {
@@ -556,6 +814,68 @@ func (x *Payment) GetCreatedAt() *timestamppb.Timestamp {
 	return nil
 }
 
+// PaymentSplit records who owes what of a booking paid with one card. It is
+// bookkeeping only; no money is moved.
+type PaymentSplit struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Mode          SplitMode              `protobuf:"varint,1,opt,name=mode,proto3,enum=travelingman.SplitMode" json:"mode,omitempty"`
+	UserIds       []int64                `protobuf:"varint,2,rep,packed,name=user_ids,json=userIds,proto3" json:"user_ids,omitempty"` // Who shares an equal split, in remainder order
+	Shares        []*Payment             `protobuf:"bytes,3,rep,name=shares,proto3" json:"shares,omitempty"`                          // Amount per user_id for an explicit split
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PaymentSplit) Reset() {
+	*x = PaymentSplit{}
+	mi := &file_protos_bookings_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PaymentSplit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PaymentSplit) ProtoMessage() {}
+
+func (x *PaymentSplit) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_bookings_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PaymentSplit.ProtoReflect.Descriptor instead.
+func (*PaymentSplit) Descriptor() ([]byte, []int) {
+	return file_protos_bookings_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *PaymentSplit) GetMode() SplitMode {
+	if x != nil {
+		return x.Mode
+	}
+	return SplitMode_SPLIT_MODE_UNSPECIFIED
+}
+
+func (x *PaymentSplit) GetUserIds() []int64 {
+	if x != nil {
+		return x.UserIds
+	}
+	return nil
+}
+
+func (x *PaymentSplit) GetShares() []*Payment {
+	if x != nil {
+		return x.Shares
+	}
+	return nil
+}
+
 var File_protos_bookings_proto protoreflect.FileDescriptor
 
 const file_protos_bookings_proto_rawDesc = "" +
@@ -587,7 +907,20 @@ const file_protos_bookings_proto_rawDesc = "" +
 	"\vprice_total\x18\x06 \x01(\tR\n" +
 	"priceTotal\x12\x1a\n" +
 	"\bcurrency\x18\a \x01(\tR\bcurrency\x12\x19\n" +
-	"\boffer_id\x18\b \x01(\tR\aofferId\"\xa0\x02\n" +
+	"\boffer_id\x18\b \x01(\tR\aofferId\"x\n" +
+	"\fFlightChange\x12\x18\n" +
+	"\asegment\x18\x01 \x01(\x05R\asegment\x12\x14\n" +
+	"\x05field\x18\x02 \x01(\tR\x05field\x12\x1b\n" +
+	"\told_value\x18\x03 \x01(\tR\boldValue\x12\x1b\n" +
+	"\tnew_value\x18\x04 \x01(\tR\bnewValue\"\x83\x02\n" +
+	"\x13BookingStatusChange\x12\x1d\n" +
+	"\n" +
+	"booking_id\x18\x01 \x01(\x03R\tbookingId\x12/\n" +
+	"\x04from\x18\x02 \x01(\x0e2\x1b.travelingman.BookingStatusR\x04from\x12+\n" +
+	"\x02to\x18\x03 \x01(\x0e2\x1b.travelingman.BookingStatusR\x02to\x124\n" +
+	"\achanges\x18\x04 \x03(\v2\x1a.travelingman.FlightChangeR\achanges\x129\n" +
+	"\n" +
+	"changed_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tchangedAt\"\xa0\x02\n" +
 	"\aBooking\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x17\n" +
 	"\auser_id\x18\x02 \x01(\x03R\x06userId\x12-\n" +
@@ -607,7 +940,11 @@ const file_protos_bookings_proto_rawDesc = "" +
 	"\x06status\x18\x06 \x01(\tR\x06status\x12%\n" +
 	"\x0etransaction_id\x18\a \x01(\tR\rtransactionId\x129\n" +
 	"\n" +
-	"created_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt*\x8e\x01\n" +
+	"created_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\x85\x01\n" +
+	"\fPaymentSplit\x12+\n" +
+	"\x04mode\x18\x01 \x01(\x0e2\x17.travelingman.SplitModeR\x04mode\x12\x19\n" +
+	"\buser_ids\x18\x02 \x03(\x03R\auserIds\x12-\n" +
+	"\x06shares\x18\x03 \x03(\v2\x15.travelingman.PaymentR\x06shares*\x8e\x01\n" +
 	"\vBookingType\x12\x1c\n" +
 	"\x18BOOKING_TYPE_UNSPECIFIED\x10\x00\x12\x17\n" +
 	"\x13BOOKING_TYPE_FLIGHT\x10\x01\x12\x16\n" +
@@ -619,7 +956,19 @@ const file_protos_bookings_proto_rawDesc = "" +
 	"\x0ePLUGIN_AMADEUS\x10\x01\x12\x16\n" +
 	"\x12PLUGIN_BOOKING_COM\x10\x02\x12\x11\n" +
 	"\rPLUGIN_MANUAL\x10\x03\x12\x10\n" +
-	"\fPLUGIN_OTHER\x10\x04B#Z!github.com/va6996/travelingman/pbb\x06proto3"
+	"\fPLUGIN_OTHER\x10\x04*\xc0\x01\n" +
+	"\rBookingStatus\x12\x1e\n" +
+	"\x1aBOOKING_STATUS_UNSPECIFIED\x10\x00\x12\x1a\n" +
+	"\x16BOOKING_STATUS_PENDING\x10\x01\x12\x1c\n" +
+	"\x18BOOKING_STATUS_CONFIRMED\x10\x02\x12\x1b\n" +
+	"\x17BOOKING_STATUS_TICKETED\x10\x03\x12\x1c\n" +
+	"\x18BOOKING_STATUS_CANCELLED\x10\x04\x12\x1a\n" +
+	"\x16BOOKING_STATUS_CHANGED\x10\x05*r\n" +
+	"\tSplitMode\x12\x1a\n" +
+	"\x16SPLIT_MODE_UNSPECIFIED\x10\x00\x12\x14\n" +
+	"\x10SPLIT_MODE_EQUAL\x10\x01\x12\x1a\n" +
+	"\x16SPLIT_MODE_BY_TRAVELER\x10\x02\x12\x17\n" +
+	"\x13SPLIT_MODE_EXPLICIT\x10\x03B#Z!github.com/va6996/travelingman/pbb\x06proto3"
 
 var (
 	file_protos_bookings_proto_rawDescOnce sync.Once
@@ -633,31 +982,42 @@ func file_protos_bookings_proto_rawDescGZIP() []byte {
 	return file_protos_bookings_proto_rawDescData
 }
 
-var file_protos_bookings_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_protos_bookings_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_protos_bookings_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_protos_bookings_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
 var file_protos_bookings_proto_goTypes = []any{
 	(BookingType)(0),              // 0: travelingman.BookingType
 	(Plugin)(0),                   // 1: travelingman.Plugin
-	(*FlightOffer)(nil),           // 2: travelingman.FlightOffer
-	(*HotelOffer)(nil),            // 3: travelingman.HotelOffer
-	(*Booking)(nil),               // 4: travelingman.Booking
-	(*Payment)(nil),               // 5: travelingman.Payment
-	(*timestamppb.Timestamp)(nil), // 6: google.protobuf.Timestamp
+	(BookingStatus)(0),            // 2: travelingman.BookingStatus
+	(SplitMode)(0),                // 3: travelingman.SplitMode
+	(*FlightOffer)(nil),           // 4: travelingman.FlightOffer
+	(*HotelOffer)(nil),            // 5: travelingman.HotelOffer
+	(*FlightChange)(nil),          // 6: travelingman.FlightChange
+	(*BookingStatusChange)(nil),   // 7: travelingman.BookingStatusChange
+	(*Booking)(nil),               // 8: travelingman.Booking
+	(*Payment)(nil),               // 9: travelingman.Payment
+	(*PaymentSplit)(nil),          // 10: travelingman.PaymentSplit
+	(*timestamppb.Timestamp)(nil), // 11: google.protobuf.Timestamp
 }
 var file_protos_bookings_proto_depIdxs = []int32{
-	6, // 0: travelingman.FlightOffer.departure_time:type_name -> google.protobuf.Timestamp
-	6, // 1: travelingman.FlightOffer.arrival_time:type_name -> google.protobuf.Timestamp
-	6, // 2: travelingman.HotelOffer.check_in:type_name -> google.protobuf.Timestamp
-	6, // 3: travelingman.HotelOffer.check_out:type_name -> google.protobuf.Timestamp
-	0, // 4: travelingman.Booking.type:type_name -> travelingman.BookingType
-	1, // 5: travelingman.Booking.plugin:type_name -> travelingman.Plugin
-	6, // 6: travelingman.Booking.created_at:type_name -> google.protobuf.Timestamp
-	6, // 7: travelingman.Payment.created_at:type_name -> google.protobuf.Timestamp
-	8, // [8:8] is the sub-list for method output_type
-	8, // [8:8] is the sub-list for method input_type
-	8, // [8:8] is the sub-list for extension type_name
-	8, // [8:8] is the sub-list for extension extendee
-	0, // [0:8] is the sub-list for field type_name
+	11, // 0: travelingman.FlightOffer.departure_time:type_name -> google.protobuf.Timestamp
+	11, // 1: travelingman.FlightOffer.arrival_time:type_name -> google.protobuf.Timestamp
+	11, // 2: travelingman.HotelOffer.check_in:type_name -> google.protobuf.Timestamp
+	11, // 3: travelingman.HotelOffer.check_out:type_name -> google.protobuf.Timestamp
+	2,  // 4: travelingman.BookingStatusChange.from:type_name -> travelingman.BookingStatus
+	2,  // 5: travelingman.BookingStatusChange.to:type_name -> travelingman.BookingStatus
+	6,  // 6: travelingman.BookingStatusChange.changes:type_name -> travelingman.FlightChange
+	11, // 7: travelingman.BookingStatusChange.changed_at:type_name -> google.protobuf.Timestamp
+	0,  // 8: travelingman.Booking.type:type_name -> travelingman.BookingType
+	1,  // 9: travelingman.Booking.plugin:type_name -> travelingman.Plugin
+	11, // 10: travelingman.Booking.created_at:type_name -> google.protobuf.Timestamp
+	11, // 11: travelingman.Payment.created_at:type_name -> google.protobuf.Timestamp
+	3,  // 12: travelingman.PaymentSplit.mode:type_name -> travelingman.SplitMode
+	9,  // 13: travelingman.PaymentSplit.shares:type_name -> travelingman.Payment
+	14, // [14:14] is the sub-list for method output_type
+	14, // [14:14] is the sub-list for method input_type
+	14, // [14:14] is the sub-list for extension type_name
+	14, // [14:14] is the sub-list for extension extendee
+	0,  // [0:14] is the sub-list for field type_name
 }
 
 func init() { file_protos_bookings_proto_init() }
@@ -670,8 +1030,8 @@ func file_protos_bookings_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_protos_bookings_proto_rawDesc), len(file_protos_bookings_proto_rawDesc)),
-			NumEnums:      2,
-			NumMessages:   4,
+			NumEnums:      4,
+			NumMessages:   7,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -91,6 +91,7 @@ type Node struct {
 	Stay          *Accommodation         `protobuf:"bytes,5,opt,name=stay,proto3" json:"stay,omitempty"`                                        // Hotel/accommodation info (from Accommodation)
 	StayOptions   []*Accommodation       `protobuf:"bytes,6,rep,name=stayOptions,proto3" json:"stayOptions,omitempty"`                          // List of possible accommodations
 	SubGraph      *Graph                 `protobuf:"bytes,7,opt,name=sub_graph,json=subGraph,proto3" json:"sub_graph,omitempty"`                // Sub-graph for daily activities
+	Notes         string                 `protobuf:"bytes,9,opt,name=notes,proto3" json:"notes,omitempty"`                                      // The user's own plans here, e.g. "dinner at Le Comptoir"; kept as written
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -174,6 +175,13 @@ func (x *Node) GetSubGraph() *Graph {
 	return nil
 }
 
+func (x *Node) GetNotes() string {
+	if x != nil {
+		return x.Notes
+	}
+	return ""
+}
+
 // Edge represents transportation between two locations
 // It maps to protobuf structures: Transport
 type Edge struct {
@@ -183,6 +191,7 @@ type Edge struct {
 	DurationSeconds  int64                  `protobuf:"varint,3,opt,name=duration_seconds,json=durationSeconds,proto3" json:"duration_seconds,omitempty"` // Duration of travel in seconds
 	Transport        *Transport             `protobuf:"bytes,4,opt,name=transport,proto3" json:"transport,omitempty"`                                     // Full Transport struct from Transport
 	TransportOptions []*Transport           `protobuf:"bytes,5,rep,name=transportOptions,proto3" json:"transportOptions,omitempty"`                       // List of possible transports
+	OptionGroups     []*OptionGroup         `protobuf:"bytes,6,rep,name=option_groups,json=optionGroups,proto3" json:"option_groups,omitempty"`           // transportOptions grouped for display; selection is unaffected
 	unknownFields    protoimpl.UnknownFields
 	sizeCache        protoimpl.SizeCache
 }
@@ -252,6 +261,13 @@ func (x *Edge) GetTransportOptions() []*Transport {
 	return nil
 }
 
+func (x *Edge) GetOptionGroups() []*OptionGroup {
+	if x != nil {
+		return x.OptionGroups
+	}
+	return nil
+}
+
 // Graph represents the complete graph structure of a user's itinerary
 type Graph struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -327,6 +343,11 @@ type Itinerary struct {
 	Tags          []string               `protobuf:"bytes,10,rep,name=tags,proto3" json:"tags,omitempty"`
 	JourneyType   JourneyType            `protobuf:"varint,11,opt,name=journey_type,json=journeyType,proto3,enum=travelingman.JourneyType" json:"journey_type,omitempty"`
 	Error         *Error                 `protobuf:"bytes,12,opt,name=error,proto3" json:"error,omitempty"`
+	Version       int64                  `protobuf:"varint,13,opt,name=version,proto3" json:"version,omitempty"`                                 // Optimistic concurrency token for saved trips
+	Stats         *ItineraryStats        `protobuf:"bytes,14,opt,name=stats,proto3" json:"stats,omitempty"`                                      // Time split between travelling and being there
+	Budget        *Cost                  `protobuf:"bytes,15,opt,name=budget,proto3" json:"budget,omitempty"`                                    // Total the travellers want to spend on the whole trip, if they said
+	BudgetSplit   *BudgetSplit           `protobuf:"bytes,16,opt,name=budget_split,json=budgetSplit,proto3" json:"budget_split,omitempty"`       // Suggested split of the budget across flights and hotels
+	CostBreakdown *CostBreakdown         `protobuf:"bytes,17,opt,name=cost_breakdown,json=costBreakdown,proto3" json:"cost_breakdown,omitempty"` // Where the money goes, from the selected options
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -445,11 +466,433 @@ func (x *Itinerary) GetError() *Error {
 	return nil
 }
 
+func (x *Itinerary) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Itinerary) GetStats() *ItineraryStats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+func (x *Itinerary) GetBudget() *Cost {
+	if x != nil {
+		return x.Budget
+	}
+	return nil
+}
+
+func (x *Itinerary) GetBudgetSplit() *BudgetSplit {
+	if x != nil {
+		return x.BudgetSplit
+	}
+	return nil
+}
+
+func (x *Itinerary) GetCostBreakdown() *CostBreakdown {
+	if x != nil {
+		return x.CostBreakdown
+	}
+	return nil
+}
+
+// ItineraryStats summarizes how much of a trip is spent in transit versus at the destinations
+type ItineraryStats struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	TransitSeconds     int64                  `protobuf:"varint,1,opt,name=transit_seconds,json=transitSeconds,proto3" json:"transit_seconds,omitempty"`             // Transport time incl. layovers and short transfers between legs
+	DestinationSeconds int64                  `protobuf:"varint,2,opt,name=destination_seconds,json=destinationSeconds,proto3" json:"destination_seconds,omitempty"` // Time at destinations (node windows minus transit)
+	HopCount           int32                  `protobuf:"varint,3,opt,name=hop_count,json=hopCount,proto3" json:"hop_count,omitempty"`                               // Number of transport legs, including sub-graphs
+	TransitRatio       float64                `protobuf:"fixed64,4,opt,name=transit_ratio,json=transitRatio,proto3" json:"transit_ratio,omitempty"`                  // transit / (transit + destination)
+	Partial            bool                   `protobuf:"varint,5,opt,name=partial,proto3" json:"partial,omitempty"`                                                 // Some times were missing; totals are lower bounds
+	Destinations       []*DestinationTime     `protobuf:"bytes,6,rep,name=destinations,proto3" json:"destinations,omitempty"`                                        // Time at each destination, in visiting order
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *ItineraryStats) Reset() {
+	*x = ItineraryStats{}
+	mi := &file_protos_graph_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ItineraryStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ItineraryStats) ProtoMessage() {}
+
+func (x *ItineraryStats) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_graph_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ItineraryStats.ProtoReflect.Descriptor instead.
+func (*ItineraryStats) Descriptor() ([]byte, []int) {
+	return file_protos_graph_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ItineraryStats) GetTransitSeconds() int64 {
+	if x != nil {
+		return x.TransitSeconds
+	}
+	return 0
+}
+
+func (x *ItineraryStats) GetDestinationSeconds() int64 {
+	if x != nil {
+		return x.DestinationSeconds
+	}
+	return 0
+}
+
+func (x *ItineraryStats) GetHopCount() int32 {
+	if x != nil {
+		return x.HopCount
+	}
+	return 0
+}
+
+func (x *ItineraryStats) GetTransitRatio() float64 {
+	if x != nil {
+		return x.TransitRatio
+	}
+	return 0
+}
+
+func (x *ItineraryStats) GetPartial() bool {
+	if x != nil {
+		return x.Partial
+	}
+	return false
+}
+
+func (x *ItineraryStats) GetDestinations() []*DestinationTime {
+	if x != nil {
+		return x.Destinations
+	}
+	return nil
+}
+
+type DestinationTime struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NodeId        string                 `protobuf:"bytes,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	Place         string                 `protobuf:"bytes,2,opt,name=place,proto3" json:"place,omitempty"` // City or name of the node's location
+	Seconds       int64                  `protobuf:"varint,3,opt,name=seconds,proto3" json:"seconds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DestinationTime) Reset() {
+	*x = DestinationTime{}
+	mi := &file_protos_graph_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DestinationTime) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DestinationTime) ProtoMessage() {}
+
+func (x *DestinationTime) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_graph_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DestinationTime.ProtoReflect.Descriptor instead.
+func (*DestinationTime) Descriptor() ([]byte, []int) {
+	return file_protos_graph_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DestinationTime) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *DestinationTime) GetPlace() string {
+	if x != nil {
+		return x.Place
+	}
+	return ""
+}
+
+func (x *DestinationTime) GetSeconds() int64 {
+	if x != nil {
+		return x.Seconds
+	}
+	return 0
+}
+
+// BudgetSplit is how a trip's budget is suggested to be spent. The per-flight and
+// per-night caps derived from it are passed to the searches as soft filters.
+type BudgetSplit struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Flights       *Cost                  `protobuf:"bytes,1,opt,name=flights,proto3" json:"flights,omitempty"` // All flights, for all travellers
+	Hotels        *Cost                  `protobuf:"bytes,2,opt,name=hotels,proto3" json:"hotels,omitempty"`   // All nights
+	Buffer        *Cost                  `protobuf:"bytes,3,opt,name=buffer,proto3" json:"buffer,omitempty"`   // Held back for everything else
+	FlightLegs    int32                  `protobuf:"varint,4,opt,name=flight_legs,json=flightLegs,proto3" json:"flight_legs,omitempty"`
+	Nights        int32                  `protobuf:"varint,5,opt,name=nights,proto3" json:"nights,omitempty"`
+	PriceIndex    float64                `protobuf:"fixed64,6,opt,name=price_index,json=priceIndex,proto3" json:"price_index,omitempty"` // Price level of the destinations, 1 is average
+	Note          string                 `protobuf:"bytes,7,opt,name=note,proto3" json:"note,omitempty"`                                 // How the split was rebalanced after verification, if it was
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BudgetSplit) Reset() {
+	*x = BudgetSplit{}
+	mi := &file_protos_graph_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BudgetSplit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BudgetSplit) ProtoMessage() {}
+
+func (x *BudgetSplit) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_graph_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BudgetSplit.ProtoReflect.Descriptor instead.
+func (*BudgetSplit) Descriptor() ([]byte, []int) {
+	return file_protos_graph_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *BudgetSplit) GetFlights() *Cost {
+	if x != nil {
+		return x.Flights
+	}
+	return nil
+}
+
+func (x *BudgetSplit) GetHotels() *Cost {
+	if x != nil {
+		return x.Hotels
+	}
+	return nil
+}
+
+func (x *BudgetSplit) GetBuffer() *Cost {
+	if x != nil {
+		return x.Buffer
+	}
+	return nil
+}
+
+func (x *BudgetSplit) GetFlightLegs() int32 {
+	if x != nil {
+		return x.FlightLegs
+	}
+	return 0
+}
+
+func (x *BudgetSplit) GetNights() int32 {
+	if x != nil {
+		return x.Nights
+	}
+	return 0
+}
+
+func (x *BudgetSplit) GetPriceIndex() float64 {
+	if x != nil {
+		return x.PriceIndex
+	}
+	return 0
+}
+
+func (x *BudgetSplit) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+// CostBreakdown splits an itinerary's total over the selected options. Costs in another
+// currency than the first one seen cannot be added up and are left out.
+type CostBreakdown struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transport     *Cost                  `protobuf:"bytes,1,opt,name=transport,proto3" json:"transport,omitempty"`         // Flights, trains and car rentals
+	Accommodation *Cost                  `protobuf:"bytes,2,opt,name=accommodation,proto3" json:"accommodation,omitempty"` // Stays
+	Ancillaries   *Cost                  `protobuf:"bytes,3,opt,name=ancillaries,proto3" json:"ancillaries,omitempty"`     // Flight extras such as bags and seats
+	Total         *Cost                  `protobuf:"bytes,4,opt,name=total,proto3" json:"total,omitempty"`                 // Sum of the above
+	Partial       bool                   `protobuf:"varint,5,opt,name=partial,proto3" json:"partial,omitempty"`            // Some costs were in another currency and are left out
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CostBreakdown) Reset() {
+	*x = CostBreakdown{}
+	mi := &file_protos_graph_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CostBreakdown) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CostBreakdown) ProtoMessage() {}
+
+func (x *CostBreakdown) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_graph_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CostBreakdown.ProtoReflect.Descriptor instead.
+func (*CostBreakdown) Descriptor() ([]byte, []int) {
+	return file_protos_graph_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CostBreakdown) GetTransport() *Cost {
+	if x != nil {
+		return x.Transport
+	}
+	return nil
+}
+
+func (x *CostBreakdown) GetAccommodation() *Cost {
+	if x != nil {
+		return x.Accommodation
+	}
+	return nil
+}
+
+func (x *CostBreakdown) GetAncillaries() *Cost {
+	if x != nil {
+		return x.Ancillaries
+	}
+	return nil
+}
+
+func (x *CostBreakdown) GetTotal() *Cost {
+	if x != nil {
+		return x.Total
+	}
+	return nil
+}
+
+func (x *CostBreakdown) GetPartial() bool {
+	if x != nil {
+		return x.Partial
+	}
+	return false
+}
+
+// OptionGroup collects transport options on an edge that depart in the same time window
+// with the same carrier. Options are referred to by ID; all of them stay in transportOptions.
+type OptionGroup struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TimeWindow    string                 `protobuf:"bytes,1,opt,name=time_window,json=timeWindow,proto3" json:"time_window,omitempty"`       // early_morning, morning, afternoon, evening or night, in local time
+	Carrier       string                 `protobuf:"bytes,2,opt,name=carrier,proto3" json:"carrier,omitempty"`                               // Carrier code shared by the options
+	VisibleIds    []string               `protobuf:"bytes,3,rep,name=visible_ids,json=visibleIds,proto3" json:"visible_ids,omitempty"`       // Options shown: those tagged Cheapest, Fastest or Best Value, else the best scored
+	CollapsedIds  []string               `protobuf:"bytes,4,rep,name=collapsed_ids,json=collapsedIds,proto3" json:"collapsed_ids,omitempty"` // Similar options shown only as a count
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OptionGroup) Reset() {
+	*x = OptionGroup{}
+	mi := &file_protos_graph_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OptionGroup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OptionGroup) ProtoMessage() {}
+
+func (x *OptionGroup) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_graph_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OptionGroup.ProtoReflect.Descriptor instead.
+func (*OptionGroup) Descriptor() ([]byte, []int) {
+	return file_protos_graph_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *OptionGroup) GetTimeWindow() string {
+	if x != nil {
+		return x.TimeWindow
+	}
+	return ""
+}
+
+func (x *OptionGroup) GetCarrier() string {
+	if x != nil {
+		return x.Carrier
+	}
+	return ""
+}
+
+func (x *OptionGroup) GetVisibleIds() []string {
+	if x != nil {
+		return x.VisibleIds
+	}
+	return nil
+}
+
+func (x *OptionGroup) GetCollapsedIds() []string {
+	if x != nil {
+		return x.CollapsedIds
+	}
+	return nil
+}
+
 var File_protos_graph_proto protoreflect.FileDescriptor
 
 const file_protos_graph_proto_rawDesc = "" +
 	"\n" +
-	"\x12protos/graph.proto\x12\ftravelingman\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x16protos/itinerary.proto\"\xee\x02\n" +
+	"\x12protos/graph.proto\x12\ftravelingman\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x13protos/common.proto\x1a\x16protos/itinerary.proto\"\x96\x03\n" +
 	"\x04Node\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x122\n" +
 	"\blocation\x18\x02 \x01(\v2\x16.travelingman.LocationR\blocation\x12A\n" +
@@ -457,17 +900,20 @@ const file_protos_graph_proto_rawDesc = "" +
 	"\fto_timestamp\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\vtoTimestamp\x12/\n" +
 	"\x04stay\x18\x05 \x01(\v2\x1b.travelingman.AccommodationR\x04stay\x12=\n" +
 	"\vstayOptions\x18\x06 \x03(\v2\x1b.travelingman.AccommodationR\vstayOptions\x120\n" +
-	"\tsub_graph\x18\a \x01(\v2\x13.travelingman.GraphR\bsubGraph\"\xdb\x01\n" +
+	"\tsub_graph\x18\a \x01(\v2\x13.travelingman.GraphR\bsubGraph\x12\x14\n" +
+	"\x05notes\x18\t \x01(\tR\x05notesJ\x04\b\b\x10\tR\n" +
+	"car_rental\"\x9b\x02\n" +
 	"\x04Edge\x12\x17\n" +
 	"\afrom_id\x18\x01 \x01(\tR\x06fromId\x12\x13\n" +
 	"\x05to_id\x18\x02 \x01(\tR\x04toId\x12)\n" +
 	"\x10duration_seconds\x18\x03 \x01(\x03R\x0fdurationSeconds\x125\n" +
 	"\ttransport\x18\x04 \x01(\v2\x17.travelingman.TransportR\ttransport\x12C\n" +
-	"\x10transportOptions\x18\x05 \x03(\v2\x17.travelingman.TransportR\x10transportOptions\"\x8d\x01\n" +
+	"\x10transportOptions\x18\x05 \x03(\v2\x17.travelingman.TransportR\x10transportOptions\x12>\n" +
+	"\roption_groups\x18\x06 \x03(\v2\x19.travelingman.OptionGroupR\foptionGroups\"\x8d\x01\n" +
 	"\x05Graph\x12(\n" +
 	"\x05nodes\x18\x01 \x03(\v2\x12.travelingman.NodeR\x05nodes\x12(\n" +
 	"\x05edges\x18\x02 \x03(\v2\x12.travelingman.EdgeR\x05edges\x120\n" +
-	"\tsub_graph\x18\x03 \x01(\v2\x13.travelingman.GraphR\bsubGraph\"\xc5\x03\n" +
+	"\tsub_graph\x18\x03 \x01(\v2\x13.travelingman.GraphR\bsubGraph\"\xc1\x05\n" +
 	"\tItinerary\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x19\n" +
 	"\bgroup_id\x18\x02 \x01(\x03R\agroupId\x12\x1d\n" +
@@ -483,7 +929,46 @@ const file_protos_graph_proto_rawDesc = "" +
 	"\x04tags\x18\n" +
 	" \x03(\tR\x04tags\x12<\n" +
 	"\fjourney_type\x18\v \x01(\x0e2\x19.travelingman.JourneyTypeR\vjourneyType\x12)\n" +
-	"\x05error\x18\f \x01(\v2\x13.travelingman.ErrorR\x05error*\xb4\x01\n" +
+	"\x05error\x18\f \x01(\v2\x13.travelingman.ErrorR\x05error\x12\x18\n" +
+	"\aversion\x18\r \x01(\x03R\aversion\x122\n" +
+	"\x05stats\x18\x0e \x01(\v2\x1c.travelingman.ItineraryStatsR\x05stats\x12*\n" +
+	"\x06budget\x18\x0f \x01(\v2\x12.travelingman.CostR\x06budget\x12<\n" +
+	"\fbudget_split\x18\x10 \x01(\v2\x19.travelingman.BudgetSplitR\vbudgetSplit\x12B\n" +
+	"\x0ecost_breakdown\x18\x11 \x01(\v2\x1b.travelingman.CostBreakdownR\rcostBreakdown\"\x89\x02\n" +
+	"\x0eItineraryStats\x12'\n" +
+	"\x0ftransit_seconds\x18\x01 \x01(\x03R\x0etransitSeconds\x12/\n" +
+	"\x13destination_seconds\x18\x02 \x01(\x03R\x12destinationSeconds\x12\x1b\n" +
+	"\thop_count\x18\x03 \x01(\x05R\bhopCount\x12#\n" +
+	"\rtransit_ratio\x18\x04 \x01(\x01R\ftransitRatio\x12\x18\n" +
+	"\apartial\x18\x05 \x01(\bR\apartial\x12A\n" +
+	"\fdestinations\x18\x06 \x03(\v2\x1d.travelingman.DestinationTimeR\fdestinations\"Z\n" +
+	"\x0fDestinationTime\x12\x17\n" +
+	"\anode_id\x18\x01 \x01(\tR\x06nodeId\x12\x14\n" +
+	"\x05place\x18\x02 \x01(\tR\x05place\x12\x18\n" +
+	"\aseconds\x18\x03 \x01(\x03R\aseconds\"\x81\x02\n" +
+	"\vBudgetSplit\x12,\n" +
+	"\aflights\x18\x01 \x01(\v2\x12.travelingman.CostR\aflights\x12*\n" +
+	"\x06hotels\x18\x02 \x01(\v2\x12.travelingman.CostR\x06hotels\x12*\n" +
+	"\x06buffer\x18\x03 \x01(\v2\x12.travelingman.CostR\x06buffer\x12\x1f\n" +
+	"\vflight_legs\x18\x04 \x01(\x05R\n" +
+	"flightLegs\x12\x16\n" +
+	"\x06nights\x18\x05 \x01(\x05R\x06nights\x12\x1f\n" +
+	"\vprice_index\x18\x06 \x01(\x01R\n" +
+	"priceIndex\x12\x12\n" +
+	"\x04note\x18\a \x01(\tR\x04note\"\xf5\x01\n" +
+	"\rCostBreakdown\x120\n" +
+	"\ttransport\x18\x01 \x01(\v2\x12.travelingman.CostR\ttransport\x128\n" +
+	"\raccommodation\x18\x02 \x01(\v2\x12.travelingman.CostR\raccommodation\x124\n" +
+	"\vancillaries\x18\x03 \x01(\v2\x12.travelingman.CostR\vancillaries\x12(\n" +
+	"\x05total\x18\x04 \x01(\v2\x12.travelingman.CostR\x05total\x12\x18\n" +
+	"\apartial\x18\x05 \x01(\bR\apartial\"\x8e\x01\n" +
+	"\vOptionGroup\x12\x1f\n" +
+	"\vtime_window\x18\x01 \x01(\tR\n" +
+	"timeWindow\x12\x18\n" +
+	"\acarrier\x18\x02 \x01(\tR\acarrier\x12\x1f\n" +
+	"\vvisible_ids\x18\x03 \x03(\tR\n" +
+	"visibleIds\x12#\n" +
+	"\rcollapsed_ids\x18\x04 \x03(\tR\fcollapsedIds*\xb4\x01\n" +
 	"\vJourneyType\x12\x1c\n" +
 	"\x18JOURNEY_TYPE_UNSPECIFIED\x10\x00\x12\x18\n" +
 	"\x14JOURNEY_TYPE_ONE_WAY\x10\x01\x12\x17\n" +
@@ -505,41 +990,60 @@ func file_protos_graph_proto_rawDescGZIP() []byte {
 }
 
 var file_protos_graph_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_protos_graph_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_protos_graph_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
 var file_protos_graph_proto_goTypes = []any{
 	(JourneyType)(0),              // 0: travelingman.JourneyType
 	(*Node)(nil),                  // 1: travelingman.Node
 	(*Edge)(nil),                  // 2: travelingman.Edge
 	(*Graph)(nil),                 // 3: travelingman.Graph
 	(*Itinerary)(nil),             // 4: travelingman.Itinerary
-	(*Location)(nil),              // 5: travelingman.Location
-	(*timestamppb.Timestamp)(nil), // 6: google.protobuf.Timestamp
-	(*Accommodation)(nil),         // 7: travelingman.Accommodation
-	(*Transport)(nil),             // 8: travelingman.Transport
-	(*Error)(nil),                 // 9: travelingman.Error
+	(*ItineraryStats)(nil),        // 5: travelingman.ItineraryStats
+	(*DestinationTime)(nil),       // 6: travelingman.DestinationTime
+	(*BudgetSplit)(nil),           // 7: travelingman.BudgetSplit
+	(*CostBreakdown)(nil),         // 8: travelingman.CostBreakdown
+	(*OptionGroup)(nil),           // 9: travelingman.OptionGroup
+	(*Location)(nil),              // 10: travelingman.Location
+	(*timestamppb.Timestamp)(nil), // 11: google.protobuf.Timestamp
+	(*Accommodation)(nil),         // 12: travelingman.Accommodation
+	(*Transport)(nil),             // 13: travelingman.Transport
+	(*Error)(nil),                 // 14: travelingman.Error
+	(*Cost)(nil),                  // 15: travelingman.Cost
 }
 var file_protos_graph_proto_depIdxs = []int32{
-	5,  // 0: travelingman.Node.location:type_name -> travelingman.Location
-	6,  // 1: travelingman.Node.from_timestamp:type_name -> google.protobuf.Timestamp
-	6,  // 2: travelingman.Node.to_timestamp:type_name -> google.protobuf.Timestamp
-	7,  // 3: travelingman.Node.stay:type_name -> travelingman.Accommodation
-	7,  // 4: travelingman.Node.stayOptions:type_name -> travelingman.Accommodation
+	10, // 0: travelingman.Node.location:type_name -> travelingman.Location
+	11, // 1: travelingman.Node.from_timestamp:type_name -> google.protobuf.Timestamp
+	11, // 2: travelingman.Node.to_timestamp:type_name -> google.protobuf.Timestamp
+	12, // 3: travelingman.Node.stay:type_name -> travelingman.Accommodation
+	12, // 4: travelingman.Node.stayOptions:type_name -> travelingman.Accommodation
 	3,  // 5: travelingman.Node.sub_graph:type_name -> travelingman.Graph
-	8,  // 6: travelingman.Edge.transport:type_name -> travelingman.Transport
-	8,  // 7: travelingman.Edge.transportOptions:type_name -> travelingman.Transport
-	1,  // 8: travelingman.Graph.nodes:type_name -> travelingman.Node
-	2,  // 9: travelingman.Graph.edges:type_name -> travelingman.Edge
-	3,  // 10: travelingman.Graph.sub_graph:type_name -> travelingman.Graph
-	6,  // 11: travelingman.Itinerary.start_time:type_name -> google.protobuf.Timestamp
-	6,  // 12: travelingman.Itinerary.end_time:type_name -> google.protobuf.Timestamp
-	3,  // 13: travelingman.Itinerary.graph:type_name -> travelingman.Graph
-	0,  // 14: travelingman.Itinerary.journey_type:type_name -> travelingman.JourneyType
-	9,  // 15: travelingman.Itinerary.error:type_name -> travelingman.Error
-	16, // [16:16] is the sub-list for method output_type
-	16, // [16:16] is the sub-list for method input_type
-	16, // [16:16] is the sub-list for extension type_name
-	16, // [16:16] is the sub-list for extension extendee
-	0,  // [0:16] is the sub-list for field type_name
+	13, // 6: travelingman.Edge.transport:type_name -> travelingman.Transport
+	13, // 7: travelingman.Edge.transportOptions:type_name -> travelingman.Transport
+	9,  // 8: travelingman.Edge.option_groups:type_name -> travelingman.OptionGroup
+	1,  // 9: travelingman.Graph.nodes:type_name -> travelingman.Node
+	2,  // 10: travelingman.Graph.edges:type_name -> travelingman.Edge
+	3,  // 11: travelingman.Graph.sub_graph:type_name -> travelingman.Graph
+	11, // 12: travelingman.Itinerary.start_time:type_name -> google.protobuf.Timestamp
+	11, // 13: travelingman.Itinerary.end_time:type_name -> google.protobuf.Timestamp
+	3,  // 14: travelingman.Itinerary.graph:type_name -> travelingman.Graph
+	0,  // 15: travelingman.Itinerary.journey_type:type_name -> travelingman.JourneyType
+	14, // 16: travelingman.Itinerary.error:type_name -> travelingman.Error
+	5,  // 17: travelingman.Itinerary.stats:type_name -> travelingman.ItineraryStats
+	15, // 18: travelingman.Itinerary.budget:type_name -> travelingman.Cost
+	7,  // 19: travelingman.Itinerary.budget_split:type_name -> travelingman.BudgetSplit
+	8,  // 20: travelingman.Itinerary.cost_breakdown:type_name -> travelingman.CostBreakdown
+	6,  // 21: travelingman.ItineraryStats.destinations:type_name -> travelingman.DestinationTime
+	15, // 22: travelingman.BudgetSplit.flights:type_name -> travelingman.Cost
+	15, // 23: travelingman.BudgetSplit.hotels:type_name -> travelingman.Cost
+	15, // 24: travelingman.BudgetSplit.buffer:type_name -> travelingman.Cost
+	15, // 25: travelingman.CostBreakdown.transport:type_name -> travelingman.Cost
+	15, // 26: travelingman.CostBreakdown.accommodation:type_name -> travelingman.Cost
+	15, // 27: travelingman.CostBreakdown.ancillaries:type_name -> travelingman.Cost
+	15, // 28: travelingman.CostBreakdown.total:type_name -> travelingman.Cost
+	29, // [29:29] is the sub-list for method output_type
+	29, // [29:29] is the sub-list for method input_type
+	29, // [29:29] is the sub-list for extension type_name
+	29, // [29:29] is the sub-list for extension extendee
+	0,  // [0:29] is the sub-list for field type_name
 }
 
 func init() { file_protos_graph_proto_init() }
@@ -547,6 +1051,7 @@ func file_protos_graph_proto_init() {
 	if File_protos_graph_proto != nil {
 		return
 	}
+	file_protos_common_proto_init()
 	file_protos_itinerary_proto_init()
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -554,7 +1059,7 @@ func file_protos_graph_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_protos_graph_proto_rawDesc), len(file_protos_graph_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   4,
+			NumMessages:   9,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -235,12 +235,13 @@ type ErrorCode int32
 const (
 	ErrorCode_ERROR_CODE_UNSPECIFIED           ErrorCode = 0
 	ErrorCode_ERROR_CODE_SEARCH_FAILED         ErrorCode = 1
-	ErrorCode_ERROR_CODE_DATA_NOT_FOUND        ErrorCode = 2
+	ErrorCode_ERROR_CODE_DATA_NOT_FOUND        ErrorCode = 2 // Nothing exists to offer, e.g. a city without hotels
 	ErrorCode_ERROR_CODE_API_LIMIT_REACHED     ErrorCode = 3
 	ErrorCode_ERROR_CODE_INVALID_INPUT         ErrorCode = 4
 	ErrorCode_ERROR_CODE_AUTHENTICATION_FAILED ErrorCode = 5
 	ErrorCode_ERROR_CODE_INTERNAL_SERVER_ERROR ErrorCode = 6
 	ErrorCode_ERROR_CODE_CONNECTION_FAILED     ErrorCode = 7
+	ErrorCode_ERROR_CODE_NO_AVAILABILITY       ErrorCode = 8 // It exists but is unavailable on the requested dates
 )
 
 // Enum value maps for ErrorCode.
@@ -254,6 +255,7 @@ var (
 		5: "ERROR_CODE_AUTHENTICATION_FAILED",
 		6: "ERROR_CODE_INTERNAL_SERVER_ERROR",
 		7: "ERROR_CODE_CONNECTION_FAILED",
+		8: "ERROR_CODE_NO_AVAILABILITY",
 	}
 	ErrorCode_value = map[string]int32{
 		"ERROR_CODE_UNSPECIFIED":           0,
@@ -264,6 +266,7 @@ var (
 		"ERROR_CODE_AUTHENTICATION_FAILED": 5,
 		"ERROR_CODE_INTERNAL_SERVER_ERROR": 6,
 		"ERROR_CODE_CONNECTION_FAILED":     7,
+		"ERROR_CODE_NO_AVAILABILITY":       8,
 	}
 )
 
@@ -347,13 +350,18 @@ func (ErrorSeverity) EnumDescriptor() ([]byte, []int) {
 }
 
 type AccommodationPreferences struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	RoomType      string                 `protobuf:"bytes,1,opt,name=room_type,json=roomType,proto3" json:"room_type,omitempty"`
-	Area          string                 `protobuf:"bytes,2,opt,name=area,proto3" json:"area,omitempty"`
-	Rating        int32                  `protobuf:"varint,3,opt,name=rating,proto3" json:"rating,omitempty"`
-	Amenities     []string               `protobuf:"bytes,4,rep,name=amenities,proto3" json:"amenities,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	RoomType        string                 `protobuf:"bytes,1,opt,name=room_type,json=roomType,proto3" json:"room_type,omitempty"`
+	Area            string                 `protobuf:"bytes,2,opt,name=area,proto3" json:"area,omitempty"`
+	Rating          int32                  `protobuf:"varint,3,opt,name=rating,proto3" json:"rating,omitempty"`
+	Amenities       []string               `protobuf:"bytes,4,rep,name=amenities,proto3" json:"amenities,omitempty"`
+	Strict          bool                   `protobuf:"varint,5,opt,name=strict,proto3" json:"strict,omitempty"`                                             // Never relax rating or amenities when no hotel matches
+	MaxNightlyPrice float64                `protobuf:"fixed64,6,opt,name=max_nightly_price,json=maxNightlyPrice,proto3" json:"max_nightly_price,omitempty"` // Soft cap on the price per night, in the stay's currency (0 for none)
+	Breakfast       bool                   `protobuf:"varint,7,opt,name=breakfast,proto3" json:"breakfast,omitempty"`                                       // The user wants breakfast; stays without it are compared as if it were bought separately
+	StrictLocation  bool                   `protobuf:"varint,8,opt,name=strict_location,json=strictLocation,proto3" json:"strict_location,omitempty"`       // The user wants to stay in this exact place; never use hotels in a nearby city instead
+	NearTransit     bool                   `protobuf:"varint,9,opt,name=near_transit,json=nearTransit,proto3" json:"near_transit,omitempty"`                // The user wants to stay close to the airport or station they arrive at; stays are ranked by the distance to it
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *AccommodationPreferences) Reset() {
@@ -414,13 +422,50 @@ func (x *AccommodationPreferences) GetAmenities() []string {
 	return nil
 }
 
+func (x *AccommodationPreferences) GetStrict() bool {
+	if x != nil {
+		return x.Strict
+	}
+	return false
+}
+
+func (x *AccommodationPreferences) GetMaxNightlyPrice() float64 {
+	if x != nil {
+		return x.MaxNightlyPrice
+	}
+	return 0
+}
+
+func (x *AccommodationPreferences) GetBreakfast() bool {
+	if x != nil {
+		return x.Breakfast
+	}
+	return false
+}
+
+func (x *AccommodationPreferences) GetStrictLocation() bool {
+	if x != nil {
+		return x.StrictLocation
+	}
+	return false
+}
+
+func (x *AccommodationPreferences) GetNearTransit() bool {
+	if x != nil {
+		return x.NearTransit
+	}
+	return false
+}
+
 type FlightPreferences struct {
 	state                        protoimpl.MessageState `protogen:"open.v1"`
 	TravelClass                  Class                  `protobuf:"varint,1,opt,name=travel_class,json=travelClass,proto3,enum=travelingman.Class" json:"travel_class,omitempty"`
-	MaxStops                     int32                  `protobuf:"varint,2,opt,name=max_stops,json=maxStops,proto3" json:"max_stops,omitempty"`
+	MaxStops                     *int32                 `protobuf:"varint,2,opt,name=max_stops,json=maxStops,proto3,oneof" json:"max_stops,omitempty"` // Most stops per flight, 0 for non-stop only; unset for any number
 	PreferredOriginAirports      []string               `protobuf:"bytes,3,rep,name=preferred_origin_airports,json=preferredOriginAirports,proto3" json:"preferred_origin_airports,omitempty"`
 	PreferredDestinationAirports []string               `protobuf:"bytes,4,rep,name=preferred_destination_airports,json=preferredDestinationAirports,proto3" json:"preferred_destination_airports,omitempty"`
-	Baggage                      *BaggagePreferences    `protobuf:"bytes,5,opt,name=baggage,proto3" json:"baggage,omitempty"` // User's baggage requirements
+	Baggage                      *BaggagePreferences    `protobuf:"bytes,5,opt,name=baggage,proto3" json:"baggage,omitempty"`                      // User's baggage requirements
+	MaxPrice                     float64                `protobuf:"fixed64,6,opt,name=max_price,json=maxPrice,proto3" json:"max_price,omitempty"`  // Soft cap on the fare per traveller, in the transport's currency (0 for none)
+	ArrivalBy                    *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=arrival_by,json=arrivalBy,proto3" json:"arrival_by,omitempty"` // Latest arrival, in the destination's local time like flight times; unset for any
 	unknownFields                protoimpl.UnknownFields
 	sizeCache                    protoimpl.SizeCache
 }
@@ -463,8 +508,8 @@ func (x *FlightPreferences) GetTravelClass() Class {
 }
 
 func (x *FlightPreferences) GetMaxStops() int32 {
-	if x != nil {
-		return x.MaxStops
+	if x != nil && x.MaxStops != nil {
+		return *x.MaxStops
 	}
 	return 0
 }
@@ -490,6 +535,20 @@ func (x *FlightPreferences) GetBaggage() *BaggagePreferences {
 	return nil
 }
 
+func (x *FlightPreferences) GetMaxPrice() float64 {
+	if x != nil {
+		return x.MaxPrice
+	}
+	return 0
+}
+
+func (x *FlightPreferences) GetArrivalBy() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ArrivalBy
+	}
+	return nil
+}
+
 type TrainPreferences struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	TravelClass   Class                  `protobuf:"varint,1,opt,name=travel_class,json=travelClass,proto3,enum=travelingman.Class" json:"travel_class,omitempty"`
@@ -950,29 +1009,115 @@ func (x *Error) GetSeverity() ErrorSeverity {
 	return ErrorSeverity_ERROR_SEVERITY_UNSPECIFIED
 }
 
+// PaymentPolicy is how a rate must be paid or guaranteed at booking
+type PaymentPolicy struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	AcceptedCardVendors []string               `protobuf:"bytes,1,rep,name=accepted_card_vendors,json=acceptedCardVendors,proto3" json:"accepted_card_vendors,omitempty"` // Card vendor codes, e.g. VI, AX; empty accepts any card
+	AcceptedMethods     []string               `protobuf:"bytes,2,rep,name=accepted_methods,json=acceptedMethods,proto3" json:"accepted_methods,omitempty"`               // Payment methods, e.g. CREDIT_CARD
+	GuaranteeRequired   bool                   `protobuf:"varint,3,opt,name=guarantee_required,json=guaranteeRequired,proto3" json:"guarantee_required,omitempty"`        // A card must be given to hold the room
+	Deposit             *Cost                  `protobuf:"bytes,4,opt,name=deposit,proto3" json:"deposit,omitempty"`                                                      // Charged at booking, if the rate needs a deposit
+	DepositDeadline     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=deposit_deadline,json=depositDeadline,proto3" json:"deposit_deadline,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *PaymentPolicy) Reset() {
+	*x = PaymentPolicy{}
+	mi := &file_protos_itinerary_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PaymentPolicy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PaymentPolicy) ProtoMessage() {}
+
+func (x *PaymentPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_itinerary_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PaymentPolicy.ProtoReflect.Descriptor instead.
+func (*PaymentPolicy) Descriptor() ([]byte, []int) {
+	return file_protos_itinerary_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *PaymentPolicy) GetAcceptedCardVendors() []string {
+	if x != nil {
+		return x.AcceptedCardVendors
+	}
+	return nil
+}
+
+func (x *PaymentPolicy) GetAcceptedMethods() []string {
+	if x != nil {
+		return x.AcceptedMethods
+	}
+	return nil
+}
+
+func (x *PaymentPolicy) GetGuaranteeRequired() bool {
+	if x != nil {
+		return x.GuaranteeRequired
+	}
+	return false
+}
+
+func (x *PaymentPolicy) GetDeposit() *Cost {
+	if x != nil {
+		return x.Deposit
+	}
+	return nil
+}
+
+func (x *PaymentPolicy) GetDepositDeadline() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DepositDeadline
+	}
+	return nil
+}
+
 type Accommodation struct {
-	state            protoimpl.MessageState    `protogen:"open.v1"`
-	Id               int64                     `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	GroupId          int64                     `protobuf:"varint,2,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
-	Name             string                    `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
-	CheckIn          *timestamppb.Timestamp    `protobuf:"bytes,4,opt,name=check_in,json=checkIn,proto3" json:"check_in,omitempty"`
-	CheckOut         *timestamppb.Timestamp    `protobuf:"bytes,5,opt,name=check_out,json=checkOut,proto3" json:"check_out,omitempty"`
-	Cost             *Cost                     `protobuf:"bytes,6,opt,name=cost,proto3" json:"cost,omitempty"`
-	BookingReference string                    `protobuf:"bytes,7,opt,name=booking_reference,json=bookingReference,proto3" json:"booking_reference,omitempty"`
-	Status           string                    `protobuf:"bytes,8,opt,name=status,proto3" json:"status,omitempty"`
-	UserIds          []int64                   `protobuf:"varint,9,rep,packed,name=user_ids,json=userIds,proto3" json:"user_ids,omitempty"`
-	Preferences      *AccommodationPreferences `protobuf:"bytes,11,opt,name=preferences,proto3" json:"preferences,omitempty"`
-	TravelerCount    int32                     `protobuf:"varint,12,opt,name=traveler_count,json=travelerCount,proto3" json:"traveler_count,omitempty"`
-	Location         *Location                 `protobuf:"bytes,13,opt,name=location,proto3" json:"location,omitempty"`
-	Error            *Error                    `protobuf:"bytes,14,opt,name=error,proto3" json:"error,omitempty"`
-	Tags             []string                  `protobuf:"bytes,15,rep,name=tags,proto3" json:"tags,omitempty"`
-	unknownFields    protoimpl.UnknownFields
-	sizeCache        protoimpl.SizeCache
+	state             protoimpl.MessageState    `protogen:"open.v1"`
+	Id                int64                     `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	GroupId           int64                     `protobuf:"varint,2,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	Name              string                    `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	CheckIn           *timestamppb.Timestamp    `protobuf:"bytes,4,opt,name=check_in,json=checkIn,proto3" json:"check_in,omitempty"`
+	CheckOut          *timestamppb.Timestamp    `protobuf:"bytes,5,opt,name=check_out,json=checkOut,proto3" json:"check_out,omitempty"`
+	Cost              *Cost                     `protobuf:"bytes,6,opt,name=cost,proto3" json:"cost,omitempty"`
+	BookingReference  string                    `protobuf:"bytes,7,opt,name=booking_reference,json=bookingReference,proto3" json:"booking_reference,omitempty"`
+	Status            string                    `protobuf:"bytes,8,opt,name=status,proto3" json:"status,omitempty"`
+	UserIds           []int64                   `protobuf:"varint,9,rep,packed,name=user_ids,json=userIds,proto3" json:"user_ids,omitempty"`
+	Preferences       *AccommodationPreferences `protobuf:"bytes,11,opt,name=preferences,proto3" json:"preferences,omitempty"`
+	TravelerCount     int32                     `protobuf:"varint,12,opt,name=traveler_count,json=travelerCount,proto3" json:"traveler_count,omitempty"`
+	Location          *Location                 `protobuf:"bytes,13,opt,name=location,proto3" json:"location,omitempty"`
+	Error             *Error                    `protobuf:"bytes,14,opt,name=error,proto3" json:"error,omitempty"`
+	Tags              []string                  `protobuf:"bytes,15,rep,name=tags,proto3" json:"tags,omitempty"`
+	HotelId           string                    `protobuf:"bytes,16,opt,name=hotel_id,json=hotelId,proto3" json:"hotel_id,omitempty"`                                // Provider property ID; offers from the same hotel share it
+	PropertyCheapest  bool                      `protobuf:"varint,17,opt,name=property_cheapest,json=propertyCheapest,proto3" json:"property_cheapest,omitempty"`    // Cheapest offer among those from the same hotel
+	NightlyCost       *Cost                     `protobuf:"bytes,18,opt,name=nightly_cost,json=nightlyCost,proto3" json:"nightly_cost,omitempty"`                    // Price per night: the provider's average, else the total over the nights
+	BreakfastIncluded bool                      `protobuf:"varint,19,opt,name=breakfast_included,json=breakfastIncluded,proto3" json:"breakfast_included,omitempty"` // The rate includes breakfast
+	PaymentPolicy     *PaymentPolicy            `protobuf:"bytes,20,opt,name=payment_policy,json=paymentPolicy,proto3" json:"payment_policy,omitempty"`
+	Warnings          []string                  `protobuf:"bytes,21,rep,name=warnings,proto3" json:"warnings,omitempty"`                                    // What to know before choosing, e.g. a deposit due at booking
+	ChildAges         []int32                   `protobuf:"varint,22,rep,packed,name=child_ages,json=childAges,proto3" json:"child_ages,omitempty"`         // Ages of the children among traveler_count, for family rooms
+	ChainCode         string                    `protobuf:"bytes,23,opt,name=chain_code,json=chainCode,proto3" json:"chain_code,omitempty"`                 // Provider code of the hotel's chain, e.g. MC for Marriott
+	PreferredChain    bool                      `protobuf:"varint,24,opt,name=preferred_chain,json=preferredChain,proto3" json:"preferred_chain,omitempty"` // The hotel belongs to one of the preferred chains
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
 func (x *Accommodation) Reset() {
 	*x = Accommodation{}
-	mi := &file_protos_itinerary_proto_msgTypes[9]
+	mi := &file_protos_itinerary_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -984,7 +1129,7 @@ func (x *Accommodation) String() string {
 func (*Accommodation) ProtoMessage() {}
 
 func (x *Accommodation) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_itinerary_proto_msgTypes[9]
+	mi := &file_protos_itinerary_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -997,7 +1142,7 @@ func (x *Accommodation) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Accommodation.ProtoReflect.Descriptor instead.
 func (*Accommodation) Descriptor() ([]byte, []int) {
-	return file_protos_itinerary_proto_rawDescGZIP(), []int{9}
+	return file_protos_itinerary_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *Accommodation) GetId() int64 {
@@ -1098,6 +1243,69 @@ func (x *Accommodation) GetTags() []string {
 	return nil
 }
 
+func (x *Accommodation) GetHotelId() string {
+	if x != nil {
+		return x.HotelId
+	}
+	return ""
+}
+
+func (x *Accommodation) GetPropertyCheapest() bool {
+	if x != nil {
+		return x.PropertyCheapest
+	}
+	return false
+}
+
+func (x *Accommodation) GetNightlyCost() *Cost {
+	if x != nil {
+		return x.NightlyCost
+	}
+	return nil
+}
+
+func (x *Accommodation) GetBreakfastIncluded() bool {
+	if x != nil {
+		return x.BreakfastIncluded
+	}
+	return false
+}
+
+func (x *Accommodation) GetPaymentPolicy() *PaymentPolicy {
+	if x != nil {
+		return x.PaymentPolicy
+	}
+	return nil
+}
+
+func (x *Accommodation) GetWarnings() []string {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+func (x *Accommodation) GetChildAges() []int32 {
+	if x != nil {
+		return x.ChildAges
+	}
+	return nil
+}
+
+func (x *Accommodation) GetChainCode() string {
+	if x != nil {
+		return x.ChainCode
+	}
+	return ""
+}
+
+func (x *Accommodation) GetPreferredChain() bool {
+	if x != nil {
+		return x.PreferredChain
+	}
+	return false
+}
+
 type Transport struct {
 	state                protoimpl.MessageState `protogen:"open.v1"`
 	Id                   int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -1128,7 +1336,7 @@ type Transport struct {
 
 func (x *Transport) Reset() {
 	*x = Transport{}
-	mi := &file_protos_itinerary_proto_msgTypes[10]
+	mi := &file_protos_itinerary_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1140,7 +1348,7 @@ func (x *Transport) String() string {
 func (*Transport) ProtoMessage() {}
 
 func (x *Transport) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_itinerary_proto_msgTypes[10]
+	mi := &file_protos_itinerary_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1153,7 +1361,7 @@ func (x *Transport) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Transport.ProtoReflect.Descriptor instead.
 func (*Transport) Descriptor() ([]byte, []int) {
-	return file_protos_itinerary_proto_rawDescGZIP(), []int{10}
+	return file_protos_itinerary_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *Transport) GetId() int64 {
@@ -1336,13 +1544,15 @@ type Flight struct {
 	Segments                 []*FlightSegment       `protobuf:"bytes,8,rep,name=segments,proto3" json:"segments,omitempty"`                                                                     // Individual flight segments
 	LayoverCount             int32                  `protobuf:"varint,9,opt,name=layover_count,json=layoverCount,proto3" json:"layover_count,omitempty"`                                        // Number of layovers (segments - 1)
 	TotalDuration            string                 `protobuf:"bytes,10,opt,name=total_duration,json=totalDuration,proto3" json:"total_duration,omitempty"`                                     // Total journey duration (e.g., "2h 30m")
+	CarrierName              string                 `protobuf:"bytes,11,opt,name=carrier_name,json=carrierName,proto3" json:"carrier_name,omitempty"`                                           // Airline name, if the provider returned it
+	Layovers                 []*Layover             `protobuf:"bytes,12,rep,name=layovers,proto3" json:"layovers,omitempty"`                                                                    // Connections between the segments, in order
 	unknownFields            protoimpl.UnknownFields
 	sizeCache                protoimpl.SizeCache
 }
 
 func (x *Flight) Reset() {
 	*x = Flight{}
-	mi := &file_protos_itinerary_proto_msgTypes[11]
+	mi := &file_protos_itinerary_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1354,7 +1564,7 @@ func (x *Flight) String() string {
 func (*Flight) ProtoMessage() {}
 
 func (x *Flight) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_itinerary_proto_msgTypes[11]
+	mi := &file_protos_itinerary_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1367,7 +1577,7 @@ func (x *Flight) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Flight.ProtoReflect.Descriptor instead.
 func (*Flight) Descriptor() ([]byte, []int) {
-	return file_protos_itinerary_proto_rawDescGZIP(), []int{11}
+	return file_protos_itinerary_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *Flight) GetCarrierCode() string {
@@ -1440,6 +1650,20 @@ func (x *Flight) GetTotalDuration() string {
 	return ""
 }
 
+func (x *Flight) GetCarrierName() string {
+	if x != nil {
+		return x.CarrierName
+	}
+	return ""
+}
+
+func (x *Flight) GetLayovers() []*Layover {
+	if x != nil {
+		return x.Layovers
+	}
+	return nil
+}
+
 type FlightSegment struct {
 	state                protoimpl.MessageState `protogen:"open.v1"`
 	CarrierCode          string                 `protobuf:"bytes,1,opt,name=carrier_code,json=carrierCode,proto3" json:"carrier_code,omitempty"`                              // Airline code
@@ -1450,13 +1674,16 @@ type FlightSegment struct {
 	ArrivalAirportCode   string                 `protobuf:"bytes,6,opt,name=arrival_airport_code,json=arrivalAirportCode,proto3" json:"arrival_airport_code,omitempty"`       // Destination IATA code
 	Duration             string                 `protobuf:"bytes,7,opt,name=duration,proto3" json:"duration,omitempty"`                                                       // Segment duration (e.g., "1h 45m")
 	Stops                int32                  `protobuf:"varint,8,opt,name=stops,proto3" json:"stops,omitempty"`                                                            // Number of stops in this segment
+	DepartureTerminal    string                 `protobuf:"bytes,9,opt,name=departure_terminal,json=departureTerminal,proto3" json:"departure_terminal,omitempty"`            // Origin terminal, if known
+	ArrivalTerminal      string                 `protobuf:"bytes,10,opt,name=arrival_terminal,json=arrivalTerminal,proto3" json:"arrival_terminal,omitempty"`                 // Destination terminal, if known
+	CarrierName          string                 `protobuf:"bytes,11,opt,name=carrier_name,json=carrierName,proto3" json:"carrier_name,omitempty"`                             // Airline name, if the provider returned it
 	unknownFields        protoimpl.UnknownFields
 	sizeCache            protoimpl.SizeCache
 }
 
 func (x *FlightSegment) Reset() {
 	*x = FlightSegment{}
-	mi := &file_protos_itinerary_proto_msgTypes[12]
+	mi := &file_protos_itinerary_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1468,7 +1695,7 @@ func (x *FlightSegment) String() string {
 func (*FlightSegment) ProtoMessage() {}
 
 func (x *FlightSegment) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_itinerary_proto_msgTypes[12]
+	mi := &file_protos_itinerary_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1481,7 +1708,7 @@ func (x *FlightSegment) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FlightSegment.ProtoReflect.Descriptor instead.
 func (*FlightSegment) Descriptor() ([]byte, []int) {
-	return file_protos_itinerary_proto_rawDescGZIP(), []int{12}
+	return file_protos_itinerary_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *FlightSegment) GetCarrierCode() string {
@@ -1540,6 +1767,96 @@ func (x *FlightSegment) GetStops() int32 {
 	return 0
 }
 
+func (x *FlightSegment) GetDepartureTerminal() string {
+	if x != nil {
+		return x.DepartureTerminal
+	}
+	return ""
+}
+
+func (x *FlightSegment) GetArrivalTerminal() string {
+	if x != nil {
+		return x.ArrivalTerminal
+	}
+	return ""
+}
+
+func (x *FlightSegment) GetCarrierName() string {
+	if x != nil {
+		return x.CarrierName
+	}
+	return ""
+}
+
+// Layover is a connection between two segments of a flight
+type Layover struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	AirportCode     string                 `protobuf:"bytes,1,opt,name=airport_code,json=airportCode,proto3" json:"airport_code,omitempty"`              // IATA code of the airport the inbound segment lands at
+	ArrivalTime     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=arrival_time,json=arrivalTime,proto3" json:"arrival_time,omitempty"`              // Arrival of the inbound segment
+	DepartureTime   *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=departure_time,json=departureTime,proto3" json:"departure_time,omitempty"`        // Departure of the outbound segment
+	DurationSeconds int64                  `protobuf:"varint,4,opt,name=duration_seconds,json=durationSeconds,proto3" json:"duration_seconds,omitempty"` // Time between the two, 0 if either time is unknown
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Layover) Reset() {
+	*x = Layover{}
+	mi := &file_protos_itinerary_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Layover) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Layover) ProtoMessage() {}
+
+func (x *Layover) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_itinerary_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Layover.ProtoReflect.Descriptor instead.
+func (*Layover) Descriptor() ([]byte, []int) {
+	return file_protos_itinerary_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Layover) GetAirportCode() string {
+	if x != nil {
+		return x.AirportCode
+	}
+	return ""
+}
+
+func (x *Layover) GetArrivalTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ArrivalTime
+	}
+	return nil
+}
+
+func (x *Layover) GetDepartureTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DepartureTime
+	}
+	return nil
+}
+
+func (x *Layover) GetDurationSeconds() int64 {
+	if x != nil {
+		return x.DurationSeconds
+	}
+	return 0
+}
+
 type Train struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	DepartureTime *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=departure_time,json=departureTime,proto3" json:"departure_time,omitempty"`
@@ -1551,7 +1868,7 @@ type Train struct {
 
 func (x *Train) Reset() {
 	*x = Train{}
-	mi := &file_protos_itinerary_proto_msgTypes[13]
+	mi := &file_protos_itinerary_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1563,7 +1880,7 @@ func (x *Train) String() string {
 func (*Train) ProtoMessage() {}
 
 func (x *Train) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_itinerary_proto_msgTypes[13]
+	mi := &file_protos_itinerary_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1576,7 +1893,7 @@ func (x *Train) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Train.ProtoReflect.Descriptor instead.
 func (*Train) Descriptor() ([]byte, []int) {
-	return file_protos_itinerary_proto_rawDescGZIP(), []int{13}
+	return file_protos_itinerary_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *Train) GetDepartureTime() *timestamppb.Timestamp {
@@ -1612,7 +1929,7 @@ type CarRental struct {
 
 func (x *CarRental) Reset() {
 	*x = CarRental{}
-	mi := &file_protos_itinerary_proto_msgTypes[14]
+	mi := &file_protos_itinerary_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1624,7 +1941,7 @@ func (x *CarRental) String() string {
 func (*CarRental) ProtoMessage() {}
 
 func (x *CarRental) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_itinerary_proto_msgTypes[14]
+	mi := &file_protos_itinerary_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1637,7 +1954,7 @@ func (x *CarRental) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CarRental.ProtoReflect.Descriptor instead.
 func (*CarRental) Descriptor() ([]byte, []int) {
-	return file_protos_itinerary_proto_rawDescGZIP(), []int{14}
+	return file_protos_itinerary_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *CarRental) GetCompany() string {
@@ -1668,22 +1985,400 @@ func (x *CarRental) GetCarType() string {
 	return ""
 }
 
+type DayPrice struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Date          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=date,proto3" json:"date,omitempty"`
+	Cost          *Cost                  `protobuf:"bytes,2,opt,name=cost,proto3" json:"cost,omitempty"`                          // Indicative lowest fare for the day
+	Interpolated  bool                   `protobuf:"varint,3,opt,name=interpolated,proto3" json:"interpolated,omitempty"`         // Estimated from neighbouring sampled days, not searched
+	IsMin         bool                   `protobuf:"varint,4,opt,name=is_min,json=isMin,proto3" json:"is_min,omitempty"`          // Cheapest day in the calendar
+	IsMedian      bool                   `protobuf:"varint,5,opt,name=is_median,json=isMedian,proto3" json:"is_median,omitempty"` // Day closest to the median price
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DayPrice) Reset() {
+	*x = DayPrice{}
+	mi := &file_protos_itinerary_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DayPrice) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DayPrice) ProtoMessage() {}
+
+func (x *DayPrice) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_itinerary_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DayPrice.ProtoReflect.Descriptor instead.
+func (*DayPrice) Descriptor() ([]byte, []int) {
+	return file_protos_itinerary_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *DayPrice) GetDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Date
+	}
+	return nil
+}
+
+func (x *DayPrice) GetCost() *Cost {
+	if x != nil {
+		return x.Cost
+	}
+	return nil
+}
+
+func (x *DayPrice) GetInterpolated() bool {
+	if x != nil {
+		return x.Interpolated
+	}
+	return false
+}
+
+func (x *DayPrice) GetIsMin() bool {
+	if x != nil {
+		return x.IsMin
+	}
+	return false
+}
+
+func (x *DayPrice) GetIsMedian() bool {
+	if x != nil {
+		return x.IsMedian
+	}
+	return false
+}
+
+type PriceCalendar struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Origin        string                 `protobuf:"bytes,1,opt,name=origin,proto3" json:"origin,omitempty"`           // Origin IATA code
+	Destination   string                 `protobuf:"bytes,2,opt,name=destination,proto3" json:"destination,omitempty"` // Destination IATA code
+	Month         string                 `protobuf:"bytes,3,opt,name=month,proto3" json:"month,omitempty"`             // YYYY-MM
+	TravelerCount int32                  `protobuf:"varint,4,opt,name=traveler_count,json=travelerCount,proto3" json:"traveler_count,omitempty"`
+	Days          []*DayPrice            `protobuf:"bytes,5,rep,name=days,proto3" json:"days,omitempty"`
+	MinPrice      *Cost                  `protobuf:"bytes,6,opt,name=min_price,json=minPrice,proto3" json:"min_price,omitempty"`
+	MedianPrice   *Cost                  `protobuf:"bytes,7,opt,name=median_price,json=medianPrice,proto3" json:"median_price,omitempty"`
+	Source        string                 `protobuf:"bytes,8,opt,name=source,proto3" json:"source,omitempty"` // FLIGHT_DATES or SAMPLED
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PriceCalendar) Reset() {
+	*x = PriceCalendar{}
+	mi := &file_protos_itinerary_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PriceCalendar) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PriceCalendar) ProtoMessage() {}
+
+func (x *PriceCalendar) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_itinerary_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PriceCalendar.ProtoReflect.Descriptor instead.
+func (*PriceCalendar) Descriptor() ([]byte, []int) {
+	return file_protos_itinerary_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *PriceCalendar) GetOrigin() string {
+	if x != nil {
+		return x.Origin
+	}
+	return ""
+}
+
+func (x *PriceCalendar) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+func (x *PriceCalendar) GetMonth() string {
+	if x != nil {
+		return x.Month
+	}
+	return ""
+}
+
+func (x *PriceCalendar) GetTravelerCount() int32 {
+	if x != nil {
+		return x.TravelerCount
+	}
+	return 0
+}
+
+func (x *PriceCalendar) GetDays() []*DayPrice {
+	if x != nil {
+		return x.Days
+	}
+	return nil
+}
+
+func (x *PriceCalendar) GetMinPrice() *Cost {
+	if x != nil {
+		return x.MinPrice
+	}
+	return nil
+}
+
+func (x *PriceCalendar) GetMedianPrice() *Cost {
+	if x != nil {
+		return x.MedianPrice
+	}
+	return nil
+}
+
+func (x *PriceCalendar) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+type WeekPrice struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WeekStart     *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=week_start,json=weekStart,proto3" json:"week_start,omitempty"`          // First departure day of the week
+	WeekEnd       *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=week_end,json=weekEnd,proto3" json:"week_end,omitempty"`                // Last departure day of the week (clipped to the range)
+	Cost          *Cost                  `protobuf:"bytes,3,opt,name=cost,proto3" json:"cost,omitempty"`                                     // Indicative lowest fare in the week
+	CheapestDate  *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=cheapest_date,json=cheapestDate,proto3" json:"cheapest_date,omitempty"` // Departure day the fare was found on (unset when interpolated)
+	Interpolated  bool                   `protobuf:"varint,5,opt,name=interpolated,proto3" json:"interpolated,omitempty"`                    // Estimated from neighbouring sampled weeks, not searched
+	IsMin         bool                   `protobuf:"varint,6,opt,name=is_min,json=isMin,proto3" json:"is_min,omitempty"`                     // Cheapest week in the range
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WeekPrice) Reset() {
+	*x = WeekPrice{}
+	mi := &file_protos_itinerary_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WeekPrice) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WeekPrice) ProtoMessage() {}
+
+func (x *WeekPrice) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_itinerary_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WeekPrice.ProtoReflect.Descriptor instead.
+func (*WeekPrice) Descriptor() ([]byte, []int) {
+	return file_protos_itinerary_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *WeekPrice) GetWeekStart() *timestamppb.Timestamp {
+	if x != nil {
+		return x.WeekStart
+	}
+	return nil
+}
+
+func (x *WeekPrice) GetWeekEnd() *timestamppb.Timestamp {
+	if x != nil {
+		return x.WeekEnd
+	}
+	return nil
+}
+
+func (x *WeekPrice) GetCost() *Cost {
+	if x != nil {
+		return x.Cost
+	}
+	return nil
+}
+
+func (x *WeekPrice) GetCheapestDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CheapestDate
+	}
+	return nil
+}
+
+func (x *WeekPrice) GetInterpolated() bool {
+	if x != nil {
+		return x.Interpolated
+	}
+	return false
+}
+
+func (x *WeekPrice) GetIsMin() bool {
+	if x != nil {
+		return x.IsMin
+	}
+	return false
+}
+
+type FareTrend struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Origin        string                 `protobuf:"bytes,1,opt,name=origin,proto3" json:"origin,omitempty"`           // Origin IATA code
+	Destination   string                 `protobuf:"bytes,2,opt,name=destination,proto3" json:"destination,omitempty"` // Destination IATA code
+	FromDate      *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=from_date,json=fromDate,proto3" json:"from_date,omitempty"`
+	ToDate        *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=to_date,json=toDate,proto3" json:"to_date,omitempty"`
+	TravelerCount int32                  `protobuf:"varint,5,opt,name=traveler_count,json=travelerCount,proto3" json:"traveler_count,omitempty"`
+	Weeks         []*WeekPrice           `protobuf:"bytes,6,rep,name=weeks,proto3" json:"weeks,omitempty"`                                   // Per-week price trend, in date order
+	CheapestWeek  *WeekPrice             `protobuf:"bytes,7,opt,name=cheapest_week,json=cheapestWeek,proto3" json:"cheapest_week,omitempty"` // Cheapest window in the range
+	Source        string                 `protobuf:"bytes,8,opt,name=source,proto3" json:"source,omitempty"`                                 // FLIGHT_DATES or SAMPLED
+	Searches      int32                  `protobuf:"varint,9,opt,name=searches,proto3" json:"searches,omitempty"`                            // Number of provider searches used to build the trend
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FareTrend) Reset() {
+	*x = FareTrend{}
+	mi := &file_protos_itinerary_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FareTrend) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FareTrend) ProtoMessage() {}
+
+func (x *FareTrend) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_itinerary_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FareTrend.ProtoReflect.Descriptor instead.
+func (*FareTrend) Descriptor() ([]byte, []int) {
+	return file_protos_itinerary_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *FareTrend) GetOrigin() string {
+	if x != nil {
+		return x.Origin
+	}
+	return ""
+}
+
+func (x *FareTrend) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+func (x *FareTrend) GetFromDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FromDate
+	}
+	return nil
+}
+
+func (x *FareTrend) GetToDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ToDate
+	}
+	return nil
+}
+
+func (x *FareTrend) GetTravelerCount() int32 {
+	if x != nil {
+		return x.TravelerCount
+	}
+	return 0
+}
+
+func (x *FareTrend) GetWeeks() []*WeekPrice {
+	if x != nil {
+		return x.Weeks
+	}
+	return nil
+}
+
+func (x *FareTrend) GetCheapestWeek() *WeekPrice {
+	if x != nil {
+		return x.CheapestWeek
+	}
+	return nil
+}
+
+func (x *FareTrend) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *FareTrend) GetSearches() int32 {
+	if x != nil {
+		return x.Searches
+	}
+	return 0
+}
+
 var File_protos_itinerary_proto protoreflect.FileDescriptor
 
 const file_protos_itinerary_proto_rawDesc = "" +
 	"\n" +
-	"\x16protos/itinerary.proto\x12\ftravelingman\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x13protos/common.proto\"\x81\x01\n" +
+	"\x16protos/itinerary.proto\x12\ftravelingman\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x13protos/common.proto\"\xaf\x02\n" +
 	"\x18AccommodationPreferences\x12\x1b\n" +
 	"\troom_type\x18\x01 \x01(\tR\broomType\x12\x12\n" +
 	"\x04area\x18\x02 \x01(\tR\x04area\x12\x16\n" +
 	"\x06rating\x18\x03 \x01(\x05R\x06rating\x12\x1c\n" +
-	"\tamenities\x18\x04 \x03(\tR\tamenities\"\xa6\x02\n" +
+	"\tamenities\x18\x04 \x03(\tR\tamenities\x12\x16\n" +
+	"\x06strict\x18\x05 \x01(\bR\x06strict\x12*\n" +
+	"\x11max_nightly_price\x18\x06 \x01(\x01R\x0fmaxNightlyPrice\x12\x1c\n" +
+	"\tbreakfast\x18\a \x01(\bR\tbreakfast\x12'\n" +
+	"\x0fstrict_location\x18\b \x01(\bR\x0estrictLocation\x12!\n" +
+	"\fnear_transit\x18\t \x01(\bR\vnearTransit\"\x91\x03\n" +
 	"\x11FlightPreferences\x126\n" +
-	"\ftravel_class\x18\x01 \x01(\x0e2\x13.travelingman.ClassR\vtravelClass\x12\x1b\n" +
-	"\tmax_stops\x18\x02 \x01(\x05R\bmaxStops\x12:\n" +
+	"\ftravel_class\x18\x01 \x01(\x0e2\x13.travelingman.ClassR\vtravelClass\x12 \n" +
+	"\tmax_stops\x18\x02 \x01(\x05H\x00R\bmaxStops\x88\x01\x01\x12:\n" +
 	"\x19preferred_origin_airports\x18\x03 \x03(\tR\x17preferredOriginAirports\x12D\n" +
 	"\x1epreferred_destination_airports\x18\x04 \x03(\tR\x1cpreferredDestinationAirports\x12:\n" +
-	"\abaggage\x18\x05 \x01(\v2 .travelingman.BaggagePreferencesR\abaggage\"g\n" +
+	"\abaggage\x18\x05 \x01(\v2 .travelingman.BaggagePreferencesR\abaggage\x12\x1b\n" +
+	"\tmax_price\x18\x06 \x01(\x01R\bmaxPrice\x129\n" +
+	"\n" +
+	"arrival_by\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tarrivalByB\f\n" +
+	"\n" +
+	"_max_stops\"g\n" +
 	"\x10TrainPreferences\x126\n" +
 	"\ftravel_class\x18\x01 \x01(\x0e2\x13.travelingman.ClassR\vtravelClass\x12\x1b\n" +
 	"\tseat_type\x18\x02 \x01(\tR\bseatType\"s\n" +
@@ -1718,7 +2413,13 @@ const file_protos_itinerary_proto_rawDesc = "" +
 	"\x05Error\x12\x18\n" +
 	"\amessage\x18\x01 \x01(\tR\amessage\x12+\n" +
 	"\x04code\x18\x02 \x01(\x0e2\x17.travelingman.ErrorCodeR\x04code\x127\n" +
-	"\bseverity\x18\x03 \x01(\x0e2\x1b.travelingman.ErrorSeverityR\bseverity\"\xaa\x04\n" +
+	"\bseverity\x18\x03 \x01(\x0e2\x1b.travelingman.ErrorSeverityR\bseverity\"\x92\x02\n" +
+	"\rPaymentPolicy\x122\n" +
+	"\x15accepted_card_vendors\x18\x01 \x03(\tR\x13acceptedCardVendors\x12)\n" +
+	"\x10accepted_methods\x18\x02 \x03(\tR\x0facceptedMethods\x12-\n" +
+	"\x12guarantee_required\x18\x03 \x01(\bR\x11guaranteeRequired\x12,\n" +
+	"\adeposit\x18\x04 \x01(\v2\x12.travelingman.CostR\adeposit\x12E\n" +
+	"\x10deposit_deadline\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x0fdepositDeadline\"\x9f\a\n" +
 	"\rAccommodation\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x19\n" +
 	"\bgroup_id\x18\x02 \x01(\x03R\agroupId\x12\x12\n" +
@@ -1733,7 +2434,18 @@ const file_protos_itinerary_proto_rawDesc = "" +
 	"\x0etraveler_count\x18\f \x01(\x05R\rtravelerCount\x122\n" +
 	"\blocation\x18\r \x01(\v2\x16.travelingman.LocationR\blocation\x12)\n" +
 	"\x05error\x18\x0e \x01(\v2\x13.travelingman.ErrorR\x05error\x12\x12\n" +
-	"\x04tags\x18\x0f \x03(\tR\x04tags\"\x94\a\n" +
+	"\x04tags\x18\x0f \x03(\tR\x04tags\x12\x19\n" +
+	"\bhotel_id\x18\x10 \x01(\tR\ahotelId\x12+\n" +
+	"\x11property_cheapest\x18\x11 \x01(\bR\x10propertyCheapest\x125\n" +
+	"\fnightly_cost\x18\x12 \x01(\v2\x12.travelingman.CostR\vnightlyCost\x12-\n" +
+	"\x12breakfast_included\x18\x13 \x01(\bR\x11breakfastIncluded\x12B\n" +
+	"\x0epayment_policy\x18\x14 \x01(\v2\x1b.travelingman.PaymentPolicyR\rpaymentPolicy\x12\x1a\n" +
+	"\bwarnings\x18\x15 \x03(\tR\bwarnings\x12\x1d\n" +
+	"\n" +
+	"child_ages\x18\x16 \x03(\x05R\tchildAges\x12\x1d\n" +
+	"\n" +
+	"chain_code\x18\x17 \x01(\tR\tchainCode\x12'\n" +
+	"\x0fpreferred_chain\x18\x18 \x01(\bR\x0epreferredChain\"\x94\a\n" +
 	"\tTransport\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1d\n" +
 	"\n" +
@@ -1757,7 +2469,7 @@ const file_protos_itinerary_proto_rawDesc = "" +
 	"\x05train\x18\r \x01(\v2\x13.travelingman.TrainH\x00R\x05train\x128\n" +
 	"\n" +
 	"car_rental\x18\x0e \x01(\v2\x17.travelingman.CarRentalH\x00R\tcarRentalB\t\n" +
-	"\adetails\"\xb4\x04\n" +
+	"\adetails\"\x8a\x05\n" +
 	"\x06Flight\x12!\n" +
 	"\fcarrier_code\x18\x01 \x01(\tR\vcarrierCode\x12#\n" +
 	"\rflight_number\x18\x02 \x01(\tR\fflightNumber\x12A\n" +
@@ -1769,7 +2481,9 @@ const file_protos_itinerary_proto_rawDesc = "" +
 	"\bsegments\x18\b \x03(\v2\x1b.travelingman.FlightSegmentR\bsegments\x12#\n" +
 	"\rlayover_count\x18\t \x01(\x05R\flayoverCount\x12%\n" +
 	"\x0etotal_duration\x18\n" +
-	" \x01(\tR\rtotalDuration\"\xf3\x02\n" +
+	" \x01(\tR\rtotalDuration\x12!\n" +
+	"\fcarrier_name\x18\v \x01(\tR\vcarrierName\x121\n" +
+	"\blayovers\x18\f \x03(\v2\x15.travelingman.LayoverR\blayovers\"\xf0\x03\n" +
 	"\rFlightSegment\x12!\n" +
 	"\fcarrier_code\x18\x01 \x01(\tR\vcarrierCode\x12#\n" +
 	"\rflight_number\x18\x02 \x01(\tR\fflightNumber\x12A\n" +
@@ -1778,7 +2492,16 @@ const file_protos_itinerary_proto_rawDesc = "" +
 	"\x16departure_airport_code\x18\x05 \x01(\tR\x14departureAirportCode\x120\n" +
 	"\x14arrival_airport_code\x18\x06 \x01(\tR\x12arrivalAirportCode\x12\x1a\n" +
 	"\bduration\x18\a \x01(\tR\bduration\x12\x14\n" +
-	"\x05stops\x18\b \x01(\x05R\x05stops\"\xac\x01\n" +
+	"\x05stops\x18\b \x01(\x05R\x05stops\x12-\n" +
+	"\x12departure_terminal\x18\t \x01(\tR\x11departureTerminal\x12)\n" +
+	"\x10arrival_terminal\x18\n" +
+	" \x01(\tR\x0farrivalTerminal\x12!\n" +
+	"\fcarrier_name\x18\v \x01(\tR\vcarrierName\"\xd9\x01\n" +
+	"\aLayover\x12!\n" +
+	"\fairport_code\x18\x01 \x01(\tR\vairportCode\x12=\n" +
+	"\farrival_time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\varrivalTime\x12A\n" +
+	"\x0edeparture_time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\rdepartureTime\x12)\n" +
+	"\x10duration_seconds\x18\x04 \x01(\x03R\x0fdurationSeconds\"\xac\x01\n" +
 	"\x05Train\x12A\n" +
 	"\x0edeparture_time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\rdepartureTime\x12=\n" +
 	"\farrival_time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\varrivalTime\x12!\n" +
@@ -1788,7 +2511,40 @@ const file_protos_itinerary_proto_rawDesc = "" +
 	"\vpickup_time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
 	"pickupTime\x12=\n" +
 	"\fdropoff_time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\vdropoffTime\x12\x19\n" +
-	"\bcar_type\x18\x04 \x01(\tR\acarType*\x98\x01\n" +
+	"\bcar_type\x18\x04 \x01(\tR\acarType\"\xba\x01\n" +
+	"\bDayPrice\x12.\n" +
+	"\x04date\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04date\x12&\n" +
+	"\x04cost\x18\x02 \x01(\v2\x12.travelingman.CostR\x04cost\x12\"\n" +
+	"\finterpolated\x18\x03 \x01(\bR\finterpolated\x12\x15\n" +
+	"\x06is_min\x18\x04 \x01(\bR\x05isMin\x12\x1b\n" +
+	"\tis_median\x18\x05 \x01(\bR\bisMedian\"\xb2\x02\n" +
+	"\rPriceCalendar\x12\x16\n" +
+	"\x06origin\x18\x01 \x01(\tR\x06origin\x12 \n" +
+	"\vdestination\x18\x02 \x01(\tR\vdestination\x12\x14\n" +
+	"\x05month\x18\x03 \x01(\tR\x05month\x12%\n" +
+	"\x0etraveler_count\x18\x04 \x01(\x05R\rtravelerCount\x12*\n" +
+	"\x04days\x18\x05 \x03(\v2\x16.travelingman.DayPriceR\x04days\x12/\n" +
+	"\tmin_price\x18\x06 \x01(\v2\x12.travelingman.CostR\bminPrice\x125\n" +
+	"\fmedian_price\x18\a \x01(\v2\x12.travelingman.CostR\vmedianPrice\x12\x16\n" +
+	"\x06source\x18\b \x01(\tR\x06source\"\xa1\x02\n" +
+	"\tWeekPrice\x129\n" +
+	"\n" +
+	"week_start\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\tweekStart\x125\n" +
+	"\bweek_end\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\aweekEnd\x12&\n" +
+	"\x04cost\x18\x03 \x01(\v2\x12.travelingman.CostR\x04cost\x12?\n" +
+	"\rcheapest_date\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\fcheapestDate\x12\"\n" +
+	"\finterpolated\x18\x05 \x01(\bR\finterpolated\x12\x15\n" +
+	"\x06is_min\x18\x06 \x01(\bR\x05isMin\"\xfb\x02\n" +
+	"\tFareTrend\x12\x16\n" +
+	"\x06origin\x18\x01 \x01(\tR\x06origin\x12 \n" +
+	"\vdestination\x18\x02 \x01(\tR\vdestination\x127\n" +
+	"\tfrom_date\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\bfromDate\x123\n" +
+	"\ato_date\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x06toDate\x12%\n" +
+	"\x0etraveler_count\x18\x05 \x01(\x05R\rtravelerCount\x12-\n" +
+	"\x05weeks\x18\x06 \x03(\v2\x17.travelingman.WeekPriceR\x05weeks\x12<\n" +
+	"\rcheapest_week\x18\a \x01(\v2\x17.travelingman.WeekPriceR\fcheapestWeek\x12\x16\n" +
+	"\x06source\x18\b \x01(\tR\x06source\x12\x1a\n" +
+	"\bsearches\x18\t \x01(\x05R\bsearches*\x98\x01\n" +
 	"\rTransportType\x12\x1e\n" +
 	"\x1aTRANSPORT_TYPE_UNSPECIFIED\x10\x00\x12\x19\n" +
 	"\x15TRANSPORT_TYPE_FLIGHT\x10\x01\x12\x18\n" +
@@ -1808,7 +2564,7 @@ const file_protos_itinerary_proto_rawDesc = "" +
 	"\fTransmission\x12\x1c\n" +
 	"\x18TRANSMISSION_UNSPECIFIED\x10\x00\x12\x17\n" +
 	"\x13TRANSMISSION_MANUAL\x10\x01\x12\x1a\n" +
-	"\x16TRANSMISSION_AUTOMATIC\x10\x02*\x92\x02\n" +
+	"\x16TRANSMISSION_AUTOMATIC\x10\x02*\xb2\x02\n" +
 	"\tErrorCode\x12\x1a\n" +
 	"\x16ERROR_CODE_UNSPECIFIED\x10\x00\x12\x1c\n" +
 	"\x18ERROR_CODE_SEARCH_FAILED\x10\x01\x12\x1d\n" +
@@ -1817,7 +2573,8 @@ const file_protos_itinerary_proto_rawDesc = "" +
 	"\x18ERROR_CODE_INVALID_INPUT\x10\x04\x12$\n" +
 	" ERROR_CODE_AUTHENTICATION_FAILED\x10\x05\x12$\n" +
 	" ERROR_CODE_INTERNAL_SERVER_ERROR\x10\x06\x12 \n" +
-	"\x1cERROR_CODE_CONNECTION_FAILED\x10\a*~\n" +
+	"\x1cERROR_CODE_CONNECTION_FAILED\x10\a\x12\x1e\n" +
+	"\x1aERROR_CODE_NO_AVAILABILITY\x10\b*~\n" +
 	"\rErrorSeverity\x12\x1e\n" +
 	"\x1aERROR_SEVERITY_UNSPECIFIED\x10\x00\x12\x17\n" +
 	"\x13ERROR_SEVERITY_INFO\x10\x01\x12\x1a\n" +
@@ -1837,7 +2594,7 @@ func file_protos_itinerary_proto_rawDescGZIP() []byte {
 }
 
 var file_protos_itinerary_proto_enumTypes = make([]protoimpl.EnumInfo, 6)
-var file_protos_itinerary_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_protos_itinerary_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
 var file_protos_itinerary_proto_goTypes = []any{
 	(TransportType)(0),               // 0: travelingman.TransportType
 	(Class)(0),                       // 1: travelingman.Class
@@ -1854,58 +2611,85 @@ var file_protos_itinerary_proto_goTypes = []any{
 	(*AncillaryCost)(nil),            // 12: travelingman.AncillaryCost
 	(*Location)(nil),                 // 13: travelingman.Location
 	(*Error)(nil),                    // 14: travelingman.Error
-	(*Accommodation)(nil),            // 15: travelingman.Accommodation
-	(*Transport)(nil),                // 16: travelingman.Transport
-	(*Flight)(nil),                   // 17: travelingman.Flight
-	(*FlightSegment)(nil),            // 18: travelingman.FlightSegment
-	(*Train)(nil),                    // 19: travelingman.Train
-	(*CarRental)(nil),                // 20: travelingman.CarRental
-	(*Cost)(nil),                     // 21: travelingman.Cost
-	(*timestamppb.Timestamp)(nil),    // 22: google.protobuf.Timestamp
+	(*PaymentPolicy)(nil),            // 15: travelingman.PaymentPolicy
+	(*Accommodation)(nil),            // 16: travelingman.Accommodation
+	(*Transport)(nil),                // 17: travelingman.Transport
+	(*Flight)(nil),                   // 18: travelingman.Flight
+	(*FlightSegment)(nil),            // 19: travelingman.FlightSegment
+	(*Layover)(nil),                  // 20: travelingman.Layover
+	(*Train)(nil),                    // 21: travelingman.Train
+	(*CarRental)(nil),                // 22: travelingman.CarRental
+	(*DayPrice)(nil),                 // 23: travelingman.DayPrice
+	(*PriceCalendar)(nil),            // 24: travelingman.PriceCalendar
+	(*WeekPrice)(nil),                // 25: travelingman.WeekPrice
+	(*FareTrend)(nil),                // 26: travelingman.FareTrend
+	(*timestamppb.Timestamp)(nil),    // 27: google.protobuf.Timestamp
+	(*Cost)(nil),                     // 28: travelingman.Cost
 }
 var file_protos_itinerary_proto_depIdxs = []int32{
 	1,  // 0: travelingman.FlightPreferences.travel_class:type_name -> travelingman.Class
 	10, // 1: travelingman.FlightPreferences.baggage:type_name -> travelingman.BaggagePreferences
-	1,  // 2: travelingman.TrainPreferences.travel_class:type_name -> travelingman.Class
-	3,  // 3: travelingman.CarRentalPreferences.transmission:type_name -> travelingman.Transmission
-	2,  // 4: travelingman.BaggagePolicy.type:type_name -> travelingman.BaggageType
-	21, // 5: travelingman.AncillaryCost.cost:type_name -> travelingman.Cost
-	4,  // 6: travelingman.Error.code:type_name -> travelingman.ErrorCode
-	5,  // 7: travelingman.Error.severity:type_name -> travelingman.ErrorSeverity
-	22, // 8: travelingman.Accommodation.check_in:type_name -> google.protobuf.Timestamp
-	22, // 9: travelingman.Accommodation.check_out:type_name -> google.protobuf.Timestamp
-	21, // 10: travelingman.Accommodation.cost:type_name -> travelingman.Cost
-	6,  // 11: travelingman.Accommodation.preferences:type_name -> travelingman.AccommodationPreferences
-	13, // 12: travelingman.Accommodation.location:type_name -> travelingman.Location
-	14, // 13: travelingman.Accommodation.error:type_name -> travelingman.Error
-	0,  // 14: travelingman.Transport.type:type_name -> travelingman.TransportType
-	13, // 15: travelingman.Transport.origin_location:type_name -> travelingman.Location
-	13, // 16: travelingman.Transport.destination_location:type_name -> travelingman.Location
-	21, // 17: travelingman.Transport.cost:type_name -> travelingman.Cost
-	7,  // 18: travelingman.Transport.flight_preferences:type_name -> travelingman.FlightPreferences
-	8,  // 19: travelingman.Transport.train_preferences:type_name -> travelingman.TrainPreferences
-	9,  // 20: travelingman.Transport.car_rental_preferences:type_name -> travelingman.CarRentalPreferences
-	14, // 21: travelingman.Transport.error:type_name -> travelingman.Error
-	17, // 22: travelingman.Transport.flight:type_name -> travelingman.Flight
-	19, // 23: travelingman.Transport.train:type_name -> travelingman.Train
-	20, // 24: travelingman.Transport.car_rental:type_name -> travelingman.CarRental
-	22, // 25: travelingman.Flight.departure_time:type_name -> google.protobuf.Timestamp
-	22, // 26: travelingman.Flight.arrival_time:type_name -> google.protobuf.Timestamp
-	11, // 27: travelingman.Flight.baggage_policy:type_name -> travelingman.BaggagePolicy
-	12, // 28: travelingman.Flight.ancillary_costs:type_name -> travelingman.AncillaryCost
-	21, // 29: travelingman.Flight.total_cost_with_ancillaries:type_name -> travelingman.Cost
-	18, // 30: travelingman.Flight.segments:type_name -> travelingman.FlightSegment
-	22, // 31: travelingman.FlightSegment.departure_time:type_name -> google.protobuf.Timestamp
-	22, // 32: travelingman.FlightSegment.arrival_time:type_name -> google.protobuf.Timestamp
-	22, // 33: travelingman.Train.departure_time:type_name -> google.protobuf.Timestamp
-	22, // 34: travelingman.Train.arrival_time:type_name -> google.protobuf.Timestamp
-	22, // 35: travelingman.CarRental.pickup_time:type_name -> google.protobuf.Timestamp
-	22, // 36: travelingman.CarRental.dropoff_time:type_name -> google.protobuf.Timestamp
-	37, // [37:37] is the sub-list for method output_type
-	37, // [37:37] is the sub-list for method input_type
-	37, // [37:37] is the sub-list for extension type_name
-	37, // [37:37] is the sub-list for extension extendee
-	0,  // [0:37] is the sub-list for field type_name
+	27, // 2: travelingman.FlightPreferences.arrival_by:type_name -> google.protobuf.Timestamp
+	1,  // 3: travelingman.TrainPreferences.travel_class:type_name -> travelingman.Class
+	3,  // 4: travelingman.CarRentalPreferences.transmission:type_name -> travelingman.Transmission
+	2,  // 5: travelingman.BaggagePolicy.type:type_name -> travelingman.BaggageType
+	28, // 6: travelingman.AncillaryCost.cost:type_name -> travelingman.Cost
+	4,  // 7: travelingman.Error.code:type_name -> travelingman.ErrorCode
+	5,  // 8: travelingman.Error.severity:type_name -> travelingman.ErrorSeverity
+	28, // 9: travelingman.PaymentPolicy.deposit:type_name -> travelingman.Cost
+	27, // 10: travelingman.PaymentPolicy.deposit_deadline:type_name -> google.protobuf.Timestamp
+	27, // 11: travelingman.Accommodation.check_in:type_name -> google.protobuf.Timestamp
+	27, // 12: travelingman.Accommodation.check_out:type_name -> google.protobuf.Timestamp
+	28, // 13: travelingman.Accommodation.cost:type_name -> travelingman.Cost
+	6,  // 14: travelingman.Accommodation.preferences:type_name -> travelingman.AccommodationPreferences
+	13, // 15: travelingman.Accommodation.location:type_name -> travelingman.Location
+	14, // 16: travelingman.Accommodation.error:type_name -> travelingman.Error
+	28, // 17: travelingman.Accommodation.nightly_cost:type_name -> travelingman.Cost
+	15, // 18: travelingman.Accommodation.payment_policy:type_name -> travelingman.PaymentPolicy
+	0,  // 19: travelingman.Transport.type:type_name -> travelingman.TransportType
+	13, // 20: travelingman.Transport.origin_location:type_name -> travelingman.Location
+	13, // 21: travelingman.Transport.destination_location:type_name -> travelingman.Location
+	28, // 22: travelingman.Transport.cost:type_name -> travelingman.Cost
+	7,  // 23: travelingman.Transport.flight_preferences:type_name -> travelingman.FlightPreferences
+	8,  // 24: travelingman.Transport.train_preferences:type_name -> travelingman.TrainPreferences
+	9,  // 25: travelingman.Transport.car_rental_preferences:type_name -> travelingman.CarRentalPreferences
+	14, // 26: travelingman.Transport.error:type_name -> travelingman.Error
+	18, // 27: travelingman.Transport.flight:type_name -> travelingman.Flight
+	21, // 28: travelingman.Transport.train:type_name -> travelingman.Train
+	22, // 29: travelingman.Transport.car_rental:type_name -> travelingman.CarRental
+	27, // 30: travelingman.Flight.departure_time:type_name -> google.protobuf.Timestamp
+	27, // 31: travelingman.Flight.arrival_time:type_name -> google.protobuf.Timestamp
+	11, // 32: travelingman.Flight.baggage_policy:type_name -> travelingman.BaggagePolicy
+	12, // 33: travelingman.Flight.ancillary_costs:type_name -> travelingman.AncillaryCost
+	28, // 34: travelingman.Flight.total_cost_with_ancillaries:type_name -> travelingman.Cost
+	19, // 35: travelingman.Flight.segments:type_name -> travelingman.FlightSegment
+	20, // 36: travelingman.Flight.layovers:type_name -> travelingman.Layover
+	27, // 37: travelingman.FlightSegment.departure_time:type_name -> google.protobuf.Timestamp
+	27, // 38: travelingman.FlightSegment.arrival_time:type_name -> google.protobuf.Timestamp
+	27, // 39: travelingman.Layover.arrival_time:type_name -> google.protobuf.Timestamp
+	27, // 40: travelingman.Layover.departure_time:type_name -> google.protobuf.Timestamp
+	27, // 41: travelingman.Train.departure_time:type_name -> google.protobuf.Timestamp
+	27, // 42: travelingman.Train.arrival_time:type_name -> google.protobuf.Timestamp
+	27, // 43: travelingman.CarRental.pickup_time:type_name -> google.protobuf.Timestamp
+	27, // 44: travelingman.CarRental.dropoff_time:type_name -> google.protobuf.Timestamp
+	27, // 45: travelingman.DayPrice.date:type_name -> google.protobuf.Timestamp
+	28, // 46: travelingman.DayPrice.cost:type_name -> travelingman.Cost
+	23, // 47: travelingman.PriceCalendar.days:type_name -> travelingman.DayPrice
+	28, // 48: travelingman.PriceCalendar.min_price:type_name -> travelingman.Cost
+	28, // 49: travelingman.PriceCalendar.median_price:type_name -> travelingman.Cost
+	27, // 50: travelingman.WeekPrice.week_start:type_name -> google.protobuf.Timestamp
+	27, // 51: travelingman.WeekPrice.week_end:type_name -> google.protobuf.Timestamp
+	28, // 52: travelingman.WeekPrice.cost:type_name -> travelingman.Cost
+	27, // 53: travelingman.WeekPrice.cheapest_date:type_name -> google.protobuf.Timestamp
+	27, // 54: travelingman.FareTrend.from_date:type_name -> google.protobuf.Timestamp
+	27, // 55: travelingman.FareTrend.to_date:type_name -> google.protobuf.Timestamp
+	25, // 56: travelingman.FareTrend.weeks:type_name -> travelingman.WeekPrice
+	25, // 57: travelingman.FareTrend.cheapest_week:type_name -> travelingman.WeekPrice
+	58, // [58:58] is the sub-list for method output_type
+	58, // [58:58] is the sub-list for method input_type
+	58, // [58:58] is the sub-list for extension type_name
+	58, // [58:58] is the sub-list for extension extendee
+	0,  // [0:58] is the sub-list for field type_name
 }
 
 func init() { file_protos_itinerary_proto_init() }
@@ -1914,7 +2698,8 @@ func file_protos_itinerary_proto_init() {
 		return
 	}
 	file_protos_common_proto_init()
-	file_protos_itinerary_proto_msgTypes[10].OneofWrappers = []any{
+	file_protos_itinerary_proto_msgTypes[1].OneofWrappers = []any{}
+	file_protos_itinerary_proto_msgTypes[11].OneofWrappers = []any{
 		(*Transport_Flight)(nil),
 		(*Transport_Train)(nil),
 		(*Transport_CarRental)(nil),
@@ -1925,7 +2710,7 @@ func file_protos_itinerary_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_protos_itinerary_proto_rawDesc), len(file_protos_itinerary_proto_rawDesc)),
 			NumEnums:      6,
-			NumMessages:   15,
+			NumMessages:   21,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -35,11 +35,74 @@ const (
 const (
 	// TravelServicePlanTripProcedure is the fully-qualified name of the TravelService's PlanTrip RPC.
 	TravelServicePlanTripProcedure = "/travelingman.TravelService/PlanTrip"
+	// TravelServicePlanTripStreamProcedure is the fully-qualified name of the TravelService's
+	// PlanTripStream RPC.
+	TravelServicePlanTripStreamProcedure = "/travelingman.TravelService/PlanTripStream"
+	// TravelServiceGetPriceCalendarProcedure is the fully-qualified name of the TravelService's
+	// GetPriceCalendar RPC.
+	TravelServiceGetPriceCalendarProcedure = "/travelingman.TravelService/GetPriceCalendar"
+	// TravelServiceGetFareTrendProcedure is the fully-qualified name of the TravelService's
+	// GetFareTrend RPC.
+	TravelServiceGetFareTrendProcedure = "/travelingman.TravelService/GetFareTrend"
+	// TravelServiceSaveTripProcedure is the fully-qualified name of the TravelService's SaveTrip RPC.
+	TravelServiceSaveTripProcedure = "/travelingman.TravelService/SaveTrip"
+	// TravelServiceUpdateTripProcedure is the fully-qualified name of the TravelService's UpdateTrip
+	// RPC.
+	TravelServiceUpdateTripProcedure = "/travelingman.TravelService/UpdateTrip"
+	// TravelServiceVerifyPlanProcedure is the fully-qualified name of the TravelService's VerifyPlan
+	// RPC.
+	TravelServiceVerifyPlanProcedure = "/travelingman.TravelService/VerifyPlan"
+	// TravelServiceGetTripGraphProcedure is the fully-qualified name of the TravelService's
+	// GetTripGraph RPC.
+	TravelServiceGetTripGraphProcedure = "/travelingman.TravelService/GetTripGraph"
+	// TravelServiceGetTripCalendarProcedure is the fully-qualified name of the TravelService's
+	// GetTripCalendar RPC.
+	TravelServiceGetTripCalendarProcedure = "/travelingman.TravelService/GetTripCalendar"
+	// TravelServiceGetMoreOptionsProcedure is the fully-qualified name of the TravelService's
+	// GetMoreOptions RPC.
+	TravelServiceGetMoreOptionsProcedure = "/travelingman.TravelService/GetMoreOptions"
+	// TravelServiceAutocompleteLocationsProcedure is the fully-qualified name of the TravelService's
+	// AutocompleteLocations RPC.
+	TravelServiceAutocompleteLocationsProcedure = "/travelingman.TravelService/AutocompleteLocations"
+	// TravelServiceBookFlightProcedure is the fully-qualified name of the TravelService's BookFlight
+	// RPC.
+	TravelServiceBookFlightProcedure = "/travelingman.TravelService/BookFlight"
+	// TravelServiceRefreshBookingStatusProcedure is the fully-qualified name of the TravelService's
+	// RefreshBookingStatus RPC.
+	TravelServiceRefreshBookingStatusProcedure = "/travelingman.TravelService/RefreshBookingStatus"
+	// TravelServiceGetBookingSplitsProcedure is the fully-qualified name of the TravelService's
+	// GetBookingSplits RPC.
+	TravelServiceGetBookingSplitsProcedure = "/travelingman.TravelService/GetBookingSplits"
+	// TravelServiceCancelBookingProcedure is the fully-qualified name of the TravelService's
+	// CancelBooking RPC.
+	TravelServiceCancelBookingProcedure = "/travelingman.TravelService/CancelBooking"
+	// TravelServiceResumeBookingProcedure is the fully-qualified name of the TravelService's
+	// ResumeBooking RPC.
+	TravelServiceResumeBookingProcedure = "/travelingman.TravelService/ResumeBooking"
+	// TravelServiceGetItineraryProcedure is the fully-qualified name of the TravelService's
+	// GetItinerary RPC.
+	TravelServiceGetItineraryProcedure = "/travelingman.TravelService/GetItinerary"
 )
 
 // TravelServiceClient is a client for the travelingman.TravelService service.
 type TravelServiceClient interface {
 	PlanTrip(context.Context, *connect.Request[pb.PlanTripRequest]) (*connect.Response[pb.PlanTripResponse], error)
+	PlanTripStream(context.Context, *connect.Request[pb.PlanTripRequest]) (*connect.ServerStreamForClient[pb.PlanTripResponse], error)
+	GetPriceCalendar(context.Context, *connect.Request[pb.GetPriceCalendarRequest]) (*connect.Response[pb.GetPriceCalendarResponse], error)
+	GetFareTrend(context.Context, *connect.Request[pb.GetFareTrendRequest]) (*connect.Response[pb.GetFareTrendResponse], error)
+	SaveTrip(context.Context, *connect.Request[pb.SaveTripRequest]) (*connect.Response[pb.SaveTripResponse], error)
+	UpdateTrip(context.Context, *connect.Request[pb.UpdateTripRequest]) (*connect.Response[pb.UpdateTripResponse], error)
+	VerifyPlan(context.Context, *connect.Request[pb.VerifyPlanRequest]) (*connect.Response[pb.VerifyPlanResponse], error)
+	GetTripGraph(context.Context, *connect.Request[pb.GetTripGraphRequest]) (*connect.Response[pb.GetTripGraphResponse], error)
+	GetTripCalendar(context.Context, *connect.Request[pb.GetTripCalendarRequest]) (*connect.Response[pb.GetTripCalendarResponse], error)
+	GetMoreOptions(context.Context, *connect.Request[pb.GetMoreOptionsRequest]) (*connect.Response[pb.GetMoreOptionsResponse], error)
+	AutocompleteLocations(context.Context, *connect.Request[pb.AutocompleteLocationsRequest]) (*connect.Response[pb.AutocompleteLocationsResponse], error)
+	BookFlight(context.Context, *connect.Request[pb.BookFlightRequest]) (*connect.Response[pb.BookFlightResponse], error)
+	RefreshBookingStatus(context.Context, *connect.Request[pb.RefreshBookingStatusRequest]) (*connect.Response[pb.RefreshBookingStatusResponse], error)
+	GetBookingSplits(context.Context, *connect.Request[pb.GetBookingSplitsRequest]) (*connect.Response[pb.GetBookingSplitsResponse], error)
+	CancelBooking(context.Context, *connect.Request[pb.CancelBookingRequest]) (*connect.Response[pb.CancelBookingResponse], error)
+	ResumeBooking(context.Context, *connect.Request[pb.ResumeBookingRequest]) (*connect.Response[pb.ResumeBookingResponse], error)
+	GetItinerary(context.Context, *connect.Request[pb.GetItineraryRequest]) (*connect.Response[pb.GetItineraryResponse], error)
 }
 
 // NewTravelServiceClient constructs a client for the travelingman.TravelService service. By
@@ -59,12 +122,127 @@ func NewTravelServiceClient(httpClient connect.HTTPClient, baseURL string, opts
 			connect.WithSchema(travelServiceMethods.ByName("PlanTrip")),
 			connect.WithClientOptions(opts...),
 		),
+		planTripStream: connect.NewClient[pb.PlanTripRequest, pb.PlanTripResponse](
+			httpClient,
+			baseURL+TravelServicePlanTripStreamProcedure,
+			connect.WithSchema(travelServiceMethods.ByName("PlanTripStream")),
+			connect.WithClientOptions(opts...),
+		),
+		getPriceCalendar: connect.NewClient[pb.GetPriceCalendarRequest, pb.GetPriceCalendarResponse](
+			httpClient,
+			baseURL+TravelServiceGetPriceCalendarProcedure,
+			connect.WithSchema(travelServiceMethods.ByName("GetPriceCalendar")),
+			connect.WithClientOptions(opts...),
+		),
+		getFareTrend: connect.NewClient[pb.GetFareTrendRequest, pb.GetFareTrendResponse](
+			httpClient,
+			baseURL+TravelServiceGetFareTrendProcedure,
+			connect.WithSchema(travelServiceMethods.ByName("GetFareTrend")),
+			connect.WithClientOptions(opts...),
+		),
+		saveTrip: connect.NewClient[pb.SaveTripRequest, pb.SaveTripResponse](
+			httpClient,
+			baseURL+TravelServiceSaveTripProcedure,
+			connect.WithSchema(travelServiceMethods.ByName("SaveTrip")),
+			connect.WithClientOptions(opts...),
+		),
+		updateTrip: connect.NewClient[pb.UpdateTripRequest, pb.UpdateTripResponse](
+			httpClient,
+			baseURL+TravelServiceUpdateTripProcedure,
+			connect.WithSchema(travelServiceMethods.ByName("UpdateTrip")),
+			connect.WithClientOptions(opts...),
+		),
+		verifyPlan: connect.NewClient[pb.VerifyPlanRequest, pb.VerifyPlanResponse](
+			httpClient,
+			baseURL+TravelServiceVerifyPlanProcedure,
+			connect.WithSchema(travelServiceMethods.ByName("VerifyPlan")),
+			connect.WithClientOptions(opts...),
+		),
+		getTripGraph: connect.NewClient[pb.GetTripGraphRequest, pb.GetTripGraphResponse](
+			httpClient,
+			baseURL+TravelServiceGetTripGraphProcedure,
+			connect.WithSchema(travelServiceMethods.ByName("GetTripGraph")),
+			connect.WithClientOptions(opts...),
+		),
+		getTripCalendar: connect.NewClient[pb.GetTripCalendarRequest, pb.GetTripCalendarResponse](
+			httpClient,
+			baseURL+TravelServiceGetTripCalendarProcedure,
+			connect.WithSchema(travelServiceMethods.ByName("GetTripCalendar")),
+			connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+			connect.WithClientOptions(opts...),
+		),
+		getMoreOptions: connect.NewClient[pb.GetMoreOptionsRequest, pb.GetMoreOptionsResponse](
+			httpClient,
+			baseURL+TravelServiceGetMoreOptionsProcedure,
+			connect.WithSchema(travelServiceMethods.ByName("GetMoreOptions")),
+			connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+			connect.WithClientOptions(opts...),
+		),
+		autocompleteLocations: connect.NewClient[pb.AutocompleteLocationsRequest, pb.AutocompleteLocationsResponse](
+			httpClient,
+			baseURL+TravelServiceAutocompleteLocationsProcedure,
+			connect.WithSchema(travelServiceMethods.ByName("AutocompleteLocations")),
+			connect.WithClientOptions(opts...),
+		),
+		bookFlight: connect.NewClient[pb.BookFlightRequest, pb.BookFlightResponse](
+			httpClient,
+			baseURL+TravelServiceBookFlightProcedure,
+			connect.WithSchema(travelServiceMethods.ByName("BookFlight")),
+			connect.WithClientOptions(opts...),
+		),
+		refreshBookingStatus: connect.NewClient[pb.RefreshBookingStatusRequest, pb.RefreshBookingStatusResponse](
+			httpClient,
+			baseURL+TravelServiceRefreshBookingStatusProcedure,
+			connect.WithSchema(travelServiceMethods.ByName("RefreshBookingStatus")),
+			connect.WithClientOptions(opts...),
+		),
+		getBookingSplits: connect.NewClient[pb.GetBookingSplitsRequest, pb.GetBookingSplitsResponse](
+			httpClient,
+			baseURL+TravelServiceGetBookingSplitsProcedure,
+			connect.WithSchema(travelServiceMethods.ByName("GetBookingSplits")),
+			connect.WithClientOptions(opts...),
+		),
+		cancelBooking: connect.NewClient[pb.CancelBookingRequest, pb.CancelBookingResponse](
+			httpClient,
+			baseURL+TravelServiceCancelBookingProcedure,
+			connect.WithSchema(travelServiceMethods.ByName("CancelBooking")),
+			connect.WithClientOptions(opts...),
+		),
+		resumeBooking: connect.NewClient[pb.ResumeBookingRequest, pb.ResumeBookingResponse](
+			httpClient,
+			baseURL+TravelServiceResumeBookingProcedure,
+			connect.WithSchema(travelServiceMethods.ByName("ResumeBooking")),
+			connect.WithClientOptions(opts...),
+		),
+		getItinerary: connect.NewClient[pb.GetItineraryRequest, pb.GetItineraryResponse](
+			httpClient,
+			baseURL+TravelServiceGetItineraryProcedure,
+			connect.WithSchema(travelServiceMethods.ByName("GetItinerary")),
+			connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+			connect.WithClientOptions(opts...),
+		),
 	}
 }
 
 // travelServiceClient implements TravelServiceClient.
 type travelServiceClient struct {
-	planTrip *connect.Client[pb.PlanTripRequest, pb.PlanTripResponse]
+	planTrip              *connect.Client[pb.PlanTripRequest, pb.PlanTripResponse]
+	planTripStream        *connect.Client[pb.PlanTripRequest, pb.PlanTripResponse]
+	getPriceCalendar      *connect.Client[pb.GetPriceCalendarRequest, pb.GetPriceCalendarResponse]
+	getFareTrend          *connect.Client[pb.GetFareTrendRequest, pb.GetFareTrendResponse]
+	saveTrip              *connect.Client[pb.SaveTripRequest, pb.SaveTripResponse]
+	updateTrip            *connect.Client[pb.UpdateTripRequest, pb.UpdateTripResponse]
+	verifyPlan            *connect.Client[pb.VerifyPlanRequest, pb.VerifyPlanResponse]
+	getTripGraph          *connect.Client[pb.GetTripGraphRequest, pb.GetTripGraphResponse]
+	getTripCalendar       *connect.Client[pb.GetTripCalendarRequest, pb.GetTripCalendarResponse]
+	getMoreOptions        *connect.Client[pb.GetMoreOptionsRequest, pb.GetMoreOptionsResponse]
+	autocompleteLocations *connect.Client[pb.AutocompleteLocationsRequest, pb.AutocompleteLocationsResponse]
+	bookFlight            *connect.Client[pb.BookFlightRequest, pb.BookFlightResponse]
+	refreshBookingStatus  *connect.Client[pb.RefreshBookingStatusRequest, pb.RefreshBookingStatusResponse]
+	getBookingSplits      *connect.Client[pb.GetBookingSplitsRequest, pb.GetBookingSplitsResponse]
+	cancelBooking         *connect.Client[pb.CancelBookingRequest, pb.CancelBookingResponse]
+	resumeBooking         *connect.Client[pb.ResumeBookingRequest, pb.ResumeBookingResponse]
+	getItinerary          *connect.Client[pb.GetItineraryRequest, pb.GetItineraryResponse]
 }
 
 // PlanTrip calls travelingman.TravelService.PlanTrip.
@@ -72,9 +250,105 @@ func (c *travelServiceClient) PlanTrip(ctx context.Context, req *connect.Request
 	return c.planTrip.CallUnary(ctx, req)
 }
 
+// PlanTripStream calls travelingman.TravelService.PlanTripStream.
+func (c *travelServiceClient) PlanTripStream(ctx context.Context, req *connect.Request[pb.PlanTripRequest]) (*connect.ServerStreamForClient[pb.PlanTripResponse], error) {
+	return c.planTripStream.CallServerStream(ctx, req)
+}
+
+// GetPriceCalendar calls travelingman.TravelService.GetPriceCalendar.
+func (c *travelServiceClient) GetPriceCalendar(ctx context.Context, req *connect.Request[pb.GetPriceCalendarRequest]) (*connect.Response[pb.GetPriceCalendarResponse], error) {
+	return c.getPriceCalendar.CallUnary(ctx, req)
+}
+
+// GetFareTrend calls travelingman.TravelService.GetFareTrend.
+func (c *travelServiceClient) GetFareTrend(ctx context.Context, req *connect.Request[pb.GetFareTrendRequest]) (*connect.Response[pb.GetFareTrendResponse], error) {
+	return c.getFareTrend.CallUnary(ctx, req)
+}
+
+// SaveTrip calls travelingman.TravelService.SaveTrip.
+func (c *travelServiceClient) SaveTrip(ctx context.Context, req *connect.Request[pb.SaveTripRequest]) (*connect.Response[pb.SaveTripResponse], error) {
+	return c.saveTrip.CallUnary(ctx, req)
+}
+
+// UpdateTrip calls travelingman.TravelService.UpdateTrip.
+func (c *travelServiceClient) UpdateTrip(ctx context.Context, req *connect.Request[pb.UpdateTripRequest]) (*connect.Response[pb.UpdateTripResponse], error) {
+	return c.updateTrip.CallUnary(ctx, req)
+}
+
+// VerifyPlan calls travelingman.TravelService.VerifyPlan.
+func (c *travelServiceClient) VerifyPlan(ctx context.Context, req *connect.Request[pb.VerifyPlanRequest]) (*connect.Response[pb.VerifyPlanResponse], error) {
+	return c.verifyPlan.CallUnary(ctx, req)
+}
+
+// GetTripGraph calls travelingman.TravelService.GetTripGraph.
+func (c *travelServiceClient) GetTripGraph(ctx context.Context, req *connect.Request[pb.GetTripGraphRequest]) (*connect.Response[pb.GetTripGraphResponse], error) {
+	return c.getTripGraph.CallUnary(ctx, req)
+}
+
+// GetTripCalendar calls travelingman.TravelService.GetTripCalendar.
+func (c *travelServiceClient) GetTripCalendar(ctx context.Context, req *connect.Request[pb.GetTripCalendarRequest]) (*connect.Response[pb.GetTripCalendarResponse], error) {
+	return c.getTripCalendar.CallUnary(ctx, req)
+}
+
+// GetMoreOptions calls travelingman.TravelService.GetMoreOptions.
+func (c *travelServiceClient) GetMoreOptions(ctx context.Context, req *connect.Request[pb.GetMoreOptionsRequest]) (*connect.Response[pb.GetMoreOptionsResponse], error) {
+	return c.getMoreOptions.CallUnary(ctx, req)
+}
+
+// AutocompleteLocations calls travelingman.TravelService.AutocompleteLocations.
+func (c *travelServiceClient) AutocompleteLocations(ctx context.Context, req *connect.Request[pb.AutocompleteLocationsRequest]) (*connect.Response[pb.AutocompleteLocationsResponse], error) {
+	return c.autocompleteLocations.CallUnary(ctx, req)
+}
+
+// BookFlight calls travelingman.TravelService.BookFlight.
+func (c *travelServiceClient) BookFlight(ctx context.Context, req *connect.Request[pb.BookFlightRequest]) (*connect.Response[pb.BookFlightResponse], error) {
+	return c.bookFlight.CallUnary(ctx, req)
+}
+
+// RefreshBookingStatus calls travelingman.TravelService.RefreshBookingStatus.
+func (c *travelServiceClient) RefreshBookingStatus(ctx context.Context, req *connect.Request[pb.RefreshBookingStatusRequest]) (*connect.Response[pb.RefreshBookingStatusResponse], error) {
+	return c.refreshBookingStatus.CallUnary(ctx, req)
+}
+
+// GetBookingSplits calls travelingman.TravelService.GetBookingSplits.
+func (c *travelServiceClient) GetBookingSplits(ctx context.Context, req *connect.Request[pb.GetBookingSplitsRequest]) (*connect.Response[pb.GetBookingSplitsResponse], error) {
+	return c.getBookingSplits.CallUnary(ctx, req)
+}
+
+// CancelBooking calls travelingman.TravelService.CancelBooking.
+func (c *travelServiceClient) CancelBooking(ctx context.Context, req *connect.Request[pb.CancelBookingRequest]) (*connect.Response[pb.CancelBookingResponse], error) {
+	return c.cancelBooking.CallUnary(ctx, req)
+}
+
+// ResumeBooking calls travelingman.TravelService.ResumeBooking.
+func (c *travelServiceClient) ResumeBooking(ctx context.Context, req *connect.Request[pb.ResumeBookingRequest]) (*connect.Response[pb.ResumeBookingResponse], error) {
+	return c.resumeBooking.CallUnary(ctx, req)
+}
+
+// GetItinerary calls travelingman.TravelService.GetItinerary.
+func (c *travelServiceClient) GetItinerary(ctx context.Context, req *connect.Request[pb.GetItineraryRequest]) (*connect.Response[pb.GetItineraryResponse], error) {
+	return c.getItinerary.CallUnary(ctx, req)
+}
+
 // TravelServiceHandler is an implementation of the travelingman.TravelService service.
 type TravelServiceHandler interface {
 	PlanTrip(context.Context, *connect.Request[pb.PlanTripRequest]) (*connect.Response[pb.PlanTripResponse], error)
+	PlanTripStream(context.Context, *connect.Request[pb.PlanTripRequest], *connect.ServerStream[pb.PlanTripResponse]) error
+	GetPriceCalendar(context.Context, *connect.Request[pb.GetPriceCalendarRequest]) (*connect.Response[pb.GetPriceCalendarResponse], error)
+	GetFareTrend(context.Context, *connect.Request[pb.GetFareTrendRequest]) (*connect.Response[pb.GetFareTrendResponse], error)
+	SaveTrip(context.Context, *connect.Request[pb.SaveTripRequest]) (*connect.Response[pb.SaveTripResponse], error)
+	UpdateTrip(context.Context, *connect.Request[pb.UpdateTripRequest]) (*connect.Response[pb.UpdateTripResponse], error)
+	VerifyPlan(context.Context, *connect.Request[pb.VerifyPlanRequest]) (*connect.Response[pb.VerifyPlanResponse], error)
+	GetTripGraph(context.Context, *connect.Request[pb.GetTripGraphRequest]) (*connect.Response[pb.GetTripGraphResponse], error)
+	GetTripCalendar(context.Context, *connect.Request[pb.GetTripCalendarRequest]) (*connect.Response[pb.GetTripCalendarResponse], error)
+	GetMoreOptions(context.Context, *connect.Request[pb.GetMoreOptionsRequest]) (*connect.Response[pb.GetMoreOptionsResponse], error)
+	AutocompleteLocations(context.Context, *connect.Request[pb.AutocompleteLocationsRequest]) (*connect.Response[pb.AutocompleteLocationsResponse], error)
+	BookFlight(context.Context, *connect.Request[pb.BookFlightRequest]) (*connect.Response[pb.BookFlightResponse], error)
+	RefreshBookingStatus(context.Context, *connect.Request[pb.RefreshBookingStatusRequest]) (*connect.Response[pb.RefreshBookingStatusResponse], error)
+	GetBookingSplits(context.Context, *connect.Request[pb.GetBookingSplitsRequest]) (*connect.Response[pb.GetBookingSplitsResponse], error)
+	CancelBooking(context.Context, *connect.Request[pb.CancelBookingRequest]) (*connect.Response[pb.CancelBookingResponse], error)
+	ResumeBooking(context.Context, *connect.Request[pb.ResumeBookingRequest]) (*connect.Response[pb.ResumeBookingResponse], error)
+	GetItinerary(context.Context, *connect.Request[pb.GetItineraryRequest]) (*connect.Response[pb.GetItineraryResponse], error)
 }
 
 // NewTravelServiceHandler builds an HTTP handler from the service implementation. It returns the
@@ -90,10 +364,141 @@ func NewTravelServiceHandler(svc TravelServiceHandler, opts ...connect.HandlerOp
 		connect.WithSchema(travelServiceMethods.ByName("PlanTrip")),
 		connect.WithHandlerOptions(opts...),
 	)
+	travelServicePlanTripStreamHandler := connect.NewServerStreamHandler(
+		TravelServicePlanTripStreamProcedure,
+		svc.PlanTripStream,
+		connect.WithSchema(travelServiceMethods.ByName("PlanTripStream")),
+		connect.WithHandlerOptions(opts...),
+	)
+	travelServiceGetPriceCalendarHandler := connect.NewUnaryHandler(
+		TravelServiceGetPriceCalendarProcedure,
+		svc.GetPriceCalendar,
+		connect.WithSchema(travelServiceMethods.ByName("GetPriceCalendar")),
+		connect.WithHandlerOptions(opts...),
+	)
+	travelServiceGetFareTrendHandler := connect.NewUnaryHandler(
+		TravelServiceGetFareTrendProcedure,
+		svc.GetFareTrend,
+		connect.WithSchema(travelServiceMethods.ByName("GetFareTrend")),
+		connect.WithHandlerOptions(opts...),
+	)
+	travelServiceSaveTripHandler := connect.NewUnaryHandler(
+		TravelServiceSaveTripProcedure,
+		svc.SaveTrip,
+		connect.WithSchema(travelServiceMethods.ByName("SaveTrip")),
+		connect.WithHandlerOptions(opts...),
+	)
+	travelServiceUpdateTripHandler := connect.NewUnaryHandler(
+		TravelServiceUpdateTripProcedure,
+		svc.UpdateTrip,
+		connect.WithSchema(travelServiceMethods.ByName("UpdateTrip")),
+		connect.WithHandlerOptions(opts...),
+	)
+	travelServiceVerifyPlanHandler := connect.NewUnaryHandler(
+		TravelServiceVerifyPlanProcedure,
+		svc.VerifyPlan,
+		connect.WithSchema(travelServiceMethods.ByName("VerifyPlan")),
+		connect.WithHandlerOptions(opts...),
+	)
+	travelServiceGetTripGraphHandler := connect.NewUnaryHandler(
+		TravelServiceGetTripGraphProcedure,
+		svc.GetTripGraph,
+		connect.WithSchema(travelServiceMethods.ByName("GetTripGraph")),
+		connect.WithHandlerOptions(opts...),
+	)
+	travelServiceGetTripCalendarHandler := connect.NewUnaryHandler(
+		TravelServiceGetTripCalendarProcedure,
+		svc.GetTripCalendar,
+		connect.WithSchema(travelServiceMethods.ByName("GetTripCalendar")),
+		connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+		connect.WithHandlerOptions(opts...),
+	)
+	travelServiceGetMoreOptionsHandler := connect.NewUnaryHandler(
+		TravelServiceGetMoreOptionsProcedure,
+		svc.GetMoreOptions,
+		connect.WithSchema(travelServiceMethods.ByName("GetMoreOptions")),
+		connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+		connect.WithHandlerOptions(opts...),
+	)
+	travelServiceAutocompleteLocationsHandler := connect.NewUnaryHandler(
+		TravelServiceAutocompleteLocationsProcedure,
+		svc.AutocompleteLocations,
+		connect.WithSchema(travelServiceMethods.ByName("AutocompleteLocations")),
+		connect.WithHandlerOptions(opts...),
+	)
+	travelServiceBookFlightHandler := connect.NewUnaryHandler(
+		TravelServiceBookFlightProcedure,
+		svc.BookFlight,
+		connect.WithSchema(travelServiceMethods.ByName("BookFlight")),
+		connect.WithHandlerOptions(opts...),
+	)
+	travelServiceRefreshBookingStatusHandler := connect.NewUnaryHandler(
+		TravelServiceRefreshBookingStatusProcedure,
+		svc.RefreshBookingStatus,
+		connect.WithSchema(travelServiceMethods.ByName("RefreshBookingStatus")),
+		connect.WithHandlerOptions(opts...),
+	)
+	travelServiceGetBookingSplitsHandler := connect.NewUnaryHandler(
+		TravelServiceGetBookingSplitsProcedure,
+		svc.GetBookingSplits,
+		connect.WithSchema(travelServiceMethods.ByName("GetBookingSplits")),
+		connect.WithHandlerOptions(opts...),
+	)
+	travelServiceCancelBookingHandler := connect.NewUnaryHandler(
+		TravelServiceCancelBookingProcedure,
+		svc.CancelBooking,
+		connect.WithSchema(travelServiceMethods.ByName("CancelBooking")),
+		connect.WithHandlerOptions(opts...),
+	)
+	travelServiceResumeBookingHandler := connect.NewUnaryHandler(
+		TravelServiceResumeBookingProcedure,
+		svc.ResumeBooking,
+		connect.WithSchema(travelServiceMethods.ByName("ResumeBooking")),
+		connect.WithHandlerOptions(opts...),
+	)
+	travelServiceGetItineraryHandler := connect.NewUnaryHandler(
+		TravelServiceGetItineraryProcedure,
+		svc.GetItinerary,
+		connect.WithSchema(travelServiceMethods.ByName("GetItinerary")),
+		connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+		connect.WithHandlerOptions(opts...),
+	)
 	return "/travelingman.TravelService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
 		case TravelServicePlanTripProcedure:
 			travelServicePlanTripHandler.ServeHTTP(w, r)
+		case TravelServicePlanTripStreamProcedure:
+			travelServicePlanTripStreamHandler.ServeHTTP(w, r)
+		case TravelServiceGetPriceCalendarProcedure:
+			travelServiceGetPriceCalendarHandler.ServeHTTP(w, r)
+		case TravelServiceGetFareTrendProcedure:
+			travelServiceGetFareTrendHandler.ServeHTTP(w, r)
+		case TravelServiceSaveTripProcedure:
+			travelServiceSaveTripHandler.ServeHTTP(w, r)
+		case TravelServiceUpdateTripProcedure:
+			travelServiceUpdateTripHandler.ServeHTTP(w, r)
+		case TravelServiceVerifyPlanProcedure:
+			travelServiceVerifyPlanHandler.ServeHTTP(w, r)
+		case TravelServiceGetTripGraphProcedure:
+			travelServiceGetTripGraphHandler.ServeHTTP(w, r)
+		case TravelServiceGetTripCalendarProcedure:
+			travelServiceGetTripCalendarHandler.ServeHTTP(w, r)
+		case TravelServiceGetMoreOptionsProcedure:
+			travelServiceGetMoreOptionsHandler.ServeHTTP(w, r)
+		case TravelServiceAutocompleteLocationsProcedure:
+			travelServiceAutocompleteLocationsHandler.ServeHTTP(w, r)
+		case TravelServiceBookFlightProcedure:
+			travelServiceBookFlightHandler.ServeHTTP(w, r)
+		case TravelServiceRefreshBookingStatusProcedure:
+			travelServiceRefreshBookingStatusHandler.ServeHTTP(w, r)
+		case TravelServiceGetBookingSplitsProcedure:
+			travelServiceGetBookingSplitsHandler.ServeHTTP(w, r)
+		case TravelServiceCancelBookingProcedure:
+			travelServiceCancelBookingHandler.ServeHTTP(w, r)
+		case TravelServiceResumeBookingProcedure:
+			travelServiceResumeBookingHandler.ServeHTTP(w, r)
+		case TravelServiceGetItineraryProcedure:
+			travelServiceGetItineraryHandler.ServeHTTP(w, r)
 		default:
 			http.NotFound(w, r)
 		}
@@ -106,3 +511,67 @@ type UnimplementedTravelServiceHandler struct{}
 func (UnimplementedTravelServiceHandler) PlanTrip(context.Context, *connect.Request[pb.PlanTripRequest]) (*connect.Response[pb.PlanTripResponse], error) {
 	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("travelingman.TravelService.PlanTrip is not implemented"))
 }
+
+func (UnimplementedTravelServiceHandler) PlanTripStream(context.Context, *connect.Request[pb.PlanTripRequest], *connect.ServerStream[pb.PlanTripResponse]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("travelingman.TravelService.PlanTripStream is not implemented"))
+}
+
+func (UnimplementedTravelServiceHandler) GetPriceCalendar(context.Context, *connect.Request[pb.GetPriceCalendarRequest]) (*connect.Response[pb.GetPriceCalendarResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("travelingman.TravelService.GetPriceCalendar is not implemented"))
+}
+
+func (UnimplementedTravelServiceHandler) GetFareTrend(context.Context, *connect.Request[pb.GetFareTrendRequest]) (*connect.Response[pb.GetFareTrendResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("travelingman.TravelService.GetFareTrend is not implemented"))
+}
+
+func (UnimplementedTravelServiceHandler) SaveTrip(context.Context, *connect.Request[pb.SaveTripRequest]) (*connect.Response[pb.SaveTripResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("travelingman.TravelService.SaveTrip is not implemented"))
+}
+
+func (UnimplementedTravelServiceHandler) UpdateTrip(context.Context, *connect.Request[pb.UpdateTripRequest]) (*connect.Response[pb.UpdateTripResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("travelingman.TravelService.UpdateTrip is not implemented"))
+}
+
+func (UnimplementedTravelServiceHandler) VerifyPlan(context.Context, *connect.Request[pb.VerifyPlanRequest]) (*connect.Response[pb.VerifyPlanResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("travelingman.TravelService.VerifyPlan is not implemented"))
+}
+
+func (UnimplementedTravelServiceHandler) GetTripGraph(context.Context, *connect.Request[pb.GetTripGraphRequest]) (*connect.Response[pb.GetTripGraphResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("travelingman.TravelService.GetTripGraph is not implemented"))
+}
+
+func (UnimplementedTravelServiceHandler) GetTripCalendar(context.Context, *connect.Request[pb.GetTripCalendarRequest]) (*connect.Response[pb.GetTripCalendarResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("travelingman.TravelService.GetTripCalendar is not implemented"))
+}
+
+func (UnimplementedTravelServiceHandler) GetMoreOptions(context.Context, *connect.Request[pb.GetMoreOptionsRequest]) (*connect.Response[pb.GetMoreOptionsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("travelingman.TravelService.GetMoreOptions is not implemented"))
+}
+
+func (UnimplementedTravelServiceHandler) AutocompleteLocations(context.Context, *connect.Request[pb.AutocompleteLocationsRequest]) (*connect.Response[pb.AutocompleteLocationsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("travelingman.TravelService.AutocompleteLocations is not implemented"))
+}
+
+func (UnimplementedTravelServiceHandler) BookFlight(context.Context, *connect.Request[pb.BookFlightRequest]) (*connect.Response[pb.BookFlightResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("travelingman.TravelService.BookFlight is not implemented"))
+}
+
+func (UnimplementedTravelServiceHandler) RefreshBookingStatus(context.Context, *connect.Request[pb.RefreshBookingStatusRequest]) (*connect.Response[pb.RefreshBookingStatusResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("travelingman.TravelService.RefreshBookingStatus is not implemented"))
+}
+
+func (UnimplementedTravelServiceHandler) GetBookingSplits(context.Context, *connect.Request[pb.GetBookingSplitsRequest]) (*connect.Response[pb.GetBookingSplitsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("travelingman.TravelService.GetBookingSplits is not implemented"))
+}
+
+func (UnimplementedTravelServiceHandler) CancelBooking(context.Context, *connect.Request[pb.CancelBookingRequest]) (*connect.Response[pb.CancelBookingResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("travelingman.TravelService.CancelBooking is not implemented"))
+}
+
+func (UnimplementedTravelServiceHandler) ResumeBooking(context.Context, *connect.Request[pb.ResumeBookingRequest]) (*connect.Response[pb.ResumeBookingResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("travelingman.TravelService.ResumeBooking is not implemented"))
+}
+
+func (UnimplementedTravelServiceHandler) GetItinerary(context.Context, *connect.Request[pb.GetItineraryRequest]) (*connect.Response[pb.GetItineraryResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("travelingman.TravelService.GetItinerary is not implemented"))
+}
@@ -9,6 +9,7 @@ package pb
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -21,9 +22,120 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type PlanStage int32
+
+const (
+	PlanStage_PLAN_STAGE_UNSPECIFIED           PlanStage = 0
+	PlanStage_PLAN_STAGE_PLANNING              PlanStage = 1 // The planner started a plan or a revision
+	PlanStage_PLAN_STAGE_TOOL_CALL             PlanStage = 2 // The planner ran a tool
+	PlanStage_PLAN_STAGE_PROPOSED              PlanStage = 3 // The planner proposed an itinerary
+	PlanStage_PLAN_STAGE_VERIFICATION_STARTED  PlanStage = 4 // Searches for an itinerary's options started
+	PlanStage_PLAN_STAGE_VERIFICATION_FINISHED PlanStage = 5 // Searches for an itinerary's options finished, whether it passed or not
+)
+
+// Enum value maps for PlanStage.
+var (
+	PlanStage_name = map[int32]string{
+		0: "PLAN_STAGE_UNSPECIFIED",
+		1: "PLAN_STAGE_PLANNING",
+		2: "PLAN_STAGE_TOOL_CALL",
+		3: "PLAN_STAGE_PROPOSED",
+		4: "PLAN_STAGE_VERIFICATION_STARTED",
+		5: "PLAN_STAGE_VERIFICATION_FINISHED",
+	}
+	PlanStage_value = map[string]int32{
+		"PLAN_STAGE_UNSPECIFIED":           0,
+		"PLAN_STAGE_PLANNING":              1,
+		"PLAN_STAGE_TOOL_CALL":             2,
+		"PLAN_STAGE_PROPOSED":              3,
+		"PLAN_STAGE_VERIFICATION_STARTED":  4,
+		"PLAN_STAGE_VERIFICATION_FINISHED": 5,
+	}
+)
+
+func (x PlanStage) Enum() *PlanStage {
+	p := new(PlanStage)
+	*p = x
+	return p
+}
+
+func (x PlanStage) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PlanStage) Descriptor() protoreflect.EnumDescriptor {
+	return file_protos_service_proto_enumTypes[0].Descriptor()
+}
+
+func (PlanStage) Type() protoreflect.EnumType {
+	return &file_protos_service_proto_enumTypes[0]
+}
+
+func (x PlanStage) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PlanStage.Descriptor instead.
+func (PlanStage) EnumDescriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{0}
+}
+
+type TripGraphNodeType int32
+
+const (
+	TripGraphNodeType_TRIP_GRAPH_NODE_TYPE_UNSPECIFIED TripGraphNodeType = 0
+	TripGraphNodeType_TRIP_GRAPH_NODE_TYPE_ORIGIN      TripGraphNodeType = 1 // Where the trip (or a day trip) starts
+	TripGraphNodeType_TRIP_GRAPH_NODE_TYPE_STAY        TripGraphNodeType = 2 // Node with accommodation
+	TripGraphNodeType_TRIP_GRAPH_NODE_TYPE_DESTINATION TripGraphNodeType = 3 // Any other place visited
+)
+
+// Enum value maps for TripGraphNodeType.
+var (
+	TripGraphNodeType_name = map[int32]string{
+		0: "TRIP_GRAPH_NODE_TYPE_UNSPECIFIED",
+		1: "TRIP_GRAPH_NODE_TYPE_ORIGIN",
+		2: "TRIP_GRAPH_NODE_TYPE_STAY",
+		3: "TRIP_GRAPH_NODE_TYPE_DESTINATION",
+	}
+	TripGraphNodeType_value = map[string]int32{
+		"TRIP_GRAPH_NODE_TYPE_UNSPECIFIED": 0,
+		"TRIP_GRAPH_NODE_TYPE_ORIGIN":      1,
+		"TRIP_GRAPH_NODE_TYPE_STAY":        2,
+		"TRIP_GRAPH_NODE_TYPE_DESTINATION": 3,
+	}
+)
+
+func (x TripGraphNodeType) Enum() *TripGraphNodeType {
+	p := new(TripGraphNodeType)
+	*p = x
+	return p
+}
+
+func (x TripGraphNodeType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TripGraphNodeType) Descriptor() protoreflect.EnumDescriptor {
+	return file_protos_service_proto_enumTypes[1].Descriptor()
+}
+
+func (TripGraphNodeType) Type() protoreflect.EnumType {
+	return &file_protos_service_proto_enumTypes[1]
+}
+
+func (x TripGraphNodeType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TripGraphNodeType.Descriptor instead.
+func (TripGraphNodeType) EnumDescriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{1}
+}
+
 type PlanTripRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Quick         bool                   `protobuf:"varint,2,opt,name=quick,proto3" json:"quick,omitempty"` // Return unverified drafts fast; verify later with VerifyPlan
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -65,9 +177,21 @@ func (x *PlanTripRequest) GetQuery() string {
 	return ""
 }
 
+func (x *PlanTripRequest) GetQuick() bool {
+	if x != nil {
+		return x.Quick
+	}
+	return false
+}
+
 type PlanTripResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Itineraries   []*Itinerary           `protobuf:"bytes,1,rep,name=itineraries,proto3" json:"itineraries,omitempty"`
+	Text          string                 `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`         // Planner text streamed by PlanTripStream while planning; empty in the final message
+	Summary       *TripSummary           `protobuf:"bytes,3,opt,name=summary,proto3" json:"summary,omitempty"`   // Header facts of the best itinerary; unset when there is none
+	Verified      *Itinerary             `protobuf:"bytes,4,opt,name=verified,proto3" json:"verified,omitempty"` // One itinerary streamed by PlanTripStream as soon as it passed verification, before scoring; unset in the final message
+	Progress      *PlanProgress          `protobuf:"bytes,5,opt,name=progress,proto3" json:"progress,omitempty"` // One planning step streamed by PlanTripStream; unset in the final message
+	Error         *Error                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`       // Why no itinerary survived; set only in the final message
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -109,17 +233,2696 @@ func (x *PlanTripResponse) GetItineraries() []*Itinerary {
 	return nil
 }
 
-var File_protos_service_proto protoreflect.FileDescriptor
+func (x *PlanTripResponse) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
 
-const file_protos_service_proto_rawDesc = "" +
-	"\n" +
-	"\x14protos/service.proto\x12\ftravelingman\x1a\x12protos/graph.proto\"'\n" +
-	"\x0fPlanTripRequest\x12\x14\n" +
-	"\x05query\x18\x01 \x01(\tR\x05query\"M\n" +
-	"\x10PlanTripResponse\x129\n" +
-	"\vitineraries\x18\x01 \x03(\v2\x17.travelingman.ItineraryR\vitineraries2Z\n" +
-	"\rTravelService\x12I\n" +
-	"\bPlanTrip\x12\x1d.travelingman.PlanTripRequest\x1a\x1e.travelingman.PlanTripResponseB#Z!github.com/va6996/travelingman/pbb\x06proto3"
+func (x *PlanTripResponse) GetSummary() *TripSummary {
+	if x != nil {
+		return x.Summary
+	}
+	return nil
+}
+
+func (x *PlanTripResponse) GetVerified() *Itinerary {
+	if x != nil {
+		return x.Verified
+	}
+	return nil
+}
+
+func (x *PlanTripResponse) GetProgress() *PlanProgress {
+	if x != nil {
+		return x.Progress
+	}
+	return nil
+}
+
+func (x *PlanTripResponse) GetError() *Error {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+// PlanProgress is a step of planning, streamed so a client can show what is going on
+type PlanProgress struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Stage         PlanStage              `protobuf:"varint,1,opt,name=stage,proto3,enum=travelingman.PlanStage" json:"stage,omitempty"`
+	Detail        string                 `protobuf:"bytes,2,opt,name=detail,proto3" json:"detail,omitempty"` // The tool called or the itinerary's title; empty when planning starts
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlanProgress) Reset() {
+	*x = PlanProgress{}
+	mi := &file_protos_service_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlanProgress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlanProgress) ProtoMessage() {}
+
+func (x *PlanProgress) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlanProgress.ProtoReflect.Descriptor instead.
+func (*PlanProgress) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PlanProgress) GetStage() PlanStage {
+	if x != nil {
+		return x.Stage
+	}
+	return PlanStage_PLAN_STAGE_UNSPECIFIED
+}
+
+func (x *PlanProgress) GetDetail() string {
+	if x != nil {
+		return x.Detail
+	}
+	return ""
+}
+
+// TripSummary is a compact header for a planned trip, taken from its best itinerary
+type TripSummary struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Destination   string                 `protobuf:"bytes,1,opt,name=destination,proto3" json:"destination,omitempty"`              // City of the first stay, or where the first leg arrives
+	StartTime     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"` // Departure of the first leg
+	EndTime       *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`       // Arrival of the last leg, or the last check-out
+	Travelers     int32                  `protobuf:"varint,4,opt,name=travelers,proto3" json:"travelers,omitempty"`
+	Total         *Cost                  `protobuf:"bytes,5,opt,name=total,proto3" json:"total,omitempty"`                                 // Total of the selected options
+	OptionCount   int32                  `protobuf:"varint,6,opt,name=option_count,json=optionCount,proto3" json:"option_count,omitempty"` // Itineraries in the response
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TripSummary) Reset() {
+	*x = TripSummary{}
+	mi := &file_protos_service_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TripSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TripSummary) ProtoMessage() {}
+
+func (x *TripSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TripSummary.ProtoReflect.Descriptor instead.
+func (*TripSummary) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *TripSummary) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+func (x *TripSummary) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *TripSummary) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+func (x *TripSummary) GetTravelers() int32 {
+	if x != nil {
+		return x.Travelers
+	}
+	return 0
+}
+
+func (x *TripSummary) GetTotal() *Cost {
+	if x != nil {
+		return x.Total
+	}
+	return nil
+}
+
+func (x *TripSummary) GetOptionCount() int32 {
+	if x != nil {
+		return x.OptionCount
+	}
+	return 0
+}
+
+type GetPriceCalendarRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Origin        string                 `protobuf:"bytes,1,opt,name=origin,proto3" json:"origin,omitempty"`           // Origin IATA code
+	Destination   string                 `protobuf:"bytes,2,opt,name=destination,proto3" json:"destination,omitempty"` // Destination IATA code
+	Month         string                 `protobuf:"bytes,3,opt,name=month,proto3" json:"month,omitempty"`             // YYYY-MM
+	Adults        int32                  `protobuf:"varint,4,opt,name=adults,proto3" json:"adults,omitempty"`
+	Currency      string                 `protobuf:"bytes,5,opt,name=currency,proto3" json:"currency,omitempty"` // ISO 4217, defaults to USD
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPriceCalendarRequest) Reset() {
+	*x = GetPriceCalendarRequest{}
+	mi := &file_protos_service_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPriceCalendarRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPriceCalendarRequest) ProtoMessage() {}
+
+func (x *GetPriceCalendarRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPriceCalendarRequest.ProtoReflect.Descriptor instead.
+func (*GetPriceCalendarRequest) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetPriceCalendarRequest) GetOrigin() string {
+	if x != nil {
+		return x.Origin
+	}
+	return ""
+}
+
+func (x *GetPriceCalendarRequest) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+func (x *GetPriceCalendarRequest) GetMonth() string {
+	if x != nil {
+		return x.Month
+	}
+	return ""
+}
+
+func (x *GetPriceCalendarRequest) GetAdults() int32 {
+	if x != nil {
+		return x.Adults
+	}
+	return 0
+}
+
+func (x *GetPriceCalendarRequest) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+type GetPriceCalendarResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Calendar      *PriceCalendar         `protobuf:"bytes,1,opt,name=calendar,proto3" json:"calendar,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPriceCalendarResponse) Reset() {
+	*x = GetPriceCalendarResponse{}
+	mi := &file_protos_service_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPriceCalendarResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPriceCalendarResponse) ProtoMessage() {}
+
+func (x *GetPriceCalendarResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPriceCalendarResponse.ProtoReflect.Descriptor instead.
+func (*GetPriceCalendarResponse) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetPriceCalendarResponse) GetCalendar() *PriceCalendar {
+	if x != nil {
+		return x.Calendar
+	}
+	return nil
+}
+
+type GetFareTrendRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Origin        string                 `protobuf:"bytes,1,opt,name=origin,proto3" json:"origin,omitempty"`                     // Origin IATA code
+	Destination   string                 `protobuf:"bytes,2,opt,name=destination,proto3" json:"destination,omitempty"`           // Destination IATA code
+	FromDate      string                 `protobuf:"bytes,3,opt,name=from_date,json=fromDate,proto3" json:"from_date,omitempty"` // YYYY-MM-DD, first departure day considered
+	ToDate        string                 `protobuf:"bytes,4,opt,name=to_date,json=toDate,proto3" json:"to_date,omitempty"`       // YYYY-MM-DD, last departure day considered
+	Adults        int32                  `protobuf:"varint,5,opt,name=adults,proto3" json:"adults,omitempty"`
+	Currency      string                 `protobuf:"bytes,6,opt,name=currency,proto3" json:"currency,omitempty"` // ISO 4217, defaults to USD
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetFareTrendRequest) Reset() {
+	*x = GetFareTrendRequest{}
+	mi := &file_protos_service_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetFareTrendRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFareTrendRequest) ProtoMessage() {}
+
+func (x *GetFareTrendRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFareTrendRequest.ProtoReflect.Descriptor instead.
+func (*GetFareTrendRequest) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetFareTrendRequest) GetOrigin() string {
+	if x != nil {
+		return x.Origin
+	}
+	return ""
+}
+
+func (x *GetFareTrendRequest) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+func (x *GetFareTrendRequest) GetFromDate() string {
+	if x != nil {
+		return x.FromDate
+	}
+	return ""
+}
+
+func (x *GetFareTrendRequest) GetToDate() string {
+	if x != nil {
+		return x.ToDate
+	}
+	return ""
+}
+
+func (x *GetFareTrendRequest) GetAdults() int32 {
+	if x != nil {
+		return x.Adults
+	}
+	return 0
+}
+
+func (x *GetFareTrendRequest) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+type GetFareTrendResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Trend         *FareTrend             `protobuf:"bytes,1,opt,name=trend,proto3" json:"trend,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetFareTrendResponse) Reset() {
+	*x = GetFareTrendResponse{}
+	mi := &file_protos_service_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetFareTrendResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFareTrendResponse) ProtoMessage() {}
+
+func (x *GetFareTrendResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFareTrendResponse.ProtoReflect.Descriptor instead.
+func (*GetFareTrendResponse) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetFareTrendResponse) GetTrend() *FareTrend {
+	if x != nil {
+		return x.Trend
+	}
+	return nil
+}
+
+type SaveTripRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Itinerary     *Itinerary             `protobuf:"bytes,1,opt,name=itinerary,proto3" json:"itinerary,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SaveTripRequest) Reset() {
+	*x = SaveTripRequest{}
+	mi := &file_protos_service_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SaveTripRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SaveTripRequest) ProtoMessage() {}
+
+func (x *SaveTripRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SaveTripRequest.ProtoReflect.Descriptor instead.
+func (*SaveTripRequest) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SaveTripRequest) GetItinerary() *Itinerary {
+	if x != nil {
+		return x.Itinerary
+	}
+	return nil
+}
+
+type SaveTripResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Itinerary     *Itinerary             `protobuf:"bytes,1,opt,name=itinerary,proto3" json:"itinerary,omitempty"` // Saved itinerary with id and version set
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SaveTripResponse) Reset() {
+	*x = SaveTripResponse{}
+	mi := &file_protos_service_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SaveTripResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SaveTripResponse) ProtoMessage() {}
+
+func (x *SaveTripResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SaveTripResponse.ProtoReflect.Descriptor instead.
+func (*SaveTripResponse) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SaveTripResponse) GetItinerary() *Itinerary {
+	if x != nil {
+		return x.Itinerary
+	}
+	return nil
+}
+
+type UpdateTripRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Itinerary     *Itinerary             `protobuf:"bytes,1,opt,name=itinerary,proto3" json:"itinerary,omitempty"` // Edited itinerary; id and version identify the saved trip
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateTripRequest) Reset() {
+	*x = UpdateTripRequest{}
+	mi := &file_protos_service_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateTripRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateTripRequest) ProtoMessage() {}
+
+func (x *UpdateTripRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateTripRequest.ProtoReflect.Descriptor instead.
+func (*UpdateTripRequest) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *UpdateTripRequest) GetItinerary() *Itinerary {
+	if x != nil {
+		return x.Itinerary
+	}
+	return nil
+}
+
+// TripConflict describes an inconsistency introduced by an edit
+type TripConflict struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ComponentId   string                 `protobuf:"bytes,1,opt,name=component_id,json=componentId,proto3" json:"component_id,omitempty"` // Node ID or edge (from_id->to_id) the conflict relates to
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Suggestion    string                 `protobuf:"bytes,3,opt,name=suggestion,proto3" json:"suggestion,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TripConflict) Reset() {
+	*x = TripConflict{}
+	mi := &file_protos_service_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TripConflict) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TripConflict) ProtoMessage() {}
+
+func (x *TripConflict) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TripConflict.ProtoReflect.Descriptor instead.
+func (*TripConflict) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *TripConflict) GetComponentId() string {
+	if x != nil {
+		return x.ComponentId
+	}
+	return ""
+}
+
+func (x *TripConflict) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *TripConflict) GetSuggestion() string {
+	if x != nil {
+		return x.Suggestion
+	}
+	return ""
+}
+
+type UpdateTripResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Itinerary     *Itinerary             `protobuf:"bytes,1,opt,name=itinerary,proto3" json:"itinerary,omitempty"` // Accepted, re-scored itinerary (unset when there are conflicts)
+	Conflicts     []*TripConflict        `protobuf:"bytes,2,rep,name=conflicts,proto3" json:"conflicts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateTripResponse) Reset() {
+	*x = UpdateTripResponse{}
+	mi := &file_protos_service_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateTripResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateTripResponse) ProtoMessage() {}
+
+func (x *UpdateTripResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateTripResponse.ProtoReflect.Descriptor instead.
+func (*UpdateTripResponse) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *UpdateTripResponse) GetItinerary() *Itinerary {
+	if x != nil {
+		return x.Itinerary
+	}
+	return nil
+}
+
+func (x *UpdateTripResponse) GetConflicts() []*TripConflict {
+	if x != nil {
+		return x.Conflicts
+	}
+	return nil
+}
+
+type VerifyPlanRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PlanId        int64                  `protobuf:"varint,1,opt,name=plan_id,json=planId,proto3" json:"plan_id,omitempty"` // ID of a saved (quick mode) plan
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyPlanRequest) Reset() {
+	*x = VerifyPlanRequest{}
+	mi := &file_protos_service_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyPlanRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyPlanRequest) ProtoMessage() {}
+
+func (x *VerifyPlanRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyPlanRequest.ProtoReflect.Descriptor instead.
+func (*VerifyPlanRequest) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *VerifyPlanRequest) GetPlanId() int64 {
+	if x != nil {
+		return x.PlanId
+	}
+	return 0
+}
+
+type VerifyPlanResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Itinerary     *Itinerary             `protobuf:"bytes,1,opt,name=itinerary,proto3" json:"itinerary,omitempty"` // Verified, scored plan (saved with a new version)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyPlanResponse) Reset() {
+	*x = VerifyPlanResponse{}
+	mi := &file_protos_service_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyPlanResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyPlanResponse) ProtoMessage() {}
+
+func (x *VerifyPlanResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyPlanResponse.ProtoReflect.Descriptor instead.
+func (*VerifyPlanResponse) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *VerifyPlanResponse) GetItinerary() *Itinerary {
+	if x != nil {
+		return x.Itinerary
+	}
+	return nil
+}
+
+// GetItineraryRequest fetches a saved plan. It is served over HTTP GET with an ETag,
+// so unchanged plans can be revalidated with If-None-Match.
+type GetItineraryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PlanId        int64                  `protobuf:"varint,1,opt,name=plan_id,json=planId,proto3" json:"plan_id,omitempty"` // ID of a saved plan
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetItineraryRequest) Reset() {
+	*x = GetItineraryRequest{}
+	mi := &file_protos_service_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetItineraryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetItineraryRequest) ProtoMessage() {}
+
+func (x *GetItineraryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetItineraryRequest.ProtoReflect.Descriptor instead.
+func (*GetItineraryRequest) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GetItineraryRequest) GetPlanId() int64 {
+	if x != nil {
+		return x.PlanId
+	}
+	return 0
+}
+
+type GetItineraryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Itinerary     *Itinerary             `protobuf:"bytes,1,opt,name=itinerary,proto3" json:"itinerary,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetItineraryResponse) Reset() {
+	*x = GetItineraryResponse{}
+	mi := &file_protos_service_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetItineraryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetItineraryResponse) ProtoMessage() {}
+
+func (x *GetItineraryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetItineraryResponse.ProtoReflect.Descriptor instead.
+func (*GetItineraryResponse) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *GetItineraryResponse) GetItinerary() *Itinerary {
+	if x != nil {
+		return x.Itinerary
+	}
+	return nil
+}
+
+type GetTripGraphRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PlanId        int64                  `protobuf:"varint,1,opt,name=plan_id,json=planId,proto3" json:"plan_id,omitempty"` // ID of a saved plan
+	Itinerary     *Itinerary             `protobuf:"bytes,2,opt,name=itinerary,proto3" json:"itinerary,omitempty"`          // Inline itinerary, used when plan_id is unset
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTripGraphRequest) Reset() {
+	*x = GetTripGraphRequest{}
+	mi := &file_protos_service_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTripGraphRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTripGraphRequest) ProtoMessage() {}
+
+func (x *GetTripGraphRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTripGraphRequest.ProtoReflect.Descriptor instead.
+func (*GetTripGraphRequest) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *GetTripGraphRequest) GetPlanId() int64 {
+	if x != nil {
+		return x.PlanId
+	}
+	return 0
+}
+
+func (x *GetTripGraphRequest) GetItinerary() *Itinerary {
+	if x != nil {
+		return x.Itinerary
+	}
+	return nil
+}
+
+type GetTripGraphResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Graph         *TripGraph             `protobuf:"bytes,1,opt,name=graph,proto3" json:"graph,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTripGraphResponse) Reset() {
+	*x = GetTripGraphResponse{}
+	mi := &file_protos_service_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTripGraphResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTripGraphResponse) ProtoMessage() {}
+
+func (x *GetTripGraphResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTripGraphResponse.ProtoReflect.Descriptor instead.
+func (*GetTripGraphResponse) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *GetTripGraphResponse) GetGraph() *TripGraph {
+	if x != nil {
+		return x.Graph
+	}
+	return nil
+}
+
+type GetTripCalendarRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PlanId        int64                  `protobuf:"varint,1,opt,name=plan_id,json=planId,proto3" json:"plan_id,omitempty"` // ID of a saved plan
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTripCalendarRequest) Reset() {
+	*x = GetTripCalendarRequest{}
+	mi := &file_protos_service_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTripCalendarRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTripCalendarRequest) ProtoMessage() {}
+
+func (x *GetTripCalendarRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTripCalendarRequest.ProtoReflect.Descriptor instead.
+func (*GetTripCalendarRequest) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *GetTripCalendarRequest) GetPlanId() int64 {
+	if x != nil {
+		return x.PlanId
+	}
+	return 0
+}
+
+type GetTripCalendarResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Calendar      *TripCalendar          `protobuf:"bytes,1,opt,name=calendar,proto3" json:"calendar,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTripCalendarResponse) Reset() {
+	*x = GetTripCalendarResponse{}
+	mi := &file_protos_service_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTripCalendarResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTripCalendarResponse) ProtoMessage() {}
+
+func (x *GetTripCalendarResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTripCalendarResponse.ProtoReflect.Descriptor instead.
+func (*GetTripCalendarResponse) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *GetTripCalendarResponse) GetCalendar() *TripCalendar {
+	if x != nil {
+		return x.Calendar
+	}
+	return nil
+}
+
+// GetMoreOptionsRequest fetches the transport options collapsed behind a shown one
+type GetMoreOptionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PlanId        int64                  `protobuf:"varint,1,opt,name=plan_id,json=planId,proto3" json:"plan_id,omitempty"`      // ID of a saved plan
+	Edge          string                 `protobuf:"bytes,2,opt,name=edge,proto3" json:"edge,omitempty"`                         // "fromID->toID"
+	OptionId      string                 `protobuf:"bytes,3,opt,name=option_id,json=optionId,proto3" json:"option_id,omitempty"` // One of the edge's visible option IDs
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMoreOptionsRequest) Reset() {
+	*x = GetMoreOptionsRequest{}
+	mi := &file_protos_service_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMoreOptionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMoreOptionsRequest) ProtoMessage() {}
+
+func (x *GetMoreOptionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMoreOptionsRequest.ProtoReflect.Descriptor instead.
+func (*GetMoreOptionsRequest) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *GetMoreOptionsRequest) GetPlanId() int64 {
+	if x != nil {
+		return x.PlanId
+	}
+	return 0
+}
+
+func (x *GetMoreOptionsRequest) GetEdge() string {
+	if x != nil {
+		return x.Edge
+	}
+	return ""
+}
+
+func (x *GetMoreOptionsRequest) GetOptionId() string {
+	if x != nil {
+		return x.OptionId
+	}
+	return ""
+}
+
+type GetMoreOptionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Options       []*Transport           `protobuf:"bytes,1,rep,name=options,proto3" json:"options,omitempty"` // In the order of the group's collapsed_ids
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMoreOptionsResponse) Reset() {
+	*x = GetMoreOptionsResponse{}
+	mi := &file_protos_service_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMoreOptionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMoreOptionsResponse) ProtoMessage() {}
+
+func (x *GetMoreOptionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMoreOptionsResponse.ProtoReflect.Descriptor instead.
+func (*GetMoreOptionsResponse) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *GetMoreOptionsResponse) GetOptions() []*Transport {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+// AutocompleteLocationsRequest looks up cities and airports for a destination input.
+// It is served from memory and never calls a provider.
+type AutocompleteLocationsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`  // City name, airport name or IATA code, or the start of one
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"` // Max results, defaults to 10 (at most 50)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AutocompleteLocationsRequest) Reset() {
+	*x = AutocompleteLocationsRequest{}
+	mi := &file_protos_service_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AutocompleteLocationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AutocompleteLocationsRequest) ProtoMessage() {}
+
+func (x *AutocompleteLocationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AutocompleteLocationsRequest.ProtoReflect.Descriptor instead.
+func (*AutocompleteLocationsRequest) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *AutocompleteLocationsRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *AutocompleteLocationsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type AutocompleteLocationsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Locations     []*Location            `protobuf:"bytes,1,rep,name=locations,proto3" json:"locations,omitempty"` // Best match first
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AutocompleteLocationsResponse) Reset() {
+	*x = AutocompleteLocationsResponse{}
+	mi := &file_protos_service_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AutocompleteLocationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AutocompleteLocationsResponse) ProtoMessage() {}
+
+func (x *AutocompleteLocationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AutocompleteLocationsResponse.ProtoReflect.Descriptor instead.
+func (*AutocompleteLocationsResponse) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *AutocompleteLocationsResponse) GetLocations() []*Location {
+	if x != nil {
+		return x.Locations
+	}
+	return nil
+}
+
+// BookFlightRequest places an order for a flight offer and records how its cost is split
+type BookFlightRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OfferJson     string                 `protobuf:"bytes,1,opt,name=offer_json,json=offerJson,proto3" json:"offer_json,omitempty"`               // The provider's flight offer, as returned by a search
+	TravelerIds   []int64                `protobuf:"varint,2,rep,packed,name=traveler_ids,json=travelerIds,proto3" json:"traveler_ids,omitempty"` // Users flying, in the offer's traveler order
+	Split         *PaymentSplit          `protobuf:"bytes,3,opt,name=split,proto3" json:"split,omitempty"`                                        // Optional: who owes what of the total
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BookFlightRequest) Reset() {
+	*x = BookFlightRequest{}
+	mi := &file_protos_service_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BookFlightRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BookFlightRequest) ProtoMessage() {}
+
+func (x *BookFlightRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BookFlightRequest.ProtoReflect.Descriptor instead.
+func (*BookFlightRequest) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *BookFlightRequest) GetOfferJson() string {
+	if x != nil {
+		return x.OfferJson
+	}
+	return ""
+}
+
+func (x *BookFlightRequest) GetTravelerIds() []int64 {
+	if x != nil {
+		return x.TravelerIds
+	}
+	return nil
+}
+
+func (x *BookFlightRequest) GetSplit() *PaymentSplit {
+	if x != nil {
+		return x.Split
+	}
+	return nil
+}
+
+type BookFlightResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BookingId     int64                  `protobuf:"varint,1,opt,name=booking_id,json=bookingId,proto3" json:"booking_id,omitempty"`
+	OrderId       string                 `protobuf:"bytes,2,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"` // Provider order ID
+	Shares        []*Payment             `protobuf:"bytes,3,rep,name=shares,proto3" json:"shares,omitempty"`                  // What each user owes, if the cost was split
+	Summary       string                 `protobuf:"bytes,4,opt,name=summary,proto3" json:"summary,omitempty"`                // As emailed to the travelers
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BookFlightResponse) Reset() {
+	*x = BookFlightResponse{}
+	mi := &file_protos_service_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BookFlightResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BookFlightResponse) ProtoMessage() {}
+
+func (x *BookFlightResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BookFlightResponse.ProtoReflect.Descriptor instead.
+func (*BookFlightResponse) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *BookFlightResponse) GetBookingId() int64 {
+	if x != nil {
+		return x.BookingId
+	}
+	return 0
+}
+
+func (x *BookFlightResponse) GetOrderId() string {
+	if x != nil {
+		return x.OrderId
+	}
+	return ""
+}
+
+func (x *BookFlightResponse) GetShares() []*Payment {
+	if x != nil {
+		return x.Shares
+	}
+	return nil
+}
+
+func (x *BookFlightResponse) GetSummary() string {
+	if x != nil {
+		return x.Summary
+	}
+	return ""
+}
+
+type RefreshBookingStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BookingId     int64                  `protobuf:"varint,1,opt,name=booking_id,json=bookingId,proto3" json:"booking_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefreshBookingStatusRequest) Reset() {
+	*x = RefreshBookingStatusRequest{}
+	mi := &file_protos_service_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefreshBookingStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefreshBookingStatusRequest) ProtoMessage() {}
+
+func (x *RefreshBookingStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefreshBookingStatusRequest.ProtoReflect.Descriptor instead.
+func (*RefreshBookingStatusRequest) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *RefreshBookingStatusRequest) GetBookingId() int64 {
+	if x != nil {
+		return x.BookingId
+	}
+	return 0
+}
+
+type RefreshBookingStatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        BookingStatus          `protobuf:"varint,1,opt,name=status,proto3,enum=travelingman.BookingStatus" json:"status,omitempty"` // Status after the refresh
+	Changes       []*FlightChange        `protobuf:"bytes,2,rep,name=changes,proto3" json:"changes,omitempty"`                                // Schedule changes found by this refresh
+	History       []*BookingStatusChange `protobuf:"bytes,3,rep,name=history,proto3" json:"history,omitempty"`                                // All transitions, oldest first
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefreshBookingStatusResponse) Reset() {
+	*x = RefreshBookingStatusResponse{}
+	mi := &file_protos_service_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefreshBookingStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefreshBookingStatusResponse) ProtoMessage() {}
+
+func (x *RefreshBookingStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefreshBookingStatusResponse.ProtoReflect.Descriptor instead.
+func (*RefreshBookingStatusResponse) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *RefreshBookingStatusResponse) GetStatus() BookingStatus {
+	if x != nil {
+		return x.Status
+	}
+	return BookingStatus_BOOKING_STATUS_UNSPECIFIED
+}
+
+func (x *RefreshBookingStatusResponse) GetChanges() []*FlightChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+func (x *RefreshBookingStatusResponse) GetHistory() []*BookingStatusChange {
+	if x != nil {
+		return x.History
+	}
+	return nil
+}
+
+type GetBookingSplitsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BookingId     int64                  `protobuf:"varint,1,opt,name=booking_id,json=bookingId,proto3" json:"booking_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBookingSplitsRequest) Reset() {
+	*x = GetBookingSplitsRequest{}
+	mi := &file_protos_service_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBookingSplitsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBookingSplitsRequest) ProtoMessage() {}
+
+func (x *GetBookingSplitsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBookingSplitsRequest.ProtoReflect.Descriptor instead.
+func (*GetBookingSplitsRequest) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *GetBookingSplitsRequest) GetBookingId() int64 {
+	if x != nil {
+		return x.BookingId
+	}
+	return 0
+}
+
+type GetBookingSplitsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Shares        []*Payment             `protobuf:"bytes,1,rep,name=shares,proto3" json:"shares,omitempty"` // What each user owes, in split order
+	Total         string                 `protobuf:"bytes,2,opt,name=total,proto3" json:"total,omitempty"`   // Sum of the shares
+	Currency      string                 `protobuf:"bytes,3,opt,name=currency,proto3" json:"currency,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBookingSplitsResponse) Reset() {
+	*x = GetBookingSplitsResponse{}
+	mi := &file_protos_service_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBookingSplitsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBookingSplitsResponse) ProtoMessage() {}
+
+func (x *GetBookingSplitsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBookingSplitsResponse.ProtoReflect.Descriptor instead.
+func (*GetBookingSplitsResponse) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *GetBookingSplitsResponse) GetShares() []*Payment {
+	if x != nil {
+		return x.Shares
+	}
+	return nil
+}
+
+func (x *GetBookingSplitsResponse) GetTotal() string {
+	if x != nil {
+		return x.Total
+	}
+	return ""
+}
+
+func (x *GetBookingSplitsResponse) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+// CancelBookingRequest cancels the provider orders of a saved plan
+type CancelBookingRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PlanId        int64                  `protobuf:"varint,1,opt,name=plan_id,json=planId,proto3" json:"plan_id,omitempty"` // ID of a saved plan
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelBookingRequest) Reset() {
+	*x = CancelBookingRequest{}
+	mi := &file_protos_service_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelBookingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelBookingRequest) ProtoMessage() {}
+
+func (x *CancelBookingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelBookingRequest.ProtoReflect.Descriptor instead.
+func (*CancelBookingRequest) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *CancelBookingRequest) GetPlanId() int64 {
+	if x != nil {
+		return x.PlanId
+	}
+	return 0
+}
+
+type CancelBookingResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Itinerary     *Itinerary             `protobuf:"bytes,1,opt,name=itinerary,proto3" json:"itinerary,omitempty"` // Saved plan with its cancelled segments marked CANCELLED
+	Failures      []*TripConflict        `protobuf:"bytes,2,rep,name=failures,proto3" json:"failures,omitempty"`   // Segments still booked; cancelling again retries them
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelBookingResponse) Reset() {
+	*x = CancelBookingResponse{}
+	mi := &file_protos_service_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelBookingResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelBookingResponse) ProtoMessage() {}
+
+func (x *CancelBookingResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelBookingResponse.ProtoReflect.Descriptor instead.
+func (*CancelBookingResponse) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *CancelBookingResponse) GetItinerary() *Itinerary {
+	if x != nil {
+		return x.Itinerary
+	}
+	return nil
+}
+
+func (x *CancelBookingResponse) GetFailures() []*TripConflict {
+	if x != nil {
+		return x.Failures
+	}
+	return nil
+}
+
+// ResumeBookingRequest books the segments of a saved plan that are not booked yet
+type ResumeBookingRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PlanId        int64                  `protobuf:"varint,1,opt,name=plan_id,json=planId,proto3" json:"plan_id,omitempty"`                                                                                            // ID of a saved plan
+	TravelerIds   []int64                `protobuf:"varint,2,rep,packed,name=traveler_ids,json=travelerIds,proto3" json:"traveler_ids,omitempty"`                                                                      // Users travelling, in the offers' traveler order
+	FlightOffers  map[string]string      `protobuf:"bytes,3,rep,name=flight_offers,json=flightOffers,proto3" json:"flight_offers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Provider flight offers as JSON, keyed by "fromID->toID"
+	HotelOffers   map[string]string      `protobuf:"bytes,4,rep,name=hotel_offers,json=hotelOffers,proto3" json:"hotel_offers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`    // Provider hotel offer IDs, keyed by node ID
+	CardNumber    string                 `protobuf:"bytes,5,opt,name=card_number,json=cardNumber,proto3" json:"card_number,omitempty"`                                                                                 // Card paying for the stays; required with hotel_offers
+	CardExpiry    string                 `protobuf:"bytes,6,opt,name=card_expiry,json=cardExpiry,proto3" json:"card_expiry,omitempty"`                                                                                 // YYYY-MM
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResumeBookingRequest) Reset() {
+	*x = ResumeBookingRequest{}
+	mi := &file_protos_service_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResumeBookingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResumeBookingRequest) ProtoMessage() {}
+
+func (x *ResumeBookingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResumeBookingRequest.ProtoReflect.Descriptor instead.
+func (*ResumeBookingRequest) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *ResumeBookingRequest) GetPlanId() int64 {
+	if x != nil {
+		return x.PlanId
+	}
+	return 0
+}
+
+func (x *ResumeBookingRequest) GetTravelerIds() []int64 {
+	if x != nil {
+		return x.TravelerIds
+	}
+	return nil
+}
+
+func (x *ResumeBookingRequest) GetFlightOffers() map[string]string {
+	if x != nil {
+		return x.FlightOffers
+	}
+	return nil
+}
+
+func (x *ResumeBookingRequest) GetHotelOffers() map[string]string {
+	if x != nil {
+		return x.HotelOffers
+	}
+	return nil
+}
+
+func (x *ResumeBookingRequest) GetCardNumber() string {
+	if x != nil {
+		return x.CardNumber
+	}
+	return ""
+}
+
+func (x *ResumeBookingRequest) GetCardExpiry() string {
+	if x != nil {
+		return x.CardExpiry
+	}
+	return ""
+}
+
+type ResumeBookingResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Itinerary     *Itinerary             `protobuf:"bytes,1,opt,name=itinerary,proto3" json:"itinerary,omitempty"` // Saved plan with the references of what is booked
+	Booked        int32                  `protobuf:"varint,2,opt,name=booked,proto3" json:"booked,omitempty"`      // Segments booked by this call
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResumeBookingResponse) Reset() {
+	*x = ResumeBookingResponse{}
+	mi := &file_protos_service_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResumeBookingResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResumeBookingResponse) ProtoMessage() {}
+
+func (x *ResumeBookingResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResumeBookingResponse.ProtoReflect.Descriptor instead.
+func (*ResumeBookingResponse) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *ResumeBookingResponse) GetItinerary() *Itinerary {
+	if x != nil {
+		return x.Itinerary
+	}
+	return nil
+}
+
+func (x *ResumeBookingResponse) GetBooked() int32 {
+	if x != nil {
+		return x.Booked
+	}
+	return 0
+}
+
+// TripGraph is an itinerary graph prepared for drawing on a map
+type TripGraph struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Nodes         []*TripGraphNode       `protobuf:"bytes,1,rep,name=nodes,proto3" json:"nodes,omitempty"`       // In visiting order
+	Edges         []*TripGraphEdge       `protobuf:"bytes,2,rep,name=edges,proto3" json:"edges,omitempty"`       // In departure order
+	Groups        []*TripGraphGroup      `protobuf:"bytes,3,rep,name=groups,proto3" json:"groups,omitempty"`     // Sub-graphs, e.g. day trips from a node
+	Warnings      []string               `protobuf:"bytes,4,rep,name=warnings,proto3" json:"warnings,omitempty"` // Parts left out of the map; only set on the top-level graph
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TripGraph) Reset() {
+	*x = TripGraph{}
+	mi := &file_protos_service_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TripGraph) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TripGraph) ProtoMessage() {}
+
+func (x *TripGraph) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TripGraph.ProtoReflect.Descriptor instead.
+func (*TripGraph) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *TripGraph) GetNodes() []*TripGraphNode {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+func (x *TripGraph) GetEdges() []*TripGraphEdge {
+	if x != nil {
+		return x.Edges
+	}
+	return nil
+}
+
+func (x *TripGraph) GetGroups() []*TripGraphGroup {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+func (x *TripGraph) GetWarnings() []string {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+type LatLng struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Lat           float64                `protobuf:"fixed64,1,opt,name=lat,proto3" json:"lat,omitempty"`
+	Lng           float64                `protobuf:"fixed64,2,opt,name=lng,proto3" json:"lng,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LatLng) Reset() {
+	*x = LatLng{}
+	mi := &file_protos_service_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LatLng) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LatLng) ProtoMessage() {}
+
+func (x *LatLng) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LatLng.ProtoReflect.Descriptor instead.
+func (*LatLng) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *LatLng) GetLat() float64 {
+	if x != nil {
+		return x.Lat
+	}
+	return 0
+}
+
+func (x *LatLng) GetLng() float64 {
+	if x != nil {
+		return x.Lng
+	}
+	return 0
+}
+
+type TripGraphNode struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Position      *LatLng                `protobuf:"bytes,2,opt,name=position,proto3" json:"position,omitempty"`
+	Label         string                 `protobuf:"bytes,3,opt,name=label,proto3" json:"label,omitempty"` // City or name of the location
+	Type          TripGraphNodeType      `protobuf:"varint,4,opt,name=type,proto3,enum=travelingman.TripGraphNodeType" json:"type,omitempty"`
+	StartTime     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"` // Arrival, or check-in for stays
+	EndTime       *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`       // Departure, or check-out for stays
+	Notes         string                 `protobuf:"bytes,7,opt,name=notes,proto3" json:"notes,omitempty"`                          // The user's notes for the node
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TripGraphNode) Reset() {
+	*x = TripGraphNode{}
+	mi := &file_protos_service_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TripGraphNode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TripGraphNode) ProtoMessage() {}
+
+func (x *TripGraphNode) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TripGraphNode.ProtoReflect.Descriptor instead.
+func (*TripGraphNode) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *TripGraphNode) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *TripGraphNode) GetPosition() *LatLng {
+	if x != nil {
+		return x.Position
+	}
+	return nil
+}
+
+func (x *TripGraphNode) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *TripGraphNode) GetType() TripGraphNodeType {
+	if x != nil {
+		return x.Type
+	}
+	return TripGraphNodeType_TRIP_GRAPH_NODE_TYPE_UNSPECIFIED
+}
+
+func (x *TripGraphNode) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *TripGraphNode) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+func (x *TripGraphNode) GetNotes() string {
+	if x != nil {
+		return x.Notes
+	}
+	return ""
+}
+
+type TripGraphEdge struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	FromId          string                 `protobuf:"bytes,1,opt,name=from_id,json=fromId,proto3" json:"from_id,omitempty"`
+	ToId            string                 `protobuf:"bytes,2,opt,name=to_id,json=toId,proto3" json:"to_id,omitempty"`
+	Mode            TransportType          `protobuf:"varint,3,opt,name=mode,proto3,enum=travelingman.TransportType" json:"mode,omitempty"`
+	Polyline        []*LatLng              `protobuf:"bytes,4,rep,name=polyline,proto3" json:"polyline,omitempty"` // Great-circle points from the from node to the to node
+	DurationSeconds int64                  `protobuf:"varint,5,opt,name=duration_seconds,json=durationSeconds,proto3" json:"duration_seconds,omitempty"`
+	Summary         string                 `protobuf:"bytes,6,opt,name=summary,proto3" json:"summary,omitempty"` // Selected option, e.g. "Flight BA 117 LHR-CDG, 120.00 EUR"
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *TripGraphEdge) Reset() {
+	*x = TripGraphEdge{}
+	mi := &file_protos_service_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TripGraphEdge) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TripGraphEdge) ProtoMessage() {}
+
+func (x *TripGraphEdge) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TripGraphEdge.ProtoReflect.Descriptor instead.
+func (*TripGraphEdge) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *TripGraphEdge) GetFromId() string {
+	if x != nil {
+		return x.FromId
+	}
+	return ""
+}
+
+func (x *TripGraphEdge) GetToId() string {
+	if x != nil {
+		return x.ToId
+	}
+	return ""
+}
+
+func (x *TripGraphEdge) GetMode() TransportType {
+	if x != nil {
+		return x.Mode
+	}
+	return TransportType_TRANSPORT_TYPE_UNSPECIFIED
+}
+
+func (x *TripGraphEdge) GetPolyline() []*LatLng {
+	if x != nil {
+		return x.Polyline
+	}
+	return nil
+}
+
+func (x *TripGraphEdge) GetDurationSeconds() int64 {
+	if x != nil {
+		return x.DurationSeconds
+	}
+	return 0
+}
+
+func (x *TripGraphEdge) GetSummary() string {
+	if x != nil {
+		return x.Summary
+	}
+	return ""
+}
+
+// TripGraphGroup is a sub-graph drawn inside its parent node
+type TripGraphGroup struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NodeId        string                 `protobuf:"bytes,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"` // Node the sub-graph belongs to; empty for a graph's intra-city details
+	Graph         *TripGraph             `protobuf:"bytes,2,opt,name=graph,proto3" json:"graph,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TripGraphGroup) Reset() {
+	*x = TripGraphGroup{}
+	mi := &file_protos_service_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TripGraphGroup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TripGraphGroup) ProtoMessage() {}
+
+func (x *TripGraphGroup) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TripGraphGroup.ProtoReflect.Descriptor instead.
+func (*TripGraphGroup) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *TripGraphGroup) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *TripGraphGroup) GetGraph() *TripGraph {
+	if x != nil {
+		return x.Graph
+	}
+	return nil
+}
+
+// TripCalendar is a saved plan laid out day by day for the calendar view
+type TripCalendar struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Destination   string                 `protobuf:"bytes,1,opt,name=destination,proto3" json:"destination,omitempty"` // First place stayed at, or where the trip ends
+	Days          []*TripDay             `protobuf:"bytes,2,rep,name=days,proto3" json:"days,omitempty"`               // In date order
+	Legs          []*TripLeg             `protobuf:"bytes,3,rep,name=legs,proto3" json:"legs,omitempty"`               // In the plan's order, sub-graphs last
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TripCalendar) Reset() {
+	*x = TripCalendar{}
+	mi := &file_protos_service_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TripCalendar) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TripCalendar) ProtoMessage() {}
+
+func (x *TripCalendar) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TripCalendar.ProtoReflect.Descriptor instead.
+func (*TripCalendar) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *TripCalendar) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+func (x *TripCalendar) GetDays() []*TripDay {
+	if x != nil {
+		return x.Days
+	}
+	return nil
+}
+
+func (x *TripCalendar) GetLegs() []*TripLeg {
+	if x != nil {
+		return x.Legs
+	}
+	return nil
+}
+
+// TripDay is one calendar day of a plan
+type TripDay struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DayNumber     int32                  `protobuf:"varint,1,opt,name=day_number,json=dayNumber,proto3" json:"day_number,omitempty"` // 1 for the first day
+	Date          *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=date,proto3" json:"date,omitempty"`                             // Midnight UTC of the day
+	Location      string                 `protobuf:"bytes,3,opt,name=location,proto3" json:"location,omitempty"`                     // Where the travellers are that day
+	Places        []*TripPlace           `protobuf:"bytes,4,rep,name=places,proto3" json:"places,omitempty"`                         // In visiting order
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TripDay) Reset() {
+	*x = TripDay{}
+	mi := &file_protos_service_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TripDay) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TripDay) ProtoMessage() {}
+
+func (x *TripDay) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TripDay.ProtoReflect.Descriptor instead.
+func (*TripDay) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *TripDay) GetDayNumber() int32 {
+	if x != nil {
+		return x.DayNumber
+	}
+	return 0
+}
+
+func (x *TripDay) GetDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Date
+	}
+	return nil
+}
+
+func (x *TripDay) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+func (x *TripDay) GetPlaces() []*TripPlace {
+	if x != nil {
+		return x.Places
+	}
+	return nil
+}
+
+// TripPlace is a node of the plan, listed on the day it is first visited
+type TripPlace struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NodeId        string                 `protobuf:"bytes,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	ParentNodeId  string                 `protobuf:"bytes,2,opt,name=parent_node_id,json=parentNodeId,proto3" json:"parent_node_id,omitempty"` // Node whose sub-graph the place is in, for activities
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Address       string                 `protobuf:"bytes,4,opt,name=address,proto3" json:"address,omitempty"`
+	PlaceType     string                 `protobuf:"bytes,5,opt,name=place_type,json=placeType,proto3" json:"place_type,omitempty"` // stop, stay or activity
+	Position      *LatLng                `protobuf:"bytes,6,opt,name=position,proto3" json:"position,omitempty"`                    // Unset if the place has no geocode
+	Notes         string                 `protobuf:"bytes,7,opt,name=notes,proto3" json:"notes,omitempty"`
+	VisitTime     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=visit_time,json=visitTime,proto3" json:"visit_time,omitempty"` // Unset if not known
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TripPlace) Reset() {
+	*x = TripPlace{}
+	mi := &file_protos_service_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TripPlace) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TripPlace) ProtoMessage() {}
+
+func (x *TripPlace) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TripPlace.ProtoReflect.Descriptor instead.
+func (*TripPlace) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *TripPlace) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *TripPlace) GetParentNodeId() string {
+	if x != nil {
+		return x.ParentNodeId
+	}
+	return ""
+}
+
+func (x *TripPlace) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TripPlace) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *TripPlace) GetPlaceType() string {
+	if x != nil {
+		return x.PlaceType
+	}
+	return ""
+}
+
+func (x *TripPlace) GetPosition() *LatLng {
+	if x != nil {
+		return x.Position
+	}
+	return nil
+}
+
+func (x *TripPlace) GetNotes() string {
+	if x != nil {
+		return x.Notes
+	}
+	return ""
+}
+
+func (x *TripPlace) GetVisitTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.VisitTime
+	}
+	return nil
+}
+
+// TripLeg is an edge of the plan
+type TripLeg struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ParentNodeId  string                 `protobuf:"bytes,1,opt,name=parent_node_id,json=parentNodeId,proto3" json:"parent_node_id,omitempty"` // Set for legs between activities, as for TripPlace
+	From          string                 `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`                                       // Names of the places
+	To            string                 `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	Mode          string                 `protobuf:"bytes,4,opt,name=mode,proto3" json:"mode,omitempty"` // e.g. FLIGHT or TRAIN
+	DepartureTime *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=departure_time,json=departureTime,proto3" json:"departure_time,omitempty"`
+	ArrivalTime   *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=arrival_time,json=arrivalTime,proto3" json:"arrival_time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TripLeg) Reset() {
+	*x = TripLeg{}
+	mi := &file_protos_service_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TripLeg) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TripLeg) ProtoMessage() {}
+
+func (x *TripLeg) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_service_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TripLeg.ProtoReflect.Descriptor instead.
+func (*TripLeg) Descriptor() ([]byte, []int) {
+	return file_protos_service_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *TripLeg) GetParentNodeId() string {
+	if x != nil {
+		return x.ParentNodeId
+	}
+	return ""
+}
+
+func (x *TripLeg) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *TripLeg) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *TripLeg) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *TripLeg) GetDepartureTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DepartureTime
+	}
+	return nil
+}
+
+func (x *TripLeg) GetArrivalTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ArrivalTime
+	}
+	return nil
+}
+
+var File_protos_service_proto protoreflect.FileDescriptor
+
+const file_protos_service_proto_rawDesc = "" +
+	"\n" +
+	"\x14protos/service.proto\x12\ftravelingman\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x15protos/bookings.proto\x1a\x13protos/common.proto\x1a\x12protos/graph.proto\x1a\x16protos/itinerary.proto\"=\n" +
+	"\x0fPlanTripRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x14\n" +
+	"\x05quick\x18\x02 \x01(\bR\x05quick\"\xae\x02\n" +
+	"\x10PlanTripResponse\x129\n" +
+	"\vitineraries\x18\x01 \x03(\v2\x17.travelingman.ItineraryR\vitineraries\x12\x12\n" +
+	"\x04text\x18\x02 \x01(\tR\x04text\x123\n" +
+	"\asummary\x18\x03 \x01(\v2\x19.travelingman.TripSummaryR\asummary\x123\n" +
+	"\bverified\x18\x04 \x01(\v2\x17.travelingman.ItineraryR\bverified\x126\n" +
+	"\bprogress\x18\x05 \x01(\v2\x1a.travelingman.PlanProgressR\bprogress\x12)\n" +
+	"\x05error\x18\x06 \x01(\v2\x13.travelingman.ErrorR\x05error\"U\n" +
+	"\fPlanProgress\x12-\n" +
+	"\x05stage\x18\x01 \x01(\x0e2\x17.travelingman.PlanStageR\x05stage\x12\x16\n" +
+	"\x06detail\x18\x02 \x01(\tR\x06detail\"\x8c\x02\n" +
+	"\vTripSummary\x12 \n" +
+	"\vdestination\x18\x01 \x01(\tR\vdestination\x129\n" +
+	"\n" +
+	"start_time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bend_time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\x12\x1c\n" +
+	"\ttravelers\x18\x04 \x01(\x05R\ttravelers\x12(\n" +
+	"\x05total\x18\x05 \x01(\v2\x12.travelingman.CostR\x05total\x12!\n" +
+	"\foption_count\x18\x06 \x01(\x05R\voptionCount\"\x9d\x01\n" +
+	"\x17GetPriceCalendarRequest\x12\x16\n" +
+	"\x06origin\x18\x01 \x01(\tR\x06origin\x12 \n" +
+	"\vdestination\x18\x02 \x01(\tR\vdestination\x12\x14\n" +
+	"\x05month\x18\x03 \x01(\tR\x05month\x12\x16\n" +
+	"\x06adults\x18\x04 \x01(\x05R\x06adults\x12\x1a\n" +
+	"\bcurrency\x18\x05 \x01(\tR\bcurrency\"S\n" +
+	"\x18GetPriceCalendarResponse\x127\n" +
+	"\bcalendar\x18\x01 \x01(\v2\x1b.travelingman.PriceCalendarR\bcalendar\"\xb9\x01\n" +
+	"\x13GetFareTrendRequest\x12\x16\n" +
+	"\x06origin\x18\x01 \x01(\tR\x06origin\x12 \n" +
+	"\vdestination\x18\x02 \x01(\tR\vdestination\x12\x1b\n" +
+	"\tfrom_date\x18\x03 \x01(\tR\bfromDate\x12\x17\n" +
+	"\ato_date\x18\x04 \x01(\tR\x06toDate\x12\x16\n" +
+	"\x06adults\x18\x05 \x01(\x05R\x06adults\x12\x1a\n" +
+	"\bcurrency\x18\x06 \x01(\tR\bcurrency\"E\n" +
+	"\x14GetFareTrendResponse\x12-\n" +
+	"\x05trend\x18\x01 \x01(\v2\x17.travelingman.FareTrendR\x05trend\"H\n" +
+	"\x0fSaveTripRequest\x125\n" +
+	"\titinerary\x18\x01 \x01(\v2\x17.travelingman.ItineraryR\titinerary\"I\n" +
+	"\x10SaveTripResponse\x125\n" +
+	"\titinerary\x18\x01 \x01(\v2\x17.travelingman.ItineraryR\titinerary\"J\n" +
+	"\x11UpdateTripRequest\x125\n" +
+	"\titinerary\x18\x01 \x01(\v2\x17.travelingman.ItineraryR\titinerary\"k\n" +
+	"\fTripConflict\x12!\n" +
+	"\fcomponent_id\x18\x01 \x01(\tR\vcomponentId\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1e\n" +
+	"\n" +
+	"suggestion\x18\x03 \x01(\tR\n" +
+	"suggestion\"\x85\x01\n" +
+	"\x12UpdateTripResponse\x125\n" +
+	"\titinerary\x18\x01 \x01(\v2\x17.travelingman.ItineraryR\titinerary\x128\n" +
+	"\tconflicts\x18\x02 \x03(\v2\x1a.travelingman.TripConflictR\tconflicts\",\n" +
+	"\x11VerifyPlanRequest\x12\x17\n" +
+	"\aplan_id\x18\x01 \x01(\x03R\x06planId\"K\n" +
+	"\x12VerifyPlanResponse\x125\n" +
+	"\titinerary\x18\x01 \x01(\v2\x17.travelingman.ItineraryR\titinerary\".\n" +
+	"\x13GetItineraryRequest\x12\x17\n" +
+	"\aplan_id\x18\x01 \x01(\x03R\x06planId\"M\n" +
+	"\x14GetItineraryResponse\x125\n" +
+	"\titinerary\x18\x01 \x01(\v2\x17.travelingman.ItineraryR\titinerary\"e\n" +
+	"\x13GetTripGraphRequest\x12\x17\n" +
+	"\aplan_id\x18\x01 \x01(\x03R\x06planId\x125\n" +
+	"\titinerary\x18\x02 \x01(\v2\x17.travelingman.ItineraryR\titinerary\"E\n" +
+	"\x14GetTripGraphResponse\x12-\n" +
+	"\x05graph\x18\x01 \x01(\v2\x17.travelingman.TripGraphR\x05graph\"1\n" +
+	"\x16GetTripCalendarRequest\x12\x17\n" +
+	"\aplan_id\x18\x01 \x01(\x03R\x06planId\"Q\n" +
+	"\x17GetTripCalendarResponse\x126\n" +
+	"\bcalendar\x18\x01 \x01(\v2\x1a.travelingman.TripCalendarR\bcalendar\"a\n" +
+	"\x15GetMoreOptionsRequest\x12\x17\n" +
+	"\aplan_id\x18\x01 \x01(\x03R\x06planId\x12\x12\n" +
+	"\x04edge\x18\x02 \x01(\tR\x04edge\x12\x1b\n" +
+	"\toption_id\x18\x03 \x01(\tR\boptionId\"K\n" +
+	"\x16GetMoreOptionsResponse\x121\n" +
+	"\aoptions\x18\x01 \x03(\v2\x17.travelingman.TransportR\aoptions\"J\n" +
+	"\x1cAutocompleteLocationsRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"U\n" +
+	"\x1dAutocompleteLocationsResponse\x124\n" +
+	"\tlocations\x18\x01 \x03(\v2\x16.travelingman.LocationR\tlocations\"\x87\x01\n" +
+	"\x11BookFlightRequest\x12\x1d\n" +
+	"\n" +
+	"offer_json\x18\x01 \x01(\tR\tofferJson\x12!\n" +
+	"\ftraveler_ids\x18\x02 \x03(\x03R\vtravelerIds\x120\n" +
+	"\x05split\x18\x03 \x01(\v2\x1a.travelingman.PaymentSplitR\x05split\"\x97\x01\n" +
+	"\x12BookFlightResponse\x12\x1d\n" +
+	"\n" +
+	"booking_id\x18\x01 \x01(\x03R\tbookingId\x12\x19\n" +
+	"\border_id\x18\x02 \x01(\tR\aorderId\x12-\n" +
+	"\x06shares\x18\x03 \x03(\v2\x15.travelingman.PaymentR\x06shares\x12\x18\n" +
+	"\asummary\x18\x04 \x01(\tR\asummary\"<\n" +
+	"\x1bRefreshBookingStatusRequest\x12\x1d\n" +
+	"\n" +
+	"booking_id\x18\x01 \x01(\x03R\tbookingId\"\xc6\x01\n" +
+	"\x1cRefreshBookingStatusResponse\x123\n" +
+	"\x06status\x18\x01 \x01(\x0e2\x1b.travelingman.BookingStatusR\x06status\x124\n" +
+	"\achanges\x18\x02 \x03(\v2\x1a.travelingman.FlightChangeR\achanges\x12;\n" +
+	"\ahistory\x18\x03 \x03(\v2!.travelingman.BookingStatusChangeR\ahistory\"8\n" +
+	"\x17GetBookingSplitsRequest\x12\x1d\n" +
+	"\n" +
+	"booking_id\x18\x01 \x01(\x03R\tbookingId\"{\n" +
+	"\x18GetBookingSplitsResponse\x12-\n" +
+	"\x06shares\x18\x01 \x03(\v2\x15.travelingman.PaymentR\x06shares\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\tR\x05total\x12\x1a\n" +
+	"\bcurrency\x18\x03 \x01(\tR\bcurrency\"/\n" +
+	"\x14CancelBookingRequest\x12\x17\n" +
+	"\aplan_id\x18\x01 \x01(\x03R\x06planId\"\x86\x01\n" +
+	"\x15CancelBookingResponse\x125\n" +
+	"\titinerary\x18\x01 \x01(\v2\x17.travelingman.ItineraryR\titinerary\x126\n" +
+	"\bfailures\x18\x02 \x03(\v2\x1a.travelingman.TripConflictR\bfailures\"\xc8\x03\n" +
+	"\x14ResumeBookingRequest\x12\x17\n" +
+	"\aplan_id\x18\x01 \x01(\x03R\x06planId\x12!\n" +
+	"\ftraveler_ids\x18\x02 \x03(\x03R\vtravelerIds\x12Y\n" +
+	"\rflight_offers\x18\x03 \x03(\v24.travelingman.ResumeBookingRequest.FlightOffersEntryR\fflightOffers\x12V\n" +
+	"\fhotel_offers\x18\x04 \x03(\v23.travelingman.ResumeBookingRequest.HotelOffersEntryR\vhotelOffers\x12\x1f\n" +
+	"\vcard_number\x18\x05 \x01(\tR\n" +
+	"cardNumber\x12\x1f\n" +
+	"\vcard_expiry\x18\x06 \x01(\tR\n" +
+	"cardExpiry\x1a?\n" +
+	"\x11FlightOffersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a>\n" +
+	"\x10HotelOffersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"f\n" +
+	"\x15ResumeBookingResponse\x125\n" +
+	"\titinerary\x18\x01 \x01(\v2\x17.travelingman.ItineraryR\titinerary\x12\x16\n" +
+	"\x06booked\x18\x02 \x01(\x05R\x06booked\"\xc3\x01\n" +
+	"\tTripGraph\x121\n" +
+	"\x05nodes\x18\x01 \x03(\v2\x1b.travelingman.TripGraphNodeR\x05nodes\x121\n" +
+	"\x05edges\x18\x02 \x03(\v2\x1b.travelingman.TripGraphEdgeR\x05edges\x124\n" +
+	"\x06groups\x18\x03 \x03(\v2\x1c.travelingman.TripGraphGroupR\x06groups\x12\x1a\n" +
+	"\bwarnings\x18\x04 \x03(\tR\bwarnings\",\n" +
+	"\x06LatLng\x12\x10\n" +
+	"\x03lat\x18\x01 \x01(\x01R\x03lat\x12\x10\n" +
+	"\x03lng\x18\x02 \x01(\x01R\x03lng\"\xa4\x02\n" +
+	"\rTripGraphNode\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x120\n" +
+	"\bposition\x18\x02 \x01(\v2\x14.travelingman.LatLngR\bposition\x12\x14\n" +
+	"\x05label\x18\x03 \x01(\tR\x05label\x123\n" +
+	"\x04type\x18\x04 \x01(\x0e2\x1f.travelingman.TripGraphNodeTypeR\x04type\x129\n" +
+	"\n" +
+	"start_time\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bend_time\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\x12\x14\n" +
+	"\x05notes\x18\a \x01(\tR\x05notes\"\xe5\x01\n" +
+	"\rTripGraphEdge\x12\x17\n" +
+	"\afrom_id\x18\x01 \x01(\tR\x06fromId\x12\x13\n" +
+	"\x05to_id\x18\x02 \x01(\tR\x04toId\x12/\n" +
+	"\x04mode\x18\x03 \x01(\x0e2\x1b.travelingman.TransportTypeR\x04mode\x120\n" +
+	"\bpolyline\x18\x04 \x03(\v2\x14.travelingman.LatLngR\bpolyline\x12)\n" +
+	"\x10duration_seconds\x18\x05 \x01(\x03R\x0fdurationSeconds\x12\x18\n" +
+	"\asummary\x18\x06 \x01(\tR\asummary\"X\n" +
+	"\x0eTripGraphGroup\x12\x17\n" +
+	"\anode_id\x18\x01 \x01(\tR\x06nodeId\x12-\n" +
+	"\x05graph\x18\x02 \x01(\v2\x17.travelingman.TripGraphR\x05graph\"\x86\x01\n" +
+	"\fTripCalendar\x12 \n" +
+	"\vdestination\x18\x01 \x01(\tR\vdestination\x12)\n" +
+	"\x04days\x18\x02 \x03(\v2\x15.travelingman.TripDayR\x04days\x12)\n" +
+	"\x04legs\x18\x03 \x03(\v2\x15.travelingman.TripLegR\x04legs\"\xa5\x01\n" +
+	"\aTripDay\x12\x1d\n" +
+	"\n" +
+	"day_number\x18\x01 \x01(\x05R\tdayNumber\x12.\n" +
+	"\x04date\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04date\x12\x1a\n" +
+	"\blocation\x18\x03 \x01(\tR\blocation\x12/\n" +
+	"\x06places\x18\x04 \x03(\v2\x17.travelingman.TripPlaceR\x06places\"\x9a\x02\n" +
+	"\tTripPlace\x12\x17\n" +
+	"\anode_id\x18\x01 \x01(\tR\x06nodeId\x12$\n" +
+	"\x0eparent_node_id\x18\x02 \x01(\tR\fparentNodeId\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x18\n" +
+	"\aaddress\x18\x04 \x01(\tR\aaddress\x12\x1d\n" +
+	"\n" +
+	"place_type\x18\x05 \x01(\tR\tplaceType\x120\n" +
+	"\bposition\x18\x06 \x01(\v2\x14.travelingman.LatLngR\bposition\x12\x14\n" +
+	"\x05notes\x18\a \x01(\tR\x05notes\x129\n" +
+	"\n" +
+	"visit_time\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tvisitTime\"\xe9\x01\n" +
+	"\aTripLeg\x12$\n" +
+	"\x0eparent_node_id\x18\x01 \x01(\tR\fparentNodeId\x12\x12\n" +
+	"\x04from\x18\x02 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x03 \x01(\tR\x02to\x12\x12\n" +
+	"\x04mode\x18\x04 \x01(\tR\x04mode\x12A\n" +
+	"\x0edeparture_time\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\rdepartureTime\x12=\n" +
+	"\farrival_time\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\varrivalTime*\xbe\x01\n" +
+	"\tPlanStage\x12\x1a\n" +
+	"\x16PLAN_STAGE_UNSPECIFIED\x10\x00\x12\x17\n" +
+	"\x13PLAN_STAGE_PLANNING\x10\x01\x12\x18\n" +
+	"\x14PLAN_STAGE_TOOL_CALL\x10\x02\x12\x17\n" +
+	"\x13PLAN_STAGE_PROPOSED\x10\x03\x12#\n" +
+	"\x1fPLAN_STAGE_VERIFICATION_STARTED\x10\x04\x12$\n" +
+	" PLAN_STAGE_VERIFICATION_FINISHED\x10\x05*\x9f\x01\n" +
+	"\x11TripGraphNodeType\x12$\n" +
+	" TRIP_GRAPH_NODE_TYPE_UNSPECIFIED\x10\x00\x12\x1f\n" +
+	"\x1bTRIP_GRAPH_NODE_TYPE_ORIGIN\x10\x01\x12\x1d\n" +
+	"\x19TRIP_GRAPH_NODE_TYPE_STAY\x10\x02\x12$\n" +
+	" TRIP_GRAPH_NODE_TYPE_DESTINATION\x10\x032\x97\f\n" +
+	"\rTravelService\x12I\n" +
+	"\bPlanTrip\x12\x1d.travelingman.PlanTripRequest\x1a\x1e.travelingman.PlanTripResponse\x12Q\n" +
+	"\x0ePlanTripStream\x12\x1d.travelingman.PlanTripRequest\x1a\x1e.travelingman.PlanTripResponse0\x01\x12a\n" +
+	"\x10GetPriceCalendar\x12%.travelingman.GetPriceCalendarRequest\x1a&.travelingman.GetPriceCalendarResponse\x12U\n" +
+	"\fGetFareTrend\x12!.travelingman.GetFareTrendRequest\x1a\".travelingman.GetFareTrendResponse\x12I\n" +
+	"\bSaveTrip\x12\x1d.travelingman.SaveTripRequest\x1a\x1e.travelingman.SaveTripResponse\x12O\n" +
+	"\n" +
+	"UpdateTrip\x12\x1f.travelingman.UpdateTripRequest\x1a .travelingman.UpdateTripResponse\x12O\n" +
+	"\n" +
+	"VerifyPlan\x12\x1f.travelingman.VerifyPlanRequest\x1a .travelingman.VerifyPlanResponse\x12U\n" +
+	"\fGetTripGraph\x12!.travelingman.GetTripGraphRequest\x1a\".travelingman.GetTripGraphResponse\x12c\n" +
+	"\x0fGetTripCalendar\x12$.travelingman.GetTripCalendarRequest\x1a%.travelingman.GetTripCalendarResponse\"\x03\x90\x02\x01\x12`\n" +
+	"\x0eGetMoreOptions\x12#.travelingman.GetMoreOptionsRequest\x1a$.travelingman.GetMoreOptionsResponse\"\x03\x90\x02\x01\x12p\n" +
+	"\x15AutocompleteLocations\x12*.travelingman.AutocompleteLocationsRequest\x1a+.travelingman.AutocompleteLocationsResponse\x12O\n" +
+	"\n" +
+	"BookFlight\x12\x1f.travelingman.BookFlightRequest\x1a .travelingman.BookFlightResponse\x12m\n" +
+	"\x14RefreshBookingStatus\x12).travelingman.RefreshBookingStatusRequest\x1a*.travelingman.RefreshBookingStatusResponse\x12a\n" +
+	"\x10GetBookingSplits\x12%.travelingman.GetBookingSplitsRequest\x1a&.travelingman.GetBookingSplitsResponse\x12X\n" +
+	"\rCancelBooking\x12\".travelingman.CancelBookingRequest\x1a#.travelingman.CancelBookingResponse\x12X\n" +
+	"\rResumeBooking\x12\".travelingman.ResumeBookingRequest\x1a#.travelingman.ResumeBookingResponse\x12Z\n" +
+	"\fGetItinerary\x12!.travelingman.GetItineraryRequest\x1a\".travelingman.GetItineraryResponse\"\x03\x90\x02\x01B#Z!github.com/va6996/travelingman/pbb\x06proto3"
 
 var (
 	file_protos_service_proto_rawDescOnce sync.Once
@@ -133,21 +2936,164 @@ func file_protos_service_proto_rawDescGZIP() []byte {
 	return file_protos_service_proto_rawDescData
 }
 
-var file_protos_service_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_protos_service_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_protos_service_proto_msgTypes = make([]protoimpl.MessageInfo, 46)
 var file_protos_service_proto_goTypes = []any{
-	(*PlanTripRequest)(nil),  // 0: travelingman.PlanTripRequest
-	(*PlanTripResponse)(nil), // 1: travelingman.PlanTripResponse
-	(*Itinerary)(nil),        // 2: travelingman.Itinerary
+	(PlanStage)(0),                        // 0: travelingman.PlanStage
+	(TripGraphNodeType)(0),                // 1: travelingman.TripGraphNodeType
+	(*PlanTripRequest)(nil),               // 2: travelingman.PlanTripRequest
+	(*PlanTripResponse)(nil),              // 3: travelingman.PlanTripResponse
+	(*PlanProgress)(nil),                  // 4: travelingman.PlanProgress
+	(*TripSummary)(nil),                   // 5: travelingman.TripSummary
+	(*GetPriceCalendarRequest)(nil),       // 6: travelingman.GetPriceCalendarRequest
+	(*GetPriceCalendarResponse)(nil),      // 7: travelingman.GetPriceCalendarResponse
+	(*GetFareTrendRequest)(nil),           // 8: travelingman.GetFareTrendRequest
+	(*GetFareTrendResponse)(nil),          // 9: travelingman.GetFareTrendResponse
+	(*SaveTripRequest)(nil),               // 10: travelingman.SaveTripRequest
+	(*SaveTripResponse)(nil),              // 11: travelingman.SaveTripResponse
+	(*UpdateTripRequest)(nil),             // 12: travelingman.UpdateTripRequest
+	(*TripConflict)(nil),                  // 13: travelingman.TripConflict
+	(*UpdateTripResponse)(nil),            // 14: travelingman.UpdateTripResponse
+	(*VerifyPlanRequest)(nil),             // 15: travelingman.VerifyPlanRequest
+	(*VerifyPlanResponse)(nil),            // 16: travelingman.VerifyPlanResponse
+	(*GetItineraryRequest)(nil),           // 17: travelingman.GetItineraryRequest
+	(*GetItineraryResponse)(nil),          // 18: travelingman.GetItineraryResponse
+	(*GetTripGraphRequest)(nil),           // 19: travelingman.GetTripGraphRequest
+	(*GetTripGraphResponse)(nil),          // 20: travelingman.GetTripGraphResponse
+	(*GetTripCalendarRequest)(nil),        // 21: travelingman.GetTripCalendarRequest
+	(*GetTripCalendarResponse)(nil),       // 22: travelingman.GetTripCalendarResponse
+	(*GetMoreOptionsRequest)(nil),         // 23: travelingman.GetMoreOptionsRequest
+	(*GetMoreOptionsResponse)(nil),        // 24: travelingman.GetMoreOptionsResponse
+	(*AutocompleteLocationsRequest)(nil),  // 25: travelingman.AutocompleteLocationsRequest
+	(*AutocompleteLocationsResponse)(nil), // 26: travelingman.AutocompleteLocationsResponse
+	(*BookFlightRequest)(nil),             // 27: travelingman.BookFlightRequest
+	(*BookFlightResponse)(nil),            // 28: travelingman.BookFlightResponse
+	(*RefreshBookingStatusRequest)(nil),   // 29: travelingman.RefreshBookingStatusRequest
+	(*RefreshBookingStatusResponse)(nil),  // 30: travelingman.RefreshBookingStatusResponse
+	(*GetBookingSplitsRequest)(nil),       // 31: travelingman.GetBookingSplitsRequest
+	(*GetBookingSplitsResponse)(nil),      // 32: travelingman.GetBookingSplitsResponse
+	(*CancelBookingRequest)(nil),          // 33: travelingman.CancelBookingRequest
+	(*CancelBookingResponse)(nil),         // 34: travelingman.CancelBookingResponse
+	(*ResumeBookingRequest)(nil),          // 35: travelingman.ResumeBookingRequest
+	(*ResumeBookingResponse)(nil),         // 36: travelingman.ResumeBookingResponse
+	(*TripGraph)(nil),                     // 37: travelingman.TripGraph
+	(*LatLng)(nil),                        // 38: travelingman.LatLng
+	(*TripGraphNode)(nil),                 // 39: travelingman.TripGraphNode
+	(*TripGraphEdge)(nil),                 // 40: travelingman.TripGraphEdge
+	(*TripGraphGroup)(nil),                // 41: travelingman.TripGraphGroup
+	(*TripCalendar)(nil),                  // 42: travelingman.TripCalendar
+	(*TripDay)(nil),                       // 43: travelingman.TripDay
+	(*TripPlace)(nil),                     // 44: travelingman.TripPlace
+	(*TripLeg)(nil),                       // 45: travelingman.TripLeg
+	nil,                                   // 46: travelingman.ResumeBookingRequest.FlightOffersEntry
+	nil,                                   // 47: travelingman.ResumeBookingRequest.HotelOffersEntry
+	(*Itinerary)(nil),                     // 48: travelingman.Itinerary
+	(*Error)(nil),                         // 49: travelingman.Error
+	(*timestamppb.Timestamp)(nil),         // 50: google.protobuf.Timestamp
+	(*Cost)(nil),                          // 51: travelingman.Cost
+	(*PriceCalendar)(nil),                 // 52: travelingman.PriceCalendar
+	(*FareTrend)(nil),                     // 53: travelingman.FareTrend
+	(*Transport)(nil),                     // 54: travelingman.Transport
+	(*Location)(nil),                      // 55: travelingman.Location
+	(*PaymentSplit)(nil),                  // 56: travelingman.PaymentSplit
+	(*Payment)(nil),                       // 57: travelingman.Payment
+	(BookingStatus)(0),                    // 58: travelingman.BookingStatus
+	(*FlightChange)(nil),                  // 59: travelingman.FlightChange
+	(*BookingStatusChange)(nil),           // 60: travelingman.BookingStatusChange
+	(TransportType)(0),                    // 61: travelingman.TransportType
 }
 var file_protos_service_proto_depIdxs = []int32{
-	2, // 0: travelingman.PlanTripResponse.itineraries:type_name -> travelingman.Itinerary
-	0, // 1: travelingman.TravelService.PlanTrip:input_type -> travelingman.PlanTripRequest
-	1, // 2: travelingman.TravelService.PlanTrip:output_type -> travelingman.PlanTripResponse
-	2, // [2:3] is the sub-list for method output_type
-	1, // [1:2] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	48, // 0: travelingman.PlanTripResponse.itineraries:type_name -> travelingman.Itinerary
+	5,  // 1: travelingman.PlanTripResponse.summary:type_name -> travelingman.TripSummary
+	48, // 2: travelingman.PlanTripResponse.verified:type_name -> travelingman.Itinerary
+	4,  // 3: travelingman.PlanTripResponse.progress:type_name -> travelingman.PlanProgress
+	49, // 4: travelingman.PlanTripResponse.error:type_name -> travelingman.Error
+	0,  // 5: travelingman.PlanProgress.stage:type_name -> travelingman.PlanStage
+	50, // 6: travelingman.TripSummary.start_time:type_name -> google.protobuf.Timestamp
+	50, // 7: travelingman.TripSummary.end_time:type_name -> google.protobuf.Timestamp
+	51, // 8: travelingman.TripSummary.total:type_name -> travelingman.Cost
+	52, // 9: travelingman.GetPriceCalendarResponse.calendar:type_name -> travelingman.PriceCalendar
+	53, // 10: travelingman.GetFareTrendResponse.trend:type_name -> travelingman.FareTrend
+	48, // 11: travelingman.SaveTripRequest.itinerary:type_name -> travelingman.Itinerary
+	48, // 12: travelingman.SaveTripResponse.itinerary:type_name -> travelingman.Itinerary
+	48, // 13: travelingman.UpdateTripRequest.itinerary:type_name -> travelingman.Itinerary
+	48, // 14: travelingman.UpdateTripResponse.itinerary:type_name -> travelingman.Itinerary
+	13, // 15: travelingman.UpdateTripResponse.conflicts:type_name -> travelingman.TripConflict
+	48, // 16: travelingman.VerifyPlanResponse.itinerary:type_name -> travelingman.Itinerary
+	48, // 17: travelingman.GetItineraryResponse.itinerary:type_name -> travelingman.Itinerary
+	48, // 18: travelingman.GetTripGraphRequest.itinerary:type_name -> travelingman.Itinerary
+	37, // 19: travelingman.GetTripGraphResponse.graph:type_name -> travelingman.TripGraph
+	42, // 20: travelingman.GetTripCalendarResponse.calendar:type_name -> travelingman.TripCalendar
+	54, // 21: travelingman.GetMoreOptionsResponse.options:type_name -> travelingman.Transport
+	55, // 22: travelingman.AutocompleteLocationsResponse.locations:type_name -> travelingman.Location
+	56, // 23: travelingman.BookFlightRequest.split:type_name -> travelingman.PaymentSplit
+	57, // 24: travelingman.BookFlightResponse.shares:type_name -> travelingman.Payment
+	58, // 25: travelingman.RefreshBookingStatusResponse.status:type_name -> travelingman.BookingStatus
+	59, // 26: travelingman.RefreshBookingStatusResponse.changes:type_name -> travelingman.FlightChange
+	60, // 27: travelingman.RefreshBookingStatusResponse.history:type_name -> travelingman.BookingStatusChange
+	57, // 28: travelingman.GetBookingSplitsResponse.shares:type_name -> travelingman.Payment
+	48, // 29: travelingman.CancelBookingResponse.itinerary:type_name -> travelingman.Itinerary
+	13, // 30: travelingman.CancelBookingResponse.failures:type_name -> travelingman.TripConflict
+	46, // 31: travelingman.ResumeBookingRequest.flight_offers:type_name -> travelingman.ResumeBookingRequest.FlightOffersEntry
+	47, // 32: travelingman.ResumeBookingRequest.hotel_offers:type_name -> travelingman.ResumeBookingRequest.HotelOffersEntry
+	48, // 33: travelingman.ResumeBookingResponse.itinerary:type_name -> travelingman.Itinerary
+	39, // 34: travelingman.TripGraph.nodes:type_name -> travelingman.TripGraphNode
+	40, // 35: travelingman.TripGraph.edges:type_name -> travelingman.TripGraphEdge
+	41, // 36: travelingman.TripGraph.groups:type_name -> travelingman.TripGraphGroup
+	38, // 37: travelingman.TripGraphNode.position:type_name -> travelingman.LatLng
+	1,  // 38: travelingman.TripGraphNode.type:type_name -> travelingman.TripGraphNodeType
+	50, // 39: travelingman.TripGraphNode.start_time:type_name -> google.protobuf.Timestamp
+	50, // 40: travelingman.TripGraphNode.end_time:type_name -> google.protobuf.Timestamp
+	61, // 41: travelingman.TripGraphEdge.mode:type_name -> travelingman.TransportType
+	38, // 42: travelingman.TripGraphEdge.polyline:type_name -> travelingman.LatLng
+	37, // 43: travelingman.TripGraphGroup.graph:type_name -> travelingman.TripGraph
+	43, // 44: travelingman.TripCalendar.days:type_name -> travelingman.TripDay
+	45, // 45: travelingman.TripCalendar.legs:type_name -> travelingman.TripLeg
+	50, // 46: travelingman.TripDay.date:type_name -> google.protobuf.Timestamp
+	44, // 47: travelingman.TripDay.places:type_name -> travelingman.TripPlace
+	38, // 48: travelingman.TripPlace.position:type_name -> travelingman.LatLng
+	50, // 49: travelingman.TripPlace.visit_time:type_name -> google.protobuf.Timestamp
+	50, // 50: travelingman.TripLeg.departure_time:type_name -> google.protobuf.Timestamp
+	50, // 51: travelingman.TripLeg.arrival_time:type_name -> google.protobuf.Timestamp
+	2,  // 52: travelingman.TravelService.PlanTrip:input_type -> travelingman.PlanTripRequest
+	2,  // 53: travelingman.TravelService.PlanTripStream:input_type -> travelingman.PlanTripRequest
+	6,  // 54: travelingman.TravelService.GetPriceCalendar:input_type -> travelingman.GetPriceCalendarRequest
+	8,  // 55: travelingman.TravelService.GetFareTrend:input_type -> travelingman.GetFareTrendRequest
+	10, // 56: travelingman.TravelService.SaveTrip:input_type -> travelingman.SaveTripRequest
+	12, // 57: travelingman.TravelService.UpdateTrip:input_type -> travelingman.UpdateTripRequest
+	15, // 58: travelingman.TravelService.VerifyPlan:input_type -> travelingman.VerifyPlanRequest
+	19, // 59: travelingman.TravelService.GetTripGraph:input_type -> travelingman.GetTripGraphRequest
+	21, // 60: travelingman.TravelService.GetTripCalendar:input_type -> travelingman.GetTripCalendarRequest
+	23, // 61: travelingman.TravelService.GetMoreOptions:input_type -> travelingman.GetMoreOptionsRequest
+	25, // 62: travelingman.TravelService.AutocompleteLocations:input_type -> travelingman.AutocompleteLocationsRequest
+	27, // 63: travelingman.TravelService.BookFlight:input_type -> travelingman.BookFlightRequest
+	29, // 64: travelingman.TravelService.RefreshBookingStatus:input_type -> travelingman.RefreshBookingStatusRequest
+	31, // 65: travelingman.TravelService.GetBookingSplits:input_type -> travelingman.GetBookingSplitsRequest
+	33, // 66: travelingman.TravelService.CancelBooking:input_type -> travelingman.CancelBookingRequest
+	35, // 67: travelingman.TravelService.ResumeBooking:input_type -> travelingman.ResumeBookingRequest
+	17, // 68: travelingman.TravelService.GetItinerary:input_type -> travelingman.GetItineraryRequest
+	3,  // 69: travelingman.TravelService.PlanTrip:output_type -> travelingman.PlanTripResponse
+	3,  // 70: travelingman.TravelService.PlanTripStream:output_type -> travelingman.PlanTripResponse
+	7,  // 71: travelingman.TravelService.GetPriceCalendar:output_type -> travelingman.GetPriceCalendarResponse
+	9,  // 72: travelingman.TravelService.GetFareTrend:output_type -> travelingman.GetFareTrendResponse
+	11, // 73: travelingman.TravelService.SaveTrip:output_type -> travelingman.SaveTripResponse
+	14, // 74: travelingman.TravelService.UpdateTrip:output_type -> travelingman.UpdateTripResponse
+	16, // 75: travelingman.TravelService.VerifyPlan:output_type -> travelingman.VerifyPlanResponse
+	20, // 76: travelingman.TravelService.GetTripGraph:output_type -> travelingman.GetTripGraphResponse
+	22, // 77: travelingman.TravelService.GetTripCalendar:output_type -> travelingman.GetTripCalendarResponse
+	24, // 78: travelingman.TravelService.GetMoreOptions:output_type -> travelingman.GetMoreOptionsResponse
+	26, // 79: travelingman.TravelService.AutocompleteLocations:output_type -> travelingman.AutocompleteLocationsResponse
+	28, // 80: travelingman.TravelService.BookFlight:output_type -> travelingman.BookFlightResponse
+	30, // 81: travelingman.TravelService.RefreshBookingStatus:output_type -> travelingman.RefreshBookingStatusResponse
+	32, // 82: travelingman.TravelService.GetBookingSplits:output_type -> travelingman.GetBookingSplitsResponse
+	34, // 83: travelingman.TravelService.CancelBooking:output_type -> travelingman.CancelBookingResponse
+	36, // 84: travelingman.TravelService.ResumeBooking:output_type -> travelingman.ResumeBookingResponse
+	18, // 85: travelingman.TravelService.GetItinerary:output_type -> travelingman.GetItineraryResponse
+	69, // [69:86] is the sub-list for method output_type
+	52, // [52:69] is the sub-list for method input_type
+	52, // [52:52] is the sub-list for extension type_name
+	52, // [52:52] is the sub-list for extension extendee
+	0,  // [0:52] is the sub-list for field type_name
 }
 
 func init() { file_protos_service_proto_init() }
@@ -155,19 +3101,23 @@ func file_protos_service_proto_init() {
 	if File_protos_service_proto != nil {
 		return
 	}
+	file_protos_bookings_proto_init()
+	file_protos_common_proto_init()
 	file_protos_graph_proto_init()
+	file_protos_itinerary_proto_init()
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_protos_service_proto_rawDesc), len(file_protos_service_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   2,
+			NumEnums:      2,
+			NumMessages:   46,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_protos_service_proto_goTypes,
 		DependencyIndexes: file_protos_service_proto_depIdxs,
+		EnumInfos:         file_protos_service_proto_enumTypes,
 		MessageInfos:      file_protos_service_proto_msgTypes,
 	}.Build()
 	File_protos_service_proto = out.File
@@ -33,7 +33,8 @@ type User struct {
 	DriversLicenses []*DriversLicense      `protobuf:"bytes,7,rep,name=drivers_licenses,json=driversLicenses,proto3" json:"drivers_licenses,omitempty"`
 	DateOfBirth     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=date_of_birth,json=dateOfBirth,proto3" json:"date_of_birth,omitempty"`
 	Gender          string                 `protobuf:"bytes,9,opt,name=gender,proto3" json:"gender,omitempty"` // MALE, FEMALE
-	Phone           string                 `protobuf:"bytes,10,opt,name=phone,proto3" json:"phone,omitempty"`
+	Phone           string                 `protobuf:"bytes,10,opt,name=phone,proto3" json:"phone,omitempty"`  // As entered, e.g. "+44 20 7946 0958" or "020 7946 0958"
+	Address         *PostalAddress         `protobuf:"bytes,11,opt,name=address,proto3" json:"address,omitempty"`
 	unknownFields   protoimpl.UnknownFields
 	sizeCache       protoimpl.SizeCache
 }
@@ -138,6 +139,82 @@ func (x *User) GetPhone() string {
 	return ""
 }
 
+func (x *User) GetAddress() *PostalAddress {
+	if x != nil {
+		return x.Address
+	}
+	return nil
+}
+
+// PostalAddress is a user's home address, used for booking contacts
+type PostalAddress struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Lines         []string               `protobuf:"bytes,1,rep,name=lines,proto3" json:"lines,omitempty"` // Street address, first line first
+	PostalCode    string                 `protobuf:"bytes,2,opt,name=postal_code,json=postalCode,proto3" json:"postal_code,omitempty"`
+	City          string                 `protobuf:"bytes,3,opt,name=city,proto3" json:"city,omitempty"`
+	CountryCode   string                 `protobuf:"bytes,4,opt,name=country_code,json=countryCode,proto3" json:"country_code,omitempty"` // ISO 3166-1 alpha-2, e.g. "GB"
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PostalAddress) Reset() {
+	*x = PostalAddress{}
+	mi := &file_protos_users_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PostalAddress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PostalAddress) ProtoMessage() {}
+
+func (x *PostalAddress) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_users_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PostalAddress.ProtoReflect.Descriptor instead.
+func (*PostalAddress) Descriptor() ([]byte, []int) {
+	return file_protos_users_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PostalAddress) GetLines() []string {
+	if x != nil {
+		return x.Lines
+	}
+	return nil
+}
+
+func (x *PostalAddress) GetPostalCode() string {
+	if x != nil {
+		return x.PostalCode
+	}
+	return ""
+}
+
+func (x *PostalAddress) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *PostalAddress) GetCountryCode() string {
+	if x != nil {
+		return x.CountryCode
+	}
+	return ""
+}
+
 type Passport struct {
 	state            protoimpl.MessageState `protogen:"open.v1"`
 	Id               int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -155,7 +232,7 @@ type Passport struct {
 
 func (x *Passport) Reset() {
 	*x = Passport{}
-	mi := &file_protos_users_proto_msgTypes[1]
+	mi := &file_protos_users_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -167,7 +244,7 @@ func (x *Passport) String() string {
 func (*Passport) ProtoMessage() {}
 
 func (x *Passport) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_users_proto_msgTypes[1]
+	mi := &file_protos_users_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -180,7 +257,7 @@ func (x *Passport) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Passport.ProtoReflect.Descriptor instead.
 func (*Passport) Descriptor() ([]byte, []int) {
-	return file_protos_users_proto_rawDescGZIP(), []int{1}
+	return file_protos_users_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *Passport) GetId() int64 {
@@ -259,7 +336,7 @@ type DriversLicense struct {
 
 func (x *DriversLicense) Reset() {
 	*x = DriversLicense{}
-	mi := &file_protos_users_proto_msgTypes[2]
+	mi := &file_protos_users_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -271,7 +348,7 @@ func (x *DriversLicense) String() string {
 func (*DriversLicense) ProtoMessage() {}
 
 func (x *DriversLicense) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_users_proto_msgTypes[2]
+	mi := &file_protos_users_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -284,7 +361,7 @@ func (x *DriversLicense) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DriversLicense.ProtoReflect.Descriptor instead.
 func (*DriversLicense) Descriptor() ([]byte, []int) {
-	return file_protos_users_proto_rawDescGZIP(), []int{2}
+	return file_protos_users_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *DriversLicense) GetId() int64 {
@@ -337,7 +414,7 @@ type TravelGroup struct {
 
 func (x *TravelGroup) Reset() {
 	*x = TravelGroup{}
-	mi := &file_protos_users_proto_msgTypes[3]
+	mi := &file_protos_users_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -349,7 +426,7 @@ func (x *TravelGroup) String() string {
 func (*TravelGroup) ProtoMessage() {}
 
 func (x *TravelGroup) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_users_proto_msgTypes[3]
+	mi := &file_protos_users_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -362,7 +439,7 @@ func (x *TravelGroup) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TravelGroup.ProtoReflect.Descriptor instead.
 func (*TravelGroup) Descriptor() ([]byte, []int) {
-	return file_protos_users_proto_rawDescGZIP(), []int{3}
+	return file_protos_users_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *TravelGroup) GetGroupId() int64 {
@@ -418,7 +495,7 @@ var File_protos_users_proto protoreflect.FileDescriptor
 
 const file_protos_users_proto_rawDesc = "" +
 	"\n" +
-	"\x12protos/users.proto\x12\ftravelingman\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x12protos/graph.proto\"\x96\x03\n" +
+	"\x12protos/users.proto\x12\ftravelingman\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x12protos/graph.proto\"\xcd\x03\n" +
 	"\x04User\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x14\n" +
 	"\x05email\x18\x02 \x01(\tR\x05email\x12#\n" +
@@ -431,7 +508,14 @@ const file_protos_users_proto_rawDesc = "" +
 	"\rdate_of_birth\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\vdateOfBirth\x12\x16\n" +
 	"\x06gender\x18\t \x01(\tR\x06gender\x12\x14\n" +
 	"\x05phone\x18\n" +
-	" \x01(\tR\x05phone\"\xe2\x02\n" +
+	" \x01(\tR\x05phone\x125\n" +
+	"\aaddress\x18\v \x01(\v2\x1b.travelingman.PostalAddressR\aaddress\"}\n" +
+	"\rPostalAddress\x12\x14\n" +
+	"\x05lines\x18\x01 \x03(\tR\x05lines\x12\x1f\n" +
+	"\vpostal_code\x18\x02 \x01(\tR\n" +
+	"postalCode\x12\x12\n" +
+	"\x04city\x18\x03 \x01(\tR\x04city\x12!\n" +
+	"\fcountry_code\x18\x04 \x01(\tR\vcountryCode\"\xe2\x02\n" +
 	"\bPassport\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x17\n" +
 	"\auser_id\x18\x02 \x01(\x03R\x06userId\x12\x16\n" +
@@ -473,31 +557,33 @@ func file_protos_users_proto_rawDescGZIP() []byte {
 	return file_protos_users_proto_rawDescData
 }
 
-var file_protos_users_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_protos_users_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
 var file_protos_users_proto_goTypes = []any{
 	(*User)(nil),                  // 0: travelingman.User
-	(*Passport)(nil),              // 1: travelingman.Passport
-	(*DriversLicense)(nil),        // 2: travelingman.DriversLicense
-	(*TravelGroup)(nil),           // 3: travelingman.TravelGroup
-	(*timestamppb.Timestamp)(nil), // 4: google.protobuf.Timestamp
-	(*Itinerary)(nil),             // 5: travelingman.Itinerary
+	(*PostalAddress)(nil),         // 1: travelingman.PostalAddress
+	(*Passport)(nil),              // 2: travelingman.Passport
+	(*DriversLicense)(nil),        // 3: travelingman.DriversLicense
+	(*TravelGroup)(nil),           // 4: travelingman.TravelGroup
+	(*timestamppb.Timestamp)(nil), // 5: google.protobuf.Timestamp
+	(*Itinerary)(nil),             // 6: travelingman.Itinerary
 }
 var file_protos_users_proto_depIdxs = []int32{
-	4,  // 0: travelingman.User.created_at:type_name -> google.protobuf.Timestamp
-	1,  // 1: travelingman.User.passports:type_name -> travelingman.Passport
-	2,  // 2: travelingman.User.drivers_licenses:type_name -> travelingman.DriversLicense
-	4,  // 3: travelingman.User.date_of_birth:type_name -> google.protobuf.Timestamp
-	4,  // 4: travelingman.Passport.expiry_date:type_name -> google.protobuf.Timestamp
-	4,  // 5: travelingman.Passport.issuance_date:type_name -> google.protobuf.Timestamp
-	4,  // 6: travelingman.DriversLicense.expiry_date:type_name -> google.protobuf.Timestamp
-	4,  // 7: travelingman.TravelGroup.travel_date:type_name -> google.protobuf.Timestamp
-	0,  // 8: travelingman.TravelGroup.members:type_name -> travelingman.User
-	5,  // 9: travelingman.TravelGroup.itinerary:type_name -> travelingman.Itinerary
-	10, // [10:10] is the sub-list for method output_type
-	10, // [10:10] is the sub-list for method input_type
-	10, // [10:10] is the sub-list for extension type_name
-	10, // [10:10] is the sub-list for extension extendee
-	0,  // [0:10] is the sub-list for field type_name
+	5,  // 0: travelingman.User.created_at:type_name -> google.protobuf.Timestamp
+	2,  // 1: travelingman.User.passports:type_name -> travelingman.Passport
+	3,  // 2: travelingman.User.drivers_licenses:type_name -> travelingman.DriversLicense
+	5,  // 3: travelingman.User.date_of_birth:type_name -> google.protobuf.Timestamp
+	1,  // 4: travelingman.User.address:type_name -> travelingman.PostalAddress
+	5,  // 5: travelingman.Passport.expiry_date:type_name -> google.protobuf.Timestamp
+	5,  // 6: travelingman.Passport.issuance_date:type_name -> google.protobuf.Timestamp
+	5,  // 7: travelingman.DriversLicense.expiry_date:type_name -> google.protobuf.Timestamp
+	5,  // 8: travelingman.TravelGroup.travel_date:type_name -> google.protobuf.Timestamp
+	0,  // 9: travelingman.TravelGroup.members:type_name -> travelingman.User
+	6,  // 10: travelingman.TravelGroup.itinerary:type_name -> travelingman.Itinerary
+	11, // [11:11] is the sub-list for method output_type
+	11, // [11:11] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
 }
 
 func init() { file_protos_users_proto_init() }
@@ -512,7 +598,7 @@ func file_protos_users_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_protos_users_proto_rawDesc), len(file_protos_users_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   5,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -0,0 +1,78 @@
+package amadeus
+
+import (
+	"strings"
+)
+
+// HotelAmenities are the amenity codes the hotel list endpoints filter on. Any other
+// code is not an error to Amadeus, it just matches no hotel.
+var HotelAmenities = []string{
+	"SWIMMING_POOL", "SPA", "FITNESS_CENTER", "AIR_CONDITIONING", "RESTAURANT", "PARKING",
+	"PETS_ALLOWED", "AIRPORT_SHUTTLE", "BUSINESS_CENTER", "DISABLED_FACILITIES", "WIFI",
+	"MEETING_ROOMS", "NO_KID_ALLOWED", "TENNIS", "GOLF", "KITCHEN", "ANIMAL_WATCHING",
+	"BABY-SITTING", "BEACH", "CASINO", "JACUZZI", "SAUNA", "SOLARIUM", "MASSAGE",
+	"VALET_PARKING", "BAR or LOUNGE", "KIDS_WELCOME", "NO_PORN_FILMS", "MINIBAR",
+	"TELEVISION", "WI-FI_IN_ROOM", "ROOM_SERVICE", "GUARDED_PARKG", "SERV_SPEC_MENU",
+}
+
+// amenityAliases maps common free-form names, as keyed by amenityKey, to their codes
+var amenityAliases = map[string]string{
+	"FREE_WIFI":             "WIFI",
+	"WI_FI":                 "WIFI",
+	"INTERNET":              "WIFI",
+	"POOL":                  "SWIMMING_POOL",
+	"GYM":                   "FITNESS_CENTER",
+	"FITNESS":               "FITNESS_CENTER",
+	"AC":                    "AIR_CONDITIONING",
+	"PETS":                  "PETS_ALLOWED",
+	"PET_FRIENDLY":          "PETS_ALLOWED",
+	"SHUTTLE":               "AIRPORT_SHUTTLE",
+	"ACCESSIBLE":            "DISABLED_FACILITIES",
+	"WHEELCHAIR_ACCESSIBLE": "DISABLED_FACILITIES",
+	"BABYSITTING":           "BABY-SITTING",
+	"HOT_TUB":               "JACUZZI",
+	"BAR":                   "BAR or LOUNGE",
+	"LOUNGE":                "BAR or LOUNGE",
+	"TV":                    "TELEVISION",
+	"FAMILY_FRIENDLY":       "KIDS_WELCOME",
+	"ADULTS_ONLY":           "NO_KID_ALLOWED",
+}
+
+// amenityCodes maps every code and alias, as keyed by amenityKey, to its code
+var amenityCodes = func() map[string]string {
+	codes := make(map[string]string, len(HotelAmenities)+len(amenityAliases))
+	for _, code := range HotelAmenities {
+		codes[amenityKey(code)] = code
+	}
+	for alias, code := range amenityAliases {
+		codes[alias] = code
+	}
+	return codes
+}()
+
+// amenityKey folds case and separators, so "free wifi", "Free-WiFi" and "FREE_WIFI"
+// look the same
+func amenityKey(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToUpper(s), func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_'
+	}), "_")
+}
+
+// NormalizeAmenities maps free-form amenities to Amadeus codes, e.g. "free_wifi" to
+// "WIFI". Codes are returned once each in the order first asked for; amenities with
+// no code are returned as given in unknown.
+func NormalizeAmenities(amenities []string) (codes []string, unknown []string) {
+	seen := make(map[string]bool, len(amenities))
+	for _, a := range amenities {
+		code, ok := amenityCodes[amenityKey(a)]
+		if !ok {
+			unknown = append(unknown, a)
+			continue
+		}
+		if !seen[code] {
+			seen[code] = true
+			codes = append(codes, code)
+		}
+	}
+	return codes, unknown
+}
@@ -0,0 +1,24 @@
+package amadeus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/pb"
+)
+
+func TestNormalizeAmenities(t *testing.T) {
+	codes, unknown := NormalizeAmenities([]string{"free_wifi", "Swimming Pool", "WIFI", "bar", "flux capacitor"})
+	assert.Equal(t, []string{"WIFI", "SWIMMING_POOL", "BAR or LOUNGE"}, codes)
+	assert.Equal(t, []string{"flux capacitor"}, unknown)
+}
+
+func TestHotelListFilters_NormalizesAmenities(t *testing.T) {
+	prefs := &pb.AccommodationPreferences{Rating: 4, Amenities: []string{"free_wifi", "flux capacitor", "bar"}}
+	assert.Equal(t, "&ratings=4&amenities=WIFI,BAR+or+LOUNGE", hotelListFilters(context.Background(), prefs))
+
+	// A filter of only unknown amenities is dropped rather than matching nothing
+	prefs = &pb.AccommodationPreferences{Amenities: []string{"flux capacitor"}}
+	assert.Empty(t, hotelListFilters(context.Background(), prefs))
+}
@@ -1,54 +1,153 @@
 package amadeus
 
 import (
+	"container/list"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	tmcontext "github.com/va6996/travelingman/context"
 )
 
-// SimpleCache is a basic thread-safe in-memory cache
+// SimpleCache is a basic thread-safe in-memory cache. Once it holds MaxEntries, the
+// least recently used entry makes room for a new one.
 type SimpleCache struct {
-	data map[string]cacheItem
-	mu   sync.RWMutex
+	// MaxEntries caps the number of entries; zero or less keeps them all
+	MaxEntries int
+
+	// order lists the entries most recently used first; data finds them by key
+	order *list.List
+	data  map[string]*list.Element
+	mu    sync.RWMutex
+
+	hits, misses, evictions, displaced atomic.Int64
+
+	// Clock decides when entries expire; nil uses the wall clock. Test mode fixes it
+	// so that cached results never expire mid-run.
+	Clock tmcontext.Clock
+}
+
+// CacheStats is a snapshot of a cache's size and counters, for tuning TTLs
+type CacheStats struct {
+	Size       int   `json:"size"`
+	MaxEntries int   `json:"max_entries"` // Zero when unbounded
+	Hits       int64 `json:"hits"`
+	Misses     int64 `json:"misses"`
+	Evictions  int64 `json:"evictions"` // Expired entries removed on lookup
+	Displaced  int64 `json:"displaced"` // Least recently used entries removed to make room
 }
 
 type cacheItem struct {
+	key        string
 	value      interface{}
 	expiryTime time.Time
 }
 
-// NewSimpleCache creates a new cache instance
-func NewSimpleCache() *SimpleCache {
+// NewSimpleCache creates a new cache instance holding at most maxEntries entries;
+// zero or less keeps them all
+func NewSimpleCache(maxEntries int) *SimpleCache {
 	return &SimpleCache{
-		data: make(map[string]cacheItem),
+		MaxEntries: maxEntries,
+		order:      list.New(),
+		data:       make(map[string]*list.Element),
 	}
 }
 
-// Get retrieves a value from the cache
+// Get retrieves a value from the cache and marks it as the most recently used. An
+// expired entry is removed and counts as a miss.
 func (c *SimpleCache) Get(key string) (interface{}, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	item, found := c.data[key]
+	elem, found := c.data[key]
 	if !found {
+		c.misses.Add(1)
 		return nil, false
 	}
 
-	if time.Now().After(item.expiryTime) {
+	item := elem.Value.(*cacheItem)
+	if c.now().After(item.expiryTime) {
+		c.remove(elem)
+		c.evictions.Add(1)
+		c.misses.Add(1)
 		return nil, false
 	}
 
+	c.order.MoveToFront(elem)
+	c.hits.Add(1)
 	return item.value, true
 }
 
-// Set adds a value to the cache with a TTL
+// Set adds a value to the cache with a TTL, making room for it if the cache is full
 func (c *SimpleCache) Set(key string, value interface{}, ttl time.Duration) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.data[key] = cacheItem{
-		value:      value,
-		expiryTime: time.Now().Add(ttl),
+	item := &cacheItem{key: key, value: value, expiryTime: c.now().Add(ttl)}
+	if elem, ok := c.data[key]; ok {
+		elem.Value = item
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	if c.MaxEntries > 0 {
+		for c.order.Len() >= c.MaxEntries {
+			c.remove(c.order.Back())
+			c.displaced.Add(1)
+		}
+	}
+	c.data[key] = c.order.PushFront(item)
+}
+
+// remove drops an entry; the caller holds the write lock
+func (c *SimpleCache) remove(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.data, elem.Value.(*cacheItem).key)
+}
+
+// Len returns the number of entries, expired ones included until they are looked up
+func (c *SimpleCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.order.Len()
+}
+
+// Keys returns the keys of the entries, most recently used first
+func (c *SimpleCache) Keys() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	keys := make([]string, 0, c.order.Len())
+	for elem := c.order.Front(); elem != nil; elem = elem.Next() {
+		keys = append(keys, elem.Value.(*cacheItem).key)
+	}
+	return keys
+}
+
+// Flush removes every entry; the counters are kept
+func (c *SimpleCache) Flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	c.data = make(map[string]*list.Element)
+}
+
+func (c *SimpleCache) now() time.Time {
+	if c.Clock != nil {
+		return c.Clock()
+	}
+	return time.Now()
+}
+
+// Stats returns the cache's current size and counters
+func (c *SimpleCache) Stats() CacheStats {
+	return CacheStats{
+		Size:       c.Len(),
+		MaxEntries: max(c.MaxEntries, 0),
+		Hits:       c.hits.Load(),
+		Misses:     c.misses.Load(),
+		Evictions:  c.evictions.Load(),
+		Displaced:  c.displaced.Load(),
 	}
 }
 
@@ -0,0 +1,84 @@
+package amadeus
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimpleCache_Stats(t *testing.T) {
+	c := NewSimpleCache(0)
+	assert.Equal(t, CacheStats{}, c.Stats())
+
+	c.Set("flight", "JFK-LHR", time.Hour)
+	_, ok := c.Get("flight")
+	assert.True(t, ok)
+	_, ok = c.Get("hotel")
+	assert.False(t, ok)
+	assert.Equal(t, CacheStats{Size: 1, Hits: 1, Misses: 1}, c.Stats())
+
+	// An expired entry is a miss and is evicted
+	c.Set("location", "NYC", -time.Second)
+	_, ok = c.Get("location")
+	assert.False(t, ok)
+	assert.Equal(t, CacheStats{Size: 1, Hits: 1, Misses: 2, Evictions: 1}, c.Stats())
+}
+
+func TestSimpleCache_LRU(t *testing.T) {
+	c := NewSimpleCache(3)
+	c.Set("JFK-LHR", 1, time.Hour)
+	c.Set("LHR-CDG", 2, time.Hour)
+	c.Set("CDG-FCO", 3, time.Hour)
+	assert.Equal(t, []string{"CDG-FCO", "LHR-CDG", "JFK-LHR"}, c.Keys())
+
+	// A full cache drops the least recently used entry
+	c.Set("FCO-ATH", 4, time.Hour)
+	assert.Equal(t, []string{"FCO-ATH", "CDG-FCO", "LHR-CDG"}, c.Keys())
+	_, ok := c.Get("JFK-LHR")
+	assert.False(t, ok)
+
+	// Looking an entry up, or setting it again, keeps it longer
+	_, ok = c.Get("LHR-CDG")
+	assert.True(t, ok)
+	c.Set("CDG-FCO", 30, time.Hour)
+	c.Set("ATH-IST", 5, time.Hour)
+	assert.Equal(t, []string{"ATH-IST", "CDG-FCO", "LHR-CDG"}, c.Keys())
+	v, _ := c.Get("CDG-FCO")
+	assert.Equal(t, 30, v)
+
+	stats := c.Stats()
+	assert.Equal(t, 3, stats.Size)
+	assert.Equal(t, 3, stats.MaxEntries)
+	assert.Equal(t, int64(2), stats.Displaced)
+
+	c.Flush()
+	assert.Equal(t, 0, c.Len())
+	assert.Empty(t, c.Keys())
+	_, ok = c.Get("ATH-IST")
+	assert.False(t, ok)
+}
+
+func TestSimpleCache_Concurrent(t *testing.T) {
+	c := NewSimpleCache(50)
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := range 200 {
+				key := fmt.Sprintf("route-%d", (g*200+i)%120)
+				c.Set(key, i, time.Hour)
+				c.Get(key)
+				c.Len()
+				c.Keys()
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 50, c.Len())
+	assert.Len(t, c.Keys(), 50)
+}
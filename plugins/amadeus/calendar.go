@@ -0,0 +1,314 @@
+package amadeus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	tmcontext "github.com/va6996/travelingman/context"
+	"github.com/va6996/travelingman/log"
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// PriceCalendarSourceFlightDates marks calendars built from the flight-dates (cheapest date) API
+	PriceCalendarSourceFlightDates = "FLIGHT_DATES"
+	// PriceCalendarSourceSampled marks calendars built from sampled flight-offers searches
+	PriceCalendarSourceSampled = "SAMPLED"
+
+	// calendarSampleStep is the spacing in days between real searches when flight-dates is unavailable
+	calendarSampleStep = 3
+)
+
+// --- Structs for Flight Cheapest Date Search ---
+
+type FlightDatesResponse struct {
+	Data []FlightDate `json:"data"`
+	Meta struct {
+		Currency string `json:"currency"`
+	} `json:"meta"`
+}
+
+type FlightDate struct {
+	Type          string `json:"type"`
+	Origin        string `json:"origin"`
+	Destination   string `json:"destination"`
+	DepartureDate string `json:"departureDate"`
+	ReturnDate    string `json:"returnDate,omitempty"`
+	Price         struct {
+		Total string `json:"total"`
+	} `json:"price"`
+}
+
+// dayQuote is an intermediate per-day price before the calendar is assembled
+type dayQuote struct {
+	value        float64
+	currency     string
+	interpolated bool
+}
+
+// GetPriceCalendar returns indicative lowest one-way fares for every day of the month
+// between origin and destination (IATA codes). Prices are for all travelers.
+// The flight-dates API is used when available; otherwise real searches are run on
+// every third day and the days in between are interpolated (and flagged as such).
+func (c *Client) GetPriceCalendar(ctx context.Context, origin, destination string, month time.Time, adults int, currency string) (*pb.PriceCalendar, error) {
+	if origin == "" || destination == "" {
+		return nil, fmt.Errorf("origin and destination are required")
+	}
+	if adults <= 0 {
+		adults = 1
+	}
+	currency = currencyOrDefault(currency, "USD")
+
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, -1)
+
+	// Check cache
+	cacheKey := GenerateCacheKey("calendar", origin, destination, start.Format("2006-01"), adults, currency)
+	if val, ok := c.Cache.Get(cacheKey); ok {
+		log.Debugf(ctx, "GetPriceCalendar: Cache hit for %s-%s %s", origin, destination, start.Format("2006-01"))
+		tmcontext.RequestStatsFromContext(ctx).AddCacheLookup("calendar", true)
+		// Callers may change what they get, so the cached calendar is not handed out
+		return proto.Clone(val.(*pb.PriceCalendar)).(*pb.PriceCalendar), nil
+	}
+	tmcontext.RequestStatsFromContext(ctx).AddCacheLookup("calendar", false)
+
+	source := PriceCalendarSourceFlightDates
+	quotes, err := c.searchFlightDates(ctx, origin, destination, start, end, adults)
+	if err != nil || len(quotes) == 0 {
+		log.Warnf(ctx, "GetPriceCalendar: flight-dates unavailable for %s-%s (%v), falling back to sampled searches", origin, destination, err)
+		source = PriceCalendarSourceSampled
+		quotes, err = c.samplePrices(ctx, origin, destination, start, end, adults, currency)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cal := buildPriceCalendar(quotes, start, end)
+	cal.Origin = origin
+	cal.Destination = destination
+	cal.Month = start.Format("2006-01")
+	cal.TravelerCount = int32(adults)
+	cal.Source = source
+
+	// Set cache
+	hours := c.Config.CacheTTL.Calendar
+	if hours <= 0 {
+		hours = c.Config.CacheTTL.Flight
+	}
+	c.Cache.Set(cacheKey, proto.Clone(cal), time.Duration(hours)*time.Hour)
+
+	return cal, nil
+}
+
+// searchFlightDates queries the cheapest-date search API for the given date range.
+// The API prices a single adult, so values are scaled by adults.
+func (c *Client) searchFlightDates(ctx context.Context, origin, destination string, start, end time.Time, adults int) (map[string]dayQuote, error) {
+	data := url.Values{}
+	data.Set("origin", origin)
+	data.Set("destination", destination)
+	data.Set("departureDate", start.Format("2006-01-02")+","+end.Format("2006-01-02"))
+	data.Set("oneWay", "true")
+	data.Set("viewBy", "DATE")
+
+	endpoint := fmt.Sprintf("/v1/shopping/flight-dates?%s", data.Encode())
+	log.Debugf(ctx, "searchFlightDates: Requesting %s", endpoint)
+
+	resp, err := c.doRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("flight dates search failed: %s", resp.Status)
+	}
+
+	var result FlightDatesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	quotes := make(map[string]dayQuote)
+	for _, d := range result.Data {
+		day, err := time.Parse("2006-01-02", d.DepartureDate)
+		if err != nil || day.Before(start) || day.After(end) {
+			continue
+		}
+		price, err := strconv.ParseFloat(d.Price.Total, 64)
+		if err != nil {
+			continue
+		}
+		key := day.Format("2006-01-02")
+		if q, ok := quotes[key]; ok && q.value <= price*float64(adults) {
+			continue
+		}
+		quotes[key] = dayQuote{value: price * float64(adults), currency: result.Meta.Currency}
+	}
+	return quotes, nil
+}
+
+// samplePrices runs real flight searches on every calendarSampleStep-th day (plus the
+// last day of the month) and linearly interpolates the days in between.
+// Days in the past, by the context's clock, are skipped.
+func (c *Client) samplePrices(ctx context.Context, origin, destination string, start, end time.Time, adults int, currency string) (map[string]dayQuote, error) {
+	today := tmcontext.Now(ctx).UTC().Truncate(24 * time.Hour)
+	first := start
+	if first.Before(today) {
+		first = today
+	}
+	if first.After(end) {
+		return nil, fmt.Errorf("month %s is in the past", start.Format("2006-01"))
+	}
+
+	var sampleDays []time.Time
+	for d := first; !d.After(end); d = d.AddDate(0, 0, calendarSampleStep) {
+		sampleDays = append(sampleDays, d)
+	}
+	if !sampleDays[len(sampleDays)-1].Equal(end) {
+		sampleDays = append(sampleDays, end)
+	}
+
+	quotes := make(map[string]dayQuote)
+	for _, d := range sampleDays {
+		transports, err := c.SearchFlights(ctx, &pb.Transport{
+			Type:                pb.TransportType_TRANSPORT_TYPE_FLIGHT,
+			TravelerCount:       int32(adults),
+			OriginLocation:      &pb.Location{IataCodes: []string{origin}},
+			DestinationLocation: &pb.Location{IataCodes: []string{destination}},
+			Cost:                &pb.Cost{Currency: currency},
+			Details: &pb.Transport_Flight{
+				Flight: &pb.Flight{DepartureTime: timestamppb.New(d)},
+			},
+		})
+		if err != nil {
+			log.Warnf(ctx, "samplePrices: search for %s failed: %v", d.Format("2006-01-02"), err)
+			continue
+		}
+		for _, t := range transports {
+			if t.Cost == nil || t.Cost.Value <= 0 {
+				continue
+			}
+			key := d.Format("2006-01-02")
+			if q, ok := quotes[key]; !ok || t.Cost.Value < q.value {
+				quotes[key] = dayQuote{value: t.Cost.Value, currency: currencyOrDefault(t.Cost.Currency, currency)}
+			}
+		}
+	}
+
+	if len(quotes) == 0 {
+		return nil, fmt.Errorf("no flight offers found for %s-%s in %s", origin, destination, start.Format("2006-01"))
+	}
+
+	interpolate(quotes, first, end)
+	return quotes, nil
+}
+
+// interpolate fills every day in [first, end] that has no quote. Days between two
+// sampled days are linearly interpolated; days outside the sampled range take the
+// nearest sampled value. All filled days are flagged as interpolated.
+func interpolate(quotes map[string]dayQuote, first, end time.Time) {
+	var known []time.Time
+	for d := first; !d.After(end); d = d.AddDate(0, 0, 1) {
+		if _, ok := quotes[d.Format("2006-01-02")]; ok {
+			known = append(known, d)
+		}
+	}
+	if len(known) == 0 {
+		return
+	}
+
+	for d := first; !d.After(end); d = d.AddDate(0, 0, 1) {
+		key := d.Format("2006-01-02")
+		if _, ok := quotes[key]; ok {
+			continue
+		}
+
+		// Find neighbouring sampled days
+		idx := sort.Search(len(known), func(i int) bool { return known[i].After(d) })
+		var q dayQuote
+		switch {
+		case idx == 0:
+			q = quotes[known[0].Format("2006-01-02")]
+		case idx == len(known):
+			q = quotes[known[len(known)-1].Format("2006-01-02")]
+		default:
+			prev, next := known[idx-1], known[idx]
+			pq, nq := quotes[prev.Format("2006-01-02")], quotes[next.Format("2006-01-02")]
+			frac := d.Sub(prev).Hours() / next.Sub(prev).Hours()
+			q = dayQuote{value: pq.value + (nq.value-pq.value)*frac, currency: pq.currency}
+		}
+		q.interpolated = true
+		quotes[key] = q
+	}
+}
+
+// buildPriceCalendar turns per-day quotes into a sorted calendar and marks the
+// cheapest day and the day closest to the median price.
+// Real (non-interpolated) prices win ties for both markers.
+func buildPriceCalendar(quotes map[string]dayQuote, start, end time.Time) *pb.PriceCalendar {
+	cal := &pb.PriceCalendar{}
+	var values []float64
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		q, ok := quotes[d.Format("2006-01-02")]
+		if !ok {
+			continue
+		}
+		value := float64(int64(q.value*100+0.5)) / 100 // Round to cents
+		cal.Days = append(cal.Days, &pb.DayPrice{
+			Date:         timestamppb.New(d),
+			Cost:         &pb.Cost{Value: value, Currency: q.currency},
+			Interpolated: q.interpolated,
+		})
+		values = append(values, value)
+	}
+	if len(cal.Days) == 0 {
+		return cal
+	}
+
+	sort.Float64s(values)
+	median := values[len(values)/2]
+	if len(values)%2 == 0 {
+		median = (values[len(values)/2-1] + values[len(values)/2]) / 2
+	}
+
+	better := func(candidate, current *pb.DayPrice, dist func(*pb.DayPrice) float64) bool {
+		if current == nil {
+			return true
+		}
+		if dist(candidate) != dist(current) {
+			return dist(candidate) < dist(current)
+		}
+		return current.Interpolated && !candidate.Interpolated
+	}
+
+	var minDay, medianDay *pb.DayPrice
+	for _, day := range cal.Days {
+		if better(day, minDay, func(d *pb.DayPrice) float64 { return d.Cost.Value }) {
+			minDay = day
+		}
+		if better(day, medianDay, func(d *pb.DayPrice) float64 {
+			diff := d.Cost.Value - median
+			if diff < 0 {
+				return -diff
+			}
+			return diff
+		}) {
+			medianDay = day
+		}
+	}
+
+	minDay.IsMin = true
+	medianDay.IsMedian = true
+	currency := cal.Days[0].Cost.Currency
+	cal.MinPrice = &pb.Cost{Value: minDay.Cost.Value, Currency: currency}
+	cal.MedianPrice = &pb.Cost{Value: float64(int64(median*100+0.5)) / 100, Currency: currency}
+	return cal
+}
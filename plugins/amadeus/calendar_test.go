@@ -0,0 +1,215 @@
+package amadeus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	tmcontext "github.com/va6996/travelingman/context"
+	"google.golang.org/protobuf/proto"
+)
+
+func newCalendarTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+
+	client, err := NewClient(Config{
+		ClientID: "id", ClientSecret: "secret", IsProduction: false,
+		FlightLimit: 10, HotelLimit: 10, Timeout: 10,
+		CacheTTL: CacheTTLConfig{Location: 24, Flight: 24, Hotel: 24},
+	}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	client.BaseURL = ts.URL
+	return client
+}
+
+func writeToken(w http.ResponseWriter) {
+	json.NewEncoder(w).Encode(AuthToken{AccessToken: "test_token", ExpiresIn: 1800, TokenType: "Bearer"})
+}
+
+func TestGetPriceCalendar_FlightDates(t *testing.T) {
+	var flightDatesCalls int32
+	client := newCalendarTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/security/oauth2/token":
+			writeToken(w)
+		case "/v1/shopping/flight-dates":
+			atomic.AddInt32(&flightDatesCalls, 1)
+			assert.Equal(t, "MAD", r.URL.Query().Get("origin"))
+			assert.Equal(t, "MUC", r.URL.Query().Get("destination"))
+			assert.Equal(t, "2027-03-01,2027-03-31", r.URL.Query().Get("departureDate"))
+			w.Write([]byte(`{
+				"data": [
+					{"type": "flight-date", "origin": "MAD", "destination": "MUC", "departureDate": "2027-03-02", "price": {"total": "120.00"}},
+					{"type": "flight-date", "origin": "MAD", "destination": "MUC", "departureDate": "2027-03-05", "price": {"total": "80.50"}},
+					{"type": "flight-date", "origin": "MAD", "destination": "MUC", "departureDate": "2027-03-09", "price": {"total": "100.00"}},
+					{"type": "flight-date", "origin": "MAD", "destination": "MUC", "departureDate": "2027-04-01", "price": {"total": "10.00"}}
+				],
+				"meta": {"currency": "EUR"}
+			}`))
+		case "/v2/shopping/flight-offers":
+			t.Errorf("flight-offers should not be called when flight-dates succeeds")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	month := time.Date(2027, 3, 15, 0, 0, 0, 0, time.UTC)
+	cal, err := client.GetPriceCalendar(context.Background(), "MAD", "MUC", month, 2, "EUR")
+	if err != nil {
+		t.Fatalf("GetPriceCalendar failed: %v", err)
+	}
+
+	assert.Equal(t, PriceCalendarSourceFlightDates, cal.Source)
+	assert.Equal(t, "2027-03", cal.Month)
+	assert.Equal(t, int32(2), cal.TravelerCount)
+	if !assert.Len(t, cal.Days, 3, "out-of-month dates are dropped") {
+		return
+	}
+
+	// Prices are per adult in the API and scaled to all travelers
+	assert.Equal(t, 240.0, cal.Days[0].Cost.Value)
+	assert.Equal(t, 161.0, cal.Days[1].Cost.Value)
+	assert.Equal(t, "EUR", cal.Days[1].Cost.Currency)
+	for _, d := range cal.Days {
+		assert.False(t, d.Interpolated)
+	}
+
+	assert.True(t, cal.Days[1].IsMin)
+	assert.True(t, cal.Days[2].IsMedian)
+	assert.Equal(t, 161.0, cal.MinPrice.Value)
+	assert.Equal(t, 200.0, cal.MedianPrice.Value)
+
+	// Repeated calls for the same month are served from the cache
+	again, err := client.GetPriceCalendar(context.Background(), "MAD", "MUC", month, 2, "EUR")
+	assert.NoError(t, err)
+	assert.True(t, proto.Equal(cal, again))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&flightDatesCalls))
+
+	// Changing a calendar does not change the cached one
+	again.Days = nil
+	cal.MinPrice.Value = 0
+	cached, err := client.GetPriceCalendar(context.Background(), "MAD", "MUC", month, 2, "EUR")
+	assert.NoError(t, err)
+	assert.Len(t, cached.Days, 3)
+	assert.Equal(t, 161.0, cached.MinPrice.Value)
+}
+
+func TestGetPriceCalendar_SampledFallback(t *testing.T) {
+	var flightDatesCalls, offerCalls int32
+	month := time.Now().UTC().AddDate(0, 2, 0)
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, -1)
+
+	// Price rises by 10 per day from the first of the month
+	priceFor := func(date string) float64 {
+		d, _ := time.Parse("2006-01-02", date)
+		return 100 + 10*d.Sub(start).Hours()/24
+	}
+
+	searched := make(map[string]bool)
+	client := newCalendarTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/security/oauth2/token":
+			writeToken(w)
+		case "/v1/shopping/flight-dates":
+			atomic.AddInt32(&flightDatesCalls, 1)
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/v2/shopping/flight-offers":
+			atomic.AddInt32(&offerCalls, 1)
+			date := r.URL.Query().Get("departureDate")
+			searched[date] = true
+			json.NewEncoder(w).Encode(FlightSearchResponse{
+				Data: []FlightOffer{
+					{ID: "1", Price: Price{Currency: "USD", Total: formatPrice(priceFor(date) + 50)}},
+					{ID: "2", Price: Price{Currency: "USD", Total: formatPrice(priceFor(date))}},
+				},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	cal, err := client.GetPriceCalendar(context.Background(), "JFK", "LHR", month, 1, "")
+	if err != nil {
+		t.Fatalf("GetPriceCalendar failed: %v", err)
+	}
+
+	assert.Equal(t, PriceCalendarSourceSampled, cal.Source)
+	if !assert.Len(t, cal.Days, end.Day()) {
+		return
+	}
+
+	// Only every third day (plus the last day) is actually searched
+	expectedSearches := (end.Day()-1)/calendarSampleStep + 1
+	if (end.Day()-1)%calendarSampleStep != 0 {
+		expectedSearches++
+	}
+	assert.Equal(t, int32(expectedSearches), atomic.LoadInt32(&offerCalls))
+
+	for i, d := range cal.Days {
+		date := d.Date.AsTime().Format("2006-01-02")
+		assert.Equal(t, !searched[date], d.Interpolated, "day %d interpolation flag", i+1)
+		// Linear prices interpolate exactly; the cheapest offer per day is used
+		assert.InDelta(t, priceFor(date), d.Cost.Value, 0.01, "day %d price", i+1)
+		assert.Equal(t, "USD", d.Cost.Currency)
+	}
+	assert.True(t, cal.Days[0].IsMin)
+	assert.False(t, cal.Days[0].Interpolated)
+
+	// Second call for the same month reuses the cached calendar
+	tried := atomic.LoadInt32(&flightDatesCalls)
+	_, err = client.GetPriceCalendar(context.Background(), "JFK", "LHR", month, 1, "")
+	assert.NoError(t, err)
+	assert.Equal(t, tried, atomic.LoadInt32(&flightDatesCalls))
+	assert.Equal(t, int32(expectedSearches), atomic.LoadInt32(&offerCalls))
+}
+
+func TestGetPriceCalendar_SampledUsesContextClock(t *testing.T) {
+	var searched []string
+	client := newCalendarTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/security/oauth2/token":
+			writeToken(w)
+		case "/v2/shopping/flight-offers":
+			searched = append(searched, r.URL.Query().Get("departureDate"))
+			json.NewEncoder(w).Encode(FlightSearchResponse{
+				Data: []FlightOffer{{ID: "1", Price: Price{Currency: "USD", Total: "120.00"}}},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	client.Config.CacheTTL.Calendar = 720
+
+	// March 2020 is long past by the wall clock, but the context says it is the 25th
+	today := time.Date(2020, 3, 25, 15, 0, 0, 0, time.UTC)
+	ctx := tmcontext.WithClock(context.Background(), tmcontext.FixedClock(today))
+	month := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	cal, err := client.GetPriceCalendar(ctx, "JFK", "LHR", month, 1, "")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, PriceCalendarSourceSampled, cal.Source)
+	assert.Equal(t, []string{"2020-03-25", "2020-03-28", "2020-03-31"}, searched)
+
+	// The calendar is kept for its own TTL rather than the flight one
+	item := client.Cache.data[GenerateCacheKey("calendar", "JFK", "LHR", "2020-03", 1, "USD")].Value.(*cacheItem)
+	assert.WithinDuration(t, time.Now().Add(720*time.Hour), item.expiryTime, time.Minute)
+}
+
+func formatPrice(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
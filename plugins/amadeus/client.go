@@ -4,13 +4,20 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math/rand"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/firebase/genkit/go/genkit"
+	tmcontext "github.com/va6996/travelingman/context"
 	"github.com/va6996/travelingman/log"
+	"github.com/va6996/travelingman/orm"
 	"github.com/va6996/travelingman/pb"
 	"github.com/va6996/travelingman/tools"
 	"gorm.io/gorm"
@@ -19,6 +26,10 @@ import (
 const (
 	BaseURLTest       = "https://test.api.amadeus.com"
 	BaseURLProduction = "https://api.amadeus.com"
+
+	// maxRetryWait bounds the wait before a retry, a server's Retry-After included, so
+	// that one rate-limited endpoint cannot stall a whole plan
+	maxRetryWait = 30 * time.Second
 )
 
 // Client is the main Amadeus API client
@@ -33,22 +44,60 @@ type Client struct {
 	HotelListTool   *HotelListTool
 	HotelOffersTool *HotelOffersTool
 	LocationTool    *LocationTool
+	CalendarTool    *PriceCalendarTool
+	FareTrendTool   *FareTrendTool
+	AmenitiesTool   *HotelAmenitiesTool
+
+	// Nearby finds cities to search for hotels when a stay's city has none. Nil
+	// disables the fallback.
+	Nearby NearbyCityFinder
+
+	// limitsMu guards Config.FlightLimit, Config.HotelLimit and Config.HotelListLimit, which
+	// can be changed at runtime
+	limitsMu sync.RWMutex
+
+	// tokenMu guards Token, so that concurrent requests share one refresh
+	tokenMu sync.Mutex
 }
 
 type Config struct {
-	ClientID     string
-	ClientSecret string
-	IsProduction bool
-	FlightLimit  int
-	HotelLimit   int
-	Timeout      int            // Seconds
-	CacheTTL     CacheTTLConfig // Hours
+	ClientID              string
+	ClientSecret          string
+	IsProduction          bool
+	FlightLimit           int
+	HotelLimit            int
+	HotelListLimit        int // Listed hotels considered when picking the HotelLimit to price; below HotelLimit counts as HotelLimit
+	HotelOffers           HotelOffersConfig
+	HotelRelaxation       HotelRelaxationConfig // Retries empty preference-filtered hotel searches; no steps disables it
+	HotelFallbackRadiusKm int                   // Nearby cities within this many km are searched when a stay's city has no hotels (0 disables)
+	Timeout               int                   // Seconds
+	UserAgent             string                // Sent with every request, if set
+	CacheTTL              CacheTTLConfig        // Hours
+	CacheMaxEntries       int                   // Entries kept in the in-memory cache, least recently used dropped first (0 for no limit)
+	Retry                 RetryConfig
+}
+
+// RetryConfig controls how calls that are rate limited or hit a server error are
+// retried. Only lookups are; bookings and cancellations are never sent twice.
+type RetryConfig struct {
+	MaxRetries  int // Retries after the first attempt (0 disables)
+	BaseDelayMs int // Wait before the first retry, doubled for each one after it
+}
+
+// HotelOffersConfig controls how many room/rate offers are requested per hotel
+type HotelOffersConfig struct {
+	BestRateOnly    bool     // Ask Amadeus for only the best rate per hotel
+	PerHotel        int      // Max offers kept per hotel (0 keeps all)
+	PreferredChains []string // Chain codes, e.g. MC or HH, whose hotels are listed first
 }
 
 type CacheTTLConfig struct {
 	Location int
 	Flight   int
 	Hotel    int
+	// Calendar is for price calendars, which only give indicative prices and cost a
+	// search per sampled day; zero uses Flight
+	Calendar int
 }
 
 // LocationSearchResponse wraps the API response for locations
@@ -94,7 +143,7 @@ func NewClient(cfg Config, gk *genkit.Genkit, registry *tools.Registry, db *gorm
 		Config:     cfg,
 		BaseURL:    baseURL,
 		HTTPClient: &http.Client{Timeout: time.Duration(cfg.Timeout) * time.Second},
-		Cache:      NewSimpleCache(),
+		Cache:      NewSimpleCache(cfg.CacheMaxEntries),
 		DB:         db,
 	}
 
@@ -104,6 +153,35 @@ func NewClient(cfg Config, gk *genkit.Genkit, registry *tools.Registry, db *gorm
 	return c, nil
 }
 
+// Limits returns the current maximum number of flight and hotel results
+func (c *Client) Limits() (flight, hotel int) {
+	c.limitsMu.RLock()
+	defer c.limitsMu.RUnlock()
+	return c.Config.FlightLimit, c.Config.HotelLimit
+}
+
+// SetLimits changes the result limits of a running client
+func (c *Client) SetLimits(flight, hotel int) {
+	c.limitsMu.Lock()
+	defer c.limitsMu.Unlock()
+	c.Config.FlightLimit = flight
+	c.Config.HotelLimit = hotel
+}
+
+// HotelListLimit returns how many hotels of a city list are considered for pricing
+func (c *Client) HotelListLimit() int {
+	c.limitsMu.RLock()
+	defer c.limitsMu.RUnlock()
+	return c.Config.HotelListLimit
+}
+
+// SetHotelListLimit changes how many listed hotels a running client considers
+func (c *Client) SetHotelListLimit(n int) {
+	c.limitsMu.Lock()
+	defer c.limitsMu.Unlock()
+	c.Config.HotelListLimit = n
+}
+
 // initTools registers all Amadeus tools
 func (c *Client) initTools(gk *genkit.Genkit, registry *tools.Registry) {
 	if gk == nil || registry == nil {
@@ -115,20 +193,46 @@ func (c *Client) initTools(gk *genkit.Genkit, registry *tools.Registry) {
 	c.FlightTool = NewFlightTool(c, gk, registry)
 	c.HotelListTool = NewHotelListTool(c, gk, registry)
 	c.HotelOffersTool = NewHotelOffersTool(c, gk, registry)
+	c.CalendarTool = NewPriceCalendarTool(c, gk, registry)
+	c.FareTrendTool = NewFareTrendTool(c, gk, registry)
+	c.AmenitiesTool = NewHotelAmenitiesTool(gk, registry)
+}
+
+// Authenticate requests an access token, which is kept until shortly before it expires
+func (c *Client) Authenticate(ctx context.Context) error {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	return c.authenticate(ctx)
+}
+
+// accessToken returns a valid access token, authenticating first if there is none or it
+// has expired. The first caller to find it stale fetches a new one; the others wait for
+// it rather than fetching their own.
+func (c *Client) accessToken(ctx context.Context) (string, error) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	if c.Token == nil || time.Now().After(c.Token.Expiry) {
+		if err := c.authenticate(ctx); err != nil {
+			return "", err
+		}
+	}
+	return c.Token.AccessToken, nil
 }
-func (c *Client) Authenticate() error {
+
+// authenticate fetches and stores a new token; the caller holds tokenMu
+func (c *Client) authenticate(ctx context.Context) error {
 	data := url.Values{}
 	data.Set("grant_type", "client_credentials")
-	data.Set("grant_type", "client_credentials")
 	data.Set("client_id", c.Config.ClientID)
 	data.Set("client_secret", c.Config.ClientSecret)
 
-	req, err := http.NewRequest("POST", c.BaseURL+"/v1/security/oauth2/token", bytes.NewBufferString(data.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", c.BaseURL+"/v1/security/oauth2/token", bytes.NewBufferString(data.Encode()))
 	if err != nil {
 		return err
 	}
 
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c.setUserAgent(req)
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
@@ -152,16 +256,21 @@ func (c *Client) Authenticate() error {
 	return nil
 }
 
+// setUserAgent identifies us to Amadeus, if a User-Agent is configured
+func (c *Client) setUserAgent(req *http.Request) {
+	if c.Config.UserAgent != "" {
+		req.Header.Set("User-Agent", c.Config.UserAgent)
+	}
+}
+
 // doRequest performs an authenticated HTTP request
 func (c *Client) doRequest(ctx context.Context, method, endpoint string, body interface{}) (*http.Response, error) {
-	if c.Token == nil || time.Now().After(c.Token.Expiry) {
-		if err := c.Authenticate(); err != nil {
-			return nil, fmt.Errorf("failed to refresh token: %w", err)
-		}
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh token: %w", err)
 	}
 
 	var reqBody []byte
-	var err error
 	if body != nil {
 		reqBody, err = json.Marshal(body)
 		if err != nil {
@@ -170,33 +279,137 @@ func (c *Client) doRequest(ctx context.Context, method, endpoint string, body in
 	}
 
 	url := c.BaseURL + endpoint
-	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(reqBody))
+	path, _, _ := strings.Cut(endpoint, "?")
+	maxAttempts := c.Config.Retry.MaxRetries + 1
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(reqBody))
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		c.setUserAgent(req)
+
+		// Every attempt counts against the request's call ceiling
+		if !tmcontext.TakeCall(ctx) {
+			log.Warnf(ctx, "Amadeus: call limit for this request reached, skipping %s %s", method, endpoint)
+			return nil, tmcontext.ErrCallBudgetExhausted
+		}
+
+		resp, err := c.HTTPClient.Do(req)
+		tmcontext.RequestStatsFromContext(ctx).AddProviderRequest("amadeus"+path, err != nil || resp.StatusCode >= 400)
+		if !isTransient(resp, err) || !retryable(method, path) || attempt >= maxAttempts || ctx.Err() != nil {
+			if err != nil {
+				log.Errorf(ctx, "Amadeus API request failed: %v", err)
+			}
+			return resp, err
+		}
+
+		// Retries are drawn from the request's shared budget
+		if !tmcontext.TakeRetry(ctx) {
+			log.Warnf(ctx, "Amadeus: retry budget exhausted, not retrying %s %s", method, endpoint)
+			if err != nil {
+				log.Errorf(ctx, "Amadeus API request failed: %v", err)
+			}
+			return resp, err
+		}
+
+		wait := c.retryDelay(attempt, resp)
+		if err != nil {
+			log.Warnf(ctx, "Amadeus: %s %s failed (attempt %d/%d): %v. Retrying in %s...", method, endpoint, attempt, maxAttempts, err, wait)
+		} else {
+			log.Warnf(ctx, "Amadeus: %s %s returned %s (attempt %d/%d). Retrying in %s...", method, endpoint, resp.Status, attempt, maxAttempts, wait)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// isTransient reports whether a request failed in a way worth retrying:
+// a transport error, rate limiting or a server error
+func isTransient(resp *http.Response, err error) bool {
 	if err != nil {
-		return nil, err
+		return true
 	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
 
-	req.Header.Set("Authorization", "Bearer "+c.Token.AccessToken)
-	req.Header.Set("Content-Type", "application/json")
+// readOnlyPosts are POST endpoints that only look things up, so sending them again
+// cannot change anything at Amadeus
+var readOnlyPosts = map[string]bool{
+	"/v1/shopping/flight-offers/pricing": true,
+}
 
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		log.Errorf(ctx, "Amadeus API request failed: %v", err)
-		return nil, err
+// retryable reports whether a failed request may be sent again. Only requests that
+// change nothing are: an order an attempt placed or cancelled before timing out would
+// otherwise be placed or cancelled twice.
+func retryable(method, path string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead:
+		return true
+	case http.MethodPost:
+		return readOnlyPosts[path]
 	}
+	return false
+}
 
-	return resp, nil
+// retryDelay is how long to wait before retrying after the given attempt: the server's
+// Retry-After if it sent one, else the base delay doubled per attempt with up to half
+// of it taken off at random, so that parallel searches do not retry in lockstep
+func (c *Client) retryDelay(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return min(wait, maxRetryWait)
+		}
+	}
+	delay := min(time.Duration(c.Config.Retry.BaseDelayMs)*time.Millisecond<<min(attempt-1, 16), maxRetryWait)
+	if delay <= 0 {
+		return 0
+	}
+	return delay - time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// parseRetryAfter reads a Retry-After header, given either in seconds or as an HTTP date
+func parseRetryAfter(header string) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(header); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
 }
 
-// SearchLocations searches for airports and cities by keyword and returns protobuf Location objects
-func (c *Client) SearchLocations(ctx context.Context, keyword string) ([]*pb.Location, error) {
+// SearchLocations searches for airports and cities by keyword and returns protobuf Location objects.
+// With nearby set, a keyword that matches no airport (e.g. a small town) is widened with
+// the airports around it, at the cost of another call. Exact lookups, such as of an
+// airport's IATA code, leave it unset.
+func (c *Client) SearchLocations(ctx context.Context, keyword string, nearby bool) ([]*pb.Location, error) {
+	// Widened and exact results are cached apart
+	prefix := "location"
+	if !nearby {
+		prefix = "location_exact"
+	}
+
 	// Check cache
-	cacheKey := GenerateCacheKey("location", keyword)
+	cacheKey := GenerateCacheKey(prefix, keyword)
 	if val, found := c.Cache.Get(cacheKey); found {
 		if locations, ok := val.([]*pb.Location); ok {
 			log.Debugf(ctx, "SearchLocations: cache hit for '%s'", keyword)
+			tmcontext.RequestStatsFromContext(ctx).AddCacheLookup("location", true)
 			return locations, nil
 		}
 	}
+	tmcontext.RequestStatsFromContext(ctx).AddCacheLookup("location", false)
 
 	data := url.Values{}
 	data.Set("keyword", keyword)
@@ -223,6 +436,7 @@ func (c *Client) SearchLocations(ctx context.Context, keyword string) ([]*pb.Loc
 	}
 
 	var locations []*pb.Location
+	var learned []*orm.Location
 	var lat, lng float64
 	foundCoordinates := false
 	foundAirport := false
@@ -237,6 +451,7 @@ func (c *Client) SearchLocations(ctx context.Context, keyword string) ([]*pb.Loc
 			Geocode:   fmt.Sprintf("%f,%f", l.GeoCode.Latitude, l.GeoCode.Longitude),
 		}
 		locations = append(locations, loc)
+		learned = append(learned, orm.LocationFromPB(loc, l.SubType == "AIRPORT"))
 
 		if l.SubType == "AIRPORT" {
 			foundAirport = true
@@ -251,7 +466,7 @@ func (c *Client) SearchLocations(ctx context.Context, keyword string) ([]*pb.Loc
 	}
 
 	// If we have coordinates but NO airports, search for nearby airports
-	if foundCoordinates && !foundAirport {
+	if nearby && foundCoordinates && !foundAirport {
 		nearbyAirports, err := c.SearchNearbyAirports(ctx, lat, lng)
 		if err == nil {
 			// Add unique airports
@@ -265,6 +480,7 @@ func (c *Client) SearchLocations(ctx context.Context, keyword string) ([]*pb.Loc
 			for _, airport := range nearbyAirports {
 				if len(airport.IataCodes) > 0 && !existingCodes[airport.IataCodes[0]] {
 					locations = append(locations, airport)
+					learned = append(learned, orm.LocationFromPB(airport, true))
 					existingCodes[airport.IataCodes[0]] = true
 				}
 			}
@@ -284,23 +500,30 @@ func (c *Client) SearchLocations(ctx context.Context, keyword string) ([]*pb.Loc
 			// Cache by IATA Codes
 			for _, code := range loc.IataCodes {
 				if code != "" {
-					key := GenerateCacheKey("location", code)
+					key := GenerateCacheKey(prefix, code)
 					c.Cache.Set(key, locations, ttl)
 				}
 			}
 			// Cache by City Code
 			if loc.CityCode != "" {
-				key := GenerateCacheKey("location", loc.CityCode)
+				key := GenerateCacheKey(prefix, loc.CityCode)
 				c.Cache.Set(key, locations, ttl)
 			}
 			// Cache by City Name
 			if loc.City != "" {
-				key := GenerateCacheKey("location", loc.City)
+				key := GenerateCacheKey(prefix, loc.City)
 				c.Cache.Set(key, locations, ttl)
 			}
 		}
 	}
 
+	// Remember them for the autocomplete index
+	if c.DB != nil && len(learned) > 0 {
+		if err := orm.SaveLocations(c.DB, learned); err != nil {
+			log.Warnf(ctx, "SearchLocations: failed to save locations: %v", err)
+		}
+	}
+
 	return locations, nil
 }
 
@@ -353,6 +576,10 @@ func (c *Client) MapError(err error) pb.ErrorCode {
 		return pb.ErrorCode_ERROR_CODE_UNSPECIFIED
 	}
 
+	if errors.Is(err, tmcontext.ErrCallBudgetExhausted) {
+		return pb.ErrorCode_ERROR_CODE_API_LIMIT_REACHED
+	}
+
 	// Check for Amadeus API errors (if we had a custom error struct, we'd check that)
 	// For now, we'll parse the error string or check for common net/http errors
 	errMsg := err.Error()
@@ -3,14 +3,21 @@ package amadeus
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	tmcontext "github.com/va6996/travelingman/context"
+	"github.com/va6996/travelingman/orm"
 	"github.com/va6996/travelingman/pb"
 	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
 )
 
 // mockAmadeusServer creates a test server that mocks Amadeus endpoints
@@ -40,6 +47,7 @@ func mockAmadeusServer() *httptest.Server {
 					AssociatedRecords []AssociatedRecord `json:"associatedRecords"`
 					FlightOffers      []FlightOffer      `json:"flightOffers"`
 					Travelers         []TravelerInfo     `json:"travelers"`
+					Tickets           []Ticket           `json:"tickets,omitempty"`
 				}{ID: "order_123"},
 			})
 		case "/v3/shopping/hotel-offers":
@@ -67,6 +75,12 @@ func mockAmadeusServer() *httptest.Server {
 			// Just return valid JSON structure matching HotelOrderResponse
 			w.WriteHeader(http.StatusCreated)
 			w.Write([]byte(`{"data": [{"id": "hotel_order_1"}]}`))
+		case "/v3/shopping/hotel-offers/offer1":
+			w.Write([]byte(`{"data": {"hotel": {"hotelId": "H1", "name": "Test Hotel"},
+				"offers": [{"id": "offer1", "price": {"currency": "USD", "total": "100.00"}, "policies": {"paymentType": "guarantee"}}]}}`))
+		case "/v2/booking/hotel-orders":
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"data": [{"id": "hotel_order_1"}]}`))
 		case "/v1/reference-data/locations":
 			json.NewEncoder(w).Encode(LocationSearchResponse{
 				Data: []LocationData{{
@@ -101,11 +115,71 @@ func TestClient_Authenticate(t *testing.T) {
 	}
 	client.BaseURL = ts.URL
 
-	err = client.Authenticate()
+	err = client.Authenticate(context.Background())
 	assert.NoError(t, err)
 	assert.Equal(t, "test_token", client.Token.AccessToken)
 }
 
+func TestDoRequest_ConcurrentTokenRefresh(t *testing.T) {
+	// A slow token endpoint leaves time for the other requests to pile up behind it
+	var tokenCalls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/security/oauth2/token" {
+			tokenCalls.Add(1)
+			time.Sleep(20 * time.Millisecond)
+			json.NewEncoder(w).Encode(AuthToken{AccessToken: "test_token", ExpiresIn: 1800})
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer test_token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(LocationSearchResponse{})
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(Config{
+		ClientID: "id", ClientSecret: "secret", IsProduction: false,
+		FlightLimit: 10, HotelLimit: 10, Timeout: 10,
+		CacheTTL: CacheTTLConfig{Location: 24, Flight: 24, Hotel: 24},
+	}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	client.BaseURL = ts.URL
+
+	requestAll := func() {
+		var wg sync.WaitGroup
+		for i := range 20 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp, err := client.doRequest(context.Background(), "GET", fmt.Sprintf("/v1/reference-data/locations?keyword=%d", i), nil)
+				if assert.NoError(t, err) {
+					assert.Equal(t, http.StatusOK, resp.StatusCode)
+					resp.Body.Close()
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	// Without a token, one refresh serves every request
+	requestAll()
+	assert.Equal(t, int32(1), tokenCalls.Load())
+
+	// Once it expires, one more
+	client.tokenMu.Lock()
+	client.Token.Expiry = time.Now().Add(-time.Second)
+	client.tokenMu.Unlock()
+	requestAll()
+	assert.Equal(t, int32(2), tokenCalls.Load())
+
+	// And none while it is valid
+	requestAll()
+	assert.Equal(t, int32(2), tokenCalls.Load())
+}
+
 func TestSearchFlights(t *testing.T) {
 	ts := mockAmadeusServer()
 	defer ts.Close()
@@ -174,6 +248,86 @@ func TestBookFlight(t *testing.T) {
 	assert.Equal(t, "order_123", resp.Data.ID)
 }
 
+func TestGetFlightOrder(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/security/oauth2/token":
+			json.NewEncoder(w).Encode(AuthToken{AccessToken: "test_token", ExpiresIn: 1800})
+		case "/v1/booking/flight-orders/eJzTd9f3 NjIyNAE=":
+			w.Write([]byte(`{"data": {"id": "eJzTd9f3 NjIyNAE=",
+				"flightOffers": [{"itineraries": [{"segments": [{"departure": {"iataCode": "JFK", "at": "2026-06-01T09:30:00"}, "arrival": {"iataCode": "LHR", "at": "2026-06-01T21:30:00"}, "carrierCode": "BA", "number": "178"}]}]}],
+				"tickets": [{"documentType": "ETICKET", "documentNumber": "125-1234567890", "documentStatus": "ISSUED", "travelerId": "1"}]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(Config{
+		ClientID: "id", ClientSecret: "secret", IsProduction: false,
+		FlightLimit: 10, HotelLimit: 10, Timeout: 10,
+		CacheTTL: CacheTTLConfig{Location: 24, Flight: 24, Hotel: 24},
+	}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	client.BaseURL = ts.URL
+
+	// Order IDs are escaped into the path
+	order, err := client.GetFlightOrder(context.Background(), "eJzTd9f3 NjIyNAE=")
+	if assert.NoError(t, err) {
+		assert.True(t, order.Ticketed())
+		flight := order.BookedFlight()
+		assert.Equal(t, "178", flight.GetFlightNumber())
+		assert.Equal(t, time.Date(2026, 6, 1, 9, 30, 0, 0, time.UTC), flight.GetDepartureTime().AsTime())
+	}
+
+	_, err = client.GetFlightOrder(context.Background(), "gone")
+	assert.ErrorIs(t, err, ErrOrderNotFound)
+
+	assert.False(t, (&FlightOrderResponse{}).Ticketed())
+	assert.Nil(t, (&FlightOrderResponse{}).BookedFlight())
+}
+
+func TestGetHotelOrder(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/security/oauth2/token":
+			json.NewEncoder(w).Encode(AuthToken{AccessToken: "test_token", ExpiresIn: 1800})
+		case "/v2/booking/hotel-orders/HO1":
+			w.Write([]byte(`{"data": {"type": "hotel-order", "id": "HO1", "hotelBookings": [{"id": "HB1", "bookingStatus": "CONFIRMED"}]}}`))
+		case "/v2/booking/hotel-orders/HO2":
+			w.Write([]byte(`{"data": {"type": "hotel-order", "id": "HO2", "hotelBookings": [{"id": "HB2", "bookingStatus": "CANCELLED"}]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(Config{
+		ClientID: "id", ClientSecret: "secret", IsProduction: false,
+		FlightLimit: 10, HotelLimit: 10, Timeout: 10,
+		CacheTTL: CacheTTLConfig{Location: 24, Flight: 24, Hotel: 24},
+	}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	client.BaseURL = ts.URL
+
+	order, err := client.GetHotelOrder(context.Background(), "HO1")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "HO1", order.Data.ID)
+		assert.False(t, order.Cancelled())
+	}
+	order, err = client.GetHotelOrder(context.Background(), "HO2")
+	if assert.NoError(t, err) {
+		assert.True(t, order.Cancelled())
+	}
+
+	_, err = client.GetHotelOrder(context.Background(), "gone")
+	assert.ErrorIs(t, err, ErrOrderNotFound)
+}
+
 func TestSearchHotelOffers(t *testing.T) {
 	ts := mockAmadeusServer()
 	defer ts.Close()
@@ -212,8 +366,530 @@ func TestSearchLocations(t *testing.T) {
 	}
 	client.BaseURL = ts.URL
 
-	resp, err := client.SearchLocations(context.Background(), "Paris")
+	resp, err := client.SearchLocations(context.Background(), "Paris", true)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, resp)
 	assert.Equal(t, "PAR", resp[0].IataCodes[0])
 }
+
+func TestSearchLocations_Nearby(t *testing.T) {
+	// A town without an airport of its own
+	var nearbyCalls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/security/oauth2/token":
+			json.NewEncoder(w).Encode(AuthToken{AccessToken: "test_token", ExpiresIn: 1800})
+		case "/v1/reference-data/locations":
+			json.NewEncoder(w).Encode(LocationSearchResponse{Data: []LocationData{{
+				SubType: "CITY", Name: "SINTRA", JobCode: "SNT",
+				Address: Address{CityName: "SINTRA", CountryName: "PORTUGAL"},
+				GeoCode: GeoCode{Latitude: 38.8, Longitude: -9.38},
+			}}})
+		case "/v1/reference-data/locations/airports":
+			nearbyCalls.Add(1)
+			json.NewEncoder(w).Encode(LocationSearchResponse{Data: []LocationData{{
+				SubType: "AIRPORT", Name: "HUMBERTO DELGADO", JobCode: "LIS",
+				Address: Address{CityName: "LISBON", CityCode: "LIS", CountryName: "PORTUGAL"},
+			}}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(Config{
+		ClientID: "id", ClientSecret: "secret", IsProduction: false,
+		FlightLimit: 10, HotelLimit: 10, Timeout: 10,
+		CacheTTL: CacheTTLConfig{Location: 24, Flight: 24, Hotel: 24},
+	}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	client.BaseURL = ts.URL
+	client.DB, err = gorm.Open(sqlite.Open("file:learned_locations?mode=memory&cache=shared"), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, client.DB.AutoMigrate(&orm.Location{}))
+
+	// An exact lookup makes no nearby-airport call
+	locations, err := client.SearchLocations(context.Background(), "Sintra", false)
+	assert.NoError(t, err)
+	assert.Len(t, locations, 1)
+	assert.Equal(t, int32(0), nearbyCalls.Load())
+
+	// Widened, the airports around it are added; the exact result cached above is not reused
+	locations, err = client.SearchLocations(context.Background(), "Sintra", true)
+	assert.NoError(t, err)
+	if assert.Len(t, locations, 2) {
+		assert.Equal(t, []string{"LIS"}, locations[1].IataCodes)
+	}
+	assert.Equal(t, int32(1), nearbyCalls.Load())
+
+	// And the widened result does not leak into exact lookups
+	locations, err = client.SearchLocations(context.Background(), "SNT", false)
+	assert.NoError(t, err)
+	assert.Len(t, locations, 1)
+	assert.Equal(t, int32(1), nearbyCalls.Load())
+
+	// Both are remembered for autocomplete, the town as a city
+	learned, err := orm.ListLocations(client.DB)
+	assert.NoError(t, err)
+	if assert.Len(t, learned, 2) {
+		assert.Equal(t, "LIS", learned[0].IataCodes[0])
+		assert.Equal(t, "SINTRA", learned[1].City)
+		assert.Equal(t, "SNT", learned[1].CityCode)
+		assert.Empty(t, learned[1].IataCodes)
+	}
+}
+
+func TestDoRequest_RetryBudget(t *testing.T) {
+	// The location endpoint is down for the whole request
+	var calls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/security/oauth2/token" {
+			json.NewEncoder(w).Encode(AuthToken{AccessToken: "test_token", ExpiresIn: 1800})
+			return
+		}
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(Config{
+		ClientID: "id", ClientSecret: "secret", IsProduction: false,
+		FlightLimit: 10, HotelLimit: 10, Timeout: 10,
+		CacheTTL: CacheTTLConfig{Location: 24, Flight: 24, Hotel: 24},
+		Retry:    RetryConfig{MaxRetries: 2},
+	}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	client.BaseURL = ts.URL
+
+	budget := tmcontext.NewRetryBudget(2)
+	ctx := tmcontext.WithRetryBudget(context.Background(), budget)
+
+	// The first call retries until the budget is spent
+	_, err = client.SearchLocations(ctx, "Paris", true)
+	assert.Error(t, err)
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Equal(t, 0, budget.Remaining())
+	assert.Equal(t, 2, budget.Used())
+
+	// Later calls in the same request are not retried
+	_, err = client.SearchLocations(ctx, "Rome", true)
+	assert.Error(t, err)
+	assert.Equal(t, int32(4), calls.Load())
+	assert.Equal(t, 2, budget.Used())
+}
+
+func TestDoRequest_RateLimited(t *testing.T) {
+	// Rate limited twice, with the server asking for no wait, then answered
+	var calls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/security/oauth2/token" {
+			json.NewEncoder(w).Encode(AuthToken{AccessToken: "test_token", ExpiresIn: 1800})
+			return
+		}
+		if calls.Add(1) <= 2 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		json.NewEncoder(w).Encode(LocationSearchResponse{Data: []LocationData{{SubType: "CITY", Name: "PARIS", JobCode: "PAR"}}})
+	}))
+	defer ts.Close()
+
+	// The base delay would outlast the test; Retry-After takes precedence
+	client, err := NewClient(Config{
+		ClientID: "id", ClientSecret: "secret", IsProduction: false,
+		FlightLimit: 10, HotelLimit: 10, Timeout: 10,
+		CacheTTL: CacheTTLConfig{Location: 24, Flight: 24, Hotel: 24},
+		Retry:    RetryConfig{MaxRetries: 3, BaseDelayMs: 60000},
+	}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	client.BaseURL = ts.URL
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	locations, err := client.SearchLocations(ctx, "Paris", true)
+	assert.NoError(t, err)
+	assert.Len(t, locations, 1)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestDoRequest_MaxRetries(t *testing.T) {
+	var calls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/security/oauth2/token" {
+			json.NewEncoder(w).Encode(AuthToken{AccessToken: "test_token", ExpiresIn: 1800})
+			return
+		}
+		calls.Add(1)
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	newClient := func(retry RetryConfig) *Client {
+		client, err := NewClient(Config{
+			ClientID: "id", ClientSecret: "secret", IsProduction: false,
+			FlightLimit: 10, HotelLimit: 10, Timeout: 10,
+			CacheTTL: CacheTTLConfig{Location: 24, Flight: 24, Hotel: 24},
+			Retry:    retry,
+		}, nil, nil, nil)
+		if err != nil {
+			t.Fatalf("Failed to create client: %v", err)
+		}
+		client.BaseURL = ts.URL
+		return client
+	}
+
+	// Attempts stop at the first plus the configured retries
+	_, err := newClient(RetryConfig{MaxRetries: 2, BaseDelayMs: 1}).SearchLocations(context.Background(), "Paris", true)
+	assert.Error(t, err)
+	assert.Equal(t, int32(3), calls.Load())
+
+	// And no retries makes one attempt
+	calls.Store(0)
+	_, err = newClient(RetryConfig{}).SearchLocations(context.Background(), "Rome", true)
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), calls.Load())
+
+	// A cancelled request stops waiting for its next attempt
+	calls.Store(0)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = newClient(RetryConfig{MaxRetries: 2, BaseDelayMs: 60000}).SearchLocations(ctx, "Madrid", true)
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestDoRequest_RetriesOnlyLookups(t *testing.T) {
+	// Every call times out at the gateway, possibly after Amadeus acted on it
+	calls := map[string]int{}
+	var mu sync.Mutex
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/security/oauth2/token" {
+			json.NewEncoder(w).Encode(AuthToken{AccessToken: "test_token", ExpiresIn: 1800})
+			return
+		}
+		mu.Lock()
+		calls[r.Method+" "+r.URL.Path]++
+		mu.Unlock()
+		w.WriteHeader(http.StatusGatewayTimeout)
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(Config{
+		ClientID: "id", ClientSecret: "secret", IsProduction: false,
+		FlightLimit: 10, HotelLimit: 10, Timeout: 10,
+		CacheTTL: CacheTTLConfig{Location: 24, Flight: 24, Hotel: 24},
+		Retry:    RetryConfig{MaxRetries: 2},
+	}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	client.BaseURL = ts.URL
+
+	for _, call := range []struct{ method, endpoint string }{
+		{"GET", "/v1/reference-data/locations?keyword=PAR"},
+		{"POST", "/v1/shopping/flight-offers/pricing"},
+		{"POST", "/v1/booking/flight-orders"},
+		{"POST", "/v2/booking/hotel-orders"},
+		{"POST", "/v1/ordering/transfer-orders"},
+		{"DELETE", "/v1/booking/flight-orders/ORDER1"},
+	} {
+		resp, err := client.doRequest(context.Background(), call.method, call.endpoint, nil)
+		if assert.NoError(t, err) {
+			resp.Body.Close()
+		}
+	}
+
+	// Lookups are retried; orders and cancellations are sent once
+	assert.Equal(t, map[string]int{
+		"GET /v1/reference-data/locations":        3,
+		"POST /v1/shopping/flight-offers/pricing": 3,
+		"POST /v1/booking/flight-orders":          1,
+		"POST /v2/booking/hotel-orders":           1,
+		"POST /v1/ordering/transfer-orders":       1,
+		"DELETE /v1/booking/flight-orders/ORDER1": 1,
+	}, calls)
+}
+
+func TestRetryDelay(t *testing.T) {
+	client := &Client{Config: Config{Retry: RetryConfig{BaseDelayMs: 1000}}}
+	withHeader := func(value string) *http.Response {
+		resp := &http.Response{Header: http.Header{}}
+		resp.Header.Set("Retry-After", value)
+		return resp
+	}
+
+	// The server's Retry-After wins, in seconds or as a date, within the cap
+	assert.Equal(t, 3*time.Second, client.retryDelay(1, withHeader("3")))
+	assert.Equal(t, maxRetryWait, client.retryDelay(1, withHeader("3600")))
+	wait := client.retryDelay(1, withHeader(time.Now().Add(10*time.Second).UTC().Format(http.TimeFormat)))
+	assert.Greater(t, wait, 8*time.Second)
+	assert.LessOrEqual(t, wait, 10*time.Second)
+
+	// Otherwise the base delay doubles per attempt, less up to half of it as jitter
+	for attempt, full := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second} {
+		for range 20 {
+			wait := client.retryDelay(attempt, withHeader("soon"))
+			assert.GreaterOrEqual(t, wait, full/2)
+			assert.LessOrEqual(t, wait, full)
+		}
+	}
+
+	// Late attempts stay within the cap
+	wait = client.retryDelay(40, nil)
+	assert.GreaterOrEqual(t, wait, maxRetryWait/2)
+	assert.LessOrEqual(t, wait, maxRetryWait)
+}
+
+func TestDoRequest_CallBudget(t *testing.T) {
+	var calls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/security/oauth2/token" {
+			json.NewEncoder(w).Encode(AuthToken{AccessToken: "test_token", ExpiresIn: 1800})
+			return
+		}
+		calls.Add(1)
+		json.NewEncoder(w).Encode(LocationSearchResponse{})
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(Config{
+		ClientID: "id", ClientSecret: "secret", IsProduction: false,
+		FlightLimit: 10, HotelLimit: 10, Timeout: 10,
+		CacheTTL: CacheTTLConfig{Location: 24, Flight: 24, Hotel: 24},
+	}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	client.BaseURL = ts.URL
+
+	budget := tmcontext.NewCallBudget(3)
+	ctx := tmcontext.WithCallBudget(context.Background(), budget)
+
+	// Past the ceiling no further calls reach the provider
+	for i, keyword := range []string{"Paris", "Rome", "Madrid", "Berlin", "Vienna"} {
+		_, err := client.SearchLocations(ctx, keyword, true)
+		if i < 3 {
+			assert.NoError(t, err, keyword)
+			continue
+		}
+		assert.ErrorIs(t, err, tmcontext.ErrCallBudgetExhausted, keyword)
+		assert.Equal(t, pb.ErrorCode_ERROR_CODE_API_LIMIT_REACHED, client.MapError(err))
+	}
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Equal(t, 3, budget.Used())
+	assert.True(t, budget.Exhausted())
+}
+
+func TestSearchHotelOffers_MultipleRates(t *testing.T) {
+	offer := func(id, category, total string) HotelOffer {
+		o := HotelOffer{ID: id, Price: HotelPrice{Total: total, Currency: "EUR"}, Guests: HotelGuests{Adults: 2}}
+		o.Room.TypeEstimated.Category = category
+		return o
+	}
+
+	var query string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/security/oauth2/token":
+			json.NewEncoder(w).Encode(AuthToken{AccessToken: "test_token", ExpiresIn: 1800})
+		case "/v3/shopping/hotel-offers":
+			query = r.URL.RawQuery
+			json.NewEncoder(w).Encode(HotelSearchResponse{Data: []HotelOfferData{
+				{Available: true, Hotel: HotelInfo{HotelId: "H1", Name: "Grand"}, Offers: []HotelOffer{
+					offer("suite", "SUITE", "400.00"),
+					offer("basic", "STANDARD_ROOM", "120.00"),
+					offer("superior", "SUPERIOR_ROOM", "180.00"),
+				}},
+				{Available: true, Hotel: HotelInfo{HotelId: "H2", Name: "Budget"}, Offers: []HotelOffer{
+					offer("only", "STANDARD_ROOM", "150.00"),
+				}},
+				{Available: true, Hotel: HotelInfo{HotelId: "H3", Name: "Far Away"}, Offers: []HotelOffer{
+					offer("far", "STANDARD_ROOM", "90.00"),
+				}},
+			}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(Config{
+		ClientID: "id", ClientSecret: "secret", IsProduction: false,
+		FlightLimit: 10, HotelLimit: 2, Timeout: 10,
+		HotelOffers: HotelOffersConfig{PerHotel: 2},
+		CacheTTL:    CacheTTLConfig{Location: 24, Flight: 24, Hotel: 24},
+	}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	client.BaseURL = ts.URL
+
+	acc := &pb.Accommodation{
+		TravelerCount: 2,
+		CheckIn:       timestamppb.New(time.Date(2025, 10, 10, 0, 0, 0, 0, time.UTC)),
+		CheckOut:      timestamppb.New(time.Date(2025, 10, 11, 0, 0, 0, 0, time.UTC)),
+		Cost:          &pb.Cost{Currency: "EUR"},
+	}
+	resp, err := client.SearchHotelOffers(context.Background(), []string{"H1", "H2", "H3"}, acc)
+	if err != nil {
+		t.Fatalf("SearchHotelOffers failed: %v", err)
+	}
+
+	assert.Contains(t, query, "bestRateOnly=false")
+
+	// The hotel limit counts properties: H3 is dropped, and H1 keeps its two cheapest rates
+	var got []string
+	for _, a := range resp {
+		got = append(got, fmt.Sprintf("%s/%s/%.0f/%t", a.HotelId, a.Preferences.RoomType, a.Cost.Value, a.PropertyCheapest))
+	}
+	assert.Equal(t, []string{
+		"H1/STANDARD_ROOM/120/true",
+		"H1/SUPERIOR_ROOM/180/false",
+		"H2/STANDARD_ROOM/150/true",
+	}, got)
+
+	// A repeat is served from the cache, unchanged by what the first caller did
+	resp[1].PropertyCheapest = true
+	resp[1].Cost.Value = 100
+	again, err := client.SearchHotelOffers(context.Background(), []string{"H1", "H2", "H3"}, acc)
+	assert.NoError(t, err)
+	if assert.Len(t, again, 3) {
+		assert.Equal(t, "SUPERIOR_ROOM", again[1].Preferences.RoomType)
+		assert.Equal(t, 180.0, again[1].Cost.Value)
+		assert.False(t, again[1].PropertyCheapest)
+	}
+}
+
+func TestClient_UserAgent(t *testing.T) {
+	agents := map[string]string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		agents[r.URL.Path] = r.Header.Get("User-Agent")
+		switch r.URL.Path {
+		case "/v1/security/oauth2/token":
+			json.NewEncoder(w).Encode(AuthToken{AccessToken: "test_token", ExpiresIn: 1800})
+		default:
+			json.NewEncoder(w).Encode(LocationSearchResponse{})
+		}
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(Config{
+		ClientID: "id", ClientSecret: "secret", IsProduction: false,
+		FlightLimit: 10, HotelLimit: 10, Timeout: 10, UserAgent: "travelingman/1.2.3",
+		CacheTTL: CacheTTLConfig{Location: 24, Flight: 24, Hotel: 24},
+	}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	client.BaseURL = ts.URL
+
+	// Both the token request and the API request carry it
+	resp, err := client.doRequest(context.Background(), "GET", "/v1/reference-data/locations?keyword=PAR", nil)
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+	}
+	assert.Equal(t, map[string]string{
+		"/v1/security/oauth2/token":    "travelingman/1.2.3",
+		"/v1/reference-data/locations": "travelingman/1.2.3",
+	}, agents)
+}
+
+func TestSearchHotelOffers_ChildAges(t *testing.T) {
+	var query string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/security/oauth2/token":
+			json.NewEncoder(w).Encode(AuthToken{AccessToken: "test_token", ExpiresIn: 1800})
+		case "/v3/shopping/hotel-offers":
+			query = r.URL.RawQuery
+			json.NewEncoder(w).Encode(HotelSearchResponse{Data: []HotelOfferData{
+				{Available: true, Hotel: HotelInfo{HotelId: "H1", Name: "Family Inn"}, Offers: []HotelOffer{{
+					ID: "family", Price: HotelPrice{Total: "200.00", Currency: "EUR"},
+					Guests: HotelGuests{Adults: 2, ChildAges: []int{5, 9}},
+				}}},
+			}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(Config{
+		ClientID: "id", ClientSecret: "secret", FlightLimit: 10, HotelLimit: 10, Timeout: 10,
+		CacheTTL: CacheTTLConfig{Location: 24, Flight: 24, Hotel: 24},
+	}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	client.BaseURL = ts.URL
+
+	// Two adults travelling with a 5 and a 9 year old
+	acc := &pb.Accommodation{
+		TravelerCount: 4,
+		ChildAges:     []int32{5, 9},
+		CheckIn:       timestamppb.New(time.Date(2025, 10, 10, 0, 0, 0, 0, time.UTC)),
+		CheckOut:      timestamppb.New(time.Date(2025, 10, 11, 0, 0, 0, 0, time.UTC)),
+		Cost:          &pb.Cost{Currency: "EUR"},
+	}
+	resp, err := client.SearchHotelOffers(context.Background(), []string{"H1"}, acc)
+	if err != nil {
+		t.Fatalf("SearchHotelOffers failed: %v", err)
+	}
+
+	assert.Contains(t, query, "adults=2&")
+	assert.Contains(t, query, "childAges=5,9")
+	if assert.Len(t, resp, 1) {
+		assert.Equal(t, int32(4), resp[0].TravelerCount)
+		assert.Equal(t, []int32{5, 9}, resp[0].ChildAges)
+	}
+}
+
+func TestCancelOrders(t *testing.T) {
+	var deleted []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/security/oauth2/token" {
+			json.NewEncoder(w).Encode(AuthToken{AccessToken: "test_token", ExpiresIn: 1800})
+			return
+		}
+		assert.Equal(t, http.MethodDelete, r.Method)
+		switch r.URL.Path {
+		case "/v1/booking/flight-orders/F1", "/v2/booking/hotel-orders/H1":
+			deleted = append(deleted, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		case "/v1/booking/flight-orders/LOCKED":
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(Config{
+		ClientID: "id", ClientSecret: "secret", IsProduction: false,
+		FlightLimit: 10, HotelLimit: 10, Timeout: 10,
+		CacheTTL: CacheTTLConfig{Location: 24, Flight: 24, Hotel: 24},
+	}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	client.BaseURL = ts.URL
+
+	assert.NoError(t, client.CancelFlightOrder(context.Background(), "F1"))
+	assert.NoError(t, client.CancelHotelOrder(context.Background(), "H1"))
+	assert.Equal(t, []string{"/v1/booking/flight-orders/F1", "/v2/booking/hotel-orders/H1"}, deleted)
+
+	assert.ErrorIs(t, client.CancelFlightOrder(context.Background(), "gone"), ErrOrderNotFound)
+	assert.ErrorIs(t, client.CancelHotelOrder(context.Background(), "gone"), ErrOrderNotFound)
+	err = client.CancelFlightOrder(context.Background(), "LOCKED")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrOrderNotFound)
+}
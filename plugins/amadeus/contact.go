@@ -0,0 +1,178 @@
+package amadeus
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/va6996/travelingman/pb"
+)
+
+// callingCodes are the country calling codes of the countries travelers commonly
+// live in, by ISO 3166-1 alpha-2 code
+var callingCodes = map[string]string{
+	"US": "1", "CA": "1", "GB": "44", "IE": "353", "FR": "33", "DE": "49", "IT": "39",
+	"ES": "34", "PT": "351", "NL": "31", "BE": "32", "CH": "41", "AT": "43", "SE": "46",
+	"NO": "47", "DK": "45", "FI": "358", "PL": "48", "GR": "30", "TR": "90", "AE": "971",
+	"IN": "91", "CN": "86", "HK": "852", "SG": "65", "JP": "81", "KR": "82", "TH": "66",
+	"AU": "61", "NZ": "64", "BR": "55", "MX": "52", "AR": "54", "ZA": "27",
+}
+
+// knownCallingCodes is the set of calling codes in callingCodes
+var knownCallingCodes = func() map[string]bool {
+	known := map[string]bool{}
+	for _, c := range callingCodes {
+		known[c] = true
+	}
+	return known
+}()
+
+// keepsTrunkZero lists the calling codes whose numbers keep their leading 0 after
+// the calling code; everywhere else outside North America the 0 is dropped
+var keepsTrunkZero = map[string]bool{"39": true}
+
+// travelerCountry is the country a user lives in: that of their address, else the
+// nationality of their first passport. Empty if neither is known.
+func travelerCountry(user *pb.User) string {
+	if c := user.GetAddress().GetCountryCode(); c != "" {
+		return strings.ToUpper(c)
+	}
+	for _, p := range user.GetPassports() {
+		if c := firstNonEmpty(p.Nationality, p.IssuingCountry); c != "" {
+			return strings.ToUpper(c)
+		}
+	}
+	return ""
+}
+
+// formatPhone splits a phone number as entered into the calling code and national
+// number Amadeus expects. Numbers starting with + or 00 carry their own calling code;
+// others are taken as national numbers of country, and as North American if the
+// country is unknown. The national number is digits only, without a trunk prefix.
+// Numbers with a calling code not in callingCodes are not sent.
+func formatPhone(raw, country string) (Phone, bool) {
+	raw = strings.TrimSpace(raw)
+	digits := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, raw)
+	if digits == "" {
+		return Phone{}, false
+	}
+
+	var code string
+	switch {
+	case strings.HasPrefix(raw, "+"):
+		code, digits = splitCallingCode(digits)
+	case strings.HasPrefix(digits, "00"):
+		code, digits = splitCallingCode(digits[2:])
+	default:
+		code = callingCodes[country]
+		if code == "" {
+			code = "1"
+		}
+	}
+	if code == "" {
+		return Phone{}, false
+	}
+
+	switch {
+	case code == "1":
+		digits = strings.TrimPrefix(digits, "1")
+	case !keepsTrunkZero[code]:
+		digits = strings.TrimLeft(digits, "0")
+	}
+	return Phone{DeviceType: "MOBILE", CountryCallingCode: code, Number: digits}, true
+}
+
+// splitCallingCode splits an international number into its calling code and the rest.
+// Calling codes are prefix-free, so the first known one of up to three digits wins.
+func splitCallingCode(digits string) (string, string) {
+	for n := 1; n <= 3 && n < len(digits); n++ {
+		if knownCallingCodes[digits[:n]] {
+			return digits[:n], digits[n:]
+		}
+	}
+	return "", digits
+}
+
+// e164 formats a phone as one international number, e.g. "+442079460958"
+func (p Phone) e164() string {
+	return "+" + p.CountryCallingCode + p.Number
+}
+
+var (
+	ukPostcode     = regexp.MustCompile(`^([A-Z]{1,2}[0-9][A-Z0-9]?)([0-9][A-Z]{2})$`)
+	canadaPostcode = regexp.MustCompile(`^([A-Z][0-9][A-Z])([0-9][A-Z][0-9])$`)
+)
+
+// formatAddress turns a user's address into the one Amadeus expects, with the postal
+// code written as its country does, e.g. "SW1A 1AA" in the UK and "100-0001" in Japan
+func formatAddress(addr *pb.PostalAddress) *PostalAddress {
+	if addr == nil {
+		return nil
+	}
+	country := strings.ToUpper(strings.TrimSpace(addr.CountryCode))
+	out := &PostalAddress{
+		PostalCode:  formatPostalCode(addr.PostalCode, country),
+		CountryCode: country,
+		CityName:    strings.TrimSpace(addr.City),
+	}
+	for _, line := range addr.Lines {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			out.Lines = append(out.Lines, line)
+		}
+	}
+	return out
+}
+
+// formatPostalCode normalizes a postal code for its country. Codes of other
+// countries, or that do not look like their country's, are only trimmed.
+func formatPostalCode(code, country string) string {
+	compact := strings.ToUpper(strings.Join(strings.Fields(code), ""))
+	switch country {
+	case "GB":
+		if m := ukPostcode.FindStringSubmatch(compact); m != nil {
+			return m[1] + " " + m[2]
+		}
+	case "CA":
+		if m := canadaPostcode.FindStringSubmatch(compact); m != nil {
+			return m[1] + " " + m[2]
+		}
+	case "JP":
+		if d := strings.ReplaceAll(compact, "-", ""); len(d) == 7 {
+			return d[:3] + "-" + d[3:]
+		}
+	case "US":
+		if d := strings.ReplaceAll(compact, "-", ""); len(d) == 9 {
+			return d[:5] + "-" + d[5:]
+		}
+	}
+	return strings.TrimSpace(code)
+}
+
+// NewHotelGuest builds the hotel guest for a user, with the phone number in the
+// international format hotel bookings require
+func NewHotelGuest(tid int, user *pb.User) HotelGuest {
+	guest := HotelGuest{
+		Tid:       tid,
+		FirstName: getFirstName(user.FullName),
+		LastName:  getLastName(user.FullName),
+		Email:     user.Email,
+	}
+	if phone, ok := formatPhone(user.Phone, travelerCountry(user)); ok {
+		guest.Phone = phone.e164()
+	}
+	return guest
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
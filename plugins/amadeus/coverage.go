@@ -0,0 +1,83 @@
+package amadeus
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	tmcore "github.com/va6996/travelingman/core"
+	"github.com/va6996/travelingman/log"
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// geocodeRadiusKm is how far around a stay's coordinates hotels count as in the place
+// itself rather than in a nearby city
+const geocodeRadiusKm = 5
+
+// NearbyCityFinder finds cities near a location, nearest first
+type NearbyCityFinder interface {
+	NearbyCities(loc *pb.Location, radiusKm float64) []tmcore.NearbyCity
+}
+
+// Coverage records that hotels were found in a nearby city because the stay's own
+// city had none
+type Coverage struct {
+	Requested  *pb.Location
+	City       *pb.Location
+	DistanceKm float64
+}
+
+// Message tells the traveller where the hotels are, e.g.
+// "hotels in Innsbruck, 14 km from Seefeld"
+func (c *Coverage) Message() string {
+	return fmt.Sprintf("hotels in %s, %d km from %s", placeName(c.City), int(math.Round(c.DistanceKm)), placeName(c.Requested))
+}
+
+// SearchHotelsNearby looks for hotels for a stay whose city search found none. It
+// first searches around the stay's coordinates, if it has them; hotels found there are
+// in the place itself and come with no coverage. Otherwise the cities with an airport
+// within Config.HotelFallbackRadiusKm are searched nearest first, like
+// SearchHotelsByCityRelaxed, and the first with hotels is returned with a coverage
+// saying how far it is. A strict-location preference, a zero radius or no finder
+// skip the nearby cities. An empty list means nothing was found; acc is not modified.
+func (c *Client) SearchHotelsNearby(ctx context.Context, acc *pb.Accommodation) (*HotelListResponse, *Relaxation, *Coverage, error) {
+	if lat, lng, ok := tmcore.ParseGeocode(acc.GetLocation().GetGeocode()); ok {
+		resp, err := c.SearchHotelsByGeocode(ctx, acc, lat, lng, geocodeRadiusKm)
+		if err != nil || len(resp.Data) > 0 {
+			return resp, nil, nil, err
+		}
+	}
+
+	empty := &HotelListResponse{}
+	radius := c.Config.HotelFallbackRadiusKm
+	if acc.GetPreferences().GetStrictLocation() || radius <= 0 || c.Nearby == nil {
+		return empty, nil, nil, nil
+	}
+
+	for _, city := range c.Nearby.NearbyCities(acc.Location, float64(radius)) {
+		log.Infof(ctx, "SearchHotelsNearby: No hotels in %s, trying %s (%.0f km away)", placeName(acc.Location), placeName(city.Location), city.DistanceKm)
+		moved := proto.Clone(acc).(*pb.Accommodation)
+		moved.Location = city.Location
+		resp, relaxation, err := c.SearchHotelsByCityRelaxed(ctx, moved)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if len(resp.Data) > 0 {
+			return resp, relaxation, &Coverage{Requested: acc.Location, City: city.Location, DistanceKm: city.DistanceKm}, nil
+		}
+	}
+	return empty, nil, nil, nil
+}
+
+// placeName names a location for the traveller: its city, else its name or code
+func placeName(loc *pb.Location) string {
+	switch {
+	case loc.GetCity() != "":
+		return loc.GetCity()
+	case loc.GetName() != "":
+		return loc.GetName()
+	default:
+		return getLocationCode(loc)
+	}
+}
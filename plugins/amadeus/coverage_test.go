@@ -0,0 +1,106 @@
+package amadeus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	tmcore "github.com/va6996/travelingman/core"
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// fakeNearby returns the same cities for every location and records the radius asked for
+type fakeNearby struct {
+	cities []tmcore.NearbyCity
+	radius float64
+}
+
+func (f *fakeNearby) NearbyCities(loc *pb.Location, radiusKm float64) []tmcore.NearbyCity {
+	f.radius = radiusKm
+	return f.cities
+}
+
+// newCoverageTestClient lists hotels only in Innsbruck and records the path and query
+// of every hotel list request
+func newCoverageTestClient(t *testing.T, requests *[]string) (*Client, *fakeNearby) {
+	client := newCalendarTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/security/oauth2/token":
+			writeToken(w)
+		case "/v1/reference-data/locations/hotels/by-city", "/v1/reference-data/locations/hotels/by-geocode":
+			*requests = append(*requests, r.URL.Path[len("/v1/reference-data/locations/hotels/"):]+"?"+r.URL.RawQuery)
+			resp := HotelListResponse{Data: []HotelData{}}
+			if r.URL.Query().Get("cityCode") == "INN" {
+				resp.Data = append(resp.Data, HotelData{HotelId: "INN1", Name: "Hotel Innsbruck"})
+			}
+			json.NewEncoder(w).Encode(resp)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	nearby := &fakeNearby{cities: []tmcore.NearbyCity{
+		{Location: &pb.Location{City: "Telfs", CityCode: "TFS", IataCodes: []string{"TFS"}}, DistanceKm: 9.6},
+		{Location: &pb.Location{City: "Innsbruck", CityCode: "INN", IataCodes: []string{"INN"}}, DistanceKm: 14.2},
+	}}
+	client.Config.HotelFallbackRadiusKm = 40
+	client.Nearby = nearby
+	return client, nearby
+}
+
+func seefeldStay(strictLocation bool) *pb.Accommodation {
+	return &pb.Accommodation{
+		Location:    &pb.Location{City: "Seefeld", CityCode: "SEF", Geocode: "47.3297,11.1878"},
+		Preferences: &pb.AccommodationPreferences{StrictLocation: strictLocation},
+	}
+}
+
+func TestSearchHotelsNearby(t *testing.T) {
+	var requests []string
+	client, nearby := newCoverageTestClient(t, &requests)
+	acc := seefeldStay(false)
+	original := proto.Clone(acc)
+
+	resp, relaxation, coverage, err := client.SearchHotelsNearby(context.Background(), acc)
+
+	assert.NoError(t, err)
+	if assert.Len(t, resp.Data, 1) {
+		assert.Equal(t, "INN1", resp.Data[0].HotelId)
+	}
+	assert.Nil(t, relaxation)
+	// The area around the stay is searched first, then the nearby cities nearest first
+	assert.Equal(t, []string{
+		"by-geocode?latitude=47.329700&longitude=11.187800&radius=5&radiusUnit=KM",
+		"by-city?cityCode=TFS",
+		"by-city?cityCode=INN",
+	}, requests)
+	assert.Equal(t, 40.0, nearby.radius)
+	if assert.NotNil(t, coverage) {
+		assert.Equal(t, "Innsbruck", coverage.City.City)
+		assert.Equal(t, "hotels in Innsbruck, 14 km from Seefeld", coverage.Message())
+	}
+	assert.True(t, proto.Equal(original, acc), "the requested location is kept")
+}
+
+func TestSearchHotelsNearby_Disabled(t *testing.T) {
+	// A strict location only searches the place itself
+	var requests []string
+	client, _ := newCoverageTestClient(t, &requests)
+	resp, _, coverage, err := client.SearchHotelsNearby(context.Background(), seefeldStay(true))
+	assert.NoError(t, err)
+	assert.Empty(t, resp.Data)
+	assert.Nil(t, coverage)
+	assert.Len(t, requests, 1)
+
+	// As does a zero radius
+	requests = nil
+	client.Config.HotelFallbackRadiusKm = 0
+	resp, _, coverage, err = client.SearchHotelsNearby(context.Background(), seefeldStay(false))
+	assert.NoError(t, err)
+	assert.Empty(t, resp.Data)
+	assert.Nil(t, coverage)
+	assert.Len(t, requests, 1)
+}
@@ -0,0 +1,267 @@
+package amadeus
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	tmcontext "github.com/va6996/travelingman/context"
+	"github.com/va6996/travelingman/log"
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// maxFareTrendSamples caps the real searches per trend when flight-dates is unavailable
+	maxFareTrendSamples = 8
+	// maxFareTrendWeeks caps the length of the range a trend can cover
+	maxFareTrendWeeks = 26
+)
+
+// fareWeek is a 7-day departure window; the last week is clipped to the range end
+type fareWeek struct {
+	start, end time.Time
+}
+
+// weekQuote is an intermediate per-week price before the trend is assembled
+type weekQuote struct {
+	dayQuote
+	date time.Time // Departure day the price was found on (zero when interpolated)
+}
+
+// GetFareTrend returns the indicative lowest one-way fare for every week between from and
+// to (inclusive) and marks the cheapest week. Prices are for all travelers.
+// The flight-dates API is used when available; otherwise one real search is run per week,
+// capped at maxFareTrendSamples evenly spread weeks, and the remaining weeks are
+// interpolated (and flagged as such). Departure days in the past, by the context's
+// clock, are skipped.
+func (c *Client) GetFareTrend(ctx context.Context, origin, destination string, from, to time.Time, adults int, currency string) (*pb.FareTrend, error) {
+	if origin == "" || destination == "" {
+		return nil, fmt.Errorf("origin and destination are required")
+	}
+	if adults <= 0 {
+		adults = 1
+	}
+	currency = currencyOrDefault(currency, "USD")
+
+	today := tmcontext.Now(ctx).UTC().Truncate(24 * time.Hour)
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	if start.Before(today) {
+		start = today
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("date range %s to %s has no future departure days", from.Format("2006-01-02"), to.Format("2006-01-02"))
+	}
+
+	weeks := splitWeeks(start, end)
+	if len(weeks) > maxFareTrendWeeks {
+		return nil, fmt.Errorf("date range covers %d weeks, at most %d are supported", len(weeks), maxFareTrendWeeks)
+	}
+
+	// Check cache
+	cacheKey := GenerateCacheKey("faretrend", origin, destination, start.Format("2006-01-02"), end.Format("2006-01-02"), adults, currency)
+	if val, ok := c.Cache.Get(cacheKey); ok {
+		log.Debugf(ctx, "GetFareTrend: Cache hit for %s-%s %s..%s", origin, destination, start.Format("2006-01-02"), end.Format("2006-01-02"))
+		tmcontext.RequestStatsFromContext(ctx).AddCacheLookup("faretrend", true)
+		// Callers may change what they get, so the cached trend is not handed out
+		return proto.Clone(val.(*pb.FareTrend)).(*pb.FareTrend), nil
+	}
+	tmcontext.RequestStatsFromContext(ctx).AddCacheLookup("faretrend", false)
+
+	source := PriceCalendarSourceFlightDates
+	searches := 1
+	var quotes map[int]weekQuote
+	days, err := c.searchFlightDates(ctx, origin, destination, start, end, adults)
+	if err == nil {
+		quotes = weeklyMinimums(days, weeks)
+	}
+	if len(quotes) == 0 {
+		log.Warnf(ctx, "GetFareTrend: flight-dates unavailable for %s-%s (%v), falling back to sampled searches", origin, destination, err)
+		source = PriceCalendarSourceSampled
+		var sampled int
+		quotes, sampled, err = c.sampleWeeks(ctx, origin, destination, weeks, adults, currency)
+		searches += sampled
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	trend := buildFareTrend(quotes, weeks)
+	trend.Origin = origin
+	trend.Destination = destination
+	trend.FromDate = timestamppb.New(start)
+	trend.ToDate = timestamppb.New(end)
+	trend.TravelerCount = int32(adults)
+	trend.Source = source
+	trend.Searches = int32(searches)
+
+	// Set cache
+	ttl := time.Duration(c.Config.CacheTTL.Flight) * time.Hour
+	c.Cache.Set(cacheKey, proto.Clone(trend), ttl)
+
+	return trend, nil
+}
+
+// splitWeeks cuts [start, end] into consecutive 7-day windows starting at start
+func splitWeeks(start, end time.Time) []fareWeek {
+	var weeks []fareWeek
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 7) {
+		last := d.AddDate(0, 0, 6)
+		if last.After(end) {
+			last = end
+		}
+		weeks = append(weeks, fareWeek{start: d, end: last})
+	}
+	return weeks
+}
+
+// weeklyMinimums reduces per-day quotes to the cheapest quote of each week, keyed by week index
+func weeklyMinimums(days map[string]dayQuote, weeks []fareWeek) map[int]weekQuote {
+	quotes := make(map[int]weekQuote)
+	for i, w := range weeks {
+		for d := w.start; !d.After(w.end); d = d.AddDate(0, 0, 1) {
+			q, ok := days[d.Format("2006-01-02")]
+			if !ok {
+				continue
+			}
+			if best, ok := quotes[i]; !ok || q.value < best.value {
+				quotes[i] = weekQuote{dayQuote: q, date: d}
+			}
+		}
+	}
+	return quotes
+}
+
+// sampleWeeks runs one real flight search on the middle day of up to maxFareTrendSamples
+// evenly spread weeks (always including the first and last) and interpolates the others.
+// It returns the quotes and the number of searches run.
+func (c *Client) sampleWeeks(ctx context.Context, origin, destination string, weeks []fareWeek, adults int, currency string) (map[int]weekQuote, int, error) {
+	quotes := make(map[int]weekQuote)
+	indices := sampleIndices(len(weeks), maxFareTrendSamples)
+	for _, i := range indices {
+		d := weeks[i].start.AddDate(0, 0, 3)
+		if d.After(weeks[i].end) {
+			d = weeks[i].end
+		}
+		transports, err := c.SearchFlights(ctx, &pb.Transport{
+			Type:                pb.TransportType_TRANSPORT_TYPE_FLIGHT,
+			TravelerCount:       int32(adults),
+			OriginLocation:      &pb.Location{IataCodes: []string{origin}},
+			DestinationLocation: &pb.Location{IataCodes: []string{destination}},
+			Cost:                &pb.Cost{Currency: currency},
+			Details: &pb.Transport_Flight{
+				Flight: &pb.Flight{DepartureTime: timestamppb.New(d)},
+			},
+		})
+		if err != nil {
+			log.Warnf(ctx, "sampleWeeks: search for %s failed: %v", d.Format("2006-01-02"), err)
+			continue
+		}
+		for _, t := range transports {
+			if t.Cost == nil || t.Cost.Value <= 0 {
+				continue
+			}
+			if q, ok := quotes[i]; !ok || t.Cost.Value < q.value {
+				quotes[i] = weekQuote{dayQuote: dayQuote{value: t.Cost.Value, currency: currencyOrDefault(t.Cost.Currency, currency)}, date: d}
+			}
+		}
+	}
+
+	if len(quotes) == 0 {
+		return nil, len(indices), fmt.Errorf("no flight offers found for %s-%s between %s and %s", origin, destination,
+			weeks[0].start.Format("2006-01-02"), weeks[len(weeks)-1].end.Format("2006-01-02"))
+	}
+
+	interpolateWeeks(quotes, len(weeks))
+	return quotes, len(indices), nil
+}
+
+// sampleIndices picks at most limit evenly spread indices from [0, n), including both ends
+func sampleIndices(n, limit int) []int {
+	if n <= limit {
+		indices := make([]int, n)
+		for i := range indices {
+			indices[i] = i
+		}
+		return indices
+	}
+	indices := make([]int, limit)
+	for i := range indices {
+		indices[i] = int(math.Round(float64(i) * float64(n-1) / float64(limit-1)))
+	}
+	return indices
+}
+
+// interpolateWeeks fills every week without a quote. Weeks between two sampled weeks are
+// linearly interpolated; weeks outside the sampled range take the nearest sampled value.
+func interpolateWeeks(quotes map[int]weekQuote, n int) {
+	var known []int
+	for i := 0; i < n; i++ {
+		if _, ok := quotes[i]; ok {
+			known = append(known, i)
+		}
+	}
+	if len(known) == 0 {
+		return
+	}
+
+	next := 0
+	for i := 0; i < n; i++ {
+		if _, ok := quotes[i]; ok {
+			continue
+		}
+		for next < len(known) && known[next] < i {
+			next++
+		}
+		var q dayQuote
+		switch {
+		case next == 0:
+			q = quotes[known[0]].dayQuote
+		case next == len(known):
+			q = quotes[known[len(known)-1]].dayQuote
+		default:
+			prev, after := known[next-1], known[next]
+			pq, nq := quotes[prev], quotes[after]
+			frac := float64(i-prev) / float64(after-prev)
+			q = dayQuote{value: pq.value + (nq.value-pq.value)*frac, currency: pq.currency}
+		}
+		q.interpolated = true
+		quotes[i] = weekQuote{dayQuote: q}
+	}
+}
+
+// buildFareTrend turns per-week quotes into the ordered trend and marks the cheapest week.
+// Real (non-interpolated) prices win ties.
+func buildFareTrend(quotes map[int]weekQuote, weeks []fareWeek) *pb.FareTrend {
+	trend := &pb.FareTrend{}
+	var minWeek *pb.WeekPrice
+	for i, w := range weeks {
+		q, ok := quotes[i]
+		if !ok {
+			continue
+		}
+		week := &pb.WeekPrice{
+			WeekStart:    timestamppb.New(w.start),
+			WeekEnd:      timestamppb.New(w.end),
+			Cost:         &pb.Cost{Value: float64(int64(q.value*100+0.5)) / 100, Currency: q.currency}, // Round to cents
+			Interpolated: q.interpolated,
+		}
+		if !q.date.IsZero() {
+			week.CheapestDate = timestamppb.New(q.date)
+		}
+		trend.Weeks = append(trend.Weeks, week)
+
+		if minWeek == nil || week.Cost.Value < minWeek.Cost.Value ||
+			(week.Cost.Value == minWeek.Cost.Value && minWeek.Interpolated && !week.Interpolated) {
+			minWeek = week
+		}
+	}
+	if minWeek != nil {
+		minWeek.IsMin = true
+		trend.CheapestWeek = proto.Clone(minWeek).(*pb.WeekPrice)
+	}
+	return trend
+}
@@ -0,0 +1,160 @@
+package amadeus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetFareTrend_SampledCheapestWeek(t *testing.T) {
+	var offerCalls int32
+	from := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 1, 0)
+	to := from.AddDate(0, 0, 10*7-1) // 10 weeks
+
+	// Fares fall towards the fifth week and rise again after it
+	priceFor := func(date string) float64 {
+		d, _ := time.Parse("2006-01-02", date)
+		week := int(d.Sub(from).Hours() / 24 / 7)
+		if week == 4 {
+			return 90
+		}
+		return 200 + 20*float64(week)
+	}
+
+	client := newCalendarTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/security/oauth2/token":
+			writeToken(w)
+		case "/v1/shopping/flight-dates":
+			w.WriteHeader(http.StatusBadRequest)
+		case "/v2/shopping/flight-offers":
+			atomic.AddInt32(&offerCalls, 1)
+			date := r.URL.Query().Get("departureDate")
+			json.NewEncoder(w).Encode(FlightSearchResponse{
+				Data: []FlightOffer{
+					{ID: "1", Price: Price{Currency: "EUR", Total: formatPrice(priceFor(date) + 40)}},
+					{ID: "2", Price: Price{Currency: "EUR", Total: formatPrice(priceFor(date))}},
+				},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	trend, err := client.GetFareTrend(context.Background(), "JFK", "LIS", from, to, 1, "EUR")
+	if err != nil {
+		t.Fatalf("GetFareTrend failed: %v", err)
+	}
+
+	assert.Equal(t, PriceCalendarSourceSampled, trend.Source)
+	if !assert.Len(t, trend.Weeks, 10) {
+		return
+	}
+
+	// Sampling is bounded: one search per sampled week, not per day
+	assert.Equal(t, int32(maxFareTrendSamples), atomic.LoadInt32(&offerCalls))
+	assert.Equal(t, int32(maxFareTrendSamples+1), trend.Searches, "the flight-dates attempt is counted")
+
+	var interpolated int
+	for _, w := range trend.Weeks {
+		if w.Interpolated {
+			interpolated++
+			assert.Nil(t, w.CheapestDate)
+		}
+	}
+	assert.Equal(t, 10-maxFareTrendSamples, interpolated)
+
+	if !assert.NotNil(t, trend.CheapestWeek) {
+		return
+	}
+	assert.True(t, trend.Weeks[4].IsMin)
+	assert.Equal(t, 90.0, trend.CheapestWeek.Cost.Value)
+	assert.Equal(t, "EUR", trend.CheapestWeek.Cost.Currency)
+	assert.Equal(t, from.AddDate(0, 0, 28), trend.CheapestWeek.WeekStart.AsTime())
+	assert.False(t, trend.CheapestWeek.Interpolated)
+
+	// The trend follows the provider prices around the dip
+	assert.Equal(t, 260.0, trend.Weeks[3].Cost.Value)
+	assert.Equal(t, 300.0, trend.Weeks[5].Cost.Value)
+	assert.Equal(t, to, trend.Weeks[9].WeekEnd.AsTime())
+}
+
+func TestGetFareTrend_FlightDates(t *testing.T) {
+	from := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 1, 0)
+	to := from.AddDate(0, 0, 20) // 3 weeks
+	day := func(offset int) string { return from.AddDate(0, 0, offset).Format("2006-01-02") }
+
+	client := newCalendarTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/security/oauth2/token":
+			writeToken(w)
+		case "/v1/shopping/flight-dates":
+			assert.Equal(t, day(0)+","+day(20), r.URL.Query().Get("departureDate"))
+			w.Write([]byte(`{
+				"data": [
+					{"departureDate": "` + day(1) + `", "price": {"total": "150.00"}},
+					{"departureDate": "` + day(5) + `", "price": {"total": "130.00"}},
+					{"departureDate": "` + day(9) + `", "price": {"total": "95.00"}},
+					{"departureDate": "` + day(16) + `", "price": {"total": "110.00"}}
+				],
+				"meta": {"currency": "USD"}
+			}`))
+		case "/v2/shopping/flight-offers":
+			t.Errorf("flight-offers should not be called when flight-dates succeeds")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	trend, err := client.GetFareTrend(context.Background(), "BOS", "LIS", from, to, 2, "USD")
+	if err != nil {
+		t.Fatalf("GetFareTrend failed: %v", err)
+	}
+
+	assert.Equal(t, PriceCalendarSourceFlightDates, trend.Source)
+	assert.Equal(t, int32(1), trend.Searches)
+	if !assert.Len(t, trend.Weeks, 3) {
+		return
+	}
+
+	// Each week keeps its cheapest day, scaled to all travelers
+	assert.Equal(t, 260.0, trend.Weeks[0].Cost.Value)
+	assert.Equal(t, day(5), trend.Weeks[0].CheapestDate.AsTime().Format("2006-01-02"))
+	assert.Equal(t, 190.0, trend.Weeks[1].Cost.Value)
+	assert.Equal(t, 220.0, trend.Weeks[2].Cost.Value)
+
+	assert.True(t, trend.Weeks[1].IsMin)
+	assert.Equal(t, day(7), trend.CheapestWeek.WeekStart.AsTime().Format("2006-01-02"))
+	assert.Equal(t, day(9), trend.CheapestWeek.CheapestDate.AsTime().Format("2006-01-02"))
+
+	// A repeat is served from the cache, unchanged by what the first caller did
+	trend.Weeks = nil
+	again, err := client.GetFareTrend(context.Background(), "BOS", "LIS", from, to, 2, "USD")
+	assert.NoError(t, err)
+	assert.Len(t, again.Weeks, 3)
+	again.CheapestWeek = nil
+	cached, err := client.GetFareTrend(context.Background(), "BOS", "LIS", from, to, 2, "USD")
+	assert.NoError(t, err)
+	assert.NotNil(t, cached.CheapestWeek)
+}
+
+func TestGetFareTrend_RangeLimits(t *testing.T) {
+	client := newCalendarTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("no request expected, got %s", r.URL.Path)
+	})
+
+	from := time.Now().UTC().AddDate(0, 0, 7)
+	_, err := client.GetFareTrend(context.Background(), "JFK", "LIS", from, from.AddDate(0, 0, 7*maxFareTrendWeeks+1), 1, "")
+	assert.Error(t, err)
+
+	past := time.Now().UTC().AddDate(0, -2, 0)
+	_, err = client.GetFareTrend(context.Background(), "JFK", "LIS", past, past.AddDate(0, 0, 14), 1, "")
+	assert.Error(t, err)
+}
@@ -3,21 +3,66 @@ package amadeus
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"net/url"
 	"strconv"
 	"time"
 
+	tmcontext "github.com/va6996/travelingman/context"
 	"github.com/va6996/travelingman/log"
 	"github.com/va6996/travelingman/orm"
 	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // --- Structs for Flight Search (Simplified) ---
 
 type FlightSearchResponse struct {
-	Data []FlightOffer `json:"data"`
+	Data         []FlightOffer `json:"data"`
+	Dictionaries *Dictionaries `json:"dictionaries,omitempty"`
+	Warnings     Warnings      `json:"warnings,omitempty"`
+}
+
+// Dictionaries maps the codes used in a flight response to names and details
+type Dictionaries struct {
+	Locations  map[string]DictionaryLocation `json:"locations,omitempty"`
+	Aircraft   map[string]string             `json:"aircraft,omitempty"`
+	Currencies map[string]string             `json:"currencies,omitempty"`
+	Carriers   map[string]string             `json:"carriers,omitempty"`
+}
+
+type DictionaryLocation struct {
+	CityCode    string `json:"cityCode"`
+	CountryCode string `json:"countryCode"`
+}
+
+// CarrierName returns the name of an airline, or "" if the response did not include it
+func (d *Dictionaries) CarrierName(code string) string {
+	if d == nil {
+		return ""
+	}
+	return d.Carriers[code]
+}
+
+// enrichLocation fills in the city and country of an airport location from the dictionary
+func (d *Dictionaries) enrichLocation(l *pb.Location, iata string) {
+	if d == nil || l == nil {
+		return
+	}
+	entry, ok := d.Locations[iata]
+	if !ok {
+		return
+	}
+	if l.CityCode == "" {
+		l.CityCode = entry.CityCode
+	}
+	if l.Country == "" {
+		l.Country = entry.CountryCode
+	}
 }
 
 type FlightOffer struct {
@@ -94,11 +139,12 @@ type FareDetails struct {
 }
 
 type TravelerPricing struct {
-	TravelerID   string        `json:"travelerId"`
-	FareOption   string        `json:"fareOption"`
-	TravelerType string        `json:"travelerType"`
-	Price        Price         `json:"price"`
-	FareDetails  []FareDetails `json:"fareDetailsBySegment,omitempty"`
+	TravelerID        string        `json:"travelerId"`
+	FareOption        string        `json:"fareOption"`
+	TravelerType      string        `json:"travelerType"`
+	AssociatedAdultID string        `json:"associatedAdultId,omitempty"` // The traveler a HELD_INFANT sits with
+	Price             Price         `json:"price"`
+	FareDetails       []FareDetails `json:"fareDetailsBySegment,omitempty"`
 }
 
 // --- Structs for Flight Price Confirmation ---
@@ -191,6 +237,9 @@ type TicketingAgreement struct {
 	Delay  string `json:"delay"`
 }
 
+// ErrOrderNotFound is returned when the provider has no order with the requested ID
+var ErrOrderNotFound = errors.New("order not found")
+
 type FlightOrderResponse struct {
 	Data struct {
 		Type              string             `json:"type"`
@@ -199,7 +248,41 @@ type FlightOrderResponse struct {
 		AssociatedRecords []AssociatedRecord `json:"associatedRecords"`
 		FlightOffers      []FlightOffer      `json:"flightOffers"`
 		Travelers         []TravelerInfo     `json:"travelers"`
+		Tickets           []Ticket           `json:"tickets,omitempty"`
 	} `json:"data"`
+	Warnings Warnings `json:"warnings,omitempty"`
+	// TravelerUserIDs maps the order's traveler IDs to our user IDs; set by BookFlight
+	TravelerUserIDs map[string]int64 `json:"-"`
+	// BookingID is the stored booking tracking this order; set by BookFlight when the
+	// client has a database
+	BookingID uint `json:"-"`
+}
+
+// Ticket is a travel document issued for an order
+type Ticket struct {
+	DocumentType   string   `json:"documentType"`
+	DocumentNumber string   `json:"documentNumber"`
+	DocumentStatus string   `json:"documentStatus"`
+	TravelerId     string   `json:"travelerId"`
+	SegmentIds     []string `json:"segmentIds"`
+}
+
+// Ticketed reports whether the order has an issued ticket
+func (r *FlightOrderResponse) Ticketed() bool {
+	for _, t := range r.Data.Tickets {
+		if t.DocumentStatus == "" || t.DocumentStatus == "ISSUED" {
+			return true
+		}
+	}
+	return false
+}
+
+// BookedFlight returns the flight of the order's first offer, or nil if it has none
+func (r *FlightOrderResponse) BookedFlight() *pb.Flight {
+	if len(r.Data.FlightOffers) == 0 {
+		return nil
+	}
+	return r.Data.FlightOffers[0].ToTransport(nil).GetFlight()
 }
 
 type AssociatedRecord struct {
@@ -211,6 +294,30 @@ type AssociatedRecord struct {
 
 // --- Methods ---
 
+// withinStops reports whether the offer's itineraries all have at most maxStops stops
+func withinStops(offer FlightOffer, maxStops int) bool {
+	longest := 0
+	for _, it := range offer.Itineraries {
+		longest = max(longest, len(it.Segments))
+	}
+	return longest <= maxStops+1
+}
+
+// arrivingBy reports whether the offer's outbound itinerary lands no later than
+// arrivalBy. Arrival times are local and compared as written; an offer whose arrival
+// cannot be read is kept.
+func arrivingBy(offer FlightOffer, arrivalBy time.Time) bool {
+	if len(offer.Itineraries) > 0 {
+		if segments := offer.Itineraries[0].Segments; len(segments) > 0 {
+			arrival, err := time.Parse("2006-01-02T15:04:05", segments[len(segments)-1].Arrival.At)
+			if err == nil && arrival.After(arrivalBy) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 // SearchFlights searches for flight offers
 // INVARIANTS (see docs/INVARIANTS.md):
 //   - transport.OriginLocation and transport.DestinationLocation are non-nil and enriched
@@ -273,14 +380,32 @@ func (c *Client) SearchFlights(ctx context.Context, transport *pb.Transport) ([]
 		if classStr != "" {
 			endpoint += fmt.Sprintf("&travelClass=%s", classStr)
 		}
+
+		// Budget cap per traveller, in whole units of the request currency
+		if maxPrice := transport.FlightPreferences.MaxPrice; maxPrice > 0 {
+			endpoint += fmt.Sprintf("&maxPrice=%d", int(math.Ceil(maxPrice)))
+		}
+
+		// Non-stop flights are asked for; other stop caps are applied to the offers
+		if maxStops := transport.FlightPreferences.MaxStops; maxStops != nil && *maxStops == 0 {
+			endpoint += "&nonStop=true"
+		}
 	}
 
-	// Optimization: If arrivalBy is set, maybe we can pass it as a filter?
-	// API doesn't seem to support arrivalBy filter directly in V2 GET.
-	// We will handle filtering in the upper layer or just ignore for now in the raw plugin call.
+	// The GET endpoint has no arrival filter, so a stop cap above zero and an arrival
+	// bound are applied to the decoded offers. They key the cache along with the query.
+	maxStops, arrivalBy := -1, ""
+	if prefs := transport.FlightPreferences; prefs != nil {
+		if prefs.MaxStops != nil && *prefs.MaxStops > 0 {
+			maxStops = int(*prefs.MaxStops)
+		}
+		if prefs.ArrivalBy != nil {
+			arrivalBy = prefs.ArrivalBy.AsTime().Format("2006-01-02T15:04:05")
+		}
+	}
 
 	// Check cache
-	cacheKey := GenerateCacheKey("flights", endpoint)
+	cacheKey := GenerateCacheKey("flights", endpoint, maxStops, arrivalBy)
 
 	// Try DB Cache first if available
 	if c.DB != nil {
@@ -289,6 +414,7 @@ func (c *Client) SearchFlights(ctx context.Context, transport *pb.Transport) ([]
 			// Unmarshal
 			var cachedTransports []*pb.Transport
 			if err := json.Unmarshal(entry.Value, &cachedTransports); err == nil {
+				tmcontext.RequestStatsFromContext(ctx).AddCacheLookup("flight", true)
 				return cachedTransports, nil
 			}
 		}
@@ -297,8 +423,11 @@ func (c *Client) SearchFlights(ctx context.Context, transport *pb.Transport) ([]
 	// Fallback to memory cache
 	if val, ok := c.Cache.Get(cacheKey); ok {
 		log.Debugf(ctx, "SearchFlights: Cache hit for %s", endpoint)
-		return val.([]*pb.Transport), nil
+		tmcontext.RequestStatsFromContext(ctx).AddCacheLookup("flight", true)
+		// Checks and ranking tag and annotate the options, so callers get copies
+		return cloneTransports(val.([]*pb.Transport)), nil
 	}
+	tmcontext.RequestStatsFromContext(ctx).AddCacheLookup("flight", false)
 
 	log.Debugf(ctx, "SearchFlights: Requesting %s", endpoint)
 
@@ -331,18 +460,40 @@ func (c *Client) SearchFlights(ctx context.Context, transport *pb.Transport) ([]
 	}
 
 	var transports []*pb.Transport
-	limit := c.Config.FlightLimit
+	limit, _ := c.Limits()
 	if limit <= 0 {
 		limit = 10 // Default
 	}
 
+	// The limit counts only the offers within the stop cap and arrival bound. byData[i]
+	// is the option made from data[i], or nil if the offer was left out, so warnings
+	// about an offer still reach its option.
+	byData := make([]*pb.Transport, len(searchResp.Data))
+	var offers []FlightOffer
+	considered, late := 0, 0
 	for i, offer := range searchResp.Data {
-		if i >= limit {
-			break
+		if maxStops > 0 && !withinStops(offer, maxStops) {
+			continue
 		}
-		transports = append(transports, offer.ToTransport())
+		considered++
+		if arrivalBy != "" && !arrivingBy(offer, transport.FlightPreferences.ArrivalBy.AsTime()) {
+			late++
+			continue
+		}
+		if len(transports) < limit {
+			byData[i] = offer.ToTransport(searchResp.Dictionaries)
+			transports = append(transports, byData[i])
+			offers = append(offers, offer)
+		}
+	}
+	if arrivalBy != "" {
+		log.Infof(ctx, "SearchFlights: filtered out %d of %d offers arriving after %s", late, considered, arrivalBy)
 	}
 
+	// Provider notes go with the options, so they are cached along with them
+	recordWarnings(ctx, "SearchFlights", searchResp.Warnings)
+	noteFlightWarnings(searchResp.Warnings, byData)
+
 	// Enrich transport locations from input transport and populate ancillary baggage pricing
 	// INVARIANT: transport locations are non-nil and enriched
 	for i, t := range transports {
@@ -355,12 +506,14 @@ func (c *Client) SearchFlights(ctx context.Context, transport *pb.Transport) ([]
 		enrichLocationFrom(t.OriginLocation, transport.OriginLocation)
 		enrichLocationFrom(t.DestinationLocation, transport.DestinationLocation)
 
-		// Copy flight preferences from input transport
-		t.FlightPreferences = transport.FlightPreferences
+		// Copy flight preferences from input transport; the caller's stay its own
+		if transport.FlightPreferences != nil {
+			t.FlightPreferences = proto.Clone(transport.FlightPreferences).(*pb.FlightPreferences)
+		}
 
 		// Populate ancillary baggage pricing if user needs more bags than included
-		if i < len(searchResp.Data) {
-			if err := c.PopulateAncillaryBaggagePricing(ctx, t, searchResp.Data[i]); err != nil {
+		if i < len(offers) {
+			if err := c.PopulateAncillaryBaggagePricing(ctx, t, offers[i]); err != nil {
 				log.Warnf(ctx, "SearchFlights: Failed to populate ancillary baggage pricing: %v", err)
 				// Continue anyway, just log the warning
 			}
@@ -369,7 +522,7 @@ func (c *Client) SearchFlights(ctx context.Context, transport *pb.Transport) ([]
 
 	// Set cache
 	ttl := time.Duration(c.Config.CacheTTL.Flight) * time.Hour
-	c.Cache.Set(cacheKey, transports, ttl)
+	c.Cache.Set(cacheKey, cloneTransports(transports), ttl)
 
 	// Persist to DB if available
 	if c.DB != nil {
@@ -382,6 +535,15 @@ func (c *Client) SearchFlights(ctx context.Context, transport *pb.Transport) ([]
 	return transports, nil
 }
 
+// cloneTransports deep-copies a list of flight options
+func cloneTransports(transports []*pb.Transport) []*pb.Transport {
+	clones := make([]*pb.Transport, len(transports))
+	for i, t := range transports {
+		clones[i] = proto.Clone(t).(*pb.Transport)
+	}
+	return clones
+}
+
 // ConfirmPrice confirms the price of a selected flight offer
 func (c *Client) ConfirmPrice(ctx context.Context, offer FlightOffer) (*FlightSearchResponse, error) {
 	reqBody := FlightPriceCheckRequest{}
@@ -405,56 +567,26 @@ func (c *Client) ConfirmPrice(ctx context.Context, offer FlightOffer) (*FlightSe
 		log.Errorf(ctx, "ConfirmPrice: failed to decode response: %v", err)
 		return nil, err
 	}
+	recordWarnings(ctx, "ConfirmPrice", priceResp.Warnings)
 
 	return &priceResp, nil
 }
 
 // BookFlight creates a flight order
 func (c *Client) BookFlight(ctx context.Context, offer FlightOffer, users []*pb.User) (*FlightOrderResponse, error) {
-	var travelers []TravelerInfo
-	for _, user := range users {
-		traveler := TravelerInfo{
-			ID:          fmt.Sprintf("%d", user.Id),
-			DateOfBirth: user.DateOfBirth.AsTime().Format("2006-01-02"),
-			Name: Name{
-				FirstName: getFirstName(user.FullName),
-				LastName:  getLastName(user.FullName),
-			},
-			Gender: user.Gender,
-			Contact: &Contact{
-				EmailAddress: user.Email,
-				Phones: []Phone{
-					{
-						DeviceType:         "MOBILE",
-						CountryCallingCode: "1", // TODO: Extract from phone number
-						Number:             user.Phone,
-					},
-				},
-			},
-		}
-
-		if len(user.Passports) > 0 {
-			passport := user.Passports[0]
-			traveler.Documents = append(traveler.Documents, Document{
-				DocumentType:     "PASSPORT",
-				BirthPlace:       passport.BirthPlace,
-				IssuanceLocation: passport.IssuanceLocation,
-				IssuanceDate:     passport.IssuanceDate.AsTime().Format("2006-01-02"),
-				Number:           passport.Number,
-				ExpiryDate:       passport.ExpiryDate.AsTime().Format("2006-01-02"),
-				IssuanceCountry:  passport.IssuingCountry,
-				ValidityCountry:  passport.IssuingCountry,
-				Nationality:      passport.Nationality,
-				Holder:           true,
-			})
-		}
-		travelers = append(travelers, traveler)
+	payload, err := buildTravelers(ctx, offer, users)
+	if err != nil {
+		log.Errorf(ctx, "BookFlight: %v", err)
+		return nil, err
+	}
+	if len(payload.Pricings) > 0 {
+		offer.TravelerPricings = payload.Pricings
 	}
 
 	reqBody := FlightOrderRequest{}
 	reqBody.Data.Type = "flight-order"
 	reqBody.Data.FlightOffers = []FlightOffer{offer}
-	reqBody.Data.Travelers = travelers
+	reqBody.Data.Travelers = payload.Travelers
 
 	resp, err := c.doRequest(ctx, "POST", "/v1/booking/flight-orders", reqBody)
 	if err != nil {
@@ -473,10 +605,88 @@ func (c *Client) BookFlight(ctx context.Context, offer FlightOffer, users []*pb.
 		log.Errorf(ctx, "BookFlight: failed to decode response: %v", err)
 		return nil, err
 	}
+	recordWarnings(ctx, "BookFlight", orderResp.Warnings)
+	orderResp.TravelerUserIDs = payload.UserIDs
+
+	// Store the order so its status can be tracked. The order stands even if this
+	// fails, so the failure is logged rather than returned.
+	if c.DB != nil {
+		var travelers []int64
+		for _, t := range payload.Travelers {
+			if id := payload.UserIDs[t.ID]; id != 0 {
+				travelers = append(travelers, id)
+			}
+		}
+		flight := orderResp.BookedFlight()
+		if flight == nil {
+			flight = offer.ToTransport(nil).GetFlight()
+		}
+		booking, err := orm.CreateBooking(c.DB, orderResp.Data.ID, flight, travelers)
+		if err != nil {
+			log.Errorf(ctx, "BookFlight: order %s placed but not stored: %v", orderResp.Data.ID, err)
+		} else {
+			orderResp.BookingID = booking.ID
+		}
+	}
 
 	return &orderResp, nil
 }
 
+// GetFlightOrder retrieves an order by its ID. It returns ErrOrderNotFound if the
+// provider no longer has it, e.g. because the airline cancelled it.
+func (c *Client) GetFlightOrder(ctx context.Context, orderID string) (*FlightOrderResponse, error) {
+	resp, err := c.doRequest(ctx, "GET", "/v1/booking/flight-orders/"+url.PathEscape(orderID), nil)
+	if err != nil {
+		log.Errorf(ctx, "GetFlightOrder: request failed: %v", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrOrderNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		log.Errorf(ctx, "GetFlightOrder: API returned status %s", resp.Status)
+		return nil, fmt.Errorf("order lookup failed: %s", resp.Status)
+	}
+
+	var orderResp FlightOrderResponse
+	if err := json.NewDecoder(resp.Body).Decode(&orderResp); err != nil {
+		log.Errorf(ctx, "GetFlightOrder: failed to decode response: %v", err)
+		return nil, err
+	}
+	recordWarnings(ctx, "GetFlightOrder", orderResp.Warnings)
+
+	return &orderResp, nil
+}
+
+// CancelFlightOrder cancels a flight order. It returns ErrOrderNotFound if the provider
+// no longer has the order, e.g. because it was already cancelled.
+func (c *Client) CancelFlightOrder(ctx context.Context, orderID string) error {
+	return c.cancelOrder(ctx, "CancelFlightOrder", "/v1/booking/flight-orders/"+url.PathEscape(orderID))
+}
+
+// cancelOrder deletes the order at endpoint; caller names the method for logging
+func (c *Client) cancelOrder(ctx context.Context, caller, endpoint string) error {
+	resp, err := c.doRequest(ctx, "DELETE", endpoint, nil)
+	if err != nil {
+		log.Errorf(ctx, "%s: request failed: %v", caller, err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+		log.Infof(ctx, "%s: cancelled %s", caller, endpoint)
+		return nil
+	case http.StatusNotFound:
+		return ErrOrderNotFound
+	default:
+		log.Errorf(ctx, "%s: API returned status %s", caller, resp.Status)
+		return fmt.Errorf("order cancellation failed: %s", resp.Status)
+	}
+}
+
 func getFirstName(fullName string) string {
 	// Simple split, assuming First Last
 	// In production, robust name parsing is needed
@@ -494,8 +704,9 @@ func getLastName(fullName string) string {
 	return lastName
 }
 
-// ToTransport converts a FlightOffer to a pb.Transport
-func (o FlightOffer) ToTransport() *pb.Transport {
+// ToTransport converts a FlightOffer to a pb.Transport. Carrier names and airport
+// cities are taken from the response's dictionaries, which may be nil.
+func (o FlightOffer) ToTransport(dict *Dictionaries) *pb.Transport {
 	t := &pb.Transport{
 		Type: pb.TransportType_TRANSPORT_TYPE_FLIGHT,
 		OriginLocation: &pb.Location{
@@ -525,10 +736,13 @@ func (o FlightOffer) ToTransport() *pb.Transport {
 
 		t.OriginLocation.IataCodes = append(t.OriginLocation.IataCodes, firstSeg.Departure.IataCode)
 		t.DestinationLocation.IataCodes = append(t.DestinationLocation.IataCodes, lastSeg.Arrival.IataCode)
+		dict.enrichLocation(t.OriginLocation, firstSeg.Departure.IataCode)
+		dict.enrichLocation(t.DestinationLocation, lastSeg.Arrival.IataCode)
 
 		// Carrier and Flight Number
 		flightDetails := &pb.Flight{
 			CarrierCode:  firstSeg.CarrierCode,
+			CarrierName:  dict.CarrierName(firstSeg.CarrierCode),
 			FlightNumber: firstSeg.Number,
 		}
 
@@ -541,7 +755,7 @@ func (o FlightOffer) ToTransport() *pb.Transport {
 		}
 
 		// Extract all segments and layover information
-		extractSegments(segments, flightDetails)
+		extractSegments(segments, flightDetails, dict)
 
 		// Set total journey duration if available
 		if itinerary.Duration != "" {
@@ -593,7 +807,7 @@ func extractBaggageInfo(offer FlightOffer, flight *pb.Flight) {
 }
 
 // extractSegments extracts all flight segments and calculates layover information
-func extractSegments(segments []Segment, flight *pb.Flight) {
+func extractSegments(segments []Segment, flight *pb.Flight, dict *Dictionaries) {
 	if len(segments) == 0 {
 		return
 	}
@@ -602,11 +816,14 @@ func extractSegments(segments []Segment, flight *pb.Flight) {
 	for _, seg := range segments {
 		pbSeg := &pb.FlightSegment{
 			CarrierCode:          seg.CarrierCode,
+			CarrierName:          dict.CarrierName(seg.CarrierCode),
 			FlightNumber:         seg.Number,
 			DepartureAirportCode: seg.Departure.IataCode,
 			ArrivalAirportCode:   seg.Arrival.IataCode,
 			Duration:             seg.Duration,
 			Stops:                int32(seg.NumberOfStops),
+			DepartureTerminal:    seg.Departure.Terminal,
+			ArrivalTerminal:      seg.Arrival.Terminal,
 		}
 
 		// Parse departure time
@@ -628,6 +845,21 @@ func extractSegments(segments []Segment, flight *pb.Flight) {
 	} else {
 		flight.LayoverCount = 0
 	}
+
+	// Each connection lasts from one segment's arrival to the next one's departure.
+	// Both are local times at the connecting airport, so they compare as written.
+	for i := 1; i < len(flight.Segments); i++ {
+		in, out := flight.Segments[i-1], flight.Segments[i]
+		layover := &pb.Layover{
+			AirportCode:   in.ArrivalAirportCode,
+			ArrivalTime:   in.ArrivalTime,
+			DepartureTime: out.DepartureTime,
+		}
+		if in.ArrivalTime != nil && out.DepartureTime != nil {
+			layover.DurationSeconds = int64(out.DepartureTime.AsTime().Sub(in.ArrivalTime.AsTime()).Seconds())
+		}
+		flight.Layovers = append(flight.Layovers, layover)
+	}
 }
 
 // GetIncludedBaggageCount returns the number of included checked bags
@@ -687,7 +919,7 @@ func (c *Client) GetAdditionalBaggagePrice(ctx context.Context, offer FlightOffe
 		},
 	}
 
-	log.Debugf(ctx, "GetAdditionalBaggagePrice: Requesting pricing for %d additional bags", additionalBags)
+	log.Tracef(ctx, "GetAdditionalBaggagePrice: Requesting pricing for %d additional bags", additionalBags)
 
 	resp, err := c.doRequest(ctx, "POST", "/v1/shopping/flight-offers/pricing", reqBody)
 	if err != nil {
@@ -748,11 +980,11 @@ func AddAncillaryBaggageCost(transport *pb.Transport, additionalBags int32, bagP
 func (c *Client) PopulateAncillaryBaggagePricing(ctx context.Context, transport *pb.Transport, offer FlightOffer) error {
 	additionalBags := CheckBaggageRequirements(transport)
 	if additionalBags == 0 {
-		log.Debugf(ctx, "PopulateAncillaryBaggagePricing: No additional bags needed")
+		log.Tracef(ctx, "PopulateAncillaryBaggagePricing: No additional bags needed")
 		return nil
 	}
 
-	log.Debugf(ctx, "PopulateAncillaryBaggagePricing: User needs %d additional bags", additionalBags)
+	log.Tracef(ctx, "PopulateAncillaryBaggagePricing: User needs %d additional bags", additionalBags)
 
 	// For now, we'll use a default price since the Flight Offers Price API
 	// doesn't always return detailed ancillary pricing in a consistent format.
@@ -782,13 +1014,13 @@ func (c *Client) PopulateAncillaryBaggagePricing(ctx context.Context, transport
 			extraCost := newPrice - originalPrice
 			bagPrice := extraCost / float64(additionalBags)
 			AddAncillaryBaggageCost(transport, additionalBags, bagPrice, currency)
-			log.Debugf(ctx, "PopulateAncillaryBaggagePricing: Added ancillary cost: %.2f %s per bag", bagPrice, currency)
+			log.Tracef(ctx, "PopulateAncillaryBaggagePricing: Added ancillary cost: %.2f %s per bag", bagPrice, currency)
 			return nil
 		}
 	}
 
 	// Fallback to default pricing if API didn't return additional bag cost
-	log.Debugf(ctx, "PopulateAncillaryBaggagePricing: Using default pricing (%.2f %s per bag)", defaultBagPrice, currency)
+	log.Tracef(ctx, "PopulateAncillaryBaggagePricing: Using default pricing (%.2f %s per bag)", defaultBagPrice, currency)
 	AddAncillaryBaggageCost(transport, additionalBags, defaultBagPrice, currency)
 
 	return nil
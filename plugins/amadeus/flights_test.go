@@ -0,0 +1,339 @@
+package amadeus
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestSearchFlights_Dictionaries(t *testing.T) {
+	client := newCalendarTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/security/oauth2/token":
+			writeToken(w)
+		case "/v2/shopping/flight-offers":
+			w.Write([]byte(`{
+				"data": [{
+					"id": "1",
+					"itineraries": [{"duration": "PT9H", "segments": [
+						{"departure": {"iataCode": "JFK", "at": "2030-05-10T08:00:00"}, "arrival": {"iataCode": "LHR", "at": "2030-05-10T20:00:00"}, "carrierCode": "BA", "number": "178"},
+						{"departure": {"iataCode": "LHR", "at": "2030-05-10T22:00:00"}, "arrival": {"iataCode": "LIS", "at": "2030-05-11T00:30:00"}, "carrierCode": "TP", "number": "1363"}
+					]}],
+					"price": {"currency": "USD", "total": "540.00"}
+				}],
+				"dictionaries": {
+					"locations": {
+						"JFK": {"cityCode": "NYC", "countryCode": "US"},
+						"LHR": {"cityCode": "LON", "countryCode": "GB"},
+						"LIS": {"cityCode": "LIS", "countryCode": "PT"}
+					},
+					"aircraft": {"789": "BOEING 787-9"},
+					"currencies": {"USD": "US DOLLAR"},
+					"carriers": {"BA": "BRITISH AIRWAYS", "TP": "TAP PORTUGAL"}
+				}
+			}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	transports, err := client.SearchFlights(context.Background(), &pb.Transport{
+		Type:                pb.TransportType_TRANSPORT_TYPE_FLIGHT,
+		TravelerCount:       1,
+		OriginLocation:      &pb.Location{IataCodes: []string{"JFK"}},
+		DestinationLocation: &pb.Location{IataCodes: []string{"LIS"}},
+		Cost:                &pb.Cost{Currency: "USD"},
+		Details: &pb.Transport_Flight{Flight: &pb.Flight{
+			DepartureTime: timestamppb.New(time.Now().AddDate(0, 1, 0)),
+		}},
+	})
+	if err != nil {
+		t.Fatalf("SearchFlights failed: %v", err)
+	}
+	if !assert.Len(t, transports, 1) {
+		return
+	}
+
+	tr := transports[0]
+	assert.Equal(t, "NYC", tr.OriginLocation.CityCode)
+	assert.Equal(t, "US", tr.OriginLocation.Country)
+	assert.Equal(t, "LIS", tr.DestinationLocation.CityCode)
+	assert.Equal(t, "PT", tr.DestinationLocation.Country)
+
+	f := tr.GetFlight()
+	assert.Equal(t, "BRITISH AIRWAYS", f.CarrierName)
+	if assert.Len(t, f.Segments, 2) {
+		assert.Equal(t, "BRITISH AIRWAYS", f.Segments[0].CarrierName)
+		assert.Equal(t, "TAP PORTUGAL", f.Segments[1].CarrierName)
+	}
+}
+
+func TestFlightOffer_ToTransport_NoDictionaries(t *testing.T) {
+	offer := FlightOffer{Itineraries: []Itinerary{{Segments: []Segment{{
+		Departure:   FlightEndPoint{IataCode: "JFK"},
+		Arrival:     FlightEndPoint{IataCode: "LHR"},
+		CarrierCode: "BA",
+	}}}}}
+
+	tr := offer.ToTransport(nil)
+
+	assert.Equal(t, "BA", tr.GetFlight().CarrierCode)
+	assert.Empty(t, tr.GetFlight().CarrierName)
+	assert.Empty(t, tr.OriginLocation.CityCode)
+}
+
+func TestFlightOffer_ToTransport_Layovers(t *testing.T) {
+	// JFK to BKK with two stops, at LHR and DXB
+	offer := FlightOffer{Itineraries: []Itinerary{{Segments: []Segment{
+		{
+			Departure:   FlightEndPoint{IataCode: "JFK", At: "2030-05-10T18:00:00"},
+			Arrival:     FlightEndPoint{IataCode: "LHR", At: "2030-05-11T06:10:00"},
+			CarrierCode: "BA", Number: "112",
+		},
+		{
+			Departure:   FlightEndPoint{IataCode: "LHR", At: "2030-05-11T08:45:00"},
+			Arrival:     FlightEndPoint{IataCode: "DXB", At: "2030-05-11T19:30:00"},
+			CarrierCode: "EK", Number: "2",
+		},
+		{
+			Departure:   FlightEndPoint{IataCode: "DXB", At: "2030-05-12T02:40:00"},
+			Arrival:     FlightEndPoint{IataCode: "BKK", At: "2030-05-12T12:00:00"},
+			CarrierCode: "EK", Number: "372",
+		},
+	}}}}
+
+	flight := offer.ToTransport(nil).GetFlight()
+
+	assert.Equal(t, int32(2), flight.LayoverCount)
+	if assert.Len(t, flight.Layovers, 2) {
+		lhr, dxb := flight.Layovers[0], flight.Layovers[1]
+		assert.Equal(t, "LHR", lhr.AirportCode)
+		assert.Equal(t, time.Date(2030, 5, 11, 6, 10, 0, 0, time.UTC), lhr.ArrivalTime.AsTime())
+		assert.Equal(t, time.Date(2030, 5, 11, 8, 45, 0, 0, time.UTC), lhr.DepartureTime.AsTime())
+		assert.Equal(t, int64((2*time.Hour + 35*time.Minute).Seconds()), lhr.DurationSeconds)
+
+		// An overnight connection
+		assert.Equal(t, "DXB", dxb.AirportCode)
+		assert.Equal(t, int64((7*time.Hour + 10*time.Minute).Seconds()), dxb.DurationSeconds)
+	}
+
+	// A connection with an unknown time has no duration
+	offer.Itineraries[0].Segments[1].Departure.At = ""
+	flight = offer.ToTransport(nil).GetFlight()
+	if assert.Len(t, flight.Layovers, 2) {
+		assert.Nil(t, flight.Layovers[0].DepartureTime)
+		assert.Zero(t, flight.Layovers[0].DurationSeconds)
+	}
+
+	// A direct flight has none
+	direct := FlightOffer{Itineraries: []Itinerary{{Segments: offer.Itineraries[0].Segments[:1]}}}
+	assert.Empty(t, direct.ToTransport(nil).GetFlight().Layovers)
+}
+
+func TestSearchFlights_MaxStops(t *testing.T) {
+	// offer has one itinerary per entry in legs, each of that many segments; the price
+	// tells the offers apart
+	offer := func(price string, legs ...int) string {
+		var its []string
+		for _, n := range legs {
+			segs := make([]string, n)
+			for i := range segs {
+				segs[i] = `{"departure": {"iataCode": "JFK"}, "arrival": {"iataCode": "LIS"}, "carrierCode": "TP"}`
+			}
+			its = append(its, `{"segments": [`+strings.Join(segs, ",")+`]}`)
+		}
+		return `{"itineraries": [` + strings.Join(its, ",") + `], "price": {"currency": "USD", "total": "` + price + `"}}`
+	}
+	offers := []string{
+		offer("100", 1),    // direct
+		offer("200", 2),    // one stop
+		offer("300", 3),    // two stops
+		offer("400", 2, 2), // one stop each way
+		offer("500", 1, 3), // direct out, two stops back
+		offer("600", 1, 1), // direct both ways
+	}
+
+	search := func(t *testing.T, prefs *pb.FlightPreferences, limit int) (string, []float64) {
+		var query string
+		client := newCalendarTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/v1/security/oauth2/token":
+				writeToken(w)
+			case "/v2/shopping/flight-offers":
+				query = r.URL.RawQuery
+				w.Write([]byte(`{"data": [` + strings.Join(offers, ",") + `]}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		})
+		client.SetLimits(limit, 10)
+
+		transports, err := client.SearchFlights(context.Background(), &pb.Transport{
+			Type:                pb.TransportType_TRANSPORT_TYPE_FLIGHT,
+			TravelerCount:       1,
+			OriginLocation:      &pb.Location{IataCodes: []string{"JFK"}},
+			DestinationLocation: &pb.Location{IataCodes: []string{"LIS"}},
+			Cost:                &pb.Cost{Currency: "USD"},
+			FlightPreferences:   prefs,
+			Details: &pb.Transport_Flight{Flight: &pb.Flight{
+				DepartureTime: timestamppb.New(time.Now().AddDate(0, 1, 0)),
+			}},
+		})
+		if err != nil {
+			t.Fatalf("SearchFlights failed: %v", err)
+		}
+		var prices []float64
+		for _, tr := range transports {
+			prices = append(prices, tr.Cost.Value)
+		}
+		return query, prices
+	}
+	stops := func(n int32) *pb.FlightPreferences { return &pb.FlightPreferences{MaxStops: &n} }
+
+	t.Run("NonStop", func(t *testing.T) {
+		query, _ := search(t, stops(0), 10)
+		assert.Contains(t, query, "nonStop=true")
+	})
+
+	t.Run("Unset", func(t *testing.T) {
+		query, prices := search(t, &pb.FlightPreferences{}, 10)
+		assert.NotContains(t, query, "nonStop")
+		assert.Len(t, prices, len(offers))
+	})
+
+	t.Run("OneStop", func(t *testing.T) {
+		query, prices := search(t, stops(1), 10)
+		assert.NotContains(t, query, "nonStop")
+		assert.Equal(t, []float64{100, 200, 400, 600}, prices)
+	})
+
+	t.Run("LimitAfterFilter", func(t *testing.T) {
+		_, prices := search(t, stops(1), 3)
+		assert.Equal(t, []float64{100, 200, 400}, prices)
+	})
+}
+
+func TestSearchFlights_ArrivalBy(t *testing.T) {
+	// leg lands at each of arrivals in turn; the price tells the offers apart
+	leg := func(arrivals ...string) string {
+		segs := make([]string, len(arrivals))
+		for i, at := range arrivals {
+			segs[i] = `{"departure": {"iataCode": "JFK"}, "arrival": {"iataCode": "LIS", "at": "` + at + `"}, "carrierCode": "TP"}`
+		}
+		return `{"segments": [` + strings.Join(segs, ",") + `]}`
+	}
+	offer := func(price string, legs ...string) string {
+		return `{"itineraries": [` + strings.Join(legs, ",") + `], "price": {"currency": "USD", "total": "` + price + `"}}`
+	}
+	offers := []string{
+		offer("100", leg("2030-05-10T08:00:00")),                             // early
+		offer("200", leg("2030-05-10T11:30:00")),                             // late
+		offer("300", leg("2030-05-10T09:00:00", "2030-05-10T13:00:00")),      // connects early, lands late
+		offer("400", leg("")),                                                // arrival unknown
+		offer("500", leg("2030-05-10T10:00:00"), leg("2030-05-17T22:00:00")), // return lands later
+		offer("600", leg("2030-05-09T23:00:00"), leg("2030-05-17T06:00:00")), // the night before
+	}
+
+	var calls int
+	client := newCalendarTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/security/oauth2/token":
+			writeToken(w)
+		case "/v2/shopping/flight-offers":
+			calls++
+			w.Write([]byte(`{"data": [` + strings.Join(offers, ",") + `]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	search := func(t *testing.T, prefs *pb.FlightPreferences) []float64 {
+		transports, err := client.SearchFlights(context.Background(), &pb.Transport{
+			Type:                pb.TransportType_TRANSPORT_TYPE_FLIGHT,
+			TravelerCount:       1,
+			OriginLocation:      &pb.Location{IataCodes: []string{"JFK"}},
+			DestinationLocation: &pb.Location{IataCodes: []string{"LIS"}},
+			Cost:                &pb.Cost{Currency: "USD"},
+			FlightPreferences:   prefs,
+			Details: &pb.Transport_Flight{Flight: &pb.Flight{
+				DepartureTime: timestamppb.New(time.Date(2030, 5, 9, 0, 0, 0, 0, time.UTC)),
+			}},
+		})
+		if err != nil {
+			t.Fatalf("SearchFlights failed: %v", err)
+		}
+		var prices []float64
+		for _, tr := range transports {
+			prices = append(prices, tr.Cost.Value)
+		}
+		return prices
+	}
+	by := timestamppb.New(time.Date(2030, 5, 10, 10, 0, 0, 0, time.UTC))
+
+	// Offers landing after the bound are dropped; landing on it is in time
+	assert.Equal(t, []float64{100, 400, 500, 600}, search(t, &pb.FlightPreferences{ArrivalBy: by}))
+
+	// The filtered result is cached apart from the unfiltered one
+	assert.Len(t, search(t, &pb.FlightPreferences{}), len(offers))
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, []float64{100, 400, 500, 600}, search(t, &pb.FlightPreferences{ArrivalBy: by}))
+	assert.Equal(t, 2, calls)
+}
+
+func TestSearchFlights_CachedCopies(t *testing.T) {
+	var searches int
+	client := newCalendarTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/security/oauth2/token":
+			writeToken(w)
+		case "/v2/shopping/flight-offers":
+			searches++
+			w.Write([]byte(`{"data": [{"itineraries": [{"segments": [{"departure": {"iataCode": "JFK"}, "arrival": {"iataCode": "LIS"}, "carrierCode": "TP"}]}], "price": {"currency": "USD", "total": "100"}}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	prefs := &pb.FlightPreferences{TravelClass: pb.Class_CLASS_ECONOMY}
+	transport := &pb.Transport{
+		Type:                pb.TransportType_TRANSPORT_TYPE_FLIGHT,
+		TravelerCount:       1,
+		OriginLocation:      &pb.Location{IataCodes: []string{"JFK"}},
+		DestinationLocation: &pb.Location{IataCodes: []string{"LIS"}},
+		Cost:                &pb.Cost{Currency: "USD"},
+		FlightPreferences:   prefs,
+		Details: &pb.Transport_Flight{Flight: &pb.Flight{
+			DepartureTime: timestamppb.New(time.Now().AddDate(0, 1, 0)),
+		}},
+	}
+	first, err := client.SearchFlights(context.Background(), transport)
+	if !assert.NoError(t, err) || !assert.Len(t, first, 1) {
+		return
+	}
+	assert.NotSame(t, prefs, first[0].FlightPreferences)
+
+	// What one request's checks and ranking write on its options stays with it
+	first[0].Tags = append(first[0].Tags, "Tight connection")
+	first[0].Error = &pb.Error{Message: "Tight connection at LIS"}
+	first[0].FlightPreferences.MaxPrice = 50
+
+	again, err := client.SearchFlights(context.Background(), transport)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, searches)
+	if assert.Len(t, again, 1) {
+		assert.Empty(t, again[0].Tags)
+		assert.Nil(t, again[0].Error)
+		assert.Zero(t, again[0].FlightPreferences.MaxPrice)
+	}
+	assert.Zero(t, prefs.MaxPrice)
+}
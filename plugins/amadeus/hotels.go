@@ -4,20 +4,28 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
+	"net/url"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
+	tmcontext "github.com/va6996/travelingman/context"
 	"github.com/va6996/travelingman/log"
 	"github.com/va6996/travelingman/orm"
 	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // --- Structs for Hotel Search ---
 
 type HotelSearchResponse struct {
-	Data []HotelOfferData `json:"data"`
+	Data     []HotelOfferData `json:"data"`
+	Warnings Warnings         `json:"warnings,omitempty"`
 }
 
 type HotelOfferData struct {
@@ -48,11 +56,12 @@ type HotelOffer struct {
 		Code string `json:"code"`
 		Type string `json:"type"`
 	} `json:"rateFamilyEstimated"`
-	Room     HotelRoom     `json:"room"`
-	Guests   HotelGuests   `json:"guests"`
-	Price    HotelPrice    `json:"price"`
-	Policies HotelPolicies `json:"policies"`
-	Self     string        `json:"self"`
+	BoardType string        `json:"boardType"` // Meals included, e.g. ROOM_ONLY or BREAKFAST
+	Room      HotelRoom     `json:"room"`
+	Guests    HotelGuests   `json:"guests"`
+	Price     HotelPrice    `json:"price"`
+	Policies  HotelPolicies `json:"policies"`
+	Self      string        `json:"self"`
 }
 
 type HotelRoom struct {
@@ -69,7 +78,8 @@ type HotelRoom struct {
 }
 
 type HotelGuests struct {
-	Adults int `json:"adults"`
+	Adults    int   `json:"adults"`
+	ChildAges []int `json:"childAges,omitempty"`
 }
 
 type HotelPrice struct {
@@ -83,22 +93,55 @@ type HotelPrice struct {
 	} `json:"variations"`
 }
 
+// includesBreakfast reports whether the offer's board type covers breakfast
+func (o HotelOffer) includesBreakfast() bool {
+	switch o.BoardType {
+	case "BREAKFAST", "HALF_BOARD", "FULL_BOARD", "ALL_INCLUSIVE":
+		return true
+	}
+	return false
+}
+
+// nightly is the price per night: the average Amadeus reports, or else the total
+// spread over the nights between check-in and check-out
+func (p HotelPrice) nightly(acc *pb.Accommodation) *pb.Cost {
+	if avg, err := strconv.ParseFloat(p.Variations.Average.Base, 64); err == nil && avg > 0 {
+		return &pb.Cost{Value: avg, Currency: p.Currency}
+	}
+	if acc.Cost == nil || acc.CheckIn == nil || acc.CheckOut == nil {
+		return nil
+	}
+	nights := int(math.Round(acc.CheckOut.AsTime().Sub(acc.CheckIn.AsTime()).Hours() / 24))
+	if nights <= 0 {
+		return nil
+	}
+	return &pb.Cost{Value: math.Round(acc.Cost.Value/float64(nights)*100) / 100, Currency: acc.Cost.Currency}
+}
+
 type HotelPolicies struct {
 	BoookingHoldPolicy struct {
 		Deadline string `json:"deadline"`
 	} `json:"bookingHoldPolicy"`
 	Guarantee struct {
-		AcceptedPayments struct {
-			CreditCards []string `json:"creditCards"`
-			Methods     []string `json:"methods"`
-		} `json:"acceptedPayments"`
+		AcceptedPayments AcceptedPayments `json:"acceptedPayments"`
 	} `json:"guarantee"`
-	PaymentType  string `json:"paymentType"`
+	Deposit struct {
+		Amount           string           `json:"amount"`
+		Deadline         string           `json:"deadline"`
+		AcceptedPayments AcceptedPayments `json:"acceptedPayments"`
+	} `json:"deposit"`
+	PaymentType  string `json:"paymentType"` // guarantee, deposit or prepay
 	Cancellation struct {
 		Deadline string `json:"deadline"`
 	} `json:"cancellation"`
 }
 
+// AcceptedPayments lists how a rate can be paid; empty lists accept anything
+type AcceptedPayments struct {
+	CreditCards []string `json:"creditCards"` // Vendor codes, e.g. VI, AX
+	Methods     []string `json:"methods"`     // e.g. CREDIT_CARD
+}
+
 // --- Structs for Hotel Booking ---
 
 type HotelOrderRequest struct {
@@ -146,12 +189,45 @@ type TravelAgent struct {
 	} `json:"contact"`
 }
 
+// HotelOfferResponse is the response from /v3/shopping/hotel-offers/{offerId}
+type HotelOfferResponse struct {
+	Data     HotelOfferData `json:"data"`
+	Warnings Warnings       `json:"warnings,omitempty"`
+}
+
 type HotelOrderResponse struct {
 	Data []struct {
 		Type string `json:"type"`
 		ID   string `json:"id"`
 		// Other fields omitted for brevity
 	} `json:"data"`
+	Warnings Warnings `json:"warnings,omitempty"`
+}
+
+// HotelOrder is a placed hotel order as GetHotelOrder returns it
+type HotelOrder struct {
+	Data struct {
+		Type          string `json:"type"`
+		ID            string `json:"id"`
+		HotelBookings []struct {
+			ID            string `json:"id"`
+			BookingStatus string `json:"bookingStatus"` // CONFIRMED, PENDING or CANCELLED
+		} `json:"hotelBookings"`
+	} `json:"data"`
+	Warnings Warnings `json:"warnings,omitempty"`
+}
+
+// Cancelled reports whether every booking of the order was cancelled
+func (o *HotelOrder) Cancelled() bool {
+	if len(o.Data.HotelBookings) == 0 {
+		return false
+	}
+	for _, b := range o.Data.HotelBookings {
+		if b.BookingStatus != "CANCELLED" {
+			return false
+		}
+	}
+	return true
 }
 
 // --- Methods ---
@@ -163,6 +239,7 @@ type HotelData struct {
 	DupeId    int    `json:"dupeId"`
 	Name      string `json:"name"`
 	HotelId   string `json:"hotelId"`
+	Rating    int    `json:"rating"` // Stars, 0 if the hotel is unrated
 	GeoCode   struct {
 		Latitude  float64 `json:"latitude"`
 		Longitude float64 `json:"longitude"`
@@ -185,55 +262,75 @@ func (c *Client) SearchHotelsByCity(ctx context.Context, acc *pb.Accommodation)
 
 	// Step 1: Get list of hotels in city
 	endpoint := fmt.Sprintf("/v1/reference-data/locations/hotels/by-city?cityCode=%s", cityCode)
+	endpoint += hotelListFilters(ctx, acc.Preferences)
+	return c.listHotels(ctx, "SearchHotelsByCity", endpoint)
+}
 
-	if acc.Preferences != nil {
-		if acc.Preferences.Rating > 0 {
-			endpoint += fmt.Sprintf("&ratings=%d", acc.Preferences.Rating)
+// SearchHotelsByGeocode searches for hotels within radiusKm of a point, with the
+// stay's rating and amenity filters
+func (c *Client) SearchHotelsByGeocode(ctx context.Context, acc *pb.Accommodation, lat, lng float64, radiusKm int) (*HotelListResponse, error) {
+	endpoint := fmt.Sprintf("/v1/reference-data/locations/hotels/by-geocode?latitude=%f&longitude=%f&radius=%d&radiusUnit=KM", lat, lng, radiusKm)
+	endpoint += hotelListFilters(ctx, acc.Preferences)
+	return c.listHotels(ctx, "SearchHotelsByGeocode", endpoint)
+}
+
+// hotelListFilters returns the query parameters for the rating and amenity preferences.
+// Amenities are normalized to Amadeus codes; unknown ones are dropped with a warning,
+// as Amadeus would otherwise silently match no hotel.
+func hotelListFilters(ctx context.Context, prefs *pb.AccommodationPreferences) string {
+	var filters string
+	if prefs != nil {
+		if prefs.Rating > 0 {
+			filters += fmt.Sprintf("&ratings=%d", prefs.Rating)
 		}
 
+		codes, unknown := NormalizeAmenities(prefs.Amenities)
+		if len(unknown) > 0 {
+			log.Warnf(ctx, "hotelListFilters: Ignoring amenities Amadeus does not know: %s", strings.Join(unknown, ", "))
+		}
 		// Amenities is comma separated list
-		if len(acc.Preferences.Amenities) > 0 {
-			// simple join
-			joined := ""
-			for i, a := range acc.Preferences.Amenities {
-				if i > 0 {
-					joined += ","
-				}
-				joined += a
+		if len(codes) > 0 {
+			for i, code := range codes {
+				codes[i] = url.QueryEscape(code)
 			}
-			endpoint += fmt.Sprintf("&amenities=%s", joined)
+			filters += fmt.Sprintf("&amenities=%s", strings.Join(codes, ","))
 		}
 	}
+	return filters
+}
 
+// listHotels requests a hotel list endpoint, logging failures as caller
+func (c *Client) listHotels(ctx context.Context, caller, endpoint string) (*HotelListResponse, error) {
 	resp, err := c.doRequest(ctx, "GET", endpoint, nil)
 	if err != nil {
-		log.Errorf(ctx, "SearchHotelsByCity: request failed: %v", err)
+		log.Errorf(ctx, "%s: request failed: %v", caller, err)
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		log.Errorf(ctx, "SearchHotelsByCity: API returned status %s", resp.Status)
-		// Log body for debugging
+		log.Errorf(ctx, "%s: API returned status %s", caller, resp.Status)
 		return nil, fmt.Errorf("hotel list search failed: %s", resp.Status)
 	}
 
 	var listResp HotelListResponse
 	if err := json.NewDecoder(resp.Body).Decode(&listResp); err != nil {
-		log.Errorf(ctx, "SearchHotelsByCity: failed to decode response: %v", err)
+		log.Errorf(ctx, "%s: failed to decode response: %v", caller, err)
 		return nil, err
 	}
 
 	return &listResp, nil
 }
 
-// SearchHotelOffers searches for hotel offers and returns them as pb.Accommodation structs
+// SearchHotelOffers searches for hotel offers and returns them as pb.Accommodation structs.
+// No offers and no error means the hotels have no rooms on the requested dates.
 func (c *Client) SearchHotelOffers(ctx context.Context, hotelIds []string, acc *pb.Accommodation) ([]*pb.Accommodation, error) {
 	// Extract parameters from accommodation object
 	// INVARIANT 3: Accommodation is non-nil
 	// INVARIANT 7: TravelerCount is positive
 
-	adults := int(acc.TravelerCount)
+	// Children are counted among the travelers; the room still needs an adult
+	adults := max(int(acc.TravelerCount)-len(acc.ChildAges), 1)
 
 	// INVARIANT 6: CheckIn and CheckOut are non-nil and valid
 	checkIn := acc.CheckIn.AsTime().Format("2006-01-02")
@@ -246,6 +343,7 @@ func (c *Client) SearchHotelOffers(ctx context.Context, hotelIds []string, acc *
 	// We chunk them to be safe (e.g., 20).
 	const chunkSize = 20
 	var accommodations []*pb.Accommodation
+	failed := 0
 
 	// Chunk the hotel IDs
 	for i := 0; i < len(hotelIds); i += chunkSize {
@@ -267,11 +365,26 @@ func (c *Client) SearchHotelOffers(ctx context.Context, hotelIds []string, acc *
 
 		endpoint := fmt.Sprintf("/v3/shopping/hotel-offers?hotelIds=%s&adults=%d&checkInDate=%s&checkOutDate=%s",
 			ids, adults, checkIn, checkOut)
+		if len(acc.ChildAges) > 0 {
+			ages := make([]string, len(acc.ChildAges))
+			for j, age := range acc.ChildAges {
+				ages[j] = strconv.Itoa(int(age))
+			}
+			endpoint += "&childAges=" + strings.Join(ages, ",")
+		}
 
 		if currency != "" {
 			endpoint += fmt.Sprintf("&currency=%s", currency)
+
+			// Budget cap per night; the API only takes a price range together with a currency
+			if maxNightly := acc.GetPreferences().GetMaxNightlyPrice(); maxNightly > 0 {
+				endpoint += fmt.Sprintf("&priceRange=-%d", int(math.Ceil(maxNightly)))
+			}
 		}
 
+		// The API defaults to one best rate per hotel; ask for all rooms so users can choose
+		endpoint += fmt.Sprintf("&bestRateOnly=%t", c.Config.HotelOffers.BestRateOnly)
+
 		// Check cache
 		cacheKey := GenerateCacheKey("hotel_offers", endpoint)
 
@@ -283,6 +396,7 @@ func (c *Client) SearchHotelOffers(ctx context.Context, hotelIds []string, acc *
 				var cachedBatch []*pb.Accommodation
 				if err := json.Unmarshal(entry.Value, &cachedBatch); err == nil {
 					accommodations = append(accommodations, cachedBatch...)
+					tmcontext.RequestStatsFromContext(ctx).AddCacheLookup("hotel", true)
 					continue
 				}
 			}
@@ -290,15 +404,19 @@ func (c *Client) SearchHotelOffers(ctx context.Context, hotelIds []string, acc *
 
 		if val, ok := c.Cache.Get(cacheKey); ok {
 			log.Debugf(ctx, "SearchHotelOffers: Cache hit for batch %d", (i/chunkSize)+1)
-			accommodations = append(accommodations, val.([]*pb.Accommodation)...)
+			// Grouping marks the offers, so it works on copies of the cached ones
+			accommodations = append(accommodations, cloneAccommodations(val.([]*pb.Accommodation))...)
+			tmcontext.RequestStatsFromContext(ctx).AddCacheLookup("hotel", true)
 			continue
 		}
+		tmcontext.RequestStatsFromContext(ctx).AddCacheLookup("hotel", false)
 
 		log.Debugf(ctx, "SearchHotelOffers: Requesting batch %d/%d: %s", (i/chunkSize)+1, (len(hotelIds)+chunkSize-1)/chunkSize, endpoint)
 
 		resp, err := c.doRequest(ctx, "GET", endpoint, nil)
 		if err != nil {
 			log.Errorf(ctx, "SearchHotelOffers: batch request failed: %v", err)
+			failed++
 			continue // Try next batch
 		}
 
@@ -320,6 +438,7 @@ func (c *Client) SearchHotelOffers(ctx context.Context, hotelIds []string, acc *
 
 			// We continue here because other batches might succeed
 			resp.Body.Close()
+			failed++
 			continue
 		}
 
@@ -327,14 +446,19 @@ func (c *Client) SearchHotelOffers(ctx context.Context, hotelIds []string, acc *
 		if err := json.NewDecoder(resp.Body).Decode(&searchResp); err != nil {
 			log.Errorf(ctx, "SearchHotelOffers: failed to decode response: %v", err)
 			resp.Body.Close()
+			failed++
 			continue
 		}
 		resp.Body.Close()
 
 		var batchAccommodations []*pb.Accommodation
-		for _, data := range searchResp.Data {
-			batchAccommodations = append(batchAccommodations, data.ToAccommodations()...)
+		byData := make([][]*pb.Accommodation, len(searchResp.Data))
+		for j, data := range searchResp.Data {
+			byData[j] = data.ToAccommodations()
+			batchAccommodations = append(batchAccommodations, byData[j]...)
 		}
+		recordWarnings(ctx, "SearchHotelOffers", searchResp.Warnings)
+		noteHotelWarnings(searchResp.Warnings, byData)
 
 		// Enrich results with source location info
 		// INVARIANT: acc.Location is non-nil and enriched
@@ -349,32 +473,139 @@ func (c *Client) SearchHotelOffers(ctx context.Context, hotelIds []string, acc *
 
 		// Set cache for this batch
 		ttl := time.Duration(c.Config.CacheTTL.Hotel) * time.Hour
-		c.Cache.Set(cacheKey, batchAccommodations, ttl)
+		c.Cache.Set(cacheKey, cloneAccommodations(batchAccommodations), ttl)
 
-		// INVARIANT: DB is always initialized
 		// Persist to DB
-		if b, err := json.Marshal(batchAccommodations); err == nil {
-			orm.SetCacheEntry(c.DB, cacheKey, b, 60*time.Minute)
+		if c.DB != nil {
+			if b, err := json.Marshal(batchAccommodations); err == nil {
+				orm.SetCacheEntry(c.DB, cacheKey, b, 60*time.Minute)
+			}
 		}
 
 		accommodations = append(accommodations, batchAccommodations...)
 	}
 
-	if len(accommodations) == 0 && len(hotelIds) > 0 {
-		return nil, fmt.Errorf("hotel offers search failed for all %d hotels (likely 400 Bad Request or no availability)", len(hotelIds))
+	// Batches that answered with no offers mean no availability, which is not an error
+	if len(accommodations) == 0 && failed > 0 {
+		return nil, fmt.Errorf("hotel offers search failed for %d of %d batches of hotels (likely 400 Bad Request)", failed, (len(hotelIds)+chunkSize-1)/chunkSize)
+	}
+
+	// Group offers by hotel and apply the limits: the hotel limit counts properties, not offers
+	_, limit := c.Limits()
+	accommodations = PreferChains(accommodations, c.Config.HotelOffers.PreferredChains)
+	return GroupHotelOffers(accommodations, c.Config.HotelOffers.PerHotel, limit), nil
+}
+
+// cloneAccommodations deep-copies a list of offers
+func cloneAccommodations(offers []*pb.Accommodation) []*pb.Accommodation {
+	clones := make([]*pb.Accommodation, len(offers))
+	for i, offer := range offers {
+		clones[i] = proto.Clone(offer).(*pb.Accommodation)
+	}
+	return clones
+}
+
+// PreferChains marks the offers from hotels of the given chains as PreferredChain and
+// moves them ahead of the others, so they are grouped first and survive the hotel
+// limit. Order is kept otherwise.
+func PreferChains(offers []*pb.Accommodation, chains []string) []*pb.Accommodation {
+	if len(chains) == 0 {
+		return offers
+	}
+	var preferred, rest []*pb.Accommodation
+	for _, offer := range offers {
+		offer.PreferredChain = offer.ChainCode != "" && slices.Contains(chains, offer.ChainCode)
+		if offer.PreferredChain {
+			preferred = append(preferred, offer)
+		} else {
+			rest = append(rest, offer)
+		}
+	}
+	return append(preferred, rest...)
+}
+
+// GroupHotelOffers groups offers by hotel (in first-seen hotel order), sorts each
+// hotel's offers by price, keeps at most perHotel of them and marks the cheapest
+// as PropertyCheapest. At most maxHotels hotels are kept. Zero limits keep everything.
+func GroupHotelOffers(offers []*pb.Accommodation, perHotel, maxHotels int) []*pb.Accommodation {
+	var order []string
+	byHotel := make(map[string][]*pb.Accommodation)
+	for _, offer := range offers {
+		key := offer.HotelId
+		if key == "" {
+			// Without an ID, fall back to the name so unrelated offers are not merged
+			key = offer.Name
+		}
+		if _, seen := byHotel[key]; !seen {
+			order = append(order, key)
+		}
+		byHotel[key] = append(byHotel[key], offer)
+	}
+
+	if maxHotels > 0 && len(order) > maxHotels {
+		order = order[:maxHotels]
+	}
+
+	var grouped []*pb.Accommodation
+	for _, key := range order {
+		hotelOffers := byHotel[key]
+		sort.SliceStable(hotelOffers, func(i, j int) bool {
+			return hotelOffers[i].GetCost().GetValue() < hotelOffers[j].GetCost().GetValue()
+		})
+		if perHotel > 0 && len(hotelOffers) > perHotel {
+			hotelOffers = hotelOffers[:perHotel]
+		}
+		for i, offer := range hotelOffers {
+			offer.PropertyCheapest = i == 0
+		}
+		grouped = append(grouped, hotelOffers...)
+	}
+	return grouped
+}
+
+// GetHotelOffer fetches the current terms of a hotel offer
+func (c *Client) GetHotelOffer(ctx context.Context, offerId string) (*HotelOfferResponse, error) {
+	resp, err := c.doRequest(ctx, "GET", "/v3/shopping/hotel-offers/"+url.PathEscape(offerId), nil)
+	if err != nil {
+		log.Errorf(ctx, "GetHotelOffer: request failed: %v", err)
+		return nil, err
 	}
+	defer resp.Body.Close()
 
-	// Apply limit
-	limit := c.Config.HotelLimit
-	if limit > 0 && len(accommodations) > limit {
-		accommodations = accommodations[:limit]
+	if resp.StatusCode != http.StatusOK {
+		log.Errorf(ctx, "GetHotelOffer: API returned status %s", resp.Status)
+		return nil, fmt.Errorf("hotel offer lookup failed: %s", resp.Status)
 	}
 
-	return accommodations, nil
+	var offerResp HotelOfferResponse
+	if err := json.NewDecoder(resp.Body).Decode(&offerResp); err != nil {
+		log.Errorf(ctx, "GetHotelOffer: failed to decode response: %v", err)
+		return nil, err
+	}
+	recordWarnings(ctx, "GetHotelOffer", offerResp.Warnings)
+	return &offerResp, nil
 }
 
-// BookHotel creates a hotel booking
+// BookHotel creates a hotel booking. The offer's payment policy is checked first,
+// so a card the property does not take fails with ErrPaymentNotAccepted before
+// anything is booked.
 func (c *Client) BookHotel(ctx context.Context, offerId string, guests []HotelGuest, payment HotelPayment) (*HotelOrderResponse, error) {
+	offerResp, err := c.GetHotelOffer(ctx, offerId)
+	if err != nil {
+		return nil, err
+	}
+	for _, offer := range offerResp.Data.Offers {
+		if err := CheckHotelPayment(offer.Policies.paymentPolicy(offer.Price.Currency), payment); err != nil {
+			log.Errorf(ctx, "BookHotel: %v", err)
+			return nil, err
+		}
+	}
+	if payment.Card != nil && payment.Card.VendorCode == "" {
+		card := *payment.Card
+		card.VendorCode = CardVendor(card.CardNumber)
+		payment.Card = &card
+	}
+
 	reqBody := HotelOrderRequest{}
 	reqBody.Data.Type = "hotel-order"
 
@@ -410,16 +641,51 @@ func (c *Client) BookHotel(ctx context.Context, offerId string, guests []HotelGu
 		log.Errorf(ctx, "BookHotel: failed to decode response: %v", err)
 		return nil, err
 	}
+	recordWarnings(ctx, "BookHotel", orderResp.Warnings)
 
 	return &orderResp, nil
 }
 
+// GetHotelOrder retrieves a hotel order by its ID. It returns ErrOrderNotFound if the
+// provider no longer has it.
+func (c *Client) GetHotelOrder(ctx context.Context, orderID string) (*HotelOrder, error) {
+	resp, err := c.doRequest(ctx, "GET", "/v2/booking/hotel-orders/"+url.PathEscape(orderID), nil)
+	if err != nil {
+		log.Errorf(ctx, "GetHotelOrder: request failed: %v", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrOrderNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		log.Errorf(ctx, "GetHotelOrder: API returned status %s", resp.Status)
+		return nil, fmt.Errorf("hotel order lookup failed: %s", resp.Status)
+	}
+
+	var order HotelOrder
+	if err := json.NewDecoder(resp.Body).Decode(&order); err != nil {
+		log.Errorf(ctx, "GetHotelOrder: failed to decode response: %v", err)
+		return nil, err
+	}
+	recordWarnings(ctx, "GetHotelOrder", order.Warnings)
+	return &order, nil
+}
+
+// CancelHotelOrder cancels a hotel order. It returns ErrOrderNotFound if the provider
+// no longer has the order.
+func (c *Client) CancelHotelOrder(ctx context.Context, orderID string) error {
+	return c.cancelOrder(ctx, "CancelHotelOrder", "/v2/booking/hotel-orders/"+url.PathEscape(orderID))
+}
+
 // ToAccommodations converts HotelOfferData to a list of pb.Accommodation
 func (d HotelOfferData) ToAccommodations() []*pb.Accommodation {
 	var accs []*pb.Accommodation
 	for _, offer := range d.Offers {
 		acc := &pb.Accommodation{
-			Name: d.Hotel.Name,
+			Name:    d.Hotel.Name,
+			HotelId: d.Hotel.HotelId,
 			Location: &pb.Location{
 				CityCode: d.Hotel.CityCode,
 				Name:     d.Hotel.Name,
@@ -433,7 +699,8 @@ func (d HotelOfferData) ToAccommodations() []*pb.Accommodation {
 				},
 				// Rating not directly in offer, maybe in HotelInfo but struct definition doesn't show it (it was in request params)
 			},
-			Status: "AVAILABLE",
+			Status:    "AVAILABLE",
+			ChainCode: d.Hotel.ChainCode,
 		}
 
 		if price, err := strconv.ParseFloat(offer.Price.Total, 64); err == nil {
@@ -452,7 +719,17 @@ func (d HotelOfferData) ToAccommodations() []*pb.Accommodation {
 
 		// If guests info is available
 		if offer.Guests.Adults > 0 {
-			acc.TravelerCount = int32(offer.Guests.Adults)
+			acc.TravelerCount = int32(offer.Guests.Adults + len(offer.Guests.ChildAges))
+		}
+		for _, age := range offer.Guests.ChildAges {
+			acc.ChildAges = append(acc.ChildAges, int32(age))
+		}
+
+		acc.NightlyCost = offer.Price.nightly(acc)
+		acc.BreakfastIncluded = offer.includesBreakfast()
+		acc.PaymentPolicy = offer.Policies.paymentPolicy(offer.Price.Currency)
+		if w := depositWarning(acc.PaymentPolicy); w != "" {
+			acc.Warnings = append(acc.Warnings, w)
 		}
 
 		accs = append(accs, acc)
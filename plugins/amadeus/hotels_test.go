@@ -0,0 +1,79 @@
+package amadeus
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/pb"
+)
+
+func TestHotelOfferData_ToAccommodations_NightlyCost(t *testing.T) {
+	offer := func(total, average, checkIn, checkOut string) HotelOffer {
+		o := HotelOffer{ID: "offer1", CheckInDate: checkIn, CheckOutDate: checkOut}
+		o.Price.Currency = "EUR"
+		o.Price.Total = total
+		o.Price.Variations.Average.Base = average
+		return o
+	}
+	data := HotelOfferData{
+		Hotel: HotelInfo{HotelId: "H1", Name: "Hotel A"},
+		Offers: []HotelOffer{
+			offer("480.00", "140.00", "2026-06-01", "2026-06-04"),
+			offer("450.00", "", "2026-06-01", "2026-06-04"),
+			offer("100.00", "", "2026-06-01", "2026-06-04"),
+			offer("450.00", "", "", ""),
+		},
+	}
+
+	accs := data.ToAccommodations()
+	if !assert.Len(t, accs, 4) {
+		return
+	}
+
+	// The average Amadeus reports is used as is
+	assert.Equal(t, 140.0, accs[0].NightlyCost.GetValue())
+	assert.Equal(t, "EUR", accs[0].NightlyCost.GetCurrency())
+	assert.Equal(t, 480.0, accs[0].Cost.GetValue(), "the total is kept")
+
+	// Without it, the total is spread over the nights
+	assert.Equal(t, 150.0, accs[1].NightlyCost.GetValue())
+	assert.Equal(t, "EUR", accs[1].NightlyCost.GetCurrency())
+	assert.Equal(t, 33.33, accs[2].NightlyCost.GetValue())
+
+	// ...which needs the dates
+	assert.Nil(t, accs[3].NightlyCost)
+}
+
+func TestHotelOffer_IncludesBreakfast(t *testing.T) {
+	for board, want := range map[string]bool{
+		"BREAKFAST":     true,
+		"HALF_BOARD":    true,
+		"ALL_INCLUSIVE": true,
+		"ROOM_ONLY":     false,
+		"":              false,
+	} {
+		data := HotelOfferData{Offers: []HotelOffer{{ID: "offer1", BoardType: board}}}
+		assert.Equal(t, want, data.ToAccommodations()[0].BreakfastIncluded, board)
+	}
+}
+
+func TestPreferChains(t *testing.T) {
+	offers := []*pb.Accommodation{
+		{HotelId: "IB1", ChainCode: "IB"},
+		{HotelId: "MC1", ChainCode: "MC"},
+		{HotelId: "XX1"},
+		{HotelId: "HH1", ChainCode: "HH"},
+	}
+
+	// Preferred chains come first and survive a two-hotel limit
+	grouped := GroupHotelOffers(PreferChains(offers, []string{"MC", "HH"}), 0, 2)
+	var got []string
+	for _, a := range grouped {
+		got = append(got, fmt.Sprintf("%s/%t", a.HotelId, a.PreferredChain))
+	}
+	assert.Equal(t, []string{"MC1/true", "HH1/true"}, got)
+
+	// Without preferred chains nothing moves
+	assert.Equal(t, offers, PreferChains(offers, nil))
+}
@@ -0,0 +1,105 @@
+package amadeus
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ErrPaymentNotAccepted is returned when a rate cannot be paid the way a booking offers
+var ErrPaymentNotAccepted = errors.New("payment not accepted")
+
+// cardPrefixes maps card number prefixes to Amadeus vendor codes, longest first
+var cardPrefixes = []struct {
+	prefix string
+	vendor string
+}{
+	{"6011", "DS"},
+	{"34", "AX"}, {"37", "AX"}, {"35", "JC"}, {"36", "DC"}, {"38", "DC"}, {"30", "DC"}, {"65", "DS"},
+	{"51", "CA"}, {"52", "CA"}, {"53", "CA"}, {"54", "CA"}, {"55", "CA"},
+	{"4", "VI"},
+}
+
+// CardVendor infers a card's vendor code, e.g. VI or AX, from its number. It returns
+// "" for numbers it does not recognise.
+func CardVendor(number string) string {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, number)
+	// Mastercard's 2-series covers 2221 to 2720
+	if len(digits) >= 4 {
+		if p, _ := strconv.Atoi(digits[:4]); p >= 2221 && p <= 2720 {
+			return "CA"
+		}
+	}
+	for _, c := range cardPrefixes {
+		if strings.HasPrefix(digits, c.prefix) {
+			return c.vendor
+		}
+	}
+	return ""
+}
+
+// paymentPolicy converts the offer's policies. The accepted payments are the
+// deposit's for a deposit rate and the guarantee's otherwise.
+func (p HotelPolicies) paymentPolicy(currency string) *pb.PaymentPolicy {
+	accepted := p.Guarantee.AcceptedPayments
+	policy := &pb.PaymentPolicy{GuaranteeRequired: strings.EqualFold(p.PaymentType, "guarantee")}
+	if strings.EqualFold(p.PaymentType, "deposit") {
+		accepted = p.Deposit.AcceptedPayments
+		if amount, err := strconv.ParseFloat(p.Deposit.Amount, 64); err == nil && amount > 0 {
+			policy.Deposit = &pb.Cost{Value: amount, Currency: currency}
+		}
+		if t, err := time.Parse("2006-01-02T15:04:05", p.Deposit.Deadline); err == nil {
+			policy.DepositDeadline = timestamppb.New(t)
+		}
+	}
+	policy.AcceptedCardVendors = accepted.CreditCards
+	policy.AcceptedMethods = accepted.Methods
+	return policy
+}
+
+// depositWarning tells the traveller about a deposit before they choose the rate
+func depositWarning(policy *pb.PaymentPolicy) string {
+	if policy.GetDeposit() == nil {
+		return ""
+	}
+	return fmt.Sprintf("Deposit of %.2f %s required at booking", policy.Deposit.Value, policy.Deposit.Currency)
+}
+
+// CheckHotelPayment reports whether a rate with the given policy can be paid with
+// payment. A card without a vendor code is checked by the vendor of its number.
+func CheckHotelPayment(policy *pb.PaymentPolicy, payment HotelPayment) error {
+	if methods := policy.GetAcceptedMethods(); len(methods) > 0 && !containsFold(methods, payment.Method) {
+		return fmt.Errorf("%w: %s is not accepted; the property takes %s", ErrPaymentNotAccepted, payment.Method, strings.Join(methods, ", "))
+	}
+	vendors := policy.GetAcceptedCardVendors()
+	if payment.Card == nil || len(vendors) == 0 {
+		return nil
+	}
+	vendor := payment.Card.VendorCode
+	if vendor == "" {
+		vendor = CardVendor(payment.Card.CardNumber)
+	}
+	if !containsFold(vendors, vendor) {
+		return fmt.Errorf("%w: %q cards are not accepted; the property takes %s", ErrPaymentNotAccepted, vendor, strings.Join(vendors, ", "))
+	}
+	return nil
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
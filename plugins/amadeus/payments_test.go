@@ -0,0 +1,136 @@
+package amadeus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/pb"
+)
+
+func TestCardVendor(t *testing.T) {
+	tests := map[string]string{
+		"4111 1111 1111 1111": "VI",
+		"378282246310005":     "AX",
+		"5555-5555-5555-4444": "CA",
+		"2223003122003222":    "CA",
+		"6011111111111117":    "DS",
+		"3530111333300000":    "JC",
+		"9999":                "",
+	}
+	for number, want := range tests {
+		assert.Equal(t, want, CardVendor(number), number)
+	}
+}
+
+func TestCheckHotelPayment(t *testing.T) {
+	axOnly := &pb.PaymentPolicy{AcceptedCardVendors: []string{"AX"}, AcceptedMethods: []string{"CREDIT_CARD"}}
+	visa := HotelPayment{Method: "CREDIT_CARD", Card: &PaymentCard{VendorCode: "VI", CardNumber: "4111111111111111"}}
+	amex := HotelPayment{Method: "CREDIT_CARD", Card: &PaymentCard{CardNumber: "378282246310005"}}
+
+	err := CheckHotelPayment(axOnly, visa)
+	assert.ErrorIs(t, err, ErrPaymentNotAccepted)
+	assert.ErrorContains(t, err, `"VI" cards are not accepted; the property takes AX`)
+
+	// The vendor is inferred from the number when it is not given
+	assert.NoError(t, CheckHotelPayment(axOnly, amex))
+
+	assert.ErrorContains(t, CheckHotelPayment(axOnly, HotelPayment{Method: "BANK_TRANSFER"}), "BANK_TRANSFER is not accepted; the property takes CREDIT_CARD")
+	assert.NoError(t, CheckHotelPayment(&pb.PaymentPolicy{}, visa))
+	assert.NoError(t, CheckHotelPayment(nil, visa))
+}
+
+func TestHotelOffer_DepositWarning(t *testing.T) {
+	offer := HotelOffer{ID: "offer1", Price: HotelPrice{Currency: "EUR", Total: "480.00"}}
+	offer.Policies.PaymentType = "deposit"
+	offer.Policies.Deposit.Amount = "120.00"
+	offer.Policies.Deposit.Deadline = "2026-05-20T23:59:00"
+	offer.Policies.Deposit.AcceptedPayments = AcceptedPayments{CreditCards: []string{"VI", "CA"}, Methods: []string{"CREDIT_CARD"}}
+	guaranteed := HotelOffer{ID: "offer2", Price: HotelPrice{Currency: "EUR", Total: "500.00"}}
+	guaranteed.Policies.PaymentType = "guarantee"
+
+	accs := HotelOfferData{Hotel: HotelInfo{HotelId: "H1", Name: "Hotel One"}, Offers: []HotelOffer{offer, guaranteed}}.ToAccommodations()
+	if !assert.Len(t, accs, 2) {
+		return
+	}
+
+	policy := accs[0].PaymentPolicy
+	assert.Equal(t, []string{"VI", "CA"}, policy.AcceptedCardVendors)
+	assert.False(t, policy.GuaranteeRequired)
+	assert.Equal(t, 120.0, policy.Deposit.GetValue())
+	assert.Equal(t, "2026-05-20T23:59:00Z", policy.DepositDeadline.AsTime().Format("2006-01-02T15:04:05Z07:00"))
+	assert.Equal(t, []string{"Deposit of 120.00 EUR required at booking"}, accs[0].Warnings)
+
+	assert.True(t, accs[1].PaymentPolicy.GuaranteeRequired)
+	assert.Nil(t, accs[1].PaymentPolicy.Deposit)
+	assert.Empty(t, accs[1].Warnings)
+}
+
+func TestBookHotel_PaymentPolicy(t *testing.T) {
+	var orders atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/security/oauth2/token":
+			w.Write([]byte(`{"access_token": "test_token", "expires_in": 1800}`))
+		case "/v3/shopping/hotel-offers/AXONLY":
+			w.Write([]byte(`{"data": {"hotel": {"hotelId": "H2"}, "offers": [{"id": "AXONLY",
+				"policies": {"paymentType": "guarantee", "guarantee": {"acceptedPayments": {"creditCards": ["AX"], "methods": ["CREDIT_CARD"]}}}}]}}`))
+		case "/v2/booking/hotel-orders":
+			orders.Add(1)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"data": [{"id": "hotel_order_1"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(Config{
+		ClientID: "id", ClientSecret: "secret", IsProduction: false,
+		FlightLimit: 10, HotelLimit: 10, Timeout: 10,
+		CacheTTL: CacheTTLConfig{Location: 24, Flight: 24, Hotel: 24},
+	}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	client.BaseURL = ts.URL
+
+	// The Visa card is refused before the order is sent
+	guests := []HotelGuest{{Tid: 1, FirstName: "John", LastName: "Doe"}}
+	visa := HotelPayment{Method: "CREDIT_CARD", Card: &PaymentCard{VendorCode: "VI", CardNumber: "4111111111111111", ExpiryDate: "2030-08"}}
+	_, err = client.BookHotel(context.Background(), "AXONLY", guests, visa)
+	assert.ErrorIs(t, err, ErrPaymentNotAccepted)
+	assert.ErrorContains(t, err, "the property takes AX")
+	assert.Equal(t, int32(0), orders.Load())
+
+	// An expired offer is not booked either
+	_, err = client.BookHotel(context.Background(), "GONE", guests, visa)
+	assert.Error(t, err)
+	assert.Equal(t, int32(0), orders.Load())
+}
+
+func TestBookHotel(t *testing.T) {
+	ts := mockAmadeusServer()
+	defer ts.Close()
+
+	client, err := NewClient(Config{
+		ClientID: "id", ClientSecret: "secret", IsProduction: false,
+		FlightLimit: 10, HotelLimit: 10, Timeout: 10,
+		CacheTTL: CacheTTLConfig{Location: 24, Flight: 24, Hotel: 24},
+	}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	client.BaseURL = ts.URL
+
+	// The property takes any card
+	guests := []HotelGuest{{Tid: 1, FirstName: "John", LastName: "Doe"}}
+	visa := HotelPayment{Method: "CREDIT_CARD", Card: &PaymentCard{CardNumber: "4111111111111111", ExpiryDate: "2030-08"}}
+	resp, err := client.BookHotel(context.Background(), "offer1", guests, visa)
+	if assert.NoError(t, err) && assert.Len(t, resp.Data, 1) {
+		assert.Equal(t, "hotel_order_1", resp.Data[0].ID)
+	}
+}
@@ -0,0 +1,109 @@
+package amadeus
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/va6996/travelingman/log"
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// Steps of the hotel search relaxation ladder
+const (
+	RelaxAmenities = "amenities" // Drop the amenity filter
+	RelaxRating    = "rating"    // Lower the star rating by one
+)
+
+// HotelRelaxationConfig controls how a hotel search is retried when the
+// preference filters leave no hotels
+type HotelRelaxationConfig struct {
+	Steps     []string // Applied in order until hotels are found; a step may repeat
+	MinRating int      // The rating is never lowered below this (0 allows dropping it)
+}
+
+// Relaxation records which preference filters a hotel search had to loosen
+type Relaxation struct {
+	Original *pb.AccommodationPreferences
+	Relaxed  *pb.AccommodationPreferences
+	Steps    []string // Steps applied, in order
+}
+
+// Message explains the relaxation to the traveller, e.g.
+// "no 5-star hotels with spa found; showing 4-star options"
+func (r *Relaxation) Message() string {
+	return fmt.Sprintf("no %s found; showing %s", describeHotels(r.Original, "hotels"), describeHotels(r.Relaxed, "options"))
+}
+
+// SearchHotelsByCityRelaxed searches hotels like SearchHotelsByCity. If the preference
+// filters leave no hotels, the search is retried with the filters loosened one step of
+// Config.HotelRelaxation at a time until hotels are found or the ladder runs out.
+// The relaxation is nil when the original filters found hotels, nothing was loosened,
+// or the preferences are strict. acc is not modified.
+func (c *Client) SearchHotelsByCityRelaxed(ctx context.Context, acc *pb.Accommodation) (*HotelListResponse, *Relaxation, error) {
+	resp, err := c.SearchHotelsByCity(ctx, acc)
+	if err != nil || len(resp.Data) > 0 {
+		return resp, nil, err
+	}
+	if acc.Preferences == nil || acc.Preferences.Strict {
+		return resp, nil, nil
+	}
+
+	cfg := c.Config.HotelRelaxation
+	relaxed := proto.Clone(acc).(*pb.Accommodation)
+	prefs := relaxed.Preferences
+	var applied []string
+	for _, step := range cfg.Steps {
+		switch step {
+		case RelaxAmenities:
+			if len(prefs.Amenities) == 0 {
+				continue
+			}
+			prefs.Amenities = nil
+		case RelaxRating:
+			if prefs.Rating <= 0 || int(prefs.Rating)-1 < cfg.MinRating {
+				continue
+			}
+			prefs.Rating--
+		default:
+			continue
+		}
+		applied = append(applied, step)
+
+		log.Infof(ctx, "SearchHotelsByCityRelaxed: No hotels in %s, retrying with relaxed %s", getLocationCode(acc.Location), step)
+		resp, err = c.SearchHotelsByCity(ctx, relaxed)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(resp.Data) > 0 {
+			return resp, &Relaxation{Original: acc.Preferences, Relaxed: prefs, Steps: applied}, nil
+		}
+	}
+	return resp, nil, nil
+}
+
+// describeHotels renders preferences as e.g. "5-star hotels with spa and wifi"
+func describeHotels(p *pb.AccommodationPreferences, noun string) string {
+	amenities := p.GetAmenities()
+	if p.GetRating() == 0 && len(amenities) == 0 {
+		return "other " + noun
+	}
+
+	s := noun
+	if p.GetRating() > 0 {
+		s = fmt.Sprintf("%d-star %s", p.Rating, noun)
+	}
+	if len(amenities) > 0 {
+		names := make([]string, len(amenities))
+		for i, a := range amenities {
+			names[i] = strings.ToLower(strings.ReplaceAll(a, "_", " "))
+		}
+		list := names[len(names)-1]
+		if len(names) > 1 {
+			list = strings.Join(names[:len(names)-1], ", ") + " and " + list
+		}
+		s += " with " + list
+	}
+	return s
+}
@@ -0,0 +1,109 @@
+package amadeus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// newRelaxationTestClient serves hotels only for 4-star searches without amenities
+// and records the query of every hotel list request
+func newRelaxationTestClient(t *testing.T, queries *[]string) *Client {
+	client := newCalendarTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/security/oauth2/token":
+			writeToken(w)
+		case "/v1/reference-data/locations/hotels/by-city":
+			*queries = append(*queries, r.URL.RawQuery)
+			resp := HotelListResponse{Data: []HotelData{}}
+			if r.URL.Query().Get("ratings") == "4" && r.URL.Query().Get("amenities") == "" {
+				resp.Data = append(resp.Data, HotelData{HotelId: "H4", Name: "Four Star"})
+			}
+			json.NewEncoder(w).Encode(resp)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	client.Config.HotelRelaxation = HotelRelaxationConfig{Steps: []string{RelaxAmenities, RelaxRating}, MinRating: 1}
+	return client
+}
+
+func relaxationTestStay(strict bool) *pb.Accommodation {
+	return &pb.Accommodation{
+		Location: &pb.Location{City: "Paris", CityCode: "PAR"},
+		Preferences: &pb.AccommodationPreferences{
+			Rating:    5,
+			Amenities: []string{"SPA"},
+			Strict:    strict,
+		},
+	}
+}
+
+func TestSearchHotelsByCityRelaxed(t *testing.T) {
+	var queries []string
+	client := newRelaxationTestClient(t, &queries)
+	acc := relaxationTestStay(false)
+	original := proto.Clone(acc)
+
+	resp, relaxation, err := client.SearchHotelsByCityRelaxed(context.Background(), acc)
+
+	assert.NoError(t, err)
+	if assert.Len(t, resp.Data, 1) {
+		assert.Equal(t, "H4", resp.Data[0].HotelId)
+	}
+	assert.Equal(t, []string{
+		"cityCode=PAR&ratings=5&amenities=SPA",
+		"cityCode=PAR&ratings=5",
+		"cityCode=PAR&ratings=4",
+	}, queries)
+	if assert.NotNil(t, relaxation) {
+		assert.Equal(t, []string{RelaxAmenities, RelaxRating}, relaxation.Steps)
+		assert.Equal(t, "no 5-star hotels with spa found; showing 4-star options", relaxation.Message())
+	}
+	assert.True(t, proto.Equal(original, acc), "the requested preferences are kept")
+}
+
+func TestSearchHotelsByCityRelaxed_Strict(t *testing.T) {
+	var queries []string
+	client := newRelaxationTestClient(t, &queries)
+
+	resp, relaxation, err := client.SearchHotelsByCityRelaxed(context.Background(), relaxationTestStay(true))
+
+	assert.NoError(t, err)
+	assert.Empty(t, resp.Data)
+	assert.Nil(t, relaxation)
+	assert.Equal(t, []string{"cityCode=PAR&ratings=5&amenities=SPA"}, queries, "strict preferences are not relaxed")
+}
+
+func TestSearchHotelsByCityRelaxed_StopsAtMinRating(t *testing.T) {
+	var queries []string
+	client := newRelaxationTestClient(t, &queries)
+	client.Config.HotelRelaxation.MinRating = 5
+
+	resp, relaxation, err := client.SearchHotelsByCityRelaxed(context.Background(), relaxationTestStay(false))
+
+	assert.NoError(t, err)
+	assert.Empty(t, resp.Data)
+	assert.Nil(t, relaxation)
+	assert.Equal(t, []string{
+		"cityCode=PAR&ratings=5&amenities=SPA",
+		"cityCode=PAR&ratings=5",
+	}, queries, "the rating is not lowered below the minimum")
+}
+
+func TestRelaxation_Message(t *testing.T) {
+	r := &Relaxation{
+		Original: &pb.AccommodationPreferences{Rating: 4, Amenities: []string{"SPA", "SWIMMING_POOL", "WIFI"}},
+		Relaxed:  &pb.AccommodationPreferences{Rating: 4},
+	}
+	assert.Equal(t, "no 4-star hotels with spa, swimming pool and wifi found; showing 4-star options", r.Message())
+
+	r.Relaxed.Rating = 0
+	assert.Equal(t, "no 4-star hotels with spa, swimming pool and wifi found; showing other options", r.Message())
+}
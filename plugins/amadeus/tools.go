@@ -11,9 +11,21 @@ import (
 	"github.com/va6996/travelingman/log"
 	"github.com/va6996/travelingman/pb"
 	"github.com/va6996/travelingman/tools"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// ToolPrefix starts the name of every tool the client registers, all of which call Amadeus
+const ToolPrefix = "amadeus_"
+
+// Names of the tools searching flight and hotel offers: the searches TravelDesk makes
+// again when it verifies a plan
+const (
+	FlightToolName      = ToolPrefix + "flight_tool"
+	HotelListToolName   = ToolPrefix + "hotel_list"
+	HotelOffersToolName = ToolPrefix + "hotel_offers"
+)
+
 // ToolLocation is a simplified location struct for tool inputs to ensure valid schema generation
 type ToolLocation struct {
 	City      string   `json:"city,omitempty"`
@@ -38,19 +50,38 @@ type FlightInput struct {
 type HotelListInput struct {
 	Location  *ToolLocation `json:"location"`
 	Rating    int           `json:"rating,omitempty" description:"Hotel rating (1-5)"`
-	Amenities []string      `json:"amenities,omitempty" description:"List of amenities"`
+	Amenities []string      `json:"amenities,omitempty" description:"List of amenity codes, see amadeus_hotel_amenities"`
 }
 
 type HotelOffersInput struct {
-	HotelIDs []string `json:"hotel_ids"`
-	Adults   int      `json:"adults"`
-	CheckIn  string   `json:"check_in"`
-	CheckOut string   `json:"check_out"`
-	Currency string   `json:"currency,omitempty"`
+	HotelIDs  []string `json:"hotel_ids"`
+	Adults    int      `json:"adults"`
+	ChildAges []int32  `json:"child_ages,omitempty" description:"Ages of the children staying, on top of the adults"`
+	CheckIn   string   `json:"check_in"`
+	CheckOut  string   `json:"check_out"`
+	Currency  string   `json:"currency,omitempty"`
 }
 
 type LocationInput struct {
 	Keyword string `json:"keyword"`
+	Exact   bool   `json:"exact,omitempty" description:"Look up only this city or airport, without adding the airports near it"`
+}
+
+type PriceCalendarInput struct {
+	Origin      string `json:"origin" description:"Origin IATA code"`
+	Destination string `json:"destination" description:"Destination IATA code"`
+	Month       string `json:"month" description:"Month in YYYY-MM format"`
+	Adults      int    `json:"adults"`
+	Currency    string `json:"currency,omitempty"`
+}
+
+type FareTrendInput struct {
+	Origin      string `json:"origin" description:"Origin IATA code"`
+	Destination string `json:"destination" description:"Destination IATA code"`
+	FromDate    string `json:"from_date" description:"First departure day in YYYY-MM-DD format"`
+	ToDate      string `json:"to_date" description:"Last departure day in YYYY-MM-DD format"`
+	Adults      int    `json:"adults"`
+	Currency    string `json:"currency,omitempty"`
 }
 
 // Helper to convert ToolLocation to pb.Location
@@ -160,12 +191,19 @@ func NewFlightTool(c *Client, gk *genkit.Genkit, registry *tools.Registry) *Flig
 	if gk == nil || registry == nil {
 		return t
 	}
+	run := func(ctx context.Context, input *FlightInput) ([]*pb.Transport, error) {
+		resp, err := t.Execute(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		return capForModel(ctx, registry, "FlightTool", resp, slimTransport), nil
+	}
 	registry.Register(genkit.DefineTool[*FlightInput, []*pb.Transport](
 		gk,
-		"amadeus_flight_tool",
+		FlightToolName,
 		t.Description(),
 		func(ctx *ai.ToolContext, input *FlightInput) ([]*pb.Transport, error) {
-			return t.Execute(ctx, input)
+			return tools.Run(ctx, FlightToolName, c.MapError, input, run)
 		},
 	), func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 		in := &FlightInput{}
@@ -173,7 +211,7 @@ func NewFlightTool(c *Client, gk *genkit.Genkit, registry *tools.Registry) *Flig
 		if err := json.Unmarshal(b, in); err != nil {
 			return nil, fmt.Errorf("failed to parse arguments: %w", err)
 		}
-		return t.Execute(ctx, in)
+		return run(ctx, in)
 	})
 	return t
 }
@@ -188,12 +226,19 @@ func NewHotelListTool(c *Client, gk *genkit.Genkit, registry *tools.Registry) *H
 	if gk == nil || registry == nil {
 		return t
 	}
+	run := func(ctx context.Context, input *HotelListInput) (*HotelListResponse, error) {
+		resp, err := t.Execute(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		return &HotelListResponse{Data: capForModel(ctx, registry, "HotelListTool", resp.Data, nil)}, nil
+	}
 	registry.Register(genkit.DefineTool[*HotelListInput, *HotelListResponse](
 		gk,
-		"amadeus_hotel_list",
+		HotelListToolName,
 		"Searches for hotels in a specific city. Returns a list of hotels with IDs.",
 		func(ctx *ai.ToolContext, input *HotelListInput) (*HotelListResponse, error) {
-			return t.Execute(ctx, input)
+			return tools.Run(ctx, HotelListToolName, c.MapError, input, run)
 		},
 	), func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 		in := &HotelListInput{}
@@ -201,7 +246,7 @@ func NewHotelListTool(c *Client, gk *genkit.Genkit, registry *tools.Registry) *H
 		if err := json.Unmarshal(b, in); err != nil {
 			return nil, fmt.Errorf("failed to parse arguments: %w", err)
 		}
-		return t.Execute(ctx, in)
+		return run(ctx, in)
 	})
 	return t
 }
@@ -244,12 +289,19 @@ func NewHotelOffersTool(c *Client, gk *genkit.Genkit, registry *tools.Registry)
 	if gk == nil || registry == nil {
 		return t
 	}
+	run := func(ctx context.Context, input *HotelOffersInput) ([]*pb.Accommodation, error) {
+		resp, err := t.Execute(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		return capForModel(ctx, registry, "HotelOffersTool", resp, slimAccommodation), nil
+	}
 	registry.Register(genkit.DefineTool[*HotelOffersInput, []*pb.Accommodation](
 		gk,
-		"amadeus_hotel_offers",
+		HotelOffersToolName,
 		"Searches for offers for specific hotels. Requires hotel IDs (from hotel_list tool), check-in/out dates, and number of adults.",
 		func(ctx *ai.ToolContext, input *HotelOffersInput) ([]*pb.Accommodation, error) {
-			return t.Execute(ctx, input)
+			return tools.Run(ctx, HotelOffersToolName, c.MapError, input, run)
 		},
 	), func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 		in := &HotelOffersInput{}
@@ -257,7 +309,7 @@ func NewHotelOffersTool(c *Client, gk *genkit.Genkit, registry *tools.Registry)
 		if err := json.Unmarshal(b, in); err != nil {
 			return nil, fmt.Errorf("failed to parse arguments: %w", err)
 		}
-		return t.Execute(ctx, in)
+		return run(ctx, in)
 	})
 	return t
 }
@@ -286,7 +338,8 @@ func (t *HotelOffersTool) Execute(ctx context.Context, input *HotelOffersInput)
 
 	// Construct temporary accommodation object for the search
 	acc := &pb.Accommodation{
-		TravelerCount: int32(adults),
+		TravelerCount: int32(adults + len(input.ChildAges)),
+		ChildAges:     input.ChildAges,
 		CheckIn:       timestamppb.New(parseDate(input.CheckIn)),
 		CheckOut:      timestamppb.New(parseDate(input.CheckOut)),
 		Cost: &pb.Cost{
@@ -315,7 +368,7 @@ func (t *LocationTool) Name() string {
 }
 
 func (t *LocationTool) Description() string {
-	return "Searches for cities and airports. Arguments: keyword (string, e.g. 'Paris'). Returns a list of Location objects. Use full city/location name, instead of abbreviations."
+	return "Searches for cities and airports. Arguments: keyword (string, e.g. 'Paris'), exact (bool, optional: true to look up only that place, e.g. an airport code, without the airports near it). Returns a list of Location objects. Use full city/location name, instead of abbreviations."
 }
 
 func (t *LocationTool) Execute(ctx context.Context, input *LocationInput) ([]*pb.Location, error) {
@@ -330,7 +383,7 @@ func (t *LocationTool) Execute(ctx context.Context, input *LocationInput) ([]*pb
 		return nil, fmt.Errorf("keyword is required")
 	}
 
-	resp, err := t.Client.SearchLocations(ctx, input.Keyword)
+	resp, err := t.Client.SearchLocations(ctx, input.Keyword, !input.Exact)
 	if err != nil {
 		log.Errorf(ctx, "LocationTool failed: %v", err)
 		return nil, err // Returning error as is
@@ -345,23 +398,224 @@ func NewLocationTool(c *Client, gk *genkit.Genkit, registry *tools.Registry) *Lo
 	if gk == nil || registry == nil {
 		return t
 	}
+	run := func(ctx context.Context, input *LocationInput) ([]*pb.Location, error) {
+		resp, err := t.Execute(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		return capForModel(ctx, registry, "LocationTool", resp, nil), nil
+	}
 	registry.Register(genkit.DefineTool[*LocationInput, []*pb.Location](
 		gk,
-		"amadeus_location_tool",
+		ToolPrefix+"location_tool",
 		t.Description(),
 		func(ctx *ai.ToolContext, input *LocationInput) ([]*pb.Location, error) {
-			return t.Execute(ctx, input)
+			return tools.Run(ctx, ToolPrefix+"location_tool", c.MapError, input, run)
 		},
 	), func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 		keyword, ok := args["keyword"].(string)
 		if !ok {
 			return nil, fmt.Errorf("keyword is required")
 		}
-		return t.Execute(ctx, &LocationInput{Keyword: keyword})
+		exact, _ := args["exact"].(bool)
+		return run(ctx, &LocationInput{Keyword: keyword, Exact: exact})
+	})
+	return t
+}
+
+// PriceCalendarTool implementation
+type PriceCalendarTool struct {
+	Client *Client
+}
+
+func (t *PriceCalendarTool) Description() string {
+	return "Returns indicative lowest one-way prices for every day of a month between two airports/cities. Arguments: origin (IATA code), destination (IATA code), month (YYYY-MM), adults (int). Use it for flexible-date queries (e.g. 'cheapest day to fly in March') before picking travel dates. Days marked interpolated are estimates."
+}
+
+func (t *PriceCalendarTool) Execute(ctx context.Context, input *PriceCalendarInput) (*pb.PriceCalendar, error) {
+	inputJSON, _ := json.Marshal(input)
+	log.Debugf(ctx, "PriceCalendarTool executing with input: %s", string(inputJSON))
+
+	if t.Client == nil {
+		return nil, fmt.Errorf("amadeus client not initialized")
+	}
+	if input == nil || input.Origin == "" || input.Destination == "" || input.Month == "" {
+		return nil, fmt.Errorf("origin, destination and month are required")
+	}
+
+	month, err := time.Parse("2006-01", input.Month)
+	if err != nil {
+		return nil, fmt.Errorf("month must be in YYYY-MM format: %w", err)
+	}
+
+	resp, err := t.Client.GetPriceCalendar(ctx, input.Origin, input.Destination, month, input.Adults, input.Currency)
+	if err != nil {
+		log.Errorf(ctx, "PriceCalendarTool failed: %v", err)
+		return nil, err
+	}
+	log.Debugf(ctx, "PriceCalendarTool completed successfully. Found %d days.", len(resp.Days))
+	return resp, nil
+}
+
+// NewPriceCalendarTool initializes and registers the PriceCalendarTool
+func NewPriceCalendarTool(c *Client, gk *genkit.Genkit, registry *tools.Registry) *PriceCalendarTool {
+	t := &PriceCalendarTool{Client: c}
+	if gk == nil || registry == nil {
+		return t
+	}
+	registry.Register(genkit.DefineTool[*PriceCalendarInput, *pb.PriceCalendar](
+		gk,
+		ToolPrefix+"price_calendar",
+		t.Description(),
+		func(ctx *ai.ToolContext, input *PriceCalendarInput) (*pb.PriceCalendar, error) {
+			return tools.Run(ctx, ToolPrefix+"price_calendar", c.MapError, input, t.Execute)
+		},
+	), func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		in := &PriceCalendarInput{}
+		b, _ := json.Marshal(args)
+		if err := json.Unmarshal(b, in); err != nil {
+			return nil, fmt.Errorf("failed to parse arguments: %w", err)
+		}
+		return t.Execute(ctx, in)
+	})
+	return t
+}
+
+// FareTrendTool implementation
+type FareTrendTool struct {
+	Client *Client
+}
+
+func (t *FareTrendTool) Description() string {
+	return "Returns the indicative lowest one-way price for every week in a date range (up to 26 weeks) between two airports/cities, and the cheapest week. Arguments: origin (IATA code), destination (IATA code), from_date (YYYY-MM-DD), to_date (YYYY-MM-DD), adults (int). Use it for 'when is it cheapest to fly' questions spanning several weeks or months, then use amadeus_price_calendar to pick a day. Weeks marked interpolated are estimates."
+}
+
+func (t *FareTrendTool) Execute(ctx context.Context, input *FareTrendInput) (*pb.FareTrend, error) {
+	inputJSON, _ := json.Marshal(input)
+	log.Debugf(ctx, "FareTrendTool executing with input: %s", string(inputJSON))
+
+	if t.Client == nil {
+		return nil, fmt.Errorf("amadeus client not initialized")
+	}
+	if input == nil || input.Origin == "" || input.Destination == "" || input.FromDate == "" || input.ToDate == "" {
+		return nil, fmt.Errorf("origin, destination, from_date and to_date are required")
+	}
+
+	from, err := time.Parse("2006-01-02", input.FromDate)
+	if err != nil {
+		return nil, fmt.Errorf("from_date must be in YYYY-MM-DD format: %w", err)
+	}
+	to, err := time.Parse("2006-01-02", input.ToDate)
+	if err != nil {
+		return nil, fmt.Errorf("to_date must be in YYYY-MM-DD format: %w", err)
+	}
+
+	resp, err := t.Client.GetFareTrend(ctx, input.Origin, input.Destination, from, to, input.Adults, input.Currency)
+	if err != nil {
+		log.Errorf(ctx, "FareTrendTool failed: %v", err)
+		return nil, err
+	}
+	log.Debugf(ctx, "FareTrendTool completed successfully. Found %d weeks.", len(resp.Weeks))
+	return resp, nil
+}
+
+// NewFareTrendTool initializes and registers the FareTrendTool
+func NewFareTrendTool(c *Client, gk *genkit.Genkit, registry *tools.Registry) *FareTrendTool {
+	t := &FareTrendTool{Client: c}
+	if gk == nil || registry == nil {
+		return t
+	}
+	registry.Register(genkit.DefineTool[*FareTrendInput, *pb.FareTrend](
+		gk,
+		ToolPrefix+"fare_trend",
+		t.Description(),
+		func(ctx *ai.ToolContext, input *FareTrendInput) (*pb.FareTrend, error) {
+			return tools.Run(ctx, ToolPrefix+"fare_trend", c.MapError, input, t.Execute)
+		},
+	), func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		in := &FareTrendInput{}
+		b, _ := json.Marshal(args)
+		if err := json.Unmarshal(b, in); err != nil {
+			return nil, fmt.Errorf("failed to parse arguments: %w", err)
+		}
+		return t.Execute(ctx, in)
 	})
 	return t
 }
 
+// HotelAmenitiesInput takes no arguments
+type HotelAmenitiesInput struct{}
+
+// HotelAmenitiesTool lists the amenity codes the hotel searches accept
+type HotelAmenitiesTool struct{}
+
+func (t *HotelAmenitiesTool) Description() string {
+	return "Lists the hotel amenity codes Amadeus recognizes, e.g. WIFI, SWIMMING_POOL, PETS_ALLOWED. Use these codes for the amenities of amadeus_hotel_list and of accommodation preferences; other amenities are ignored."
+}
+
+func (t *HotelAmenitiesTool) Execute(ctx context.Context, input *HotelAmenitiesInput) ([]string, error) {
+	return append([]string(nil), HotelAmenities...), nil
+}
+
+// NewHotelAmenitiesTool initializes and registers the HotelAmenitiesTool
+func NewHotelAmenitiesTool(gk *genkit.Genkit, registry *tools.Registry) *HotelAmenitiesTool {
+	t := &HotelAmenitiesTool{}
+	if gk == nil || registry == nil {
+		return t
+	}
+	registry.Register(genkit.DefineTool[*HotelAmenitiesInput, []string](
+		gk,
+		ToolPrefix+"hotel_amenities",
+		t.Description(),
+		func(ctx *ai.ToolContext, input *HotelAmenitiesInput) ([]string, error) {
+			return tools.Run(ctx, ToolPrefix+"hotel_amenities", nil, input, t.Execute)
+		},
+	), func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return t.Execute(ctx, &HotelAmenitiesInput{})
+	})
+	return t
+}
+
+// capForModel trims a list tool result to the registry's size cap before it is
+// handed back to the model and appended to the planning history
+func capForModel[T any](ctx context.Context, registry *tools.Registry, tool string, items []T, slim func(T) T) []T {
+	capped, dropped := tools.CapList(items, registry.MaxResultBytes(), slim)
+	if dropped > 0 {
+		log.Debugf(ctx, "%s: Result capped to %d of %d items (max %d bytes)", tool, len(capped), len(items), registry.MaxResultBytes())
+	}
+	return capped
+}
+
+// slimTransport drops the fields the planner does not need from a flight offer:
+// per-segment details, baggage and ancillary pricing, and location extras
+func slimTransport(t *pb.Transport) *pb.Transport {
+	c := proto.Clone(t).(*pb.Transport)
+	c.OriginLocation = slimLocation(c.OriginLocation)
+	c.DestinationLocation = slimLocation(c.DestinationLocation)
+	if f := c.GetFlight(); f != nil {
+		f.Segments = nil
+		f.BaggagePolicy = nil
+		f.AncillaryCosts = nil
+		f.TotalCostWithAncillaries = nil
+	}
+	return c
+}
+
+// slimAccommodation drops location extras from a hotel offer
+func slimAccommodation(a *pb.Accommodation) *pb.Accommodation {
+	c := proto.Clone(a).(*pb.Accommodation)
+	c.Location = slimLocation(c.Location)
+	return c
+}
+
+// slimLocation keeps only the names and codes of a location
+func slimLocation(l *pb.Location) *pb.Location {
+	if l == nil {
+		return nil
+	}
+	return &pb.Location{City: l.City, Country: l.Country, IataCodes: l.IataCodes, CityCode: l.CityCode, Name: l.Name}
+}
+
 // currencyOrDefault returns the currency if not empty, otherwise returns the default value
 func currencyOrDefault(c, def string) string {
 	if c == "" {
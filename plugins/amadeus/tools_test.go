@@ -0,0 +1,90 @@
+package amadeus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/firebase/genkit/go/genkit"
+	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/pb"
+	"github.com/va6996/travelingman/tools"
+)
+
+func TestFlightTool_CapsResultSize(t *testing.T) {
+	const offers = 40
+	const maxBytes = 4096
+
+	client := newCalendarTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/security/oauth2/token":
+			writeToken(w)
+		case "/v2/shopping/flight-offers":
+			resp := FlightSearchResponse{}
+			for i := 0; i < offers; i++ {
+				legs := []Segment{
+					{Departure: FlightEndPoint{IataCode: "JFK", At: "2027-05-01T08:00:00"}, Arrival: FlightEndPoint{IataCode: "LHR", At: "2027-05-01T20:00:00"}, CarrierCode: "BA", Number: fmt.Sprint(100 + i), Duration: "PT7H"},
+					{Departure: FlightEndPoint{IataCode: "LHR", At: "2027-05-01T22:00:00"}, Arrival: FlightEndPoint{IataCode: "LIS", At: "2027-05-02T00:45:00"}, CarrierCode: "BA", Number: fmt.Sprint(500 + i), Duration: "PT2H45M"},
+				}
+				resp.Data = append(resp.Data, FlightOffer{
+					ID:          fmt.Sprint(i + 1),
+					Itineraries: []Itinerary{{Duration: "PT11H45M", Segments: legs}},
+					Price:       Price{Currency: "USD", Total: formatPrice(400 + float64(i))},
+				})
+			}
+			json.NewEncoder(w).Encode(resp)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	ctx := context.Background()
+	gk := genkit.Init(ctx)
+	registry := tools.NewRegistry()
+	registry.SetMaxResultBytes(maxBytes)
+	NewFlightTool(client, gk, registry)
+
+	tool, ok := registry.Lookup("amadeus_flight_tool")
+	if !assert.True(t, ok) {
+		return
+	}
+	out, err := tool.RunRaw(ctx, map[string]any{
+		"origin":      map[string]any{"iata_codes": []string{"JFK"}},
+		"destination": map[string]any{"iata_codes": []string{"LIS"}},
+		"date":        "2027-05-01",
+		"adults":      1,
+	})
+	if err != nil {
+		t.Fatalf("RunRaw failed: %v", err)
+	}
+
+	// This is what the model sees as the tool response
+	b, err := json.Marshal(out)
+	if err != nil {
+		t.Fatalf("Failed to encode result: %v", err)
+	}
+	assert.LessOrEqual(t, len(b), maxBytes, "the result must fit the configured cap")
+
+	var got []struct {
+		Cost    *pb.Cost
+		Details struct {
+			Flight *pb.Flight
+		}
+	}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("Failed to decode result: %v", err)
+	}
+	if !assert.NotEmpty(t, got) {
+		return
+	}
+	assert.Less(t, len(got), offers, "trailing offers are dropped")
+
+	// Offers keep what the planner needs; segment details are dropped
+	first := got[0]
+	assert.Equal(t, 400.0, first.Cost.GetValue())
+	assert.Equal(t, "BA", first.Details.Flight.GetCarrierCode())
+	assert.Empty(t, first.Details.Flight.GetSegments())
+}
@@ -0,0 +1,190 @@
+package amadeus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tmcontext "github.com/va6996/travelingman/context"
+	"github.com/va6996/travelingman/log"
+	"github.com/va6996/travelingman/pb"
+)
+
+// ErrInvalidTravelers is returned when the travelers of a booking cannot be sent as
+// they are; the wrapping error says why
+var ErrInvalidTravelers = errors.New("invalid travelers")
+
+const (
+	// infantAge is the age below which a traveler flies on an adult's lap
+	infantAge = 2
+	// adultAge is the age from which Amadeus prices a traveler as an adult
+	adultAge = 12
+)
+
+// travelerPayload is the travelers part of a flight order
+type travelerPayload struct {
+	Travelers []TravelerInfo
+	// Pricings are the offer's traveler pricings with each held infant's adult set
+	Pricings []TravelerPricing
+	// UserIDs maps each Amadeus traveler ID to our user ID, 0 for users without one
+	UserIDs map[string]int64
+}
+
+// buildTravelers turns the users booked onto an offer into order travelers. Users
+// listed twice, by ID or email, are booked once. Travelers are numbered "1".."n" in
+// order, as the offer's pricings are, rather than by user ID, so users without an ID
+// such as infants can be booked. Every infant is seated with an adult of its own.
+func buildTravelers(ctx context.Context, offer FlightOffer, users []*pb.User) (*travelerPayload, error) {
+	p := &travelerPayload{UserIDs: map[string]int64{}}
+	departure := offerDeparture(ctx, offer)
+
+	seen := map[string]bool{}
+	var adults, infants []string
+	for _, user := range users {
+		if user == nil {
+			continue
+		}
+		keys := userKeys(user)
+		if duplicate := seenAny(seen, keys); duplicate != "" {
+			log.Warnf(ctx, "BookFlight: skipping duplicate traveler %s", duplicate)
+			continue
+		}
+		for _, k := range keys {
+			seen[k] = true
+		}
+
+		id := fmt.Sprintf("%d", len(p.Travelers)+1)
+		p.Travelers = append(p.Travelers, travelerInfo(id, user))
+		p.UserIDs[id] = user.Id
+
+		switch age := ageAt(user, departure); {
+		case age < infantAge:
+			infants = append(infants, id)
+		case age >= adultAge:
+			adults = append(adults, id)
+		}
+	}
+
+	if n := len(offer.TravelerPricings); n > 0 && n != len(p.Travelers) {
+		return nil, fmt.Errorf("%w: offer is priced for %d travelers but %d are booked", ErrInvalidTravelers, n, len(p.Travelers))
+	}
+	if len(infants) > len(adults) {
+		return nil, fmt.Errorf("%w: %d infants need an adult each but only %d adults are booked", ErrInvalidTravelers, len(infants), len(adults))
+	}
+
+	// Infants sit with the adults in booking order
+	adultOf := make(map[string]string, len(infants))
+	for i, infant := range infants {
+		adultOf[infant] = adults[i]
+	}
+	p.Pricings = make([]TravelerPricing, len(offer.TravelerPricings))
+	for i, tp := range offer.TravelerPricings {
+		adult, isInfant := adultOf[tp.TravelerID]
+		switch {
+		case isInfant && tp.TravelerType != "HELD_INFANT":
+			return nil, fmt.Errorf("%w: traveler %s is an infant but is priced as %s", ErrInvalidTravelers, tp.TravelerID, tp.TravelerType)
+		case !isInfant && tp.TravelerType == "HELD_INFANT":
+			return nil, fmt.Errorf("%w: traveler %s is priced as an infant but is not one", ErrInvalidTravelers, tp.TravelerID)
+		case isInfant:
+			tp.AssociatedAdultID = adult
+		}
+		p.Pricings[i] = tp
+	}
+	return p, nil
+}
+
+// AssignTravelers returns the user each of the offer's travelers will be booked as,
+// keyed by traveler ID, and the offer's pricings with each held infant's adult set, as
+// BookFlight sends them. It fails with ErrInvalidTravelers where BookFlight would, so
+// a booking can be checked before the order is placed.
+func AssignTravelers(ctx context.Context, offer FlightOffer, users []*pb.User) (map[string]int64, []TravelerPricing, error) {
+	p, err := buildTravelers(ctx, offer, users)
+	if err != nil {
+		return nil, nil, err
+	}
+	return p.UserIDs, p.Pricings, nil
+}
+
+// userKeys are what identifies a user as already booked: its ID and email, if set
+func userKeys(user *pb.User) []string {
+	var keys []string
+	if user.Id != 0 {
+		keys = append(keys, fmt.Sprintf("id %d", user.Id))
+	}
+	if email := strings.ToLower(strings.TrimSpace(user.Email)); email != "" {
+		keys = append(keys, "email "+email)
+	}
+	return keys
+}
+
+func seenAny(seen map[string]bool, keys []string) string {
+	for _, k := range keys {
+		if seen[k] {
+			return k
+		}
+	}
+	return ""
+}
+
+// offerDeparture is when the offer's first flight leaves, or now if it has none
+func offerDeparture(ctx context.Context, offer FlightOffer) time.Time {
+	if len(offer.Itineraries) > 0 && len(offer.Itineraries[0].Segments) > 0 {
+		if t, err := time.Parse("2006-01-02T15:04:05", offer.Itineraries[0].Segments[0].Departure.At); err == nil {
+			return t
+		}
+	}
+	return tmcontext.Now(ctx)
+}
+
+// ageAt is the user's age in whole years at t. Users without a date of birth count
+// as adults.
+func ageAt(user *pb.User, t time.Time) int {
+	if user.DateOfBirth == nil {
+		return adultAge
+	}
+	dob := user.DateOfBirth.AsTime()
+	age := t.Year() - dob.Year()
+	if t.Month() < dob.Month() || t.Month() == dob.Month() && t.Day() < dob.Day() {
+		age--
+	}
+	return age
+}
+
+// travelerInfo builds the order traveler for a user
+func travelerInfo(id string, user *pb.User) TravelerInfo {
+	traveler := TravelerInfo{
+		ID:          id,
+		DateOfBirth: user.DateOfBirth.AsTime().Format("2006-01-02"),
+		Name: Name{
+			FirstName: getFirstName(user.FullName),
+			LastName:  getLastName(user.FullName),
+		},
+		Gender: user.Gender,
+		Contact: &Contact{
+			EmailAddress: user.Email,
+			Address:      formatAddress(user.Address),
+		},
+	}
+	if phone, ok := formatPhone(user.Phone, travelerCountry(user)); ok {
+		traveler.Contact.Phones = []Phone{phone}
+	}
+
+	if len(user.Passports) > 0 {
+		passport := user.Passports[0]
+		traveler.Documents = append(traveler.Documents, Document{
+			DocumentType:     "PASSPORT",
+			BirthPlace:       passport.BirthPlace,
+			IssuanceLocation: passport.IssuanceLocation,
+			IssuanceDate:     passport.IssuanceDate.AsTime().Format("2006-01-02"),
+			Number:           passport.Number,
+			ExpiryDate:       passport.ExpiryDate.AsTime().Format("2006-01-02"),
+			IssuanceCountry:  passport.IssuingCountry,
+			ValidityCountry:  passport.IssuingCountry,
+			Nationality:      passport.Nationality,
+			Holder:           true,
+		})
+	}
+	return traveler
+}
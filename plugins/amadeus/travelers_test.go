@@ -0,0 +1,199 @@
+package amadeus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func traveler(id int64, email string, born time.Time) *pb.User {
+	return &pb.User{Id: id, FullName: "Test Traveler", Email: email, DateOfBirth: timestamppb.New(born)}
+}
+
+// pricedOffer is an offer departing on 1 June 2026, priced for the given traveler types
+func pricedOffer(types ...string) FlightOffer {
+	seg := Segment{CarrierCode: "BA", Number: "178"}
+	seg.Departure = FlightEndPoint{IataCode: "JFK", At: "2026-06-01T09:30:00"}
+	offer := FlightOffer{ID: "1", Itineraries: []Itinerary{{Segments: []Segment{seg}}}}
+	for i, t := range types {
+		offer.TravelerPricings = append(offer.TravelerPricings, TravelerPricing{TravelerID: string(rune('1' + i)), TravelerType: t})
+	}
+	return offer
+}
+
+var (
+	adultBorn  = time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)
+	infantBorn = time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func TestBuildTravelers(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Duplicates", func(t *testing.T) {
+		// The same user, by ID or by email, is booked once
+		users := []*pb.User{
+			traveler(7, "ann@example.com", adultBorn),
+			traveler(9, "bob@example.com", adultBorn),
+			traveler(7, "ann@example.com", adultBorn),
+			traveler(0, "BOB@example.com", adultBorn),
+		}
+		p, err := buildTravelers(ctx, pricedOffer("ADULT", "ADULT"), users)
+		if assert.NoError(t, err) {
+			assert.Len(t, p.Travelers, 2)
+			assert.Equal(t, map[string]int64{"1": 7, "2": 9}, p.UserIDs)
+		}
+	})
+
+	t.Run("InfantWithAdult", func(t *testing.T) {
+		// The infant has no ID of its own and sits with the first adult
+		users := []*pb.User{traveler(7, "ann@example.com", adultBorn), traveler(0, "", infantBorn)}
+		p, err := buildTravelers(ctx, pricedOffer("ADULT", "HELD_INFANT"), users)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "1", p.Travelers[0].ID)
+			assert.Equal(t, "2", p.Travelers[1].ID)
+			assert.Equal(t, map[string]int64{"1": 7, "2": 0}, p.UserIDs)
+			assert.Equal(t, "", p.Pricings[0].AssociatedAdultID)
+			assert.Equal(t, "1", p.Pricings[1].AssociatedAdultID)
+		}
+	})
+
+	t.Run("InfantWithoutAdult", func(t *testing.T) {
+		users := []*pb.User{
+			traveler(7, "ann@example.com", adultBorn),
+			traveler(0, "", infantBorn),
+			traveler(0, "", infantBorn.AddDate(0, 1, 0)),
+		}
+		_, err := buildTravelers(ctx, pricedOffer("ADULT", "HELD_INFANT", "HELD_INFANT"), users)
+		assert.ErrorIs(t, err, ErrInvalidTravelers)
+		assert.ErrorContains(t, err, "2 infants need an adult each but only 1 adults are booked")
+	})
+
+	t.Run("InfantPricedAsAdult", func(t *testing.T) {
+		users := []*pb.User{traveler(7, "ann@example.com", adultBorn), traveler(0, "", infantBorn)}
+		_, err := buildTravelers(ctx, pricedOffer("ADULT", "ADULT"), users)
+		assert.ErrorIs(t, err, ErrInvalidTravelers)
+		assert.ErrorContains(t, err, "traveler 2 is an infant but is priced as ADULT")
+	})
+
+	t.Run("CountMismatch", func(t *testing.T) {
+		users := []*pb.User{traveler(7, "ann@example.com", adultBorn)}
+		_, err := buildTravelers(ctx, pricedOffer("ADULT", "ADULT"), users)
+		assert.ErrorIs(t, err, ErrInvalidTravelers)
+		assert.ErrorContains(t, err, "offer is priced for 2 travelers but 1 are booked")
+	})
+}
+
+func TestBookFlight_TravelerIDs(t *testing.T) {
+	var sent FlightOrderRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/security/oauth2/token":
+			json.NewEncoder(w).Encode(AuthToken{AccessToken: "test_token", ExpiresIn: 1800})
+		case "/v1/booking/flight-orders":
+			json.NewDecoder(r.Body).Decode(&sent)
+			var order FlightOrderResponse
+			order.Data.ID = "order_123"
+			order.Data.Travelers = sent.Data.Travelers
+			json.NewEncoder(w).Encode(order)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(Config{
+		ClientID: "id", ClientSecret: "secret", IsProduction: false,
+		FlightLimit: 10, HotelLimit: 10, Timeout: 10,
+		CacheTTL: CacheTTLConfig{Location: 24, Flight: 24, Hotel: 24},
+	}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	client.BaseURL = ts.URL
+
+	offer := pricedOffer("ADULT", "ADULT", "HELD_INFANT")
+	users := []*pb.User{
+		traveler(42, "ann@example.com", adultBorn),
+		traveler(42, "ann@example.com", adultBorn),
+		traveler(17, "bob@example.com", adultBorn),
+		traveler(0, "", infantBorn),
+	}
+	resp, err := client.BookFlight(context.Background(), offer, users)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Amadeus sees travelers 1..3, and each maps back to the user it was booked for
+	if assert.Len(t, resp.Data.Travelers, 3) {
+		for i, tr := range resp.Data.Travelers {
+			assert.Equal(t, string(rune('1'+i)), tr.ID)
+		}
+	}
+	assert.Equal(t, map[string]int64{"1": 42, "2": 17, "3": 0}, resp.TravelerUserIDs)
+	assert.Equal(t, "1", sent.Data.FlightOffers[0].TravelerPricings[2].AssociatedAdultID)
+
+	// The caller's offer is left as it was
+	assert.Equal(t, "", offer.TravelerPricings[2].AssociatedAdultID)
+}
+
+func TestTravelerContact(t *testing.T) {
+	t.Run("UK", func(t *testing.T) {
+		user := &pb.User{
+			FullName: "Ada Lovelace",
+			Email:    "ada@example.co.uk",
+			Phone:    "020 7946 0958",
+			Address:  &pb.PostalAddress{Lines: []string{"10  Downing Street"}, PostalCode: "sw1a2aa", City: "London", CountryCode: "gb"},
+		}
+		contact := travelerInfo("1", user).Contact
+		assert.Equal(t, []Phone{{DeviceType: "MOBILE", CountryCallingCode: "44", Number: "2079460958"}}, contact.Phones)
+		assert.Equal(t, &PostalAddress{Lines: []string{"10 Downing Street"}, PostalCode: "SW1A 2AA", CountryCode: "GB", CityName: "London"}, contact.Address)
+		assert.Equal(t, "+442079460958", NewHotelGuest(1, user).Phone)
+	})
+
+	t.Run("Japan", func(t *testing.T) {
+		// Without an address the passport says where the traveler is from
+		user := &pb.User{
+			FullName:  "Kenji Sato",
+			Phone:     "090-1234-5678",
+			Passports: []*pb.Passport{{Number: "TK1234567", Nationality: "JP", IssuingCountry: "JP"}},
+		}
+		contact := travelerInfo("1", user).Contact
+		assert.Equal(t, []Phone{{DeviceType: "MOBILE", CountryCallingCode: "81", Number: "9012345678"}}, contact.Phones)
+		assert.Nil(t, contact.Address)
+
+		user.Address = &pb.PostalAddress{Lines: []string{"1-1 Chiyoda"}, PostalCode: "1000001", City: "Chiyoda-ku, Tokyo", CountryCode: "JP"}
+		contact = travelerInfo("1", user).Contact
+		assert.Equal(t, "100-0001", contact.Address.PostalCode)
+		assert.Equal(t, "JP", contact.Address.CountryCode)
+
+		guest := NewHotelGuest(2, user)
+		assert.Equal(t, "+819012345678", guest.Phone)
+		assert.Equal(t, "Kenji", guest.FirstName)
+		assert.Equal(t, "Sato", guest.LastName)
+	})
+
+	t.Run("International", func(t *testing.T) {
+		// Numbers with their own calling code keep it whatever the traveler's country
+		for raw, want := range map[string]Phone{
+			"+44 (0)20 7946 0958": {DeviceType: "MOBILE", CountryCallingCode: "44", Number: "2079460958"},
+			"0081 3 1234 5678":    {DeviceType: "MOBILE", CountryCallingCode: "81", Number: "312345678"},
+			"+39 06 1234 5678":    {DeviceType: "MOBILE", CountryCallingCode: "39", Number: "0612345678"},
+			"(555) 123-4567":      {DeviceType: "MOBILE", CountryCallingCode: "1", Number: "5551234567"},
+		} {
+			phone, ok := formatPhone(raw, "")
+			assert.True(t, ok, raw)
+			assert.Equal(t, want, phone, raw)
+		}
+		_, ok := formatPhone("", "GB")
+		assert.False(t, ok)
+		_, ok = formatPhone("+7 495 123 4567", "")
+		assert.False(t, ok, "unknown calling codes are not sent")
+	})
+}
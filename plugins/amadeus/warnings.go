@@ -0,0 +1,165 @@
+package amadeus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	tmcontext "github.com/va6996/travelingman/context"
+	"github.com/va6996/travelingman/log"
+	"github.com/va6996/travelingman/pb"
+)
+
+// Warning is an entry of the top-level "warnings" array that Amadeus adds to successful
+// responses, e.g. a price change since shopping or carriers excluded from the search
+type Warning struct {
+	Code      string
+	Title     string
+	Detail    string
+	Pointer   string // JSON pointer to the part of the response the warning is about, if any
+	Parameter string // Request parameter the warning is about, if any
+}
+
+// Warnings decodes leniently: entries that do not look like a warning are skipped and a
+// "warnings" value that is not an array is ignored, so changes on the provider side never
+// fail an otherwise good response
+type Warnings []Warning
+
+func (w *Warnings) UnmarshalJSON(b []byte) error {
+	*w = nil
+	var entries []json.RawMessage
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return nil
+	}
+	for _, raw := range entries {
+		var entry struct {
+			Code   json.RawMessage `json:"code"`
+			Title  string          `json:"title"`
+			Detail string          `json:"detail"`
+			Source struct {
+				Pointer   string `json:"pointer"`
+				Parameter string `json:"parameter"`
+			} `json:"source"`
+		}
+		if err := json.Unmarshal(raw, &entry); err != nil {
+			continue
+		}
+		// Codes are numbers in the API reference but strings in some responses
+		code := strings.Trim(string(entry.Code), `"`)
+		if code == "null" {
+			code = ""
+		}
+		if code == "" && entry.Title == "" && entry.Detail == "" {
+			continue
+		}
+		*w = append(*w, Warning{
+			Code:      code,
+			Title:     entry.Title,
+			Detail:    entry.Detail,
+			Pointer:   entry.Source.Pointer,
+			Parameter: entry.Source.Parameter,
+		})
+	}
+	return nil
+}
+
+// Note formats the warning for display, e.g. "Amadeus 4926: PRICE CHANGED (fare increased)"
+func (w Warning) Note() string {
+	title, detail := w.Title, w.Detail
+	if title == "" {
+		title, detail = detail, ""
+	}
+	note := "Amadeus"
+	if w.Code != "" {
+		note += " " + w.Code
+	}
+	if title != "" {
+		note += ": " + title
+	}
+	if detail != "" && detail != title {
+		note += fmt.Sprintf(" (%s)", detail)
+	}
+	return note
+}
+
+var dataIndexPointer = regexp.MustCompile(`^/data/(\d+)(/|$)`)
+
+// dataIndex returns the index into the response's data array the warning points at.
+// ok is false for warnings about the whole result.
+func (w Warning) dataIndex() (int, bool) {
+	m := dataIndexPointer.FindStringSubmatch(w.Pointer)
+	if m == nil {
+		return 0, false
+	}
+	i, err := strconv.Atoi(m[1])
+	return i, err == nil
+}
+
+// recordWarnings logs the warnings of a response and counts them per code
+func recordWarnings(ctx context.Context, endpoint string, warnings Warnings) {
+	stats := tmcontext.RequestStatsFromContext(ctx)
+	for _, w := range warnings {
+		log.Infof(ctx, "%s: Provider warning: %s", endpoint, w.Note())
+		code := w.Code
+		if code == "" {
+			code = "unknown"
+		}
+		stats.AddProviderWarning("amadeus/" + code)
+	}
+}
+
+// withNote adds an INFO note to an option's error. Notes are appended to an existing
+// INFO error; a warning or error already on the option takes precedence and is kept.
+func withNote(existing *pb.Error, note string) *pb.Error {
+	if existing == nil {
+		return &pb.Error{Message: note, Severity: pb.ErrorSeverity_ERROR_SEVERITY_INFO}
+	}
+	if existing.Severity <= pb.ErrorSeverity_ERROR_SEVERITY_INFO && !strings.Contains(existing.Message, note) {
+		existing.Message += "; " + note
+		existing.Severity = pb.ErrorSeverity_ERROR_SEVERITY_INFO
+	}
+	return existing
+}
+
+// noteFlightWarnings attaches the warnings of a flight search to the options they are
+// about, and warnings about the whole search to every option. byData[i] holds the
+// option converted from data[i], or nil if that offer was left out.
+func noteFlightWarnings(warnings Warnings, byData []*pb.Transport) {
+	for _, w := range warnings {
+		if i, ok := w.dataIndex(); ok {
+			if i < len(byData) && byData[i] != nil {
+				byData[i].Error = withNote(byData[i].Error, w.Note())
+			}
+			continue
+		}
+		for _, t := range byData {
+			if t != nil {
+				t.Error = withNote(t.Error, w.Note())
+			}
+		}
+	}
+}
+
+// noteHotelWarnings attaches the warnings of a hotel offers search to the offers of the
+// hotel they are about, and warnings about the whole search to every offer.
+// byData[i] holds the offers converted from data[i].
+func noteHotelWarnings(warnings Warnings, byData [][]*pb.Accommodation) {
+	for _, w := range warnings {
+		if i, ok := w.dataIndex(); ok {
+			if i < len(byData) {
+				for _, acc := range byData[i] {
+					acc.Error = withNote(acc.Error, w.Note())
+				}
+			}
+			continue
+		}
+		for _, accs := range byData {
+			for _, acc := range accs {
+				acc.Error = withNote(acc.Error, w.Note())
+			}
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package amadeus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	tmcontext "github.com/va6996/travelingman/context"
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestSearchFlights_Warnings(t *testing.T) {
+	client := newCalendarTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/security/oauth2/token":
+			writeToken(w)
+		case "/v2/shopping/flight-offers":
+			w.Write([]byte(`{
+				"warnings": [
+					{"status": 200, "code": 12345, "title": "CARRIERS EXCLUDED", "detail": "Some carriers are not available"},
+					{"code": "4926", "title": "PRICE CHANGED", "detail": "Fare increased since shopping", "source": {"pointer": "/data/1/price"}},
+					"not a warning",
+					{"code": 1, "title": {"unexpected": true}}
+				],
+				"data": [
+					{"id": "1", "price": {"currency": "USD", "total": "300.00"}},
+					{"id": "2", "price": {"currency": "USD", "total": "320.00"}}
+				]
+			}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	stats := tmcontext.NewRequestStats()
+	ctx := tmcontext.WithRequestStats(context.Background(), stats)
+	transports, err := client.SearchFlights(ctx, &pb.Transport{
+		Type:                pb.TransportType_TRANSPORT_TYPE_FLIGHT,
+		TravelerCount:       1,
+		OriginLocation:      &pb.Location{IataCodes: []string{"JFK"}},
+		DestinationLocation: &pb.Location{IataCodes: []string{"LIS"}},
+		Cost:                &pb.Cost{Currency: "USD"},
+		Details: &pb.Transport_Flight{Flight: &pb.Flight{
+			DepartureTime: timestamppb.New(time.Now().AddDate(0, 1, 0)),
+		}},
+	})
+	if err != nil {
+		t.Fatalf("SearchFlights failed: %v", err)
+	}
+	if !assert.Len(t, transports, 2) {
+		return
+	}
+
+	// The search-wide warning is on every option, the price change only on the second
+	first, second := transports[0].Error, transports[1].Error
+	if assert.NotNil(t, first) {
+		assert.Equal(t, pb.ErrorSeverity_ERROR_SEVERITY_INFO, first.Severity)
+		assert.Equal(t, "Amadeus 12345: CARRIERS EXCLUDED (Some carriers are not available)", first.Message)
+	}
+	if assert.NotNil(t, second) {
+		assert.Equal(t, pb.ErrorSeverity_ERROR_SEVERITY_INFO, second.Severity)
+		assert.Contains(t, second.Message, "CARRIERS EXCLUDED")
+		assert.Contains(t, second.Message, "Amadeus 4926: PRICE CHANGED (Fare increased since shopping)")
+	}
+
+	// Malformed entries are skipped; the others are counted per code
+	assert.Contains(t, stats.String(), "warnings=amadeus/12345:1,amadeus/4926:1 ")
+}
+
+func TestSearchFlights_WarningsAfterStopFilter(t *testing.T) {
+	client := newCalendarTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/security/oauth2/token":
+			writeToken(w)
+		case "/v2/shopping/flight-offers":
+			// The first offer has two stops and is dropped by the stop cap
+			w.Write([]byte(`{
+				"warnings": [
+					{"code": "4926", "title": "PRICE CHANGED", "source": {"pointer": "/data/0/price"}},
+					{"code": "4927", "title": "FEW SEATS LEFT", "source": {"pointer": "/data/1"}}
+				],
+				"data": [
+					{"id": "1", "price": {"currency": "USD", "total": "250.00"}, "itineraries": [{"segments": [
+						{"departure": {"iataCode": "JFK", "at": "2030-06-01T08:00:00"}, "arrival": {"iataCode": "BOS", "at": "2030-06-01T09:00:00"}},
+						{"departure": {"iataCode": "BOS", "at": "2030-06-01T10:00:00"}, "arrival": {"iataCode": "DUB", "at": "2030-06-01T20:00:00"}},
+						{"departure": {"iataCode": "DUB", "at": "2030-06-02T07:00:00"}, "arrival": {"iataCode": "LIS", "at": "2030-06-02T10:00:00"}}
+					]}]},
+					{"id": "2", "price": {"currency": "USD", "total": "320.00"}, "itineraries": [{"segments": [
+						{"departure": {"iataCode": "JFK", "at": "2030-06-01T18:00:00"}, "arrival": {"iataCode": "LIS", "at": "2030-06-02T06:00:00"}}
+					]}]}
+				]
+			}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	transports, err := client.SearchFlights(context.Background(), &pb.Transport{
+		Type:                pb.TransportType_TRANSPORT_TYPE_FLIGHT,
+		TravelerCount:       1,
+		OriginLocation:      &pb.Location{IataCodes: []string{"JFK"}},
+		DestinationLocation: &pb.Location{IataCodes: []string{"LIS"}},
+		Cost:                &pb.Cost{Currency: "USD"},
+		FlightPreferences:   &pb.FlightPreferences{MaxStops: proto.Int32(1)},
+		Details: &pb.Transport_Flight{Flight: &pb.Flight{
+			DepartureTime: timestamppb.New(time.Now().AddDate(0, 1, 0)),
+		}},
+	})
+	if err != nil {
+		t.Fatalf("SearchFlights failed: %v", err)
+	}
+
+	// Only the warning about the offer that was kept is on its option
+	if assert.Len(t, transports, 1) && assert.NotNil(t, transports[0].Error) {
+		assert.Equal(t, "Amadeus 4927: FEW SEATS LEFT", transports[0].Error.Message)
+	}
+}
+
+func TestSearchHotelOffers_Warnings(t *testing.T) {
+	client := newCalendarTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/security/oauth2/token":
+			writeToken(w)
+		case "/v3/shopping/hotel-offers":
+			w.Write([]byte(`{
+				"data": [
+					{"available": true, "hotel": {"hotelId": "H1", "name": "Grand"}, "offers": [
+						{"id": "a", "price": {"currency": "EUR", "total": "200.00"}},
+						{"id": "b", "price": {"currency": "EUR", "total": "260.00"}}
+					]},
+					{"available": true, "hotel": {"hotelId": "H2", "name": "Budget"}, "offers": [
+						{"id": "c", "price": {"currency": "EUR", "total": "120.00"}}
+					]}
+				],
+				"warnings": [
+					{"code": 3289, "title": "RESTRICTED RATES", "source": {"pointer": "/data/0"}}
+				]
+			}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	accs, err := client.SearchHotelOffers(context.Background(), []string{"H1", "H2"}, &pb.Accommodation{
+		TravelerCount: 1,
+		CheckIn:       timestamppb.New(time.Date(2027, 5, 1, 0, 0, 0, 0, time.UTC)),
+		CheckOut:      timestamppb.New(time.Date(2027, 5, 3, 0, 0, 0, 0, time.UTC)),
+		Cost:          &pb.Cost{Currency: "EUR"},
+	})
+	if err != nil {
+		t.Fatalf("SearchHotelOffers failed: %v", err)
+	}
+	if !assert.Len(t, accs, 3) {
+		return
+	}
+	for _, a := range accs {
+		if a.HotelId == "H1" {
+			if assert.NotNil(t, a.Error, "offer %s", a.BookingReference) {
+				assert.Equal(t, "Amadeus 3289: RESTRICTED RATES", a.Error.Message)
+			}
+		} else {
+			assert.Nil(t, a.Error)
+		}
+	}
+}
+
+func TestWarnings_TolerateUnknownShapes(t *testing.T) {
+	var resp FlightSearchResponse
+	err := json.Unmarshal([]byte(`{"data": [{"id": "1"}], "warnings": {"code": 1, "title": "not an array"}}`), &resp)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	assert.Len(t, resp.Data, 1)
+	assert.Empty(t, resp.Warnings)
+
+	err = json.Unmarshal([]byte(`{"data": [], "warnings": [null, 42, {"code": null, "detail": "Only a detail"}]}`), &resp)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if assert.Len(t, resp.Warnings, 1) {
+		assert.Equal(t, "Amadeus: Only a detail", resp.Warnings[0].Note())
+	}
+}
@@ -0,0 +1,157 @@
+package core
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Generic minimum connection times, used for airports missing from the table
+const (
+	DefaultDomesticMCT      = 60 * time.Minute
+	DefaultInternationalMCT = 90 * time.Minute
+)
+
+//go:embed mct.csv
+var mctCSV string
+
+// MinConnectionTime holds an airport's minimum connection times in minutes.
+// Zero durations fall back to the generic defaults.
+type MinConnectionTime struct {
+	Country       string // ISO 3166-1 alpha-2 code of the airport's country
+	Domestic      int
+	International int
+	InterTerminal int // Used instead of the base time when the connection changes terminal and this is longer
+}
+
+// ConnectionTimes looks up minimum connection times (MCT) per airport.
+// A nil table uses only the generic defaults.
+type ConnectionTimes struct {
+	airports map[string]MinConnectionTime
+}
+
+var (
+	defaultConnectionTimes     *ConnectionTimes
+	defaultConnectionTimesOnce sync.Once
+)
+
+// DefaultConnectionTimes returns the built-in per-airport table
+func DefaultConnectionTimes() *ConnectionTimes {
+	defaultConnectionTimesOnce.Do(func() {
+		airports, err := parseMCT(mctCSV)
+		if err != nil {
+			panic(fmt.Sprintf("invalid embedded MCT table: %v", err))
+		}
+		defaultConnectionTimes = &ConnectionTimes{airports: airports}
+	})
+	return defaultConnectionTimes
+}
+
+// NewConnectionTimes returns the built-in table with corrections applied on top.
+// Overrides are keyed by IATA code; only their non-zero fields replace the built-in values.
+func NewConnectionTimes(overrides map[string]MinConnectionTime) *ConnectionTimes {
+	base := DefaultConnectionTimes()
+	airports := make(map[string]MinConnectionTime, len(base.airports)+len(overrides))
+	for code, mct := range base.airports {
+		airports[code] = mct
+	}
+	for code, o := range overrides {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		mct := airports[code]
+		if o.Country != "" {
+			mct.Country = strings.ToUpper(o.Country)
+		}
+		if o.Domestic > 0 {
+			mct.Domestic = o.Domestic
+		}
+		if o.International > 0 {
+			mct.International = o.International
+		}
+		if o.InterTerminal > 0 {
+			mct.InterTerminal = o.InterTerminal
+		}
+		airports[code] = mct
+	}
+	return &ConnectionTimes{airports: airports}
+}
+
+// Country returns the country of an airport, or "" if it is not in the table
+func (c *ConnectionTimes) Country(airport string) string {
+	if c == nil {
+		return ""
+	}
+	return c.airports[strings.ToUpper(airport)].Country
+}
+
+// IsInternational reports whether a connection at via, arriving from "from" and
+// leaving for "to", crosses a border. Airports of unknown country count as foreign,
+// so an unknown connection gets the longer international minimum.
+func (c *ConnectionTimes) IsInternational(from, via, to string) bool {
+	country := c.Country(via)
+	if country == "" {
+		return true
+	}
+	for _, code := range []string{from, to} {
+		if code != "" && c.Country(code) != country {
+			return true
+		}
+	}
+	return false
+}
+
+// Minimum returns the minimum connection time at an airport
+func (c *ConnectionTimes) Minimum(airport string, international, terminalChange bool) time.Duration {
+	var mct MinConnectionTime
+	if c != nil {
+		mct = c.airports[strings.ToUpper(airport)]
+	}
+
+	minimum := DefaultDomesticMCT
+	if mct.Domestic > 0 {
+		minimum = time.Duration(mct.Domestic) * time.Minute
+	}
+	if international {
+		minimum = DefaultInternationalMCT
+		if mct.International > 0 {
+			minimum = time.Duration(mct.International) * time.Minute
+		}
+	}
+	if terminalChange {
+		if t := time.Duration(mct.InterTerminal) * time.Minute; t > minimum {
+			minimum = t
+		}
+	}
+	return minimum
+}
+
+// parseMCT reads the iata,country,domestic,international,inter_terminal table
+func parseMCT(data string) (map[string]MinConnectionTime, error) {
+	r := csv.NewReader(strings.NewReader(data))
+	r.Comment = '#'
+	r.FieldsPerRecord = 5
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	airports := make(map[string]MinConnectionTime, len(records))
+	for _, rec := range records {
+		var minutes [3]int
+		for i, field := range rec[2:] {
+			if minutes[i], err = strconv.Atoi(strings.TrimSpace(field)); err != nil {
+				return nil, fmt.Errorf("%s: %w", rec[0], err)
+			}
+		}
+		airports[strings.TrimSpace(rec[0])] = MinConnectionTime{
+			Country:       strings.TrimSpace(rec[1]),
+			Domestic:      minutes[0],
+			International: minutes[1],
+			InterTerminal: minutes[2],
+		}
+	}
+	return airports, nil
+}
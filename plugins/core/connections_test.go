@@ -0,0 +1,54 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConnectionTimes_EmbeddedTable(t *testing.T) {
+	mct := DefaultConnectionTimes()
+
+	// Mega-hub: an international connection needs more than 70 minutes
+	assert.Equal(t, "GB", mct.Country("LHR"))
+	assert.Greater(t, mct.Minimum("LHR", true, false), 70*time.Minute)
+	assert.Greater(t, mct.Minimum("LHR", true, true), mct.Minimum("LHR", true, false), "changing terminal takes longer")
+
+	// Small airport: 70 minutes is plenty
+	assert.LessOrEqual(t, mct.Minimum("LCY", true, false), 70*time.Minute)
+
+	// Unknown airports use the generic defaults
+	assert.Equal(t, DefaultDomesticMCT, mct.Minimum("XXX", false, false))
+	assert.Equal(t, DefaultInternationalMCT, mct.Minimum("XXX", true, true))
+	var none *ConnectionTimes
+	assert.Equal(t, DefaultInternationalMCT, none.Minimum("LHR", true, false))
+}
+
+func TestConnectionTimes_IsInternational(t *testing.T) {
+	mct := DefaultConnectionTimes()
+	assert.False(t, mct.IsInternational("BOS", "JFK", "LAX"))
+	assert.True(t, mct.IsInternational("JFK", "LHR", "EDI"))
+	assert.True(t, mct.IsInternational("EDI", "LHR", "CDG"))
+	assert.True(t, mct.IsInternational("EDI", "LHR", "XXX"), "unknown airports count as foreign")
+	assert.True(t, mct.IsInternational("EDI", "XXX", "MAN"))
+}
+
+func TestNewConnectionTimes_Overrides(t *testing.T) {
+	mct := NewConnectionTimes(map[string]MinConnectionTime{
+		"lhr": {International: 75},
+		"INV": {Country: "GB", Domestic: 30, International: 45},
+	})
+
+	// Only the overridden field changes
+	assert.Equal(t, 75*time.Minute, mct.Minimum("LHR", true, false))
+	assert.Equal(t, 60*time.Minute, mct.Minimum("LHR", false, false))
+	assert.Equal(t, 105*time.Minute, mct.Minimum("LHR", true, true))
+
+	// New airports can be added
+	assert.Equal(t, 30*time.Minute, mct.Minimum("INV", false, false))
+	assert.False(t, mct.IsInternational("EDI", "INV", "LHR"))
+
+	// The built-in table is not modified
+	assert.Equal(t, 90*time.Minute, DefaultConnectionTimes().Minimum("LHR", true, false))
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/firebase/genkit/go/ai"
 	"github.com/firebase/genkit/go/genkit"
 	"github.com/sirupsen/logrus"
+	tmcontext "github.com/va6996/travelingman/context"
 	"github.com/va6996/travelingman/log"
 	"github.com/va6996/travelingman/tools"
 )
@@ -20,13 +21,15 @@ type DateInput struct {
 
 // DateTool provides current date functionality
 type DateTool struct {
-	Now func() time.Time
+	// Now is the clock 'now' is read from. A clock attached to the request
+	// context (tmcontext.WithClock) takes precedence.
+	Now tmcontext.Clock
 }
 
 // NewDateTool creates a new DateTool and registers it
 func NewDateTool(gk *genkit.Genkit, registry *tools.Registry) *DateTool {
 	t := &DateTool{
-		Now: time.Now,
+		Now: tmcontext.SystemClock,
 	}
 
 	if gk == nil || registry == nil {
@@ -38,7 +41,7 @@ func NewDateTool(gk *genkit.Genkit, registry *tools.Registry) *DateTool {
 		"dateTool",
 		t.Description(),
 		func(ctx *ai.ToolContext, input *DateInput) ([]time.Time, error) {
-			return t.Execute(ctx, input)
+			return tools.Run(ctx, "dateTool", nil, input, t.Execute)
 		},
 	), func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 		// Adapter for generic registry execution
@@ -73,7 +76,7 @@ func (t *DateTool) Execute(ctx context.Context, input *DateInput) ([]time.Time,
 	log.Infof(ctx, "[DateTool] Executing expression: %s", expression)
 
 	vm := goja.New()
-	err := vm.Set("now", t.Now().UnixMilli())
+	err := vm.Set("now", t.now(ctx).UnixMilli())
 	if err != nil {
 		return nil, fmt.Errorf("failed to set 'now': %w", err)
 	}
@@ -118,6 +121,17 @@ func (t *DateTool) Execute(ctx context.Context, input *DateInput) ([]time.Time,
 	return nil, fmt.Errorf("result is not a valid Date, ISO string, or array. Got Type: %T, Value: %v", exported, exported)
 }
 
+// now returns the current time from the request's clock, falling back to t.Now
+func (t *DateTool) now(ctx context.Context) time.Time {
+	if clock := tmcontext.ClockFromContext(ctx); clock != nil {
+		return clock()
+	}
+	if t.Now != nil {
+		return t.Now()
+	}
+	return time.Now()
+}
+
 func (t *DateTool) processArray(arr []interface{}) ([]time.Time, error) {
 	var dates []time.Time
 	for i, item := range arr {
@@ -7,6 +7,7 @@ import (
 
 	"github.com/firebase/genkit/go/genkit"
 	"github.com/stretchr/testify/assert"
+	tmcontext "github.com/va6996/travelingman/context"
 	"github.com/va6996/travelingman/tools"
 )
 
@@ -125,3 +126,41 @@ func TestDateTool_Execute_Validation(t *testing.T) {
 		assert.WithinDuration(t, expected2, res[1], time.Minute)
 	})
 }
+
+func TestDateTool_Execute_FrozenClock(t *testing.T) {
+	dt := NewDateTool(nil, nil)
+	// Thursday, half a minute before the year ends
+	now := time.Date(2026, 12, 31, 23, 59, 30, 0, time.UTC)
+	ctx := tmcontext.WithClock(context.Background(), tmcontext.FixedClock(now))
+
+	// "Next weekend": the coming Saturday, or the one after if today is Saturday
+	nextWeekend := &DateInput{Expression: "var d = new Date(now); d.setUTCHours(0, 0, 0, 0); d.setUTCDate(d.getUTCDate() + ((6 - d.getUTCDay() + 7) % 7 || 7)); [d]"}
+
+	first, err := dt.Execute(ctx, nextWeekend)
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{time.Date(2027, 1, 2, 0, 0, 0, 0, time.UTC)}, utc(first))
+
+	// Resolving again gives the same answer however much wall time has passed
+	second, err := dt.Execute(ctx, nextWeekend)
+	assert.NoError(t, err)
+	assert.Equal(t, utc(first), utc(second))
+
+	// The request's clock takes precedence over the tool's own
+	dt.Now = tmcontext.FixedClock(time.Date(2030, 6, 1, 0, 0, 0, 0, time.UTC))
+	third, err := dt.Execute(ctx, nextWeekend)
+	assert.NoError(t, err)
+	assert.Equal(t, utc(first), utc(third))
+
+	// Without one the tool's clock is used
+	fallback, err := dt.Execute(context.Background(), nextWeekend)
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{time.Date(2030, 6, 8, 0, 0, 0, 0, time.UTC)}, utc(fallback))
+}
+
+func utc(dates []time.Time) []time.Time {
+	out := make([]time.Time, len(dates))
+	for i, d := range dates {
+		out[i] = d.UTC()
+	}
+	return out
+}
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tmcontext "github.com/va6996/travelingman/context"
+	tmcore "github.com/va6996/travelingman/core"
+	"github.com/va6996/travelingman/log"
+	"github.com/va6996/travelingman/pb"
+)
+
+// maxGroundSpeedKmh is the fastest a flight covers the great-circle distance between
+// its airports, a jet with a strong tailwind
+const maxGroundSpeedKmh = 1200
+
+// CheckFeasibility is a cheap pass over an itinerary's flights, before any provider is
+// asked about them: their airport codes must look like IATA codes, they must not leave
+// in the past, and their times must allow for the distance flown, which an arrival
+// before the departure (usually a time zone mix-up) does not. Airports are placed by
+// their location's geocode, else by code in places; nil places, or an airport it does
+// not know, skips the distance check. Problems are returned as a *ValidationError.
+func CheckFeasibility(ctx context.Context, itinerary *pb.Itinerary, places *PlaceIndex) error {
+	var problems []string
+	checkFlightFeasibility(ctx, itinerary.GetGraph(), places, &problems)
+	if len(problems) > 0 {
+		err := &ValidationError{Problems: problems}
+		log.Errorf(ctx, "CheckFeasibility: %s", err)
+		return err
+	}
+	return nil
+}
+
+// checkFlightFeasibility appends the problems with the flights of g and its sub-graphs
+func checkFlightFeasibility(ctx context.Context, g *pb.Graph, places *PlaceIndex, problems *[]string) {
+	if g == nil {
+		return
+	}
+	// Same buffer as ValidateItinerary, for flights in time zones behind UTC
+	yesterday := tmcontext.Now(ctx).AddDate(0, 0, -1)
+	for i, edge := range g.Edges {
+		t := edge.GetTransport()
+		flight := t.GetFlight()
+		if flight == nil {
+			continue
+		}
+		name := fmt.Sprintf("Edge %d (%s -> %s)", i, edge.FromId, edge.ToId)
+
+		for _, loc := range []*pb.Location{t.OriginLocation, t.DestinationLocation} {
+			for _, code := range loc.GetIataCodes() {
+				if !isIATACode(code) {
+					*problems = append(*problems, fmt.Sprintf("%s: %q is not an airport code", name, code))
+				}
+			}
+		}
+
+		if flight.DepartureTime == nil {
+			continue
+		}
+		dep := flight.DepartureTime.AsTime()
+		if dep.Before(yesterday) {
+			*problems = append(*problems, fmt.Sprintf("%s: Flight departs in the past (%s)", name, dep.Format(time.RFC3339)))
+		}
+		if flight.ArrivalTime == nil {
+			continue
+		}
+		arr := flight.ArrivalTime.AsTime()
+		if !arr.After(dep) {
+			*problems = append(*problems, fmt.Sprintf("%s: Flight arrives (%s) before it departs (%s); check the times are in UTC", name, arr.Format(time.RFC3339), dep.Format(time.RFC3339)))
+			continue
+		}
+		if km, ok := places.distanceKm(t.OriginLocation, t.DestinationLocation); ok {
+			if hours, least := arr.Sub(dep).Hours(), km/maxGroundSpeedKmh; hours < least {
+				*problems = append(*problems, fmt.Sprintf("%s: Flight takes %.1fh, but its %.0f km need at least %.1fh", name, hours, km, least))
+			}
+		}
+	}
+	checkFlightFeasibility(ctx, g.SubGraph, places, problems)
+}
+
+// isIATACode reports whether s is three letters, as airport and city codes are
+func isIATACode(s string) bool {
+	if len(s) != 3 {
+		return false
+	}
+	for _, r := range strings.ToUpper(s) {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// distanceKm is the great-circle distance between two locations, if both can be placed
+func (x *PlaceIndex) distanceKm(a, b *pb.Location) (float64, bool) {
+	lat1, lng1, ok1 := x.locate(a)
+	lat2, lng2, ok2 := x.locate(b)
+	if !ok1 || !ok2 {
+		return 0, false
+	}
+	return tmcore.DistanceKm(lat1, lng1, lat2, lng2), true
+}
@@ -0,0 +1,60 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	tmcontext "github.com/va6996/travelingman/context"
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestCheckFeasibility(t *testing.T) {
+	now := time.Date(2030, 1, 7, 9, 0, 0, 0, time.UTC)
+	ctx := tmcontext.WithClock(context.Background(), tmcontext.FixedClock(now))
+	dep := now.AddDate(0, 1, 0)
+
+	flight := func(from, to string, dep time.Time, hours float64) *pb.Itinerary {
+		return &pb.Itinerary{Graph: &pb.Graph{Edges: []*pb.Edge{{
+			FromId: "a",
+			ToId:   "b",
+			Transport: &pb.Transport{
+				Type:                pb.TransportType_TRANSPORT_TYPE_FLIGHT,
+				OriginLocation:      &pb.Location{IataCodes: []string{from}},
+				DestinationLocation: &pb.Location{IataCodes: []string{to}},
+				Details: &pb.Transport_Flight{Flight: &pb.Flight{
+					DepartureTime: timestamppb.New(dep),
+					ArrivalTime:   timestamppb.New(dep.Add(time.Duration(hours * float64(time.Hour)))),
+				}},
+			},
+		}}}}
+	}
+
+	tests := []struct {
+		name      string
+		itinerary *pb.Itinerary
+		problem   string
+	}{
+		{"Feasible", flight("LHR", "JFK", dep, 8), ""},
+		{"UnknownAirportsAreNotPlaced", flight("ZZZ", "JFK", dep, 0.5), ""},
+		{"ArrivesBeforeDeparting", flight("LHR", "JFK", dep, -5), "Edge 0 (a -> b): Flight arrives (2030-02-07T04:00:00Z) before it departs (2030-02-07T09:00:00Z); check the times are in UTC"},
+		{"TooFast", flight("LHR", "JFK", dep, 1), "Edge 0 (a -> b): Flight takes 1.0h, but its 5540 km need at least 4.6h"},
+		{"NotAnAirportCode", flight("L1R", "JFK", dep, 8), `Edge 0 (a -> b): "L1R" is not an airport code`},
+		{"DepartsInThePast", flight("LHR", "JFK", now.AddDate(0, 0, -3), 8), "Edge 0 (a -> b): Flight departs in the past (2030-01-04T09:00:00Z)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckFeasibility(ctx, tt.itinerary, DefaultPlaceIndex())
+			if tt.problem == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var validationErr *ValidationError
+			if assert.ErrorAs(t, err, &validationErr) {
+				assert.Equal(t, []string{tt.problem}, validationErr.Problems)
+			}
+		})
+	}
+}
@@ -0,0 +1,369 @@
+package core
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	tmcore "github.com/va6996/travelingman/core"
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// Autocomplete result limits
+const (
+	DefaultAutocompleteLimit = 10
+	MaxAutocompleteLimit     = 50
+)
+
+//go:embed places.csv
+var placesCSV string
+
+// Match quality, best first
+const (
+	matchCode = iota
+	matchCity
+	matchAirport
+	matchFuzzy
+)
+
+// place is one city or airport in the index
+type place struct {
+	loc     *pb.Location
+	code    string
+	airport bool
+	size    int
+}
+
+// placeKey is a searchable word sequence of a place's city or airport name, e.g. both
+// "new york" and "york" for New York
+type placeKey struct {
+	text  string
+	place int
+	match int // matchCity or matchAirport
+}
+
+// PlaceIndex answers location autocomplete queries from memory. It is built once and
+// safe for concurrent use.
+type PlaceIndex struct {
+	places []place
+	codes  map[string][]int
+	keys   []placeKey // Sorted by text for prefix lookups
+}
+
+var (
+	defaultPlaceIndex     *PlaceIndex
+	defaultPlaceIndexOnce sync.Once
+)
+
+// DefaultPlaceIndex returns the index of the built-in city and airport table
+func DefaultPlaceIndex() *PlaceIndex {
+	defaultPlaceIndexOnce.Do(func() {
+		defaultPlaceIndex = NewPlaceIndex()
+	})
+	return defaultPlaceIndex
+}
+
+// NewPlaceIndex indexes the built-in table plus locations learned elsewhere, such as
+// from earlier provider lookups. A learned location with IATA codes is taken to be an
+// airport, otherwise a city.
+func NewPlaceIndex(learned ...*pb.Location) *PlaceIndex {
+	places, err := parsePlaces(placesCSV)
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded places table: %v", err))
+	}
+	for _, loc := range learned {
+		loc = proto.Clone(loc).(*pb.Location)
+		p := place{loc: loc, code: loc.CityCode, size: 2}
+		if len(loc.IataCodes) > 0 {
+			p.code, p.airport = loc.IataCodes[0], true
+		}
+		if p.code == "" && loc.City == "" {
+			continue
+		}
+		places = append(places, p)
+	}
+
+	x := &PlaceIndex{places: places, codes: map[string][]int{}}
+	cityAirports := map[string][]string{}
+	for i, p := range places {
+		if p.code != "" {
+			x.codes[strings.ToUpper(p.code)] = append(x.codes[strings.ToUpper(p.code)], i)
+		}
+		if p.airport {
+			cityAirports[p.loc.CityCode] = append(cityAirports[p.loc.CityCode], p.code)
+		}
+		for _, text := range wordSuffixes(p.loc.City) {
+			x.keys = append(x.keys, placeKey{text: text, place: i, match: matchCity})
+		}
+		if p.airport {
+			for _, text := range wordSuffixes(p.loc.Name) {
+				x.keys = append(x.keys, placeKey{text: text, place: i, match: matchAirport})
+			}
+		}
+	}
+	// A city lists its airports so that searching from it covers all of them
+	for _, p := range places {
+		if !p.airport && len(p.loc.IataCodes) == 0 {
+			p.loc.IataCodes = cityAirports[p.loc.CityCode]
+		}
+	}
+	sort.Slice(x.keys, func(i, j int) bool { return x.keys[i].text < x.keys[j].text })
+	return x
+}
+
+// Search returns up to limit places matching a query, best first: an exact IATA code,
+// then cities and airports whose city name starts with the query, then airports whose
+// name does, then close misspellings of either. Ties go to cities over airports and
+// bigger places over smaller ones.
+func (x *PlaceIndex) Search(query string, limit int) []*pb.Location {
+	if limit <= 0 {
+		limit = DefaultAutocompleteLimit
+	}
+	if limit > MaxAutocompleteLimit {
+		limit = MaxAutocompleteLimit
+	}
+	q := normalizePlace(query)
+	if q == "" {
+		return nil
+	}
+
+	type hit struct {
+		place, match, distance int
+	}
+	best := map[int]hit{}
+	record := func(h hit) {
+		if b, ok := best[h.place]; !ok || h.match < b.match || (h.match == b.match && h.distance < b.distance) {
+			best[h.place] = h
+		}
+	}
+
+	for _, i := range x.codes[strings.ToUpper(q)] {
+		record(hit{place: i, match: matchCode})
+	}
+	for k := sort.Search(len(x.keys), func(k int) bool { return x.keys[k].text >= q }); k < len(x.keys) && strings.HasPrefix(x.keys[k].text, q); k++ {
+		record(hit{place: x.keys[k].place, match: x.keys[k].match})
+	}
+	if maxEdits := fuzzyEdits(q); maxEdits > 0 {
+		runes := []rune(q)
+		for _, key := range x.keys {
+			if _, ok := best[key.place]; ok {
+				continue
+			}
+			prefix := []rune(key.text)
+			if len(prefix) > len(runes) {
+				prefix = prefix[:len(runes)]
+			}
+			if d := editDistance(runes, prefix); d <= maxEdits {
+				record(hit{place: key.place, match: matchFuzzy, distance: d})
+			}
+		}
+	}
+
+	hits := make([]hit, 0, len(best))
+	for _, h := range best {
+		hits = append(hits, h)
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		a, b := hits[i], hits[j]
+		first, second := x.places[a.place], x.places[b.place]
+		switch {
+		case a.match != b.match:
+			return a.match < b.match
+		case a.distance != b.distance:
+			return a.distance < b.distance
+		case first.airport != second.airport:
+			return !first.airport
+		case first.size != second.size:
+			return first.size < second.size
+		default:
+			return first.loc.Name < second.loc.Name
+		}
+	})
+
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	locs := make([]*pb.Location, len(hits))
+	for i, h := range hits {
+		locs[i] = proto.Clone(x.places[h.place].loc).(*pb.Location)
+	}
+	return locs
+}
+
+// NearbyCities returns the cities with an airport within radiusKm of loc, nearest
+// first, leaving out loc's own city. loc is placed by its geocode, or failing that by
+// its city or airport code in the index; nil is returned if it cannot be placed.
+func (x *PlaceIndex) NearbyCities(loc *pb.Location, radiusKm float64) []tmcore.NearbyCity {
+	lat, lng, ok := x.locate(loc)
+	if !ok {
+		return nil
+	}
+
+	// A city is listed once, as its city entry if it has one, at the distance of its
+	// nearest entry
+	byCity := map[string]*tmcore.NearbyCity{}
+	var order []string
+	for _, p := range x.places {
+		code := p.loc.CityCode
+		if code == "" || len(p.loc.IataCodes) == 0 || strings.EqualFold(code, loc.GetCityCode()) ||
+			(loc.GetCity() != "" && strings.EqualFold(p.loc.City, loc.GetCity()) && strings.EqualFold(p.loc.Country, loc.GetCountry())) {
+			continue
+		}
+		plat, plng, ok := tmcore.ParseGeocode(p.loc.Geocode)
+		if !ok {
+			continue
+		}
+		d := tmcore.DistanceKm(lat, lng, plat, plng)
+		c, seen := byCity[code]
+		if !seen {
+			c = &tmcore.NearbyCity{Location: p.loc, DistanceKm: d}
+			byCity[code] = c
+			order = append(order, code)
+		}
+		if !p.airport {
+			c.Location = p.loc
+		}
+		c.DistanceKm = math.Min(c.DistanceKm, d)
+	}
+
+	var cities []tmcore.NearbyCity
+	for _, code := range order {
+		if c := byCity[code]; c.DistanceKm <= radiusKm {
+			cities = append(cities, tmcore.NearbyCity{Location: proto.Clone(c.Location).(*pb.Location), DistanceKm: c.DistanceKm})
+		}
+	}
+	sort.SliceStable(cities, func(i, j int) bool { return cities[i].DistanceKm < cities[j].DistanceKm })
+	return cities
+}
+
+// locate places a location by its geocode, else by the first of its city and airport
+// codes the index knows
+func (x *PlaceIndex) locate(loc *pb.Location) (lat, lng float64, ok bool) {
+	if lat, lng, ok = tmcore.ParseGeocode(loc.GetGeocode()); ok || x == nil {
+		return lat, lng, ok
+	}
+	for _, code := range append([]string{loc.GetCityCode()}, loc.GetIataCodes()...) {
+		if i, found := x.codes[strings.ToUpper(code)]; found && code != "" {
+			if lat, lng, ok = tmcore.ParseGeocode(x.places[i[0]].loc.Geocode); ok {
+				return lat, lng, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// LocateAirport places the airport or station of a transport's end by the first of
+// its IATA codes the index has as an airport, else like any other location
+func (x *PlaceIndex) LocateAirport(loc *pb.Location) (lat, lng float64, ok bool) {
+	if x != nil {
+		for _, code := range loc.GetIataCodes() {
+			for _, i := range x.codes[strings.ToUpper(code)] {
+				if !x.places[i].airport {
+					continue
+				}
+				if lat, lng, ok = tmcore.ParseGeocode(x.places[i].loc.Geocode); ok {
+					return lat, lng, true
+				}
+			}
+		}
+	}
+	return x.locate(loc)
+}
+
+// normalizePlace lower-cases a name and collapses its whitespace
+func normalizePlace(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// wordSuffixes returns a name from each of its words on, e.g. "new york" and "york"
+func wordSuffixes(name string) []string {
+	words := strings.Fields(normalizePlace(name))
+	suffixes := make([]string, len(words))
+	for i := range words {
+		suffixes[i] = strings.Join(words[i:], " ")
+	}
+	return suffixes
+}
+
+// fuzzyEdits is how many typos a query may contain; short queries must match exactly
+func fuzzyEdits(q string) int {
+	switch n := len([]rune(q)); {
+	case n < 4:
+		return 0
+	case n < 7:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance is the Levenshtein distance between two strings
+func editDistance(ra, rb []rune) int {
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+// parsePlaces reads the code,kind,city_code,name,city,country,lat,lng,size table
+func parsePlaces(data string) ([]place, error) {
+	r := csv.NewReader(strings.NewReader(data))
+	r.Comment = '#'
+	r.FieldsPerRecord = 9
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	places := make([]place, 0, len(records))
+	for _, rec := range records {
+		for i := range rec {
+			rec[i] = strings.TrimSpace(rec[i])
+		}
+		code, kind := rec[0], rec[1]
+		if kind != "city" && kind != "airport" {
+			return nil, fmt.Errorf("%s: unknown kind %q", code, kind)
+		}
+		for _, coord := range rec[6:8] {
+			if _, err := strconv.ParseFloat(coord, 64); err != nil {
+				return nil, fmt.Errorf("%s: %w", code, err)
+			}
+		}
+		size, err := strconv.Atoi(rec[8])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", code, err)
+		}
+
+		loc := &pb.Location{
+			Name:     rec[3],
+			City:     rec[4],
+			Country:  rec[5],
+			CityCode: rec[2],
+			Geocode:  rec[6] + "," + rec[7],
+		}
+		if kind == "airport" {
+			loc.IataCodes = []string{code}
+		}
+		places = append(places, place{loc: loc, code: code, airport: kind == "airport", size: size})
+	}
+	return places, nil
+}
@@ -0,0 +1,130 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	tmcore "github.com/va6996/travelingman/core"
+	"github.com/va6996/travelingman/pb"
+)
+
+// codes returns the first IATA code of each result, or the city code for cities
+func codes(locs []*pb.Location) []string {
+	var out []string
+	for _, loc := range locs {
+		if loc.Name == loc.City && loc.CityCode != "" {
+			out = append(out, loc.CityCode)
+		} else if len(loc.IataCodes) > 0 {
+			out = append(out, loc.IataCodes[0])
+		}
+	}
+	return out
+}
+
+func TestPlaceIndex_Build(t *testing.T) {
+	x := DefaultPlaceIndex()
+	assert.Same(t, x, DefaultPlaceIndex(), "built once")
+
+	places, err := parsePlaces(placesCSV)
+	assert.NoError(t, err)
+	assert.Len(t, x.places, len(places))
+	assert.True(t, sort.SliceIsSorted(x.keys, func(i, j int) bool { return x.keys[i].text < x.keys[j].text }))
+
+	// Multi-word names are found from any word
+	var texts []string
+	for _, k := range x.keys {
+		if x.places[k.place].code == "JFK" {
+			texts = append(texts, k.text)
+		}
+	}
+	assert.ElementsMatch(t, []string{"new york", "york", "john f. kennedy international airport", "f. kennedy international airport", "kennedy international airport", "international airport", "airport"}, texts)
+
+	// Cities list their airports and carry coordinates
+	paris := x.Search("PAR", 1)
+	if assert.Len(t, paris, 1) {
+		assert.Equal(t, []string{"CDG", "ORY", "BVA"}, paris[0].IataCodes)
+		assert.Equal(t, "48.8566,2.3522", paris[0].Geocode)
+		assert.Equal(t, "FR", paris[0].Country)
+	}
+
+	// Learned locations are searchable too
+	learned := NewPlaceIndex(&pb.Location{City: "Ljubljana", Country: "SI", CityCode: "LJU", Name: "Ljubljana Joze Pucnik Airport", IataCodes: []string{"LJU"}})
+	assert.Equal(t, []string{"LJU"}, codes(learned.Search("ljub", 5)))
+	assert.Empty(t, x.Search("ljub", 5), "the default index is not changed")
+
+	_, err = parsePlaces("XXX,station,XXX,Somewhere,Somewhere,US,1,2,1\n")
+	assert.Error(t, err)
+	_, err = parsePlaces("XXX,city,XXX,Somewhere,Somewhere,US,north,2,1\n")
+	assert.Error(t, err)
+}
+
+func TestPlaceIndex_Search(t *testing.T) {
+	x := DefaultPlaceIndex()
+
+	tests := []struct {
+		name  string
+		query string
+		limit int
+		want  []string
+	}{
+		// The city code beats its own airports, which beat Paris, Texas
+		{"CityCode", "par", 10, []string{"PAR", "CDG", "ORY", "BVA", "PRX"}},
+		{"AirportCode", "lhr", 10, []string{"LHR"}},
+		{"CodeAboveCityPrefix", "IST", 10, []string{"IST", "IST", "SAW"}},
+		{"CityPrefix", "lond", 10, []string{"LON", "LGW", "LHR", "LCY", "STN"}},
+		{"CityWord", "york", 10, []string{"NYC", "JFK", "LGA"}},
+		{"AirportName", "heathrow", 10, []string{"LHR"}},
+		{"AirportWord", "kennedy", 10, []string{"JFK"}},
+		{"CityAboveAirportName", "mil", 10, []string{"MIL", "MXP", "LIN"}},
+		{"Typo", "tokio", 10, []string{"TYO", "HND", "NRT"}},
+		{"TwoTypos", "barcelnoa", 10, []string{"BCN"}},
+		{"ShortQueriesAreExact", "tko", 10, nil},
+		{"Limit", "par", 2, []string{"PAR", "CDG"}},
+		{"NoMatch", "zzzzzz", 10, nil},
+		{"Blank", "  ", 10, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, codes(x.Search(tt.query, tt.limit)))
+		})
+	}
+
+	// Results are copies
+	x.Search("par", 1)[0].City = "Changed"
+	assert.Equal(t, "Paris", x.Search("par", 1)[0].City)
+}
+
+func TestPlaceIndex_NearbyCities(t *testing.T) {
+	x := DefaultPlaceIndex()
+	names := func(cities []tmcore.NearbyCity) []string {
+		var out []string
+		for _, c := range cities {
+			out = append(out, fmt.Sprintf("%s %.0f", c.Location.Name, c.DistanceKm))
+		}
+		return out
+	}
+
+	// Placed by its code; London is listed as the city, as far away as Gatwick
+	paris := &pb.Location{City: "Paris", Country: "FR", CityCode: "PAR"}
+	assert.Equal(t, []string{"Brussels Airport 274", "London 313"}, names(x.NearbyCities(paris, 320)))
+	assert.Empty(t, x.NearbyCities(paris, 100))
+
+	// Placed by its geocode; a city with several airports is listed once
+	hoboken := &pb.Location{City: "Hoboken", Country: "US", Geocode: "40.7440,-74.0324"}
+	nearby := x.NearbyCities(hoboken, 30)
+	assert.Equal(t, []string{"New York 4"}, names(nearby))
+	if assert.Len(t, nearby, 1) {
+		assert.Equal(t, []string{"JFK", "LGA", "EWR"}, nearby[0].Location.IataCodes)
+	}
+
+	assert.Nil(t, x.NearbyCities(&pb.Location{City: "Seefeld"}, 100), "cannot be placed")
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance([]rune("tokyo"), []rune("tokyo")))
+	assert.Equal(t, 1, editDistance([]rune("tokio"), []rune("tokyo")))
+	assert.Equal(t, 2, editDistance([]rune("barcelnoa"), []rune("barcelona")))
+	assert.Equal(t, 3, editDistance([]rune(""), []rune("abc")))
+}
@@ -0,0 +1,65 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// TripLengthLimits bounds how long an itinerary may last, from its start to its end
+// time, to catch implausible plans. A zero bound is not checked.
+type TripLengthLimits struct {
+	Min time.Duration
+	Max time.Duration
+}
+
+// DefaultTripLengthLimits rejects trips under two hours or over 90 days
+var DefaultTripLengthLimits = TripLengthLimits{Min: 2 * time.Hour, Max: 90 * 24 * time.Hour}
+
+var (
+	tripLengthMu sync.RWMutex
+	tripLength   = DefaultTripLengthLimits
+)
+
+// SetTripLengthLimits changes the limits ValidateItinerary checks trips against
+func SetTripLengthLimits(l TripLengthLimits) {
+	tripLengthMu.Lock()
+	defer tripLengthMu.Unlock()
+	tripLength = l
+}
+
+// CurrentTripLengthLimits returns the limits ValidateItinerary checks trips against
+func CurrentTripLengthLimits() TripLengthLimits {
+	tripLengthMu.RLock()
+	defer tripLengthMu.RUnlock()
+	return tripLength
+}
+
+// check returns a description of how a trip of length d breaks the limits, or ""
+func (l TripLengthLimits) check(d time.Duration) string {
+	switch {
+	case l.Min > 0 && d < l.Min:
+		return fmt.Sprintf("Trip lasts %s, shorter than the minimum of %s; check the start and end times", formatTripLength(d), formatTripLength(l.Min))
+	case l.Max > 0 && d > l.Max:
+		return fmt.Sprintf("Trip lasts %s, longer than the maximum of %s; check the dates match the request", formatTripLength(d), formatTripLength(l.Max))
+	}
+	return ""
+}
+
+// formatTripLength renders a duration in days, hours or minutes, e.g. "21 days" or "1.5 hours"
+func formatTripLength(d time.Duration) string {
+	n, unit := d.Minutes(), "minute"
+	switch {
+	case d >= 48*time.Hour:
+		n, unit = d.Hours()/24, "day"
+	case d >= time.Hour:
+		n, unit = d.Hours(), "hour"
+	}
+	n = math.Round(n*10) / 10
+	if n != 1 {
+		unit += "s"
+	}
+	return strconv.FormatFloat(n, 'f', -1, 64) + " " + unit
+}
@@ -2,17 +2,64 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
-	"time"
 
+	tmcontext "github.com/va6996/travelingman/context"
 	tmcore "github.com/va6996/travelingman/core"
 	"github.com/va6996/travelingman/log"
 	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
-// ValidateItinerary checks itinerary logic for consistency
+// ValidationError lists what is wrong with an itinerary that cannot be checked as it is
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("Validation Failed with %d errors:\n- %s", len(e.Problems), strings.Join(e.Problems, "\n- "))
+}
+
+// ErrIncompleteItinerary is returned for itineraries that have nothing to book: no
+// node with a stay and no edge with a transport, sub-graphs included
+var ErrIncompleteItinerary = errors.New("itinerary has no transport and no stay")
+
+// CheckCompleteness returns ErrIncompleteItinerary if the itinerary has neither a stay
+// nor a transport anywhere in its graph
+func CheckCompleteness(itinerary *pb.Itinerary) error {
+	var complete func(g *pb.Graph) bool
+	complete = func(g *pb.Graph) bool {
+		if g == nil {
+			return false
+		}
+		for _, e := range g.Edges {
+			if e.GetTransport() != nil {
+				return true
+			}
+		}
+		for _, n := range g.Nodes {
+			if n.GetStay() != nil || complete(n.GetSubGraph()) {
+				return true
+			}
+		}
+		return complete(g.SubGraph)
+	}
+	if !complete(itinerary.GetGraph()) {
+		return ErrIncompleteItinerary
+	}
+	return nil
+}
+
+// ValidateItinerary checks itinerary logic for consistency. Problems are returned as a
+// *ValidationError.
 func ValidateItinerary(ctx context.Context, itinerary *pb.Itinerary) error {
+	if itinerary == nil {
+		err := &ValidationError{Problems: []string{"Itinerary is missing"}}
+		log.Errorf(ctx, "ValidateItinerary: %s", err)
+		return err
+	}
 	log.Debugf(ctx, "Validating itinerary: %s", itinerary.Title)
 
 	// Perform Checks
@@ -27,7 +74,7 @@ func ValidateItinerary(ctx context.Context, itinerary *pb.Itinerary) error {
 	start := itinerary.StartTime.AsTime()
 	end := itinerary.EndTime.AsTime()
 	// Use yesterday as buffer to account for timezones
-	yesterday := time.Now().AddDate(0, 0, -1)
+	yesterday := tmcontext.Now(ctx).AddDate(0, 0, -1)
 
 	if !start.IsZero() {
 		if start.Before(yesterday) {
@@ -40,6 +87,10 @@ func ValidateItinerary(ctx context.Context, itinerary *pb.Itinerary) error {
 	if !end.IsZero() {
 		if !start.IsZero() && end.Before(start) {
 			errors = append(errors, fmt.Sprintf("End time (%s) is before start time (%s)", end, start))
+		} else if !start.IsZero() {
+			if issue := CurrentTripLengthLimits().check(end.Sub(start)); issue != "" {
+				errors = append(errors, issue)
+			}
 		}
 	} else {
 		errors = append(errors, "End time missing")
@@ -57,6 +108,10 @@ func ValidateItinerary(ctx context.Context, itinerary *pb.Itinerary) error {
 
 		// Validate nodes have required fields (INVARIANT 3)
 		for i, node := range itinerary.Graph.Nodes {
+			if node == nil {
+				errors = append(errors, fmt.Sprintf("Node %d is nil", i))
+				continue
+			}
 			if node.Location == nil {
 				errors = append(errors, fmt.Sprintf("Node %d (%s): Location is nil (INVARIANT 3 violation)", i, node.Id))
 			}
@@ -90,6 +145,9 @@ func ValidateItinerary(ctx context.Context, itinerary *pb.Itinerary) error {
 				if node.Stay.TravelerCount <= 0 {
 					errors = append(errors, fmt.Sprintf("Node %d (%s): Accommodation.TravelerCount must be positive (INVARIANT 7 violation)", i, node.Id))
 				}
+				if len(node.Stay.ChildAges) > 0 && len(node.Stay.ChildAges) >= int(node.Stay.TravelerCount) {
+					errors = append(errors, fmt.Sprintf("Node %d (%s): Accommodation.ChildAges leaves no adult among the %d travelers", i, node.Id, node.Stay.TravelerCount))
+				}
 				if node.Stay.Cost != nil && node.Stay.Cost.Currency == "" {
 					errors = append(errors, fmt.Sprintf("Node %d (%s): Accommodation.Cost.Currency is empty (INVARIANT 8 violation)", i, node.Id))
 				}
@@ -98,6 +156,10 @@ func ValidateItinerary(ctx context.Context, itinerary *pb.Itinerary) error {
 
 		// Validate edges have required fields (INVARIANT 2)
 		for i, edge := range itinerary.Graph.Edges {
+			if edge == nil {
+				errors = append(errors, fmt.Sprintf("Edge %d is nil", i))
+				continue
+			}
 			if edge.Transport == nil {
 				errors = append(errors, fmt.Sprintf("Edge %d (%s -> %s): Transport is nil", i, edge.FromId, edge.ToId))
 				continue
@@ -162,11 +224,83 @@ func ValidateItinerary(ctx context.Context, itinerary *pb.Itinerary) error {
 	}
 
 	if len(errors) > 0 {
-		errMsg := fmt.Sprintf("Validation Failed with %d errors:\n- %s", len(errors), strings.Join(errors, "\n- "))
-		log.Errorf(ctx, "ValidateItinerary: %s", errMsg)
-		return fmt.Errorf("%s", errMsg)
+		err := &ValidationError{Problems: errors}
+		log.Errorf(ctx, "ValidateItinerary: %s", err)
+		return err
 	}
 
 	log.Debugf(ctx, "ValidateItinerary: Validation passed.")
 	return nil
 }
+
+// RepairPastDates moves an itinerary that starts in the past forward by whole years until
+// it no longer does, shifting every date in it (nodes, stays, transports and sub-graphs)
+// so the trip keeps its shape. Planners sometimes pick last year's dates for requests like
+// "in March". It returns the number of years shifted.
+func RepairPastDates(ctx context.Context, itinerary *pb.Itinerary) int {
+	if itinerary.StartTime == nil {
+		return 0
+	}
+
+	// Same buffer as ValidateItinerary
+	yesterday := tmcontext.Now(ctx).AddDate(0, 0, -1)
+	start := itinerary.StartTime.AsTime()
+	years := 0
+	for start.AddDate(years, 0, 0).Before(yesterday) {
+		years++
+	}
+	if years == 0 {
+		return 0
+	}
+
+	log.Warnf(ctx, "RepairPastDates: %q starts in the past (%s), moving it forward %d year(s)", itinerary.Title, start.Format("2006-01-02"), years)
+	shift := func(ts *timestamppb.Timestamp) *timestamppb.Timestamp {
+		if ts == nil {
+			return nil
+		}
+		return timestamppb.New(ts.AsTime().AddDate(years, 0, 0))
+	}
+	itinerary.StartTime = shift(itinerary.StartTime)
+	itinerary.EndTime = shift(itinerary.EndTime)
+	shiftGraph(itinerary.Graph, shift)
+	return years
+}
+
+// shiftGraph applies shift to every timestamp in the graph and its sub-graphs
+func shiftGraph(g *pb.Graph, shift func(*timestamppb.Timestamp) *timestamppb.Timestamp) {
+	if g == nil {
+		return
+	}
+	for _, node := range g.Nodes {
+		node.FromTimestamp = shift(node.FromTimestamp)
+		node.ToTimestamp = shift(node.ToTimestamp)
+		if node.Stay != nil {
+			node.Stay.CheckIn = shift(node.Stay.CheckIn)
+			node.Stay.CheckOut = shift(node.Stay.CheckOut)
+		}
+		shiftGraph(node.SubGraph, shift)
+	}
+	for _, edge := range g.Edges {
+		t := edge.Transport
+		if t == nil {
+			continue
+		}
+		if f := t.GetFlight(); f != nil {
+			f.DepartureTime = shift(f.DepartureTime)
+			f.ArrivalTime = shift(f.ArrivalTime)
+			for _, seg := range f.Segments {
+				seg.DepartureTime = shift(seg.DepartureTime)
+				seg.ArrivalTime = shift(seg.ArrivalTime)
+			}
+		}
+		if tr := t.GetTrain(); tr != nil {
+			tr.DepartureTime = shift(tr.DepartureTime)
+			tr.ArrivalTime = shift(tr.ArrivalTime)
+		}
+		if c := t.GetCarRental(); c != nil {
+			c.PickupTime = shift(c.PickupTime)
+			c.DropoffTime = shift(c.DropoffTime)
+		}
+	}
+	shiftGraph(g.SubGraph, shift)
+}
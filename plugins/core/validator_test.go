@@ -4,9 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	tmcontext "github.com/va6996/travelingman/context"
 	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func TestValidateItinerary_MissingNodes(t *testing.T) {
@@ -106,3 +109,104 @@ func TestValidateItinerary_ValidGraph(t *testing.T) {
 	err = ValidateItinerary(ctx, &itinerary)
 	assert.NoError(t, err)
 }
+
+func TestRepairPastDates(t *testing.T) {
+	now := time.Date(2026, 12, 31, 23, 30, 0, 0, time.UTC)
+	ctx := tmcontext.WithClock(context.Background(), tmcontext.FixedClock(now))
+	start := now.AddDate(-1, 0, 7).Truncate(time.Hour)
+	end := start.AddDate(0, 0, 3)
+
+	itinerary := &pb.Itinerary{
+		Title:     "Weekend in Lisbon",
+		StartTime: timestamppb.New(start),
+		EndTime:   timestamppb.New(end),
+		Graph: &pb.Graph{
+			Nodes: []*pb.Node{
+				{Id: "start_loc"},
+				{Id: "node_1", Stay: &pb.Accommodation{CheckIn: timestamppb.New(start), CheckOut: timestamppb.New(end)},
+					SubGraph: &pb.Graph{Nodes: []*pb.Node{{Id: "act_1", FromTimestamp: timestamppb.New(start.Add(4 * time.Hour))}}}},
+			},
+			Edges: []*pb.Edge{{
+				FromId: "start_loc", ToId: "node_1",
+				Transport: &pb.Transport{Details: &pb.Transport_Flight{Flight: &pb.Flight{DepartureTime: timestamppb.New(start)}}},
+			}},
+		},
+	}
+
+	assert.Equal(t, 1, RepairPastDates(ctx, itinerary))
+	next := func(tm time.Time) time.Time { return tm.AddDate(1, 0, 0) }
+	assert.Equal(t, next(start), itinerary.StartTime.AsTime())
+	assert.Equal(t, next(end), itinerary.EndTime.AsTime())
+	assert.Equal(t, next(start), itinerary.Graph.Nodes[1].Stay.CheckIn.AsTime())
+	assert.Equal(t, next(end), itinerary.Graph.Nodes[1].Stay.CheckOut.AsTime())
+	assert.Equal(t, next(start.Add(4*time.Hour)), itinerary.Graph.Nodes[1].SubGraph.Nodes[0].FromTimestamp.AsTime())
+	assert.Equal(t, next(start), itinerary.Graph.Edges[0].Transport.GetFlight().DepartureTime.AsTime())
+	assert.Nil(t, itinerary.Graph.Nodes[0].FromTimestamp, "unset times stay unset")
+
+	// Future itineraries are left alone
+	assert.Equal(t, 0, RepairPastDates(ctx, itinerary))
+	assert.Equal(t, next(start), itinerary.StartTime.AsTime())
+}
+
+func TestValidateItinerary_TripLength(t *testing.T) {
+	SetTripLengthLimits(TripLengthLimits{Min: 2 * time.Hour, Max: 14 * 24 * time.Hour})
+	t.Cleanup(func() { SetTripLengthLimits(DefaultTripLengthLimits) })
+
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	ctx := tmcontext.WithClock(context.Background(), tmcontext.FixedClock(now))
+	start := now.AddDate(0, 0, 4)
+	trip := func(end time.Time) *pb.Itinerary {
+		return &pb.Itinerary{
+			Title:       "Weekend in Rome",
+			StartTime:   timestamppb.New(start),
+			EndTime:     timestamppb.New(end),
+			Travelers:   1,
+			JourneyType: pb.JourneyType_JOURNEY_TYPE_ONE_WAY,
+			Graph: &pb.Graph{Nodes: []*pb.Node{
+				{Id: "start_loc", Location: &pb.Location{City: "London"}},
+				{Id: "node_1", Location: &pb.Location{City: "Rome"}},
+			}},
+		}
+	}
+
+	t.Run("TooLong", func(t *testing.T) {
+		err := ValidateItinerary(ctx, trip(start.AddDate(0, 0, 21)))
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "Trip lasts 21 days, longer than the maximum of 14 days")
+		}
+	})
+
+	t.Run("TooShort", func(t *testing.T) {
+		err := ValidateItinerary(ctx, trip(start.Add(time.Hour)))
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "Trip lasts 1 hour, shorter than the minimum of 2 hours")
+		}
+	})
+
+	t.Run("WithinLimits", func(t *testing.T) {
+		assert.NoError(t, ValidateItinerary(ctx, trip(start.AddDate(0, 0, 3))))
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		SetTripLengthLimits(TripLengthLimits{})
+		assert.NoError(t, ValidateItinerary(ctx, trip(start.AddDate(0, 0, 21))))
+		assert.NoError(t, ValidateItinerary(ctx, trip(start.Add(time.Hour))))
+	})
+}
+
+func TestCheckCompleteness(t *testing.T) {
+	// An empty graph passes validation but has nothing to book
+	empty := &pb.Itinerary{Title: "Nothing", Graph: &pb.Graph{Nodes: []*pb.Node{{Id: "home", Location: &pb.Location{City: "London"}}}}}
+	assert.ErrorIs(t, CheckCompleteness(empty), ErrIncompleteItinerary)
+	assert.ErrorIs(t, CheckCompleteness(&pb.Itinerary{}), ErrIncompleteItinerary)
+
+	stay := &pb.Itinerary{Graph: &pb.Graph{Nodes: []*pb.Node{{Id: "rome", Stay: &pb.Accommodation{}}}}}
+	assert.NoError(t, CheckCompleteness(stay))
+
+	transport := &pb.Itinerary{Graph: &pb.Graph{Edges: []*pb.Edge{{FromId: "a", ToId: "b", Transport: &pb.Transport{}}}}}
+	assert.NoError(t, CheckCompleteness(transport))
+
+	// A day trip counts
+	dayTrip := &pb.Itinerary{Graph: &pb.Graph{Nodes: []*pb.Node{{Id: "rome", SubGraph: transport.Graph}}}}
+	assert.NoError(t, CheckCompleteness(dayTrip))
+}
@@ -52,7 +52,7 @@ func TestAmadeusIntegration(t *testing.T) {
 
 	// Test Authentication
 	t.Run("Authentication", func(t *testing.T) {
-		err := client.Authenticate()
+		err := client.Authenticate(context.Background())
 		if err != nil {
 			t.Fatalf("Authentication failed: %v", err)
 		}
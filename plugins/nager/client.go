@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/firebase/genkit/go/genkit"
+	tmcontext "github.com/va6996/travelingman/context"
 	"github.com/va6996/travelingman/pb"
 	"github.com/va6996/travelingman/tools"
 )
@@ -17,6 +18,7 @@ import (
 type Client struct {
 	BaseURL    string
 	HTTPClient *http.Client
+	UserAgent  string // Sent with every request, if set
 }
 
 // NewClient creates a new Nager.Date API client and initializes tools
@@ -77,16 +79,29 @@ type LongWeekend struct {
 	UniqueHolidayCount int    `json:"uniqueHolidayCount"`
 }
 
+// newRequest creates a GET request for the API
+func (c *Client) newRequest(ctx context.Context, url string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	return req, nil
+}
+
 // GetAvailableCountries returns a list of available countries
 func (c *Client) GetAvailableCountries(ctx context.Context) ([]Country, error) {
 	url := fmt.Sprintf("%s/AvailableCountries", c.BaseURL)
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	req, err := c.newRequest(ctx, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	resp, err := c.HTTPClient.Do(req)
+	tmcontext.RequestStatsFromContext(ctx).AddProviderRequest("nager/AvailableCountries", err != nil || resp.StatusCode != http.StatusOK)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get available countries: %w", err)
 	}
@@ -108,12 +123,13 @@ func (c *Client) GetAvailableCountries(ctx context.Context) ([]Country, error) {
 func (c *Client) GetPublicHolidays(ctx context.Context, year int, countryCode string) ([]Holiday, error) {
 	url := fmt.Sprintf("%s/PublicHolidays/%d/%s", c.BaseURL, year, countryCode)
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	req, err := c.newRequest(ctx, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	resp, err := c.HTTPClient.Do(req)
+	tmcontext.RequestStatsFromContext(ctx).AddProviderRequest("nager/PublicHolidays", err != nil || resp.StatusCode != http.StatusOK)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get public holidays: %w", err)
 	}
@@ -135,12 +151,13 @@ func (c *Client) GetPublicHolidays(ctx context.Context, year int, countryCode st
 func (c *Client) GetLongWeekends(ctx context.Context, year int, countryCode string) ([]LongWeekend, error) {
 	url := fmt.Sprintf("%s/LongWeekend/%d/%s", c.BaseURL, year, countryCode)
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	req, err := c.newRequest(ctx, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	resp, err := c.HTTPClient.Do(req)
+	tmcontext.RequestStatsFromContext(ctx).AddProviderRequest("nager/LongWeekend", err != nil || resp.StatusCode != http.StatusOK)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get long weekends: %w", err)
 	}
@@ -160,7 +177,7 @@ func (c *Client) GetLongWeekends(ctx context.Context, year int, countryCode stri
 
 // IsTodayPublicHoliday checks if today is a public holiday for a specific country
 func (c *Client) IsTodayPublicHoliday(ctx context.Context, countryCode string) (bool, error) {
-	today := time.Now()
+	today := tmcontext.Now(ctx)
 	year := today.Year()
 
 	holidays, err := c.GetPublicHolidays(ctx, year, countryCode)
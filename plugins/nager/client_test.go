@@ -2,6 +2,8 @@ package nager
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -14,6 +16,27 @@ func TestNewClient(t *testing.T) {
 	assert.NotNil(t, client.HTTPClient)
 }
 
+func TestClient_UserAgent(t *testing.T) {
+	var agents []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		agents = append(agents, r.Header.Get("User-Agent"))
+		w.Write([]byte(`[]`))
+	}))
+	defer ts.Close()
+
+	client := NewClient(nil, nil)
+	client.BaseURL = ts.URL
+	client.UserAgent = "travelingman/1.2.3"
+
+	_, err := client.GetAvailableCountries(context.Background())
+	assert.NoError(t, err)
+	_, err = client.GetPublicHolidays(context.Background(), 2026, "US")
+	assert.NoError(t, err)
+	_, err = client.GetLongWeekends(context.Background(), 2026, "US")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"travelingman/1.2.3", "travelingman/1.2.3", "travelingman/1.2.3"}, agents)
+}
+
 func TestClient_GetAvailableCountries(t *testing.T) {
 	client := NewClient(nil, nil)
 
@@ -4,10 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"time"
 
 	"github.com/firebase/genkit/go/ai"
 	"github.com/firebase/genkit/go/genkit"
+	tmcontext "github.com/va6996/travelingman/context"
 	"github.com/va6996/travelingman/log"
 	toolspkg "github.com/va6996/travelingman/tools"
 )
@@ -36,7 +36,7 @@ func NewAvailableCountriesTool(client *Client, gk *genkit.Genkit, registry *tool
 		"nager_available_countries",
 		"Returns a list of all available countries supported by the Nager.Date API.",
 		func(ctx *ai.ToolContext, input *AvailableCountriesInput) (*AvailableCountriesOutput, error) {
-			return t.Execute(ctx, input)
+			return toolspkg.Run(ctx, "nager_available_countries", nil, input, t.Execute)
 		},
 	), func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 		return t.Execute(ctx, &AvailableCountriesInput{})
@@ -91,7 +91,7 @@ func NewPublicHolidaysTool(client *Client, gk *genkit.Genkit, registry *toolspkg
 		"nager_public_holidays",
 		"Returns public holidays for a specific country and year.",
 		func(ctx *ai.ToolContext, input *PublicHolidaysInput) (*PublicHolidaysOutput, error) {
-			return t.Execute(ctx, input)
+			return toolspkg.Run(ctx, "nager_public_holidays", nil, input, t.Execute)
 		},
 	), func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 		b, _ := json.Marshal(args)
@@ -101,7 +101,7 @@ func NewPublicHolidaysTool(client *Client, gk *genkit.Genkit, registry *toolspkg
 		}
 		// Default to current year if 0
 		if input.Year == 0 {
-			input.Year = time.Now().Year()
+			input.Year = tmcontext.Now(ctx).Year()
 		}
 		return t.Execute(ctx, &input)
 	})
@@ -119,7 +119,7 @@ func (t *PublicHolidaysTool) Execute(ctx context.Context, input *PublicHolidaysI
 		return nil, fmt.Errorf("country_code is required")
 	}
 	if input.Year == 0 {
-		input.Year = time.Now().Year()
+		input.Year = tmcontext.Now(ctx).Year()
 	}
 
 	holidays, err := t.client.GetPublicHolidays(ctx, input.Year, input.CountryCode)
@@ -162,7 +162,7 @@ func NewLongWeekendsTool(client *Client, gk *genkit.Genkit, registry *toolspkg.R
 		"nager_long_weekends",
 		"Returns long weekends for a specific country and year.",
 		func(ctx *ai.ToolContext, input *LongWeekendsInput) (*LongWeekendsOutput, error) {
-			return t.Execute(ctx, input)
+			return toolspkg.Run(ctx, "nager_long_weekends", nil, input, t.Execute)
 		},
 	), func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 		b, _ := json.Marshal(args)
@@ -171,7 +171,7 @@ func NewLongWeekendsTool(client *Client, gk *genkit.Genkit, registry *toolspkg.R
 			return nil, fmt.Errorf("failed to parse arguments: %w", err)
 		}
 		if input.Year == 0 {
-			input.Year = time.Now().Year()
+			input.Year = tmcontext.Now(ctx).Year()
 		}
 		return t.Execute(ctx, &input)
 	})
@@ -189,7 +189,7 @@ func (t *LongWeekendsTool) Execute(ctx context.Context, input *LongWeekendsInput
 		return nil, fmt.Errorf("country_code is required")
 	}
 	if input.Year == 0 {
-		input.Year = time.Now().Year()
+		input.Year = tmcontext.Now(ctx).Year()
 	}
 
 	weekends, err := t.client.GetLongWeekends(ctx, input.Year, input.CountryCode)
@@ -231,7 +231,7 @@ func NewIsTodayHolidayTool(client *Client, gk *genkit.Genkit, registry *toolspkg
 		"nager_is_today_holiday",
 		"Checks if today is a public holiday in the specified country.",
 		func(ctx *ai.ToolContext, input *IsTodayHolidayInput) (*IsTodayHolidayOutput, error) {
-			return t.Execute(ctx, input)
+			return toolspkg.Run(ctx, "nager_is_today_holiday", nil, input, t.Execute)
 		},
 	), func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 		b, _ := json.Marshal(args)
@@ -264,6 +264,6 @@ func (t *IsTodayHolidayTool) Execute(ctx context.Context, input *IsTodayHolidayI
 	log.Debugf(ctx, "IsTodayHolidayTool completed successfully. IsHoliday: %v", isHoliday)
 	return &IsTodayHolidayOutput{
 		IsHoliday: isHoliday,
-		Date:      time.Now().Format("2006-01-02"),
+		Date:      tmcontext.Now(ctx).Format("2006-01-02"),
 	}, nil
 }
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/firebase/genkit/go/genkit"
+	tmcontext "github.com/va6996/travelingman/context"
 	"github.com/va6996/travelingman/log"
 	"github.com/va6996/travelingman/tools"
 )
@@ -130,6 +131,7 @@ func (c *Client) Search(ctx context.Context, req *SearchRequest) (*SearchRespons
 	log.Debugf(ctx, "[Tavily] Sending search request: query=%s, depth=%s, max_results=%d", req.Query, req.SearchDepth, req.MaxResults)
 
 	resp, err := c.httpClient.Do(httpReq)
+	tmcontext.RequestStatsFromContext(ctx).AddProviderRequest("tavily/search", err != nil || resp.StatusCode != http.StatusOK)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
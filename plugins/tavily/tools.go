@@ -109,7 +109,7 @@ func (c *Client) registerTools(gk *genkit.Genkit, registry *tools.Registry) {
 	searchTool := &SearchTool{client: c}
 	registry.Register(genkit.DefineTool(gk, searchTool.Name(), searchTool.Description(),
 		func(ctx *ai.ToolContext, input *SearchRequest) (*SearchResponse, error) {
-			return searchTool.Execute(ctx, input)
+			return tools.Run(ctx, searchTool.Name(), nil, input, searchTool.Execute)
 		},
 	), func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 		// Adapter for generic registry execution
@@ -0,0 +1,220 @@
+package prompts
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/va6996/travelingman/log"
+)
+
+// TripPlanner is the system prompt of the trip planner
+const TripPlanner = "trip_planner"
+
+//go:embed templates/*.tmpl
+var defaults embed.FS
+
+// required lists the variables each prompt must use; a template without them would
+// leave the model unable to do its job
+var required = map[string][]string{
+	TripPlanner: {"Tools", "Schema"},
+}
+
+// funcs are the functions templates can call besides the text/template builtins
+var funcs = template.FuncMap{
+	"join": strings.Join,
+}
+
+// Vars are the values a prompt template is rendered with
+type Vars struct {
+	Tools  []string  // Names of the tools the model may call
+	Now    time.Time // The request's current time
+	Schema string    // JSON Schema the answer must follow
+	// A worked example: a request and a complete answer to it
+	ExampleQuery string
+	Example      string
+	Query        string   // The user's request
+	Issues       string   // Problems found with earlier plans for the request, if any
+	Pins         []string // Parts of the trip the user fixed, one per line, which plans must keep
+}
+
+// Template is a parsed prompt and the version it is recorded under
+type Template struct {
+	Name    string
+	Version string // "default@<hash>" for the embedded template, "override@<hash>" for a file
+	tmpl    *template.Template
+}
+
+// Parse parses and validates a prompt template. It fails if the template does not
+// parse, does not use the prompt's required variables or cannot be rendered.
+func Parse(name, version, text string) (*Template, error) {
+	tmpl, err := template.New(name).Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("prompt %s: %w", name, err)
+	}
+
+	used := map[string]bool{}
+	if tmpl.Tree != nil {
+		fieldsUsed(tmpl.Tree.Root, used)
+	}
+	var missing []string
+	for _, v := range required[name] {
+		if !used[v] {
+			missing = append(missing, "."+v)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("prompt %s: must use %s", name, strings.Join(missing, ", "))
+	}
+
+	t := &Template{Name: name, Version: fmt.Sprintf("%s@%s", version, hash(text)), tmpl: tmpl}
+	// Catch mistakes that only show when rendering, such as unknown variables
+	if _, err := t.Render(Vars{Tools: []string{"tool"}, Now: time.Now(), Schema: "{}"}); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Render renders the template with vars
+func (t *Template) Render(vars Vars) (string, error) {
+	var b bytes.Buffer
+	if err := t.tmpl.Execute(&b, vars); err != nil {
+		return "", fmt.Errorf("prompt %s: %w", t.Name, err)
+	}
+	return b.String(), nil
+}
+
+// Library holds the prompt templates in use: the embedded defaults, each replaced by
+// a valid file of the same name in the override directory. It is safe for
+// concurrent use.
+type Library struct {
+	mu        sync.RWMutex
+	templates map[string]*Template
+}
+
+// New creates a Library of the embedded defaults
+func New() *Library {
+	l := &Library{}
+	l.Load(context.Background(), "")
+	return l
+}
+
+// Load (re)reads the templates, taking <name>.tmpl from dir over the embedded
+// default. An override that cannot be read or is invalid is logged and the default
+// is used instead. An empty dir uses the defaults only.
+func (l *Library) Load(ctx context.Context, dir string) {
+	templates := make(map[string]*Template, len(required))
+	for name := range required {
+		t := defaultTemplate(name)
+		if dir != "" {
+			if override, err := loadOverride(dir, name); err != nil {
+				log.Errorf(ctx, "Prompts: Ignoring override for %s, using the default: %v", name, err)
+			} else if override != nil {
+				t = override
+			}
+		}
+		templates[name] = t
+		log.Debugf(ctx, "Prompts: %s is %s", name, t.Version)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.templates = templates
+}
+
+// Get returns the named template
+func (l *Library) Get(name string) (*Template, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	t, ok := l.templates[name]
+	return t, ok
+}
+
+// defaultTemplate parses the embedded template. The defaults ship with the binary,
+// so one that does not parse is a programming error.
+func defaultTemplate(name string) *Template {
+	text, err := defaults.ReadFile("templates/" + name + ".tmpl")
+	if err != nil {
+		panic(fmt.Sprintf("missing default prompt %s: %v", name, err))
+	}
+	t, err := Parse(name, "default", string(text))
+	if err != nil {
+		panic(fmt.Sprintf("invalid default prompt: %v", err))
+	}
+	return t
+}
+
+// loadOverride reads dir/<name>.tmpl. It returns nil if there is no such file.
+func loadOverride(dir, name string) (*Template, error) {
+	text, err := os.ReadFile(filepath.Join(dir, name+".tmpl"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return Parse(name, "override", string(text))
+}
+
+func hash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:4])
+}
+
+// fieldsUsed collects the top-level variables, e.g. Tools for {{.Tools}}, that a
+// template tree refers to
+func fieldsUsed(node parse.Node, used map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			fieldsUsed(c, used)
+		}
+	case *parse.ActionNode:
+		fieldsUsed(n.Pipe, used)
+	case *parse.IfNode:
+		fieldsUsed(&n.BranchNode, used)
+	case *parse.RangeNode:
+		fieldsUsed(&n.BranchNode, used)
+	case *parse.WithNode:
+		fieldsUsed(&n.BranchNode, used)
+	case *parse.BranchNode:
+		fieldsUsed(n.Pipe, used)
+		fieldsUsed(n.List, used)
+		fieldsUsed(n.ElseList, used)
+	case *parse.TemplateNode:
+		fieldsUsed(n.Pipe, used)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Cmds {
+			fieldsUsed(c, used)
+		}
+	case *parse.CommandNode:
+		for _, a := range n.Args {
+			fieldsUsed(a, used)
+		}
+	case *parse.ChainNode:
+		fieldsUsed(n.Node, used)
+	case *parse.FieldNode:
+		used[n.Ident[0]] = true
+	case *parse.VariableNode:
+		// $.Tools inside a range or with
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			used[n.Ident[1]] = true
+		}
+	}
+}
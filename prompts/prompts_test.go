@@ -0,0 +1,116 @@
+package prompts
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testVars = Vars{
+	Tools:        []string{"dateTool", "locationTool"},
+	Now:          time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC),
+	Schema:       "{\n  \"type\": \"object\"\n}",
+	ExampleQuery: "A weekend in Rome",
+	Example:      "{\n  \"itineraries\": []\n}",
+	Query:        "A weekend in Paris",
+}
+
+func writeOverride(t *testing.T, dir, text string) {
+	if err := os.WriteFile(filepath.Join(dir, TripPlanner+".tmpl"), []byte(text), 0o600); err != nil {
+		t.Fatalf("Failed to write override: %v", err)
+	}
+}
+
+func render(t *testing.T, l *Library) (string, string) {
+	tmpl, ok := l.Get(TripPlanner)
+	if !assert.True(t, ok) {
+		t.FailNow()
+	}
+	text, err := tmpl.Render(testVars)
+	assert.NoError(t, err)
+	return text, tmpl.Version
+}
+
+func TestDefault_Golden(t *testing.T) {
+	// The default renders the prompt the planner was built with, plus the tools line
+	// and the worked example
+	golden, err := os.ReadFile("testdata/trip_planner.golden")
+	if err != nil {
+		t.Fatalf("Failed to read golden file: %v", err)
+	}
+
+	text, version := render(t, New())
+	assert.Equal(t, string(golden), text)
+	assert.True(t, strings.HasPrefix(version, "default@"), version)
+}
+
+func TestDefault_Pins(t *testing.T) {
+	tmpl, _ := New().Get(TripPlanner)
+	vars := testVars
+	vars.Pins = []string{"The trip starts on 2026-06-05", "There are 2 travelers"}
+	text, err := tmpl.Render(vars)
+	assert.NoError(t, err)
+	assert.Contains(t, text, "\n\nFIXED BY THE USER:\n")
+	assert.Contains(t, text, "\n- The trip starts on 2026-06-05\n- There are 2 travelers\n\nWORKED EXAMPLE:")
+}
+
+func TestLibrary_Override(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	l := New()
+
+	// No file in the directory keeps the default
+	l.Load(ctx, dir)
+	_, version := render(t, l)
+	assert.True(t, strings.HasPrefix(version, "default@"), version)
+
+	writeOverride(t, dir, "Today is {{.Now.Format \"Jan 2\"}}. Tools: {{join .Tools \", \"}}. Answer as {{.Schema}} to {{.Query}}")
+	l.Load(ctx, dir)
+	text, version := render(t, l)
+	assert.Equal(t, "Today is May 1. Tools: dateTool, locationTool. Answer as {\n  \"type\": \"object\"\n} to A weekend in Paris", text)
+	assert.True(t, strings.HasPrefix(version, "override@"), version)
+
+	// An invalid override falls back to the default
+	writeOverride(t, dir, "Answer as {{.Schema}}")
+	l.Load(ctx, dir)
+	_, version = render(t, l)
+	assert.True(t, strings.HasPrefix(version, "default@"), version)
+}
+
+func TestLibrary_Reload(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	l := New()
+
+	writeOverride(t, dir, "v1 {{.Tools}} {{.Schema}}")
+	l.Load(ctx, dir)
+	text, v1 := render(t, l)
+	assert.True(t, strings.HasPrefix(text, "v1 "), text)
+
+	// Edits are picked up on the next load
+	writeOverride(t, dir, "v2 {{.Tools}} {{.Schema}}")
+	l.Load(ctx, dir)
+	text, v2 := render(t, l)
+	assert.True(t, strings.HasPrefix(text, "v2 "), text)
+	assert.NotEqual(t, v1, v2)
+}
+
+func TestParse(t *testing.T) {
+	_, err := Parse(TripPlanner, "override", "Answer as {{.Schema}}")
+	assert.ErrorContains(t, err, "must use .Tools")
+
+	// Variables used inside blocks count
+	_, err = Parse(TripPlanner, "override", "{{range .Tools}}- {{.}} {{$.Schema}}\n{{end}}")
+	assert.NoError(t, err)
+
+	_, err = Parse(TripPlanner, "override", "{{.Tools} {{.Schema}}")
+	assert.Error(t, err)
+
+	_, err = Parse(TripPlanner, "override", "{{.Tools}} {{.Schema}} {{.Budget}}")
+	assert.ErrorContains(t, err, "Budget")
+}
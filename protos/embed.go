@@ -0,0 +1,10 @@
+// Package protos embeds the .proto sources so their comments can be used at
+// runtime (e.g. as field descriptions in the planner's JSON schema).
+package protos
+
+import "embed"
+
+// Files holds the .proto definitions in this directory
+//
+//go:embed *.proto
+var Files embed.FS
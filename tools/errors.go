@@ -0,0 +1,140 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/va6996/travelingman/pb"
+)
+
+// ToolError is a failed tool call as the model sees it. Retryable tells the model
+// whether calling the tool again, e.g. with other arguments, can succeed, or whether
+// it should do without the tool.
+type ToolError struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Retryable bool   `json:"retryable"`
+}
+
+func (e ToolError) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+// retryableCodes are the error codes another call to the tool can get past
+var retryableCodes = map[pb.ErrorCode]bool{
+	pb.ErrorCode_ERROR_CODE_SEARCH_FAILED:     true,
+	pb.ErrorCode_ERROR_CODE_DATA_NOT_FOUND:    true,
+	pb.ErrorCode_ERROR_CODE_INVALID_INPUT:     true,
+	pb.ErrorCode_ERROR_CODE_CONNECTION_FAILED: true,
+	pb.ErrorCode_ERROR_CODE_NO_AVAILABILITY:   true,
+}
+
+// NewToolError describes err for the model. classify maps it to an error code and may
+// be nil; errors it cannot classify are search failures. A tool that ran out of time
+// failed to connect, which another call, e.g. for another route, can get past.
+func NewToolError(err error, classify func(error) pb.ErrorCode) ToolError {
+	var te ToolError
+	if errors.As(err, &te) {
+		return te
+	}
+	code := pb.ErrorCode_ERROR_CODE_SEARCH_FAILED
+	if classify != nil {
+		if c := classify(err); c != pb.ErrorCode_ERROR_CODE_UNSPECIFIED {
+			code = c
+		}
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		code = pb.ErrorCode_ERROR_CODE_CONNECTION_FAILED
+	}
+	return ToolError{
+		Code:      strings.TrimPrefix(code.String(), "ERROR_CODE_"),
+		Message:   err.Error(),
+		Retryable: retryableCodes[code],
+	}
+}
+
+// Run calls fn as the tool name. If it fails, generation is interrupted with a
+// ToolError instead of ending, so the planner can hand the error to the model; see
+// ToolErrorOf. Within a context from WithToolFailures, a tool that failed with an error
+// that is not retryable fails the same way again without calling fn, and so does a call
+// that timed out when made again with the same input. Within a context from
+// WithToolTimeouts, fn is abandoned once the tool's timeout passes.
+func Run[In, Out any](ctx *ai.ToolContext, name string, classify func(error) pb.ErrorCode, input In, fn func(context.Context, In) (Out, error)) (Out, error) {
+	failures, _ := ctx.Value(toolFailuresKey{}).(*toolFailures)
+	call := callKey(name, input)
+	for _, key := range []string{name, call} {
+		if te, ok := failures.get(key); ok {
+			var zero Out
+			return zero, ai.InterruptWith(ctx, te)
+		}
+	}
+	registry, _ := ctx.Value(toolTimeoutsKey{}).(*Registry)
+	out, err := runWithTimeout(ctx, name, registry.ToolTimeout(name), func(ctx context.Context) (Out, error) {
+		return fn(ctx, input)
+	})
+	if err == nil {
+		return out, nil
+	}
+	te := NewToolError(err, classify)
+	if !te.Retryable {
+		failures.set(name, te)
+	} else if errors.Is(err, context.DeadlineExceeded) {
+		failures.set(call, te)
+	}
+	return out, ai.InterruptWith(ctx, te)
+}
+
+// callKey identifies a call of the tool name with input among the remembered failures
+func callKey(name string, input any) string {
+	b, err := json.Marshal(input)
+	if err != nil {
+		return name + " " + fmt.Sprint(input)
+	}
+	return name + " " + string(b)
+}
+
+// ToolErrorOf returns the error of a tool request part interrupted by Run
+func ToolErrorOf(p *ai.Part) (ToolError, bool) {
+	te, ok := ai.InterruptAs[ToolError](p)
+	return te, ok && te.Code != ""
+}
+
+type toolFailuresKey struct{}
+
+// toolFailures remembers the tools, or the calls of a tool, that failed for good during
+// one request. It is safe for concurrent use; a nil toolFailures remembers nothing.
+type toolFailures struct {
+	mu   sync.Mutex
+	errs map[string]ToolError // By tool name or callKey
+}
+
+// WithToolFailures returns a context in which Run remembers the tools that failed
+// with errors that are not retryable, and the calls that timed out, for as long as
+// the context lives
+func WithToolFailures(ctx context.Context) context.Context {
+	return context.WithValue(ctx, toolFailuresKey{}, &toolFailures{errs: make(map[string]ToolError)})
+}
+
+func (f *toolFailures) get(name string) (ToolError, bool) {
+	if f == nil {
+		return ToolError{}, false
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	te, ok := f.errs[name]
+	return te, ok
+}
+
+func (f *toolFailures) set(name string, te ToolError) {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.errs[name] = te
+}
@@ -2,7 +2,10 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/firebase/genkit/go/ai"
 	"github.com/firebase/genkit/go/genkit"
@@ -21,6 +24,14 @@ type Registry struct {
 	tools     []ai.Tool
 	toolRefs  []ai.ToolRef
 	executors map[string]ToolExecutor
+
+	// maxResultBytes caps the encoded size of list results handed back to the model
+	maxResultBytes int
+
+	// toolTimeout bounds every tool run by ExecuteTool, or by Run within a context from
+	// WithToolTimeouts, unless timeouts has one for the tool
+	toolTimeout time.Duration
+	timeouts    map[string]time.Duration
 }
 
 // NewRegistry creates a new tool registry
@@ -29,6 +40,7 @@ func NewRegistry() *Registry {
 		tools:     make([]ai.Tool, 0),
 		toolRefs:  make([]ai.ToolRef, 0),
 		executors: make(map[string]ToolExecutor),
+		timeouts:  make(map[string]time.Duration),
 	}
 }
 
@@ -39,6 +51,44 @@ func (r *Registry) Register(tool ai.Tool, executor ToolExecutor) {
 	r.executors[tool.Definition().Name] = executor
 }
 
+// SetMaxResultBytes sets the size cap for list tool results; zero or less uses DefaultMaxResultBytes
+func (r *Registry) SetMaxResultBytes(n int) {
+	r.maxResultBytes = n
+}
+
+// MaxResultBytes returns the size cap for list tool results
+func (r *Registry) MaxResultBytes() int {
+	if r.maxResultBytes <= 0 {
+		return DefaultMaxResultBytes
+	}
+	return r.maxResultBytes
+}
+
+// SetToolTimeout sets how long a tool may run; zero or less lets tools run for as long
+// as their context allows
+func (r *Registry) SetToolTimeout(d time.Duration) {
+	r.toolTimeout = d
+}
+
+// SetToolTimeoutFor overrides the tool timeout for the named tool; zero or less lets it
+// run for as long as its context allows
+func (r *Registry) SetToolTimeoutFor(name string, d time.Duration) {
+	r.timeouts[name] = d
+}
+
+// ToolTimeout returns how long the named tool may run, zero if unbounded. A nil
+// Registry bounds nothing.
+func (r *Registry) ToolTimeout(name string) time.Duration {
+	if r == nil {
+		return 0
+	}
+	d, ok := r.timeouts[name]
+	if !ok {
+		d = r.toolTimeout
+	}
+	return max(d, 0)
+}
+
 // GetTools returns all registered tools
 func (r *Registry) GetTools() []ai.Tool {
 	return r.tools
@@ -48,6 +98,29 @@ func (r *Registry) GetToolRefs() []ai.ToolRef {
 	return r.toolRefs
 }
 
+// GetToolRefsExcept returns the registered tools whose names start with none of prefixes
+func (r *Registry) GetToolRefsExcept(prefixes ...string) []ai.ToolRef {
+	if len(prefixes) == 0 {
+		return r.toolRefs
+	}
+	var refs []ai.ToolRef
+	for _, t := range r.tools {
+		if !hasAnyPrefix(t.Definition().Name, prefixes) {
+			refs = append(refs, t)
+		}
+	}
+	return refs
+}
+
+func hasAnyPrefix(name string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(name, p) {
+			return true
+		}
+	}
+	return false
+}
+
 // Lookup finds a tool definition by name
 func (r *Registry) Lookup(name string) (ai.Tool, bool) {
 	for _, t := range r.tools {
@@ -58,11 +131,52 @@ func (r *Registry) Lookup(name string) (ai.Tool, bool) {
 	return nil, false
 }
 
-// ExecuteTool runs a registered tool by name
+// ExecuteTool runs a registered tool by name. A tool still running when its timeout
+// passes is abandoned with an error wrapping context.DeadlineExceeded, even if it
+// ignores its context.
 func (r *Registry) ExecuteTool(ctx context.Context, name string, args map[string]interface{}) (interface{}, error) {
 	executor, ok := r.executors[name]
 	if !ok {
 		return nil, fmt.Errorf("tool not found: %s", name)
 	}
-	return executor(ctx, args)
+	return runWithTimeout(ctx, name, r.ToolTimeout(name), func(ctx context.Context) (interface{}, error) {
+		return executor(ctx, args)
+	})
+}
+
+// runWithTimeout calls fn, the tool name, with a context that ends after timeout, and
+// gives up on it then even if it ignores its context. A zero timeout calls fn with ctx.
+func runWithTimeout[Out any](ctx context.Context, name string, timeout time.Duration, fn func(context.Context) (Out, error)) (Out, error) {
+	if timeout == 0 {
+		return fn(ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	type result struct {
+		out Out
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		out, err := fn(ctx)
+		done <- result{out, err}
+	}()
+	select {
+	case res := <-done:
+		return res.out, res.err
+	case <-ctx.Done():
+		var zero Out
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return zero, fmt.Errorf("tool %s timed out after %s: %w", name, timeout, ctx.Err())
+		}
+		return zero, ctx.Err()
+	}
+}
+
+type toolTimeoutsKey struct{}
+
+// WithToolTimeouts returns a context in which Run gives every tool the time r allows it
+func WithToolTimeouts(ctx context.Context, r *Registry) context.Context {
+	return context.WithValue(ctx, toolTimeoutsKey{}, r)
 }
@@ -2,13 +2,19 @@ package tools_test
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/va6996/travelingman/plugins/core"
 	"github.com/va6996/travelingman/tools"
 	"github.com/firebase/genkit/go/ai"
 	"github.com/firebase/genkit/go/genkit"
 	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func TestNewRegistry(t *testing.T) {
@@ -38,3 +44,138 @@ func TestRegistry_Register(t *testing.T) {
 	assert.Len(t, tools, 1)
 	assert.Equal(t, "testTool", tools[0].Definition().Name)
 }
+
+func TestRegistry_GetToolRefsExcept(t *testing.T) {
+	ctx := context.Background()
+	gk := genkit.Init(ctx)
+	reg := tools.NewRegistry()
+
+	for _, name := range []string{"amadeus_flight_tool", "amadeus_hotel_list", "date_tool"} {
+		reg.Register(genkit.DefineTool[*core.DateInput, string](
+			gk,
+			name,
+			"Test Description",
+			func(ctx *ai.ToolContext, input *core.DateInput) (string, error) {
+				return "ok", nil
+			},
+		), nil)
+	}
+
+	assert.Len(t, reg.GetToolRefsExcept(), 3)
+	refs := reg.GetToolRefsExcept("amadeus_")
+	if assert.Len(t, refs, 1) {
+		assert.Equal(t, "date_tool", refs[0].Name())
+	}
+}
+
+func TestRegistry_ToolOutputIsJSON(t *testing.T) {
+	ctx := context.Background()
+	gk := genkit.Init(ctx)
+	reg := tools.NewRegistry()
+
+	departure := time.Date(2026, 6, 1, 9, 30, 0, 0, time.UTC)
+	reg.Register(genkit.DefineTool[*core.DateInput, []*pb.Transport](
+		gk,
+		"flightTool",
+		"Returns a flight",
+		func(ctx *ai.ToolContext, input *core.DateInput) ([]*pb.Transport, error) {
+			return []*pb.Transport{{
+				Type: pb.TransportType_TRANSPORT_TYPE_FLIGHT,
+				Cost: &pb.Cost{Value: 120.5, Currency: "EUR"},
+				Details: &pb.Transport_Flight{Flight: &pb.Flight{
+					CarrierCode:   "TP",
+					DepartureTime: timestamppb.New(departure),
+				}},
+			}}, nil
+		},
+	), nil)
+
+	tool, ok := reg.Lookup("flightTool")
+	if !assert.True(t, ok) {
+		return
+	}
+
+	// Genkit stores this output in the tool response part of the conversation history
+	out, err := tool.RunRaw(ctx, map[string]any{"expression": "now"})
+	if err != nil {
+		t.Fatalf("RunRaw failed: %v", err)
+	}
+	b, err := json.Marshal(out)
+	if err != nil {
+		t.Fatalf("Failed to encode output: %v", err)
+	}
+
+	history := string(b)
+	assert.True(t, json.Valid(b))
+	assert.False(t, strings.Contains(history, "&{"), "no Go struct formatting: %s", history)
+	assert.Contains(t, history, `"carrier_code":"TP"`)
+	assert.Contains(t, history, `"currency":"EUR"`)
+}
+
+func TestRegistry_ExecuteToolTimeout(t *testing.T) {
+	ctx := context.Background()
+	gk := genkit.Init(ctx)
+	reg := tools.NewRegistry()
+	reg.SetToolTimeout(50 * time.Millisecond)
+
+	// A hung provider call that ignores its context
+	release := make(chan struct{})
+	defer close(release)
+	slow := func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		<-release
+		return "late", nil
+	}
+	for _, name := range []string{"slowTool", "patientTool"} {
+		reg.Register(genkit.DefineTool[*core.DateInput, string](
+			gk,
+			name,
+			"Test Description",
+			func(ctx *ai.ToolContext, input *core.DateInput) (string, error) {
+				return "ok", nil
+			},
+		), slow)
+	}
+	reg.SetToolTimeoutFor("patientTool", 200*time.Millisecond)
+
+	for name, limit := range map[string]time.Duration{"slowTool": 50 * time.Millisecond, "patientTool": 200 * time.Millisecond} {
+		assert.Equal(t, limit, reg.ToolTimeout(name))
+		start := time.Now()
+		out, err := reg.ExecuteTool(ctx, name, nil)
+		elapsed := time.Since(start)
+
+		assert.Nil(t, out)
+		assert.ErrorIs(t, err, context.DeadlineExceeded, name)
+		assert.GreaterOrEqual(t, elapsed, limit, name)
+		assert.Less(t, elapsed, limit+time.Second, name)
+	}
+}
+
+func TestRegistry_ExecuteToolWithinTimeout(t *testing.T) {
+	ctx := context.Background()
+	gk := genkit.Init(ctx)
+	reg := tools.NewRegistry()
+	reg.SetToolTimeout(time.Second)
+
+	reg.Register(genkit.DefineTool[*core.DateInput, string](
+		gk,
+		"fastTool",
+		"Test Description",
+		func(ctx *ai.ToolContext, input *core.DateInput) (string, error) {
+			return "ok", nil
+		},
+	), func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			return nil, errors.New("no deadline")
+		}
+		return "ok", nil
+	})
+
+	out, err := reg.ExecuteTool(ctx, "fastTool", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", out)
+
+	reg.SetToolTimeoutFor("fastTool", 0)
+	assert.Zero(t, reg.ToolTimeout("fastTool"))
+	_, err = reg.ExecuteTool(ctx, "fastTool", nil)
+	assert.EqualError(t, err, "no deadline")
+}
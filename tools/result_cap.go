@@ -0,0 +1,44 @@
+package tools
+
+import "encoding/json"
+
+// DefaultMaxResultBytes is the tool result size cap used when none is configured
+const DefaultMaxResultBytes = 16 * 1024
+
+// CapList keeps the JSON encoding of a list tool result within maxBytes, so a single
+// tool call cannot fill the model's context window. When the list is too large every
+// item is first passed through slim (which should drop verbose fields, and may be nil),
+// then trailing items are dropped until the list fits. Lists are expected to be sorted
+// best first. At least one item is always kept. It returns the capped list and the
+// number of items dropped.
+func CapList[T any](items []T, maxBytes int, slim func(T) T) ([]T, int) {
+	if maxBytes <= 0 || encodedSize(items) <= maxBytes {
+		return items, 0
+	}
+
+	capped := make([]T, len(items))
+	for i, item := range items {
+		if slim != nil {
+			item = slim(item)
+		}
+		capped[i] = item
+	}
+
+	// Item sizes are measured once so trimming stays linear
+	size := encodedSize(capped)
+	n := len(capped)
+	for n > 1 && size > maxBytes {
+		n--
+		// One item plus its separating comma
+		size -= encodedSize(capped[n]) + 1
+	}
+	return capped[:n], len(items) - n
+}
+
+func encodedSize(v any) int {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return len(b)
+}
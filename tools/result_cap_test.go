@@ -0,0 +1,58 @@
+package tools_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/va6996/travelingman/tools"
+)
+
+type capItem struct {
+	Name  string `json:"name"`
+	Notes string `json:"notes,omitempty"`
+}
+
+func TestCapList(t *testing.T) {
+	items := make([]capItem, 20)
+	for i := range items {
+		items[i] = capItem{Name: "item", Notes: strings.Repeat("x", 200)}
+	}
+
+	// Under the cap the list is returned as is
+	capped, dropped := tools.CapList(items, 1<<20, nil)
+	assert.Len(t, capped, 20)
+	assert.Equal(t, 0, dropped)
+
+	// Slimming alone is enough to fit
+	slim := func(c capItem) capItem { c.Notes = ""; return c }
+	capped, dropped = tools.CapList(items, 1024, slim)
+	assert.Len(t, capped, 20)
+	assert.Equal(t, 0, dropped)
+	assert.Empty(t, capped[0].Notes)
+	assert.NotEmpty(t, items[0].Notes, "the input is not modified")
+
+	// Without slimming, trailing items are dropped
+	capped, dropped = tools.CapList(items, 1024, nil)
+	b, _ := json.Marshal(capped)
+	assert.LessOrEqual(t, len(b), 1024)
+	assert.Equal(t, 20-len(capped), dropped)
+	assert.Less(t, len(capped), 20)
+
+	// At least one item is always kept
+	capped, dropped = tools.CapList(items, 10, nil)
+	assert.Len(t, capped, 1)
+	assert.Equal(t, 19, dropped)
+}
+
+func TestRegistry_MaxResultBytes(t *testing.T) {
+	reg := tools.NewRegistry()
+	assert.Equal(t, tools.DefaultMaxResultBytes, reg.MaxResultBytes())
+
+	reg.SetMaxResultBytes(2048)
+	assert.Equal(t, 2048, reg.MaxResultBytes())
+
+	reg.SetMaxResultBytes(0)
+	assert.Equal(t, tools.DefaultMaxResultBytes, reg.MaxResultBytes())
+}
@@ -15,6 +15,13 @@ import { TimelineCard, OptionCard } from "./TimelineCard";
 import BaggageSummary from "./BaggageSummary";
 import { FlightSegments } from "./FlightSegments";
 
+// Formats a layover length, e.g. "2h 35m" or "50m"
+const formatLayover = (ms: number) => {
+  const hours = Math.floor(ms / (1000 * 60 * 60));
+  const minutes = Math.floor((ms % (1000 * 60 * 60)) / (1000 * 60));
+  return hours > 0 ? `${hours}h ${minutes}m` : `${minutes}m`;
+};
+
 // Helper to get layover information
 const getLayoverInfo = (transport: Transport) => {
   if (transport.details.case !== "flight") return null;
@@ -29,30 +36,37 @@ const getLayoverInfo = (transport: Transport) => {
     };
   }
 
-  // Calculate layovers between segments
+  // Use the layovers the server reported; trips saved before it did only have segments
   const layovers: any[] = [];
-  for (let i = 0; i < segments.length - 1; i++) {
-    const currentSeg = segments[i];
-    const nextSeg = segments[i + 1];
-
-    const arrivalTime = currentSeg.arrivalTime?.toDate();
-    const departureTime = nextSeg.departureTime?.toDate();
-
-    if (arrivalTime && departureTime) {
-      const layoverTime = departureTime.getTime() - arrivalTime.getTime();
-      const hours = Math.floor(layoverTime / (1000 * 60 * 60));
-      const minutes = Math.floor(
-        (layoverTime % (1000 * 60 * 60)) / (1000 * 60),
-      );
-
+  if (flight.layovers?.length) {
+    for (const layover of flight.layovers) {
       layovers.push({
-        airportCode:
-          currentSeg.arrivalAirportCode || nextSeg.departureAirportCode,
-        duration: hours > 0 ? `${hours}h ${minutes}m` : `${minutes}m`,
-        arrivalTime,
-        departureTime,
+        airportCode: layover.airportCode,
+        duration: formatLayover(Number(layover.durationSeconds) * 1000),
+        arrivalTime: layover.arrivalTime?.toDate(),
+        departureTime: layover.departureTime?.toDate(),
       });
     }
+  } else {
+    for (let i = 0; i < segments.length - 1; i++) {
+      const currentSeg = segments[i];
+      const nextSeg = segments[i + 1];
+
+      const arrivalTime = currentSeg.arrivalTime?.toDate();
+      const departureTime = nextSeg.departureTime?.toDate();
+
+      if (arrivalTime && departureTime) {
+        layovers.push({
+          airportCode:
+            currentSeg.arrivalAirportCode || nextSeg.departureAirportCode,
+          duration: formatLayover(
+            departureTime.getTime() - arrivalTime.getTime(),
+          ),
+          arrivalTime,
+          departureTime,
+        });
+      }
+    }
   }
 
   return {
@@ -158,6 +158,7 @@ const SingleItineraryTimeline = ({ itinerary }: { itinerary: Itinerary }) => {
                                 selectedOptionIndex={selectedIdx}
                                 onSelectOption={(i) => handleSelect(id, i)}
                                 locationName={(item.data as Node).location?.city || (item.data as Node).location?.name || "Unknown Location"}
+                                notes={(item.data as Node).notes}
                             />
                         ) : (
                             <EdgeCard
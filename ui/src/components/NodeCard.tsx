@@ -18,9 +18,10 @@ interface NodeCardProps {
     selectedOptionIndex: number
     onSelectOption: (index: number) => void
     locationName?: string
+    notes?: string
 }
 
-export const NodeCard = ({ stay, options, selectedOptionIndex, onSelectOption, locationName }: NodeCardProps) => {
+export const NodeCard = ({ stay, options, selectedOptionIndex, onSelectOption, locationName, notes }: NodeCardProps) => {
     const currentStay = options.length > 0 ? options[selectedOptionIndex] : stay
 
     // If no stay data, treat as a location/waypoint
@@ -33,7 +34,7 @@ export const NodeCard = ({ stay, options, selectedOptionIndex, onSelectOption, l
                 tags={[]}
                 hideToggle={true}
             >
-                <Text color="gray.500" fontSize="md">No details available.</Text>
+                <Text color="gray.500" fontSize="md">{notes || "No details available."}</Text>
             </TimelineCard>
         )
     }
@@ -63,6 +64,10 @@ export const NodeCard = ({ stay, options, selectedOptionIndex, onSelectOption, l
                     {checkIn && <Text fontSize="sm" color="gray.500">Check In: {checkIn.toLocaleDateString()}</Text>}
                     {checkOut && <Text fontSize="sm" color="gray.500">Check Out: {checkOut.toLocaleDateString()}</Text>}
                     {currentStay.travelerCount && <Text fontSize="sm" color="gray.500">Guests: {currentStay.travelerCount}</Text>}
+                    {notes && <Text fontSize="sm" color="gray.300">Notes: {notes}</Text>}
+                    {currentStay.warnings.map((w, i) => (
+                        <Text key={i} fontSize="sm" color="orange.300">{w}</Text>
+                    ))}
                 </>
             }
         >
@@ -87,6 +92,9 @@ export const NodeCard = ({ stay, options, selectedOptionIndex, onSelectOption, l
                                             <VStack align="start" spacing={0}>
                                                 <Text fontWeight="bold" fontSize="md" color="white">{capitalizeFirstLetter(opt.name)}</Text>
                                                 <Text fontSize="sm" color="gray.500">{opt.tags.join(", ")}</Text>
+                                                {opt.warnings.map((w, i) => (
+                                                    <Text key={i} fontSize="sm" color="orange.300">{w}</Text>
+                                                ))}
                                             </VStack>
                                             <VStack>
                                                 {isSelected && <Badge colorScheme="green" variant="solid">Selected</Badge>}
@@ -82,6 +82,60 @@ proto3.util.setEnumType(Plugin, "travelingman.Plugin", [
   { no: 4, name: "PLUGIN_OTHER" },
 ]);
 
+/**
+ * @generated from enum travelingman.BookingStatus
+ */
+export enum BookingStatus {
+  /**
+   * @generated from enum value: BOOKING_STATUS_UNSPECIFIED = 0;
+   */
+  UNSPECIFIED = 0,
+
+  /**
+   * Requested, not yet confirmed by the provider
+   *
+   * @generated from enum value: BOOKING_STATUS_PENDING = 1;
+   */
+  PENDING = 1,
+
+  /**
+   * Confirmed, tickets not yet issued
+   *
+   * @generated from enum value: BOOKING_STATUS_CONFIRMED = 2;
+   */
+  CONFIRMED = 2,
+
+  /**
+   * Tickets issued
+   *
+   * @generated from enum value: BOOKING_STATUS_TICKETED = 3;
+   */
+  TICKETED = 3,
+
+  /**
+   * Cancelled, e.g. by the airline
+   *
+   * @generated from enum value: BOOKING_STATUS_CANCELLED = 4;
+   */
+  CANCELLED = 4,
+
+  /**
+   * The provider changed the schedule since it was last checked
+   *
+   * @generated from enum value: BOOKING_STATUS_CHANGED = 5;
+   */
+  CHANGED = 5,
+}
+// Retrieve enum metadata with: proto3.getEnumType(BookingStatus)
+proto3.util.setEnumType(BookingStatus, "travelingman.BookingStatus", [
+  { no: 0, name: "BOOKING_STATUS_UNSPECIFIED" },
+  { no: 1, name: "BOOKING_STATUS_PENDING" },
+  { no: 2, name: "BOOKING_STATUS_CONFIRMED" },
+  { no: 3, name: "BOOKING_STATUS_TICKETED" },
+  { no: 4, name: "BOOKING_STATUS_CANCELLED" },
+  { no: 5, name: "BOOKING_STATUS_CHANGED" },
+]);
+
 /**
  * @generated from message travelingman.FlightOffer
  */
@@ -264,6 +318,130 @@ export class HotelOffer extends Message<HotelOffer> {
   }
 }
 
+/**
+ * FlightChange is one difference between a booked flight and the provider's current order
+ *
+ * @generated from message travelingman.FlightChange
+ */
+export class FlightChange extends Message<FlightChange> {
+  /**
+   * Index of the segment in the flight
+   *
+   * @generated from field: int32 segment = 1;
+   */
+  segment = 0;
+
+  /**
+   * e.g. departure_time, arrival_time, flight_number
+   *
+   * @generated from field: string field = 2;
+   */
+  field = "";
+
+  /**
+   * @generated from field: string old_value = 3;
+   */
+  oldValue = "";
+
+  /**
+   * @generated from field: string new_value = 4;
+   */
+  newValue = "";
+
+  constructor(data?: PartialMessage<FlightChange>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.FlightChange";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "segment", kind: "scalar", T: 5 /* ScalarType.INT32 */ },
+    { no: 2, name: "field", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "old_value", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 4, name: "new_value", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): FlightChange {
+    return new FlightChange().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): FlightChange {
+    return new FlightChange().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): FlightChange {
+    return new FlightChange().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: FlightChange | PlainMessage<FlightChange> | undefined, b: FlightChange | PlainMessage<FlightChange> | undefined): boolean {
+    return proto3.util.equals(FlightChange, a, b);
+  }
+}
+
+/**
+ * BookingStatusChange records a status transition and the schedule changes behind it
+ *
+ * @generated from message travelingman.BookingStatusChange
+ */
+export class BookingStatusChange extends Message<BookingStatusChange> {
+  /**
+   * @generated from field: int64 booking_id = 1;
+   */
+  bookingId = protoInt64.zero;
+
+  /**
+   * @generated from field: travelingman.BookingStatus from = 2;
+   */
+  from = BookingStatus.UNSPECIFIED;
+
+  /**
+   * @generated from field: travelingman.BookingStatus to = 3;
+   */
+  to = BookingStatus.UNSPECIFIED;
+
+  /**
+   * @generated from field: repeated travelingman.FlightChange changes = 4;
+   */
+  changes: FlightChange[] = [];
+
+  /**
+   * @generated from field: google.protobuf.Timestamp changed_at = 5;
+   */
+  changedAt?: Timestamp;
+
+  constructor(data?: PartialMessage<BookingStatusChange>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.BookingStatusChange";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "booking_id", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 2, name: "from", kind: "enum", T: proto3.getEnumType(BookingStatus) },
+    { no: 3, name: "to", kind: "enum", T: proto3.getEnumType(BookingStatus) },
+    { no: 4, name: "changes", kind: "message", T: FlightChange, repeated: true },
+    { no: 5, name: "changed_at", kind: "message", T: Timestamp },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): BookingStatusChange {
+    return new BookingStatusChange().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): BookingStatusChange {
+    return new BookingStatusChange().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): BookingStatusChange {
+    return new BookingStatusChange().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: BookingStatusChange | PlainMessage<BookingStatusChange> | undefined, b: BookingStatusChange | PlainMessage<BookingStatusChange> | undefined): boolean {
+    return proto3.util.equals(BookingStatusChange, a, b);
+  }
+}
+
 /**
  * @generated from message travelingman.Booking
  */
@@ -416,3 +594,97 @@ export class Payment extends Message<Payment> {
   }
 }
 
+
+/**
+ * @generated from enum travelingman.SplitMode
+ */
+export enum SplitMode {
+  /**
+   * @generated from enum value: SPLIT_MODE_UNSPECIFIED = 0;
+   */
+  UNSPECIFIED = 0,
+
+  /**
+   * Equally among user_ids
+   *
+   * @generated from enum value: SPLIT_MODE_EQUAL = 1;
+   */
+  EQUAL = 1,
+
+  /**
+   * Each traveler owes their own fare
+   *
+   * @generated from enum value: SPLIT_MODE_BY_TRAVELER = 2;
+   */
+  BY_TRAVELER = 2,
+
+  /**
+   * The amounts in shares
+   *
+   * @generated from enum value: SPLIT_MODE_EXPLICIT = 3;
+   */
+  EXPLICIT = 3,
+}
+// Retrieve enum metadata with: proto3.getEnumType(SplitMode)
+proto3.util.setEnumType(SplitMode, "travelingman.SplitMode", [
+  { no: 0, name: "SPLIT_MODE_UNSPECIFIED" },
+  { no: 1, name: "SPLIT_MODE_EQUAL" },
+  { no: 2, name: "SPLIT_MODE_BY_TRAVELER" },
+  { no: 3, name: "SPLIT_MODE_EXPLICIT" },
+]);
+
+/**
+ * PaymentSplit records who owes what of a booking paid with one card. It is
+ * bookkeeping only; no money is moved.
+ *
+ * @generated from message travelingman.PaymentSplit
+ */
+export class PaymentSplit extends Message<PaymentSplit> {
+  /**
+   * @generated from field: travelingman.SplitMode mode = 1;
+   */
+  mode = SplitMode.UNSPECIFIED;
+
+  /**
+   * Who shares an equal split, in remainder order
+   *
+   * @generated from field: repeated int64 user_ids = 2;
+   */
+  userIds: bigint[] = [];
+
+  /**
+   * Amount per user_id for an explicit split
+   *
+   * @generated from field: repeated travelingman.Payment shares = 3;
+   */
+  shares: Payment[] = [];
+
+  constructor(data?: PartialMessage<PaymentSplit>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.PaymentSplit";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "mode", kind: "enum", T: proto3.getEnumType(SplitMode) },
+    { no: 2, name: "user_ids", kind: "scalar", T: 3 /* ScalarType.INT64 */, repeated: true },
+    { no: 3, name: "shares", kind: "message", T: Payment, repeated: true },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): PaymentSplit {
+    return new PaymentSplit().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): PaymentSplit {
+    return new PaymentSplit().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): PaymentSplit {
+    return new PaymentSplit().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: PaymentSplit | PlainMessage<PaymentSplit> | undefined, b: PaymentSplit | PlainMessage<PaymentSplit> | undefined): boolean {
+    return proto3.util.equals(PaymentSplit, a, b);
+  }
+}
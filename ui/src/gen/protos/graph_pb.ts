@@ -5,6 +5,7 @@
 
 import type { BinaryReadOptions, FieldList, JsonReadOptions, JsonValue, PartialMessage, PlainMessage } from "@bufbuild/protobuf";
 import { Message, proto3, protoInt64, Timestamp } from "@bufbuild/protobuf";
+import { Cost } from "./common_pb.js";
 import { Accommodation, Error, Location, Transport } from "./itinerary_pb.js";
 
 /**
@@ -107,6 +108,13 @@ export class Node extends Message<Node> {
    */
   subGraph?: Graph;
 
+  /**
+   * The user's own plans here, e.g. "dinner at Le Comptoir"; kept as written
+   *
+   * @generated from field: string notes = 9;
+   */
+  notes = "";
+
   constructor(data?: PartialMessage<Node>) {
     super();
     proto3.util.initPartial(data, this);
@@ -122,6 +130,7 @@ export class Node extends Message<Node> {
     { no: 5, name: "stay", kind: "message", T: Accommodation },
     { no: 6, name: "stayOptions", kind: "message", T: Accommodation, repeated: true },
     { no: 7, name: "sub_graph", kind: "message", T: Graph },
+    { no: 9, name: "notes", kind: "scalar", T: 9 /* ScalarType.STRING */ },
   ]);
 
   static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): Node {
@@ -183,6 +192,13 @@ export class Edge extends Message<Edge> {
    */
   transportOptions: Transport[] = [];
 
+  /**
+   * transportOptions grouped for display; selection is unaffected
+   *
+   * @generated from field: repeated travelingman.OptionGroup option_groups = 6;
+   */
+  optionGroups: OptionGroup[] = [];
+
   constructor(data?: PartialMessage<Edge>) {
     super();
     proto3.util.initPartial(data, this);
@@ -196,6 +212,7 @@ export class Edge extends Message<Edge> {
     { no: 3, name: "duration_seconds", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
     { no: 4, name: "transport", kind: "message", T: Transport },
     { no: 5, name: "transportOptions", kind: "message", T: Transport, repeated: true },
+    { no: 6, name: "option_groups", kind: "message", T: OptionGroup, repeated: true },
   ]);
 
   static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): Edge {
@@ -336,6 +353,41 @@ export class Itinerary extends Message<Itinerary> {
    */
   error?: Error;
 
+  /**
+   * Optimistic concurrency token for saved trips
+   *
+   * @generated from field: int64 version = 13;
+   */
+  version = protoInt64.zero;
+
+  /**
+   * Time split between travelling and being there
+   *
+   * @generated from field: travelingman.ItineraryStats stats = 14;
+   */
+  stats?: ItineraryStats;
+
+  /**
+   * Total the travellers want to spend on the whole trip, if they said
+   *
+   * @generated from field: travelingman.Cost budget = 15;
+   */
+  budget?: Cost;
+
+  /**
+   * Suggested split of the budget across flights and hotels
+   *
+   * @generated from field: travelingman.BudgetSplit budget_split = 16;
+   */
+  budgetSplit?: BudgetSplit;
+
+  /**
+   * Where the money goes, from the selected options
+   *
+   * @generated from field: travelingman.CostBreakdown cost_breakdown = 17;
+   */
+  costBreakdown?: CostBreakdown;
+
   constructor(data?: PartialMessage<Itinerary>) {
     super();
     proto3.util.initPartial(data, this);
@@ -356,6 +408,11 @@ export class Itinerary extends Message<Itinerary> {
     { no: 10, name: "tags", kind: "scalar", T: 9 /* ScalarType.STRING */, repeated: true },
     { no: 11, name: "journey_type", kind: "enum", T: proto3.getEnumType(JourneyType) },
     { no: 12, name: "error", kind: "message", T: Error },
+    { no: 13, name: "version", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 14, name: "stats", kind: "message", T: ItineraryStats },
+    { no: 15, name: "budget", kind: "message", T: Cost },
+    { no: 16, name: "budget_split", kind: "message", T: BudgetSplit },
+    { no: 17, name: "cost_breakdown", kind: "message", T: CostBreakdown },
   ]);
 
   static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): Itinerary {
@@ -375,3 +432,361 @@ export class Itinerary extends Message<Itinerary> {
   }
 }
 
+/**
+ * ItineraryStats summarizes how much of a trip is spent in transit versus at the destinations
+ *
+ * @generated from message travelingman.ItineraryStats
+ */
+export class ItineraryStats extends Message<ItineraryStats> {
+  /**
+   * Transport time incl. layovers and short transfers between legs
+   *
+   * @generated from field: int64 transit_seconds = 1;
+   */
+  transitSeconds = protoInt64.zero;
+
+  /**
+   * Time at destinations (node windows minus transit)
+   *
+   * @generated from field: int64 destination_seconds = 2;
+   */
+  destinationSeconds = protoInt64.zero;
+
+  /**
+   * Number of transport legs, including sub-graphs
+   *
+   * @generated from field: int32 hop_count = 3;
+   */
+  hopCount = 0;
+
+  /**
+   * transit / (transit + destination)
+   *
+   * @generated from field: double transit_ratio = 4;
+   */
+  transitRatio = 0;
+
+  /**
+   * Some times were missing; totals are lower bounds
+   *
+   * @generated from field: bool partial = 5;
+   */
+  partial = false;
+
+  /**
+   * Time at each destination, in visiting order
+   *
+   * @generated from field: repeated travelingman.DestinationTime destinations = 6;
+   */
+  destinations: DestinationTime[] = [];
+
+  constructor(data?: PartialMessage<ItineraryStats>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.ItineraryStats";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "transit_seconds", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 2, name: "destination_seconds", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 3, name: "hop_count", kind: "scalar", T: 5 /* ScalarType.INT32 */ },
+    { no: 4, name: "transit_ratio", kind: "scalar", T: 1 /* ScalarType.DOUBLE */ },
+    { no: 5, name: "partial", kind: "scalar", T: 8 /* ScalarType.BOOL */ },
+    { no: 6, name: "destinations", kind: "message", T: DestinationTime, repeated: true },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): ItineraryStats {
+    return new ItineraryStats().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): ItineraryStats {
+    return new ItineraryStats().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): ItineraryStats {
+    return new ItineraryStats().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: ItineraryStats | PlainMessage<ItineraryStats> | undefined, b: ItineraryStats | PlainMessage<ItineraryStats> | undefined): boolean {
+    return proto3.util.equals(ItineraryStats, a, b);
+  }
+}
+
+/**
+ * @generated from message travelingman.DestinationTime
+ */
+export class DestinationTime extends Message<DestinationTime> {
+  /**
+   * @generated from field: string node_id = 1;
+   */
+  nodeId = "";
+
+  /**
+   * City or name of the node's location
+   *
+   * @generated from field: string place = 2;
+   */
+  place = "";
+
+  /**
+   * @generated from field: int64 seconds = 3;
+   */
+  seconds = protoInt64.zero;
+
+  constructor(data?: PartialMessage<DestinationTime>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.DestinationTime";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "node_id", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "place", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "seconds", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): DestinationTime {
+    return new DestinationTime().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): DestinationTime {
+    return new DestinationTime().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): DestinationTime {
+    return new DestinationTime().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: DestinationTime | PlainMessage<DestinationTime> | undefined, b: DestinationTime | PlainMessage<DestinationTime> | undefined): boolean {
+    return proto3.util.equals(DestinationTime, a, b);
+  }
+}
+
+/**
+ * BudgetSplit is how a trip's budget is suggested to be spent. The per-flight and
+ * per-night caps derived from it are passed to the searches as soft filters.
+ *
+ * @generated from message travelingman.BudgetSplit
+ */
+export class BudgetSplit extends Message<BudgetSplit> {
+  /**
+   * All flights, for all travellers
+   *
+   * @generated from field: travelingman.Cost flights = 1;
+   */
+  flights?: Cost;
+
+  /**
+   * All nights
+   *
+   * @generated from field: travelingman.Cost hotels = 2;
+   */
+  hotels?: Cost;
+
+  /**
+   * Held back for everything else
+   *
+   * @generated from field: travelingman.Cost buffer = 3;
+   */
+  buffer?: Cost;
+
+  /**
+   * @generated from field: int32 flight_legs = 4;
+   */
+  flightLegs = 0;
+
+  /**
+   * @generated from field: int32 nights = 5;
+   */
+  nights = 0;
+
+  /**
+   * Price level of the destinations, 1 is average
+   *
+   * @generated from field: double price_index = 6;
+   */
+  priceIndex = 0;
+
+  /**
+   * How the split was rebalanced after verification, if it was
+   *
+   * @generated from field: string note = 7;
+   */
+  note = "";
+
+  constructor(data?: PartialMessage<BudgetSplit>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.BudgetSplit";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "flights", kind: "message", T: Cost },
+    { no: 2, name: "hotels", kind: "message", T: Cost },
+    { no: 3, name: "buffer", kind: "message", T: Cost },
+    { no: 4, name: "flight_legs", kind: "scalar", T: 5 /* ScalarType.INT32 */ },
+    { no: 5, name: "nights", kind: "scalar", T: 5 /* ScalarType.INT32 */ },
+    { no: 6, name: "price_index", kind: "scalar", T: 1 /* ScalarType.DOUBLE */ },
+    { no: 7, name: "note", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): BudgetSplit {
+    return new BudgetSplit().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): BudgetSplit {
+    return new BudgetSplit().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): BudgetSplit {
+    return new BudgetSplit().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: BudgetSplit | PlainMessage<BudgetSplit> | undefined, b: BudgetSplit | PlainMessage<BudgetSplit> | undefined): boolean {
+    return proto3.util.equals(BudgetSplit, a, b);
+  }
+}
+
+/**
+ * CostBreakdown splits an itinerary's total over the selected options. Costs in another
+ * currency than the first one seen cannot be added up and are left out.
+ *
+ * @generated from message travelingman.CostBreakdown
+ */
+export class CostBreakdown extends Message<CostBreakdown> {
+  /**
+   * Flights, trains and car rentals
+   *
+   * @generated from field: travelingman.Cost transport = 1;
+   */
+  transport?: Cost;
+
+  /**
+   * Stays
+   *
+   * @generated from field: travelingman.Cost accommodation = 2;
+   */
+  accommodation?: Cost;
+
+  /**
+   * Flight extras such as bags and seats
+   *
+   * @generated from field: travelingman.Cost ancillaries = 3;
+   */
+  ancillaries?: Cost;
+
+  /**
+   * Sum of the above
+   *
+   * @generated from field: travelingman.Cost total = 4;
+   */
+  total?: Cost;
+
+  /**
+   * Some costs were in another currency and are left out
+   *
+   * @generated from field: bool partial = 5;
+   */
+  partial = false;
+
+  constructor(data?: PartialMessage<CostBreakdown>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.CostBreakdown";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "transport", kind: "message", T: Cost },
+    { no: 2, name: "accommodation", kind: "message", T: Cost },
+    { no: 3, name: "ancillaries", kind: "message", T: Cost },
+    { no: 4, name: "total", kind: "message", T: Cost },
+    { no: 5, name: "partial", kind: "scalar", T: 8 /* ScalarType.BOOL */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): CostBreakdown {
+    return new CostBreakdown().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): CostBreakdown {
+    return new CostBreakdown().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): CostBreakdown {
+    return new CostBreakdown().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: CostBreakdown | PlainMessage<CostBreakdown> | undefined, b: CostBreakdown | PlainMessage<CostBreakdown> | undefined): boolean {
+    return proto3.util.equals(CostBreakdown, a, b);
+  }
+}
+
+/**
+ * OptionGroup collects transport options on an edge that depart in the same time window
+ * with the same carrier. Options are referred to by ID; all of them stay in transportOptions.
+ *
+ * @generated from message travelingman.OptionGroup
+ */
+export class OptionGroup extends Message<OptionGroup> {
+  /**
+   * early_morning, morning, afternoon, evening or night, in local time
+   *
+   * @generated from field: string time_window = 1;
+   */
+  timeWindow = "";
+
+  /**
+   * Carrier code shared by the options
+   *
+   * @generated from field: string carrier = 2;
+   */
+  carrier = "";
+
+  /**
+   * Options shown: those tagged Cheapest, Fastest or Best Value, else the best scored
+   *
+   * @generated from field: repeated string visible_ids = 3;
+   */
+  visibleIds: string[] = [];
+
+  /**
+   * Similar options shown only as a count
+   *
+   * @generated from field: repeated string collapsed_ids = 4;
+   */
+  collapsedIds: string[] = [];
+
+  constructor(data?: PartialMessage<OptionGroup>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.OptionGroup";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "time_window", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "carrier", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "visible_ids", kind: "scalar", T: 9 /* ScalarType.STRING */, repeated: true },
+    { no: 4, name: "collapsed_ids", kind: "scalar", T: 9 /* ScalarType.STRING */, repeated: true },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): OptionGroup {
+    return new OptionGroup().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): OptionGroup {
+    return new OptionGroup().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): OptionGroup {
+    return new OptionGroup().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: OptionGroup | PlainMessage<OptionGroup> | undefined, b: OptionGroup | PlainMessage<OptionGroup> | undefined): boolean {
+    return proto3.util.equals(OptionGroup, a, b);
+  }
+}
+
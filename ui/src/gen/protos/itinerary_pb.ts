@@ -150,6 +150,8 @@ export enum ErrorCode {
   SEARCH_FAILED = 1,
 
   /**
+   * Nothing exists to offer, e.g. a city without hotels
+   *
    * @generated from enum value: ERROR_CODE_DATA_NOT_FOUND = 2;
    */
   DATA_NOT_FOUND = 2,
@@ -178,6 +180,13 @@ export enum ErrorCode {
    * @generated from enum value: ERROR_CODE_CONNECTION_FAILED = 7;
    */
   CONNECTION_FAILED = 7,
+
+  /**
+   * It exists but is unavailable on the requested dates
+   *
+   * @generated from enum value: ERROR_CODE_NO_AVAILABILITY = 8;
+   */
+  NO_AVAILABILITY = 8,
 }
 // Retrieve enum metadata with: proto3.getEnumType(ErrorCode)
 proto3.util.setEnumType(ErrorCode, "travelingman.ErrorCode", [
@@ -189,6 +198,7 @@ proto3.util.setEnumType(ErrorCode, "travelingman.ErrorCode", [
   { no: 5, name: "ERROR_CODE_AUTHENTICATION_FAILED" },
   { no: 6, name: "ERROR_CODE_INTERNAL_SERVER_ERROR" },
   { no: 7, name: "ERROR_CODE_CONNECTION_FAILED" },
+  { no: 8, name: "ERROR_CODE_NO_AVAILABILITY" },
 ]);
 
 /**
@@ -247,6 +257,41 @@ export class AccommodationPreferences extends Message<AccommodationPreferences>
    */
   amenities: string[] = [];
 
+  /**
+   * Never relax rating or amenities when no hotel matches
+   *
+   * @generated from field: bool strict = 5;
+   */
+  strict = false;
+
+  /**
+   * Soft cap on the price per night, in the stay's currency (0 for none)
+   *
+   * @generated from field: double max_nightly_price = 6;
+   */
+  maxNightlyPrice = 0;
+
+  /**
+   * The user wants breakfast; stays without it are compared as if it were bought separately
+   *
+   * @generated from field: bool breakfast = 7;
+   */
+  breakfast = false;
+
+  /**
+   * The user wants to stay in this exact place; never use hotels in a nearby city instead
+   *
+   * @generated from field: bool strict_location = 8;
+   */
+  strictLocation = false;
+
+  /**
+   * The user wants to stay close to the airport or station they arrive at; stays are ranked by the distance to it
+   *
+   * @generated from field: bool near_transit = 9;
+   */
+  nearTransit = false;
+
   constructor(data?: PartialMessage<AccommodationPreferences>) {
     super();
     proto3.util.initPartial(data, this);
@@ -259,6 +304,11 @@ export class AccommodationPreferences extends Message<AccommodationPreferences>
     { no: 2, name: "area", kind: "scalar", T: 9 /* ScalarType.STRING */ },
     { no: 3, name: "rating", kind: "scalar", T: 5 /* ScalarType.INT32 */ },
     { no: 4, name: "amenities", kind: "scalar", T: 9 /* ScalarType.STRING */, repeated: true },
+    { no: 5, name: "strict", kind: "scalar", T: 8 /* ScalarType.BOOL */ },
+    { no: 6, name: "max_nightly_price", kind: "scalar", T: 1 /* ScalarType.DOUBLE */ },
+    { no: 7, name: "breakfast", kind: "scalar", T: 8 /* ScalarType.BOOL */ },
+    { no: 8, name: "strict_location", kind: "scalar", T: 8 /* ScalarType.BOOL */ },
+    { no: 9, name: "near_transit", kind: "scalar", T: 8 /* ScalarType.BOOL */ },
   ]);
 
   static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): AccommodationPreferences {
@@ -288,9 +338,11 @@ export class FlightPreferences extends Message<FlightPreferences> {
   travelClass = Class.UNSPECIFIED;
 
   /**
-   * @generated from field: int32 max_stops = 2;
+   * Most stops per flight, 0 for non-stop only; unset for any number
+   *
+   * @generated from field: optional int32 max_stops = 2;
    */
-  maxStops = 0;
+  maxStops?: number;
 
   /**
    * @generated from field: repeated string preferred_origin_airports = 3;
@@ -309,6 +361,20 @@ export class FlightPreferences extends Message<FlightPreferences> {
    */
   baggage?: BaggagePreferences;
 
+  /**
+   * Soft cap on the fare per traveller, in the transport's currency (0 for none)
+   *
+   * @generated from field: double max_price = 6;
+   */
+  maxPrice = 0;
+
+  /**
+   * Latest arrival, in the destination's local time like flight times; unset for any
+   *
+   * @generated from field: google.protobuf.Timestamp arrival_by = 7;
+   */
+  arrivalBy?: Timestamp;
+
   constructor(data?: PartialMessage<FlightPreferences>) {
     super();
     proto3.util.initPartial(data, this);
@@ -318,10 +384,12 @@ export class FlightPreferences extends Message<FlightPreferences> {
   static readonly typeName = "travelingman.FlightPreferences";
   static readonly fields: FieldList = proto3.util.newFieldList(() => [
     { no: 1, name: "travel_class", kind: "enum", T: proto3.getEnumType(Class) },
-    { no: 2, name: "max_stops", kind: "scalar", T: 5 /* ScalarType.INT32 */ },
+    { no: 2, name: "max_stops", kind: "scalar", T: 5 /* ScalarType.INT32 */, opt: true },
     { no: 3, name: "preferred_origin_airports", kind: "scalar", T: 9 /* ScalarType.STRING */, repeated: true },
     { no: 4, name: "preferred_destination_airports", kind: "scalar", T: 9 /* ScalarType.STRING */, repeated: true },
     { no: 5, name: "baggage", kind: "message", T: BaggagePreferences },
+    { no: 6, name: "max_price", kind: "scalar", T: 1 /* ScalarType.DOUBLE */ },
+    { no: 7, name: "arrival_by", kind: "message", T: Timestamp },
   ]);
 
   static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): FlightPreferences {
@@ -734,6 +802,77 @@ export class Error extends Message<Error> {
   }
 }
 
+/**
+ * PaymentPolicy is how a rate must be paid or guaranteed at booking
+ *
+ * @generated from message travelingman.PaymentPolicy
+ */
+export class PaymentPolicy extends Message<PaymentPolicy> {
+  /**
+   * Card vendor codes, e.g. VI, AX; empty accepts any card
+   *
+   * @generated from field: repeated string accepted_card_vendors = 1;
+   */
+  acceptedCardVendors: string[] = [];
+
+  /**
+   * Payment methods, e.g. CREDIT_CARD
+   *
+   * @generated from field: repeated string accepted_methods = 2;
+   */
+  acceptedMethods: string[] = [];
+
+  /**
+   * A card must be given to hold the room
+   *
+   * @generated from field: bool guarantee_required = 3;
+   */
+  guaranteeRequired = false;
+
+  /**
+   * Charged at booking, if the rate needs a deposit
+   *
+   * @generated from field: travelingman.Cost deposit = 4;
+   */
+  deposit?: Cost;
+
+  /**
+   * @generated from field: google.protobuf.Timestamp deposit_deadline = 5;
+   */
+  depositDeadline?: Timestamp;
+
+  constructor(data?: PartialMessage<PaymentPolicy>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.PaymentPolicy";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "accepted_card_vendors", kind: "scalar", T: 9 /* ScalarType.STRING */, repeated: true },
+    { no: 2, name: "accepted_methods", kind: "scalar", T: 9 /* ScalarType.STRING */, repeated: true },
+    { no: 3, name: "guarantee_required", kind: "scalar", T: 8 /* ScalarType.BOOL */ },
+    { no: 4, name: "deposit", kind: "message", T: Cost },
+    { no: 5, name: "deposit_deadline", kind: "message", T: Timestamp },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): PaymentPolicy {
+    return new PaymentPolicy().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): PaymentPolicy {
+    return new PaymentPolicy().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): PaymentPolicy {
+    return new PaymentPolicy().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: PaymentPolicy | PlainMessage<PaymentPolicy> | undefined, b: PaymentPolicy | PlainMessage<PaymentPolicy> | undefined): boolean {
+    return proto3.util.equals(PaymentPolicy, a, b);
+  }
+}
+
 /**
  * @generated from message travelingman.Accommodation
  */
@@ -808,6 +947,67 @@ export class Accommodation extends Message<Accommodation> {
    */
   tags: string[] = [];
 
+  /**
+   * Provider property ID; offers from the same hotel share it
+   *
+   * @generated from field: string hotel_id = 16;
+   */
+  hotelId = "";
+
+  /**
+   * Cheapest offer among those from the same hotel
+   *
+   * @generated from field: bool property_cheapest = 17;
+   */
+  propertyCheapest = false;
+
+  /**
+   * Price per night: the provider's average, else the total over the nights
+   *
+   * @generated from field: travelingman.Cost nightly_cost = 18;
+   */
+  nightlyCost?: Cost;
+
+  /**
+   * The rate includes breakfast
+   *
+   * @generated from field: bool breakfast_included = 19;
+   */
+  breakfastIncluded = false;
+
+  /**
+   * @generated from field: travelingman.PaymentPolicy payment_policy = 20;
+   */
+  paymentPolicy?: PaymentPolicy;
+
+  /**
+   * What to know before choosing, e.g. a deposit due at booking
+   *
+   * @generated from field: repeated string warnings = 21;
+   */
+  warnings: string[] = [];
+
+  /**
+   * Ages of the children among traveler_count, for family rooms
+   *
+   * @generated from field: repeated int32 child_ages = 22;
+   */
+  childAges: number[] = [];
+
+  /**
+   * Provider code of the hotel's chain, e.g. MC for Marriott
+   *
+   * @generated from field: string chain_code = 23;
+   */
+  chainCode = "";
+
+  /**
+   * The hotel belongs to one of the preferred chains
+   *
+   * @generated from field: bool preferred_chain = 24;
+   */
+  preferredChain = false;
+
   constructor(data?: PartialMessage<Accommodation>) {
     super();
     proto3.util.initPartial(data, this);
@@ -830,6 +1030,15 @@ export class Accommodation extends Message<Accommodation> {
     { no: 13, name: "location", kind: "message", T: Location },
     { no: 14, name: "error", kind: "message", T: Error },
     { no: 15, name: "tags", kind: "scalar", T: 9 /* ScalarType.STRING */, repeated: true },
+    { no: 16, name: "hotel_id", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 17, name: "property_cheapest", kind: "scalar", T: 8 /* ScalarType.BOOL */ },
+    { no: 18, name: "nightly_cost", kind: "message", T: Cost },
+    { no: 19, name: "breakfast_included", kind: "scalar", T: 8 /* ScalarType.BOOL */ },
+    { no: 20, name: "payment_policy", kind: "message", T: PaymentPolicy },
+    { no: 21, name: "warnings", kind: "scalar", T: 9 /* ScalarType.STRING */, repeated: true },
+    { no: 22, name: "child_ages", kind: "scalar", T: 5 /* ScalarType.INT32 */, repeated: true },
+    { no: 23, name: "chain_code", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 24, name: "preferred_chain", kind: "scalar", T: 8 /* ScalarType.BOOL */ },
   ]);
 
   static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): Accommodation {
@@ -1068,6 +1277,20 @@ export class Flight extends Message<Flight> {
    */
   totalDuration = "";
 
+  /**
+   * Airline name, if the provider returned it
+   *
+   * @generated from field: string carrier_name = 11;
+   */
+  carrierName = "";
+
+  /**
+   * Connections between the segments, in order
+   *
+   * @generated from field: repeated travelingman.Layover layovers = 12;
+   */
+  layovers: Layover[] = [];
+
   constructor(data?: PartialMessage<Flight>) {
     super();
     proto3.util.initPartial(data, this);
@@ -1086,6 +1309,8 @@ export class Flight extends Message<Flight> {
     { no: 8, name: "segments", kind: "message", T: FlightSegment, repeated: true },
     { no: 9, name: "layover_count", kind: "scalar", T: 5 /* ScalarType.INT32 */ },
     { no: 10, name: "total_duration", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 11, name: "carrier_name", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 12, name: "layovers", kind: "message", T: Layover, repeated: true },
   ]);
 
   static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): Flight {
@@ -1165,6 +1390,27 @@ export class FlightSegment extends Message<FlightSegment> {
    */
   stops = 0;
 
+  /**
+   * Origin terminal, if known
+   *
+   * @generated from field: string departure_terminal = 9;
+   */
+  departureTerminal = "";
+
+  /**
+   * Destination terminal, if known
+   *
+   * @generated from field: string arrival_terminal = 10;
+   */
+  arrivalTerminal = "";
+
+  /**
+   * Airline name, if the provider returned it
+   *
+   * @generated from field: string carrier_name = 11;
+   */
+  carrierName = "";
+
   constructor(data?: PartialMessage<FlightSegment>) {
     super();
     proto3.util.initPartial(data, this);
@@ -1181,6 +1427,9 @@ export class FlightSegment extends Message<FlightSegment> {
     { no: 6, name: "arrival_airport_code", kind: "scalar", T: 9 /* ScalarType.STRING */ },
     { no: 7, name: "duration", kind: "scalar", T: 9 /* ScalarType.STRING */ },
     { no: 8, name: "stops", kind: "scalar", T: 5 /* ScalarType.INT32 */ },
+    { no: 9, name: "departure_terminal", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 10, name: "arrival_terminal", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 11, name: "carrier_name", kind: "scalar", T: 9 /* ScalarType.STRING */ },
   ]);
 
   static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): FlightSegment {
@@ -1200,6 +1449,71 @@ export class FlightSegment extends Message<FlightSegment> {
   }
 }
 
+/**
+ * Layover is a connection between two segments of a flight
+ *
+ * @generated from message travelingman.Layover
+ */
+export class Layover extends Message<Layover> {
+  /**
+   * IATA code of the airport the inbound segment lands at
+   *
+   * @generated from field: string airport_code = 1;
+   */
+  airportCode = "";
+
+  /**
+   * Arrival of the inbound segment
+   *
+   * @generated from field: google.protobuf.Timestamp arrival_time = 2;
+   */
+  arrivalTime?: Timestamp;
+
+  /**
+   * Departure of the outbound segment
+   *
+   * @generated from field: google.protobuf.Timestamp departure_time = 3;
+   */
+  departureTime?: Timestamp;
+
+  /**
+   * Time between the two, 0 if either time is unknown
+   *
+   * @generated from field: int64 duration_seconds = 4;
+   */
+  durationSeconds = protoInt64.zero;
+
+  constructor(data?: PartialMessage<Layover>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.Layover";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "airport_code", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "arrival_time", kind: "message", T: Timestamp },
+    { no: 3, name: "departure_time", kind: "message", T: Timestamp },
+    { no: 4, name: "duration_seconds", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): Layover {
+    return new Layover().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): Layover {
+    return new Layover().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): Layover {
+    return new Layover().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: Layover | PlainMessage<Layover> | undefined, b: Layover | PlainMessage<Layover> | undefined): boolean {
+    return proto3.util.equals(Layover, a, b);
+  }
+}
+
 /**
  * @generated from message travelingman.Train
  */
@@ -1304,3 +1618,335 @@ export class CarRental extends Message<CarRental> {
   }
 }
 
+/**
+ * @generated from message travelingman.DayPrice
+ */
+export class DayPrice extends Message<DayPrice> {
+  /**
+   * @generated from field: google.protobuf.Timestamp date = 1;
+   */
+  date?: Timestamp;
+
+  /**
+   * Indicative lowest fare for the day
+   *
+   * @generated from field: travelingman.Cost cost = 2;
+   */
+  cost?: Cost;
+
+  /**
+   * Estimated from neighbouring sampled days, not searched
+   *
+   * @generated from field: bool interpolated = 3;
+   */
+  interpolated = false;
+
+  /**
+   * Cheapest day in the calendar
+   *
+   * @generated from field: bool is_min = 4;
+   */
+  isMin = false;
+
+  /**
+   * Day closest to the median price
+   *
+   * @generated from field: bool is_median = 5;
+   */
+  isMedian = false;
+
+  constructor(data?: PartialMessage<DayPrice>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.DayPrice";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "date", kind: "message", T: Timestamp },
+    { no: 2, name: "cost", kind: "message", T: Cost },
+    { no: 3, name: "interpolated", kind: "scalar", T: 8 /* ScalarType.BOOL */ },
+    { no: 4, name: "is_min", kind: "scalar", T: 8 /* ScalarType.BOOL */ },
+    { no: 5, name: "is_median", kind: "scalar", T: 8 /* ScalarType.BOOL */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): DayPrice {
+    return new DayPrice().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): DayPrice {
+    return new DayPrice().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): DayPrice {
+    return new DayPrice().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: DayPrice | PlainMessage<DayPrice> | undefined, b: DayPrice | PlainMessage<DayPrice> | undefined): boolean {
+    return proto3.util.equals(DayPrice, a, b);
+  }
+}
+
+/**
+ * @generated from message travelingman.PriceCalendar
+ */
+export class PriceCalendar extends Message<PriceCalendar> {
+  /**
+   * Origin IATA code
+   *
+   * @generated from field: string origin = 1;
+   */
+  origin = "";
+
+  /**
+   * Destination IATA code
+   *
+   * @generated from field: string destination = 2;
+   */
+  destination = "";
+
+  /**
+   * YYYY-MM
+   *
+   * @generated from field: string month = 3;
+   */
+  month = "";
+
+  /**
+   * @generated from field: int32 traveler_count = 4;
+   */
+  travelerCount = 0;
+
+  /**
+   * @generated from field: repeated travelingman.DayPrice days = 5;
+   */
+  days: DayPrice[] = [];
+
+  /**
+   * @generated from field: travelingman.Cost min_price = 6;
+   */
+  minPrice?: Cost;
+
+  /**
+   * @generated from field: travelingman.Cost median_price = 7;
+   */
+  medianPrice?: Cost;
+
+  /**
+   * FLIGHT_DATES or SAMPLED
+   *
+   * @generated from field: string source = 8;
+   */
+  source = "";
+
+  constructor(data?: PartialMessage<PriceCalendar>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.PriceCalendar";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "origin", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "destination", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "month", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 4, name: "traveler_count", kind: "scalar", T: 5 /* ScalarType.INT32 */ },
+    { no: 5, name: "days", kind: "message", T: DayPrice, repeated: true },
+    { no: 6, name: "min_price", kind: "message", T: Cost },
+    { no: 7, name: "median_price", kind: "message", T: Cost },
+    { no: 8, name: "source", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): PriceCalendar {
+    return new PriceCalendar().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): PriceCalendar {
+    return new PriceCalendar().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): PriceCalendar {
+    return new PriceCalendar().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: PriceCalendar | PlainMessage<PriceCalendar> | undefined, b: PriceCalendar | PlainMessage<PriceCalendar> | undefined): boolean {
+    return proto3.util.equals(PriceCalendar, a, b);
+  }
+}
+
+/**
+ * @generated from message travelingman.WeekPrice
+ */
+export class WeekPrice extends Message<WeekPrice> {
+  /**
+   * First departure day of the week
+   *
+   * @generated from field: google.protobuf.Timestamp week_start = 1;
+   */
+  weekStart?: Timestamp;
+
+  /**
+   * Last departure day of the week (clipped to the range)
+   *
+   * @generated from field: google.protobuf.Timestamp week_end = 2;
+   */
+  weekEnd?: Timestamp;
+
+  /**
+   * Indicative lowest fare in the week
+   *
+   * @generated from field: travelingman.Cost cost = 3;
+   */
+  cost?: Cost;
+
+  /**
+   * Departure day the fare was found on (unset when interpolated)
+   *
+   * @generated from field: google.protobuf.Timestamp cheapest_date = 4;
+   */
+  cheapestDate?: Timestamp;
+
+  /**
+   * Estimated from neighbouring sampled weeks, not searched
+   *
+   * @generated from field: bool interpolated = 5;
+   */
+  interpolated = false;
+
+  /**
+   * Cheapest week in the range
+   *
+   * @generated from field: bool is_min = 6;
+   */
+  isMin = false;
+
+  constructor(data?: PartialMessage<WeekPrice>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.WeekPrice";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "week_start", kind: "message", T: Timestamp },
+    { no: 2, name: "week_end", kind: "message", T: Timestamp },
+    { no: 3, name: "cost", kind: "message", T: Cost },
+    { no: 4, name: "cheapest_date", kind: "message", T: Timestamp },
+    { no: 5, name: "interpolated", kind: "scalar", T: 8 /* ScalarType.BOOL */ },
+    { no: 6, name: "is_min", kind: "scalar", T: 8 /* ScalarType.BOOL */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): WeekPrice {
+    return new WeekPrice().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): WeekPrice {
+    return new WeekPrice().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): WeekPrice {
+    return new WeekPrice().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: WeekPrice | PlainMessage<WeekPrice> | undefined, b: WeekPrice | PlainMessage<WeekPrice> | undefined): boolean {
+    return proto3.util.equals(WeekPrice, a, b);
+  }
+}
+
+/**
+ * @generated from message travelingman.FareTrend
+ */
+export class FareTrend extends Message<FareTrend> {
+  /**
+   * Origin IATA code
+   *
+   * @generated from field: string origin = 1;
+   */
+  origin = "";
+
+  /**
+   * Destination IATA code
+   *
+   * @generated from field: string destination = 2;
+   */
+  destination = "";
+
+  /**
+   * @generated from field: google.protobuf.Timestamp from_date = 3;
+   */
+  fromDate?: Timestamp;
+
+  /**
+   * @generated from field: google.protobuf.Timestamp to_date = 4;
+   */
+  toDate?: Timestamp;
+
+  /**
+   * @generated from field: int32 traveler_count = 5;
+   */
+  travelerCount = 0;
+
+  /**
+   * Per-week price trend, in date order
+   *
+   * @generated from field: repeated travelingman.WeekPrice weeks = 6;
+   */
+  weeks: WeekPrice[] = [];
+
+  /**
+   * Cheapest window in the range
+   *
+   * @generated from field: travelingman.WeekPrice cheapest_week = 7;
+   */
+  cheapestWeek?: WeekPrice;
+
+  /**
+   * FLIGHT_DATES or SAMPLED
+   *
+   * @generated from field: string source = 8;
+   */
+  source = "";
+
+  /**
+   * Number of provider searches used to build the trend
+   *
+   * @generated from field: int32 searches = 9;
+   */
+  searches = 0;
+
+  constructor(data?: PartialMessage<FareTrend>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.FareTrend";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "origin", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "destination", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "from_date", kind: "message", T: Timestamp },
+    { no: 4, name: "to_date", kind: "message", T: Timestamp },
+    { no: 5, name: "traveler_count", kind: "scalar", T: 5 /* ScalarType.INT32 */ },
+    { no: 6, name: "weeks", kind: "message", T: WeekPrice, repeated: true },
+    { no: 7, name: "cheapest_week", kind: "message", T: WeekPrice },
+    { no: 8, name: "source", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 9, name: "searches", kind: "scalar", T: 5 /* ScalarType.INT32 */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): FareTrend {
+    return new FareTrend().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): FareTrend {
+    return new FareTrend().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): FareTrend {
+    return new FareTrend().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: FareTrend | PlainMessage<FareTrend> | undefined, b: FareTrend | PlainMessage<FareTrend> | undefined): boolean {
+    return proto3.util.equals(FareTrend, a, b);
+  }
+}
+
@@ -3,8 +3,8 @@
 /* eslint-disable */
 // @ts-nocheck
 
-import { PlanTripRequest, PlanTripResponse } from "./service_pb.js";
-import { MethodKind } from "@bufbuild/protobuf";
+import { AutocompleteLocationsRequest, AutocompleteLocationsResponse, BookFlightRequest, BookFlightResponse, CancelBookingRequest, CancelBookingResponse, GetBookingSplitsRequest, GetBookingSplitsResponse, GetFareTrendRequest, GetFareTrendResponse, GetItineraryRequest, GetItineraryResponse, GetMoreOptionsRequest, GetMoreOptionsResponse, GetPriceCalendarRequest, GetPriceCalendarResponse, GetTripCalendarRequest, GetTripCalendarResponse, GetTripGraphRequest, GetTripGraphResponse, PlanTripRequest, PlanTripResponse, RefreshBookingStatusRequest, RefreshBookingStatusResponse, ResumeBookingRequest, ResumeBookingResponse, SaveTripRequest, SaveTripResponse, UpdateTripRequest, UpdateTripResponse, VerifyPlanRequest, VerifyPlanResponse } from "./service_pb.js";
+import { MethodIdempotency, MethodKind } from "@bufbuild/protobuf";
 
 /**
  * @generated from service travelingman.TravelService
@@ -21,6 +21,153 @@ export const TravelService = {
       O: PlanTripResponse,
       kind: MethodKind.Unary,
     },
+    /**
+     * @generated from rpc travelingman.TravelService.PlanTripStream
+     */
+    planTripStream: {
+      name: "PlanTripStream",
+      I: PlanTripRequest,
+      O: PlanTripResponse,
+      kind: MethodKind.ServerStreaming,
+    },
+    /**
+     * @generated from rpc travelingman.TravelService.GetPriceCalendar
+     */
+    getPriceCalendar: {
+      name: "GetPriceCalendar",
+      I: GetPriceCalendarRequest,
+      O: GetPriceCalendarResponse,
+      kind: MethodKind.Unary,
+    },
+    /**
+     * @generated from rpc travelingman.TravelService.GetFareTrend
+     */
+    getFareTrend: {
+      name: "GetFareTrend",
+      I: GetFareTrendRequest,
+      O: GetFareTrendResponse,
+      kind: MethodKind.Unary,
+    },
+    /**
+     * @generated from rpc travelingman.TravelService.SaveTrip
+     */
+    saveTrip: {
+      name: "SaveTrip",
+      I: SaveTripRequest,
+      O: SaveTripResponse,
+      kind: MethodKind.Unary,
+    },
+    /**
+     * @generated from rpc travelingman.TravelService.UpdateTrip
+     */
+    updateTrip: {
+      name: "UpdateTrip",
+      I: UpdateTripRequest,
+      O: UpdateTripResponse,
+      kind: MethodKind.Unary,
+    },
+    /**
+     * @generated from rpc travelingman.TravelService.VerifyPlan
+     */
+    verifyPlan: {
+      name: "VerifyPlan",
+      I: VerifyPlanRequest,
+      O: VerifyPlanResponse,
+      kind: MethodKind.Unary,
+    },
+    /**
+     * @generated from rpc travelingman.TravelService.GetTripGraph
+     */
+    getTripGraph: {
+      name: "GetTripGraph",
+      I: GetTripGraphRequest,
+      O: GetTripGraphResponse,
+      kind: MethodKind.Unary,
+    },
+    /**
+     * @generated from rpc travelingman.TravelService.GetTripCalendar
+     */
+    getTripCalendar: {
+      name: "GetTripCalendar",
+      I: GetTripCalendarRequest,
+      O: GetTripCalendarResponse,
+      kind: MethodKind.Unary,
+      idempotency: MethodIdempotency.NoSideEffects,
+    },
+    /**
+     * @generated from rpc travelingman.TravelService.GetMoreOptions
+     */
+    getMoreOptions: {
+      name: "GetMoreOptions",
+      I: GetMoreOptionsRequest,
+      O: GetMoreOptionsResponse,
+      kind: MethodKind.Unary,
+      idempotency: MethodIdempotency.NoSideEffects,
+    },
+    /**
+     * @generated from rpc travelingman.TravelService.AutocompleteLocations
+     */
+    autocompleteLocations: {
+      name: "AutocompleteLocations",
+      I: AutocompleteLocationsRequest,
+      O: AutocompleteLocationsResponse,
+      kind: MethodKind.Unary,
+    },
+    /**
+     * @generated from rpc travelingman.TravelService.BookFlight
+     */
+    bookFlight: {
+      name: "BookFlight",
+      I: BookFlightRequest,
+      O: BookFlightResponse,
+      kind: MethodKind.Unary,
+    },
+    /**
+     * @generated from rpc travelingman.TravelService.RefreshBookingStatus
+     */
+    refreshBookingStatus: {
+      name: "RefreshBookingStatus",
+      I: RefreshBookingStatusRequest,
+      O: RefreshBookingStatusResponse,
+      kind: MethodKind.Unary,
+    },
+    /**
+     * @generated from rpc travelingman.TravelService.GetBookingSplits
+     */
+    getBookingSplits: {
+      name: "GetBookingSplits",
+      I: GetBookingSplitsRequest,
+      O: GetBookingSplitsResponse,
+      kind: MethodKind.Unary,
+    },
+    /**
+     * @generated from rpc travelingman.TravelService.CancelBooking
+     */
+    cancelBooking: {
+      name: "CancelBooking",
+      I: CancelBookingRequest,
+      O: CancelBookingResponse,
+      kind: MethodKind.Unary,
+    },
+    /**
+     * @generated from rpc travelingman.TravelService.ResumeBooking
+     */
+    resumeBooking: {
+      name: "ResumeBooking",
+      I: ResumeBookingRequest,
+      O: ResumeBookingResponse,
+      kind: MethodKind.Unary,
+    },
+    /**
+     * @generated from rpc travelingman.TravelService.GetItinerary
+     */
+    getItinerary: {
+      name: "GetItinerary",
+      I: GetItineraryRequest,
+      O: GetItineraryResponse,
+      kind: MethodKind.Unary,
+      idempotency: MethodIdempotency.NoSideEffects,
+    },
   }
 } as const;
 
@@ -4,8 +4,103 @@
 // @ts-nocheck
 
 import type { BinaryReadOptions, FieldList, JsonReadOptions, JsonValue, PartialMessage, PlainMessage } from "@bufbuild/protobuf";
-import { Message, proto3 } from "@bufbuild/protobuf";
+import { Message, proto3, protoInt64, Timestamp } from "@bufbuild/protobuf";
 import { Itinerary } from "./graph_pb.js";
+import { Cost } from "./common_pb.js";
+import { BookingStatus, BookingStatusChange, FlightChange, Payment, PaymentSplit } from "./bookings_pb.js";
+import { Error, FareTrend, Location, PriceCalendar, Transport, TransportType } from "./itinerary_pb.js";
+
+/**
+ * @generated from enum travelingman.PlanStage
+ */
+export enum PlanStage {
+  /**
+   * @generated from enum value: PLAN_STAGE_UNSPECIFIED = 0;
+   */
+  UNSPECIFIED = 0,
+
+  /**
+   * The planner started a plan or a revision
+   *
+   * @generated from enum value: PLAN_STAGE_PLANNING = 1;
+   */
+  PLANNING = 1,
+
+  /**
+   * The planner ran a tool
+   *
+   * @generated from enum value: PLAN_STAGE_TOOL_CALL = 2;
+   */
+  TOOL_CALL = 2,
+
+  /**
+   * The planner proposed an itinerary
+   *
+   * @generated from enum value: PLAN_STAGE_PROPOSED = 3;
+   */
+  PROPOSED = 3,
+
+  /**
+   * Searches for an itinerary's options started
+   *
+   * @generated from enum value: PLAN_STAGE_VERIFICATION_STARTED = 4;
+   */
+  VERIFICATION_STARTED = 4,
+
+  /**
+   * Searches for an itinerary's options finished, whether it passed or not
+   *
+   * @generated from enum value: PLAN_STAGE_VERIFICATION_FINISHED = 5;
+   */
+  VERIFICATION_FINISHED = 5,
+}
+// Retrieve enum metadata with: proto3.getEnumType(PlanStage)
+proto3.util.setEnumType(PlanStage, "travelingman.PlanStage", [
+  { no: 0, name: "PLAN_STAGE_UNSPECIFIED" },
+  { no: 1, name: "PLAN_STAGE_PLANNING" },
+  { no: 2, name: "PLAN_STAGE_TOOL_CALL" },
+  { no: 3, name: "PLAN_STAGE_PROPOSED" },
+  { no: 4, name: "PLAN_STAGE_VERIFICATION_STARTED" },
+  { no: 5, name: "PLAN_STAGE_VERIFICATION_FINISHED" },
+]);
+
+/**
+ * @generated from enum travelingman.TripGraphNodeType
+ */
+export enum TripGraphNodeType {
+  /**
+   * @generated from enum value: TRIP_GRAPH_NODE_TYPE_UNSPECIFIED = 0;
+   */
+  UNSPECIFIED = 0,
+
+  /**
+   * Where the trip (or a day trip) starts
+   *
+   * @generated from enum value: TRIP_GRAPH_NODE_TYPE_ORIGIN = 1;
+   */
+  ORIGIN = 1,
+
+  /**
+   * Node with accommodation
+   *
+   * @generated from enum value: TRIP_GRAPH_NODE_TYPE_STAY = 2;
+   */
+  STAY = 2,
+
+  /**
+   * Any other place visited
+   *
+   * @generated from enum value: TRIP_GRAPH_NODE_TYPE_DESTINATION = 3;
+   */
+  DESTINATION = 3,
+}
+// Retrieve enum metadata with: proto3.getEnumType(TripGraphNodeType)
+proto3.util.setEnumType(TripGraphNodeType, "travelingman.TripGraphNodeType", [
+  { no: 0, name: "TRIP_GRAPH_NODE_TYPE_UNSPECIFIED" },
+  { no: 1, name: "TRIP_GRAPH_NODE_TYPE_ORIGIN" },
+  { no: 2, name: "TRIP_GRAPH_NODE_TYPE_STAY" },
+  { no: 3, name: "TRIP_GRAPH_NODE_TYPE_DESTINATION" },
+]);
 
 /**
  * @generated from message travelingman.PlanTripRequest
@@ -16,6 +111,13 @@ export class PlanTripRequest extends Message<PlanTripRequest> {
    */
   query = "";
 
+  /**
+   * Return unverified drafts fast; verify later with VerifyPlan
+   *
+   * @generated from field: bool quick = 2;
+   */
+  quick = false;
+
   constructor(data?: PartialMessage<PlanTripRequest>) {
     super();
     proto3.util.initPartial(data, this);
@@ -25,6 +127,7 @@ export class PlanTripRequest extends Message<PlanTripRequest> {
   static readonly typeName = "travelingman.PlanTripRequest";
   static readonly fields: FieldList = proto3.util.newFieldList(() => [
     { no: 1, name: "query", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "quick", kind: "scalar", T: 8 /* ScalarType.BOOL */ },
   ]);
 
   static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): PlanTripRequest {
@@ -53,6 +156,41 @@ export class PlanTripResponse extends Message<PlanTripResponse> {
    */
   itineraries: Itinerary[] = [];
 
+  /**
+   * Planner text streamed by PlanTripStream while planning; empty in the final message
+   *
+   * @generated from field: string text = 2;
+   */
+  text = "";
+
+  /**
+   * Header facts of the best itinerary; unset when there is none
+   *
+   * @generated from field: travelingman.TripSummary summary = 3;
+   */
+  summary?: TripSummary;
+
+  /**
+   * One itinerary streamed by PlanTripStream as soon as it passed verification, before scoring; unset in the final message
+   *
+   * @generated from field: travelingman.Itinerary verified = 4;
+   */
+  verified?: Itinerary;
+
+  /**
+   * One planning step streamed by PlanTripStream; unset in the final message
+   *
+   * @generated from field: travelingman.PlanProgress progress = 5;
+   */
+  progress?: PlanProgress;
+
+  /**
+   * Why no itinerary survived; set only in the final message
+   *
+   * @generated from field: travelingman.Error error = 6;
+   */
+  error?: Error;
+
   constructor(data?: PartialMessage<PlanTripResponse>) {
     super();
     proto3.util.initPartial(data, this);
@@ -62,6 +200,11 @@ export class PlanTripResponse extends Message<PlanTripResponse> {
   static readonly typeName = "travelingman.PlanTripResponse";
   static readonly fields: FieldList = proto3.util.newFieldList(() => [
     { no: 1, name: "itineraries", kind: "message", T: Itinerary, repeated: true },
+    { no: 2, name: "text", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "summary", kind: "message", T: TripSummary },
+    { no: 4, name: "verified", kind: "message", T: Itinerary },
+    { no: 5, name: "progress", kind: "message", T: PlanProgress },
+    { no: 6, name: "error", kind: "message", T: Error },
   ]);
 
   static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): PlanTripResponse {
@@ -81,3 +224,2172 @@ export class PlanTripResponse extends Message<PlanTripResponse> {
   }
 }
 
+/**
+ * PlanProgress is a step of planning, streamed so a client can show what is going on
+ *
+ * @generated from message travelingman.PlanProgress
+ */
+export class PlanProgress extends Message<PlanProgress> {
+  /**
+   * @generated from field: travelingman.PlanStage stage = 1;
+   */
+  stage = PlanStage.UNSPECIFIED;
+
+  /**
+   * The tool called or the itinerary's title; empty when planning starts
+   *
+   * @generated from field: string detail = 2;
+   */
+  detail = "";
+
+  constructor(data?: PartialMessage<PlanProgress>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.PlanProgress";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "stage", kind: "enum", T: proto3.getEnumType(PlanStage) },
+    { no: 2, name: "detail", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): PlanProgress {
+    return new PlanProgress().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): PlanProgress {
+    return new PlanProgress().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): PlanProgress {
+    return new PlanProgress().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: PlanProgress | PlainMessage<PlanProgress> | undefined, b: PlanProgress | PlainMessage<PlanProgress> | undefined): boolean {
+    return proto3.util.equals(PlanProgress, a, b);
+  }
+}
+
+/**
+ * TripSummary is a compact header for a planned trip, taken from its best itinerary
+ *
+ * @generated from message travelingman.TripSummary
+ */
+export class TripSummary extends Message<TripSummary> {
+  /**
+   * City of the first stay, or where the first leg arrives
+   *
+   * @generated from field: string destination = 1;
+   */
+  destination = "";
+
+  /**
+   * Departure of the first leg
+   *
+   * @generated from field: google.protobuf.Timestamp start_time = 2;
+   */
+  startTime?: Timestamp;
+
+  /**
+   * Arrival of the last leg, or the last check-out
+   *
+   * @generated from field: google.protobuf.Timestamp end_time = 3;
+   */
+  endTime?: Timestamp;
+
+  /**
+   * @generated from field: int32 travelers = 4;
+   */
+  travelers = 0;
+
+  /**
+   * Total of the selected options
+   *
+   * @generated from field: travelingman.Cost total = 5;
+   */
+  total?: Cost;
+
+  /**
+   * Itineraries in the response
+   *
+   * @generated from field: int32 option_count = 6;
+   */
+  optionCount = 0;
+
+  constructor(data?: PartialMessage<TripSummary>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.TripSummary";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "destination", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "start_time", kind: "message", T: Timestamp },
+    { no: 3, name: "end_time", kind: "message", T: Timestamp },
+    { no: 4, name: "travelers", kind: "scalar", T: 5 /* ScalarType.INT32 */ },
+    { no: 5, name: "total", kind: "message", T: Cost },
+    { no: 6, name: "option_count", kind: "scalar", T: 5 /* ScalarType.INT32 */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): TripSummary {
+    return new TripSummary().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): TripSummary {
+    return new TripSummary().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): TripSummary {
+    return new TripSummary().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: TripSummary | PlainMessage<TripSummary> | undefined, b: TripSummary | PlainMessage<TripSummary> | undefined): boolean {
+    return proto3.util.equals(TripSummary, a, b);
+  }
+}
+
+/**
+ * @generated from message travelingman.GetPriceCalendarRequest
+ */
+export class GetPriceCalendarRequest extends Message<GetPriceCalendarRequest> {
+  /**
+   * Origin IATA code
+   *
+   * @generated from field: string origin = 1;
+   */
+  origin = "";
+
+  /**
+   * Destination IATA code
+   *
+   * @generated from field: string destination = 2;
+   */
+  destination = "";
+
+  /**
+   * YYYY-MM
+   *
+   * @generated from field: string month = 3;
+   */
+  month = "";
+
+  /**
+   * @generated from field: int32 adults = 4;
+   */
+  adults = 0;
+
+  /**
+   * ISO 4217, defaults to USD
+   *
+   * @generated from field: string currency = 5;
+   */
+  currency = "";
+
+  constructor(data?: PartialMessage<GetPriceCalendarRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.GetPriceCalendarRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "origin", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "destination", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "month", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 4, name: "adults", kind: "scalar", T: 5 /* ScalarType.INT32 */ },
+    { no: 5, name: "currency", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): GetPriceCalendarRequest {
+    return new GetPriceCalendarRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): GetPriceCalendarRequest {
+    return new GetPriceCalendarRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): GetPriceCalendarRequest {
+    return new GetPriceCalendarRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: GetPriceCalendarRequest | PlainMessage<GetPriceCalendarRequest> | undefined, b: GetPriceCalendarRequest | PlainMessage<GetPriceCalendarRequest> | undefined): boolean {
+    return proto3.util.equals(GetPriceCalendarRequest, a, b);
+  }
+}
+
+/**
+ * @generated from message travelingman.GetPriceCalendarResponse
+ */
+export class GetPriceCalendarResponse extends Message<GetPriceCalendarResponse> {
+  /**
+   * @generated from field: travelingman.PriceCalendar calendar = 1;
+   */
+  calendar?: PriceCalendar;
+
+  constructor(data?: PartialMessage<GetPriceCalendarResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.GetPriceCalendarResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "calendar", kind: "message", T: PriceCalendar },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): GetPriceCalendarResponse {
+    return new GetPriceCalendarResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): GetPriceCalendarResponse {
+    return new GetPriceCalendarResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): GetPriceCalendarResponse {
+    return new GetPriceCalendarResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: GetPriceCalendarResponse | PlainMessage<GetPriceCalendarResponse> | undefined, b: GetPriceCalendarResponse | PlainMessage<GetPriceCalendarResponse> | undefined): boolean {
+    return proto3.util.equals(GetPriceCalendarResponse, a, b);
+  }
+}
+
+/**
+ * @generated from message travelingman.GetFareTrendRequest
+ */
+export class GetFareTrendRequest extends Message<GetFareTrendRequest> {
+  /**
+   * Origin IATA code
+   *
+   * @generated from field: string origin = 1;
+   */
+  origin = "";
+
+  /**
+   * Destination IATA code
+   *
+   * @generated from field: string destination = 2;
+   */
+  destination = "";
+
+  /**
+   * YYYY-MM-DD, first departure day considered
+   *
+   * @generated from field: string from_date = 3;
+   */
+  fromDate = "";
+
+  /**
+   * YYYY-MM-DD, last departure day considered
+   *
+   * @generated from field: string to_date = 4;
+   */
+  toDate = "";
+
+  /**
+   * @generated from field: int32 adults = 5;
+   */
+  adults = 0;
+
+  /**
+   * ISO 4217, defaults to USD
+   *
+   * @generated from field: string currency = 6;
+   */
+  currency = "";
+
+  constructor(data?: PartialMessage<GetFareTrendRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.GetFareTrendRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "origin", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "destination", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "from_date", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 4, name: "to_date", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 5, name: "adults", kind: "scalar", T: 5 /* ScalarType.INT32 */ },
+    { no: 6, name: "currency", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): GetFareTrendRequest {
+    return new GetFareTrendRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): GetFareTrendRequest {
+    return new GetFareTrendRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): GetFareTrendRequest {
+    return new GetFareTrendRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: GetFareTrendRequest | PlainMessage<GetFareTrendRequest> | undefined, b: GetFareTrendRequest | PlainMessage<GetFareTrendRequest> | undefined): boolean {
+    return proto3.util.equals(GetFareTrendRequest, a, b);
+  }
+}
+
+/**
+ * @generated from message travelingman.GetFareTrendResponse
+ */
+export class GetFareTrendResponse extends Message<GetFareTrendResponse> {
+  /**
+   * @generated from field: travelingman.FareTrend trend = 1;
+   */
+  trend?: FareTrend;
+
+  constructor(data?: PartialMessage<GetFareTrendResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.GetFareTrendResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "trend", kind: "message", T: FareTrend },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): GetFareTrendResponse {
+    return new GetFareTrendResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): GetFareTrendResponse {
+    return new GetFareTrendResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): GetFareTrendResponse {
+    return new GetFareTrendResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: GetFareTrendResponse | PlainMessage<GetFareTrendResponse> | undefined, b: GetFareTrendResponse | PlainMessage<GetFareTrendResponse> | undefined): boolean {
+    return proto3.util.equals(GetFareTrendResponse, a, b);
+  }
+}
+
+/**
+ * @generated from message travelingman.SaveTripRequest
+ */
+export class SaveTripRequest extends Message<SaveTripRequest> {
+  /**
+   * @generated from field: travelingman.Itinerary itinerary = 1;
+   */
+  itinerary?: Itinerary;
+
+  constructor(data?: PartialMessage<SaveTripRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.SaveTripRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "itinerary", kind: "message", T: Itinerary },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): SaveTripRequest {
+    return new SaveTripRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): SaveTripRequest {
+    return new SaveTripRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): SaveTripRequest {
+    return new SaveTripRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: SaveTripRequest | PlainMessage<SaveTripRequest> | undefined, b: SaveTripRequest | PlainMessage<SaveTripRequest> | undefined): boolean {
+    return proto3.util.equals(SaveTripRequest, a, b);
+  }
+}
+
+/**
+ * @generated from message travelingman.SaveTripResponse
+ */
+export class SaveTripResponse extends Message<SaveTripResponse> {
+  /**
+   * Saved itinerary with id and version set
+   *
+   * @generated from field: travelingman.Itinerary itinerary = 1;
+   */
+  itinerary?: Itinerary;
+
+  constructor(data?: PartialMessage<SaveTripResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.SaveTripResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "itinerary", kind: "message", T: Itinerary },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): SaveTripResponse {
+    return new SaveTripResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): SaveTripResponse {
+    return new SaveTripResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): SaveTripResponse {
+    return new SaveTripResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: SaveTripResponse | PlainMessage<SaveTripResponse> | undefined, b: SaveTripResponse | PlainMessage<SaveTripResponse> | undefined): boolean {
+    return proto3.util.equals(SaveTripResponse, a, b);
+  }
+}
+
+/**
+ * @generated from message travelingman.UpdateTripRequest
+ */
+export class UpdateTripRequest extends Message<UpdateTripRequest> {
+  /**
+   * Edited itinerary; id and version identify the saved trip
+   *
+   * @generated from field: travelingman.Itinerary itinerary = 1;
+   */
+  itinerary?: Itinerary;
+
+  constructor(data?: PartialMessage<UpdateTripRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.UpdateTripRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "itinerary", kind: "message", T: Itinerary },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): UpdateTripRequest {
+    return new UpdateTripRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): UpdateTripRequest {
+    return new UpdateTripRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): UpdateTripRequest {
+    return new UpdateTripRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: UpdateTripRequest | PlainMessage<UpdateTripRequest> | undefined, b: UpdateTripRequest | PlainMessage<UpdateTripRequest> | undefined): boolean {
+    return proto3.util.equals(UpdateTripRequest, a, b);
+  }
+}
+
+/**
+ * TripConflict describes an inconsistency introduced by an edit
+ *
+ * @generated from message travelingman.TripConflict
+ */
+export class TripConflict extends Message<TripConflict> {
+  /**
+   * Node ID or edge (from_id->to_id) the conflict relates to
+   *
+   * @generated from field: string component_id = 1;
+   */
+  componentId = "";
+
+  /**
+   * @generated from field: string message = 2;
+   */
+  message = "";
+
+  /**
+   * @generated from field: string suggestion = 3;
+   */
+  suggestion = "";
+
+  constructor(data?: PartialMessage<TripConflict>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.TripConflict";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "component_id", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "message", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "suggestion", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): TripConflict {
+    return new TripConflict().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): TripConflict {
+    return new TripConflict().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): TripConflict {
+    return new TripConflict().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: TripConflict | PlainMessage<TripConflict> | undefined, b: TripConflict | PlainMessage<TripConflict> | undefined): boolean {
+    return proto3.util.equals(TripConflict, a, b);
+  }
+}
+
+/**
+ * @generated from message travelingman.UpdateTripResponse
+ */
+export class UpdateTripResponse extends Message<UpdateTripResponse> {
+  /**
+   * Accepted, re-scored itinerary (unset when there are conflicts)
+   *
+   * @generated from field: travelingman.Itinerary itinerary = 1;
+   */
+  itinerary?: Itinerary;
+
+  /**
+   * @generated from field: repeated travelingman.TripConflict conflicts = 2;
+   */
+  conflicts: TripConflict[] = [];
+
+  constructor(data?: PartialMessage<UpdateTripResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.UpdateTripResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "itinerary", kind: "message", T: Itinerary },
+    { no: 2, name: "conflicts", kind: "message", T: TripConflict, repeated: true },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): UpdateTripResponse {
+    return new UpdateTripResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): UpdateTripResponse {
+    return new UpdateTripResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): UpdateTripResponse {
+    return new UpdateTripResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: UpdateTripResponse | PlainMessage<UpdateTripResponse> | undefined, b: UpdateTripResponse | PlainMessage<UpdateTripResponse> | undefined): boolean {
+    return proto3.util.equals(UpdateTripResponse, a, b);
+  }
+}
+
+/**
+ * @generated from message travelingman.VerifyPlanRequest
+ */
+export class VerifyPlanRequest extends Message<VerifyPlanRequest> {
+  /**
+   * ID of a saved (quick mode) plan
+   *
+   * @generated from field: int64 plan_id = 1;
+   */
+  planId = protoInt64.zero;
+
+  constructor(data?: PartialMessage<VerifyPlanRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.VerifyPlanRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "plan_id", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): VerifyPlanRequest {
+    return new VerifyPlanRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): VerifyPlanRequest {
+    return new VerifyPlanRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): VerifyPlanRequest {
+    return new VerifyPlanRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: VerifyPlanRequest | PlainMessage<VerifyPlanRequest> | undefined, b: VerifyPlanRequest | PlainMessage<VerifyPlanRequest> | undefined): boolean {
+    return proto3.util.equals(VerifyPlanRequest, a, b);
+  }
+}
+
+/**
+ * @generated from message travelingman.VerifyPlanResponse
+ */
+export class VerifyPlanResponse extends Message<VerifyPlanResponse> {
+  /**
+   * Verified, scored plan (saved with a new version)
+   *
+   * @generated from field: travelingman.Itinerary itinerary = 1;
+   */
+  itinerary?: Itinerary;
+
+  constructor(data?: PartialMessage<VerifyPlanResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.VerifyPlanResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "itinerary", kind: "message", T: Itinerary },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): VerifyPlanResponse {
+    return new VerifyPlanResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): VerifyPlanResponse {
+    return new VerifyPlanResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): VerifyPlanResponse {
+    return new VerifyPlanResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: VerifyPlanResponse | PlainMessage<VerifyPlanResponse> | undefined, b: VerifyPlanResponse | PlainMessage<VerifyPlanResponse> | undefined): boolean {
+    return proto3.util.equals(VerifyPlanResponse, a, b);
+  }
+}
+
+/**
+ * GetItineraryRequest fetches a saved plan. It is served over HTTP GET with an ETag,
+ * so unchanged plans can be revalidated with If-None-Match.
+ *
+ * @generated from message travelingman.GetItineraryRequest
+ */
+export class GetItineraryRequest extends Message<GetItineraryRequest> {
+  /**
+   * ID of a saved plan
+   *
+   * @generated from field: int64 plan_id = 1;
+   */
+  planId = protoInt64.zero;
+
+  constructor(data?: PartialMessage<GetItineraryRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.GetItineraryRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "plan_id", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): GetItineraryRequest {
+    return new GetItineraryRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): GetItineraryRequest {
+    return new GetItineraryRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): GetItineraryRequest {
+    return new GetItineraryRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: GetItineraryRequest | PlainMessage<GetItineraryRequest> | undefined, b: GetItineraryRequest | PlainMessage<GetItineraryRequest> | undefined): boolean {
+    return proto3.util.equals(GetItineraryRequest, a, b);
+  }
+}
+
+/**
+ * @generated from message travelingman.GetItineraryResponse
+ */
+export class GetItineraryResponse extends Message<GetItineraryResponse> {
+  /**
+   * @generated from field: travelingman.Itinerary itinerary = 1;
+   */
+  itinerary?: Itinerary;
+
+  constructor(data?: PartialMessage<GetItineraryResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.GetItineraryResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "itinerary", kind: "message", T: Itinerary },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): GetItineraryResponse {
+    return new GetItineraryResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): GetItineraryResponse {
+    return new GetItineraryResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): GetItineraryResponse {
+    return new GetItineraryResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: GetItineraryResponse | PlainMessage<GetItineraryResponse> | undefined, b: GetItineraryResponse | PlainMessage<GetItineraryResponse> | undefined): boolean {
+    return proto3.util.equals(GetItineraryResponse, a, b);
+  }
+}
+
+/**
+ * @generated from message travelingman.GetTripGraphRequest
+ */
+export class GetTripGraphRequest extends Message<GetTripGraphRequest> {
+  /**
+   * ID of a saved plan
+   *
+   * @generated from field: int64 plan_id = 1;
+   */
+  planId = protoInt64.zero;
+
+  /**
+   * Inline itinerary, used when plan_id is unset
+   *
+   * @generated from field: travelingman.Itinerary itinerary = 2;
+   */
+  itinerary?: Itinerary;
+
+  constructor(data?: PartialMessage<GetTripGraphRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.GetTripGraphRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "plan_id", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 2, name: "itinerary", kind: "message", T: Itinerary },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): GetTripGraphRequest {
+    return new GetTripGraphRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): GetTripGraphRequest {
+    return new GetTripGraphRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): GetTripGraphRequest {
+    return new GetTripGraphRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: GetTripGraphRequest | PlainMessage<GetTripGraphRequest> | undefined, b: GetTripGraphRequest | PlainMessage<GetTripGraphRequest> | undefined): boolean {
+    return proto3.util.equals(GetTripGraphRequest, a, b);
+  }
+}
+
+/**
+ * @generated from message travelingman.GetTripGraphResponse
+ */
+export class GetTripGraphResponse extends Message<GetTripGraphResponse> {
+  /**
+   * @generated from field: travelingman.TripGraph graph = 1;
+   */
+  graph?: TripGraph;
+
+  constructor(data?: PartialMessage<GetTripGraphResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.GetTripGraphResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "graph", kind: "message", T: TripGraph },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): GetTripGraphResponse {
+    return new GetTripGraphResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): GetTripGraphResponse {
+    return new GetTripGraphResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): GetTripGraphResponse {
+    return new GetTripGraphResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: GetTripGraphResponse | PlainMessage<GetTripGraphResponse> | undefined, b: GetTripGraphResponse | PlainMessage<GetTripGraphResponse> | undefined): boolean {
+    return proto3.util.equals(GetTripGraphResponse, a, b);
+  }
+}
+
+/**
+ * @generated from message travelingman.GetTripCalendarRequest
+ */
+export class GetTripCalendarRequest extends Message<GetTripCalendarRequest> {
+  /**
+   * ID of a saved plan
+   *
+   * @generated from field: int64 plan_id = 1;
+   */
+  planId = protoInt64.zero;
+
+  constructor(data?: PartialMessage<GetTripCalendarRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.GetTripCalendarRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "plan_id", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): GetTripCalendarRequest {
+    return new GetTripCalendarRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): GetTripCalendarRequest {
+    return new GetTripCalendarRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): GetTripCalendarRequest {
+    return new GetTripCalendarRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: GetTripCalendarRequest | PlainMessage<GetTripCalendarRequest> | undefined, b: GetTripCalendarRequest | PlainMessage<GetTripCalendarRequest> | undefined): boolean {
+    return proto3.util.equals(GetTripCalendarRequest, a, b);
+  }
+}
+
+/**
+ * @generated from message travelingman.GetTripCalendarResponse
+ */
+export class GetTripCalendarResponse extends Message<GetTripCalendarResponse> {
+  /**
+   * @generated from field: travelingman.TripCalendar calendar = 1;
+   */
+  calendar?: TripCalendar;
+
+  constructor(data?: PartialMessage<GetTripCalendarResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.GetTripCalendarResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "calendar", kind: "message", T: TripCalendar },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): GetTripCalendarResponse {
+    return new GetTripCalendarResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): GetTripCalendarResponse {
+    return new GetTripCalendarResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): GetTripCalendarResponse {
+    return new GetTripCalendarResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: GetTripCalendarResponse | PlainMessage<GetTripCalendarResponse> | undefined, b: GetTripCalendarResponse | PlainMessage<GetTripCalendarResponse> | undefined): boolean {
+    return proto3.util.equals(GetTripCalendarResponse, a, b);
+  }
+}
+
+/**
+ * GetMoreOptionsRequest fetches the transport options collapsed behind a shown one
+ *
+ * @generated from message travelingman.GetMoreOptionsRequest
+ */
+export class GetMoreOptionsRequest extends Message<GetMoreOptionsRequest> {
+  /**
+   * ID of a saved plan
+   *
+   * @generated from field: int64 plan_id = 1;
+   */
+  planId = protoInt64.zero;
+
+  /**
+   * "fromID->toID"
+   *
+   * @generated from field: string edge = 2;
+   */
+  edge = "";
+
+  /**
+   * One of the edge's visible option IDs
+   *
+   * @generated from field: string option_id = 3;
+   */
+  optionId = "";
+
+  constructor(data?: PartialMessage<GetMoreOptionsRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.GetMoreOptionsRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "plan_id", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 2, name: "edge", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "option_id", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): GetMoreOptionsRequest {
+    return new GetMoreOptionsRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): GetMoreOptionsRequest {
+    return new GetMoreOptionsRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): GetMoreOptionsRequest {
+    return new GetMoreOptionsRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: GetMoreOptionsRequest | PlainMessage<GetMoreOptionsRequest> | undefined, b: GetMoreOptionsRequest | PlainMessage<GetMoreOptionsRequest> | undefined): boolean {
+    return proto3.util.equals(GetMoreOptionsRequest, a, b);
+  }
+}
+
+/**
+ * @generated from message travelingman.GetMoreOptionsResponse
+ */
+export class GetMoreOptionsResponse extends Message<GetMoreOptionsResponse> {
+  /**
+   * In the order of the group's collapsed_ids
+   *
+   * @generated from field: repeated travelingman.Transport options = 1;
+   */
+  options: Transport[] = [];
+
+  constructor(data?: PartialMessage<GetMoreOptionsResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.GetMoreOptionsResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "options", kind: "message", T: Transport, repeated: true },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): GetMoreOptionsResponse {
+    return new GetMoreOptionsResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): GetMoreOptionsResponse {
+    return new GetMoreOptionsResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): GetMoreOptionsResponse {
+    return new GetMoreOptionsResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: GetMoreOptionsResponse | PlainMessage<GetMoreOptionsResponse> | undefined, b: GetMoreOptionsResponse | PlainMessage<GetMoreOptionsResponse> | undefined): boolean {
+    return proto3.util.equals(GetMoreOptionsResponse, a, b);
+  }
+}
+
+/**
+ * AutocompleteLocationsRequest looks up cities and airports for a destination input.
+ * It is served from memory and never calls a provider.
+ *
+ * @generated from message travelingman.AutocompleteLocationsRequest
+ */
+export class AutocompleteLocationsRequest extends Message<AutocompleteLocationsRequest> {
+  /**
+   * City name, airport name or IATA code, or the start of one
+   *
+   * @generated from field: string query = 1;
+   */
+  query = "";
+
+  /**
+   * Max results, defaults to 10 (at most 50)
+   *
+   * @generated from field: int32 limit = 2;
+   */
+  limit = 0;
+
+  constructor(data?: PartialMessage<AutocompleteLocationsRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.AutocompleteLocationsRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "query", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "limit", kind: "scalar", T: 5 /* ScalarType.INT32 */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): AutocompleteLocationsRequest {
+    return new AutocompleteLocationsRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): AutocompleteLocationsRequest {
+    return new AutocompleteLocationsRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): AutocompleteLocationsRequest {
+    return new AutocompleteLocationsRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: AutocompleteLocationsRequest | PlainMessage<AutocompleteLocationsRequest> | undefined, b: AutocompleteLocationsRequest | PlainMessage<AutocompleteLocationsRequest> | undefined): boolean {
+    return proto3.util.equals(AutocompleteLocationsRequest, a, b);
+  }
+}
+
+/**
+ * @generated from message travelingman.AutocompleteLocationsResponse
+ */
+export class AutocompleteLocationsResponse extends Message<AutocompleteLocationsResponse> {
+  /**
+   * Best match first
+   *
+   * @generated from field: repeated travelingman.Location locations = 1;
+   */
+  locations: Location[] = [];
+
+  constructor(data?: PartialMessage<AutocompleteLocationsResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.AutocompleteLocationsResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "locations", kind: "message", T: Location, repeated: true },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): AutocompleteLocationsResponse {
+    return new AutocompleteLocationsResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): AutocompleteLocationsResponse {
+    return new AutocompleteLocationsResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): AutocompleteLocationsResponse {
+    return new AutocompleteLocationsResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: AutocompleteLocationsResponse | PlainMessage<AutocompleteLocationsResponse> | undefined, b: AutocompleteLocationsResponse | PlainMessage<AutocompleteLocationsResponse> | undefined): boolean {
+    return proto3.util.equals(AutocompleteLocationsResponse, a, b);
+  }
+}
+
+/**
+ * BookFlightRequest places an order for a flight offer and records how its cost is split
+ *
+ * @generated from message travelingman.BookFlightRequest
+ */
+export class BookFlightRequest extends Message<BookFlightRequest> {
+  /**
+   * The provider's flight offer, as returned by a search
+   *
+   * @generated from field: string offer_json = 1;
+   */
+  offerJson = "";
+
+  /**
+   * Users flying, in the offer's traveler order
+   *
+   * @generated from field: repeated int64 traveler_ids = 2;
+   */
+  travelerIds: bigint[] = [];
+
+  /**
+   * Optional: who owes what of the total
+   *
+   * @generated from field: travelingman.PaymentSplit split = 3;
+   */
+  split?: PaymentSplit;
+
+  constructor(data?: PartialMessage<BookFlightRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.BookFlightRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "offer_json", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "traveler_ids", kind: "scalar", T: 3 /* ScalarType.INT64 */, repeated: true },
+    { no: 3, name: "split", kind: "message", T: PaymentSplit },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): BookFlightRequest {
+    return new BookFlightRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): BookFlightRequest {
+    return new BookFlightRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): BookFlightRequest {
+    return new BookFlightRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: BookFlightRequest | PlainMessage<BookFlightRequest> | undefined, b: BookFlightRequest | PlainMessage<BookFlightRequest> | undefined): boolean {
+    return proto3.util.equals(BookFlightRequest, a, b);
+  }
+}
+
+/**
+ * @generated from message travelingman.BookFlightResponse
+ */
+export class BookFlightResponse extends Message<BookFlightResponse> {
+  /**
+   * @generated from field: int64 booking_id = 1;
+   */
+  bookingId = protoInt64.zero;
+
+  /**
+   * Provider order ID
+   *
+   * @generated from field: string order_id = 2;
+   */
+  orderId = "";
+
+  /**
+   * What each user owes, if the cost was split
+   *
+   * @generated from field: repeated travelingman.Payment shares = 3;
+   */
+  shares: Payment[] = [];
+
+  /**
+   * As emailed to the travelers
+   *
+   * @generated from field: string summary = 4;
+   */
+  summary = "";
+
+  constructor(data?: PartialMessage<BookFlightResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.BookFlightResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "booking_id", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 2, name: "order_id", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "shares", kind: "message", T: Payment, repeated: true },
+    { no: 4, name: "summary", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): BookFlightResponse {
+    return new BookFlightResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): BookFlightResponse {
+    return new BookFlightResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): BookFlightResponse {
+    return new BookFlightResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: BookFlightResponse | PlainMessage<BookFlightResponse> | undefined, b: BookFlightResponse | PlainMessage<BookFlightResponse> | undefined): boolean {
+    return proto3.util.equals(BookFlightResponse, a, b);
+  }
+}
+
+/**
+ * @generated from message travelingman.RefreshBookingStatusRequest
+ */
+export class RefreshBookingStatusRequest extends Message<RefreshBookingStatusRequest> {
+  /**
+   * @generated from field: int64 booking_id = 1;
+   */
+  bookingId = protoInt64.zero;
+
+  constructor(data?: PartialMessage<RefreshBookingStatusRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.RefreshBookingStatusRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "booking_id", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): RefreshBookingStatusRequest {
+    return new RefreshBookingStatusRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): RefreshBookingStatusRequest {
+    return new RefreshBookingStatusRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): RefreshBookingStatusRequest {
+    return new RefreshBookingStatusRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: RefreshBookingStatusRequest | PlainMessage<RefreshBookingStatusRequest> | undefined, b: RefreshBookingStatusRequest | PlainMessage<RefreshBookingStatusRequest> | undefined): boolean {
+    return proto3.util.equals(RefreshBookingStatusRequest, a, b);
+  }
+}
+
+/**
+ * @generated from message travelingman.RefreshBookingStatusResponse
+ */
+export class RefreshBookingStatusResponse extends Message<RefreshBookingStatusResponse> {
+  /**
+   * Status after the refresh
+   *
+   * @generated from field: travelingman.BookingStatus status = 1;
+   */
+  status = BookingStatus.UNSPECIFIED;
+
+  /**
+   * Schedule changes found by this refresh
+   *
+   * @generated from field: repeated travelingman.FlightChange changes = 2;
+   */
+  changes: FlightChange[] = [];
+
+  /**
+   * All transitions, oldest first
+   *
+   * @generated from field: repeated travelingman.BookingStatusChange history = 3;
+   */
+  history: BookingStatusChange[] = [];
+
+  constructor(data?: PartialMessage<RefreshBookingStatusResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.RefreshBookingStatusResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "status", kind: "enum", T: proto3.getEnumType(BookingStatus) },
+    { no: 2, name: "changes", kind: "message", T: FlightChange, repeated: true },
+    { no: 3, name: "history", kind: "message", T: BookingStatusChange, repeated: true },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): RefreshBookingStatusResponse {
+    return new RefreshBookingStatusResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): RefreshBookingStatusResponse {
+    return new RefreshBookingStatusResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): RefreshBookingStatusResponse {
+    return new RefreshBookingStatusResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: RefreshBookingStatusResponse | PlainMessage<RefreshBookingStatusResponse> | undefined, b: RefreshBookingStatusResponse | PlainMessage<RefreshBookingStatusResponse> | undefined): boolean {
+    return proto3.util.equals(RefreshBookingStatusResponse, a, b);
+  }
+}
+
+/**
+ * @generated from message travelingman.GetBookingSplitsRequest
+ */
+export class GetBookingSplitsRequest extends Message<GetBookingSplitsRequest> {
+  /**
+   * @generated from field: int64 booking_id = 1;
+   */
+  bookingId = protoInt64.zero;
+
+  constructor(data?: PartialMessage<GetBookingSplitsRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.GetBookingSplitsRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "booking_id", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): GetBookingSplitsRequest {
+    return new GetBookingSplitsRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): GetBookingSplitsRequest {
+    return new GetBookingSplitsRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): GetBookingSplitsRequest {
+    return new GetBookingSplitsRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: GetBookingSplitsRequest | PlainMessage<GetBookingSplitsRequest> | undefined, b: GetBookingSplitsRequest | PlainMessage<GetBookingSplitsRequest> | undefined): boolean {
+    return proto3.util.equals(GetBookingSplitsRequest, a, b);
+  }
+}
+
+/**
+ * @generated from message travelingman.GetBookingSplitsResponse
+ */
+export class GetBookingSplitsResponse extends Message<GetBookingSplitsResponse> {
+  /**
+   * What each user owes, in split order
+   *
+   * @generated from field: repeated travelingman.Payment shares = 1;
+   */
+  shares: Payment[] = [];
+
+  /**
+   * Sum of the shares
+   *
+   * @generated from field: string total = 2;
+   */
+  total = "";
+
+  /**
+   * @generated from field: string currency = 3;
+   */
+  currency = "";
+
+  constructor(data?: PartialMessage<GetBookingSplitsResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.GetBookingSplitsResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "shares", kind: "message", T: Payment, repeated: true },
+    { no: 2, name: "total", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "currency", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): GetBookingSplitsResponse {
+    return new GetBookingSplitsResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): GetBookingSplitsResponse {
+    return new GetBookingSplitsResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): GetBookingSplitsResponse {
+    return new GetBookingSplitsResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: GetBookingSplitsResponse | PlainMessage<GetBookingSplitsResponse> | undefined, b: GetBookingSplitsResponse | PlainMessage<GetBookingSplitsResponse> | undefined): boolean {
+    return proto3.util.equals(GetBookingSplitsResponse, a, b);
+  }
+}
+
+/**
+ * CancelBookingRequest cancels the provider orders of a saved plan
+ *
+ * @generated from message travelingman.CancelBookingRequest
+ */
+export class CancelBookingRequest extends Message<CancelBookingRequest> {
+  /**
+   * ID of a saved plan
+   *
+   * @generated from field: int64 plan_id = 1;
+   */
+  planId = protoInt64.zero;
+
+  constructor(data?: PartialMessage<CancelBookingRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.CancelBookingRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "plan_id", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): CancelBookingRequest {
+    return new CancelBookingRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): CancelBookingRequest {
+    return new CancelBookingRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): CancelBookingRequest {
+    return new CancelBookingRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: CancelBookingRequest | PlainMessage<CancelBookingRequest> | undefined, b: CancelBookingRequest | PlainMessage<CancelBookingRequest> | undefined): boolean {
+    return proto3.util.equals(CancelBookingRequest, a, b);
+  }
+}
+
+/**
+ * @generated from message travelingman.CancelBookingResponse
+ */
+export class CancelBookingResponse extends Message<CancelBookingResponse> {
+  /**
+   * Saved plan with its cancelled segments marked CANCELLED
+   *
+   * @generated from field: travelingman.Itinerary itinerary = 1;
+   */
+  itinerary?: Itinerary;
+
+  /**
+   * Segments still booked; cancelling again retries them
+   *
+   * @generated from field: repeated travelingman.TripConflict failures = 2;
+   */
+  failures: TripConflict[] = [];
+
+  constructor(data?: PartialMessage<CancelBookingResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.CancelBookingResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "itinerary", kind: "message", T: Itinerary },
+    { no: 2, name: "failures", kind: "message", T: TripConflict, repeated: true },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): CancelBookingResponse {
+    return new CancelBookingResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): CancelBookingResponse {
+    return new CancelBookingResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): CancelBookingResponse {
+    return new CancelBookingResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: CancelBookingResponse | PlainMessage<CancelBookingResponse> | undefined, b: CancelBookingResponse | PlainMessage<CancelBookingResponse> | undefined): boolean {
+    return proto3.util.equals(CancelBookingResponse, a, b);
+  }
+}
+
+/**
+ * ResumeBookingRequest books the segments of a saved plan that are not booked yet
+ *
+ * @generated from message travelingman.ResumeBookingRequest
+ */
+export class ResumeBookingRequest extends Message<ResumeBookingRequest> {
+  /**
+   * ID of a saved plan
+   *
+   * @generated from field: int64 plan_id = 1;
+   */
+  planId = protoInt64.zero;
+
+  /**
+   * Users travelling, in the offers' traveler order
+   *
+   * @generated from field: repeated int64 traveler_ids = 2;
+   */
+  travelerIds: bigint[] = [];
+
+  /**
+   * Provider flight offers as JSON, keyed by "fromID->toID"
+   *
+   * @generated from field: map<string, string> flight_offers = 3;
+   */
+  flightOffers: { [key: string]: string } = {};
+
+  /**
+   * Provider hotel offer IDs, keyed by node ID
+   *
+   * @generated from field: map<string, string> hotel_offers = 4;
+   */
+  hotelOffers: { [key: string]: string } = {};
+
+  /**
+   * Card paying for the stays; required with hotel_offers
+   *
+   * @generated from field: string card_number = 5;
+   */
+  cardNumber = "";
+
+  /**
+   * YYYY-MM
+   *
+   * @generated from field: string card_expiry = 6;
+   */
+  cardExpiry = "";
+
+  constructor(data?: PartialMessage<ResumeBookingRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.ResumeBookingRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "plan_id", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 2, name: "traveler_ids", kind: "scalar", T: 3 /* ScalarType.INT64 */, repeated: true },
+    { no: 3, name: "flight_offers", kind: "map", K: 9 /* ScalarType.STRING */, V: {kind: "scalar", T: 9 /* ScalarType.STRING */} },
+    { no: 4, name: "hotel_offers", kind: "map", K: 9 /* ScalarType.STRING */, V: {kind: "scalar", T: 9 /* ScalarType.STRING */} },
+    { no: 5, name: "card_number", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 6, name: "card_expiry", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): ResumeBookingRequest {
+    return new ResumeBookingRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): ResumeBookingRequest {
+    return new ResumeBookingRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): ResumeBookingRequest {
+    return new ResumeBookingRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: ResumeBookingRequest | PlainMessage<ResumeBookingRequest> | undefined, b: ResumeBookingRequest | PlainMessage<ResumeBookingRequest> | undefined): boolean {
+    return proto3.util.equals(ResumeBookingRequest, a, b);
+  }
+}
+
+/**
+ * @generated from message travelingman.ResumeBookingResponse
+ */
+export class ResumeBookingResponse extends Message<ResumeBookingResponse> {
+  /**
+   * Saved plan with the references of what is booked
+   *
+   * @generated from field: travelingman.Itinerary itinerary = 1;
+   */
+  itinerary?: Itinerary;
+
+  /**
+   * Segments booked by this call
+   *
+   * @generated from field: int32 booked = 2;
+   */
+  booked = 0;
+
+  constructor(data?: PartialMessage<ResumeBookingResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.ResumeBookingResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "itinerary", kind: "message", T: Itinerary },
+    { no: 2, name: "booked", kind: "scalar", T: 5 /* ScalarType.INT32 */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): ResumeBookingResponse {
+    return new ResumeBookingResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): ResumeBookingResponse {
+    return new ResumeBookingResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): ResumeBookingResponse {
+    return new ResumeBookingResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: ResumeBookingResponse | PlainMessage<ResumeBookingResponse> | undefined, b: ResumeBookingResponse | PlainMessage<ResumeBookingResponse> | undefined): boolean {
+    return proto3.util.equals(ResumeBookingResponse, a, b);
+  }
+}
+
+/**
+ * TripGraph is an itinerary graph prepared for drawing on a map
+ *
+ * @generated from message travelingman.TripGraph
+ */
+export class TripGraph extends Message<TripGraph> {
+  /**
+   * In visiting order
+   *
+   * @generated from field: repeated travelingman.TripGraphNode nodes = 1;
+   */
+  nodes: TripGraphNode[] = [];
+
+  /**
+   * In departure order
+   *
+   * @generated from field: repeated travelingman.TripGraphEdge edges = 2;
+   */
+  edges: TripGraphEdge[] = [];
+
+  /**
+   * Sub-graphs, e.g. day trips from a node
+   *
+   * @generated from field: repeated travelingman.TripGraphGroup groups = 3;
+   */
+  groups: TripGraphGroup[] = [];
+
+  /**
+   * Parts left out of the map; only set on the top-level graph
+   *
+   * @generated from field: repeated string warnings = 4;
+   */
+  warnings: string[] = [];
+
+  constructor(data?: PartialMessage<TripGraph>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.TripGraph";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "nodes", kind: "message", T: TripGraphNode, repeated: true },
+    { no: 2, name: "edges", kind: "message", T: TripGraphEdge, repeated: true },
+    { no: 3, name: "groups", kind: "message", T: TripGraphGroup, repeated: true },
+    { no: 4, name: "warnings", kind: "scalar", T: 9 /* ScalarType.STRING */, repeated: true },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): TripGraph {
+    return new TripGraph().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): TripGraph {
+    return new TripGraph().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): TripGraph {
+    return new TripGraph().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: TripGraph | PlainMessage<TripGraph> | undefined, b: TripGraph | PlainMessage<TripGraph> | undefined): boolean {
+    return proto3.util.equals(TripGraph, a, b);
+  }
+}
+
+/**
+ * @generated from message travelingman.LatLng
+ */
+export class LatLng extends Message<LatLng> {
+  /**
+   * @generated from field: double lat = 1;
+   */
+  lat = 0;
+
+  /**
+   * @generated from field: double lng = 2;
+   */
+  lng = 0;
+
+  constructor(data?: PartialMessage<LatLng>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.LatLng";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "lat", kind: "scalar", T: 1 /* ScalarType.DOUBLE */ },
+    { no: 2, name: "lng", kind: "scalar", T: 1 /* ScalarType.DOUBLE */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): LatLng {
+    return new LatLng().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): LatLng {
+    return new LatLng().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): LatLng {
+    return new LatLng().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: LatLng | PlainMessage<LatLng> | undefined, b: LatLng | PlainMessage<LatLng> | undefined): boolean {
+    return proto3.util.equals(LatLng, a, b);
+  }
+}
+
+/**
+ * @generated from message travelingman.TripGraphNode
+ */
+export class TripGraphNode extends Message<TripGraphNode> {
+  /**
+   * @generated from field: string id = 1;
+   */
+  id = "";
+
+  /**
+   * @generated from field: travelingman.LatLng position = 2;
+   */
+  position?: LatLng;
+
+  /**
+   * City or name of the location
+   *
+   * @generated from field: string label = 3;
+   */
+  label = "";
+
+  /**
+   * @generated from field: travelingman.TripGraphNodeType type = 4;
+   */
+  type = TripGraphNodeType.UNSPECIFIED;
+
+  /**
+   * Arrival, or check-in for stays
+   *
+   * @generated from field: google.protobuf.Timestamp start_time = 5;
+   */
+  startTime?: Timestamp;
+
+  /**
+   * Departure, or check-out for stays
+   *
+   * @generated from field: google.protobuf.Timestamp end_time = 6;
+   */
+  endTime?: Timestamp;
+
+  /**
+   * The user's notes for the node
+   *
+   * @generated from field: string notes = 7;
+   */
+  notes = "";
+
+  constructor(data?: PartialMessage<TripGraphNode>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.TripGraphNode";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "id", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "position", kind: "message", T: LatLng },
+    { no: 3, name: "label", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 4, name: "type", kind: "enum", T: proto3.getEnumType(TripGraphNodeType) },
+    { no: 5, name: "start_time", kind: "message", T: Timestamp },
+    { no: 6, name: "end_time", kind: "message", T: Timestamp },
+    { no: 7, name: "notes", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): TripGraphNode {
+    return new TripGraphNode().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): TripGraphNode {
+    return new TripGraphNode().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): TripGraphNode {
+    return new TripGraphNode().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: TripGraphNode | PlainMessage<TripGraphNode> | undefined, b: TripGraphNode | PlainMessage<TripGraphNode> | undefined): boolean {
+    return proto3.util.equals(TripGraphNode, a, b);
+  }
+}
+
+/**
+ * @generated from message travelingman.TripGraphEdge
+ */
+export class TripGraphEdge extends Message<TripGraphEdge> {
+  /**
+   * @generated from field: string from_id = 1;
+   */
+  fromId = "";
+
+  /**
+   * @generated from field: string to_id = 2;
+   */
+  toId = "";
+
+  /**
+   * @generated from field: travelingman.TransportType mode = 3;
+   */
+  mode = TransportType.UNSPECIFIED;
+
+  /**
+   * Great-circle points from the from node to the to node
+   *
+   * @generated from field: repeated travelingman.LatLng polyline = 4;
+   */
+  polyline: LatLng[] = [];
+
+  /**
+   * @generated from field: int64 duration_seconds = 5;
+   */
+  durationSeconds = protoInt64.zero;
+
+  /**
+   * Selected option, e.g. "Flight BA 117 LHR-CDG, 120.00 EUR"
+   *
+   * @generated from field: string summary = 6;
+   */
+  summary = "";
+
+  constructor(data?: PartialMessage<TripGraphEdge>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.TripGraphEdge";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "from_id", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "to_id", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "mode", kind: "enum", T: proto3.getEnumType(TransportType) },
+    { no: 4, name: "polyline", kind: "message", T: LatLng, repeated: true },
+    { no: 5, name: "duration_seconds", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 6, name: "summary", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): TripGraphEdge {
+    return new TripGraphEdge().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): TripGraphEdge {
+    return new TripGraphEdge().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): TripGraphEdge {
+    return new TripGraphEdge().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: TripGraphEdge | PlainMessage<TripGraphEdge> | undefined, b: TripGraphEdge | PlainMessage<TripGraphEdge> | undefined): boolean {
+    return proto3.util.equals(TripGraphEdge, a, b);
+  }
+}
+
+/**
+ * TripGraphGroup is a sub-graph drawn inside its parent node
+ *
+ * @generated from message travelingman.TripGraphGroup
+ */
+export class TripGraphGroup extends Message<TripGraphGroup> {
+  /**
+   * Node the sub-graph belongs to; empty for a graph's intra-city details
+   *
+   * @generated from field: string node_id = 1;
+   */
+  nodeId = "";
+
+  /**
+   * @generated from field: travelingman.TripGraph graph = 2;
+   */
+  graph?: TripGraph;
+
+  constructor(data?: PartialMessage<TripGraphGroup>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.TripGraphGroup";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "node_id", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "graph", kind: "message", T: TripGraph },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): TripGraphGroup {
+    return new TripGraphGroup().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): TripGraphGroup {
+    return new TripGraphGroup().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): TripGraphGroup {
+    return new TripGraphGroup().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: TripGraphGroup | PlainMessage<TripGraphGroup> | undefined, b: TripGraphGroup | PlainMessage<TripGraphGroup> | undefined): boolean {
+    return proto3.util.equals(TripGraphGroup, a, b);
+  }
+}
+
+/**
+ * TripCalendar is a saved plan laid out day by day for the calendar view
+ *
+ * @generated from message travelingman.TripCalendar
+ */
+export class TripCalendar extends Message<TripCalendar> {
+  /**
+   * First place stayed at, or where the trip ends
+   *
+   * @generated from field: string destination = 1;
+   */
+  destination = "";
+
+  /**
+   * In date order
+   *
+   * @generated from field: repeated travelingman.TripDay days = 2;
+   */
+  days: TripDay[] = [];
+
+  /**
+   * In the plan's order, sub-graphs last
+   *
+   * @generated from field: repeated travelingman.TripLeg legs = 3;
+   */
+  legs: TripLeg[] = [];
+
+  constructor(data?: PartialMessage<TripCalendar>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.TripCalendar";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "destination", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "days", kind: "message", T: TripDay, repeated: true },
+    { no: 3, name: "legs", kind: "message", T: TripLeg, repeated: true },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): TripCalendar {
+    return new TripCalendar().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): TripCalendar {
+    return new TripCalendar().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): TripCalendar {
+    return new TripCalendar().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: TripCalendar | PlainMessage<TripCalendar> | undefined, b: TripCalendar | PlainMessage<TripCalendar> | undefined): boolean {
+    return proto3.util.equals(TripCalendar, a, b);
+  }
+}
+
+/**
+ * TripDay is one calendar day of a plan
+ *
+ * @generated from message travelingman.TripDay
+ */
+export class TripDay extends Message<TripDay> {
+  /**
+   * 1 for the first day
+   *
+   * @generated from field: int32 day_number = 1;
+   */
+  dayNumber = 0;
+
+  /**
+   * Midnight UTC of the day
+   *
+   * @generated from field: google.protobuf.Timestamp date = 2;
+   */
+  date?: Timestamp;
+
+  /**
+   * Where the travellers are that day
+   *
+   * @generated from field: string location = 3;
+   */
+  location = "";
+
+  /**
+   * In visiting order
+   *
+   * @generated from field: repeated travelingman.TripPlace places = 4;
+   */
+  places: TripPlace[] = [];
+
+  constructor(data?: PartialMessage<TripDay>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.TripDay";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "day_number", kind: "scalar", T: 5 /* ScalarType.INT32 */ },
+    { no: 2, name: "date", kind: "message", T: Timestamp },
+    { no: 3, name: "location", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 4, name: "places", kind: "message", T: TripPlace, repeated: true },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): TripDay {
+    return new TripDay().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): TripDay {
+    return new TripDay().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): TripDay {
+    return new TripDay().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: TripDay | PlainMessage<TripDay> | undefined, b: TripDay | PlainMessage<TripDay> | undefined): boolean {
+    return proto3.util.equals(TripDay, a, b);
+  }
+}
+
+/**
+ * TripPlace is a node of the plan, listed on the day it is first visited
+ *
+ * @generated from message travelingman.TripPlace
+ */
+export class TripPlace extends Message<TripPlace> {
+  /**
+   * @generated from field: string node_id = 1;
+   */
+  nodeId = "";
+
+  /**
+   * Node whose sub-graph the place is in, for activities
+   *
+   * @generated from field: string parent_node_id = 2;
+   */
+  parentNodeId = "";
+
+  /**
+   * @generated from field: string name = 3;
+   */
+  name = "";
+
+  /**
+   * @generated from field: string address = 4;
+   */
+  address = "";
+
+  /**
+   * stop, stay or activity
+   *
+   * @generated from field: string place_type = 5;
+   */
+  placeType = "";
+
+  /**
+   * Unset if the place has no geocode
+   *
+   * @generated from field: travelingman.LatLng position = 6;
+   */
+  position?: LatLng;
+
+  /**
+   * @generated from field: string notes = 7;
+   */
+  notes = "";
+
+  /**
+   * Unset if not known
+   *
+   * @generated from field: google.protobuf.Timestamp visit_time = 8;
+   */
+  visitTime?: Timestamp;
+
+  constructor(data?: PartialMessage<TripPlace>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.TripPlace";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "node_id", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "parent_node_id", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "name", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 4, name: "address", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 5, name: "place_type", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 6, name: "position", kind: "message", T: LatLng },
+    { no: 7, name: "notes", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 8, name: "visit_time", kind: "message", T: Timestamp },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): TripPlace {
+    return new TripPlace().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): TripPlace {
+    return new TripPlace().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): TripPlace {
+    return new TripPlace().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: TripPlace | PlainMessage<TripPlace> | undefined, b: TripPlace | PlainMessage<TripPlace> | undefined): boolean {
+    return proto3.util.equals(TripPlace, a, b);
+  }
+}
+
+/**
+ * TripLeg is an edge of the plan
+ *
+ * @generated from message travelingman.TripLeg
+ */
+export class TripLeg extends Message<TripLeg> {
+  /**
+   * Set for legs between activities, as for TripPlace
+   *
+   * @generated from field: string parent_node_id = 1;
+   */
+  parentNodeId = "";
+
+  /**
+   * Names of the places
+   *
+   * @generated from field: string from = 2;
+   */
+  from = "";
+
+  /**
+   * @generated from field: string to = 3;
+   */
+  to = "";
+
+  /**
+   * e.g. FLIGHT or TRAIN
+   *
+   * @generated from field: string mode = 4;
+   */
+  mode = "";
+
+  /**
+   * @generated from field: google.protobuf.Timestamp departure_time = 5;
+   */
+  departureTime?: Timestamp;
+
+  /**
+   * @generated from field: google.protobuf.Timestamp arrival_time = 6;
+   */
+  arrivalTime?: Timestamp;
+
+  constructor(data?: PartialMessage<TripLeg>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.TripLeg";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "parent_node_id", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "from", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "to", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 4, name: "mode", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 5, name: "departure_time", kind: "message", T: Timestamp },
+    { no: 6, name: "arrival_time", kind: "message", T: Timestamp },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): TripLeg {
+    return new TripLeg().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): TripLeg {
+    return new TripLeg().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): TripLeg {
+    return new TripLeg().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: TripLeg | PlainMessage<TripLeg> | undefined, b: TripLeg | PlainMessage<TripLeg> | undefined): boolean {
+    return proto3.util.equals(TripLeg, a, b);
+  }
+}
@@ -59,10 +59,17 @@ export class User extends Message<User> {
   gender = "";
 
   /**
+   * As entered, e.g. "+44 20 7946 0958" or "020 7946 0958"
+   *
    * @generated from field: string phone = 10;
    */
   phone = "";
 
+  /**
+   * @generated from field: travelingman.PostalAddress address = 11;
+   */
+  address?: PostalAddress;
+
   constructor(data?: PartialMessage<User>) {
     super();
     proto3.util.initPartial(data, this);
@@ -81,6 +88,7 @@ export class User extends Message<User> {
     { no: 8, name: "date_of_birth", kind: "message", T: Timestamp },
     { no: 9, name: "gender", kind: "scalar", T: 9 /* ScalarType.STRING */ },
     { no: 10, name: "phone", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 11, name: "address", kind: "message", T: PostalAddress },
   ]);
 
   static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): User {
@@ -100,6 +108,67 @@ export class User extends Message<User> {
   }
 }
 
+/**
+ * PostalAddress is a user's home address, used for booking contacts
+ *
+ * @generated from message travelingman.PostalAddress
+ */
+export class PostalAddress extends Message<PostalAddress> {
+  /**
+   * Street address, first line first
+   *
+   * @generated from field: repeated string lines = 1;
+   */
+  lines: string[] = [];
+
+  /**
+   * @generated from field: string postal_code = 2;
+   */
+  postalCode = "";
+
+  /**
+   * @generated from field: string city = 3;
+   */
+  city = "";
+
+  /**
+   * ISO 3166-1 alpha-2, e.g. "GB"
+   *
+   * @generated from field: string country_code = 4;
+   */
+  countryCode = "";
+
+  constructor(data?: PartialMessage<PostalAddress>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "travelingman.PostalAddress";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "lines", kind: "scalar", T: 9 /* ScalarType.STRING */, repeated: true },
+    { no: 2, name: "postal_code", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "city", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 4, name: "country_code", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): PostalAddress {
+    return new PostalAddress().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): PostalAddress {
+    return new PostalAddress().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): PostalAddress {
+    return new PostalAddress().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: PostalAddress | PlainMessage<PostalAddress> | undefined, b: PostalAddress | PlainMessage<PostalAddress> | undefined): boolean {
+    return proto3.util.equals(PostalAddress, a, b);
+  }
+}
+
 /**
  * @generated from message travelingman.Passport
  */